@@ -21,6 +21,7 @@ import (
 	"github.com/go-acme/lego/v4/challenge"
 	gokitmetrics "github.com/go-kit/kit/metrics"
 	"github.com/sirupsen/logrus"
+	"github.com/spiffe/go-spiffe/v2/workloadapi"
 	"github.com/traefik/paerser/cli"
 	"github.com/traefik/traefik/v2/cmd"
 	"github.com/traefik/traefik/v2/cmd/healthcheck"
@@ -30,24 +31,34 @@ import (
 	"github.com/traefik/traefik/v2/pkg/config/dynamic"
 	"github.com/traefik/traefik/v2/pkg/config/runtime"
 	"github.com/traefik/traefik/v2/pkg/config/static"
+	"github.com/traefik/traefik/v2/pkg/dnsdiscovery"
+	"github.com/traefik/traefik/v2/pkg/filewatcher"
+	"github.com/traefik/traefik/v2/pkg/ip"
+	"github.com/traefik/traefik/v2/pkg/ipban"
 	"github.com/traefik/traefik/v2/pkg/log"
 	"github.com/traefik/traefik/v2/pkg/metrics"
 	"github.com/traefik/traefik/v2/pkg/middlewares/accesslog"
+	"github.com/traefik/traefik/v2/pkg/middlewares/auth"
 	"github.com/traefik/traefik/v2/pkg/pilot"
 	"github.com/traefik/traefik/v2/pkg/provider/acme"
 	"github.com/traefik/traefik/v2/pkg/provider/aggregator"
 	"github.com/traefik/traefik/v2/pkg/provider/hub"
 	"github.com/traefik/traefik/v2/pkg/provider/traefik"
+	"github.com/traefik/traefik/v2/pkg/provider/vault"
 	"github.com/traefik/traefik/v2/pkg/safe"
 	"github.com/traefik/traefik/v2/pkg/server"
 	"github.com/traefik/traefik/v2/pkg/server/middleware"
 	"github.com/traefik/traefik/v2/pkg/server/service"
+	"github.com/traefik/traefik/v2/pkg/tcp"
 	traefiktls "github.com/traefik/traefik/v2/pkg/tls"
 	"github.com/traefik/traefik/v2/pkg/types"
 	"github.com/traefik/traefik/v2/pkg/version"
 	"github.com/vulcand/oxy/roundrobin"
 )
 
+// spiffeSourceTimeout is the maximum duration to wait for the first X.509 SVID from the SPIFFE Workload API.
+const spiffeSourceTimeout = 30 * time.Second
+
 func main() {
 	// traefik config inits
 	tConfig := cmd.NewTraefikConfiguration()
@@ -181,6 +192,22 @@ func setupServer(staticConfiguration *static.Configuration) (*server.Server, err
 	// ACME
 
 	tlsManager := traefiktls.NewManager()
+	routinesPool.GoCtx(tlsManager.RunOCSPStapler)
+
+	memcachedClient := setupMemcached(staticConfiguration.Memcached)
+
+	banManager := ipban.NewManager(memcachedClient)
+
+	// The files, the IP source lists, and the HMAC nonces, kept for the middlewares across the configuration changes.
+	sharedFiles := filewatcher.NewSharedFiles()
+	sourceLists := ip.NewSourceLists()
+	hmacNonces := auth.NewHMACNonceStores()
+
+	err = setupTLSSessionTickets(ctx, staticConfiguration.TLSSessionTickets, tlsManager, memcachedClient)
+	if err != nil {
+		return nil, err
+	}
+
 	httpChallengeProvider := acme.NewChallengeHTTP()
 
 	tlsChallengeProvider := acme.NewChallengeTLSALPN()
@@ -191,6 +218,10 @@ func setupServer(staticConfiguration *static.Configuration) (*server.Server, err
 
 	acmeProviders := initACMEProvider(staticConfiguration, &providerAggregator, tlsManager, httpChallengeProvider, tlsChallengeProvider)
 
+	// Vault
+
+	vaultProviders := initVaultProvider(staticConfiguration, &providerAggregator)
+
 	// Entrypoints
 
 	serverEntryPointsTCP, err := server.NewTCPEntryPoints(staticConfiguration.EntryPoints, staticConfiguration.HostResolver)
@@ -267,19 +298,32 @@ func setupServer(staticConfiguration *static.Configuration) (*server.Server, err
 	}
 	metricsRegistry := metrics.NewMultiRegistry(metricRegistries)
 
+	for _, acmeProvider := range acmeProviders {
+		acmeProvider.SetMetricsRegistry(metricsRegistry)
+	}
+
+	for _, vaultProvider := range vaultProviders {
+		vaultProvider.SetMetricsRegistry(metricsRegistry)
+	}
+
 	// Service manager factory
 
-	roundTripperManager := service.NewRoundTripperManager()
+	spiffeX509Source, err := setupSpiffe(ctx, routinesPool, staticConfiguration.Spiffe)
+	if err != nil {
+		return nil, err
+	}
+
+	roundTripperManager := service.NewRoundTripperManager(spiffeX509Source)
+	dialerManager := tcp.NewDialerManager(spiffeX509Source)
 	acmeHTTPHandler := getHTTPChallengeHandler(acmeProviders, httpChallengeProvider)
-	managerFactory := service.NewManagerFactory(*staticConfiguration, routinesPool, metricsRegistry, roundTripperManager, acmeHTTPHandler)
+	managerFactory := service.NewManagerFactory(*staticConfiguration, routinesPool, metricsRegistry, roundTripperManager, acmeHTTPHandler, tlsManager, banManager)
 
 	// Router factory
 
 	accessLog := setupAccessLog(staticConfiguration.AccessLog)
 	chainBuilder := middleware.NewChainBuilder(*staticConfiguration, metricsRegistry, accessLog)
 
-	memcachedClient := setupMemcached(staticConfiguration.Memcached)
-	routerFactory := server.NewRouterFactory(*staticConfiguration, managerFactory, tlsManager, chainBuilder, pluginBuilder, metricsRegistry, memcachedClient)
+	routerFactory := server.NewRouterFactory(*staticConfiguration, managerFactory, tlsManager, chainBuilder, pluginBuilder, metricsRegistry, memcachedClient, banManager, sharedFiles, sourceLists, hmacNonces, dialerManager, dnsdiscovery.NewLauncher(routinesPool), accessLog)
 
 	// Watcher
 
@@ -310,11 +354,19 @@ func setupServer(staticConfiguration *static.Configuration) (*server.Server, err
 	// Server Transports
 	watcher.AddListener(func(conf dynamic.Configuration) {
 		roundTripperManager.Update(conf.HTTP.ServersTransports)
+		dialerManager.Update(conf.TCP.ServersTransports)
 	})
 
 	// Switch router
 	watcher.AddListener(switchRouter(routerFactory, serverEntryPointsTCP, serverEntryPointsUDP, aviator))
 
+	// Shared files, IP source lists, and HMAC nonces, which are no longer used by the new routers.
+	watcher.AddListener(func(_ dynamic.Configuration) {
+		sharedFiles.Sweep()
+		sourceLists.Sweep()
+		hmacNonces.Sweep()
+	})
+
 	// Metrics
 	if metricsRegistry.IsEpEnabled() || metricsRegistry.IsSvcEnabled() {
 		var eps []string
@@ -336,6 +388,12 @@ func setupServer(staticConfiguration *static.Configuration) (*server.Server, err
 		watcher.AddListener(p.ListenConfiguration)
 	}
 
+	// Vault
+	for _, p := range vaultProviders {
+		resolverNames[p.ResolverName] = struct{}{}
+		watcher.AddListener(p.ListenConfiguration)
+	}
+
 	// Certificate resolver logs
 	watcher.AddListener(func(config dynamic.Configuration) {
 		for rtName, rt := range config.HTTP.Routers {
@@ -405,7 +463,7 @@ func switchRouter(routerFactory *server.RouterFactory, serverEntryPointsTCP serv
 }
 
 // initACMEProvider creates an acme provider from the ACME part of globalConfiguration.
-func initACMEProvider(c *static.Configuration, providerAggregator *aggregator.ProviderAggregator, tlsManager *traefiktls.Manager, httpChallengeProvider, tlsChallengeProvider challenge.Provider) []*acme.Provider {
+func initACMEProvider(c *static.Configuration, providerAggregator *aggregator.ProviderAggregator, tlsManager *traefiktls.Manager, httpChallengeProvider *acme.ChallengeHTTP, tlsChallengeProvider challenge.Provider) []*acme.Provider {
 	localStores := map[string]*acme.LocalStore{}
 
 	var resolvers []*acme.Provider
@@ -414,13 +472,27 @@ func initACMEProvider(c *static.Configuration, providerAggregator *aggregator.Pr
 			continue
 		}
 
-		if localStores[resolver.ACME.Storage] == nil {
-			localStores[resolver.ACME.Storage] = acme.NewLocalStore(resolver.ACME.Storage)
+		var store acme.Store
+		if resolver.ACME.KVStorage != nil {
+			kvStore, err := acme.NewKVStore(context.Background(), resolver.ACME.KVStorage)
+			if err != nil {
+				log.WithoutContext().Errorf("The ACME resolver %q is skipped from the resolvers list because: %v", name, err)
+				continue
+			}
+
+			// The HTTP challenges are shared, as the ACME server can reach any Traefik instance.
+			httpChallengeProvider.AddStore(kvStore)
+			store = kvStore
+		} else {
+			if localStores[resolver.ACME.Storage] == nil {
+				localStores[resolver.ACME.Storage] = acme.NewLocalStore(resolver.ACME.Storage)
+			}
+			store = localStores[resolver.ACME.Storage]
 		}
 
 		p := &acme.Provider{
 			Configuration:         resolver.ACME,
-			Store:                 localStores[resolver.ACME.Storage],
+			Store:                 store,
 			ResolverName:          name,
 			HTTPChallengeProvider: httpChallengeProvider,
 			TLSChallengeProvider:  tlsChallengeProvider,
@@ -441,6 +513,32 @@ func initACMEProvider(c *static.Configuration, providerAggregator *aggregator.Pr
 	return resolvers
 }
 
+// initVaultProvider creates a Vault provider from the Vault part of each certificate resolver.
+func initVaultProvider(c *static.Configuration, providerAggregator *aggregator.ProviderAggregator) []*vault.Provider {
+	var resolvers []*vault.Provider
+	for name, resolver := range c.CertificatesResolvers {
+		if resolver.Vault == nil {
+			continue
+		}
+
+		p := &vault.Provider{
+			Configuration: resolver.Vault,
+			ResolverName:  name,
+		}
+
+		if err := providerAggregator.AddProvider(p); err != nil {
+			log.WithoutContext().Errorf("The Vault resolver %q is skipped from the resolvers list because: %v", name, err)
+			continue
+		}
+
+		p.SetConfigListenerChan(make(chan dynamic.Configuration))
+
+		resolvers = append(resolvers, p)
+	}
+
+	return resolvers
+}
+
 func registerMetricClients(metricsConfig *types.Metrics) []metrics.Registry {
 	if metricsConfig == nil {
 		return nil
@@ -613,3 +711,64 @@ func collect(staticConfiguration *static.Configuration) {
 func setupMemcached(conf *static.Memcached) *memcached.Client {
 	return memcached.NewMemcachedClient(conf)
 }
+
+func setupSpiffe(ctx context.Context, routinesPool *safe.Pool, conf *static.SpiffeClientConfig) (traefiktls.SpiffeX509Source, error) {
+	if conf == nil {
+		return nil, nil
+	}
+
+	var clientOptions []workloadapi.ClientOption
+	if conf.WorkloadAPIAddr != "" {
+		clientOptions = append(clientOptions, workloadapi.WithAddr(conf.WorkloadAPIAddr))
+	}
+
+	// The creation of the source waits for the first X.509 SVID, which never comes if the Workload API is not reachable.
+	ctxSource, cancel := context.WithTimeout(ctx, spiffeSourceTimeout)
+	defer cancel()
+
+	source, err := workloadapi.NewX509Source(ctxSource, workloadapi.WithClientOptions(clientOptions...))
+	if err != nil {
+		return nil, fmt.Errorf("unable to create SPIFFE x509 source: %w", err)
+	}
+
+	log.WithoutContext().Info("Successfully obtained SPIFFE SVID.")
+
+	routinesPool.GoCtx(func(ctxPool context.Context) {
+		<-ctxPool.Done()
+
+		if err := source.Close(); err != nil {
+			log.WithoutContext().Errorf("Error while closing SPIFFE x509 source: %v", err)
+		}
+	})
+
+	return source, nil
+}
+
+func setupTLSSessionTickets(ctx context.Context, conf *static.TLSSessionTickets, tlsManager *traefiktls.Manager, memcachedClient *memcached.Client) error {
+	if conf == nil {
+		return nil
+	}
+
+	if len(conf.KeyFiles) > 0 {
+		keys, err := traefiktls.NewSessionTicketKeysFromFiles(ctx, conf.KeyFiles)
+		if err != nil {
+			return fmt.Errorf("unable to read the TLS session ticket keys: %w", err)
+		}
+
+		tlsManager.SetSessionTicketKeys(keys)
+		return nil
+	}
+
+	var store traefiktls.SessionTicketKeyStore
+	if conf.Store == "memcached" {
+		store = memcached.NewMemcachedHandler[[]byte](memcachedClient)
+	}
+
+	keys, err := traefiktls.NewRotatingSessionTicketKeys(ctx, store, time.Duration(conf.RotationInterval), time.Duration(conf.Overlap))
+	if err != nil {
+		return fmt.Errorf("unable to rotate the TLS session ticket keys: %w", err)
+	}
+
+	tlsManager.SetSessionTicketKeys(keys)
+	return nil
+}
@@ -10,7 +10,9 @@ import (
 	"github.com/traefik/traefik/v2/pkg/config/dynamic"
 	"github.com/traefik/traefik/v2/pkg/config/runtime"
 	"github.com/traefik/traefik/v2/pkg/config/static"
+	"github.com/traefik/traefik/v2/pkg/ipban"
 	"github.com/traefik/traefik/v2/pkg/log"
+	"github.com/traefik/traefik/v2/pkg/tls"
 	"github.com/traefik/traefik/v2/pkg/version"
 )
 
@@ -51,12 +53,21 @@ type Handler struct {
 
 	// runtimeConfiguration is the data set used to create all the data representations exposed by the API.
 	runtimeConfiguration *runtime.Configuration
+
+	// tlsManager provides the certificates exposed by the API.
+	tlsManager *tls.Manager
+
+	// banManager provides the IP bans exposed by the API.
+	banManager *ipban.Manager
 }
 
 // NewBuilder returns a http.Handler builder based on runtime.Configuration.
-func NewBuilder(staticConfig static.Configuration) func(*runtime.Configuration) http.Handler {
+func NewBuilder(staticConfig static.Configuration, tlsManager *tls.Manager, banManager *ipban.Manager) func(*runtime.Configuration) http.Handler {
 	return func(configuration *runtime.Configuration) http.Handler {
-		return New(staticConfig, configuration).createRouter()
+		handler := New(staticConfig, configuration)
+		handler.tlsManager = tlsManager
+		handler.banManager = banManager
+		return handler.createRouter()
 	}
 }
 
@@ -109,6 +120,16 @@ func (h Handler) createRouter() *mux.Router {
 	router.Methods(http.MethodGet).Path("/api/udp/services").HandlerFunc(h.getUDPServices)
 	router.Methods(http.MethodGet).Path("/api/udp/services/{serviceID}").HandlerFunc(h.getUDPService)
 
+	router.Methods(http.MethodGet).Path("/api/tls/certificates").HandlerFunc(h.getCertificates)
+	router.Methods(http.MethodGet).Path("/api/tls/certificates/{certificateID}").HandlerFunc(h.getCertificate)
+
+	router.Methods(http.MethodGet).Path("/api/ipbans").HandlerFunc(h.getIPBans)
+
+	if h.staticConfig.API.ClearIPBans {
+		router.Methods(http.MethodDelete).Path("/api/ipbans/{list}").HandlerFunc(h.clearIPBans)
+		router.Methods(http.MethodDelete).Path("/api/ipbans/{list}/{ip}").HandlerFunc(h.clearIPBans)
+	}
+
 	version.Handler{}.Append(router)
 
 	return router
@@ -0,0 +1,66 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/traefik/traefik/v2/pkg/ipban"
+	"github.com/traefik/traefik/v2/pkg/log"
+)
+
+type clearedBansRepresentation struct {
+	Cleared int `json:"cleared"`
+}
+
+func (h Handler) getIPBans(rw http.ResponseWriter, request *http.Request) {
+	results := make([]ipban.Ban, 0)
+
+	criterion := newSearchCriterion(request.URL.Query())
+
+	if h.banManager != nil {
+		for _, ban := range h.banManager.Bans() {
+			if criterion == nil || criterion.searchIn(ban.List, ban.IP) {
+				results = append(results, ban)
+			}
+		}
+	}
+
+	rw.Header().Set("Content-Type", "application/json")
+
+	pageInfo, err := pagination(request, len(results))
+	if err != nil {
+		writeError(rw, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	rw.Header().Set(nextPageHeader, strconv.Itoa(pageInfo.nextPage))
+
+	err = json.NewEncoder(rw).Encode(results[pageInfo.startIndex:pageInfo.endIndex])
+	if err != nil {
+		log.FromContext(request.Context()).Error(err)
+		writeError(rw, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// clearIPBans clears the ban of an IP from a ban list, or the bans of all the IPs of the list if the IP is not given.
+func (h Handler) clearIPBans(rw http.ResponseWriter, request *http.Request) {
+	list := mux.Vars(request)["list"]
+	ip := mux.Vars(request)["ip"]
+
+	rw.Header().Set("Content-Type", "application/json")
+
+	if h.banManager == nil {
+		writeError(rw, "the ban lists are not available", http.StatusNotFound)
+		return
+	}
+
+	cleared := h.banManager.Clear(request.Context(), list, ip)
+
+	err := json.NewEncoder(rw).Encode(clearedBansRepresentation{Cleared: cleared})
+	if err != nil {
+		log.FromContext(request.Context()).Error(err)
+		writeError(rw, err.Error(), http.StatusInternalServerError)
+	}
+}
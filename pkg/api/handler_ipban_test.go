@@ -0,0 +1,175 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/traefik/traefik/v2/pkg/config/runtime"
+	"github.com/traefik/traefik/v2/pkg/config/static"
+	"github.com/traefik/traefik/v2/pkg/ipban"
+)
+
+func TestHandler_IPBans(t *testing.T) {
+	newBanManager := func(t *testing.T) *ipban.Manager {
+		t.Helper()
+
+		banManager := ipban.NewManager(nil)
+		for _, ban := range []struct{ list, ip string }{
+			{list: "login", ip: "10.0.0.1"},
+			{list: "login", ip: "10.0.0.2"},
+			{list: "admin", ip: "10.0.0.1"},
+		} {
+			list, err := banManager.List(ban.list, ipban.StoreMemory)
+			require.NoError(t, err)
+
+			list.Ban(context.Background(), ban.ip, time.Hour, "test")
+		}
+
+		return banManager
+	}
+
+	testCases := []struct {
+		desc            string
+		method          string
+		path            string
+		noBanManager    bool
+		clearDisabled   bool
+		expectedStatus  int
+		expectedNext    string
+		expectedBans    []string
+		expectedCleared int
+		expectedLeft    []string
+	}{
+		{
+			desc:           "all bans, but no ban manager",
+			method:         http.MethodGet,
+			path:           "/api/ipbans",
+			noBanManager:   true,
+			expectedStatus: http.StatusOK,
+			expectedNext:   "1",
+			expectedBans:   []string{},
+		},
+		{
+			desc:           "all bans",
+			method:         http.MethodGet,
+			path:           "/api/ipbans",
+			expectedStatus: http.StatusOK,
+			expectedNext:   "1",
+			expectedBans:   []string{"admin/10.0.0.1", "login/10.0.0.1", "login/10.0.0.2"},
+		},
+		{
+			desc:           "all bans, pagination, 1 res per page, want page 2",
+			method:         http.MethodGet,
+			path:           "/api/ipbans?page=2&per_page=1",
+			expectedStatus: http.StatusOK,
+			expectedNext:   "3",
+			expectedBans:   []string{"login/10.0.0.1"},
+		},
+		{
+			desc:           "bans filtered by search",
+			method:         http.MethodGet,
+			path:           "/api/ipbans?search=login",
+			expectedStatus: http.StatusOK,
+			expectedNext:   "1",
+			expectedBans:   []string{"login/10.0.0.1", "login/10.0.0.2"},
+		},
+		{
+			desc:            "clear a ban",
+			method:          http.MethodDelete,
+			path:            "/api/ipbans/login/10.0.0.1",
+			expectedStatus:  http.StatusOK,
+			expectedCleared: 1,
+			expectedLeft:    []string{"admin/10.0.0.1", "login/10.0.0.2"},
+		},
+		{
+			desc:            "clear a ban list",
+			method:          http.MethodDelete,
+			path:            "/api/ipbans/login",
+			expectedStatus:  http.StatusOK,
+			expectedCleared: 2,
+			expectedLeft:    []string{"admin/10.0.0.1"},
+		},
+		{
+			desc:            "clear a ban that does not exist",
+			method:          http.MethodDelete,
+			path:            "/api/ipbans/login/10.0.0.3",
+			expectedStatus:  http.StatusOK,
+			expectedCleared: 0,
+			expectedLeft:    []string{"admin/10.0.0.1", "login/10.0.0.1", "login/10.0.0.2"},
+		},
+		{
+			desc:           "clear a ban, but clearing disabled",
+			method:         http.MethodDelete,
+			path:           "/api/ipbans/login/10.0.0.1",
+			clearDisabled:  true,
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			desc:           "clear a ban, but no ban manager",
+			method:         http.MethodDelete,
+			path:           "/api/ipbans/login/10.0.0.1",
+			noBanManager:   true,
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			handler := New(static.Configuration{API: &static.API{ClearIPBans: !test.clearDisabled}, Global: &static.Global{}}, &runtime.Configuration{})
+			if !test.noBanManager {
+				handler.banManager = newBanManager(t)
+			}
+			server := httptest.NewServer(handler.createRouter())
+			t.Cleanup(server.Close)
+
+			req, err := http.NewRequest(test.method, server.URL+test.path, http.NoBody)
+			require.NoError(t, err)
+
+			resp, err := http.DefaultClient.Do(req)
+			require.NoError(t, err)
+			defer func() { _ = resp.Body.Close() }()
+
+			require.Equal(t, test.expectedStatus, resp.StatusCode)
+			assert.Equal(t, test.expectedNext, resp.Header.Get(nextPageHeader))
+
+			if test.expectedStatus != http.StatusOK {
+				return
+			}
+
+			assert.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+
+			if test.method == http.MethodDelete {
+				var cleared clearedBansRepresentation
+				err = json.NewDecoder(resp.Body).Decode(&cleared)
+				require.NoError(t, err)
+
+				assert.Equal(t, test.expectedCleared, cleared.Cleared)
+				assert.Equal(t, test.expectedLeft, banNames(handler.banManager.Bans()))
+				return
+			}
+
+			var bans []ipban.Ban
+			err = json.NewDecoder(resp.Body).Decode(&bans)
+			require.NoError(t, err)
+
+			assert.Equal(t, test.expectedBans, banNames(bans))
+		})
+	}
+}
+
+func banNames(bans []ipban.Ban) []string {
+	names := make([]string, 0, len(bans))
+	for _, ban := range bans {
+		names = append(names, ban.List+"/"+ban.IP)
+	}
+	return names
+}
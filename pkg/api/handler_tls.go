@@ -0,0 +1,306 @@
+package api
+
+import (
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/traefik/traefik/v2/pkg/config/runtime"
+	"github.com/traefik/traefik/v2/pkg/log"
+	httpmuxer "github.com/traefik/traefik/v2/pkg/muxer/http"
+	tcpmuxer "github.com/traefik/traefik/v2/pkg/muxer/tcp"
+	"github.com/traefik/traefik/v2/pkg/tls"
+)
+
+// certificateExpiringPeriod is the period before the expiration of a certificate during which it is reported with a warning status.
+const certificateExpiringPeriod = 30 * 24 * time.Hour
+
+// resolverProviderSuffixes are the suffixes of the names of the providers pushing the certificates of the certificate resolvers.
+var resolverProviderSuffixes = []string{".acme", ".vault"}
+
+type certificateRepresentation struct {
+	// Name is the SHA-256 fingerprint of the certificate.
+	Name         string    `json:"name"`
+	CommonName   string    `json:"commonName,omitempty"`
+	SANs         []string  `json:"sans,omitempty"`
+	Issuer       string    `json:"issuer,omitempty"`
+	SerialNumber string    `json:"serialNumber,omitempty"`
+	NotBefore    time.Time `json:"notBefore"`
+	NotAfter     time.Time `json:"notAfter"`
+	Status       string    `json:"status,omitempty"`
+	Stores       []string  `json:"stores,omitempty"`
+	Default      bool      `json:"default,omitempty"`
+	Provider     string    `json:"provider,omitempty"`
+	Resolver     string    `json:"resolver,omitempty"`
+	Routers      []string  `json:"routers,omitempty"`
+	TCPRouters   []string  `json:"tcpRouters,omitempty"`
+
+	leaf *x509.Certificate
+}
+
+func newCertificateRepresentation(info tls.CertificateInfo) *certificateRepresentation {
+	leaf := info.Certificate
+	fingerprint := sha256.Sum256(leaf.Raw)
+
+	sans := append([]string{}, leaf.DNSNames...)
+	for _, ip := range leaf.IPAddresses {
+		sans = append(sans, ip.String())
+	}
+
+	var resolver string
+	for _, suffix := range resolverProviderSuffixes {
+		if strings.HasSuffix(info.Provider, suffix) {
+			resolver = strings.TrimSuffix(info.Provider, suffix)
+		}
+	}
+
+	status := runtime.StatusEnabled
+	now := time.Now()
+	switch {
+	case now.After(leaf.NotAfter) || now.Before(leaf.NotBefore):
+		status = runtime.StatusDisabled
+	case now.Add(certificateExpiringPeriod).After(leaf.NotAfter):
+		status = runtime.StatusWarning
+	}
+
+	return &certificateRepresentation{
+		Name:         hex.EncodeToString(fingerprint[:]),
+		CommonName:   leaf.Subject.CommonName,
+		SANs:         sans,
+		Issuer:       leaf.Issuer.String(),
+		SerialNumber: leaf.SerialNumber.String(),
+		NotBefore:    leaf.NotBefore,
+		NotAfter:     leaf.NotAfter,
+		Status:       status,
+		Provider:     info.Provider,
+		Resolver:     resolver,
+		leaf:         leaf,
+	}
+}
+
+func (h Handler) getCertificates(rw http.ResponseWriter, request *http.Request) {
+	certificates := h.getCertificateRepresentations()
+	results := make([]*certificateRepresentation, 0, len(certificates))
+
+	criterion := newSearchCriterion(request.URL.Query())
+
+	for _, certificate := range certificates {
+		if keepCertificate(certificate, criterion) {
+			results = append(results, certificate)
+		}
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		if !results[i].NotAfter.Equal(results[j].NotAfter) {
+			return results[i].NotAfter.Before(results[j].NotAfter)
+		}
+		return results[i].Name < results[j].Name
+	})
+
+	rw.Header().Set("Content-Type", "application/json")
+
+	pageInfo, err := pagination(request, len(results))
+	if err != nil {
+		writeError(rw, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	rw.Header().Set(nextPageHeader, strconv.Itoa(pageInfo.nextPage))
+
+	err = json.NewEncoder(rw).Encode(results[pageInfo.startIndex:pageInfo.endIndex])
+	if err != nil {
+		log.FromContext(request.Context()).Error(err)
+		writeError(rw, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func (h Handler) getCertificate(rw http.ResponseWriter, request *http.Request) {
+	certificateID := mux.Vars(request)["certificateID"]
+
+	rw.Header().Set("Content-Type", "application/json")
+
+	var result *certificateRepresentation
+	for _, certificate := range h.getCertificateRepresentations() {
+		if certificate.Name == strings.ToLower(certificateID) {
+			result = certificate
+			break
+		}
+	}
+
+	if result == nil {
+		writeError(rw, fmt.Sprintf("certificate not found: %s", certificateID), http.StatusNotFound)
+		return
+	}
+
+	err := json.NewEncoder(rw).Encode(result)
+	if err != nil {
+		log.FromContext(request.Context()).Error(err)
+		writeError(rw, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// getCertificateRepresentations returns the certificates served by the TLS stores,
+// a certificate served by several stores being represented once.
+func (h Handler) getCertificateRepresentations() []*certificateRepresentation {
+	if h.tlsManager == nil {
+		return nil
+	}
+
+	var certificates []*certificateRepresentation
+	byName := make(map[string]*certificateRepresentation)
+	for _, info := range h.tlsManager.GetCertificatesInfo() {
+		certificate := newCertificateRepresentation(info)
+
+		existing, ok := byName[certificate.Name]
+		if !ok {
+			byName[certificate.Name] = certificate
+			certificates = append(certificates, certificate)
+			existing = certificate
+		}
+
+		existing.Stores = appendUnique(existing.Stores, info.Store)
+		existing.Default = existing.Default || info.Default
+		if existing.Provider == "" {
+			existing.Provider = certificate.Provider
+			existing.Resolver = certificate.Resolver
+		}
+	}
+
+	for _, certificate := range certificates {
+		sort.Strings(certificate.Stores)
+	}
+
+	h.addCertificatesRouters(certificates)
+
+	return certificates
+}
+
+// addCertificatesRouters adds to the certificates of the default TLS store the routers they are served for,
+// based on the domains of the routers.
+// The domains not matched by any certificate are served with the default certificate.
+func (h Handler) addCertificatesRouters(certificates []*certificateRepresentation) {
+	var defaultCertificate *certificateRepresentation
+	var storeCertificates []*certificateRepresentation
+	for _, certificate := range certificates {
+		if !contains(certificate.Stores, tls.DefaultTLSStoreName) {
+			continue
+		}
+
+		if certificate.Default {
+			defaultCertificate = certificate
+		}
+
+		if certificate.Provider != "" {
+			storeCertificates = append(storeCertificates, certificate)
+		}
+	}
+
+	servedCertificates := func(domains []string) []*certificateRepresentation {
+		var served []*certificateRepresentation
+		for _, domain := range domains {
+			var matched bool
+			for _, certificate := range storeCertificates {
+				if certificateMatches(certificate.leaf, domain) {
+					served = append(served, certificate)
+					matched = true
+				}
+			}
+
+			if !matched && defaultCertificate != nil {
+				served = append(served, defaultCertificate)
+			}
+		}
+		return served
+	}
+
+	for name, rt := range h.runtimeConfiguration.Routers {
+		if rt.TLS == nil {
+			continue
+		}
+
+		domains, _ := httpmuxer.ParseDomains(rt.Rule)
+		for _, domain := range rt.TLS.Domains {
+			domains = append(domains, domain.ToStrArray()...)
+		}
+
+		for _, certificate := range servedCertificates(domains) {
+			certificate.Routers = appendUnique(certificate.Routers, name)
+		}
+	}
+
+	for name, rt := range h.runtimeConfiguration.TCPRouters {
+		if rt.TLS == nil || rt.TLS.Passthrough {
+			continue
+		}
+
+		var domains []string
+		hostSNIs, _ := tcpmuxer.ParseHostSNI(rt.Rule)
+		for _, domain := range hostSNIs {
+			if domain != "*" {
+				domains = append(domains, domain)
+			}
+		}
+		for _, domain := range rt.TLS.Domains {
+			domains = append(domains, domain.ToStrArray()...)
+		}
+
+		for _, certificate := range servedCertificates(domains) {
+			certificate.TCPRouters = appendUnique(certificate.TCPRouters, name)
+		}
+	}
+
+	for _, certificate := range certificates {
+		sort.Strings(certificate.Routers)
+		sort.Strings(certificate.TCPRouters)
+	}
+}
+
+// certificateMatches returns whether the certificate is valid for the given domain,
+// which can be a wildcard domain.
+func certificateMatches(leaf *x509.Certificate, domain string) bool {
+	if !strings.HasPrefix(domain, "*.") {
+		return leaf.VerifyHostname(domain) == nil
+	}
+
+	for _, name := range leaf.DNSNames {
+		if strings.EqualFold(name, domain) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func keepCertificate(item *certificateRepresentation, criterion *searchCriterion) bool {
+	if criterion == nil {
+		return true
+	}
+
+	values := append([]string{item.CommonName, item.Issuer, item.Provider}, item.SANs...)
+
+	return criterion.withStatus(item.Status) && criterion.searchIn(values...)
+}
+
+func appendUnique(values []string, value string) []string {
+	if contains(values, value) {
+		return values
+	}
+	return append(values, value)
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,219 @@
+package api
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/traefik/traefik/v2/pkg/config/dynamic"
+	"github.com/traefik/traefik/v2/pkg/config/runtime"
+	"github.com/traefik/traefik/v2/pkg/config/static"
+	"github.com/traefik/traefik/v2/pkg/tls"
+	"github.com/traefik/traefik/v2/pkg/types"
+)
+
+func TestHandler_TLS(t *testing.T) {
+	type expected struct {
+		statusCode int
+		nextPage   string
+		jsonFile   string
+	}
+
+	tlsManager := tls.NewManager()
+	tlsManager.UpdateConfigs(context.Background(),
+		map[string]tls.Store{
+			tls.DefaultTLSStoreName: {
+				DefaultCertificate: &tls.Certificate{CertFile: "testdata/tls/default.crt", KeyFile: "testdata/tls/default.key"},
+			},
+			"other": {
+				DefaultCertificate: &tls.Certificate{CertFile: "testdata/tls/default.crt", KeyFile: "testdata/tls/default.key"},
+			},
+		},
+		nil,
+		[]*tls.CertAndStores{
+			{
+				Certificate: tls.Certificate{CertFile: "testdata/tls/foo.crt", KeyFile: "testdata/tls/foo.key"},
+				Provider:    "myresolver.acme",
+			},
+			{
+				Certificate: tls.Certificate{CertFile: "testdata/tls/expired.crt", KeyFile: "testdata/tls/expired.key"},
+				Stores:      []string{tls.DefaultTLSStoreName, "other"},
+				Provider:    "file",
+			},
+		})
+
+	rtConf := runtime.Configuration{
+		Routers: map[string]*runtime.RouterInfo{
+			"foo@docker": {
+				Router: &dynamic.Router{Rule: "Host(`foo.example.com`)", Service: "foo", TLS: &dynamic.RouterTLSConfig{}},
+			},
+			"wildcard@docker": {
+				Router: &dynamic.Router{Rule: "Host(`baz.bar.example.com`)", Service: "foo", TLS: &dynamic.RouterTLSConfig{}},
+			},
+			"unknown@docker": {
+				Router: &dynamic.Router{Rule: "Host(`unknown.example.com`)", Service: "foo", TLS: &dynamic.RouterTLSConfig{}},
+			},
+			"expired@file": {
+				Router: &dynamic.Router{Rule: "PathPrefix(`/`)", Service: "foo", TLS: &dynamic.RouterTLSConfig{
+					Domains: []types.Domain{{Main: "expired.example.com"}},
+				}},
+			},
+			"notls@docker": {
+				Router: &dynamic.Router{Rule: "Host(`foo.example.com`)", Service: "foo"},
+			},
+		},
+		TCPRouters: map[string]*runtime.TCPRouterInfo{
+			"foo@file": {
+				TCPRouter: &dynamic.TCPRouter{Rule: "HostSNI(`foo.example.com`)", Service: "foo", TLS: &dynamic.RouterTCPTLSConfig{}},
+			},
+			"passthrough@file": {
+				TCPRouter: &dynamic.TCPRouter{Rule: "HostSNI(`foo.example.com`)", Service: "foo", TLS: &dynamic.RouterTCPTLSConfig{Passthrough: true}},
+			},
+		},
+	}
+
+	fooID := fingerprint(t, "testdata/tls/foo.crt")
+
+	testCases := []struct {
+		desc       string
+		path       string
+		tlsManager *tls.Manager
+		expected   expected
+	}{
+		{
+			desc: "all certificates, but no TLS manager",
+			path: "/api/tls/certificates",
+			expected: expected{
+				statusCode: http.StatusOK,
+				nextPage:   "1",
+				jsonFile:   "testdata/certificates-empty.json",
+			},
+		},
+		{
+			desc:       "all certificates",
+			path:       "/api/tls/certificates",
+			tlsManager: tlsManager,
+			expected: expected{
+				statusCode: http.StatusOK,
+				nextPage:   "1",
+				jsonFile:   "testdata/certificates.json",
+			},
+		},
+		{
+			desc:       "all certificates, pagination, 1 res per page, want page 2",
+			path:       "/api/tls/certificates?page=2&per_page=1",
+			tlsManager: tlsManager,
+			expected: expected{
+				statusCode: http.StatusOK,
+				nextPage:   "3",
+				jsonFile:   "testdata/certificates-page2.json",
+			},
+		},
+		{
+			desc:       "expired certificates",
+			path:       "/api/tls/certificates?status=disabled",
+			tlsManager: tlsManager,
+			expected: expected{
+				statusCode: http.StatusOK,
+				nextPage:   "1",
+				jsonFile:   "testdata/certificates-filtered-status.json",
+			},
+		},
+		{
+			desc:       "certificates filtered by search",
+			path:       "/api/tls/certificates?search=bar.example",
+			tlsManager: tlsManager,
+			expected: expected{
+				statusCode: http.StatusOK,
+				nextPage:   "1",
+				jsonFile:   "testdata/certificates-filtered-search.json",
+			},
+		},
+		{
+			desc:       "one certificate by fingerprint",
+			path:       "/api/tls/certificates/" + fooID,
+			tlsManager: tlsManager,
+			expected: expected{
+				statusCode: http.StatusOK,
+				jsonFile:   "testdata/certificate-foo.json",
+			},
+		},
+		{
+			desc:       "one certificate by fingerprint, that does not exist",
+			path:       "/api/tls/certificates/foo",
+			tlsManager: tlsManager,
+			expected: expected{
+				statusCode: http.StatusNotFound,
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			handler := New(static.Configuration{API: &static.API{}, Global: &static.Global{}}, &rtConf)
+			handler.tlsManager = test.tlsManager
+			server := httptest.NewServer(handler.createRouter())
+
+			resp, err := http.DefaultClient.Get(server.URL + test.path)
+			require.NoError(t, err)
+
+			assert.Equal(t, test.expected.nextPage, resp.Header.Get(nextPageHeader))
+
+			require.Equal(t, test.expected.statusCode, resp.StatusCode)
+
+			if test.expected.jsonFile == "" {
+				return
+			}
+
+			assert.Equal(t, resp.Header.Get("Content-Type"), "application/json")
+
+			contents, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
+
+			err = resp.Body.Close()
+			require.NoError(t, err)
+
+			if *updateExpected {
+				var results interface{}
+				err := json.Unmarshal(contents, &results)
+				require.NoError(t, err)
+
+				newJSON, err := json.MarshalIndent(results, "", "\t")
+				require.NoError(t, err)
+
+				err = os.WriteFile(test.expected.jsonFile, newJSON, 0o644)
+				require.NoError(t, err)
+			}
+
+			data, err := os.ReadFile(test.expected.jsonFile)
+			require.NoError(t, err)
+			assert.JSONEq(t, string(data), string(contents))
+		})
+	}
+}
+
+func fingerprint(t *testing.T, certFile string) string {
+	t.Helper()
+
+	data, err := os.ReadFile(certFile)
+	require.NoError(t, err)
+
+	block, _ := pem.Decode(data)
+	require.NotNil(t, block)
+
+	sum := sha256.Sum256(block.Bytes)
+
+	return hex.EncodeToString(sum[:])
+}
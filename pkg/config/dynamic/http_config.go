@@ -79,9 +79,31 @@ func (m *Mirroring) SetDefaults() {
 
 // Failover holds the Failover configuration.
 type Failover struct {
-	Service     string       `json:"service,omitempty" toml:"service,omitempty" yaml:"service,omitempty" export:"true"`
-	Fallback    string       `json:"fallback,omitempty" toml:"fallback,omitempty" yaml:"fallback,omitempty" export:"true"`
+	Service  string `json:"service,omitempty" toml:"service,omitempty" yaml:"service,omitempty" export:"true"`
+	Fallback string `json:"fallback,omitempty" toml:"fallback,omitempty" yaml:"fallback,omitempty" export:"true"`
+	// Fallbacks defines additional fallback services, used in order when the Service and the Fallback are down.
+	Fallbacks   []string     `json:"fallbacks,omitempty" toml:"fallbacks,omitempty" yaml:"fallbacks,omitempty" export:"true"`
 	HealthCheck *HealthCheck `json:"healthCheck,omitempty" toml:"healthCheck,omitempty" yaml:"healthCheck,omitempty" label:"allowEmpty" file:"allowEmpty" export:"true"`
+	// Errors defines the response status codes that make a request fail over to the next available service.
+	Errors *FailoverErrors `json:"errors,omitempty" toml:"errors,omitempty" yaml:"errors,omitempty" export:"true"`
+}
+
+// +k8s:deepcopy-gen=true
+
+// FailoverErrors holds the per-request failover configuration.
+type FailoverErrors struct {
+	// Status defines the response status codes (or ranges, e.g. 500-599) triggering the failover.
+	// Connection errors to the servers always trigger the failover, whatever the status codes.
+	Status []string `json:"status,omitempty" toml:"status,omitempty" yaml:"status,omitempty" export:"true"`
+	// MaxBodySize defines the maximum size, in bytes, of the request body buffered to be replayed.
+	// Requests with a larger body are not replayed. Default is 1048576 (1 MiB), and -1 means no limit.
+	MaxBodySize *int64 `json:"maxBodySize,omitempty" toml:"maxBodySize,omitempty" yaml:"maxBodySize,omitempty" export:"true"`
+}
+
+// SetDefaults Default values for a FailoverErrors.
+func (f *FailoverErrors) SetDefaults() {
+	var defaultMaxBodySize int64 = 1 << 20
+	f.MaxBodySize = &defaultMaxBodySize
 }
 
 // +k8s:deepcopy-gen=true
@@ -112,6 +134,9 @@ type WeightedRoundRobin struct {
 type WRRService struct {
 	Name   string `json:"name,omitempty" toml:"name,omitempty" yaml:"name,omitempty" export:"true"`
 	Weight *int   `json:"weight,omitempty" toml:"weight,omitempty" yaml:"weight,omitempty" export:"true"`
+	// Match defines the conditions under which a request is deterministically sent to this service,
+	// regardless of the weights.
+	Match *WRRMatch `json:"match,omitempty" toml:"match,omitempty" yaml:"match,omitempty" export:"true"`
 }
 
 // SetDefaults Default values for a WRRService.
@@ -122,6 +147,33 @@ func (w *WRRService) SetDefaults() {
 
 // +k8s:deepcopy-gen=true
 
+// WRRMatch holds the conditions routing a request to a given WRRService.
+// All the defined conditions must be satisfied for a request to match.
+type WRRMatch struct {
+	// Headers defines the request headers, and their expected values, that a request must have.
+	Headers map[string]string `json:"headers,omitempty" toml:"headers,omitempty" yaml:"headers,omitempty" export:"true"`
+	// Cookies defines the request cookies, and their expected values, that a request must have.
+	Cookies map[string]string `json:"cookies,omitempty" toml:"cookies,omitempty" yaml:"cookies,omitempty" export:"true"`
+	// Percentage defines the share of the requests to match, based on a hash of a request attribute.
+	Percentage *WRRPercentage `json:"percentage,omitempty" toml:"percentage,omitempty" yaml:"percentage,omitempty" export:"true"`
+}
+
+// +k8s:deepcopy-gen=true
+
+// WRRPercentage holds the configuration of a hash-based percentage match.
+// Exactly one of Header or Cookie must be set, and holds the value (e.g. a user ID) being hashed,
+// so that a given value is always routed to the same service.
+type WRRPercentage struct {
+	// Header defines the name of the request header holding the hashed value.
+	Header string `json:"header,omitempty" toml:"header,omitempty" yaml:"header,omitempty" export:"true"`
+	// Cookie defines the name of the request cookie holding the hashed value.
+	Cookie string `json:"cookie,omitempty" toml:"cookie,omitempty" yaml:"cookie,omitempty" export:"true"`
+	// Value defines the percentage (between 0 and 100) of the hashed values to match.
+	Value int `json:"value,omitempty" toml:"value,omitempty" yaml:"value,omitempty" export:"true"`
+}
+
+// +k8s:deepcopy-gen=true
+
 // Sticky holds the sticky configuration.
 type Sticky struct {
 	// Cookie defines the sticky cookie configuration.
@@ -149,6 +201,8 @@ type Cookie struct {
 type ServersLoadBalancer struct {
 	Sticky  *Sticky  `json:"sticky,omitempty" toml:"sticky,omitempty" yaml:"sticky,omitempty" label:"allowEmpty" file:"allowEmpty" kv:"allowEmpty" export:"true"`
 	Servers []Server `json:"servers,omitempty" toml:"servers,omitempty" yaml:"servers,omitempty" label-slice-as-struct:"server" export:"true"`
+	// DNSServers defines servers discovered through periodic DNS lookups, in addition to the static Servers.
+	DNSServers []DNSServer `json:"dnsServers,omitempty" toml:"dnsServers,omitempty" yaml:"dnsServers,omitempty" label:"-" export:"true"`
 	// HealthCheck enables regular active checks of the responsiveness of the
 	// children servers of this load-balancer. To propagate status changes (e.g. all
 	// servers of this service are down) upwards, HealthCheck must also be enabled on
@@ -210,6 +264,34 @@ func (s *Server) SetDefaults() {
 
 // +k8s:deepcopy-gen=true
 
+// DNSServer holds the configuration of servers discovered through periodic DNS lookups.
+// It is shared by the HTTP, TCP and UDP load-balancers.
+type DNSServer struct {
+	// Name defines the DNS name to look up.
+	Name string `json:"name,omitempty" toml:"name,omitempty" yaml:"name,omitempty" export:"true"`
+	// RecordType defines the type of the DNS records to look up: A, AAAA or SRV. Default is A.
+	RecordType string `json:"recordType,omitempty" toml:"recordType,omitempty" yaml:"recordType,omitempty" export:"true"`
+	// Port defines the port of the discovered servers.
+	// It is mandatory for A and AAAA records, whereas SRV records provide their own ports.
+	Port int `json:"port,omitempty" toml:"port,omitempty,omitzero" yaml:"port,omitempty" export:"true"`
+	// Scheme defines the scheme used to reach the discovered servers of an HTTP service. Default is http.
+	Scheme string `json:"scheme,omitempty" toml:"scheme,omitempty" yaml:"scheme,omitempty" export:"true"`
+	// Nameserver defines the address (host:port) of the DNS server to query.
+	// Default is the first nameserver of the system resolver configuration.
+	Nameserver string `json:"nameserver,omitempty" toml:"nameserver,omitempty" yaml:"nameserver,omitempty" export:"true"`
+	// RefreshInterval defines the maximum duration between two lookups.
+	// The lookups happen earlier when the TTL of the records expires. Default is 30s.
+	RefreshInterval ptypes.Duration `json:"refreshInterval,omitempty" toml:"refreshInterval,omitempty" yaml:"refreshInterval,omitempty" export:"true"`
+}
+
+// SetDefaults Default values for a DNSServer.
+func (d *DNSServer) SetDefaults() {
+	d.RecordType = "A"
+	d.RefreshInterval = ptypes.Duration(30 * time.Second)
+}
+
+// +k8s:deepcopy-gen=true
+
 // ServerHealthCheck holds the HealthCheck configuration.
 type ServerHealthCheck struct {
 	Scheme string `json:"scheme,omitempty" toml:"scheme,omitempty" yaml:"scheme,omitempty" export:"true"`
@@ -247,6 +329,19 @@ type ServersTransport struct {
 	ForwardingTimeouts  *ForwardingTimeouts        `description:"Timeouts for requests forwarded to the backend servers." json:"forwardingTimeouts,omitempty" toml:"forwardingTimeouts,omitempty" yaml:"forwardingTimeouts,omitempty" export:"true"`
 	DisableHTTP2        bool                       `description:"Disable HTTP/2 for connections with backend servers." json:"disableHTTP2,omitempty" toml:"disableHTTP2,omitempty" yaml:"disableHTTP2,omitempty" export:"true"`
 	PeerCertURI         string                     `description:"URI used to match against SAN URI during the peer certificate verification." json:"peerCertURI,omitempty" toml:"peerCertURI,omitempty" yaml:"peerCertURI,omitempty" export:"true"`
+	Spiffe              *Spiffe                    `description:"Defines the SPIFFE configuration." json:"spiffe,omitempty" toml:"spiffe,omitempty" yaml:"spiffe,omitempty" label:"allowEmpty" file:"allowEmpty" kv:"allowEmpty" export:"true"`
+}
+
+// +k8s:deepcopy-gen=true
+
+// Spiffe holds the SPIFFE configuration used to connect to the servers.
+// The servers are authenticated with the trust bundle fetched from the SPIFFE Workload API,
+// and Traefik presents its own X.509 SVID to the servers.
+type Spiffe struct {
+	// IDs defines the allowed SPIFFE IDs of the servers (takes precedence over the SPIFFE TrustDomain).
+	IDs []string `description:"Defines the allowed SPIFFE IDs (takes precedence over the SPIFFE TrustDomain)." json:"ids,omitempty" toml:"ids,omitempty" yaml:"ids,omitempty"`
+	// TrustDomain defines the allowed SPIFFE trust domain of the servers.
+	TrustDomain string `description:"Defines the allowed SPIFFE trust domain." json:"trustDomain,omitempty" toml:"trustDomain,omitempty" yaml:"trustDomain,omitempty"`
 }
 
 // +k8s:deepcopy-gen=true
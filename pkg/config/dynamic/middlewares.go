@@ -27,11 +27,22 @@ type Middleware struct {
 	BasicAuth         *BasicAuth         `json:"basicAuth,omitempty" toml:"basicAuth,omitempty" yaml:"basicAuth,omitempty" export:"true"`
 	DigestAuth        *DigestAuth        `json:"digestAuth,omitempty" toml:"digestAuth,omitempty" yaml:"digestAuth,omitempty" export:"true"`
 	ForwardAuth       *ForwardAuth       `json:"forwardAuth,omitempty" toml:"forwardAuth,omitempty" yaml:"forwardAuth,omitempty" export:"true"`
+	JWTAuth           *JWTAuth           `json:"jwtAuth,omitempty" toml:"jwtAuth,omitempty" yaml:"jwtAuth,omitempty" export:"true"`
+	OIDCAuth          *OIDCAuth          `json:"oidcAuth,omitempty" toml:"oidcAuth,omitempty" yaml:"oidcAuth,omitempty" export:"true"`
+	APIKeyAuth        *APIKeyAuth        `json:"apiKeyAuth,omitempty" toml:"apiKeyAuth,omitempty" yaml:"apiKeyAuth,omitempty" export:"true"`
+	HMACAuth          *HMACAuth          `json:"hmacAuth,omitempty" toml:"hmacAuth,omitempty" yaml:"hmacAuth,omitempty" export:"true"`
 	InFlightReq       *InFlightReq       `json:"inFlightReq,omitempty" toml:"inFlightReq,omitempty" yaml:"inFlightReq,omitempty" export:"true"`
 	Buffering         *Buffering         `json:"buffering,omitempty" toml:"buffering,omitempty" yaml:"buffering,omitempty" export:"true"`
 	CircuitBreaker    *CircuitBreaker    `json:"circuitBreaker,omitempty" toml:"circuitBreaker,omitempty" yaml:"circuitBreaker,omitempty" export:"true"`
 	Compress          *Compress          `json:"compress,omitempty" toml:"compress,omitempty" yaml:"compress,omitempty" label:"allowEmpty" file:"allowEmpty" kv:"allowEmpty" export:"true"`
 	PassTLSClientCert *PassTLSClientCert `json:"passTLSClientCert,omitempty" toml:"passTLSClientCert,omitempty" yaml:"passTLSClientCert,omitempty" export:"true"`
+	TLSClientCertAuth *TLSClientCertAuth `json:"tlsClientCertAuth,omitempty" toml:"tlsClientCertAuth,omitempty" yaml:"tlsClientCertAuth,omitempty" export:"true"`
+	WAF               *WAF               `json:"waf,omitempty" toml:"waf,omitempty" yaml:"waf,omitempty" export:"true"`
+	BodyLimit         *BodyLimit         `json:"bodyLimit,omitempty" toml:"bodyLimit,omitempty" yaml:"bodyLimit,omitempty" export:"true"`
+	RequestValidation *RequestValidation `json:"requestValidation,omitempty" toml:"requestValidation,omitempty" yaml:"requestValidation,omitempty" export:"true"`
+	GeoIP             *GeoIP             `json:"geoIP,omitempty" toml:"geoIP,omitempty" yaml:"geoIP,omitempty" export:"true"`
+	IPDenyList        *IPDenyList        `json:"ipDenyList,omitempty" toml:"ipDenyList,omitempty" yaml:"ipDenyList,omitempty" export:"true"`
+	Challenge         *Challenge         `json:"challenge,omitempty" toml:"challenge,omitempty" yaml:"challenge,omitempty" export:"true"`
 	Retry             *Retry             `json:"retry,omitempty" toml:"retry,omitempty" yaml:"retry,omitempty" export:"true"`
 	ContentType       *ContentType       `json:"contentType,omitempty" toml:"contentType,omitempty" yaml:"contentType,omitempty" export:"true"`
 	Cache             *Cache             `json:"cache,omitempty" toml:"cache,omitempty" yaml:"cache,omitempty" label:"allowEmpty" file:"allowEmpty" kv:"allowEmpty" export:"true"`
@@ -70,6 +81,40 @@ type AddPrefix struct {
 
 // +k8s:deepcopy-gen=true
 
+// APIKeyAuth holds the API key authentication middleware configuration.
+// This middleware verifies the API keys of the requests against hashed keys.
+// More info: https://doc.traefik.io/traefik/v2.8/middlewares/http/apikeyauth/
+type APIKeyAuth struct {
+	// Keys defines the authorized API keys.
+	// Each key must be declared using the consumer:sha256-digest[:metadata] format,
+	// where the metadata is a comma-separated list of name=value pairs.
+	Keys []string `json:"keys,omitempty" toml:"keys,omitempty" yaml:"keys,omitempty" loggable:"false"`
+	// KeysFile is the path to an external file that contains the authorized API keys.
+	// The file is reloaded when it changes.
+	KeysFile string `json:"keysFile,omitempty" toml:"keysFile,omitempty" yaml:"keysFile,omitempty"`
+	// HeaderName defines the name of the header holding the API key.
+	// Default: X-API-Key.
+	HeaderName string `json:"headerName,omitempty" toml:"headerName,omitempty" yaml:"headerName,omitempty" export:"true"`
+	// QueryParam defines the name of the query parameter holding the API key.
+	QueryParam string `json:"queryParam,omitempty" toml:"queryParam,omitempty" yaml:"queryParam,omitempty" export:"true"`
+	// CookieName defines the name of the cookie holding the API key.
+	CookieName string `json:"cookieName,omitempty" toml:"cookieName,omitempty" yaml:"cookieName,omitempty" export:"true"`
+	// RemoveKey defines whether to remove the API key from the request before forwarding it to the service.
+	RemoveKey bool `json:"removeKey,omitempty" toml:"removeKey,omitempty" yaml:"removeKey,omitempty" export:"true"`
+	// ConsumerHeader defines the header set on the forwarded requests with the consumer name of the API key.
+	ConsumerHeader string `json:"consumerHeader,omitempty" toml:"consumerHeader,omitempty" yaml:"consumerHeader,omitempty" export:"true"`
+	// MetadataHeaders defines the headers set on the forwarded requests from the metadata of the API key.
+	// The key is the header name, and the value is the metadata name.
+	MetadataHeaders map[string]string `json:"metadataHeaders,omitempty" toml:"metadataHeaders,omitempty" yaml:"metadataHeaders,omitempty" export:"true"`
+}
+
+// SetDefaults sets the default values on an APIKeyAuth.
+func (a *APIKeyAuth) SetDefaults() {
+	a.HeaderName = "X-API-Key"
+}
+
+// +k8s:deepcopy-gen=true
+
 // BasicAuth holds the basic auth middleware configuration.
 // This middleware restricts access to your services to known users.
 // More info: https://doc.traefik.io/traefik/v2.8/middlewares/http/basicauth/
@@ -93,6 +138,18 @@ type BasicAuth struct {
 
 // +k8s:deepcopy-gen=true
 
+// BodyLimit holds the body limit middleware configuration.
+// This middleware limits the size of the request bodies, without buffering them.
+// More info: https://doc.traefik.io/traefik/v2.8/middlewares/http/bodylimit/
+type BodyLimit struct {
+	// MaxBodySize defines the maximum size in bytes of the request bodies.
+	// The requests announcing a larger body are refused, and the reading of a body is interrupted as soon as it crosses the limit.
+	// The client then gets a 413 (Request Entity Too Large) response.
+	MaxBodySize int64 `json:"maxBodySize,omitempty" toml:"maxBodySize,omitempty" yaml:"maxBodySize,omitempty" export:"true"`
+}
+
+// +k8s:deepcopy-gen=true
+
 // Buffering holds the buffering middleware configuration.
 // This middleware retries or limits the size of requests that can be forwarded to backends.
 // More info: https://doc.traefik.io/traefik/v2.8/middlewares/http/buffering/#maxrequestbodybytes
@@ -128,6 +185,81 @@ type Chain struct {
 
 // +k8s:deepcopy-gen=true
 
+// Challenge holds the challenge middleware configuration.
+// This middleware serves a proof-of-work or JavaScript challenge page to the matching requests without a clearance cookie,
+// and grants a signed clearance cookie to the clients solving it.
+// More info: https://doc.traefik.io/traefik/v2.8/middlewares/http/challenge/
+type Challenge struct {
+	// Secret defines the secret signing the challenges and the clearance cookies, which must be at least 32 characters long.
+	Secret string `json:"secret,omitempty" toml:"secret,omitempty" yaml:"secret,omitempty" loggable:"false"`
+	// Mode defines the challenge: proofOfWork (the client computes a proof-of-work in JavaScript),
+	// or javascript (the client only has to run JavaScript).
+	// Default: proofOfWork.
+	Mode string `json:"mode,omitempty" toml:"mode,omitempty" yaml:"mode,omitempty" export:"true"`
+	// Difficulty defines the number of leading zero bits of the proof-of-work hash.
+	// Default: 16.
+	Difficulty int `json:"difficulty,omitempty" toml:"difficulty,omitempty" yaml:"difficulty,omitempty" export:"true"`
+	// UserAgents defines the regular expressions matching the suspicious user agents, which are challenged.
+	UserAgents []string `json:"userAgents,omitempty" toml:"userAgents,omitempty" yaml:"userAgents,omitempty" export:"true"`
+	// RequestRate defines the request rate of a client IP above which its requests are challenged.
+	RequestRate *ChallengeRequestRate `json:"requestRate,omitempty" toml:"requestRate,omitempty" yaml:"requestRate,omitempty" export:"true"`
+	IPStrategy  *IPStrategy           `json:"ipStrategy,omitempty" toml:"ipStrategy,omitempty" yaml:"ipStrategy,omitempty"  label:"allowEmpty" file:"allowEmpty" kv:"allowEmpty" export:"true"`
+	// BindTo defines what the clearance cookies are bound to: ip, userAgent, or both.
+	// Default: ip.
+	BindTo []string `json:"bindTo,omitempty" toml:"bindTo,omitempty" yaml:"bindTo,omitempty" export:"true"`
+	// VerifyPath defines the path receiving the solutions of the challenges.
+	// Default: /_traefik/challenge.
+	VerifyPath string `json:"verifyPath,omitempty" toml:"verifyPath,omitempty" yaml:"verifyPath,omitempty" export:"true"`
+	// ChallengeTTL defines how long a challenge can be solved.
+	// Default: 5m.
+	ChallengeTTL ptypes.Duration `json:"challengeTTL,omitempty" toml:"challengeTTL,omitempty" yaml:"challengeTTL,omitempty" export:"true"`
+	// ClearanceTTL defines how long a clearance cookie is valid.
+	// Default: 1h.
+	ClearanceTTL ptypes.Duration `json:"clearanceTTL,omitempty" toml:"clearanceTTL,omitempty" yaml:"clearanceTTL,omitempty" export:"true"`
+	// CookieName defines the name of the clearance cookie.
+	// Default: _traefik_clearance.
+	CookieName string `json:"cookieName,omitempty" toml:"cookieName,omitempty" yaml:"cookieName,omitempty" export:"true"`
+	// CookieDomain defines the domain of the clearance cookie.
+	CookieDomain string `json:"cookieDomain,omitempty" toml:"cookieDomain,omitempty" yaml:"cookieDomain,omitempty" export:"true"`
+	// CookiePath defines the path of the clearance cookie.
+	// Default: /.
+	CookiePath string `json:"cookiePath,omitempty" toml:"cookiePath,omitempty" yaml:"cookiePath,omitempty" export:"true"`
+	// CookieSameSite defines the SameSite attribute of the clearance cookie (none, lax or strict).
+	// Default: lax.
+	CookieSameSite string `json:"cookieSameSite,omitempty" toml:"cookieSameSite,omitempty" yaml:"cookieSameSite,omitempty" export:"true"`
+}
+
+// SetDefaults sets the default values on a Challenge.
+func (c *Challenge) SetDefaults() {
+	c.Mode = "proofOfWork"
+	c.Difficulty = 16
+	c.BindTo = []string{"ip"}
+	c.VerifyPath = "/_traefik/challenge"
+	c.ChallengeTTL = ptypes.Duration(5 * time.Minute)
+	c.ClearanceTTL = ptypes.Duration(time.Hour)
+	c.CookieName = "_traefik_clearance"
+	c.CookiePath = "/"
+	c.CookieSameSite = "lax"
+}
+
+// +k8s:deepcopy-gen=true
+
+// ChallengeRequestRate holds the request rate above which the requests of a client IP are challenged.
+type ChallengeRequestRate struct {
+	// Average defines the number of requests of a client IP allowed per period without being challenged.
+	Average int64 `json:"average,omitempty" toml:"average,omitempty" yaml:"average,omitempty" export:"true"`
+	// Period defines the period during which the requests are counted.
+	// Default: 1s.
+	Period ptypes.Duration `json:"period,omitempty" toml:"period,omitempty" yaml:"period,omitempty" export:"true"`
+}
+
+// SetDefaults sets the default values on a ChallengeRequestRate.
+func (r *ChallengeRequestRate) SetDefaults() {
+	r.Period = ptypes.Duration(time.Second)
+}
+
+// +k8s:deepcopy-gen=true
+
 // CircuitBreaker holds the circuit breaker middleware configuration.
 // This middleware protects the system from stacking requests to unhealthy services, resulting in cascading failures.
 // More info: https://doc.traefik.io/traefik/v2.8/middlewares/http/circuitbreaker/
@@ -221,6 +353,77 @@ type ForwardAuth struct {
 	// AuthRequestHeaders defines the list of the headers to copy from the request to the authentication server.
 	// If not set or empty then all request headers are passed.
 	AuthRequestHeaders []string `json:"authRequestHeaders,omitempty" toml:"authRequestHeaders,omitempty" yaml:"authRequestHeaders,omitempty" export:"true"`
+	// ForwardBody defines whether to forward the request body to the authentication server, with the method of the request.
+	ForwardBody bool `json:"forwardBody,omitempty" toml:"forwardBody,omitempty" yaml:"forwardBody,omitempty" export:"true"`
+	// MaxBodySize defines the maximum size in bytes of the request body forwarded to the authentication server.
+	// Requests with a larger body are refused. Default: 1048576 (1 MiB).
+	MaxBodySize int64 `json:"maxBodySize,omitempty" toml:"maxBodySize,omitempty" yaml:"maxBodySize,omitempty" export:"true"`
+	// Cache defines the caching of the authentication decisions.
+	// More info: https://doc.traefik.io/traefik/v2.8/middlewares/http/forwardauth/#cache
+	Cache *ForwardAuthCache `json:"cache,omitempty" toml:"cache,omitempty" yaml:"cache,omitempty" export:"true"`
+	// FailurePolicy defines how the failures of the authentication server are handled:
+	// passthrough (the response of the authentication server is returned), open (the request is forwarded), or closed (the request is refused).
+	FailurePolicy string `json:"failurePolicy,omitempty" toml:"failurePolicy,omitempty" yaml:"failurePolicy,omitempty" export:"true"`
+	// FailureStatus defines the status codes, or ranges of status codes, of the authentication server responses handled as failures.
+	// Default: 500-599.
+	FailureStatus []string `json:"failureStatus,omitempty" toml:"failureStatus,omitempty" yaml:"failureStatus,omitempty" export:"true"`
+}
+
+// +k8s:deepcopy-gen=true
+
+// ForwardAuthCache holds the forward auth decisions cache configuration.
+type ForwardAuthCache struct {
+	// TTL defines how long the decisions allowing the requests are cached.
+	TTL ptypes.Duration `json:"ttl,omitempty" toml:"ttl,omitempty" yaml:"ttl,omitempty" export:"true"`
+	// DenyTTL defines how long the decisions refusing the requests are cached.
+	// The refusals are not cached by default.
+	DenyTTL ptypes.Duration `json:"denyTTL,omitempty" toml:"denyTTL,omitempty" yaml:"denyTTL,omitempty" export:"true"`
+	// KeyHeaders defines the request headers identifying the decisions, such as Authorization.
+	KeyHeaders []string `json:"keyHeaders,omitempty" toml:"keyHeaders,omitempty" yaml:"keyHeaders,omitempty" export:"true"`
+	// KeyCookies defines the request cookies identifying the decisions.
+	KeyCookies []string `json:"keyCookies,omitempty" toml:"keyCookies,omitempty" yaml:"keyCookies,omitempty" export:"true"`
+	// IgnoreMethodAndURI defines whether the decisions are shared between the methods and URIs of a host.
+	IgnoreMethodAndURI bool `json:"ignoreMethodAndURI,omitempty" toml:"ignoreMethodAndURI,omitempty" yaml:"ignoreMethodAndURI,omitempty" export:"true"`
+	// Store defines where the decisions are cached: memory (default), or memcached to share them between the Traefik instances.
+	Store string `json:"store,omitempty" toml:"store,omitempty" yaml:"store,omitempty" export:"true"`
+	// MaxEntries defines the maximum number of decisions cached in memory. Default: 10000.
+	MaxEntries int `json:"maxEntries,omitempty" toml:"maxEntries,omitempty" yaml:"maxEntries,omitempty" export:"true"`
+}
+
+// +k8s:deepcopy-gen=true
+
+// GeoIP holds the GeoIP middleware configuration.
+// This middleware allows or denies the requests by the location of their source IP, found in MaxMind databases,
+// and forwards this location to the services.
+// More info: https://doc.traefik.io/traefik/v2.8/middlewares/http/geoip/
+type GeoIP struct {
+	// DatabaseFiles defines the paths to the MaxMind DB (mmdb) files, such as the GeoLite2 City and ASN databases.
+	// The databases are looked up in order, and are reloaded when they change.
+	DatabaseFiles []string `json:"databaseFiles,omitempty" toml:"databaseFiles,omitempty" yaml:"databaseFiles,omitempty" export:"true"`
+	// AllowedCountries defines the ISO 3166-1 alpha-2 codes of the allowed countries, such as FR.
+	AllowedCountries []string `json:"allowedCountries,omitempty" toml:"allowedCountries,omitempty" yaml:"allowedCountries,omitempty" export:"true"`
+	// DeniedCountries defines the ISO 3166-1 alpha-2 codes of the denied countries.
+	DeniedCountries []string `json:"deniedCountries,omitempty" toml:"deniedCountries,omitempty" yaml:"deniedCountries,omitempty" export:"true"`
+	// AllowedContinents defines the codes of the allowed continents, such as EU.
+	AllowedContinents []string `json:"allowedContinents,omitempty" toml:"allowedContinents,omitempty" yaml:"allowedContinents,omitempty" export:"true"`
+	// DeniedContinents defines the codes of the denied continents.
+	DeniedContinents []string `json:"deniedContinents,omitempty" toml:"deniedContinents,omitempty" yaml:"deniedContinents,omitempty" export:"true"`
+	// AllowedASNs defines the allowed autonomous system numbers.
+	AllowedASNs []int `json:"allowedASNs,omitempty" toml:"allowedASNs,omitempty" yaml:"allowedASNs,omitempty" export:"true"`
+	// DeniedASNs defines the denied autonomous system numbers.
+	DeniedASNs []int `json:"deniedASNs,omitempty" toml:"deniedASNs,omitempty" yaml:"deniedASNs,omitempty" export:"true"`
+	// AllowUnknown defines whether the requests from a source IP not found in the databases, such as a private IP,
+	// are allowed when allow lists are defined.
+	AllowUnknown bool `json:"allowUnknown,omitempty" toml:"allowUnknown,omitempty" yaml:"allowUnknown,omitempty" export:"true"`
+	// CountryHeader defines the header set on the forwarded requests with the country code of the source IP.
+	CountryHeader string `json:"countryHeader,omitempty" toml:"countryHeader,omitempty" yaml:"countryHeader,omitempty" export:"true"`
+	// ContinentHeader defines the header set on the forwarded requests with the continent code of the source IP.
+	ContinentHeader string `json:"continentHeader,omitempty" toml:"continentHeader,omitempty" yaml:"continentHeader,omitempty" export:"true"`
+	// CityHeader defines the header set on the forwarded requests with the English name of the city of the source IP.
+	CityHeader string `json:"cityHeader,omitempty" toml:"cityHeader,omitempty" yaml:"cityHeader,omitempty" export:"true"`
+	// ASNHeader defines the header set on the forwarded requests with the autonomous system number of the source IP.
+	ASNHeader  string      `json:"asnHeader,omitempty" toml:"asnHeader,omitempty" yaml:"asnHeader,omitempty" export:"true"`
+	IPStrategy *IPStrategy `json:"ipStrategy,omitempty" toml:"ipStrategy,omitempty" yaml:"ipStrategy,omitempty"  label:"allowEmpty" file:"allowEmpty" kv:"allowEmpty" export:"true"`
 }
 
 // +k8s:deepcopy-gen=true
@@ -351,6 +554,62 @@ func (h *Headers) HasSecureHeadersDefined() bool {
 
 // +k8s:deepcopy-gen=true
 
+// HMACAuth holds the HMAC signature authentication middleware configuration.
+// This middleware verifies the HMAC signatures of the requests, computed with shared secrets.
+// More info: https://doc.traefik.io/traefik/v2.8/middlewares/http/hmacauth/
+type HMACAuth struct {
+	// Secrets defines the shared secrets, using the keyID:secret format.
+	Secrets []string `json:"secrets,omitempty" toml:"secrets,omitempty" yaml:"secrets,omitempty" loggable:"false"`
+	// SecretsFile is the path to an external file that contains the shared secrets.
+	SecretsFile string `json:"secretsFile,omitempty" toml:"secretsFile,omitempty" yaml:"secretsFile,omitempty"`
+	// Scheme defines how the requests are signed: body (the signature of the body is sent in a header),
+	// or canonical (the signature of the canonical request is sent in the Authorization header).
+	// Default: body.
+	Scheme string `json:"scheme,omitempty" toml:"scheme,omitempty" yaml:"scheme,omitempty" export:"true"`
+	// Algorithm defines the hash algorithm of the signatures: sha1, sha256, or sha512.
+	// Default: sha256.
+	Algorithm string `json:"algorithm,omitempty" toml:"algorithm,omitempty" yaml:"algorithm,omitempty" export:"true"`
+	// SignatureHeader defines the header holding the signature, for the body scheme.
+	// Default: X-Signature.
+	SignatureHeader string `json:"signatureHeader,omitempty" toml:"signatureHeader,omitempty" yaml:"signatureHeader,omitempty" export:"true"`
+	// SignaturePrefix defines the prefix of the signature in the signature header, such as sha256=, for the body scheme.
+	SignaturePrefix string `json:"signaturePrefix,omitempty" toml:"signaturePrefix,omitempty" yaml:"signaturePrefix,omitempty" export:"true"`
+	// SignatureEncoding defines the encoding of the signature, for the body scheme: hex, or base64.
+	// Default: hex.
+	SignatureEncoding string `json:"signatureEncoding,omitempty" toml:"signatureEncoding,omitempty" yaml:"signatureEncoding,omitempty" export:"true"`
+	// SignedHeaders defines the headers that must be signed, for the canonical scheme.
+	SignedHeaders []string `json:"signedHeaders,omitempty" toml:"signedHeaders,omitempty" yaml:"signedHeaders,omitempty" export:"true"`
+	// TimestampHeader defines the header holding the signing time of the request, which is signed.
+	// It is required for the canonical scheme.
+	TimestampHeader string `json:"timestampHeader,omitempty" toml:"timestampHeader,omitempty" yaml:"timestampHeader,omitempty" export:"true"`
+	// MaxSkew defines the maximum difference between the signing time of the requests and the current time.
+	// Default: 5m.
+	MaxSkew ptypes.Duration `json:"maxSkew,omitempty" toml:"maxSkew,omitempty" yaml:"maxSkew,omitempty" export:"true"`
+	// NonceHeader defines the header holding the nonce of the request, which is signed.
+	// Each nonce is only accepted once, which protects against the replay of the requests.
+	NonceHeader string `json:"nonceHeader,omitempty" toml:"nonceHeader,omitempty" yaml:"nonceHeader,omitempty" export:"true"`
+	// NonceStore defines where the nonces are remembered: memory (default), which only detects the requests replayed to the same Traefik instance,
+	// or memcached to share them between the Traefik instances.
+	NonceStore string `json:"nonceStore,omitempty" toml:"nonceStore,omitempty" yaml:"nonceStore,omitempty" export:"true"`
+	// MaxBodySize defines the maximum size in bytes of the signed request bodies.
+	// Requests with a larger body are refused. Default: 1048576 (1 MiB).
+	MaxBodySize int64 `json:"maxBodySize,omitempty" toml:"maxBodySize,omitempty" yaml:"maxBodySize,omitempty" export:"true"`
+	// KeyIDHeader defines the header set on the forwarded requests with the key ID of the secret verifying the signature.
+	KeyIDHeader string `json:"keyIDHeader,omitempty" toml:"keyIDHeader,omitempty" yaml:"keyIDHeader,omitempty" export:"true"`
+}
+
+// SetDefaults sets the default values on an HMACAuth.
+func (h *HMACAuth) SetDefaults() {
+	h.Scheme = "body"
+	h.Algorithm = "sha256"
+	h.SignatureHeader = "X-Signature"
+	h.SignatureEncoding = "hex"
+	h.MaxSkew = ptypes.Duration(5 * time.Minute)
+	h.MaxBodySize = 1 << 20
+}
+
+// +k8s:deepcopy-gen=true
+
 // IPStrategy holds the IP strategy configuration used by Traefik to determine the client IP.
 // More info: https://doc.traefik.io/traefik/v2.8/middlewares/http/ipwhitelist/#ipstrategy
 type IPStrategy struct {
@@ -391,6 +650,55 @@ func (s *IPStrategy) Get() (ip.Strategy, error) {
 
 // +k8s:deepcopy-gen=true
 
+// IPDenyList holds the IP deny list middleware configuration.
+// This middleware refuses the requests from the denied IPs, listed in the configuration, in files, or at URLs,
+// and from the IPs banned after too many matching responses.
+// More info: https://doc.traefik.io/traefik/v2.8/middlewares/http/ipdenylist/
+type IPDenyList struct {
+	// SourceRange defines the denied IPs (or ranges of denied IPs by using CIDR notation).
+	SourceRange []string `json:"sourceRange,omitempty" toml:"sourceRange,omitempty" yaml:"sourceRange,omitempty"`
+	// Sources defines the files, or the HTTP(S) URLs, listing the denied IPs or ranges, one per line.
+	Sources []string `json:"sources,omitempty" toml:"sources,omitempty" yaml:"sources,omitempty"`
+	// RefreshInterval defines the interval between two reloads of the sources. Default: 10m.
+	RefreshInterval ptypes.Duration `json:"refreshInterval,omitempty" toml:"refreshInterval,omitempty" yaml:"refreshInterval,omitempty" export:"true"`
+	IPStrategy      *IPStrategy     `json:"ipStrategy,omitempty" toml:"ipStrategy,omitempty" yaml:"ipStrategy,omitempty"  label:"allowEmpty" file:"allowEmpty" kv:"allowEmpty" export:"true"`
+	// BanList defines the name of the ban list whose IPs are refused.
+	// The ban lists are shared by the HTTP and TCP deny list middlewares.
+	BanList string `json:"banList,omitempty" toml:"banList,omitempty" yaml:"banList,omitempty" export:"true"`
+	// BanStore defines where the bans are stored: memory (default), or memcached to share them between the Traefik instances.
+	BanStore string `json:"banStore,omitempty" toml:"banStore,omitempty" yaml:"banStore,omitempty" export:"true"`
+	// Ban defines the responses banning the IPs into the ban list.
+	Ban *IPBan `json:"ban,omitempty" toml:"ban,omitempty" yaml:"ban,omitempty" export:"true"`
+}
+
+// SetDefaults sets the default values on an IPDenyList.
+func (d *IPDenyList) SetDefaults() {
+	d.RefreshInterval = ptypes.Duration(10 * time.Minute)
+}
+
+// +k8s:deepcopy-gen=true
+
+// IPBan holds the configuration banning the IPs after too many matching responses.
+type IPBan struct {
+	// StatusCodes defines the status codes, or ranges of status codes, of the responses counted to ban an IP, such as 401 or 500-599.
+	StatusCodes []string `json:"statusCodes,omitempty" toml:"statusCodes,omitempty" yaml:"statusCodes,omitempty" export:"true"`
+	// MaxResponses defines the number of counted responses to the requests from an IP, within the window, banning the IP. Default: 5.
+	MaxResponses int `json:"maxResponses,omitempty" toml:"maxResponses,omitempty" yaml:"maxResponses,omitempty" export:"true"`
+	// Window defines the period during which the responses are counted. Default: 1m.
+	Window ptypes.Duration `json:"window,omitempty" toml:"window,omitempty" yaml:"window,omitempty" export:"true"`
+	// Duration defines how long the IPs are banned. Default: 10m.
+	Duration ptypes.Duration `json:"duration,omitempty" toml:"duration,omitempty" yaml:"duration,omitempty" export:"true"`
+}
+
+// SetDefaults sets the default values on an IPBan.
+func (b *IPBan) SetDefaults() {
+	b.MaxResponses = 5
+	b.Window = ptypes.Duration(time.Minute)
+	b.Duration = ptypes.Duration(10 * time.Minute)
+}
+
+// +k8s:deepcopy-gen=true
+
 // IPWhiteList holds the IP whitelist middleware configuration.
 // This middleware accepts / refuses requests based on the client IP.
 // More info: https://doc.traefik.io/traefik/v2.8/middlewares/http/ipwhitelist/
@@ -418,6 +726,120 @@ type InFlightReq struct {
 
 // +k8s:deepcopy-gen=true
 
+// JWTAuth holds the JWT authentication middleware configuration.
+// This middleware verifies the JSON Web Token (JWT) bearer token of the requests.
+// More info: https://doc.traefik.io/traefik/v2.8/middlewares/http/jwtauth/
+type JWTAuth struct {
+	// Keys defines the keys verifying the token signatures, as PEM encoded public keys or certificates (RSA, ECDSA or Ed25519), or as JSON Web Key Sets.
+	Keys []string `json:"keys,omitempty" toml:"keys,omitempty" yaml:"keys,omitempty"`
+	// Secret defines the shared secret verifying the tokens signed with an HMAC algorithm (HS256, HS384 or HS512).
+	Secret string `json:"secret,omitempty" toml:"secret,omitempty" yaml:"secret,omitempty" loggable:"false"`
+	// JWKSURL defines the URL of the JSON Web Key Set verifying the token signatures.
+	JWKSURL string `json:"jwksURL,omitempty" toml:"jwksURL,omitempty" yaml:"jwksURL,omitempty"`
+	// JWKSRefreshInterval defines the interval between two fetches of the JSON Web Key Set.
+	// The set is also fetched again when a token references an unknown key.
+	// Default: 15m.
+	JWKSRefreshInterval ptypes.Duration `json:"jwksRefreshInterval,omitempty" toml:"jwksRefreshInterval,omitempty" yaml:"jwksRefreshInterval,omitempty" export:"true"`
+	// Algorithms defines the allowed signature algorithms.
+	// Default: all the algorithms matching the configured keys.
+	Algorithms []string `json:"algorithms,omitempty" toml:"algorithms,omitempty" yaml:"algorithms,omitempty" export:"true"`
+	// Issuers defines the allowed issuers (iss claim) of the tokens.
+	Issuers []string `json:"issuers,omitempty" toml:"issuers,omitempty" yaml:"issuers,omitempty" export:"true"`
+	// Audiences defines the allowed audiences (aud claim) of the tokens, a token having to be issued for at least one of them.
+	Audiences []string `json:"audiences,omitempty" toml:"audiences,omitempty" yaml:"audiences,omitempty" export:"true"`
+	// ClockSkew defines the tolerated clock skew when checking the exp, nbf and iat claims.
+	// Default: 0s.
+	ClockSkew ptypes.Duration `json:"clockSkew,omitempty" toml:"clockSkew,omitempty" yaml:"clockSkew,omitempty" export:"true"`
+	// RequireExp defines whether the tokens must have an expiration time (exp claim).
+	// Default: true.
+	RequireExp bool `json:"requireExp,omitempty" toml:"requireExp,omitempty" yaml:"requireExp,omitempty" export:"true"`
+	// RequiredClaims defines the claims the tokens must have.
+	// A claim must be equal to the given value, or contain it if the claim is an array, unless the value is empty.
+	RequiredClaims map[string]string `json:"requiredClaims,omitempty" toml:"requiredClaims,omitempty" yaml:"requiredClaims,omitempty" export:"true"`
+	// RequiredScopes defines the scopes the tokens must have, in their scope or scp claim.
+	RequiredScopes []string `json:"requiredScopes,omitempty" toml:"requiredScopes,omitempty" yaml:"requiredScopes,omitempty" export:"true"`
+	// ClaimsHeaders defines the headers set on the forwarded requests from the token claims.
+	// The key is the header name, and the value is the claim name, the names of nested claims being separated by dots.
+	ClaimsHeaders map[string]string `json:"claimsHeaders,omitempty" toml:"claimsHeaders,omitempty" yaml:"claimsHeaders,omitempty" export:"true"`
+	// RemoveHeader defines whether to remove the authorization header before forwarding the request to the backend.
+	RemoveHeader bool `json:"removeHeader,omitempty" toml:"removeHeader,omitempty" yaml:"removeHeader,omitempty" export:"true"`
+}
+
+// SetDefaults sets the default values on a JWTAuth.
+func (j *JWTAuth) SetDefaults() {
+	j.JWKSRefreshInterval = ptypes.Duration(15 * time.Minute)
+	j.RequireExp = true
+}
+
+// +k8s:deepcopy-gen=true
+
+// OIDCAuth holds the OpenID Connect authentication middleware configuration.
+// This middleware authenticates the users with an OpenID Connect provider, and keeps their session in cookies.
+// More info: https://doc.traefik.io/traefik/v2.8/middlewares/http/oidcauth/
+type OIDCAuth struct {
+	// IssuerURL defines the URL of the OpenID Connect provider, from which its configuration is discovered.
+	IssuerURL string `json:"issuerURL,omitempty" toml:"issuerURL,omitempty" yaml:"issuerURL,omitempty"`
+	// ClientID defines the client identifier registered with the provider.
+	ClientID string `json:"clientID,omitempty" toml:"clientID,omitempty" yaml:"clientID,omitempty"`
+	// ClientSecret defines the client secret registered with the provider.
+	// It can be omitted for the public clients, the authorization code being protected with PKCE.
+	ClientSecret string `json:"clientSecret,omitempty" toml:"clientSecret,omitempty" yaml:"clientSecret,omitempty" loggable:"false"`
+	// Scopes defines the scopes requested to the provider.
+	// Default: openid, profile, email.
+	Scopes []string `json:"scopes,omitempty" toml:"scopes,omitempty" yaml:"scopes,omitempty" export:"true"`
+	// CallbackPath defines the path of the redirection URI handling the authorization responses of the provider.
+	// Default: /oauth2/callback.
+	CallbackPath string `json:"callbackPath,omitempty" toml:"callbackPath,omitempty" yaml:"callbackPath,omitempty" export:"true"`
+	// LogoutPath defines the path ending the session of the users.
+	// Default: /oauth2/logout.
+	LogoutPath string `json:"logoutPath,omitempty" toml:"logoutPath,omitempty" yaml:"logoutPath,omitempty" export:"true"`
+	// PostLogoutRedirectURL defines the URL the users are redirected to after the logout.
+	PostLogoutRedirectURL string `json:"postLogoutRedirectURL,omitempty" toml:"postLogoutRedirectURL,omitempty" yaml:"postLogoutRedirectURL,omitempty" export:"true"`
+	// SessionSecret defines the secret encrypting and signing the session cookies, which must be at least 32 characters long.
+	SessionSecret string `json:"sessionSecret,omitempty" toml:"sessionSecret,omitempty" yaml:"sessionSecret,omitempty" loggable:"false"`
+	// SessionMaxAge defines the maximum lifetime of the sessions, the access tokens being renewed with the refresh tokens in the meantime.
+	// Default: 24h.
+	SessionMaxAge ptypes.Duration `json:"sessionMaxAge,omitempty" toml:"sessionMaxAge,omitempty" yaml:"sessionMaxAge,omitempty" export:"true"`
+	// CookieName defines the name of the session cookie.
+	// Default: _traefik_oidc.
+	CookieName string `json:"cookieName,omitempty" toml:"cookieName,omitempty" yaml:"cookieName,omitempty" export:"true"`
+	// CookieDomain defines the domain of the session cookie.
+	CookieDomain string `json:"cookieDomain,omitempty" toml:"cookieDomain,omitempty" yaml:"cookieDomain,omitempty" export:"true"`
+	// CookiePath defines the path of the session cookie.
+	// Default: /.
+	CookiePath string `json:"cookiePath,omitempty" toml:"cookiePath,omitempty" yaml:"cookiePath,omitempty" export:"true"`
+	// CookieSameSite defines the SameSite attribute of the session cookie (none, lax or strict).
+	// Default: lax.
+	CookieSameSite string `json:"cookieSameSite,omitempty" toml:"cookieSameSite,omitempty" yaml:"cookieSameSite,omitempty" export:"true"`
+	// GroupsClaim defines the claim holding the groups of the users.
+	// Default: groups.
+	GroupsClaim string `json:"groupsClaim,omitempty" toml:"groupsClaim,omitempty" yaml:"groupsClaim,omitempty" export:"true"`
+	// AllowedGroups defines the groups allowed to access the service, a user having to be a member of at least one of them.
+	AllowedGroups []string `json:"allowedGroups,omitempty" toml:"allowedGroups,omitempty" yaml:"allowedGroups,omitempty" export:"true"`
+	// RequiredClaims defines the claims the ID tokens of the users must have.
+	// A claim must be equal to the given value, or contain it if the claim is an array, unless the value is empty.
+	RequiredClaims map[string]string `json:"requiredClaims,omitempty" toml:"requiredClaims,omitempty" yaml:"requiredClaims,omitempty" export:"true"`
+	// ClaimsHeaders defines the headers set on the forwarded requests from the ID token claims.
+	// The key is the header name, and the value is the claim name, the names of nested claims being separated by dots.
+	ClaimsHeaders map[string]string `json:"claimsHeaders,omitempty" toml:"claimsHeaders,omitempty" yaml:"claimsHeaders,omitempty" export:"true"`
+	// ForwardAccessToken defines whether to forward the access token in the Authorization header of the requests.
+	ForwardAccessToken bool `json:"forwardAccessToken,omitempty" toml:"forwardAccessToken,omitempty" yaml:"forwardAccessToken,omitempty" export:"true"`
+}
+
+// SetDefaults sets the default values on a OIDCAuth.
+func (o *OIDCAuth) SetDefaults() {
+	o.Scopes = []string{"openid", "profile", "email"}
+	o.CallbackPath = "/oauth2/callback"
+	o.LogoutPath = "/oauth2/logout"
+	o.SessionMaxAge = ptypes.Duration(24 * time.Hour)
+	o.CookieName = "_traefik_oidc"
+	o.CookiePath = "/"
+	o.CookieSameSite = "lax"
+	o.GroupsClaim = "groups"
+}
+
+// +k8s:deepcopy-gen=true
+
 // PassTLSClientCert holds the pass TLS client cert middleware configuration.
 // This middleware adds the selected data from the passed client TLS certificate to a header.
 // More info: https://doc.traefik.io/traefik/v2.8/middlewares/http/passtlsclientcert/
@@ -631,5 +1053,116 @@ type TLSClientCertificateSubjectDNInfo struct {
 
 // +k8s:deepcopy-gen=true
 
+// TLSClientCertAuth holds the TLS client certificate authorization middleware configuration.
+// This middleware authorizes the requests whose verified TLS client certificate matches one of the rules.
+// More info: https://doc.traefik.io/traefik/v2.8/middlewares/http/tlsclientcertauth/
+type TLSClientCertAuth struct {
+	// Rules defines the rules matched against the verified TLS client certificate.
+	// A request is authorized if its certificate matches at least one rule, and is rejected with a 403 status code otherwise.
+	Rules []TLSClientCertAuthRule `json:"rules,omitempty" toml:"rules,omitempty" yaml:"rules,omitempty" export:"true"`
+}
+
+// +k8s:deepcopy-gen=true
+
+// TLSClientCertAuthRule holds a TLS client certificate authorization rule.
+// A certificate matches the rule if it matches all the criteria defined by the rule.
+// The values of the criteria can contain the * wildcard, which matches any sequence of characters.
+type TLSClientCertAuthRule struct {
+	// Subject defines the criteria matched against the subject distinguished name of the certificate.
+	Subject *TLSClientCertAuthDN `json:"subject,omitempty" toml:"subject,omitempty" yaml:"subject,omitempty" export:"true"`
+	// Issuer defines the criteria matched against the issuer distinguished name of the certificate.
+	Issuer *TLSClientCertAuthDN `json:"issuer,omitempty" toml:"issuer,omitempty" yaml:"issuer,omitempty" export:"true"`
+	// SANURIs defines the allowed URI Subject Alternative Names, such as SPIFFE IDs.
+	// The certificate matches if one of its URI Subject Alternative Names matches one of the values.
+	SANURIs []string `json:"sanURIs,omitempty" toml:"sanURIs,omitempty" yaml:"sanURIs,omitempty" export:"true"`
+}
+
+// +k8s:deepcopy-gen=true
+
+// TLSClientCertAuthDN holds the criteria matched against a certificate distinguished name.
+// For each defined attribute, the distinguished name matches if one of its values matches one of the allowed values.
+type TLSClientCertAuthDN struct {
+	// CommonName defines the allowed common names.
+	CommonName []string `json:"commonName,omitempty" toml:"commonName,omitempty" yaml:"commonName,omitempty" export:"true"`
+	// Organization defines the allowed organizations.
+	Organization []string `json:"organization,omitempty" toml:"organization,omitempty" yaml:"organization,omitempty" export:"true"`
+	// OrganizationalUnit defines the allowed organizational units.
+	OrganizationalUnit []string `json:"organizationalUnit,omitempty" toml:"organizationalUnit,omitempty" yaml:"organizationalUnit,omitempty" export:"true"`
+}
+
+// +k8s:deepcopy-gen=true
+
+// WAF holds the web application firewall middleware configuration.
+// This middleware inspects the requests with a set of rules written in a subset of the ModSecurity SecRule language.
+// More info: https://doc.traefik.io/traefik/v2.8/middlewares/http/waf/
+type WAF struct {
+	// Rules defines the rules, using the SecRule syntax.
+	Rules []string `json:"rules,omitempty" toml:"rules,omitempty" yaml:"rules,omitempty" export:"true"`
+	// RulesFiles defines the paths to external files containing rules, using the SecRule syntax.
+	RulesFiles []string `json:"rulesFiles,omitempty" toml:"rulesFiles,omitempty" yaml:"rulesFiles,omitempty" export:"true"`
+	// Mode defines whether the requests reaching the anomaly threshold, or matching a deny rule, are refused (block),
+	// or only recorded in the access logs and the metrics (detect).
+	// Default: block.
+	Mode string `json:"mode,omitempty" toml:"mode,omitempty" yaml:"mode,omitempty" export:"true"`
+	// AnomalyThreshold defines the anomaly score from which a request is blocked.
+	// Default: 5.
+	AnomalyThreshold int `json:"anomalyThreshold,omitempty" toml:"anomalyThreshold,omitempty" yaml:"anomalyThreshold,omitempty" export:"true"`
+	// MaxBodySize defines the maximum size in bytes of the request body inspected by the rules.
+	// The rest of the body is forwarded without being inspected. Default: 131072 (128 KiB).
+	MaxBodySize int64 `json:"maxBodySize,omitempty" toml:"maxBodySize,omitempty" yaml:"maxBodySize,omitempty" export:"true"`
+	// Exclusions defines the rules, or the rule targets, disabled for some routers.
+	Exclusions []WAFExclusion `json:"exclusions,omitempty" toml:"exclusions,omitempty" yaml:"exclusions,omitempty" export:"true"`
+}
+
+// SetDefaults sets the default values on a WAF.
+func (w *WAF) SetDefaults() {
+	w.Mode = "block"
+	w.AnomalyThreshold = 5
+	w.MaxBodySize = 128 * 1024
+}
+
+// +k8s:deepcopy-gen=true
+
+// WAFExclusion holds a WAF rule exclusion.
+type WAFExclusion struct {
+	// Routers defines the names of the routers the exclusion applies to.
+	// The exclusion applies to all the routers if empty.
+	Routers []string `json:"routers,omitempty" toml:"routers,omitempty" yaml:"routers,omitempty" export:"true"`
+	// RuleIDs defines the IDs, or ID ranges such as 942100-942199, of the excluded rules.
+	// All the rules are excluded if empty.
+	RuleIDs []string `json:"ruleIDs,omitempty" toml:"ruleIDs,omitempty" yaml:"ruleIDs,omitempty" export:"true"`
+	// Targets defines the variables, such as ARGS:password or REQUEST_COOKIES:session, removed from the excluded rules.
+	// The rules are disabled if empty.
+	Targets []string `json:"targets,omitempty" toml:"targets,omitempty" yaml:"targets,omitempty" export:"true"`
+}
+
+// +k8s:deepcopy-gen=true
+
+// RequestValidation holds the request validation middleware configuration.
+// This middleware validates the JSON request bodies against a JSON Schema, or against the operations of an OpenAPI 3 document.
+// More info: https://doc.traefik.io/traefik/v2.8/middlewares/http/requestvalidation/
+type RequestValidation struct {
+	// Schema defines the JSON Schema of the request bodies, in JSON or YAML.
+	Schema string `json:"schema,omitempty" toml:"schema,omitempty" yaml:"schema,omitempty" export:"true"`
+	// SchemaFile defines the path to a file containing the JSON Schema of the request bodies.
+	SchemaFile string `json:"schemaFile,omitempty" toml:"schemaFile,omitempty" yaml:"schemaFile,omitempty" export:"true"`
+	// OpenAPI defines an OpenAPI 3 document, in JSON or YAML.
+	// The request bodies are validated against the schema of the operation matching their method and path.
+	OpenAPI string `json:"openAPI,omitempty" toml:"openAPI,omitempty" yaml:"openAPI,omitempty" export:"true"`
+	// OpenAPIFile defines the path to a file containing an OpenAPI 3 document.
+	OpenAPIFile string `json:"openAPIFile,omitempty" toml:"openAPIFile,omitempty" yaml:"openAPIFile,omitempty" export:"true"`
+	// MaxBodySize defines the maximum size in bytes of the validated request bodies.
+	// The requests with a larger body are refused with a 413 (Request Entity Too Large) response.
+	// Default: 1048576 (1 MiB).
+	MaxBodySize int64 `json:"maxBodySize,omitempty" toml:"maxBodySize,omitempty" yaml:"maxBodySize,omitempty" export:"true"`
+}
+
+// SetDefaults sets the default values on a RequestValidation.
+func (r *RequestValidation) SetDefaults() {
+	r.MaxBodySize = 1024 * 1024
+}
+
+// +k8s:deepcopy-gen=true
+
 // Users holds a list of users.
 type Users []string
@@ -1,11 +1,22 @@
 package dynamic
 
+import (
+	"time"
+
+	ptypes "github.com/traefik/paerser/types"
+)
+
 // +k8s:deepcopy-gen=true
 
 // TCPMiddleware holds the TCPMiddleware configuration.
 type TCPMiddleware struct {
 	InFlightConn *TCPInFlightConn `json:"inFlightConn,omitempty" toml:"inFlightConn,omitempty" yaml:"inFlightConn,omitempty" export:"true"`
 	IPWhiteList  *TCPIPWhiteList  `json:"ipWhiteList,omitempty" toml:"ipWhiteList,omitempty" yaml:"ipWhiteList,omitempty" export:"true"`
+	IPDenyList   *TCPIPDenyList   `json:"ipDenyList,omitempty" toml:"ipDenyList,omitempty" yaml:"ipDenyList,omitempty" export:"true"`
+	RateLimit    *TCPRateLimit    `json:"rateLimit,omitempty" toml:"rateLimit,omitempty" yaml:"rateLimit,omitempty" export:"true"`
+	MaxConn      *TCPMaxConn      `json:"maxConn,omitempty" toml:"maxConn,omitempty" yaml:"maxConn,omitempty" export:"true"`
+	ConnTimeout  *TCPConnTimeout  `json:"connTimeout,omitempty" toml:"connTimeout,omitempty" yaml:"connTimeout,omitempty" export:"true"`
+	AccessLog    *TCPAccessLog    `json:"accessLog,omitempty" toml:"accessLog,omitempty" yaml:"accessLog,omitempty" label:"allowEmpty" file:"allowEmpty" kv:"allowEmpty" export:"true"`
 }
 
 // +k8s:deepcopy-gen=true
@@ -28,3 +39,78 @@ type TCPIPWhiteList struct {
 	// SourceRange defines the allowed IPs (or ranges of allowed IPs by using CIDR notation).
 	SourceRange []string `json:"sourceRange,omitempty" toml:"sourceRange,omitempty" yaml:"sourceRange,omitempty"`
 }
+
+// +k8s:deepcopy-gen=true
+
+// TCPIPDenyList holds the TCP IPDenyList middleware configuration.
+// This middleware refuses the connections from the denied IPs, listed in the configuration, in files, or at URLs,
+// and from the IPs banned by the HTTP IPDenyList middlewares.
+type TCPIPDenyList struct {
+	// SourceRange defines the denied IPs (or ranges of denied IPs by using CIDR notation).
+	SourceRange []string `json:"sourceRange,omitempty" toml:"sourceRange,omitempty" yaml:"sourceRange,omitempty"`
+	// Sources defines the files, or the HTTP(S) URLs, listing the denied IPs or ranges, one per line.
+	Sources []string `json:"sources,omitempty" toml:"sources,omitempty" yaml:"sources,omitempty"`
+	// RefreshInterval defines the interval between two reloads of the sources. Default: 10m.
+	RefreshInterval ptypes.Duration `json:"refreshInterval,omitempty" toml:"refreshInterval,omitempty" yaml:"refreshInterval,omitempty" export:"true"`
+	// BanList defines the name of the ban list whose IPs are refused.
+	BanList string `json:"banList,omitempty" toml:"banList,omitempty" yaml:"banList,omitempty" export:"true"`
+	// BanStore defines where the bans are stored: memory (default), or memcached to share them between the Traefik instances.
+	BanStore string `json:"banStore,omitempty" toml:"banStore,omitempty" yaml:"banStore,omitempty" export:"true"`
+}
+
+// SetDefaults sets the default values on a TCPIPDenyList.
+func (d *TCPIPDenyList) SetDefaults() {
+	d.RefreshInterval = ptypes.Duration(10 * time.Minute)
+}
+
+// +k8s:deepcopy-gen=true
+
+// TCPRateLimit holds the TCP RateLimit middleware configuration.
+// This middleware limits the rate of new connections for one IP, with a token bucket.
+type TCPRateLimit struct {
+	// Average is the maximum rate, by default in connections/s, allowed for one IP.
+	// The rate is actually defined by dividing Average by Period.
+	Average int64 `json:"average,omitempty" toml:"average,omitempty" yaml:"average,omitempty" export:"true"`
+	// Period, in combination with Average, defines the actual maximum rate, such as:
+	// r = Average / Period. It defaults to a second.
+	Period ptypes.Duration `json:"period,omitempty" toml:"period,omitempty" yaml:"period,omitempty" export:"true"`
+	// Burst is the maximum number of connections allowed to arrive in the same arbitrarily small period of time.
+	// It defaults to 1.
+	Burst int64 `json:"burst,omitempty" toml:"burst,omitempty" yaml:"burst,omitempty" export:"true"`
+}
+
+// SetDefaults sets the default values on a TCPRateLimit.
+func (r *TCPRateLimit) SetDefaults() {
+	r.Burst = 1
+	r.Period = ptypes.Duration(time.Second)
+}
+
+// +k8s:deepcopy-gen=true
+
+// TCPMaxConn holds the TCP MaxConn middleware configuration.
+// This middleware limits the number of simultaneous connections, whatever their client IP,
+// on each router using it.
+type TCPMaxConn struct {
+	// Amount defines the maximum amount of allowed simultaneous connections.
+	// The middleware closes the connection if there are already amount connections opened.
+	Amount int64 `json:"amount,omitempty" toml:"amount,omitempty" yaml:"amount,omitempty" export:"true"`
+}
+
+// +k8s:deepcopy-gen=true
+
+// TCPConnTimeout holds the TCP ConnTimeout middleware configuration.
+// This middleware closes the connections that are idle, or opened, for too long.
+type TCPConnTimeout struct {
+	// IdleTimeout defines the maximum duration a connection can stay without any data transfer, in both directions.
+	// Zero means no limit.
+	IdleTimeout ptypes.Duration `json:"idleTimeout,omitempty" toml:"idleTimeout,omitempty" yaml:"idleTimeout,omitempty" export:"true"`
+	// MaxDuration defines the maximum duration of a connection. Zero means no limit.
+	MaxDuration ptypes.Duration `json:"maxDuration,omitempty" toml:"maxDuration,omitempty" yaml:"maxDuration,omitempty" export:"true"`
+}
+
+// +k8s:deepcopy-gen=true
+
+// TCPAccessLog holds the TCP AccessLog middleware configuration.
+// This middleware logs, once closed, each connection with its client IP, the bytes transferred
+// in both directions, and its duration.
+type TCPAccessLog struct{}
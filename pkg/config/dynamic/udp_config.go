@@ -8,8 +8,9 @@ import (
 
 // UDPConfiguration contains all the UDP configuration parameters.
 type UDPConfiguration struct {
-	Routers  map[string]*UDPRouter  `json:"routers,omitempty" toml:"routers,omitempty" yaml:"routers,omitempty" export:"true"`
-	Services map[string]*UDPService `json:"services,omitempty" toml:"services,omitempty" yaml:"services,omitempty" export:"true"`
+	Routers     map[string]*UDPRouter     `json:"routers,omitempty" toml:"routers,omitempty" yaml:"routers,omitempty" export:"true"`
+	Services    map[string]*UDPService    `json:"services,omitempty" toml:"services,omitempty" yaml:"services,omitempty" export:"true"`
+	Middlewares map[string]*UDPMiddleware `json:"middlewares,omitempty" toml:"middlewares,omitempty" yaml:"middlewares,omitempty" export:"true"`
 }
 
 // +k8s:deepcopy-gen=true
@@ -46,7 +47,12 @@ func (w *UDPWRRService) SetDefaults() {
 // UDPRouter defines the configuration for an UDP router.
 type UDPRouter struct {
 	EntryPoints []string `json:"entryPoints,omitempty" toml:"entryPoints,omitempty" yaml:"entryPoints,omitempty" export:"true"`
+	Middlewares []string `json:"middlewares,omitempty" toml:"middlewares,omitempty" yaml:"middlewares,omitempty" export:"true"`
 	Service     string   `json:"service,omitempty" toml:"service,omitempty" yaml:"service,omitempty" export:"true"`
+	// Rule matches the sessions on their source, with ClientIP matchers.
+	// A router without rule matches all the sessions of its entry points.
+	Rule     string `json:"rule,omitempty" toml:"rule,omitempty" yaml:"rule,omitempty"`
+	Priority int    `json:"priority,omitempty" toml:"priority,omitempty,omitzero" yaml:"priority,omitempty" export:"true"`
 }
 
 // +k8s:deepcopy-gen=true
@@ -54,6 +60,8 @@ type UDPRouter struct {
 // UDPServersLoadBalancer defines the configuration for a load-balancer of UDP servers.
 type UDPServersLoadBalancer struct {
 	Servers []UDPServer `json:"servers,omitempty" toml:"servers,omitempty" yaml:"servers,omitempty" label-slice-as-struct:"server" export:"true"`
+	// DNSServers defines servers discovered through periodic DNS lookups, in addition to the static Servers.
+	DNSServers []DNSServer `json:"dnsServers,omitempty" toml:"dnsServers,omitempty" yaml:"dnsServers,omitempty" label:"-" export:"true"`
 }
 
 // Mergeable reports whether the given load-balancer can be merged with the receiver.
@@ -0,0 +1,47 @@
+package dynamic
+
+import (
+	"time"
+
+	ptypes "github.com/traefik/paerser/types"
+)
+
+// +k8s:deepcopy-gen=true
+
+// UDPMiddleware holds the UDPMiddleware configuration.
+type UDPMiddleware struct {
+	IPWhiteList *UDPIPWhiteList `json:"ipWhiteList,omitempty" toml:"ipWhiteList,omitempty" yaml:"ipWhiteList,omitempty" export:"true"`
+	RateLimit   *UDPRateLimit   `json:"rateLimit,omitempty" toml:"rateLimit,omitempty" yaml:"rateLimit,omitempty" export:"true"`
+}
+
+// +k8s:deepcopy-gen=true
+
+// UDPIPWhiteList holds the UDP IPWhiteList middleware configuration.
+// This middleware accepts/refuses sessions based on the client IP.
+type UDPIPWhiteList struct {
+	// SourceRange defines the allowed IPs (or ranges of allowed IPs by using CIDR notation).
+	SourceRange []string `json:"sourceRange,omitempty" toml:"sourceRange,omitempty" yaml:"sourceRange,omitempty"`
+}
+
+// +k8s:deepcopy-gen=true
+
+// UDPRateLimit holds the UDP RateLimit middleware configuration.
+// This middleware limits the rate of packets received from one IP, with a token bucket.
+// The packets exceeding the rate are dropped.
+type UDPRateLimit struct {
+	// Average is the maximum rate, by default in packets/s, allowed for one IP.
+	// The rate is actually defined by dividing Average by Period.
+	Average int64 `json:"average,omitempty" toml:"average,omitempty" yaml:"average,omitempty" export:"true"`
+	// Period, in combination with Average, defines the actual maximum rate, such as:
+	// r = Average / Period. It defaults to a second.
+	Period ptypes.Duration `json:"period,omitempty" toml:"period,omitempty" yaml:"period,omitempty" export:"true"`
+	// Burst is the maximum number of packets allowed to arrive in the same arbitrarily small period of time.
+	// It defaults to 1.
+	Burst int64 `json:"burst,omitempty" toml:"burst,omitempty" yaml:"burst,omitempty" export:"true"`
+}
+
+// SetDefaults sets the default values on a UDPRateLimit.
+func (r *UDPRateLimit) SetDefaults() {
+	r.Burst = 1
+	r.Period = ptypes.Duration(time.Second)
+}
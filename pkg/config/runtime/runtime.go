@@ -25,6 +25,7 @@ type Configuration struct {
 	TCPServices    map[string]*TCPServiceInfo    `json:"tcpServices,omitempty"`
 	UDPRouters     map[string]*UDPRouterInfo     `json:"udpRouters,omitempty"`
 	UDPServices    map[string]*UDPServiceInfo    `json:"udpServices,omitempty"`
+	UDPMiddlewares map[string]*UDPMiddlewareInfo `json:"udpMiddlewares,omitempty"`
 }
 
 // NewConfig returns a Configuration initialized with the given conf. It never returns nil.
@@ -98,6 +99,13 @@ func NewConfig(conf dynamic.Configuration) *Configuration {
 				runtimeConfig.UDPServices[k] = &UDPServiceInfo{UDPService: v, Status: StatusEnabled}
 			}
 		}
+
+		if len(conf.UDP.Middlewares) > 0 {
+			runtimeConfig.UDPMiddlewares = make(map[string]*UDPMiddlewareInfo, len(conf.UDP.Middlewares))
+			for k, v := range conf.UDP.Middlewares {
+				runtimeConfig.UDPMiddlewares[k] = &UDPMiddlewareInfo{UDPMiddleware: v, Status: StatusEnabled}
+			}
+		}
 	}
 
 	return runtimeConfig
@@ -206,6 +214,14 @@ func (c *Configuration) PopulateUsedBy() {
 			continue
 		}
 
+		for _, midName := range routerInfo.UDPRouter.Middlewares {
+			fullMidName := getQualifiedName(providerName, midName)
+			if _, ok := c.UDPMiddlewares[fullMidName]; !ok {
+				continue
+			}
+			c.UDPMiddlewares[fullMidName].UsedBy = append(c.UDPMiddlewares[fullMidName].UsedBy, routerName)
+		}
+
 		serviceName := getQualifiedName(providerName, routerInfo.UDPRouter.Service)
 		if _, ok := c.UDPServices[serviceName]; !ok {
 			continue
@@ -221,6 +237,15 @@ func (c *Configuration) PopulateUsedBy() {
 
 		sort.Strings(c.UDPServices[k].UsedBy)
 	}
+
+	for midName, mid := range c.UDPMiddlewares {
+		// lazily initialize Status in case caller forgot to do it
+		if mid.Status == "" {
+			mid.Status = StatusEnabled
+		}
+
+		sort.Strings(c.UDPMiddlewares[midName].UsedBy)
+	}
 }
 
 func contains(entryPoints []string, entryPointName string) bool {
@@ -1,6 +1,7 @@
 package static
 
 import (
+	"errors"
 	"fmt"
 	stdlog "log"
 	"strings"
@@ -29,6 +30,7 @@ import (
 	"github.com/traefik/traefik/v2/pkg/provider/nomad"
 	"github.com/traefik/traefik/v2/pkg/provider/rancher"
 	"github.com/traefik/traefik/v2/pkg/provider/rest"
+	"github.com/traefik/traefik/v2/pkg/provider/vault"
 	"github.com/traefik/traefik/v2/pkg/tls"
 	"github.com/traefik/traefik/v2/pkg/tracing/datadog"
 	"github.com/traefik/traefik/v2/pkg/tracing/elastic"
@@ -80,6 +82,10 @@ type Configuration struct {
 
 	CertificatesResolvers map[string]CertificateResolver `description:"Certificates resolvers configuration." json:"certificatesResolvers,omitempty" toml:"certificatesResolvers,omitempty" yaml:"certificatesResolvers,omitempty" export:"true"`
 
+	Spiffe *SpiffeClientConfig `description:"SPIFFE integration configuration." json:"spiffe,omitempty" toml:"spiffe,omitempty" yaml:"spiffe,omitempty" label:"allowEmpty" file:"allowEmpty" export:"true"`
+
+	TLSSessionTickets *TLSSessionTickets `description:"TLS session ticket keys configuration." json:"tlsSessionTickets,omitempty" toml:"tlsSessionTickets,omitempty" yaml:"tlsSessionTickets,omitempty" label:"allowEmpty" file:"allowEmpty" export:"true"`
+
 	// Deprecated.
 	Pilot *Pilot `description:"Traefik Pilot configuration." json:"pilot,omitempty" toml:"pilot,omitempty" yaml:"pilot,omitempty" export:"true"`
 
@@ -90,7 +96,28 @@ type Configuration struct {
 
 // CertificateResolver contains the configuration for the different types of certificates resolver.
 type CertificateResolver struct {
-	ACME *acmeprovider.Configuration `description:"Enable ACME (Let's Encrypt): automatic SSL." json:"acme,omitempty" toml:"acme,omitempty" yaml:"acme,omitempty" export:"true"`
+	ACME  *acmeprovider.Configuration `description:"Enable ACME (Let's Encrypt): automatic SSL." json:"acme,omitempty" toml:"acme,omitempty" yaml:"acme,omitempty" export:"true"`
+	Vault *vault.Configuration        `description:"Enable the certificates issuance with a HashiCorp Vault PKI secrets engine." json:"vault,omitempty" toml:"vault,omitempty" yaml:"vault,omitempty" export:"true"`
+}
+
+// SpiffeClientConfig defines the SPIFFE client configuration.
+type SpiffeClientConfig struct {
+	WorkloadAPIAddr string `description:"Defines the workload API address. If empty, the SPIFFE_ENDPOINT_SOCKET environment variable is used." json:"workloadAPIAddr,omitempty" toml:"workloadAPIAddr,omitempty" yaml:"workloadAPIAddr,omitempty"`
+}
+
+// TLSSessionTickets defines the session ticket keys shared by the TLS options.
+// The keys are either read from files, or rotated by Traefik and optionally shared between the instances with a store.
+type TLSSessionTickets struct {
+	KeyFiles         []string        `description:"Defines the files of the session ticket keys, each holding a 32 bytes key, raw or base64 encoded. The first key encrypts the new tickets." json:"keyFiles,omitempty" toml:"keyFiles,omitempty" yaml:"keyFiles,omitempty"`
+	Store            string          `description:"Defines the store sharing the rotated session ticket keys between the Traefik instances. The only supported store is memcached." json:"store,omitempty" toml:"store,omitempty" yaml:"store,omitempty" export:"true"`
+	RotationInterval ptypes.Duration `description:"Defines the interval between the rotations of the session ticket key." json:"rotationInterval,omitempty" toml:"rotationInterval,omitempty" yaml:"rotationInterval,omitempty" export:"true"`
+	Overlap          ptypes.Duration `description:"Defines how long the rotated session ticket keys still decrypt the tickets." json:"overlap,omitempty" toml:"overlap,omitempty" yaml:"overlap,omitempty" export:"true"`
+}
+
+// SetDefaults sets the default values.
+func (t *TLSSessionTickets) SetDefaults() {
+	t.RotationInterval = ptypes.Duration(24 * time.Hour)
+	t.Overlap = ptypes.Duration(24 * time.Hour)
 }
 
 // Global holds the global configuration.
@@ -109,9 +136,10 @@ type ServersTransport struct {
 
 // API holds the API configuration.
 type API struct {
-	Insecure  bool `description:"Activate API directly on the entryPoint named traefik." json:"insecure,omitempty" toml:"insecure,omitempty" yaml:"insecure,omitempty" export:"true"`
-	Dashboard bool `description:"Activate dashboard." json:"dashboard,omitempty" toml:"dashboard,omitempty" yaml:"dashboard,omitempty" export:"true"`
-	Debug     bool `description:"Enable additional endpoints for debugging and profiling." json:"debug,omitempty" toml:"debug,omitempty" yaml:"debug,omitempty" export:"true"`
+	Insecure    bool `description:"Activate API directly on the entryPoint named traefik." json:"insecure,omitempty" toml:"insecure,omitempty" yaml:"insecure,omitempty" export:"true"`
+	Dashboard   bool `description:"Activate dashboard." json:"dashboard,omitempty" toml:"dashboard,omitempty" yaml:"dashboard,omitempty" export:"true"`
+	Debug       bool `description:"Enable additional endpoints for debugging and profiling." json:"debug,omitempty" toml:"debug,omitempty" yaml:"debug,omitempty" export:"true"`
+	ClearIPBans bool `description:"Enable the endpoints clearing the IP bans." json:"clearIPBans,omitempty" toml:"clearIPBans,omitempty" yaml:"clearIPBans,omitempty" export:"true"`
 	// TODO: Re-enable statistics
 	// Statistics      *types.Statistics `description:"Enable more detailed statistics." json:"statistics,omitempty" toml:"statistics,omitempty" yaml:"statistics,omitempty" label:"allowEmpty" file:"allowEmpty" export:"true"`
 }
@@ -317,13 +345,24 @@ func (c *Configuration) initACMEProvider() {
 
 // ValidateConfiguration validate that configuration is coherent.
 func (c *Configuration) ValidateConfiguration() error {
-	var acmeEmail string
+	var acmeEmail, onDemandResolver string
 	for name, resolver := range c.CertificatesResolvers {
+		if resolver.ACME != nil && resolver.Vault != nil {
+			return fmt.Errorf("unable to initialize certificates resolver %q, a resolver cannot use both ACME and Vault", name)
+		}
+
 		if resolver.ACME == nil {
 			continue
 		}
 
-		if len(resolver.ACME.Storage) == 0 {
+		if resolver.ACME.OnDemand != nil {
+			if onDemandResolver != "" {
+				return fmt.Errorf("unable to initialize certificates resolver %q, only one acme resolver can obtain certificates on demand, and %q already does", name, onDemandResolver)
+			}
+			onDemandResolver = name
+		}
+
+		if resolver.ACME.KVStorage == nil && len(resolver.ACME.Storage) == 0 {
 			return fmt.Errorf("unable to initialize certificates resolver %q with no storage location for the certificates", name)
 		}
 
@@ -333,6 +372,24 @@ func (c *Configuration) ValidateConfiguration() error {
 		acmeEmail = resolver.ACME.Email
 	}
 
+	if c.TLSSessionTickets != nil {
+		if len(c.TLSSessionTickets.KeyFiles) > 0 && c.TLSSessionTickets.Store != "" {
+			return errors.New("TLS session ticket keys cannot be both read from files and shared with a store")
+		}
+
+		if c.TLSSessionTickets.Store != "" && c.TLSSessionTickets.Store != "memcached" {
+			return fmt.Errorf("unknown TLS session ticket keys store %q", c.TLSSessionTickets.Store)
+		}
+
+		if c.TLSSessionTickets.Store == "memcached" && c.Memcached == nil {
+			return errors.New("TLS session ticket keys cannot be shared with memcached, as memcached is not configured")
+		}
+
+		if len(c.TLSSessionTickets.KeyFiles) == 0 && c.TLSSessionTickets.RotationInterval <= 0 {
+			return errors.New("the rotation interval of the TLS session ticket keys must be positive")
+		}
+	}
+
 	if c.Providers.ConsulCatalog != nil && c.Providers.ConsulCatalog.Namespace != "" && len(c.Providers.ConsulCatalog.Namespaces) > 0 {
 		return fmt.Errorf("consul catalog provider cannot have both namespace and namespaces options configured")
 	}
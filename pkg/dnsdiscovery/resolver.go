@@ -0,0 +1,204 @@
+package dnsdiscovery
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/miekg/dns"
+)
+
+const resolvConfPath = "/etc/resolv.conf"
+
+// Target is a server discovered through DNS.
+type Target struct {
+	Host string
+	Port int
+}
+
+// Address returns the host:port address of the target.
+func (t Target) Address() string {
+	return net.JoinHostPort(t.Host, strconv.Itoa(t.Port))
+}
+
+// resolver looks up DNS records against a given nameserver.
+type resolver struct {
+	client     *dns.Client
+	nameserver string
+}
+
+func newResolver(nameserver string) (*resolver, error) {
+	if nameserver == "" {
+		conf, err := dns.ClientConfigFromFile(resolvConfPath)
+		if err != nil {
+			return nil, fmt.Errorf("reading system resolver configuration: %w", err)
+		}
+
+		if len(conf.Servers) == 0 {
+			return nil, errors.New("no nameserver in the system resolver configuration")
+		}
+
+		nameserver = net.JoinHostPort(conf.Servers[0], conf.Port)
+	}
+
+	if _, _, err := net.SplitHostPort(nameserver); err != nil {
+		nameserver = net.JoinHostPort(nameserver, "53")
+	}
+
+	return &resolver{
+		client:     &dns.Client{Timeout: 5 * time.Second},
+		nameserver: nameserver,
+	}, nil
+}
+
+// lookup returns the targets found for the given name and record type,
+// along with the lowest TTL of the answers.
+// The port is used for A and AAAA records only, as SRV records provide their own ports.
+func (r *resolver) lookup(ctx context.Context, name, recordType string, port int) ([]Target, time.Duration, error) {
+	switch strings.ToUpper(recordType) {
+	case "", "A":
+		hosts, ttl, err := r.lookupHosts(ctx, name, dns.TypeA, nil)
+		return withPort(hosts, port), ttl, err
+	case "AAAA":
+		hosts, ttl, err := r.lookupHosts(ctx, name, dns.TypeAAAA, nil)
+		return withPort(hosts, port), ttl, err
+	case "SRV":
+		return r.lookupSRV(ctx, name)
+	default:
+		return nil, 0, fmt.Errorf("unsupported record type %q", recordType)
+	}
+}
+
+func (r *resolver) lookupSRV(ctx context.Context, name string) ([]Target, time.Duration, error) {
+	msg, err := r.exchange(ctx, name, dns.TypeSRV)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var records []*dns.SRV
+	ttl := minTTL(msg.Answer)
+	for _, rr := range msg.Answer {
+		if srv, ok := rr.(*dns.SRV); ok {
+			records = append(records, srv)
+		}
+	}
+
+	if len(records) == 0 {
+		return nil, ttl, nil
+	}
+
+	// Only the records with the lowest priority are used, as the other ones are meant as backups.
+	sort.SliceStable(records, func(i, j int) bool { return records[i].Priority < records[j].Priority })
+
+	var targets []Target
+	for _, srv := range records {
+		if srv.Priority != records[0].Priority {
+			break
+		}
+
+		// The addresses of the targets are usually provided as additional records (e.g. by Consul),
+		// which matters when the targets cannot be resolved by the system resolver.
+		hosts, hostsTTL, err := r.lookupHosts(ctx, srv.Target, dns.TypeA, msg.Extra)
+		if err != nil {
+			return nil, 0, err
+		}
+
+		if hostsTTL > 0 && hostsTTL < ttl {
+			ttl = hostsTTL
+		}
+
+		targets = append(targets, withPort(hosts, int(srv.Port))...)
+	}
+
+	return targets, ttl, nil
+}
+
+// lookupHosts returns the IP addresses of the given name, looked up in the extra records if found there.
+func (r *resolver) lookupHosts(ctx context.Context, name string, qtype uint16, extra []dns.RR) ([]string, time.Duration, error) {
+	hosts, ttl := hostsFromRecords(dns.Fqdn(name), extra)
+	if len(hosts) > 0 {
+		return hosts, ttl, nil
+	}
+
+	msg, err := r.exchange(ctx, name, qtype)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	hosts, ttl = hostsFromRecords(dns.Fqdn(name), msg.Answer)
+
+	return hosts, ttl, nil
+}
+
+func (r *resolver) exchange(ctx context.Context, name string, qtype uint16) (*dns.Msg, error) {
+	query := new(dns.Msg)
+	query.SetQuestion(dns.Fqdn(name), qtype)
+
+	msg, _, err := r.client.ExchangeContext(ctx, query, r.nameserver)
+	if err != nil {
+		return nil, fmt.Errorf("looking up %s %s: %w", dns.TypeToString[qtype], name, err)
+	}
+
+	if msg.Rcode != dns.RcodeSuccess {
+		return nil, fmt.Errorf("looking up %s %s: %s", dns.TypeToString[qtype], name, dns.RcodeToString[msg.Rcode])
+	}
+
+	return msg, nil
+}
+
+// hostsFromRecords returns the IP addresses found in the A and AAAA records,
+// following the CNAME records from the given name.
+func hostsFromRecords(name string, records []dns.RR) ([]string, time.Duration) {
+	names := map[string]struct{}{strings.ToLower(name): {}}
+	for _, rr := range records {
+		if cname, ok := rr.(*dns.CNAME); ok {
+			names[strings.ToLower(cname.Target)] = struct{}{}
+		}
+	}
+
+	var hosts []string
+	var matched []dns.RR
+	for _, rr := range records {
+		if _, ok := names[strings.ToLower(rr.Header().Name)]; !ok {
+			continue
+		}
+
+		switch record := rr.(type) {
+		case *dns.A:
+			hosts = append(hosts, record.A.String())
+		case *dns.AAAA:
+			hosts = append(hosts, record.AAAA.String())
+		default:
+			continue
+		}
+
+		matched = append(matched, rr)
+	}
+
+	return hosts, minTTL(matched)
+}
+
+func minTTL(records []dns.RR) time.Duration {
+	var ttl uint32
+	for i, rr := range records {
+		if i == 0 || rr.Header().Ttl < ttl {
+			ttl = rr.Header().Ttl
+		}
+	}
+
+	return time.Duration(ttl) * time.Second
+}
+
+func withPort(hosts []string, port int) []Target {
+	targets := make([]Target, 0, len(hosts))
+	for _, host := range hosts {
+		targets = append(targets, Target{Host: host, Port: port})
+	}
+
+	return targets
+}
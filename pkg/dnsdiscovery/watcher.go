@@ -0,0 +1,203 @@
+package dnsdiscovery
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/traefik/traefik/v2/pkg/config/dynamic"
+	"github.com/traefik/traefik/v2/pkg/log"
+	"github.com/traefik/traefik/v2/pkg/safe"
+)
+
+const (
+	defaultRefreshInterval = 30 * time.Second
+	// minRefreshInterval prevents records with a very low TTL from flooding the nameserver.
+	minRefreshInterval = time.Second
+)
+
+// Watcher periodically looks up a DNS name,
+// and calls its update function whenever the discovered targets change.
+type Watcher struct {
+	config          dynamic.DNSServer
+	refreshInterval time.Duration
+	resolver        *resolver
+	update          func(targets []Target)
+	logger          log.Logger
+
+	previous []Target
+
+	stopOnce sync.Once
+	stop     chan struct{}
+}
+
+// NewWatcher creates a new Watcher.
+// The context is only used to enrich the logs.
+func NewWatcher(ctx context.Context, config dynamic.DNSServer, update func(targets []Target)) (*Watcher, error) {
+	if config.Name == "" {
+		return nil, errors.New("DNS name is mandatory")
+	}
+
+	switch strings.ToUpper(config.RecordType) {
+	case "", "A", "AAAA":
+		if config.Port <= 0 {
+			return nil, fmt.Errorf("a port is mandatory to discover %s through %s records", config.Name, config.RecordType)
+		}
+	case "SRV":
+	default:
+		return nil, fmt.Errorf("unsupported record type %q", config.RecordType)
+	}
+
+	r, err := newResolver(config.Nameserver)
+	if err != nil {
+		return nil, err
+	}
+
+	refreshInterval := time.Duration(config.RefreshInterval)
+	if refreshInterval <= 0 {
+		refreshInterval = defaultRefreshInterval
+	}
+
+	return &Watcher{
+		config:          config,
+		refreshInterval: refreshInterval,
+		resolver:        r,
+		update:          update,
+		logger:          log.FromContext(ctx).WithField("dnsName", config.Name),
+		stop:            make(chan struct{}),
+	}, nil
+}
+
+// Run looks up the DNS name until the context is done, or the watcher is stopped.
+// The next lookup happens when the lowest TTL of the records expires,
+// bounded by the refresh interval.
+func (w *Watcher) Run(ctx context.Context) {
+	for {
+		next := w.refresh(ctx)
+
+		timer := time.NewTimer(next)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-w.stop:
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+	}
+}
+
+// Stop stops the watcher.
+func (w *Watcher) Stop() {
+	w.stopOnce.Do(func() {
+		close(w.stop)
+	})
+}
+
+func (w *Watcher) refresh(ctx context.Context) time.Duration {
+	targets, ttl, err := w.resolver.lookup(ctx, w.config.Name, w.config.RecordType, w.config.Port)
+	if err != nil {
+		if ctx.Err() != nil {
+			return 0
+		}
+
+		// The previously discovered targets are kept, as the failure might be transient.
+		w.logger.Errorf("DNS discovery failed: %v", err)
+		return w.refreshInterval
+	}
+
+	sort.Slice(targets, func(i, j int) bool { return targets[i].Address() < targets[j].Address() })
+
+	if !equal(w.previous, targets) {
+		w.logger.Debugf("DNS discovery found %d server(s): %v", len(targets), targets)
+		w.previous = targets
+		w.update(targets)
+	}
+
+	switch {
+	case ttl <= 0 || ttl >= w.refreshInterval:
+		return w.refreshInterval
+	case ttl < minRefreshInterval:
+		return minRefreshInterval
+	default:
+		return ttl
+	}
+}
+
+func equal(a, b []Target) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Launcher runs the watchers of the current configuration.
+type Launcher struct {
+	routinesPool *safe.Pool
+
+	mu       sync.Mutex
+	watchers []*Watcher
+}
+
+// NewLauncher creates a new Launcher.
+func NewLauncher(routinesPool *safe.Pool) *Launcher {
+	return &Launcher{routinesPool: routinesPool}
+}
+
+// Launch runs the given watchers in the background,
+// and stops the previously launched ones, as they belong to an outdated configuration.
+func (l *Launcher) Launch(watchers []*Watcher) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for _, watcher := range l.watchers {
+		watcher.Stop()
+	}
+
+	l.watchers = watchers
+
+	for _, watcher := range watchers {
+		l.routinesPool.GoCtx(watcher.Run)
+	}
+}
+
+// TargetSet aggregates the targets discovered by several watchers.
+type TargetSet struct {
+	mu      sync.Mutex
+	targets [][]Target
+}
+
+// NewTargetSet creates a new TargetSet for the given number of watchers.
+func NewTargetSet(size int) *TargetSet {
+	return &TargetSet{targets: make([][]Target, size)}
+}
+
+// Set sets the targets discovered by the watcher at the given index,
+// and returns the addresses of all the discovered targets.
+func (s *TargetSet) Set(index int, targets []Target) []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.targets[index] = targets
+
+	var addresses []string
+	for _, watcherTargets := range s.targets {
+		for _, target := range watcherTargets {
+			addresses = append(addresses, target.Address())
+		}
+	}
+
+	return addresses
+}
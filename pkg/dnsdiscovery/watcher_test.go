@@ -0,0 +1,265 @@
+package dnsdiscovery
+
+import (
+	"context"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	ptypes "github.com/traefik/paerser/types"
+	"github.com/traefik/traefik/v2/pkg/config/dynamic"
+	"github.com/traefik/traefik/v2/pkg/safe"
+)
+
+// fakeNameserver is a local DNS server answering with the records of its zone.
+type fakeNameserver struct {
+	mu   sync.Mutex
+	zone map[uint16][]dns.RR
+}
+
+func (f *fakeNameserver) ServeDNS(w dns.ResponseWriter, req *dns.Msg) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	msg := new(dns.Msg)
+	msg.SetReply(req)
+
+	for _, rr := range f.zone[req.Question[0].Qtype] {
+		if rr.Header().Name == req.Question[0].Name {
+			msg.Answer = append(msg.Answer, rr)
+		}
+	}
+
+	if req.Question[0].Qtype == dns.TypeSRV {
+		msg.Extra = f.zone[dns.TypeA]
+	}
+
+	_ = w.WriteMsg(msg)
+}
+
+func (f *fakeNameserver) setZone(records ...string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.zone = make(map[uint16][]dns.RR)
+	for _, record := range records {
+		rr, err := dns.NewRR(record)
+		if err != nil {
+			panic(err)
+		}
+		f.zone[rr.Header().Rrtype] = append(f.zone[rr.Header().Rrtype], rr)
+	}
+}
+
+func startFakeNameserver(t *testing.T, records ...string) (*fakeNameserver, string) {
+	t.Helper()
+
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	nameserver := &fakeNameserver{}
+	nameserver.setZone(records...)
+
+	server := &dns.Server{PacketConn: conn, Handler: nameserver}
+	go func() { _ = server.ActivateAndServe() }()
+	t.Cleanup(func() { _ = server.Shutdown() })
+
+	return nameserver, conn.LocalAddr().String()
+}
+
+func TestResolverLookup(t *testing.T) {
+	_, addr := startFakeNameserver(t,
+		"backend.example.com. 10 IN A 10.0.0.1",
+		"backend.example.com. 20 IN A 10.0.0.2",
+		"backend.example.com. 30 IN AAAA ::1",
+		"node1.example.com. 5 IN A 10.0.1.1",
+		"node2.example.com. 60 IN A 10.0.1.2",
+		"_http._tcp.backend.example.com. 60 IN SRV 1 1 8080 node1.example.com.",
+		"_http._tcp.backend.example.com. 60 IN SRV 1 1 8081 node2.example.com.",
+		"_http._tcp.backend.example.com. 60 IN SRV 2 1 9090 node3.example.com.",
+	)
+
+	testCases := []struct {
+		desc            string
+		name            string
+		recordType      string
+		expectedTargets []Target
+		expectedTTL     time.Duration
+		expectedErr     bool
+	}{
+		{
+			desc:       "A records",
+			name:       "backend.example.com",
+			recordType: "A",
+			expectedTargets: []Target{
+				{Host: "10.0.0.1", Port: 80},
+				{Host: "10.0.0.2", Port: 80},
+			},
+			expectedTTL: 10 * time.Second,
+		},
+		{
+			desc:            "AAAA records",
+			name:            "backend.example.com",
+			recordType:      "AAAA",
+			expectedTargets: []Target{{Host: "::1", Port: 80}},
+			expectedTTL:     30 * time.Second,
+		},
+		{
+			desc:       "SRV records with the lowest priority",
+			name:       "_http._tcp.backend.example.com",
+			recordType: "SRV",
+			expectedTargets: []Target{
+				{Host: "10.0.1.1", Port: 8080},
+				{Host: "10.0.1.2", Port: 8081},
+			},
+			expectedTTL: 5 * time.Second,
+		},
+		{
+			desc:        "unsupported record type",
+			name:        "backend.example.com",
+			recordType:  "TXT",
+			expectedErr: true,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			r, err := newResolver(addr)
+			require.NoError(t, err)
+
+			targets, ttl, err := r.lookup(context.Background(), test.name, test.recordType, 80)
+			if test.expectedErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			assert.Equal(t, test.expectedTargets, targets)
+			assert.Equal(t, test.expectedTTL, ttl)
+		})
+	}
+}
+
+func TestWatcher(t *testing.T) {
+	nameserver, addr := startFakeNameserver(t, "backend.example.com. 1 IN A 10.0.0.1")
+
+	updates := make(chan []Target, 10)
+	watcher, err := NewWatcher(context.Background(), dynamic.DNSServer{
+		Name:            "backend.example.com",
+		RecordType:      "A",
+		Port:            80,
+		Nameserver:      addr,
+		RefreshInterval: ptypes.Duration(time.Minute),
+	}, func(targets []Target) {
+		updates <- targets
+	})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	go watcher.Run(ctx)
+
+	select {
+	case targets := <-updates:
+		assert.Equal(t, []Target{{Host: "10.0.0.1", Port: 80}}, targets)
+	case <-time.After(5 * time.Second):
+		t.Fatal("timeout waiting for the first update")
+	}
+
+	// The TTL of the records (1s) triggers the next lookup before the refresh interval.
+	nameserver.setZone("backend.example.com. 1 IN A 10.0.0.2", "backend.example.com. 1 IN A 10.0.0.1")
+
+	select {
+	case targets := <-updates:
+		assert.Equal(t, []Target{{Host: "10.0.0.1", Port: 80}, {Host: "10.0.0.2", Port: 80}}, targets)
+	case <-time.After(5 * time.Second):
+		t.Fatal("timeout waiting for the second update")
+	}
+}
+
+func TestLauncher(t *testing.T) {
+	_, addr := startFakeNameserver(t, "backend.example.com. 1 IN A 10.0.0.1")
+
+	newWatcher := func(updates chan []Target) *Watcher {
+		watcher, err := NewWatcher(context.Background(), dynamic.DNSServer{
+			Name:       "backend.example.com",
+			RecordType: "A",
+			Port:       80,
+			Nameserver: addr,
+		}, func(targets []Target) {
+			updates <- targets
+		})
+		require.NoError(t, err)
+
+		return watcher
+	}
+
+	routinesPool := safe.NewPool(context.Background())
+	t.Cleanup(routinesPool.Stop)
+
+	launcher := NewLauncher(routinesPool)
+
+	previousUpdates := make(chan []Target, 10)
+	previous := newWatcher(previousUpdates)
+	launcher.Launch([]*Watcher{previous})
+
+	select {
+	case <-previousUpdates:
+	case <-time.After(5 * time.Second):
+		t.Fatal("timeout waiting for the first update")
+	}
+
+	currentUpdates := make(chan []Target, 10)
+	launcher.Launch([]*Watcher{newWatcher(currentUpdates)})
+
+	select {
+	case <-previous.stop:
+	default:
+		t.Fatal("previous watcher not stopped")
+	}
+
+	select {
+	case targets := <-currentUpdates:
+		assert.Equal(t, []Target{{Host: "10.0.0.1", Port: 80}}, targets)
+	case <-time.After(5 * time.Second):
+		t.Fatal("timeout waiting for the update of the current watcher")
+	}
+}
+
+func TestNewWatcher_validation(t *testing.T) {
+	testCases := []struct {
+		desc   string
+		config dynamic.DNSServer
+	}{
+		{
+			desc:   "missing name",
+			config: dynamic.DNSServer{RecordType: "SRV", Nameserver: "127.0.0.1:53"},
+		},
+		{
+			desc:   "missing port for A records",
+			config: dynamic.DNSServer{Name: "backend.example.com", RecordType: "A", Nameserver: "127.0.0.1:53"},
+		},
+		{
+			desc:   "unsupported record type",
+			config: dynamic.DNSServer{Name: "backend.example.com", RecordType: "MX", Port: 80, Nameserver: "127.0.0.1:53"},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := NewWatcher(context.Background(), test.config, func([]Target) {})
+			assert.Error(t, err)
+		})
+	}
+}
@@ -0,0 +1,121 @@
+package filewatcher
+
+import (
+	"context"
+	"sync"
+
+	"github.com/traefik/traefik/v2/pkg/log"
+)
+
+// LoadFunc loads the content of a file.
+type LoadFunc func(file string) (interface{}, error)
+
+// SharedFiles holds the files shared by their users, such as the middlewares, across the configuration changes.
+// The shared files are loaded once for all their users, and are watched as long as they are used by the configuration.
+type SharedFiles struct {
+	mu    sync.Mutex
+	files map[sharedFileKey]*SharedFile
+}
+
+// NewSharedFiles creates a SharedFiles.
+func NewSharedFiles() *SharedFiles {
+	return &SharedFiles{files: make(map[sharedFileKey]*SharedFile)}
+}
+
+type sharedFileKey struct {
+	kind string
+	file string
+}
+
+// SharedFile is the content of a file shared by its users, reloaded when the file changes.
+type SharedFile struct {
+	file string
+	load LoadFunc
+
+	mu    sync.RWMutex
+	value interface{}
+
+	watcher *Watcher
+	// used is guarded by the lock of the SharedFiles.
+	used bool
+}
+
+// Get returns the shared content of a file, loaded with the given function, and reloaded when the file changes.
+// The kind identifies the loading function, as the same file can be loaded differently by its users.
+func (s *SharedFiles) Get(ctx context.Context, kind, file string, load LoadFunc) (*SharedFile, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := sharedFileKey{kind: kind, file: file}
+	if sharedFile, ok := s.files[key]; ok {
+		sharedFile.used = true
+		return sharedFile, nil
+	}
+
+	value, err := load(file)
+	if err != nil {
+		return nil, err
+	}
+
+	sharedFile := &SharedFile{
+		file:  file,
+		load:  load,
+		value: value,
+		used:  true,
+	}
+
+	sharedFile.watcher, err = New(ctx, []string{file}, sharedFile.reload)
+	if err != nil {
+		log.FromContext(ctx).Errorf("Unable to watch the file %s, it will not be reloaded: %v", file, err)
+	}
+
+	s.files[key] = sharedFile
+
+	return sharedFile, nil
+}
+
+// Value returns the last loaded content of the file.
+func (f *SharedFile) Value() interface{} {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	return f.value
+}
+
+// reload loads the file again, before taking the lock, so that the readers only wait for the swap of the content.
+// The previous content is kept if the file cannot be loaded.
+func (f *SharedFile) reload() {
+	logger := log.WithoutContext()
+
+	value, err := f.load(f.file)
+	if err != nil {
+		logger.Errorf("Unable to reload the file %s, the previous content is kept: %v", f.file, err)
+		return
+	}
+
+	logger.Debugf("The file %s has changed, its content is reloaded", f.file)
+
+	f.mu.Lock()
+	f.value = value
+	f.mu.Unlock()
+}
+
+// Sweep stops watching, and forgets, the shared files which have not been gotten since the previous sweep.
+// It is called after each configuration update, once the users of the files in the new configuration are created.
+func (s *SharedFiles) Sweep() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for key, sharedFile := range s.files {
+		if sharedFile.used {
+			sharedFile.used = false
+			continue
+		}
+
+		if sharedFile.watcher != nil {
+			sharedFile.watcher.Close()
+		}
+
+		delete(s.files, key)
+	}
+}
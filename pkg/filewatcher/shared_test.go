@@ -0,0 +1,95 @@
+package filewatcher
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSharedFiles_Get(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "file")
+	require.NoError(t, os.WriteFile(file, []byte("foo"), 0o600))
+
+	var loads int
+	load := func(file string) (interface{}, error) {
+		content, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+
+		if string(content) == "invalid" {
+			return nil, errors.New("invalid content")
+		}
+
+		loads++
+
+		return string(content), nil
+	}
+
+	sharedFiles := NewSharedFiles()
+
+	sharedFile, err := sharedFiles.Get(context.Background(), "test", file, load)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		sharedFiles.Sweep()
+		sharedFiles.Sweep()
+	})
+
+	assert.Equal(t, "foo", sharedFile.Value())
+
+	// The file is loaded once for all its users.
+	other, err := sharedFiles.Get(context.Background(), "test", file, load)
+	require.NoError(t, err)
+
+	assert.Same(t, sharedFile, other)
+	assert.Equal(t, 1, loads)
+
+	require.NoError(t, os.WriteFile(file, []byte("bar"), 0o600))
+
+	assert.Eventually(t, func() bool { return sharedFile.Value() == "bar" }, 5*time.Second, 50*time.Millisecond)
+
+	// An invalid file keeps the previous content.
+	require.NoError(t, os.WriteFile(file, []byte("invalid"), 0o600))
+	time.Sleep(2 * ChangeDelay)
+
+	assert.Equal(t, "bar", sharedFile.Value())
+}
+
+func TestSharedFiles_Sweep(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "file")
+	require.NoError(t, os.WriteFile(file, []byte("foo"), 0o600))
+
+	load := func(file string) (interface{}, error) {
+		content, err := os.ReadFile(file)
+		return string(content), err
+	}
+
+	sharedFiles := NewSharedFiles()
+
+	sharedFile, err := sharedFiles.Get(context.Background(), "test", file, load)
+	require.NoError(t, err)
+
+	// The file has been gotten since the previous sweep, it is kept.
+	sharedFiles.Sweep()
+
+	other, err := sharedFiles.Get(context.Background(), "test", file, load)
+	require.NoError(t, err)
+	assert.Same(t, sharedFile, other)
+
+	// The file has not been gotten since the previous sweep, it is forgotten.
+	sharedFiles.Sweep()
+	sharedFiles.Sweep()
+
+	other, err = sharedFiles.Get(context.Background(), "test", file, load)
+	require.NoError(t, err)
+	assert.NotSame(t, sharedFile, other)
+
+	sharedFiles.Sweep()
+	sharedFiles.Sweep()
+}
@@ -0,0 +1,141 @@
+package filewatcher
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/traefik/traefik/v2/pkg/log"
+	"github.com/traefik/traefik/v2/pkg/safe"
+	"gopkg.in/fsnotify.v1"
+)
+
+// ChangeDelay is the delay, after the last event on the watched directories, before checking the files for changes.
+// It lets the tools writing the files, or swapping the symlinks of a mounted volume, complete their updates.
+var ChangeDelay = 500 * time.Millisecond
+
+// Watcher watches files, and calls the onChange function when their content changes.
+// The directories of the files are watched, rather than the files themselves,
+// to detect the files replaced by a rename, or by the swap of a symlink.
+type Watcher struct {
+	files    []string
+	onChange func()
+	watcher  *fsnotify.Watcher
+
+	mu     sync.Mutex
+	hashes map[string][sha256.Size]byte
+	timer  *time.Timer
+	closed bool
+}
+
+// New creates a Watcher of the given files, the current content of the files being the reference to detect the changes.
+func New(ctx context.Context, files []string, onChange func()) (*Watcher, error) {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, fmt.Errorf("creating file watcher: %w", err)
+	}
+
+	directories := make(map[string]struct{})
+	for _, file := range files {
+		directories[filepath.Dir(file)] = struct{}{}
+	}
+
+	for directory := range directories {
+		if err = watcher.Add(directory); err != nil {
+			_ = watcher.Close()
+			return nil, fmt.Errorf("watching directory %s: %w", directory, err)
+		}
+	}
+
+	w := &Watcher{
+		files:    files,
+		onChange: onChange,
+		watcher:  watcher,
+		hashes:   make(map[string][sha256.Size]byte),
+	}
+	w.HasChanged()
+
+	safe.Go(func() { w.watch(ctx) })
+
+	return w, nil
+}
+
+// Files returns the watched files.
+func (w *Watcher) Files() []string {
+	return w.files
+}
+
+func (w *Watcher) watch(ctx context.Context) {
+	for {
+		select {
+		case _, ok := <-w.watcher.Events:
+			if !ok {
+				return
+			}
+
+			w.mu.Lock()
+			if w.timer == nil {
+				w.timer = time.AfterFunc(ChangeDelay, w.check)
+			} else {
+				w.timer.Reset(ChangeDelay)
+			}
+			w.mu.Unlock()
+
+		case err, ok := <-w.watcher.Errors:
+			if !ok {
+				return
+			}
+
+			log.FromContext(ctx).Errorf("Files watcher error: %v", err)
+		}
+	}
+}
+
+func (w *Watcher) check() {
+	w.mu.Lock()
+	closed := w.closed
+	w.mu.Unlock()
+
+	if !closed && w.HasChanged() {
+		w.onChange()
+	}
+}
+
+// HasChanged reads the files, and returns whether the content of one of them has changed since the previous call.
+// The files which cannot be read, while they are being written, are considered unchanged.
+func (w *Watcher) HasChanged() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	var changed bool
+	for _, file := range w.files {
+		content, err := os.ReadFile(file)
+		if err != nil {
+			continue
+		}
+
+		hash := sha256.Sum256(content)
+		if previous, ok := w.hashes[file]; ok && previous != hash {
+			changed = true
+		}
+		w.hashes[file] = hash
+	}
+
+	return changed
+}
+
+// Close stops watching the files.
+func (w *Watcher) Close() {
+	w.mu.Lock()
+	w.closed = true
+	if w.timer != nil {
+		w.timer.Stop()
+	}
+	w.mu.Unlock()
+
+	_ = w.watcher.Close()
+}
@@ -0,0 +1,333 @@
+package ip
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/traefik/traefik/v2/pkg/log"
+	"github.com/traefik/traefik/v2/pkg/safe"
+)
+
+// maxSourceSize is the maximum size of a source read from a URL.
+const maxSourceSize = 32 << 20
+
+// sourceRetryDelay is the delay before retrying to load the sources which have never been loaded,
+// when it is shorter than the refresh interval.
+const sourceRetryDelay = time.Minute
+
+// SourceLists holds the source lists shared by the middlewares, so that they are not loaded again on each configuration change.
+// The source lists are kept as long as they are used by the configuration.
+type SourceLists struct {
+	mu    sync.Mutex
+	lists map[string]*sourceListEntry
+}
+
+// NewSourceLists creates a SourceLists.
+func NewSourceLists() *SourceLists {
+	return &SourceLists{lists: make(map[string]*sourceListEntry)}
+}
+
+// sourceListEntry is a shared source list.
+type sourceListEntry struct {
+	list *SourceList
+
+	// used is guarded by the lock of the SourceLists.
+	used bool
+}
+
+// SourceList is a list of IPs and CIDR ranges, loaded from files or HTTP(S) URLs, and reloaded periodically.
+type SourceList struct {
+	sources         []string
+	refreshInterval time.Duration
+	client          *http.Client
+
+	mu sync.RWMutex
+	// ranges are the sorted, and non-overlapping, ranges of the IPs and CIDR ranges of the sources.
+	ranges      []ipRange
+	refreshedAt time.Time
+	refreshing  bool
+	// loaded is whether the sources have been loaded at least once.
+	loaded bool
+}
+
+// Get returns the shared list of the IPs and CIDR ranges of the given sources, and loads it if needed.
+// The sources are files or HTTP(S) URLs, listing one IP or CIDR range per line.
+// The sources are loaded in the background, and the list is empty until they are loaded.
+func (s *SourceLists) Get(ctx context.Context, sources []string, refreshInterval time.Duration) (*SourceList, error) {
+	if refreshInterval <= 0 {
+		return nil, errors.New("the refresh interval must be positive")
+	}
+
+	key := strings.Join(sources, "\n") + "\n" + refreshInterval.String()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.lists[key]
+	if !ok {
+		entry = &sourceListEntry{list: newSourceList(ctx, sources, refreshInterval)}
+		s.lists[key] = entry
+	}
+	entry.used = true
+
+	return entry.list, nil
+}
+
+// Sweep forgets the source lists which have not been gotten since the previous sweep.
+// It is called after each configuration update, once the middlewares of the new configuration are created.
+func (s *SourceLists) Sweep() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for key, entry := range s.lists {
+		if entry.used {
+			entry.used = false
+			continue
+		}
+
+		delete(s.lists, key)
+	}
+}
+
+// newSourceList creates the list, and starts loading its sources in the background,
+// so that a slow, or unavailable, source does not delay the configuration.
+func newSourceList(ctx context.Context, sources []string, refreshInterval time.Duration) *SourceList {
+	list := &SourceList{
+		sources:         sources,
+		refreshInterval: refreshInterval,
+		client:          &http.Client{Timeout: 30 * time.Second},
+		refreshing:      true,
+	}
+
+	logger := log.FromContext(ctx)
+	safe.Go(func() { list.reload(logger) })
+
+	return list
+}
+
+// ContainsIP checks if the provided address is in the list.
+// The sources are reloaded in the background when the refresh interval has elapsed.
+func (l *SourceList) ContainsIP(ctx context.Context, addr net.IP) bool {
+	l.refresh(ctx)
+
+	ip := addr.To16()
+	if ip == nil {
+		return false
+	}
+
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	// The range containing the IP, if any, is the last one starting before the IP.
+	i := sort.Search(len(l.ranges), func(i int) bool {
+		return bytes.Compare(l.ranges[i].first, ip) > 0
+	})
+
+	return i > 0 && bytes.Compare(ip, l.ranges[i-1].last) <= 0
+}
+
+func (l *SourceList) refresh(ctx context.Context) {
+	l.mu.RLock()
+	due := l.due()
+	l.mu.RUnlock()
+
+	if !due {
+		return
+	}
+
+	l.mu.Lock()
+	if !l.due() {
+		l.mu.Unlock()
+		return
+	}
+	l.refreshing = true
+	l.mu.Unlock()
+
+	logger := log.FromContext(ctx)
+
+	safe.Go(func() { l.reload(logger) })
+}
+
+// due returns whether the sources must be reloaded, and must be called with the lock held.
+// The sources which have never been loaded are retried sooner than the refresh interval.
+func (l *SourceList) due() bool {
+	interval := l.refreshInterval
+	if !l.loaded && interval > sourceRetryDelay {
+		interval = sourceRetryDelay
+	}
+
+	return !l.refreshing && time.Since(l.refreshedAt) >= interval
+}
+
+func (l *SourceList) reload(logger log.Logger) {
+	ranges, err := l.load(context.Background())
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.refreshing = false
+	l.refreshedAt = time.Now()
+
+	if err != nil {
+		if !l.loaded {
+			logger.Errorf("Unable to load the IP sources, the list is empty until they are loaded: %v", err)
+			return
+		}
+
+		logger.Errorf("Unable to reload the IP sources, the previous IPs are kept: %v", err)
+		return
+	}
+
+	l.ranges = ranges
+	l.loaded = true
+}
+
+func (l *SourceList) load(ctx context.Context) ([]ipRange, error) {
+	var ranges []ipRange
+
+	for _, source := range l.sources {
+		err := l.read(ctx, source, func(reader io.Reader) error {
+			return parseSource(reader, &ranges)
+		})
+		if err != nil {
+			return nil, fmt.Errorf("loading the IP source %s: %w", source, err)
+		}
+	}
+
+	return mergeRanges(ranges), nil
+}
+
+func (l *SourceList) read(ctx context.Context, source string, parse func(io.Reader) error) error {
+	if !strings.HasPrefix(source, "http://") && !strings.HasPrefix(source, "https://") {
+		file, err := os.Open(source)
+		if err != nil {
+			return err
+		}
+		defer func() { _ = file.Close() }()
+
+		return parse(file)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, source, http.NoBody)
+	if err != nil {
+		return err
+	}
+
+	resp, err := l.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+
+	if resp.ContentLength > maxSourceSize {
+		return fmt.Errorf("the source is larger than %d bytes", maxSourceSize)
+	}
+
+	// Reads one more byte than the maximum size, to detect the larger sources rather than truncating them.
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxSourceSize+1))
+	if err != nil {
+		return err
+	}
+
+	if len(body) > maxSourceSize {
+		return fmt.Errorf("the source is larger than %d bytes", maxSourceSize)
+	}
+
+	return parse(bytes.NewReader(body))
+}
+
+// parseSource parses a list of IPs and CIDR ranges, one per line.
+// The comments, starting with # or ;, and the text following the IP or range on a line, are ignored.
+func parseSource(reader io.Reader, ranges *[]ipRange) error {
+	scanner := bufio.NewScanner(reader)
+
+	var lineNumber int
+	for scanner.Scan() {
+		lineNumber++
+
+		line := scanner.Text()
+		if i := strings.IndexAny(line, "#;"); i >= 0 {
+			line = line[:i]
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+
+		if addr := net.ParseIP(fields[0]); addr != nil {
+			*ranges = append(*ranges, ipRange{first: addr.To16(), last: addr.To16()})
+			continue
+		}
+
+		_, ipNet, err := net.ParseCIDR(fields[0])
+		if err != nil {
+			return fmt.Errorf("line %d: invalid IP or CIDR range %q", lineNumber, fields[0])
+		}
+		*ranges = append(*ranges, netRange(ipNet))
+	}
+
+	return scanner.Err()
+}
+
+// ipRange is a range of IPs, in their 16-byte form, from the first to the last IP inclusive.
+type ipRange struct {
+	first net.IP
+	last  net.IP
+}
+
+// netRange returns the range of the IPs of a CIDR range.
+func netRange(ipNet *net.IPNet) ipRange {
+	ip := ipNet.IP.To16()
+
+	// The mask of an IPv4 range is extended to the 16-byte form of the IPv4 addresses.
+	mask := ipNet.Mask
+	if len(mask) == net.IPv4len {
+		mask = append(net.CIDRMask(96, 8*net.IPv6len)[:12:12], mask...)
+	}
+
+	first := make(net.IP, net.IPv6len)
+	last := make(net.IP, net.IPv6len)
+	for i := range ip {
+		first[i] = ip[i] & mask[i]
+		last[i] = ip[i] | ^mask[i]
+	}
+
+	return ipRange{first: first, last: last}
+}
+
+// mergeRanges sorts the ranges, and merges the overlapping ones.
+func mergeRanges(ranges []ipRange) []ipRange {
+	sort.Slice(ranges, func(i, j int) bool {
+		return bytes.Compare(ranges[i].first, ranges[j].first) < 0
+	})
+
+	var merged []ipRange
+	for _, r := range ranges {
+		if n := len(merged); n > 0 && bytes.Compare(r.first, merged[n-1].last) <= 0 {
+			if bytes.Compare(r.last, merged[n-1].last) > 0 {
+				merged[n-1].last = r.last
+			}
+			continue
+		}
+
+		merged = append(merged, r)
+	}
+
+	return merged
+}
@@ -0,0 +1,248 @@
+package ip
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSource(t *testing.T) {
+	testCases := []struct {
+		desc           string
+		source         string
+		expectedRanges []string
+		expectedError  bool
+	}{
+		{
+			desc:   "empty",
+			source: "",
+		},
+		{
+			desc:   "IPs and ranges",
+			source: "1.2.3.4\n10.0.0.0/8\n2001:db8::1\n2001:db8:1::/48\n",
+			expectedRanges: []string{
+				"1.2.3.4-1.2.3.4",
+				"10.0.0.0-10.255.255.255",
+				"2001:db8::1-2001:db8::1",
+				"2001:db8:1::-2001:db8:1:ffff:ffff:ffff:ffff:ffff",
+			},
+		},
+		{
+			desc:           "comments",
+			source:         "# Denied IPs\n\n1.2.3.4 # scanner\n  10.0.0.0/8 ; SBL123\n; end\n",
+			expectedRanges: []string{"1.2.3.4-1.2.3.4", "10.0.0.0-10.255.255.255"},
+		},
+		{
+			desc:          "invalid IP",
+			source:        "1.2.3.4\nfoo\n",
+			expectedError: true,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			var ranges []ipRange
+
+			err := parseSource(strings.NewReader(test.source), &ranges)
+			if test.expectedError {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			assert.Equal(t, test.expectedRanges, rangeStrings(ranges))
+		})
+	}
+}
+
+func TestMergeRanges(t *testing.T) {
+	var ranges []ipRange
+	err := parseSource(strings.NewReader("10.1.0.0/16\n10.0.0.0/8\n1.2.3.4\n1.2.3.0/30\n1.2.3.3\n1.2.3.1\n192.168.0.0/24\n::1\n"), &ranges)
+	require.NoError(t, err)
+
+	expected := []string{
+		"::1-::1",
+		"1.2.3.0-1.2.3.3",
+		"1.2.3.4-1.2.3.4",
+		"10.0.0.0-10.255.255.255",
+		"192.168.0.0-192.168.0.255",
+	}
+	assert.Equal(t, expected, rangeStrings(mergeRanges(ranges)))
+}
+
+func TestSourceList_ContainsIP(t *testing.T) {
+	var ranges []ipRange
+	err := parseSource(strings.NewReader("1.2.3.4\n10.0.0.0/8\n2001:db8:1::/48\n"), &ranges)
+	require.NoError(t, err)
+
+	list := &SourceList{ranges: mergeRanges(ranges), refreshInterval: time.Hour, refreshedAt: time.Now()}
+
+	testCases := []struct {
+		ip       string
+		expected bool
+	}{
+		{ip: "1.2.3.4", expected: true},
+		{ip: "1.2.3.5"},
+		{ip: "1.2.3.3"},
+		{ip: "10.0.0.0", expected: true},
+		{ip: "10.255.255.255", expected: true},
+		{ip: "11.0.0.0"},
+		{ip: "9.255.255.255"},
+		{ip: "0.0.0.0"},
+		{ip: "::ffff:10.1.2.3", expected: true},
+		{ip: "2001:db8:1:2::1", expected: true},
+		{ip: "2001:db8:2::1"},
+		{ip: "::1"},
+	}
+
+	for _, test := range testCases {
+		assert.Equal(t, test.expected, list.ContainsIP(context.Background(), net.ParseIP(test.ip)), test.ip)
+	}
+}
+
+func TestSourceLists_Get(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "denied.txt")
+	err := os.WriteFile(file, []byte("1.2.3.4\n"), 0o600)
+	require.NoError(t, err)
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		_, _ = rw.Write([]byte("10.0.0.0/8\n"))
+	}))
+	t.Cleanup(server.Close)
+
+	sourceLists := NewSourceLists()
+
+	list, err := sourceLists.Get(context.Background(), []string{file, server.URL}, time.Hour)
+	require.NoError(t, err)
+
+	// The sources are loaded in the background.
+	assert.Eventually(t, func() bool {
+		return list.ContainsIP(context.Background(), net.ParseIP("1.2.3.4"))
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.True(t, list.ContainsIP(context.Background(), net.ParseIP("10.1.2.3")))
+	assert.False(t, list.ContainsIP(context.Background(), net.ParseIP("1.2.3.5")))
+
+	// The lists are shared.
+	other, err := sourceLists.Get(context.Background(), []string{file, server.URL}, time.Hour)
+	require.NoError(t, err)
+	assert.Same(t, list, other)
+
+	// The sources which cannot be loaded give an empty list.
+	missing, err := sourceLists.Get(context.Background(), []string{filepath.Join(t.TempDir(), "missing.txt")}, time.Hour)
+	require.NoError(t, err)
+	assert.False(t, missing.ContainsIP(context.Background(), net.ParseIP("1.2.3.4")))
+
+	_, err = sourceLists.Get(context.Background(), []string{file}, 0)
+	assert.Error(t, err)
+
+	// The lists not gotten since the previous sweep are forgotten.
+	sourceLists.Sweep()
+	sourceLists.Sweep()
+
+	other, err = sourceLists.Get(context.Background(), []string{file, server.URL}, time.Hour)
+	require.NoError(t, err)
+	assert.NotSame(t, list, other)
+}
+
+func TestSourceList_refresh(t *testing.T) {
+	var mu sync.Mutex
+	status := http.StatusOK
+	content := "1.2.3.4\n"
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		rw.WriteHeader(status)
+		_, _ = rw.Write([]byte(content))
+	}))
+	t.Cleanup(server.Close)
+
+	list, err := NewSourceLists().Get(context.Background(), []string{server.URL}, 10*time.Millisecond)
+	require.NoError(t, err)
+
+	assert.Eventually(t, func() bool {
+		return list.ContainsIP(context.Background(), net.ParseIP("1.2.3.4"))
+	}, 5*time.Second, 10*time.Millisecond)
+
+	mu.Lock()
+	content = "5.6.7.8\n"
+	mu.Unlock()
+
+	assert.Eventually(t, func() bool {
+		return list.ContainsIP(context.Background(), net.ParseIP("5.6.7.8"))
+	}, 5*time.Second, 20*time.Millisecond)
+	assert.False(t, list.ContainsIP(context.Background(), net.ParseIP("1.2.3.4")))
+
+	// The previous IPs are kept when the sources cannot be reloaded.
+	mu.Lock()
+	status = http.StatusInternalServerError
+	mu.Unlock()
+
+	time.Sleep(100 * time.Millisecond)
+	assert.True(t, list.ContainsIP(context.Background(), net.ParseIP("5.6.7.8")))
+}
+
+func TestSourceList_firstLoadFailure(t *testing.T) {
+	var mu sync.Mutex
+	status := http.StatusInternalServerError
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		rw.WriteHeader(status)
+		_, _ = rw.Write([]byte("1.2.3.4\n"))
+	}))
+	t.Cleanup(server.Close)
+
+	list, err := NewSourceLists().Get(context.Background(), []string{server.URL}, 10*time.Millisecond)
+	require.NoError(t, err)
+
+	// The list is empty until the sources are loaded.
+	time.Sleep(50 * time.Millisecond)
+	assert.False(t, list.ContainsIP(context.Background(), net.ParseIP("1.2.3.4")))
+
+	mu.Lock()
+	status = http.StatusOK
+	mu.Unlock()
+
+	assert.Eventually(t, func() bool {
+		return list.ContainsIP(context.Background(), net.ParseIP("1.2.3.4"))
+	}, 5*time.Second, 20*time.Millisecond)
+}
+
+func TestSourceList_load_tooLarge(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		// Streams the content, without Content-Length header.
+		rw.(http.Flusher).Flush()
+		_, _ = rw.Write([]byte(strings.Repeat("#", maxSourceSize) + "\n1.2.3.4\n"))
+	}))
+	t.Cleanup(server.Close)
+
+	list := &SourceList{sources: []string{server.URL}, client: http.DefaultClient}
+
+	_, err := list.load(context.Background())
+	assert.Error(t, err)
+}
+
+func rangeStrings(ranges []ipRange) []string {
+	var values []string
+	for _, r := range ranges {
+		values = append(values, r.first.String()+"-"+r.last.String())
+	}
+	return values
+}
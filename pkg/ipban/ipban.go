@@ -0,0 +1,397 @@
+package ipban
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/mailgun/ttlmap"
+	"github.com/traefik/traefik/v2/pkg/log"
+	mc "github.com/traefik/traefik/v2/pkg/memcached"
+	"github.com/traefik/traefik/v2/pkg/middlewares"
+)
+
+const (
+	// StoreMemory is the store keeping the bans in the memory of the Traefik instance.
+	StoreMemory = "memory"
+	// StoreMemcached is the store sharing the bans between the Traefik instances through memcached.
+	StoreMemcached = "memcached"
+)
+
+const (
+	// clearedBanTTL is how long a cleared ban is kept in memcached, to override the ban for the other instances.
+	clearedBanTTL = time.Second
+
+	// notBannedTTL is how long the IPs not banned in memcached are not looked up again,
+	// the bans of the other instances being applied after this delay at most.
+	notBannedTTL = time.Second
+	// maxNotBannedIPs is the maximum number of IPs remembered as not banned in memcached.
+	maxNotBannedIPs = 100000
+	// maxCountedIPs is the maximum number of IPs whose responses are counted at the same time, for each ban list.
+	maxCountedIPs = 65536
+)
+
+// Ban is an IP banned from a ban list.
+type Ban struct {
+	List     string    `json:"list"`
+	IP       string    `json:"ip"`
+	Reason   string    `json:"reason,omitempty"`
+	BannedAt time.Time `json:"bannedAt"`
+	Until    time.Time `json:"until"`
+}
+
+func (b Ban) active(now time.Time) bool {
+	return now.Before(b.Until)
+}
+
+// counterStore stores the counters of the responses of the IPs of the shared lists.
+type counterStore interface {
+	Increment(ctx context.Context, key string, ttl time.Duration) (uint64, error)
+	Delete(ctx context.Context, key string) error
+}
+
+// counter counts the responses of an IP, within a window.
+type counter struct {
+	count int
+	end   time.Time
+}
+
+// Manager holds the ban lists, shared by the deny list middlewares across the configuration changes.
+type Manager struct {
+	mh       middlewares.IMemcachedHandler[Ban]
+	counters counterStore
+	// notBanned remembers the IPs not banned in memcached, so that the requests of an IP do not all look it up.
+	notBanned *ttlmap.TtlMap
+
+	mu    sync.RWMutex
+	lists map[string]map[string]Ban
+
+	// localCounters holds the counters of the responses of the IPs, by ban list.
+	localCountersMu sync.Mutex
+	localCounters   map[string]*ttlmap.TtlMap
+}
+
+// NewManager creates a Manager, which shares the bans through memcached if a client is given.
+func NewManager(memcached *mc.Client) *Manager {
+	m := &Manager{
+		lists:         make(map[string]map[string]Ban),
+		localCounters: make(map[string]*ttlmap.TtlMap),
+	}
+
+	// The handler is only set when the client exists, as a nil handler would be a non-nil interface.
+	if memcached != nil {
+		handler := mc.NewMemcachedHandler[Ban](memcached)
+		m.mh = handler
+		m.counters = handler
+		// The capacity is positive, so the creation cannot fail.
+		m.notBanned, _ = ttlmap.NewConcurrent(maxNotBannedIPs)
+	}
+
+	return m
+}
+
+// List returns a ban list, stored in the given store.
+func (m *Manager) List(name, store string) (*List, error) {
+	if name == "" {
+		return nil, errors.New("the ban list name is empty")
+	}
+
+	switch store {
+	case "", StoreMemory:
+		return &List{manager: m, name: name}, nil
+
+	case StoreMemcached:
+		if m.mh == nil {
+			return nil, errors.New("the bans cannot be stored in memcached, as memcached is not configured")
+		}
+		return &List{manager: m, name: name, shared: true}, nil
+
+	default:
+		return nil, fmt.Errorf("unknown ban store %q", store)
+	}
+}
+
+// Bans returns the active bans known by the Traefik instance, sorted by list and IP.
+func (m *Manager) Bans() []Ban {
+	now := time.Now()
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var bans []Ban
+	for _, list := range m.lists {
+		for _, ban := range list {
+			if ban.active(now) {
+				bans = append(bans, ban)
+			}
+		}
+	}
+
+	sort.Slice(bans, func(i, j int) bool {
+		if bans[i].List != bans[j].List {
+			return bans[i].List < bans[j].List
+		}
+		return bans[i].IP < bans[j].IP
+	})
+
+	return bans
+}
+
+// Clear clears the ban of an IP from a list, or of all the IPs of the list if the IP is empty,
+// and returns the number of cleared bans known by the Traefik instance.
+func (m *Manager) Clear(ctx context.Context, list, ip string) int {
+	m.mu.Lock()
+
+	var ips []string
+	if ip != "" {
+		ips = []string{ip}
+	} else {
+		for bannedIP := range m.lists[list] {
+			ips = append(ips, bannedIP)
+		}
+	}
+
+	now := time.Now()
+
+	var cleared int
+	for _, clearedIP := range ips {
+		if ban, ok := m.lists[list][clearedIP]; ok {
+			if ban.active(now) {
+				cleared++
+			}
+			delete(m.lists[list], clearedIP)
+		}
+	}
+
+	if len(m.lists[list]) == 0 {
+		delete(m.lists, list)
+	}
+
+	m.mu.Unlock()
+
+	if m.mh == nil {
+		return cleared
+	}
+
+	// The bans are overridden in memcached, which does not support deleting the keys.
+	for _, clearedIP := range ips {
+		ban := Ban{List: list, IP: clearedIP, Until: now}
+		if err := m.mh.Set(ctx, key(list, clearedIP), ban, clearedBanTTL); err != nil {
+			log.FromContext(ctx).Errorf("Unable to clear the ban of %s from the ban list %s in memcached: %v", clearedIP, list, err)
+		}
+	}
+
+	return cleared
+}
+
+func (m *Manager) get(list, ip string) (Ban, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	ban, ok := m.lists[list][ip]
+	return ban, ok
+}
+
+func (m *Manager) set(ban Ban) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+
+	bans, ok := m.lists[ban.List]
+	if !ok {
+		bans = make(map[string]Ban)
+		m.lists[ban.List] = bans
+	}
+
+	// The expired bans of the list are removed, so that the lists do not grow forever.
+	for ip, b := range bans {
+		if !b.active(now) {
+			delete(bans, ip)
+		}
+	}
+
+	bans[ban.IP] = ban
+}
+
+// count counts a response of an IP for a list, and returns the number of responses counted within the window.
+func (m *Manager) count(list, ip string, window time.Duration) int {
+	m.localCountersMu.Lock()
+	defer m.localCountersMu.Unlock()
+
+	counters, ok := m.localCounters[list]
+	if !ok {
+		// The capacity is positive, so the creation cannot fail.
+		counters, _ = ttlmap.NewMap(maxCountedIPs)
+		m.localCounters[list] = counters
+	}
+
+	now := time.Now()
+
+	value, ok := counters.Get(ip)
+	if c, isCounter := value.(*counter); ok && isCounter && now.Before(c.end) {
+		c.count++
+		return c.count
+	}
+
+	// The window is rounded up to a second, as the TTLs of the map are in seconds.
+	ttl := int((window + time.Second - 1) / time.Second)
+	if err := counters.Set(ip, &counter{count: 1, end: now.Add(window)}, ttl); err != nil {
+		log.WithoutContext().Errorf("Unable to count the response of %s for the ban list %s: %v", ip, list, err)
+	}
+
+	return 1
+}
+
+func (m *Manager) resetCount(list, ip string) {
+	m.localCountersMu.Lock()
+	defer m.localCountersMu.Unlock()
+
+	counters, ok := m.localCounters[list]
+	if !ok {
+		return
+	}
+
+	if value, ok := counters.Get(ip); ok {
+		if c, isCounter := value.(*counter); isCounter {
+			c.count = 0
+		}
+	}
+}
+
+func (m *Manager) remove(list, ip string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.lists[list], ip)
+	if len(m.lists[list]) == 0 {
+		delete(m.lists, list)
+	}
+}
+
+// List is a named ban list.
+type List struct {
+	manager *Manager
+	name    string
+	// shared defines whether the bans are shared through memcached.
+	shared bool
+}
+
+// Ban bans an IP for the given duration.
+func (l *List) Ban(ctx context.Context, ip string, duration time.Duration, reason string) {
+	now := time.Now()
+
+	ban := Ban{
+		List:     l.name,
+		IP:       ip,
+		Reason:   reason,
+		BannedAt: now,
+		Until:    now.Add(duration),
+	}
+
+	l.manager.set(ban)
+
+	if !l.shared {
+		return
+	}
+
+	if err := l.manager.mh.Set(ctx, key(l.name, ip), ban, duration); err != nil {
+		log.FromContext(ctx).Errorf("Unable to share the ban of %s from the ban list %s in memcached: %v", ip, l.name, err)
+	}
+}
+
+// Count counts a response of an IP, and returns the number of responses counted within the window.
+// The responses are counted by ban list, so that the counts are kept across the configuration changes,
+// and are counted in memcached for the shared lists, so that the responses served by all the Traefik instances are counted.
+func (l *List) Count(ctx context.Context, ip string, window time.Duration) int {
+	if l.shared {
+		count, err := l.manager.counters.Increment(ctx, counterKey(l.name, ip), window)
+		if err == nil {
+			return int(count)
+		}
+
+		log.FromContext(ctx).Errorf("Unable to count the response of %s for the ban list %s in memcached, the response is counted locally: %v", ip, l.name, err)
+	}
+
+	return l.manager.count(l.name, ip, window)
+}
+
+// ResetCount resets the count of the responses of an IP, once it is banned.
+func (l *List) ResetCount(ctx context.Context, ip string) {
+	if l.shared {
+		if err := l.manager.counters.Delete(ctx, counterKey(l.name, ip)); err != nil {
+			log.FromContext(ctx).Errorf("Unable to reset the count of the responses of %s for the ban list %s in memcached: %v", ip, l.name, err)
+		}
+	}
+
+	l.manager.resetCount(l.name, ip)
+}
+
+// IsBanned returns whether an IP is banned.
+// The bans of the shared lists are looked up in memcached, so that the bans and their clearing by any Traefik instance apply.
+func (l *List) IsBanned(ctx context.Context, ip string) bool {
+	now := time.Now()
+
+	if !l.shared {
+		ban, ok := l.manager.get(l.name, ip)
+		return ok && ban.active(now)
+	}
+
+	banKey := key(l.name, ip)
+
+	// The IPs recently not banned in memcached are only looked up in the local bans,
+	// which hold the bans of the Traefik instance.
+	if _, ok := l.manager.notBanned.Get(banKey); ok {
+		localBan, ok := l.manager.get(l.name, ip)
+		return ok && localBan.active(now)
+	}
+
+	var ban Ban
+	err := l.manager.mh.Get(ctx, banKey, &ban)
+	if err != nil {
+		if !errors.As(err, &mc.ErrKeyNotFound{}) {
+			log.FromContext(ctx).Errorf("Unable to get the ban of %s from the ban list %s in memcached, the local bans are used: %v", ip, l.name, err)
+
+			localBan, ok := l.manager.get(l.name, ip)
+			return ok && localBan.active(now)
+		}
+
+		l.manager.remove(l.name, ip)
+
+		if err := l.manager.notBanned.Set(banKey, struct{}{}, int(notBannedTTL.Seconds())); err != nil {
+			log.FromContext(ctx).Debugf("Unable to remember that %s is not banned from the ban list %s: %v", ip, l.name, err)
+		}
+
+		return false
+	}
+
+	if !ban.active(now) {
+		l.manager.remove(l.name, ip)
+		return false
+	}
+
+	// The bans of the other instances are recorded, so that they are listed by the API.
+	if localBan, ok := l.manager.get(l.name, ip); !ok || !localBan.Until.Equal(ban.Until) {
+		l.manager.set(ban)
+	}
+
+	return true
+}
+
+// key returns the memcached key of the ban of an IP from a list.
+// The list name is hashed, as the memcached keys cannot contain spaces and control characters.
+func key(list, ip string) string {
+	hash := sha256.Sum256([]byte(list))
+	return "traefik-ipban-" + hex.EncodeToString(hash[:8]) + "-" + ip
+}
+
+// counterKey returns the memcached key of the counter of the responses of an IP for a list.
+func counterKey(list, ip string) string {
+	hash := sha256.Sum256([]byte(list))
+	return "traefik-ipban-count-" + hex.EncodeToString(hash[:8]) + "-" + ip
+}
@@ -0,0 +1,211 @@
+package ipban
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/mailgun/ttlmap"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	mc "github.com/traefik/traefik/v2/pkg/memcached"
+)
+
+func TestManager_List(t *testing.T) {
+	testCases := []struct {
+		desc          string
+		name          string
+		store         string
+		expectedError bool
+	}{
+		{
+			desc:  "default store",
+			name:  "login",
+			store: "",
+		},
+		{
+			desc:  "memory store",
+			name:  "login",
+			store: StoreMemory,
+		},
+		{
+			desc:          "memcached store without memcached",
+			name:          "login",
+			store:         StoreMemcached,
+			expectedError: true,
+		},
+		{
+			desc:          "unknown store",
+			name:          "login",
+			store:         "foo",
+			expectedError: true,
+		},
+		{
+			desc:          "empty name",
+			name:          "",
+			expectedError: true,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			list, err := NewManager(nil).List(test.name, test.store)
+			if test.expectedError {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.NotNil(t, list)
+		})
+	}
+}
+
+func TestManager_Bans(t *testing.T) {
+	manager := NewManager(nil)
+
+	login, err := manager.List("login", StoreMemory)
+	require.NoError(t, err)
+
+	admin, err := manager.List("admin", StoreMemory)
+	require.NoError(t, err)
+
+	login.Ban(context.Background(), "10.0.0.2", time.Hour, "too many failed logins")
+	login.Ban(context.Background(), "10.0.0.1", time.Hour, "too many failed logins")
+	login.Ban(context.Background(), "10.0.0.3", -time.Second, "expired")
+	admin.Ban(context.Background(), "10.0.0.1", time.Hour, "")
+
+	assert.True(t, login.IsBanned(context.Background(), "10.0.0.1"))
+	assert.True(t, admin.IsBanned(context.Background(), "10.0.0.1"))
+	assert.False(t, login.IsBanned(context.Background(), "10.0.0.3"))
+	assert.False(t, login.IsBanned(context.Background(), "10.0.0.4"))
+
+	bans := manager.Bans()
+	require.Len(t, bans, 3)
+	assert.Equal(t, Ban{List: "admin", IP: "10.0.0.1"}, Ban{List: bans[0].List, IP: bans[0].IP})
+	assert.Equal(t, Ban{List: "login", IP: "10.0.0.1"}, Ban{List: bans[1].List, IP: bans[1].IP})
+	assert.Equal(t, Ban{List: "login", IP: "10.0.0.2"}, Ban{List: bans[2].List, IP: bans[2].IP})
+	assert.Equal(t, "too many failed logins", bans[1].Reason)
+
+	assert.Equal(t, 1, manager.Clear(context.Background(), "login", "10.0.0.1"))
+	assert.False(t, login.IsBanned(context.Background(), "10.0.0.1"))
+	assert.True(t, admin.IsBanned(context.Background(), "10.0.0.1"))
+
+	assert.Equal(t, 1, manager.Clear(context.Background(), "login", ""))
+	assert.False(t, login.IsBanned(context.Background(), "10.0.0.2"))
+
+	assert.Equal(t, 0, manager.Clear(context.Background(), "unknown", ""))
+	assert.Len(t, manager.Bans(), 1)
+}
+
+func TestList_IsBanned_shared(t *testing.T) {
+	store := &fakeMemcached{bans: make(map[string]Ban)}
+
+	manager := NewManager(nil)
+	manager.mh = store
+	manager.notBanned, _ = ttlmap.NewConcurrent(maxNotBannedIPs)
+
+	login, err := manager.List("login", StoreMemcached)
+	require.NoError(t, err)
+
+	// The IPs not banned in memcached are not looked up again for a while.
+	assert.False(t, login.IsBanned(context.Background(), "10.0.0.1"))
+	assert.False(t, login.IsBanned(context.Background(), "10.0.0.1"))
+	assert.Equal(t, 1, store.gets)
+
+	// The bans of the instance apply immediately.
+	login.Ban(context.Background(), "10.0.0.1", time.Hour, "")
+	assert.True(t, login.IsBanned(context.Background(), "10.0.0.1"))
+
+	// The bans of the other instances are looked up in memcached.
+	store.bans[key("login", "10.0.0.2")] = Ban{List: "login", IP: "10.0.0.2", Until: time.Now().Add(time.Hour)}
+	assert.True(t, login.IsBanned(context.Background(), "10.0.0.2"))
+	assert.Len(t, manager.Bans(), 2)
+}
+
+func TestList_Count(t *testing.T) {
+	manager := NewManager(nil)
+
+	login, err := manager.List("login", StoreMemory)
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, login.Count(context.Background(), "10.0.0.1", time.Minute))
+	assert.Equal(t, 1, login.Count(context.Background(), "10.0.0.2", time.Minute))
+
+	// The counts are kept by the manager, for the lists of the same name.
+	login, err = manager.List("login", StoreMemory)
+	require.NoError(t, err)
+	assert.Equal(t, 2, login.Count(context.Background(), "10.0.0.1", time.Minute))
+
+	admin, err := manager.List("admin", StoreMemory)
+	require.NoError(t, err)
+	assert.Equal(t, 1, admin.Count(context.Background(), "10.0.0.1", time.Minute))
+
+	login.ResetCount(context.Background(), "10.0.0.1")
+	assert.Equal(t, 1, login.Count(context.Background(), "10.0.0.1", time.Minute))
+
+	// The counts are reset once the window has elapsed.
+	assert.Equal(t, 1, login.Count(context.Background(), "10.0.0.3", time.Nanosecond))
+	assert.Equal(t, 1, login.Count(context.Background(), "10.0.0.3", time.Nanosecond))
+}
+
+func TestList_Count_shared(t *testing.T) {
+	store := &fakeMemcached{bans: make(map[string]Ban), counters: make(map[string]uint64)}
+
+	manager := NewManager(nil)
+	manager.mh = store
+	manager.counters = store
+	manager.notBanned, _ = ttlmap.NewConcurrent(maxNotBannedIPs)
+
+	login, err := manager.List("login", StoreMemcached)
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, login.Count(context.Background(), "10.0.0.1", time.Minute))
+
+	// The responses counted by the other instances are counted.
+	store.counters[counterKey("login", "10.0.0.1")] += 2
+	assert.Equal(t, 4, login.Count(context.Background(), "10.0.0.1", time.Minute))
+
+	login.ResetCount(context.Background(), "10.0.0.1")
+	assert.Equal(t, 1, login.Count(context.Background(), "10.0.0.1", time.Minute))
+}
+
+type fakeMemcached struct {
+	bans     map[string]Ban
+	counters map[string]uint64
+	gets     int
+}
+
+func (f *fakeMemcached) Get(_ context.Context, key string, dst *Ban) error {
+	f.gets++
+
+	ban, ok := f.bans[key]
+	if !ok {
+		return mc.ErrKeyNotFound{}
+	}
+
+	*dst = ban
+	return nil
+}
+
+func (f *fakeMemcached) Set(_ context.Context, key string, item Ban, _ time.Duration) error {
+	f.bans[key] = item
+	return nil
+}
+
+func (f *fakeMemcached) Increment(_ context.Context, key string, _ time.Duration) (uint64, error) {
+	f.counters[key]++
+	return f.counters[key], nil
+}
+
+func (f *fakeMemcached) Delete(_ context.Context, key string) error {
+	delete(f.counters, key)
+	return nil
+}
+
+func (f *fakeMemcached) Ping() error {
+	return nil
+}
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"encoding/gob"
+	"errors"
 	"github.com/bradfitz/gomemcache/memcache"
 	"go.skia.org/infra/go/reconnectingmemcached"
 	"time"
@@ -11,6 +12,7 @@ import (
 
 type handler[K any] struct {
 	client reconnectingmemcached.Client
+	direct *memcache.Client
 }
 
 func NewMemcachedHandler[K any](client *Client) *handler[K] {
@@ -19,6 +21,7 @@ func NewMemcachedHandler[K any](client *Client) *handler[K] {
 	}
 	return &handler[K]{
 		client: client.client,
+		direct: client.direct,
 	}
 }
 
@@ -59,7 +62,7 @@ func (c *handler[K]) Set(ctx context.Context, key string, item K, ttl time.Durat
 	ok := c.client.Set(&memcache.Item{
 		Key:        key,
 		Value:      buf.Bytes(),
-		Expiration: int32(ttl.Seconds()),
+		Expiration: expiration(ttl),
 	})
 	if !ok {
 		return ErrUnknown
@@ -68,6 +71,89 @@ func (c *handler[K]) Set(ctx context.Context, key string, item K, ttl time.Durat
 	return nil
 }
 
+// Add stores the item only if the key does not exist yet, and returns whether it was stored.
+// Unlike Set, it never overwrites the item stored by another instance.
+func (c *handler[K]) Add(ctx context.Context, key string, item K, ttl time.Duration) (bool, error) {
+	if c == nil {
+		return false, ErrMemcachedNotinitialized
+	}
+
+	buf := new(bytes.Buffer)
+	if err := gob.NewEncoder(buf).Encode(item); err != nil {
+		return false, err
+	}
+
+	err := c.direct.Add(&memcache.Item{
+		Key:        key,
+		Value:      buf.Bytes(),
+		Expiration: expiration(ttl),
+	})
+	if errors.Is(err, memcache.ErrNotStored) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// Increment increments the counter of the key, created with the given TTL if it does not exist yet,
+// and returns its new value.
+// The counters are stored as decimal numbers, as memcached requires, rather than encoded as the other items.
+func (c *handler[K]) Increment(ctx context.Context, key string, ttl time.Duration) (uint64, error) {
+	if c == nil {
+		return 0, ErrMemcachedNotinitialized
+	}
+
+	value, err := c.direct.Increment(key, 1)
+	if !errors.Is(err, memcache.ErrCacheMiss) {
+		return value, err
+	}
+
+	err = c.direct.Add(&memcache.Item{
+		Key:        key,
+		Value:      []byte("1"),
+		Expiration: expiration(ttl),
+	})
+	if errors.Is(err, memcache.ErrNotStored) {
+		// Another instance created the counter in the meantime.
+		return c.direct.Increment(key, 1)
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	return 1, nil
+}
+
+// Delete deletes the item of the key, if any.
+func (c *handler[K]) Delete(ctx context.Context, key string) error {
+	if c == nil {
+		return ErrMemcachedNotinitialized
+	}
+
+	err := c.direct.Delete(key)
+	if errors.Is(err, memcache.ErrCacheMiss) {
+		return nil
+	}
+
+	return err
+}
+
+// maxRelativeExpiration is the longest expiration that memcached reads as a number of seconds,
+// the longer ones being read as a Unix time.
+const maxRelativeExpiration = 30 * 24 * time.Hour
+
+// expiration returns the memcached expiration of an item kept for the given duration.
+func expiration(ttl time.Duration) int32 {
+	if ttl > maxRelativeExpiration {
+		return int32(time.Now().Add(ttl).Unix())
+	}
+
+	return int32(ttl.Seconds())
+}
+
 func (c *handler[K]) Ping() error {
 	if c == nil {
 		return ErrMemcachedNotinitialized
@@ -0,0 +1,17 @@
+package memcached
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExpiration(t *testing.T) {
+	assert.Equal(t, int32(90), expiration(90*time.Second))
+	assert.Equal(t, int32(2592000), expiration(30*24*time.Hour))
+
+	// The expirations longer than 30 days are Unix times.
+	expected := time.Now().Add(31 * 24 * time.Hour).Unix()
+	assert.InDelta(t, expected, expiration(31*24*time.Hour), 1)
+}
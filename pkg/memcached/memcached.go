@@ -1,12 +1,15 @@
 package memcached
 
 import (
+	"github.com/bradfitz/gomemcache/memcache"
 	"github.com/traefik/traefik/v2/pkg/config/static"
 	"go.skia.org/infra/go/reconnectingmemcached"
 )
 
 type Client struct {
 	client reconnectingmemcached.Client
+	// direct is used for the operations that the reconnecting client does not support, such as Add.
+	direct *memcache.Client
 }
 
 func NewMemcachedClient(conf *static.Memcached) *Client {
@@ -20,5 +23,6 @@ func NewMemcachedClient(conf *static.Memcached) *Client {
 	})
 	return &Client{
 		client: c,
+		direct: memcache.New(conf.Address),
 	}
 }
@@ -23,6 +23,9 @@ const (
 	ddLastConfigReloadSuccessName   = "config.reload.lastSuccessTimestamp"
 	ddLastConfigReloadFailureName   = "config.reload.lastFailureTimestamp"
 	ddTLSCertsNotAfterTimestampName = "tls.certs.notAfterTimestamp"
+	ddTLSCertsRenewalsName          = "tls.certs.renewal.total"
+	ddWAFRuleMatchesName            = "waf.rule.matches.total"
+	ddWAFBlockedRequestsName        = "waf.blocked.requests.total"
 
 	ddEntryPointReqsName        = "entrypoint.request.total"
 	ddEntryPointReqsTLSName     = "entrypoint.request.tls.total"
@@ -65,6 +68,10 @@ func RegisterDatadog(ctx context.Context, config *types.Datadog) Registry {
 		lastConfigReloadSuccessGauge:   datadogClient.NewGauge(ddLastConfigReloadSuccessName),
 		lastConfigReloadFailureGauge:   datadogClient.NewGauge(ddLastConfigReloadFailureName),
 		tlsCertsNotAfterTimestampGauge: datadogClient.NewGauge(ddTLSCertsNotAfterTimestampName),
+		tlsCertsRenewalsCounter:        datadogClient.NewCounter(ddTLSCertsRenewalsName, 1.0),
+		tlsCertsRenewalsFailureCounter: datadogClient.NewCounter(ddTLSCertsRenewalsName, 1.0).With(ddConfigReloadsFailureTagName, "true"),
+		wafRuleMatchesCounter:          datadogClient.NewCounter(ddWAFRuleMatchesName, 1.0),
+		wafBlockedRequestsCounter:      datadogClient.NewCounter(ddWAFBlockedRequestsName, 1.0),
 	}
 
 	if config.AddEntryPointsLabels {
@@ -50,6 +50,11 @@ func testDatadogRegistry(t *testing.T, metricsPrefix string, datadogRegistry Reg
 		metricsPrefix + ".config.reload.lastFailureTimestamp:1.000000|g\n",
 
 		metricsPrefix + ".tls.certs.notAfterTimestamp:1.000000|g|#key:value\n",
+		metricsPrefix + ".tls.certs.renewal.total:1.000000|c|#resolver:foo\n",
+		metricsPrefix + ".tls.certs.renewal.total:1.000000|c|#failure:true,resolver:foo\n",
+
+		metricsPrefix + ".waf.rule.matches.total:1.000000|c|#middleware:waf,rule:942100\n",
+		metricsPrefix + ".waf.blocked.requests.total:1.000000|c|#middleware:waf\n",
 
 		metricsPrefix + ".entrypoint.request.total:1.000000|c|#entrypoint:test\n",
 		metricsPrefix + ".entrypoint.request.tls.total:1.000000|c|#entrypoint:test,tls_version:foo,tls_cipher:bar\n",
@@ -79,6 +84,11 @@ func testDatadogRegistry(t *testing.T, metricsPrefix string, datadogRegistry Reg
 		datadogRegistry.LastConfigReloadFailureGauge().Add(1)
 
 		datadogRegistry.TLSCertsNotAfterTimestampGauge().With("key", "value").Set(1)
+		datadogRegistry.TLSCertsRenewalsCounter().With("resolver", "foo").Add(1)
+		datadogRegistry.TLSCertsRenewalsFailureCounter().With("resolver", "foo").Add(1)
+
+		datadogRegistry.WAFRuleMatchesCounter().With("middleware", "waf", "rule", "942100").Add(1)
+		datadogRegistry.WAFBlockedRequestsCounter().With("middleware", "waf").Add(1)
 
 		datadogRegistry.EntryPointReqsCounter().With("entrypoint", "test").Add(1)
 		datadogRegistry.EntryPointReqsTLSCounter().With("entrypoint", "test", "tls_version", "foo", "tls_cipher", "bar").Add(1)
@@ -28,6 +28,11 @@ const (
 	influxDBLastConfigReloadFailureName = "traefik.config.reload.lastFailureTimestamp"
 
 	influxDBTLSCertsNotAfterTimestampName = "traefik.tls.certs.notAfterTimestamp"
+	influxDBTLSCertsRenewalsName          = "traefik.tls.certs.renewal.total"
+	influxDBTLSCertsRenewalsFailureName   = influxDBTLSCertsRenewalsName + ".failure"
+
+	influxDBWAFRuleMatchesName     = "traefik.waf.rule.matches.total"
+	influxDBWAFBlockedRequestsName = "traefik.waf.blocked.requests.total"
 
 	influxDBEntryPointReqsName        = "traefik.entrypoint.requests.total"
 	influxDBEntryPointReqsTLSName     = "traefik.entrypoint.requests.tls.total"
@@ -67,6 +72,10 @@ func RegisterInfluxDB(ctx context.Context, config *types.InfluxDB) Registry {
 		lastConfigReloadSuccessGauge:   influxDBClient.NewGauge(influxDBLastConfigReloadSuccessName),
 		lastConfigReloadFailureGauge:   influxDBClient.NewGauge(influxDBLastConfigReloadFailureName),
 		tlsCertsNotAfterTimestampGauge: influxDBClient.NewGauge(influxDBTLSCertsNotAfterTimestampName),
+		tlsCertsRenewalsCounter:        influxDBClient.NewCounter(influxDBTLSCertsRenewalsName),
+		tlsCertsRenewalsFailureCounter: influxDBClient.NewCounter(influxDBTLSCertsRenewalsFailureName),
+		wafRuleMatchesCounter:          influxDBClient.NewCounter(influxDBWAFRuleMatchesName),
+		wafBlockedRequestsCounter:      influxDBClient.NewCounter(influxDBWAFBlockedRequestsName),
 	}
 
 	if config.AddEntryPointsLabels {
@@ -57,6 +57,10 @@ func RegisterInfluxDB2(ctx context.Context, config *types.InfluxDB2) Registry {
 		lastConfigReloadSuccessGauge:   influxDB2Store.NewGauge(influxDBLastConfigReloadSuccessName),
 		lastConfigReloadFailureGauge:   influxDB2Store.NewGauge(influxDBLastConfigReloadFailureName),
 		tlsCertsNotAfterTimestampGauge: influxDB2Store.NewGauge(influxDBTLSCertsNotAfterTimestampName),
+		tlsCertsRenewalsCounter:        influxDB2Store.NewCounter(influxDBTLSCertsRenewalsName),
+		tlsCertsRenewalsFailureCounter: influxDB2Store.NewCounter(influxDBTLSCertsRenewalsFailureName),
+		wafRuleMatchesCounter:          influxDB2Store.NewCounter(influxDBWAFRuleMatchesName),
+		wafBlockedRequestsCounter:      influxDB2Store.NewCounter(influxDBWAFBlockedRequestsName),
 	}
 
 	if config.AddEntryPointsLabels {
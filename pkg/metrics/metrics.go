@@ -29,6 +29,13 @@ type Registry interface {
 	// TLS
 
 	TLSCertsNotAfterTimestampGauge() metrics.Gauge
+	TLSCertsRenewalsCounter() metrics.Counter
+	TLSCertsRenewalsFailureCounter() metrics.Counter
+
+	// WAF
+
+	WAFRuleMatchesCounter() metrics.Counter
+	WAFBlockedRequestsCounter() metrics.Counter
 
 	// entry point metrics
 
@@ -69,6 +76,10 @@ func NewMultiRegistry(registries []Registry) Registry {
 	var lastConfigReloadSuccessGauge []metrics.Gauge
 	var lastConfigReloadFailureGauge []metrics.Gauge
 	var tlsCertsNotAfterTimestampGauge []metrics.Gauge
+	var tlsCertsRenewalsCounter []metrics.Counter
+	var tlsCertsRenewalsFailureCounter []metrics.Counter
+	var wafRuleMatchesCounter []metrics.Counter
+	var wafBlockedRequestsCounter []metrics.Counter
 	var entryPointReqsCounter []metrics.Counter
 	var entryPointReqsTLSCounter []metrics.Counter
 	var entryPointReqDurationHistogram []ScalableHistogram
@@ -100,6 +111,18 @@ func NewMultiRegistry(registries []Registry) Registry {
 		if r.TLSCertsNotAfterTimestampGauge() != nil {
 			tlsCertsNotAfterTimestampGauge = append(tlsCertsNotAfterTimestampGauge, r.TLSCertsNotAfterTimestampGauge())
 		}
+		if r.TLSCertsRenewalsCounter() != nil {
+			tlsCertsRenewalsCounter = append(tlsCertsRenewalsCounter, r.TLSCertsRenewalsCounter())
+		}
+		if r.TLSCertsRenewalsFailureCounter() != nil {
+			tlsCertsRenewalsFailureCounter = append(tlsCertsRenewalsFailureCounter, r.TLSCertsRenewalsFailureCounter())
+		}
+		if r.WAFRuleMatchesCounter() != nil {
+			wafRuleMatchesCounter = append(wafRuleMatchesCounter, r.WAFRuleMatchesCounter())
+		}
+		if r.WAFBlockedRequestsCounter() != nil {
+			wafBlockedRequestsCounter = append(wafBlockedRequestsCounter, r.WAFBlockedRequestsCounter())
+		}
 		if r.EntryPointReqsCounter() != nil {
 			entryPointReqsCounter = append(entryPointReqsCounter, r.EntryPointReqsCounter())
 		}
@@ -153,6 +176,10 @@ func NewMultiRegistry(registries []Registry) Registry {
 		lastConfigReloadSuccessGauge:   multi.NewGauge(lastConfigReloadSuccessGauge...),
 		lastConfigReloadFailureGauge:   multi.NewGauge(lastConfigReloadFailureGauge...),
 		tlsCertsNotAfterTimestampGauge: multi.NewGauge(tlsCertsNotAfterTimestampGauge...),
+		tlsCertsRenewalsCounter:        multi.NewCounter(tlsCertsRenewalsCounter...),
+		tlsCertsRenewalsFailureCounter: multi.NewCounter(tlsCertsRenewalsFailureCounter...),
+		wafRuleMatchesCounter:          multi.NewCounter(wafRuleMatchesCounter...),
+		wafBlockedRequestsCounter:      multi.NewCounter(wafBlockedRequestsCounter...),
 		entryPointReqsCounter:          multi.NewCounter(entryPointReqsCounter...),
 		entryPointReqsTLSCounter:       multi.NewCounter(entryPointReqsTLSCounter...),
 		entryPointReqDurationHistogram: NewMultiHistogram(entryPointReqDurationHistogram...),
@@ -179,6 +206,10 @@ type standardRegistry struct {
 	lastConfigReloadSuccessGauge   metrics.Gauge
 	lastConfigReloadFailureGauge   metrics.Gauge
 	tlsCertsNotAfterTimestampGauge metrics.Gauge
+	tlsCertsRenewalsCounter        metrics.Counter
+	tlsCertsRenewalsFailureCounter metrics.Counter
+	wafRuleMatchesCounter          metrics.Counter
+	wafBlockedRequestsCounter      metrics.Counter
 	entryPointReqsCounter          metrics.Counter
 	entryPointReqsTLSCounter       metrics.Counter
 	entryPointReqDurationHistogram ScalableHistogram
@@ -227,6 +258,22 @@ func (r *standardRegistry) TLSCertsNotAfterTimestampGauge() metrics.Gauge {
 	return r.tlsCertsNotAfterTimestampGauge
 }
 
+func (r *standardRegistry) TLSCertsRenewalsCounter() metrics.Counter {
+	return r.tlsCertsRenewalsCounter
+}
+
+func (r *standardRegistry) TLSCertsRenewalsFailureCounter() metrics.Counter {
+	return r.tlsCertsRenewalsFailureCounter
+}
+
+func (r *standardRegistry) WAFRuleMatchesCounter() metrics.Counter {
+	return r.wafRuleMatchesCounter
+}
+
+func (r *standardRegistry) WAFBlockedRequestsCounter() metrics.Counter {
+	return r.wafBlockedRequestsCounter
+}
+
 func (r *standardRegistry) EntryPointReqsCounter() metrics.Counter {
 	return r.entryPointReqsCounter
 }
@@ -28,8 +28,15 @@ const (
 	configLastReloadFailureName    = metricConfigPrefix + "last_reload_failure"
 
 	// TLS.
-	metricsTLSPrefix          = MetricNamePrefix + "tls_"
-	tlsCertsNotAfterTimestamp = metricsTLSPrefix + "certs_not_after"
+	metricsTLSPrefix                  = MetricNamePrefix + "tls_"
+	tlsCertsNotAfterTimestamp         = metricsTLSPrefix + "certs_not_after"
+	tlsCertsRenewalsTotalName         = metricsTLSPrefix + "certs_renewals_total"
+	tlsCertsRenewalsFailuresTotalName = metricsTLSPrefix + "certs_renewals_failure_total"
+
+	// WAF.
+	metricsWAFPrefix            = MetricNamePrefix + "waf_"
+	wafRuleMatchesTotalName     = metricsWAFPrefix + "rule_matches_total"
+	wafBlockedRequestsTotalName = metricsWAFPrefix + "blocked_requests_total"
 
 	// entry point.
 	metricEntryPointPrefix     = MetricNamePrefix + "entrypoint_"
@@ -128,6 +135,22 @@ func initStandardRegistry(config *types.Prometheus) Registry {
 		Name: tlsCertsNotAfterTimestamp,
 		Help: "Certificate expiration timestamp",
 	}, []string{"cn", "serial", "sans"})
+	tlsCertsRenewals := newCounterFrom(stdprometheus.CounterOpts{
+		Name: tlsCertsRenewalsTotalName,
+		Help: "Certificate renewals, partitioned by certificate resolver.",
+	}, []string{"resolver"})
+	tlsCertsRenewalsFailures := newCounterFrom(stdprometheus.CounterOpts{
+		Name: tlsCertsRenewalsFailuresTotalName,
+		Help: "Certificate renewal failures, partitioned by certificate resolver.",
+	}, []string{"resolver"})
+	wafRuleMatches := newCounterFrom(stdprometheus.CounterOpts{
+		Name: wafRuleMatchesTotalName,
+		Help: "WAF rule matches, partitioned by middleware and rule.",
+	}, []string{"middleware", "rule"})
+	wafBlockedRequests := newCounterFrom(stdprometheus.CounterOpts{
+		Name: wafBlockedRequestsTotalName,
+		Help: "Requests blocked by the WAF, partitioned by middleware.",
+	}, []string{"middleware"})
 
 	promState.vectors = []vector{
 		configReloads.cv,
@@ -135,6 +158,10 @@ func initStandardRegistry(config *types.Prometheus) Registry {
 		lastConfigReloadSuccess.gv,
 		lastConfigReloadFailure.gv,
 		tlsCertsNotAfterTimestamp.gv,
+		tlsCertsRenewals.cv,
+		tlsCertsRenewalsFailures.cv,
+		wafRuleMatches.cv,
+		wafBlockedRequests.cv,
 	}
 
 	reg := &standardRegistry{
@@ -146,6 +173,10 @@ func initStandardRegistry(config *types.Prometheus) Registry {
 		lastConfigReloadSuccessGauge:   lastConfigReloadSuccess,
 		lastConfigReloadFailureGauge:   lastConfigReloadFailure,
 		tlsCertsNotAfterTimestampGauge: tlsCertsNotAfterTimestamp,
+		tlsCertsRenewalsCounter:        tlsCertsRenewals,
+		tlsCertsRenewalsFailureCounter: tlsCertsRenewalsFailures,
+		wafRuleMatchesCounter:          wafRuleMatches,
+		wafBlockedRequestsCounter:      wafBlockedRequests,
 	}
 
 	if config.AddEntryPointsLabels {
@@ -108,6 +108,22 @@ func TestPrometheus(t *testing.T) {
 		TLSCertsNotAfterTimestampGauge().
 		With("cn", "value", "serial", "value", "sans", "value").
 		Set(float64(time.Now().Unix()))
+	prometheusRegistry.
+		TLSCertsRenewalsCounter().
+		With("resolver", "foo").
+		Add(1)
+	prometheusRegistry.
+		TLSCertsRenewalsFailureCounter().
+		With("resolver", "foo").
+		Add(1)
+	prometheusRegistry.
+		WAFRuleMatchesCounter().
+		With("middleware", "waf@file", "rule", "942100").
+		Add(1)
+	prometheusRegistry.
+		WAFBlockedRequestsCounter().
+		With("middleware", "waf@file").
+		Add(1)
 
 	prometheusRegistry.
 		EntryPointReqsCounter().
@@ -198,6 +214,35 @@ func TestPrometheus(t *testing.T) {
 			},
 			assert: buildTimestampAssert(t, tlsCertsNotAfterTimestamp),
 		},
+		{
+			name: tlsCertsRenewalsTotalName,
+			labels: map[string]string{
+				"resolver": "foo",
+			},
+			assert: buildCounterAssert(t, tlsCertsRenewalsTotalName, 1),
+		},
+		{
+			name: tlsCertsRenewalsFailuresTotalName,
+			labels: map[string]string{
+				"resolver": "foo",
+			},
+			assert: buildCounterAssert(t, tlsCertsRenewalsFailuresTotalName, 1),
+		},
+		{
+			name: wafRuleMatchesTotalName,
+			labels: map[string]string{
+				"middleware": "waf@file",
+				"rule":       "942100",
+			},
+			assert: buildCounterAssert(t, wafRuleMatchesTotalName, 1),
+		},
+		{
+			name: wafBlockedRequestsTotalName,
+			labels: map[string]string{
+				"middleware": "waf@file",
+			},
+			assert: buildCounterAssert(t, wafBlockedRequestsTotalName, 1),
+		},
 		{
 			name: entryPointReqsTotalName,
 			labels: map[string]string{
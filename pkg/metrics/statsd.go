@@ -23,6 +23,11 @@ const (
 	statsdLastConfigReloadFailureName = "config.reload.lastFailureTimestamp"
 
 	statsdTLSCertsNotAfterTimestampName = "tls.certs.notAfterTimestamp"
+	statsdTLSCertsRenewalsName          = "tls.certs.renewal.total"
+	statsdTLSCertsRenewalsFailureName   = statsdTLSCertsRenewalsName + ".failure"
+
+	statsdWAFRuleMatchesName     = "waf.rule.matches.total"
+	statsdWAFBlockedRequestsName = "waf.blocked.requests.total"
 
 	statsdEntryPointReqsName        = "entrypoint.request.total"
 	statsdEntryPointReqsTLSName     = "entrypoint.request.tls.total"
@@ -64,6 +69,10 @@ func RegisterStatsd(ctx context.Context, config *types.Statsd) Registry {
 		lastConfigReloadSuccessGauge:   statsdClient.NewGauge(statsdLastConfigReloadSuccessName),
 		lastConfigReloadFailureGauge:   statsdClient.NewGauge(statsdLastConfigReloadFailureName),
 		tlsCertsNotAfterTimestampGauge: statsdClient.NewGauge(statsdTLSCertsNotAfterTimestampName),
+		tlsCertsRenewalsCounter:        statsdClient.NewCounter(statsdTLSCertsRenewalsName, 1.0),
+		tlsCertsRenewalsFailureCounter: statsdClient.NewCounter(statsdTLSCertsRenewalsFailureName, 1.0),
+		wafRuleMatchesCounter:          statsdClient.NewCounter(statsdWAFRuleMatchesName, 1.0),
+		wafBlockedRequestsCounter:      statsdClient.NewCounter(statsdWAFBlockedRequestsName, 1.0),
 	}
 
 	if config.AddEntryPointsLabels {
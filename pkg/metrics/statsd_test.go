@@ -54,6 +54,11 @@ func testRegistry(t *testing.T, metricsPrefix string, registry Registry) {
 		metricsPrefix + ".config.reload.lastFailureTimestamp:1.000000|g\n",
 
 		metricsPrefix + ".tls.certs.notAfterTimestamp:1.000000|g\n",
+		metricsPrefix + ".tls.certs.renewal.total:1.000000|c\n",
+		metricsPrefix + ".tls.certs.renewal.total.failure:1.000000|c\n",
+
+		metricsPrefix + ".waf.rule.matches.total:1.000000|c\n",
+		metricsPrefix + ".waf.blocked.requests.total:1.000000|c\n",
 
 		metricsPrefix + ".entrypoint.request.total:1.000000|c\n",
 		metricsPrefix + ".entrypoint.request.tls.total:1.000000|c\n",
@@ -80,6 +85,11 @@ func testRegistry(t *testing.T, metricsPrefix string, registry Registry) {
 		registry.LastConfigReloadFailureGauge().Set(1)
 
 		registry.TLSCertsNotAfterTimestampGauge().With("key", "value").Set(1)
+		registry.TLSCertsRenewalsCounter().With("resolver", "foo").Add(1)
+		registry.TLSCertsRenewalsFailureCounter().With("resolver", "foo").Add(1)
+
+		registry.WAFRuleMatchesCounter().With("middleware", "waf", "rule", "942100").Add(1)
+		registry.WAFBlockedRequestsCounter().With("middleware", "waf").Add(1)
 
 		registry.EntryPointReqsCounter().With("entrypoint", "test", "code", strconv.Itoa(http.StatusOK), "method", http.MethodGet).Add(1)
 		registry.EntryPointReqsTLSCounter().With("entrypoint", "test", "tls_version", "foo", "tls_cipher", "bar").Add(1)
@@ -75,6 +75,25 @@ const (
 	TLSVersion = "TLSVersion"
 	// TLSCipher is the cipher used in the request.
 	TLSCipher = "TLSCipher"
+
+	// WAFMatchedRules is the map key used for the comma-separated IDs of the WAF rules matched by the request.
+	WAFMatchedRules = "WAFMatchedRules"
+	// WAFAnomalyScore is the map key used for the anomaly score of the request computed by the WAF.
+	WAFAnomalyScore = "WAFAnomalyScore"
+	// WAFAction is the map key used for the action taken by the WAF on the request (blocked or detected).
+	WAFAction = "WAFAction"
+	// GeoCountry is the map key used for the country code of the source IP found by the GeoIP middleware.
+	GeoCountry = "GeoCountry"
+	// GeoContinent is the map key used for the continent code of the source IP found by the GeoIP middleware.
+	GeoContinent = "GeoContinent"
+	// GeoCity is the map key used for the city name of the source IP found by the GeoIP middleware.
+	GeoCity = "GeoCity"
+	// GeoASN is the map key used for the autonomous system number of the source IP found by the GeoIP middleware.
+	GeoASN = "GeoASN"
+	// BytesIn is the map key used for the number of bytes received from the client on a TCP connection.
+	BytesIn = "BytesIn"
+	// BytesOut is the map key used for the number of bytes sent to the client on a TCP connection.
+	BytesOut = "BytesOut"
 )
 
 // These are written out in the default case when no config is provided to specify keys of interest.
@@ -118,6 +137,15 @@ func init() {
 	allCoreKeys[RetryAttempts] = struct{}{}
 	allCoreKeys[TLSVersion] = struct{}{}
 	allCoreKeys[TLSCipher] = struct{}{}
+	allCoreKeys[WAFMatchedRules] = struct{}{}
+	allCoreKeys[WAFAnomalyScore] = struct{}{}
+	allCoreKeys[WAFAction] = struct{}{}
+	allCoreKeys[GeoCountry] = struct{}{}
+	allCoreKeys[GeoContinent] = struct{}{}
+	allCoreKeys[GeoCity] = struct{}{}
+	allCoreKeys[GeoASN] = struct{}{}
+	allCoreKeys[BytesIn] = struct{}{}
+	allCoreKeys[BytesOut] = struct{}{}
 }
 
 // CoreLogData holds the fields computed from the request/response.
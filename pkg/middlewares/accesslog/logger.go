@@ -57,6 +57,7 @@ type handlerParams struct {
 type Handler struct {
 	config         *types.AccessLog
 	logger         *logrus.Logger
+	tcpLogger      *logrus.Logger
 	file           io.WriteCloser
 	mu             sync.Mutex
 	httpCodeRanges types.HTTPCodeRanges
@@ -85,16 +86,19 @@ func NewHandler(config *types.AccessLog) (*Handler, error) {
 	}
 	logHandlerChan := make(chan handlerParams, config.BufferingSize)
 
-	var formatter logrus.Formatter
+	var formatter, tcpFormatter logrus.Formatter
 
 	switch config.Format {
 	case CommonFormat:
 		formatter = new(CommonLogFormatter)
+		tcpFormatter = new(TCPCommonLogFormatter)
 	case JSONFormat:
 		formatter = new(logrus.JSONFormatter)
+		tcpFormatter = formatter
 	default:
 		log.WithoutContext().Errorf("unsupported access log format: %q, defaulting to common format instead.", config.Format)
 		formatter = new(CommonLogFormatter)
+		tcpFormatter = new(TCPCommonLogFormatter)
 	}
 
 	logger := &logrus.Logger{
@@ -104,6 +108,13 @@ func NewHandler(config *types.AccessLog) (*Handler, error) {
 		Level:     logrus.InfoLevel,
 	}
 
+	tcpLogger := &logrus.Logger{
+		Out:       file,
+		Formatter: tcpFormatter,
+		Hooks:     make(logrus.LevelHooks),
+		Level:     logrus.InfoLevel,
+	}
+
 	// Transform headers names in config to a canonical form, to be used as is without further transformations.
 	if config.Fields != nil && config.Fields.Headers != nil && len(config.Fields.Headers.Names) > 0 {
 		fields := map[string]string{}
@@ -118,6 +129,7 @@ func NewHandler(config *types.AccessLog) (*Handler, error) {
 	logHandler := &Handler{
 		config:         config,
 		logger:         logger,
+		tcpLogger:      tcpLogger,
 		file:           file,
 		logHandlerChan: logHandlerChan,
 	}
@@ -273,9 +285,25 @@ func (h *Handler) Rotate() error {
 	h.mu.Lock()
 	defer h.mu.Unlock()
 	h.logger.Out = h.file
+	h.tcpLogger.Out = h.file
 	return nil
 }
 
+// LogTCPConnection writes the access log entry of a closed TCP connection.
+// The filters of the access log only apply to the HTTP requests.
+func (h *Handler) LogTCPConnection(data logrus.Fields) {
+	fields := logrus.Fields{}
+	for k, v := range data {
+		if h.config.Fields.Keep(k) {
+			fields[k] = v
+		}
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.tcpLogger.WithFields(fields).Println()
+}
+
 func silentSplitHostPort(value string) (host, port string) {
 	host, port, err := net.SplitHostPort(value)
 	if err != nil {
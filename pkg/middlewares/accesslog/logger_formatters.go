@@ -52,6 +52,35 @@ func (f *CommonLogFormatter) Format(entry *logrus.Entry) ([]byte, error) {
 	return b.Bytes(), err
 }
 
+// TCPCommonLogFormatter provides formatting of the TCP connections in the Traefik common log format.
+type TCPCommonLogFormatter struct{}
+
+// Format formats the log entry of a TCP connection in the Traefik common log format.
+func (f *TCPCommonLogFormatter) Format(entry *logrus.Entry) ([]byte, error) {
+	b := &bytes.Buffer{}
+
+	timestamp := defaultValue
+	if v, ok := entry.Data[StartUTC]; ok {
+		timestamp = v.(time.Time).Format(commonLogTimeFormat)
+	} else if v, ok := entry.Data[StartLocal]; ok {
+		timestamp = v.(time.Time).Local().Format(commonLogTimeFormat)
+	}
+
+	var elapsedMillis int64
+	if v, ok := entry.Data[Duration]; ok {
+		elapsedMillis = v.(time.Duration).Nanoseconds() / 1000000
+	}
+
+	_, err := fmt.Fprintf(b, "%s - - [%s] \"TCP\" %v %v %dms\n",
+		toLog(entry.Data, ClientHost, defaultValue, false),
+		timestamp,
+		toLog(entry.Data, BytesIn, defaultValue, false),
+		toLog(entry.Data, BytesOut, defaultValue, false),
+		elapsedMillis)
+
+	return b.Bytes(), err
+}
+
 func toLog(fields logrus.Fields, key, defaultValue string, quoted bool) interface{} {
 	if v, ok := fields[key]; ok {
 		if v == nil {
@@ -100,6 +100,22 @@ func TestCommonLogFormatter_Format(t *testing.T) {
 	}
 }
 
+func TestTCPCommonLogFormatter_Format(t *testing.T) {
+	clf := TCPCommonLogFormatter{}
+
+	entry := &logrus.Entry{Data: map[string]interface{}{
+		StartUTC:   time.Date(2009, time.November, 10, 23, 0, 0, 0, time.UTC),
+		Duration:   123 * time.Second,
+		ClientHost: "10.0.0.1",
+		BytesIn:    int64(5),
+		BytesOut:   int64(4),
+	}}
+
+	raw, err := clf.Format(entry)
+	assert.NoError(t, err)
+	assert.Equal(t, "10.0.0.1 - - [10/Nov/2009:23:00:00 +0000] \"TCP\" 5 4 123000ms\n", string(raw))
+}
+
 func Test_toLog(t *testing.T) {
 	testCases := []struct {
 		desc         string
@@ -0,0 +1,285 @@
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/opentracing/opentracing-go/ext"
+	"github.com/traefik/traefik/v2/pkg/config/dynamic"
+	"github.com/traefik/traefik/v2/pkg/filewatcher"
+	"github.com/traefik/traefik/v2/pkg/log"
+	"github.com/traefik/traefik/v2/pkg/middlewares"
+	"github.com/traefik/traefik/v2/pkg/middlewares/accesslog"
+	"github.com/traefik/traefik/v2/pkg/tracing"
+)
+
+const apiKeyTypeName = "APIKeyAuth"
+
+// apiKey is the consumer of an API key, with its metadata.
+type apiKey struct {
+	consumer string
+	metadata map[string]string
+}
+
+// apiKeyAuth is a middleware that verifies the API keys of the requests against hashed keys.
+type apiKeyAuth struct {
+	next http.Handler
+	name string
+
+	keys            *apiKeyStore
+	headerName      string
+	queryParam      string
+	cookieName      string
+	removeKey       bool
+	consumerHeader  string
+	metadataHeaders map[string]string
+}
+
+// NewAPIKey creates an apiKeyAuth middleware.
+func NewAPIKey(ctx context.Context, next http.Handler, config dynamic.APIKeyAuth, name string, sharedFiles *filewatcher.SharedFiles) (http.Handler, error) {
+	log.FromContext(middlewares.GetLoggerCtx(ctx, name, apiKeyTypeName)).Debug("Creating middleware")
+
+	if config.HeaderName == "" && config.QueryParam == "" && config.CookieName == "" {
+		return nil, errors.New("a header name, a query parameter, or a cookie name must be set")
+	}
+
+	keys, err := newAPIKeyStore(ctx, sharedFiles, config.KeysFile, config.Keys)
+	if err != nil {
+		return nil, err
+	}
+
+	return &apiKeyAuth{
+		next:            next,
+		name:            name,
+		keys:            keys,
+		headerName:      config.HeaderName,
+		queryParam:      config.QueryParam,
+		cookieName:      config.CookieName,
+		removeKey:       config.RemoveKey,
+		consumerHeader:  config.ConsumerHeader,
+		metadataHeaders: config.MetadataHeaders,
+	}, nil
+}
+
+func (a *apiKeyAuth) GetTracingInformation() (string, ext.SpanKindEnum) {
+	return a.name, tracing.SpanKindNoneEnum
+}
+
+func (a *apiKeyAuth) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	ctx := middlewares.GetLoggerCtx(req.Context(), a.name, apiKeyTypeName)
+	logger := log.FromContext(ctx)
+
+	rawKey := a.extractKey(req)
+	if rawKey == "" {
+		logger.Debug("Authentication failed: missing API key")
+		tracing.SetErrorWithEvent(req, "Authentication failed: missing API key")
+		rejectAPIKey(ctx, rw)
+		return
+	}
+
+	digest := sha256.Sum256([]byte(rawKey))
+
+	key, ok := a.keys.lookup(hex.EncodeToString(digest[:]))
+	if !ok {
+		logger.Debug("Authentication failed: unknown API key")
+		tracing.SetErrorWithEvent(req, "Authentication failed: unknown API key")
+		rejectAPIKey(ctx, rw)
+		return
+	}
+
+	logData := accesslog.GetLogData(req)
+	if logData != nil {
+		logData.Core[accesslog.ClientUsername] = key.consumer
+	}
+
+	logger.Debug("Authentication succeeded")
+
+	if a.consumerHeader != "" {
+		req.Header.Set(a.consumerHeader, key.consumer)
+	}
+
+	for header, name := range a.metadataHeaders {
+		req.Header.Del(header)
+
+		if value, ok := key.metadata[name]; ok {
+			req.Header.Set(header, value)
+		}
+	}
+
+	if a.removeKey {
+		a.removeRequestKey(req)
+	}
+
+	a.next.ServeHTTP(rw, req)
+}
+
+// extractKey returns the API key of the request, looked up in the header, the query parameter, and the cookie, in this order.
+func (a *apiKeyAuth) extractKey(req *http.Request) string {
+	if a.headerName != "" {
+		if value := req.Header.Get(a.headerName); value != "" {
+			return value
+		}
+	}
+
+	if a.queryParam != "" {
+		if value := req.URL.Query().Get(a.queryParam); value != "" {
+			return value
+		}
+	}
+
+	if a.cookieName != "" {
+		if cookie, err := req.Cookie(a.cookieName); err == nil {
+			return cookie.Value
+		}
+	}
+
+	return ""
+}
+
+// removeRequestKey removes the API key from the request, so that it is not forwarded to the service.
+func (a *apiKeyAuth) removeRequestKey(req *http.Request) {
+	if a.headerName != "" {
+		req.Header.Del(a.headerName)
+	}
+
+	if a.queryParam != "" {
+		query := req.URL.Query()
+		if _, ok := query[a.queryParam]; ok {
+			query.Del(a.queryParam)
+			req.URL.RawQuery = query.Encode()
+			req.RequestURI = req.URL.RequestURI()
+		}
+	}
+
+	if a.cookieName != "" {
+		cookies := req.Cookies()
+		req.Header.Del("Cookie")
+
+		for _, cookie := range cookies {
+			if cookie.Name != a.cookieName {
+				req.AddCookie(cookie)
+			}
+		}
+	}
+}
+
+// apiKeyStore holds the API keys indexed by their SHA-256 digest.
+// The keys of the keys file are shared by the middlewares, and reloaded when the file changes.
+type apiKeyStore struct {
+	keys     map[string]apiKey
+	fileKeys *filewatcher.SharedFile
+}
+
+func newAPIKeyStore(ctx context.Context, sharedFiles *filewatcher.SharedFiles, file string, staticKeys []string) (*apiKeyStore, error) {
+	keys, err := loadAPIKeys("", staticKeys)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &apiKeyStore{keys: keys}
+
+	if file == "" {
+		return s, nil
+	}
+
+	if sharedFiles == nil {
+		return nil, errors.New("the shared files are not available")
+	}
+
+	s.fileKeys, err = sharedFiles.Get(ctx, "apikeyauth", file, func(file string) (interface{}, error) {
+		return loadAPIKeys(file, nil)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for digest, key := range s.fileKeys.Value().(map[string]apiKey) {
+		if _, exists := s.keys[digest]; exists {
+			return nil, fmt.Errorf("duplicate API key for the consumer %q", key.consumer)
+		}
+	}
+
+	return s, nil
+}
+
+// lookup returns the API key matching the digest.
+func (s *apiKeyStore) lookup(digest string) (apiKey, bool) {
+	if key, ok := s.keys[digest]; ok {
+		return key, true
+	}
+
+	if s.fileKeys == nil {
+		return apiKey{}, false
+	}
+
+	key, ok := s.fileKeys.Value().(map[string]apiKey)[digest]
+
+	return key, ok
+}
+
+func loadAPIKeys(file string, staticKeys []string) (map[string]apiKey, error) {
+	entries, err := loadUsers(file, staticKeys)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make(map[string]apiKey, len(entries))
+	for _, entry := range entries {
+		digest, key, err := parseAPIKey(entry)
+		if err != nil {
+			return nil, err
+		}
+
+		if _, exists := keys[digest]; exists {
+			return nil, fmt.Errorf("duplicate API key for the consumer %q", key.consumer)
+		}
+		keys[digest] = key
+	}
+
+	return keys, nil
+}
+
+// parseAPIKey parses an API key entry in the consumer:sha256-digest[:metadata] format.
+func parseAPIKey(entry string) (string, apiKey, error) {
+	parts := strings.SplitN(strings.TrimSpace(entry), ":", 3)
+	if len(parts) < 2 || parts[0] == "" {
+		return "", apiKey{}, errors.New("invalid API key entry, the format must be consumer:sha256-digest[:metadata]")
+	}
+
+	key := apiKey{consumer: parts[0]}
+
+	digest := strings.ToLower(parts[1])
+	if decoded, err := hex.DecodeString(digest); err != nil || len(decoded) != sha256.Size {
+		return "", apiKey{}, fmt.Errorf("invalid API key digest for the consumer %q, it must be a hex encoded SHA-256 digest", key.consumer)
+	}
+
+	if len(parts) == 3 && parts[2] != "" {
+		key.metadata = make(map[string]string)
+
+		for _, pair := range strings.Split(parts[2], ",") {
+			name, value, ok := strings.Cut(pair, "=")
+			name = strings.TrimSpace(name)
+			if !ok || name == "" {
+				return "", apiKey{}, fmt.Errorf("invalid API key metadata %q for the consumer %q, the format must be name=value", pair, key.consumer)
+			}
+
+			key.metadata[name] = strings.TrimSpace(value)
+		}
+	}
+
+	return digest, key, nil
+}
+
+func rejectAPIKey(ctx context.Context, rw http.ResponseWriter) {
+	rw.WriteHeader(http.StatusUnauthorized)
+
+	_, err := rw.Write([]byte(http.StatusText(http.StatusUnauthorized)))
+	if err != nil {
+		log.FromContext(ctx).Error(err)
+	}
+}
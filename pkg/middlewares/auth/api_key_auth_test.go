@@ -0,0 +1,356 @@
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/traefik/traefik/v2/pkg/config/dynamic"
+	"github.com/traefik/traefik/v2/pkg/filewatcher"
+)
+
+func TestNewAPIKey(t *testing.T) {
+	keysFile := filepath.Join(t.TempDir(), "keys")
+	writeAPIKeysFile(t, keysFile, "alice:"+apiKeyDigest("shared-key"))
+
+	testCases := []struct {
+		desc          string
+		config        dynamic.APIKeyAuth
+		expectedError bool
+	}{
+		{
+			desc: "valid keys",
+			config: dynamic.APIKeyAuth{
+				HeaderName: "X-API-Key",
+				Keys: []string{
+					"alice:" + apiKeyDigest("alice-key"),
+					"bob:" + apiKeyDigest("bob-key") + ":plan=gold, team=billing",
+				},
+			},
+		},
+		{
+			desc: "uppercase digest",
+			config: dynamic.APIKeyAuth{
+				HeaderName: "X-API-Key",
+				Keys:       []string{"alice:" + "2BB80D537B1DA3E38BD30361AA855686BDE0EACD7162FEF6A25FE97BF527A25B"},
+			},
+		},
+		{
+			desc: "no key source",
+			config: dynamic.APIKeyAuth{
+				Keys: []string{"alice:" + apiKeyDigest("alice-key")},
+			},
+			expectedError: true,
+		},
+		{
+			desc: "missing digest",
+			config: dynamic.APIKeyAuth{
+				HeaderName: "X-API-Key",
+				Keys:       []string{"alice"},
+			},
+			expectedError: true,
+		},
+		{
+			desc: "missing consumer",
+			config: dynamic.APIKeyAuth{
+				HeaderName: "X-API-Key",
+				Keys:       []string{":" + apiKeyDigest("alice-key")},
+			},
+			expectedError: true,
+		},
+		{
+			desc: "plain key instead of digest",
+			config: dynamic.APIKeyAuth{
+				HeaderName: "X-API-Key",
+				Keys:       []string{"alice:alice-key"},
+			},
+			expectedError: true,
+		},
+		{
+			desc: "invalid metadata",
+			config: dynamic.APIKeyAuth{
+				HeaderName: "X-API-Key",
+				Keys:       []string{"alice:" + apiKeyDigest("alice-key") + ":gold"},
+			},
+			expectedError: true,
+		},
+		{
+			desc: "duplicate key",
+			config: dynamic.APIKeyAuth{
+				HeaderName: "X-API-Key",
+				Keys: []string{
+					"alice:" + apiKeyDigest("shared-key"),
+					"bob:" + apiKeyDigest("shared-key"),
+				},
+			},
+			expectedError: true,
+		},
+		{
+			desc: "duplicate key in the keys file",
+			config: dynamic.APIKeyAuth{
+				HeaderName: "X-API-Key",
+				Keys:       []string{"bob:" + apiKeyDigest("shared-key")},
+				KeysFile:   keysFile,
+			},
+			expectedError: true,
+		},
+		{
+			desc: "missing keys file",
+			config: dynamic.APIKeyAuth{
+				HeaderName: "X-API-Key",
+				KeysFile:   filepath.Join(t.TempDir(), "missing"),
+			},
+			expectedError: true,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {})
+
+			_, err := NewAPIKey(context.Background(), next, test.config, "apiKeyAuth", filewatcher.NewSharedFiles())
+			if test.expectedError {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestAPIKeyAuth_ServeHTTP(t *testing.T) {
+	keys := []string{
+		"alice:" + apiKeyDigest("alice-key") + ":plan=gold,team=billing",
+		"bob:" + apiKeyDigest("bob-key"),
+	}
+
+	testCases := []struct {
+		desc            string
+		config          dynamic.APIKeyAuth
+		request         func() *http.Request
+		expectedStatus  int
+		expectedHeaders map[string]string
+		expectedURI     string
+		expectedCookie  string
+	}{
+		{
+			desc:   "key in header",
+			config: dynamic.APIKeyAuth{HeaderName: "X-API-Key"},
+			request: func() *http.Request {
+				req := httptest.NewRequest(http.MethodGet, "/foo", nil)
+				req.Header.Set("X-API-Key", "alice-key")
+				return req
+			},
+			expectedStatus:  http.StatusOK,
+			expectedHeaders: map[string]string{"X-API-Key": "alice-key"},
+		},
+		{
+			desc:   "missing key",
+			config: dynamic.APIKeyAuth{HeaderName: "X-API-Key"},
+			request: func() *http.Request {
+				return httptest.NewRequest(http.MethodGet, "/foo", nil)
+			},
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			desc:   "unknown key",
+			config: dynamic.APIKeyAuth{HeaderName: "X-API-Key"},
+			request: func() *http.Request {
+				req := httptest.NewRequest(http.MethodGet, "/foo", nil)
+				req.Header.Set("X-API-Key", "mallory-key")
+				return req
+			},
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			desc:   "key in query parameter",
+			config: dynamic.APIKeyAuth{HeaderName: "X-API-Key", QueryParam: "api_key"},
+			request: func() *http.Request {
+				return httptest.NewRequest(http.MethodGet, "/foo?api_key=bob-key&bar=baz", nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedURI:    "/foo?api_key=bob-key&bar=baz",
+		},
+		{
+			desc:   "key in query parameter without query parameter source",
+			config: dynamic.APIKeyAuth{HeaderName: "X-API-Key"},
+			request: func() *http.Request {
+				return httptest.NewRequest(http.MethodGet, "/foo?api_key=bob-key", nil)
+			},
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			desc:   "key in cookie",
+			config: dynamic.APIKeyAuth{CookieName: "api_key"},
+			request: func() *http.Request {
+				req := httptest.NewRequest(http.MethodGet, "/foo", nil)
+				req.AddCookie(&http.Cookie{Name: "api_key", Value: "bob-key"})
+				return req
+			},
+			expectedStatus: http.StatusOK,
+			expectedCookie: "api_key=bob-key",
+		},
+		{
+			desc:   "header has precedence over query parameter",
+			config: dynamic.APIKeyAuth{HeaderName: "X-API-Key", QueryParam: "api_key"},
+			request: func() *http.Request {
+				req := httptest.NewRequest(http.MethodGet, "/foo?api_key=alice-key", nil)
+				req.Header.Set("X-API-Key", "mallory-key")
+				return req
+			},
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			desc: "consumer and metadata headers",
+			config: dynamic.APIKeyAuth{
+				HeaderName:      "X-API-Key",
+				ConsumerHeader:  "X-Consumer",
+				MetadataHeaders: map[string]string{"X-Plan": "plan", "X-Region": "region"},
+			},
+			request: func() *http.Request {
+				req := httptest.NewRequest(http.MethodGet, "/foo", nil)
+				req.Header.Set("X-API-Key", "alice-key")
+				req.Header.Set("X-Consumer", "forged")
+				req.Header.Set("X-Region", "forged")
+				return req
+			},
+			expectedStatus:  http.StatusOK,
+			expectedHeaders: map[string]string{"X-Consumer": "alice", "X-Plan": "gold", "X-Region": ""},
+		},
+		{
+			desc: "remove key",
+			config: dynamic.APIKeyAuth{
+				HeaderName: "X-API-Key",
+				QueryParam: "api_key",
+				CookieName: "api_key",
+				RemoveKey:  true,
+			},
+			request: func() *http.Request {
+				req := httptest.NewRequest(http.MethodGet, "/foo?api_key=alice-key&bar=baz", nil)
+				req.Header.Set("X-API-Key", "alice-key")
+				req.AddCookie(&http.Cookie{Name: "api_key", Value: "alice-key"})
+				req.AddCookie(&http.Cookie{Name: "session", Value: "foo"})
+				return req
+			},
+			expectedStatus:  http.StatusOK,
+			expectedHeaders: map[string]string{"X-API-Key": ""},
+			expectedURI:     "/foo?bar=baz",
+			expectedCookie:  "session=foo",
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			var forwarded *http.Request
+			next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				forwarded = req
+			})
+
+			config := test.config
+			config.Keys = keys
+
+			handler, err := NewAPIKey(context.Background(), next, config, "apiKeyAuth", filewatcher.NewSharedFiles())
+			require.NoError(t, err)
+
+			rw := httptest.NewRecorder()
+			handler.ServeHTTP(rw, test.request())
+
+			assert.Equal(t, test.expectedStatus, rw.Code)
+
+			if test.expectedStatus != http.StatusOK {
+				assert.Nil(t, forwarded)
+				return
+			}
+
+			require.NotNil(t, forwarded)
+
+			for name, value := range test.expectedHeaders {
+				assert.Equal(t, value, forwarded.Header.Get(name), name)
+			}
+
+			if test.expectedURI != "" {
+				assert.Equal(t, test.expectedURI, forwarded.URL.RequestURI())
+			}
+
+			if test.expectedCookie != "" {
+				assert.Equal(t, test.expectedCookie, forwarded.Header.Get("Cookie"))
+			}
+		})
+	}
+}
+
+func TestAPIKeyAuth_keysFileReload(t *testing.T) {
+	keysFile := filepath.Join(t.TempDir(), "keys")
+	writeAPIKeysFile(t, keysFile, "# API keys", "alice:"+apiKeyDigest("alice-key"))
+
+	next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		_, _ = fmt.Fprint(rw, req.Header.Get("X-Consumer"))
+	})
+
+	handler, err := NewAPIKey(context.Background(), next, dynamic.APIKeyAuth{
+		KeysFile:       keysFile,
+		HeaderName:     "X-API-Key",
+		ConsumerHeader: "X-Consumer",
+	}, "apiKeyAuth", filewatcher.NewSharedFiles())
+	require.NoError(t, err)
+
+	serve := func(key string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("X-API-Key", key)
+
+		rw := httptest.NewRecorder()
+		handler.ServeHTTP(rw, req)
+
+		return rw
+	}
+
+	assert.Equal(t, http.StatusOK, serve("alice-key").Code)
+	assert.Equal(t, http.StatusUnauthorized, serve("bob-key").Code)
+
+	// Rotates the key of alice and adds bob.
+	writeAPIKeysFile(t, keysFile, "alice:"+apiKeyDigest("alice-new-key"), "bob:"+apiKeyDigest("bob-key"))
+
+	assert.Eventually(t, func() bool { return serve("alice-key").Code == http.StatusUnauthorized }, 5*time.Second, 50*time.Millisecond)
+
+	rw := serve("bob-key")
+	assert.Equal(t, http.StatusOK, rw.Code)
+	assert.Equal(t, "bob", rw.Body.String())
+
+	// An invalid file keeps the previous keys.
+	writeAPIKeysFile(t, keysFile, "alice")
+	time.Sleep(2 * filewatcher.ChangeDelay)
+
+	assert.Equal(t, http.StatusOK, serve("alice-new-key").Code)
+}
+
+func apiKeyDigest(key string) string {
+	digest := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(digest[:])
+}
+
+func writeAPIKeysFile(t *testing.T, path string, lines ...string) {
+	t.Helper()
+
+	var content string
+	for _, line := range lines {
+		content += line + "\n"
+	}
+
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+}
@@ -1,6 +1,10 @@
 package auth
 
 import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
 	"os"
 	"strings"
 )
@@ -8,6 +12,11 @@ import (
 // UserParser Parses a string and return a userName/userHash. An error if the format of the string is incorrect.
 type UserParser func(user string) (string, string, error)
 
+// defaultMaxBodySize is the default maximum size of the request bodies read by the middlewares.
+const defaultMaxBodySize = 1 << 20
+
+var errBodyTooLarge = errors.New("request body too large")
+
 const (
 	defaultRealm        = "traefik"
 	authorizationHeader = "Authorization"
@@ -63,3 +72,32 @@ func getLinesFromFile(filename string) ([]string, error) {
 
 	return filteredLines, nil
 }
+
+// readBody reads the request body, up to maxSize bytes, and restores it for the next handlers.
+func readBody(req *http.Request, maxSize int64) ([]byte, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, nil
+	}
+
+	if req.ContentLength > maxSize {
+		return nil, errBodyTooLarge
+	}
+
+	body, err := io.ReadAll(io.LimitReader(req.Body, maxSize+1))
+	if err != nil {
+		// The body is limited by a BodyLimit middleware.
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			return nil, errBodyTooLarge
+		}
+		return nil, err
+	}
+
+	if int64(len(body)) > maxSize {
+		return nil, errBodyTooLarge
+	}
+
+	req.Body = io.NopCloser(bytes.NewReader(body))
+
+	return body, nil
+}
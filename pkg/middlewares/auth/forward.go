@@ -1,6 +1,7 @@
 package auth
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
@@ -14,9 +15,11 @@ import (
 	"github.com/opentracing/opentracing-go/ext"
 	"github.com/traefik/traefik/v2/pkg/config/dynamic"
 	"github.com/traefik/traefik/v2/pkg/log"
+	mc "github.com/traefik/traefik/v2/pkg/memcached"
 	"github.com/traefik/traefik/v2/pkg/middlewares"
 	"github.com/traefik/traefik/v2/pkg/middlewares/connectionheader"
 	"github.com/traefik/traefik/v2/pkg/tracing"
+	"github.com/traefik/traefik/v2/pkg/types"
 	"github.com/vulcand/oxy/forward"
 	"github.com/vulcand/oxy/utils"
 )
@@ -27,6 +30,12 @@ const (
 	forwardedTypeName = "ForwardedAuthType"
 )
 
+const (
+	failurePolicyPassthrough = "passthrough"
+	failurePolicyOpen        = "open"
+	failurePolicyClosed      = "closed"
+)
+
 // hopHeaders Hop-by-hop headers to be removed in the authentication request.
 // http://www.w3.org/Protocols/rfc2616/rfc2616-sec13.html
 // Proxy-Authorization header is forwarded to the authentication server (see https://tools.ietf.org/html/rfc7235#section-4.4).
@@ -48,10 +57,15 @@ type forwardAuth struct {
 	client                   http.Client
 	trustForwardHeader       bool
 	authRequestHeaders       []string
+	forwardBody              bool
+	maxBodySize              int64
+	cache                    *forwardAuthCache
+	failurePolicy            string
+	failureStatus            types.HTTPCodeRanges
 }
 
 // NewForward creates a forward auth middleware.
-func NewForward(ctx context.Context, next http.Handler, config dynamic.ForwardAuth, name string) (http.Handler, error) {
+func NewForward(ctx context.Context, next http.Handler, config dynamic.ForwardAuth, name string, memcached *mc.Client) (http.Handler, error) {
 	log.FromContext(middlewares.GetLoggerCtx(ctx, name, forwardedTypeName)).Debug("Creating middleware")
 
 	fa := &forwardAuth{
@@ -61,6 +75,9 @@ func NewForward(ctx context.Context, next http.Handler, config dynamic.ForwardAu
 		name:                name,
 		trustForwardHeader:  config.TrustForwardHeader,
 		authRequestHeaders:  config.AuthRequestHeaders,
+		forwardBody:         config.ForwardBody,
+		maxBodySize:         config.MaxBodySize,
+		failurePolicy:       config.FailurePolicy,
 	}
 
 	// Ensure our request client does not follow redirects
@@ -90,6 +107,36 @@ func NewForward(ctx context.Context, next http.Handler, config dynamic.ForwardAu
 		fa.authResponseHeadersRegex = re
 	}
 
+	if fa.maxBodySize <= 0 {
+		fa.maxBodySize = defaultMaxBodySize
+	}
+
+	switch fa.failurePolicy {
+	case "":
+		fa.failurePolicy = failurePolicyPassthrough
+	case failurePolicyPassthrough, failurePolicyOpen, failurePolicyClosed:
+	default:
+		return nil, fmt.Errorf("unknown failure policy %q", fa.failurePolicy)
+	}
+
+	failureStatus := config.FailureStatus
+	if len(failureStatus) == 0 {
+		failureStatus = []string{"500-599"}
+	}
+
+	var err error
+	fa.failureStatus, err = types.NewHTTPCodeRanges(failureStatus)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing failure status: %w", err)
+	}
+
+	if config.Cache != nil {
+		fa.cache, err = newForwardAuthCache(config.Cache, name, config.Address, memcached)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	return connectionheader.Remover(fa), nil
 }
 
@@ -100,7 +147,31 @@ func (fa *forwardAuth) GetTracingInformation() (string, ext.SpanKindEnum) {
 func (fa *forwardAuth) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
 	logger := log.FromContext(middlewares.GetLoggerCtx(req.Context(), fa.name, forwardedTypeName))
 
-	forwardReq, err := http.NewRequest(http.MethodGet, fa.address, nil)
+	method := http.MethodGet
+	var reqBody []byte
+	var forwardBody io.Reader
+	if fa.forwardBody {
+		var err error
+		reqBody, err = readBody(req, fa.maxBodySize)
+		if err != nil {
+			logMessage := fmt.Sprintf("Error reading request body. Cause: %s", err)
+			logger.Debug(logMessage)
+			tracing.SetErrorWithEvent(req, logMessage)
+
+			if errors.Is(err, errBodyTooLarge) {
+				rw.WriteHeader(http.StatusRequestEntityTooLarge)
+				return
+			}
+
+			rw.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		method = req.Method
+		forwardBody = bytes.NewReader(reqBody)
+	}
+
+	forwardReq, err := http.NewRequest(method, fa.address, forwardBody)
 	tracing.LogRequest(tracing.GetSpan(req), forwardReq)
 	if err != nil {
 		logMessage := fmt.Sprintf("Error calling %s. Cause %s", fa.address, err)
@@ -117,13 +188,25 @@ func (fa *forwardAuth) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
 
 	writeHeader(req, forwardReq, fa.trustForwardHeader, fa.authRequestHeaders)
 
+	var cacheKey string
+	if fa.cache != nil {
+		cacheKey = fa.cache.key(req, forwardReq, reqBody)
+		if cacheKey != "" {
+			if decision, ok := fa.cache.get(req.Context(), logger, cacheKey); ok {
+				logger.Debugf("Using cached decision of %s. StatusCode: %d", fa.address, decision.StatusCode)
+				fa.serveDecision(rw, req, logger, decision)
+				return
+			}
+		}
+	}
+
 	forwardResponse, forwardErr := fa.client.Do(forwardReq)
 	if forwardErr != nil {
 		logMessage := fmt.Sprintf("Error calling %s. Cause: %s", fa.address, forwardErr)
 		logger.Debug(logMessage)
 		tracing.SetErrorWithEvent(req, logMessage)
 
-		rw.WriteHeader(http.StatusInternalServerError)
+		fa.serveFailure(rw, req, logger, http.StatusInternalServerError)
 		return
 	}
 
@@ -133,18 +216,29 @@ func (fa *forwardAuth) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
 		logger.Debug(logMessage)
 		tracing.SetErrorWithEvent(req, logMessage)
 
-		rw.WriteHeader(http.StatusInternalServerError)
+		fa.serveFailure(rw, req, logger, http.StatusInternalServerError)
 		return
 	}
 	defer forwardResponse.Body.Close()
 
+	if fa.failurePolicy != failurePolicyPassthrough && fa.failureStatus.Contains(forwardResponse.StatusCode) {
+		logMessage := fmt.Sprintf("Remote error %s. StatusCode: %d", fa.address, forwardResponse.StatusCode)
+		logger.Debug(logMessage)
+		tracing.SetErrorWithEvent(req, logMessage)
+
+		fa.serveFailure(rw, req, logger, forwardResponse.StatusCode)
+		return
+	}
+
+	decision := forwardAuthDecision{StatusCode: forwardResponse.StatusCode}
+
 	// Pass the forward response's body and selected headers if it
 	// didn't return a response within the range of [200, 300).
 	if forwardResponse.StatusCode < http.StatusOK || forwardResponse.StatusCode >= http.StatusMultipleChoices {
 		logger.Debugf("Remote error %s. StatusCode: %d", fa.address, forwardResponse.StatusCode)
 
-		utils.CopyHeaders(rw.Header(), forwardResponse.Header)
-		utils.RemoveHeaders(rw.Header(), hopHeaders...)
+		decision.Header = forwardResponse.Header.Clone()
+		utils.RemoveHeaders(decision.Header, hopHeaders...)
 
 		// Grab the location header, if any.
 		redirectURL, err := forwardResponse.Location()
@@ -160,13 +254,31 @@ func (fa *forwardAuth) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
 			}
 		} else if redirectURL.String() != "" {
 			// Set the location in our response if one was sent back.
-			rw.Header().Set("Location", redirectURL.String())
+			decision.Header.Set("Location", redirectURL.String())
 		}
 
-		tracing.LogResponseCode(tracing.GetSpan(req), forwardResponse.StatusCode)
-		rw.WriteHeader(forwardResponse.StatusCode)
+		decision.Body = body
+	} else {
+		decision.Header = fa.selectAuthResponseHeaders(forwardResponse.Header)
+	}
+
+	if cacheKey != "" {
+		fa.cache.set(req.Context(), logger, cacheKey, decision)
+	}
+
+	fa.serveDecision(rw, req, logger, decision)
+}
+
+// serveDecision forwards the request with the selected headers of the authentication server response if the decision allows it,
+// and returns the authentication server response otherwise.
+func (fa *forwardAuth) serveDecision(rw http.ResponseWriter, req *http.Request, logger log.Logger, decision forwardAuthDecision) {
+	if decision.StatusCode < http.StatusOK || decision.StatusCode >= http.StatusMultipleChoices {
+		utils.CopyHeaders(rw.Header(), decision.Header)
+
+		tracing.LogResponseCode(tracing.GetSpan(req), decision.StatusCode)
+		rw.WriteHeader(decision.StatusCode)
 
-		if _, err = rw.Write(body); err != nil {
+		if _, err := rw.Write(decision.Body); err != nil {
 			logger.Error(err)
 		}
 		return
@@ -175,8 +287,8 @@ func (fa *forwardAuth) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
 	for _, headerName := range fa.authResponseHeaders {
 		headerKey := http.CanonicalHeaderKey(headerName)
 		req.Header.Del(headerKey)
-		if len(forwardResponse.Header[headerKey]) > 0 {
-			req.Header[headerKey] = append([]string(nil), forwardResponse.Header[headerKey]...)
+		if len(decision.Header[headerKey]) > 0 {
+			req.Header[headerKey] = append([]string(nil), decision.Header[headerKey]...)
 		}
 	}
 
@@ -187,7 +299,7 @@ func (fa *forwardAuth) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
 			}
 		}
 
-		for headerKey, headerValues := range forwardResponse.Header {
+		for headerKey, headerValues := range decision.Header {
 			if fa.authResponseHeadersRegex.MatchString(headerKey) {
 				req.Header[headerKey] = append([]string(nil), headerValues...)
 			}
@@ -198,6 +310,43 @@ func (fa *forwardAuth) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
 	fa.next.ServeHTTP(rw, req)
 }
 
+// serveFailure handles a failure of the authentication server according to the failure policy.
+func (fa *forwardAuth) serveFailure(rw http.ResponseWriter, req *http.Request, logger log.Logger, statusCode int) {
+	switch fa.failurePolicy {
+	case failurePolicyOpen:
+		logger.Warnf("Authentication server %s failed, forwarding the request", fa.address)
+		// The headers of the authentication server response are removed from the request, so that they cannot be forged.
+		fa.serveDecision(rw, req, logger, forwardAuthDecision{StatusCode: http.StatusOK})
+	case failurePolicyClosed:
+		tracing.LogResponseCode(tracing.GetSpan(req), http.StatusServiceUnavailable)
+		rw.WriteHeader(http.StatusServiceUnavailable)
+	default:
+		rw.WriteHeader(statusCode)
+	}
+}
+
+// selectAuthResponseHeaders returns the headers of the authentication server response to set on the forwarded request.
+func (fa *forwardAuth) selectAuthResponseHeaders(header http.Header) http.Header {
+	selected := http.Header{}
+
+	for _, headerName := range fa.authResponseHeaders {
+		headerKey := http.CanonicalHeaderKey(headerName)
+		if len(header[headerKey]) > 0 {
+			selected[headerKey] = append([]string(nil), header[headerKey]...)
+		}
+	}
+
+	if fa.authResponseHeadersRegex != nil {
+		for headerKey, headerValues := range header {
+			if fa.authResponseHeadersRegex.MatchString(headerKey) {
+				selected[headerKey] = append([]string(nil), headerValues...)
+			}
+		}
+	}
+
+	return selected
+}
+
 func writeHeader(req, forwardReq *http.Request, trustForwardHeader bool, allowedHeaders []string) {
 	utils.CopyHeaders(forwardReq.Header, req.Header)
 	utils.RemoveHeaders(forwardReq.Header, hopHeaders...)
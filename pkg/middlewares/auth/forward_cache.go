@@ -0,0 +1,192 @@
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"time"
+
+	"github.com/mailgun/ttlmap"
+	"github.com/traefik/traefik/v2/pkg/config/dynamic"
+	"github.com/traefik/traefik/v2/pkg/log"
+	mc "github.com/traefik/traefik/v2/pkg/memcached"
+	"github.com/traefik/traefik/v2/pkg/middlewares"
+	"github.com/vulcand/oxy/forward"
+)
+
+const (
+	forwardAuthStoreMemory    = "memory"
+	forwardAuthStoreMemcached = "memcached"
+)
+
+const defaultForwardAuthCacheMaxEntries = 10000
+
+// forwardAuthDecision is a decision of the authentication server.
+// The decisions allowing the requests only hold the headers to set on the forwarded requests.
+type forwardAuthDecision struct {
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+}
+
+// forwardAuthCache caches the decisions of the authentication server,
+// in memory or in memcached to share them between the Traefik instances.
+type forwardAuthCache struct {
+	ttl                time.Duration
+	denyTTL            time.Duration
+	keyHeaders         []string
+	keyCookies         []string
+	ignoreMethodAndURI bool
+	keyPrefix          string
+
+	decisions *ttlmap.TtlMap
+	mh        middlewares.IMemcachedHandler[forwardAuthDecision]
+}
+
+func newForwardAuthCache(config *dynamic.ForwardAuthCache, name, address string, memcached *mc.Client) (*forwardAuthCache, error) {
+	if config.TTL <= 0 {
+		return nil, errors.New("the cache TTL must be positive")
+	}
+
+	if len(config.KeyHeaders) == 0 && len(config.KeyCookies) == 0 {
+		return nil, errors.New("the cache requires at least one key header or key cookie")
+	}
+
+	// The decisions of the different middlewares are kept apart in the shared stores.
+	namespace := sha256.Sum256([]byte(name + "\n" + address))
+
+	c := &forwardAuthCache{
+		ttl:                time.Duration(config.TTL),
+		denyTTL:            time.Duration(config.DenyTTL),
+		keyHeaders:         config.KeyHeaders,
+		keyCookies:         config.KeyCookies,
+		ignoreMethodAndURI: config.IgnoreMethodAndURI,
+		keyPrefix:          "traefik-forwardauth-" + hex.EncodeToString(namespace[:8]) + "-",
+	}
+
+	switch config.Store {
+	case "", forwardAuthStoreMemory:
+		maxEntries := config.MaxEntries
+		if maxEntries <= 0 {
+			maxEntries = defaultForwardAuthCacheMaxEntries
+		}
+
+		decisions, err := ttlmap.NewConcurrent(maxEntries)
+		if err != nil {
+			return nil, err
+		}
+		c.decisions = decisions
+
+	case forwardAuthStoreMemcached:
+		if memcached == nil {
+			return nil, errors.New("the decisions cannot be cached in memcached, as memcached is not configured")
+		}
+		c.mh = mc.NewMemcachedHandler[forwardAuthDecision](memcached)
+
+	default:
+		return nil, fmt.Errorf("unknown cache store %q", config.Store)
+	}
+
+	return c, nil
+}
+
+// key returns the cache key of the decision for the request,
+// or an empty string if the request does not have any of the key headers and cookies.
+func (c *forwardAuthCache) key(req, forwardReq *http.Request, body []byte) string {
+	hash := sha256.New()
+
+	var identified bool
+	for _, name := range c.keyHeaders {
+		values := req.Header.Values(name)
+		if len(values) > 0 {
+			identified = true
+		}
+		fmt.Fprintf(hash, "header %q %q\n", http.CanonicalHeaderKey(name), values)
+	}
+
+	for _, name := range c.keyCookies {
+		cookie, err := req.Cookie(name)
+		if err != nil {
+			fmt.Fprintf(hash, "cookie %q\n", name)
+			continue
+		}
+		identified = true
+		fmt.Fprintf(hash, "cookie %q %q\n", name, cookie.Value)
+	}
+
+	if !identified {
+		return ""
+	}
+
+	// The request attributes are read from the headers sent to the authentication server,
+	// as they may come from the trusted X-Forwarded-* headers.
+	fmt.Fprintf(hash, "proto %q\n", forwardReq.Header.Get(forward.XForwardedProto))
+	fmt.Fprintf(hash, "host %q\n", forwardReq.Header.Get(forward.XForwardedHost))
+	if !c.ignoreMethodAndURI {
+		fmt.Fprintf(hash, "method %q\n", forwardReq.Header.Get(xForwardedMethod))
+		fmt.Fprintf(hash, "uri %q\n", forwardReq.Header.Get(xForwardedURI))
+	}
+
+	if body != nil {
+		fmt.Fprintf(hash, "body %x\n", sha256.Sum256(body))
+	}
+
+	return c.keyPrefix + hex.EncodeToString(hash.Sum(nil))
+}
+
+func (c *forwardAuthCache) get(ctx context.Context, logger log.Logger, key string) (forwardAuthDecision, bool) {
+	if c.decisions != nil {
+		value, ok := c.decisions.Get(key)
+		if !ok {
+			return forwardAuthDecision{}, false
+		}
+
+		return value.(forwardAuthDecision), true
+	}
+
+	var decision forwardAuthDecision
+	if err := c.mh.Get(ctx, key, &decision); err != nil {
+		if !errors.As(err, &mc.ErrKeyNotFound{}) {
+			logger.Errorf("Could not get the decision from memcached: %v", err)
+		}
+		return forwardAuthDecision{}, false
+	}
+
+	return decision, true
+}
+
+// set caches the decision, unless it is an error of the authentication server.
+func (c *forwardAuthCache) set(ctx context.Context, logger log.Logger, key string, decision forwardAuthDecision) {
+	ttl := c.ttl
+	switch {
+	case decision.StatusCode >= http.StatusInternalServerError:
+		return
+	case decision.StatusCode < http.StatusOK || decision.StatusCode >= http.StatusMultipleChoices:
+		ttl = c.denyTTL
+	}
+
+	if ttl <= 0 {
+		return
+	}
+
+	if c.decisions != nil {
+		if err := c.decisions.Set(key, decision, ttlSeconds(ttl)); err != nil {
+			logger.Errorf("Could not cache the decision: %v", err)
+		}
+		return
+	}
+
+	// The memcached expirations are in seconds.
+	if err := c.mh.Set(ctx, key, decision, time.Duration(ttlSeconds(ttl))*time.Second); err != nil {
+		logger.Errorf("Could not set the decision in memcached: %v", err)
+	}
+}
+
+// ttlSeconds returns the TTL rounded up to the second, as the stores do not handle smaller TTLs.
+func ttlSeconds(ttl time.Duration) int {
+	return int(math.Ceil(ttl.Seconds()))
+}
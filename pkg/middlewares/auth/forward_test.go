@@ -6,12 +6,16 @@ import (
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/opentracing/opentracing-go"
 	"github.com/opentracing/opentracing-go/mocktracer"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	ptypes "github.com/traefik/paerser/types"
 	"github.com/traefik/traefik/v2/pkg/config/dynamic"
 	tracingMiddleware "github.com/traefik/traefik/v2/pkg/middlewares/tracing"
 	"github.com/traefik/traefik/v2/pkg/testhelpers"
@@ -32,7 +36,7 @@ func TestForwardAuthFail(t *testing.T) {
 
 	middleware, err := NewForward(context.Background(), next, dynamic.ForwardAuth{
 		Address: server.URL,
-	}, "authTest")
+	}, "authTest", nil)
 	require.NoError(t, err)
 
 	ts := httptest.NewServer(middleware)
@@ -77,7 +81,7 @@ func TestForwardAuthSuccess(t *testing.T) {
 		AuthResponseHeaders:      []string{"X-Auth-User", "X-Auth-Group"},
 		AuthResponseHeadersRegex: "^Foo-",
 	}
-	middleware, err := NewForward(context.Background(), next, auth, "authTest")
+	middleware, err := NewForward(context.Background(), next, auth, "authTest", nil)
 	require.NoError(t, err)
 
 	ts := httptest.NewServer(middleware)
@@ -110,7 +114,7 @@ func TestForwardAuthRedirect(t *testing.T) {
 
 	auth := dynamic.ForwardAuth{Address: authTs.URL}
 
-	authMiddleware, err := NewForward(context.Background(), next, auth, "authTest")
+	authMiddleware, err := NewForward(context.Background(), next, auth, "authTest", nil)
 	require.NoError(t, err)
 
 	ts := httptest.NewServer(authMiddleware)
@@ -161,7 +165,7 @@ func TestForwardAuthRemoveHopByHopHeaders(t *testing.T) {
 
 	auth := dynamic.ForwardAuth{Address: authTs.URL}
 
-	authMiddleware, err := NewForward(context.Background(), next, auth, "authTest")
+	authMiddleware, err := NewForward(context.Background(), next, auth, "authTest", nil)
 	require.NoError(t, err)
 
 	ts := httptest.NewServer(authMiddleware)
@@ -207,7 +211,7 @@ func TestForwardAuthFailResponseHeaders(t *testing.T) {
 	auth := dynamic.ForwardAuth{
 		Address: authTs.URL,
 	}
-	authMiddleware, err := NewForward(context.Background(), next, auth, "authTest")
+	authMiddleware, err := NewForward(context.Background(), next, auth, "authTest", nil)
 	require.NoError(t, err)
 
 	ts := httptest.NewServer(authMiddleware)
@@ -245,6 +249,461 @@ func TestForwardAuthFailResponseHeaders(t *testing.T) {
 	assert.Equal(t, "Forbidden\n", string(body))
 }
 
+func TestNewForward_errors(t *testing.T) {
+	testCases := []struct {
+		desc   string
+		config dynamic.ForwardAuth
+	}{
+		{
+			desc:   "unknown failure policy",
+			config: dynamic.ForwardAuth{Address: "http://auth", FailurePolicy: "ignore"},
+		},
+		{
+			desc:   "invalid failure status",
+			config: dynamic.ForwardAuth{Address: "http://auth", FailurePolicy: "open", FailureStatus: []string{"5xx"}},
+		},
+		{
+			desc: "cache without TTL",
+			config: dynamic.ForwardAuth{Address: "http://auth", Cache: &dynamic.ForwardAuthCache{
+				KeyHeaders: []string{"Authorization"},
+			}},
+		},
+		{
+			desc: "cache without key",
+			config: dynamic.ForwardAuth{Address: "http://auth", Cache: &dynamic.ForwardAuthCache{
+				TTL: ptypes.Duration(time.Minute),
+			}},
+		},
+		{
+			desc: "cache in memcached without memcached",
+			config: dynamic.ForwardAuth{Address: "http://auth", Cache: &dynamic.ForwardAuthCache{
+				TTL:        ptypes.Duration(time.Minute),
+				KeyHeaders: []string{"Authorization"},
+				Store:      "memcached",
+			}},
+		},
+		{
+			desc: "unknown cache store",
+			config: dynamic.ForwardAuth{Address: "http://auth", Cache: &dynamic.ForwardAuthCache{
+				TTL:        ptypes.Duration(time.Minute),
+				KeyHeaders: []string{"Authorization"},
+				Store:      "redis",
+			}},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+
+			_, err := NewForward(context.Background(), next, test.config, "authTest", nil)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestForwardAuthCache(t *testing.T) {
+	testCases := []struct {
+		desc           string
+		cache          dynamic.ForwardAuthCache
+		requests       []*http.Request
+		expectedStatus []int
+		expectedCalls  int32
+	}{
+		{
+			desc: "allowed decision cached",
+			cache: dynamic.ForwardAuthCache{
+				TTL:        ptypes.Duration(time.Minute),
+				KeyHeaders: []string{"Authorization"},
+			},
+			requests: []*http.Request{
+				newForwardAuthRequest(http.MethodGet, "/foo", "Bearer alice"),
+				newForwardAuthRequest(http.MethodGet, "/foo", "Bearer alice"),
+			},
+			expectedStatus: []int{http.StatusOK, http.StatusOK},
+			expectedCalls:  1,
+		},
+		{
+			desc: "decisions cached by key header",
+			cache: dynamic.ForwardAuthCache{
+				TTL:        ptypes.Duration(time.Minute),
+				KeyHeaders: []string{"Authorization"},
+			},
+			requests: []*http.Request{
+				newForwardAuthRequest(http.MethodGet, "/foo", "Bearer alice"),
+				newForwardAuthRequest(http.MethodGet, "/foo", "Bearer bob"),
+				newForwardAuthRequest(http.MethodGet, "/foo", "Bearer alice"),
+			},
+			expectedStatus: []int{http.StatusOK, http.StatusOK, http.StatusOK},
+			expectedCalls:  2,
+		},
+		{
+			desc: "decisions cached by URI",
+			cache: dynamic.ForwardAuthCache{
+				TTL:        ptypes.Duration(time.Minute),
+				KeyHeaders: []string{"Authorization"},
+			},
+			requests: []*http.Request{
+				newForwardAuthRequest(http.MethodGet, "/foo", "Bearer alice"),
+				newForwardAuthRequest(http.MethodGet, "/bar", "Bearer alice"),
+				newForwardAuthRequest(http.MethodPost, "/bar", "Bearer alice"),
+			},
+			expectedStatus: []int{http.StatusOK, http.StatusOK, http.StatusOK},
+			expectedCalls:  3,
+		},
+		{
+			desc: "decisions shared between URIs",
+			cache: dynamic.ForwardAuthCache{
+				TTL:                ptypes.Duration(time.Minute),
+				KeyHeaders:         []string{"Authorization"},
+				IgnoreMethodAndURI: true,
+			},
+			requests: []*http.Request{
+				newForwardAuthRequest(http.MethodGet, "/foo", "Bearer alice"),
+				newForwardAuthRequest(http.MethodGet, "/bar", "Bearer alice"),
+				newForwardAuthRequest(http.MethodPost, "/bar", "Bearer alice"),
+			},
+			expectedStatus: []int{http.StatusOK, http.StatusOK, http.StatusOK},
+			expectedCalls:  1,
+		},
+		{
+			desc: "decisions cached by key cookie",
+			cache: dynamic.ForwardAuthCache{
+				TTL:        ptypes.Duration(time.Minute),
+				KeyCookies: []string{"session"},
+			},
+			requests: []*http.Request{
+				newForwardAuthRequest(http.MethodGet, "/foo", "Bearer alice", &http.Cookie{Name: "session", Value: "1"}),
+				newForwardAuthRequest(http.MethodGet, "/foo", "Bearer alice", &http.Cookie{Name: "session", Value: "1"}),
+				newForwardAuthRequest(http.MethodGet, "/foo", "Bearer alice", &http.Cookie{Name: "session", Value: "2"}),
+			},
+			expectedStatus: []int{http.StatusOK, http.StatusOK, http.StatusOK},
+			expectedCalls:  2,
+		},
+		{
+			desc: "request without key not cached",
+			cache: dynamic.ForwardAuthCache{
+				TTL:        ptypes.Duration(time.Minute),
+				KeyHeaders: []string{"Authorization"},
+			},
+			requests: []*http.Request{
+				newForwardAuthRequest(http.MethodGet, "/foo", ""),
+				newForwardAuthRequest(http.MethodGet, "/foo", ""),
+			},
+			expectedStatus: []int{http.StatusUnauthorized, http.StatusUnauthorized},
+			expectedCalls:  2,
+		},
+		{
+			desc: "denied decision not cached by default",
+			cache: dynamic.ForwardAuthCache{
+				TTL:        ptypes.Duration(time.Minute),
+				KeyHeaders: []string{"Authorization"},
+			},
+			requests: []*http.Request{
+				newForwardAuthRequest(http.MethodGet, "/foo", "Bearer mallory"),
+				newForwardAuthRequest(http.MethodGet, "/foo", "Bearer mallory"),
+			},
+			expectedStatus: []int{http.StatusForbidden, http.StatusForbidden},
+			expectedCalls:  2,
+		},
+		{
+			desc: "denied decision cached",
+			cache: dynamic.ForwardAuthCache{
+				TTL:        ptypes.Duration(time.Minute),
+				DenyTTL:    ptypes.Duration(time.Minute),
+				KeyHeaders: []string{"Authorization"},
+			},
+			requests: []*http.Request{
+				newForwardAuthRequest(http.MethodGet, "/foo", "Bearer mallory"),
+				newForwardAuthRequest(http.MethodGet, "/foo", "Bearer mallory"),
+			},
+			expectedStatus: []int{http.StatusForbidden, http.StatusForbidden},
+			expectedCalls:  1,
+		},
+		{
+			desc: "server error not cached",
+			cache: dynamic.ForwardAuthCache{
+				TTL:        ptypes.Duration(time.Minute),
+				DenyTTL:    ptypes.Duration(time.Minute),
+				KeyHeaders: []string{"Authorization"},
+			},
+			requests: []*http.Request{
+				newForwardAuthRequest(http.MethodGet, "/foo", "Bearer error"),
+				newForwardAuthRequest(http.MethodGet, "/foo", "Bearer error"),
+			},
+			expectedStatus: []int{http.StatusInternalServerError, http.StatusInternalServerError},
+			expectedCalls:  2,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			var calls int32
+			authServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				atomic.AddInt32(&calls, 1)
+
+				switch r.Header.Get("Authorization") {
+				case "Bearer alice", "Bearer bob":
+					w.Header().Set("X-Auth-User", strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer "))
+				case "Bearer mallory":
+					http.Error(w, "Forbidden", http.StatusForbidden)
+				case "Bearer error":
+					http.Error(w, "Error", http.StatusInternalServerError)
+				default:
+					http.Error(w, "Unauthorized", http.StatusUnauthorized)
+				}
+			}))
+			t.Cleanup(authServer.Close)
+
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				fmt.Fprint(w, r.Header.Get("X-Auth-User"))
+			})
+
+			cache := test.cache
+			auth := dynamic.ForwardAuth{
+				Address:             authServer.URL,
+				AuthResponseHeaders: []string{"X-Auth-User"},
+				Cache:               &cache,
+			}
+
+			handler, err := NewForward(context.Background(), next, auth, "authTest", nil)
+			require.NoError(t, err)
+
+			for i, req := range test.requests {
+				// The response headers of the authentication server must replace the forged ones, even when cached.
+				req.Header.Set("X-Auth-User", "forged")
+
+				rw := httptest.NewRecorder()
+				handler.ServeHTTP(rw, req)
+
+				assert.Equal(t, test.expectedStatus[i], rw.Code)
+				if rw.Code == http.StatusOK {
+					assert.Equal(t, strings.TrimPrefix(req.Header.Get("Authorization"), "Bearer "), rw.Body.String())
+				} else {
+					assert.NotEmpty(t, rw.Body.String())
+				}
+			}
+
+			assert.Equal(t, test.expectedCalls, atomic.LoadInt32(&calls))
+		})
+	}
+}
+
+func TestForwardAuthForwardBody(t *testing.T) {
+	testCases := []struct {
+		desc               string
+		forwardBody        bool
+		maxBodySize        int64
+		body               string
+		expectedStatus     int
+		expectedAuthMethod string
+		expectedAuthBody   string
+	}{
+		{
+			desc:               "body not forwarded",
+			body:               "payload",
+			expectedStatus:     http.StatusOK,
+			expectedAuthMethod: http.MethodGet,
+		},
+		{
+			desc:               "body forwarded",
+			forwardBody:        true,
+			body:               "payload",
+			expectedStatus:     http.StatusOK,
+			expectedAuthMethod: http.MethodPost,
+			expectedAuthBody:   "payload",
+		},
+		{
+			desc:               "body within the max size",
+			forwardBody:        true,
+			maxBodySize:        7,
+			body:               "payload",
+			expectedStatus:     http.StatusOK,
+			expectedAuthMethod: http.MethodPost,
+			expectedAuthBody:   "payload",
+		},
+		{
+			desc:           "body too large",
+			forwardBody:    true,
+			maxBodySize:    6,
+			body:           "payload",
+			expectedStatus: http.StatusRequestEntityTooLarge,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			var authMethod, authBody string
+			authServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, err := io.ReadAll(r.Body)
+				require.NoError(t, err)
+
+				authMethod = r.Method
+				authBody = string(body)
+			}))
+			t.Cleanup(authServer.Close)
+
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, err := io.ReadAll(r.Body)
+				require.NoError(t, err)
+
+				fmt.Fprint(w, string(body))
+			})
+
+			auth := dynamic.ForwardAuth{
+				Address:     authServer.URL,
+				ForwardBody: test.forwardBody,
+				MaxBodySize: test.maxBodySize,
+			}
+
+			handler, err := NewForward(context.Background(), next, auth, "authTest", nil)
+			require.NoError(t, err)
+
+			req := httptest.NewRequest(http.MethodPost, "http://example.com/foo", strings.NewReader(test.body))
+			rw := httptest.NewRecorder()
+			handler.ServeHTTP(rw, req)
+
+			assert.Equal(t, test.expectedStatus, rw.Code)
+			assert.Equal(t, test.expectedAuthMethod, authMethod)
+			assert.Equal(t, test.expectedAuthBody, authBody)
+
+			if test.expectedStatus == http.StatusOK {
+				assert.Equal(t, test.body, rw.Body.String())
+			}
+		})
+	}
+}
+
+func TestForwardAuthFailurePolicy(t *testing.T) {
+	testCases := []struct {
+		desc           string
+		failurePolicy  string
+		failureStatus  []string
+		authStatus     int
+		unavailable    bool
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			desc:           "passthrough server error",
+			authStatus:     http.StatusServiceUnavailable,
+			expectedStatus: http.StatusServiceUnavailable,
+			expectedBody:   "auth failure\n",
+		},
+		{
+			desc:           "fail open on server error",
+			failurePolicy:  "open",
+			authStatus:     http.StatusServiceUnavailable,
+			expectedStatus: http.StatusOK,
+			expectedBody:   "traefik",
+		},
+		{
+			desc:           "fail closed on server error",
+			failurePolicy:  "closed",
+			authStatus:     http.StatusBadGateway,
+			expectedStatus: http.StatusServiceUnavailable,
+		},
+		{
+			desc:           "status not handled as failure",
+			failurePolicy:  "open",
+			failureStatus:  []string{"502"},
+			authStatus:     http.StatusServiceUnavailable,
+			expectedStatus: http.StatusServiceUnavailable,
+			expectedBody:   "auth failure\n",
+		},
+		{
+			desc:           "fail open on custom status",
+			failurePolicy:  "open",
+			failureStatus:  []string{"429", "500-599"},
+			authStatus:     http.StatusTooManyRequests,
+			expectedStatus: http.StatusOK,
+			expectedBody:   "traefik",
+		},
+		{
+			desc:           "passthrough unavailable server",
+			unavailable:    true,
+			expectedStatus: http.StatusInternalServerError,
+		},
+		{
+			desc:           "fail open on unavailable server",
+			failurePolicy:  "open",
+			unavailable:    true,
+			expectedStatus: http.StatusOK,
+			expectedBody:   "traefik",
+		},
+		{
+			desc:           "fail closed on unavailable server",
+			failurePolicy:  "closed",
+			unavailable:    true,
+			expectedStatus: http.StatusServiceUnavailable,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			authServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("X-Auth-User", "user@example.com")
+				http.Error(w, "auth failure", test.authStatus)
+			}))
+			t.Cleanup(authServer.Close)
+
+			if test.unavailable {
+				authServer.Close()
+			}
+
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				// The forged header must not reach the service when failing open.
+				assert.Empty(t, r.Header.Get("X-Auth-User"))
+				fmt.Fprint(w, "traefik")
+			})
+
+			auth := dynamic.ForwardAuth{
+				Address:             authServer.URL,
+				AuthResponseHeaders: []string{"X-Auth-User"},
+				FailurePolicy:       test.failurePolicy,
+				FailureStatus:       test.failureStatus,
+			}
+
+			handler, err := NewForward(context.Background(), next, auth, "authTest", nil)
+			require.NoError(t, err)
+
+			req := httptest.NewRequest(http.MethodGet, "http://example.com/foo", nil)
+			req.Header.Set("X-Auth-User", "forged")
+
+			rw := httptest.NewRecorder()
+			handler.ServeHTTP(rw, req)
+
+			assert.Equal(t, test.expectedStatus, rw.Code)
+			assert.Equal(t, test.expectedBody, rw.Body.String())
+		})
+	}
+}
+
+func newForwardAuthRequest(method, path, authorization string, cookies ...*http.Cookie) *http.Request {
+	req := httptest.NewRequest(method, "http://example.com"+path, nil)
+	if authorization != "" {
+		req.Header.Set("Authorization", authorization)
+	}
+
+	for _, cookie := range cookies {
+		req.AddCookie(cookie)
+	}
+
+	return req
+}
+
 func Test_writeHeader(t *testing.T) {
 	testCases := []struct {
 		name                      string
@@ -469,7 +928,7 @@ func TestForwardAuthUsesTracing(t *testing.T) {
 
 	tr, _ := tracing.NewTracing("testApp", 100, &mockBackend{tracer})
 
-	next, err := NewForward(context.Background(), next, auth, "authTest")
+	next, err := NewForward(context.Background(), next, auth, "authTest", nil)
 	require.NoError(t, err)
 
 	next = tracingMiddleware.NewEntryPoint(context.Background(), tr, "tracingTest", next)
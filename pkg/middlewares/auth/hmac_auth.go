@@ -0,0 +1,464 @@
+package auth
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/opentracing/opentracing-go/ext"
+	"github.com/traefik/traefik/v2/pkg/config/dynamic"
+	"github.com/traefik/traefik/v2/pkg/log"
+	mc "github.com/traefik/traefik/v2/pkg/memcached"
+	"github.com/traefik/traefik/v2/pkg/middlewares"
+	"github.com/traefik/traefik/v2/pkg/middlewares/accesslog"
+	"github.com/traefik/traefik/v2/pkg/tracing"
+)
+
+const (
+	hmacTypeName = "HMACAuth"
+
+	hmacSchemeBody      = "body"
+	hmacSchemeCanonical = "canonical"
+
+	// hmacMaxNonces is the maximum number of nonces remembered to detect the replayed requests,
+	// the requests with a new nonce being refused once it is reached.
+	hmacMaxNonces = 100000
+)
+
+// hmacHashes are the supported hash algorithms of the HMAC signatures.
+var hmacHashes = map[string]func() hash.Hash{
+	"sha1":   sha1.New,
+	"sha256": sha256.New,
+	"sha512": sha512.New,
+}
+
+// hmacAuth is a middleware that verifies the HMAC signatures of the requests.
+type hmacAuth struct {
+	next http.Handler
+	name string
+
+	secrets map[string][]byte
+	keyIDs  []string
+
+	scheme            string
+	algorithm         string
+	newHash           func() hash.Hash
+	signatureHeader   string
+	signaturePrefix   string
+	signatureEncoding string
+	signedHeaders     []string
+	timestampHeader   string
+	maxSkew           time.Duration
+	nonceHeader       string
+	nonces            hmacNonceStore
+	maxBodySize       int64
+	keyIDHeader       string
+}
+
+// NewHMAC creates an hmacAuth middleware.
+func NewHMAC(ctx context.Context, next http.Handler, config dynamic.HMACAuth, name string, nonceStores *HMACNonceStores, memcached *mc.Client) (http.Handler, error) {
+	log.FromContext(middlewares.GetLoggerCtx(ctx, name, hmacTypeName)).Debug("Creating middleware")
+
+	secrets, err := loadHMACSecrets(config.SecretsFile, config.Secrets)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(secrets) == 0 {
+		return nil, errors.New("at least one secret must be set")
+	}
+
+	h := &hmacAuth{
+		next:              next,
+		name:              name,
+		secrets:           secrets,
+		scheme:            config.Scheme,
+		algorithm:         strings.ToLower(config.Algorithm),
+		signatureHeader:   config.SignatureHeader,
+		signaturePrefix:   config.SignaturePrefix,
+		signatureEncoding: config.SignatureEncoding,
+		timestampHeader:   config.TimestampHeader,
+		maxSkew:           time.Duration(config.MaxSkew),
+		nonceHeader:       config.NonceHeader,
+		maxBodySize:       config.MaxBodySize,
+		keyIDHeader:       config.KeyIDHeader,
+	}
+
+	for keyID := range secrets {
+		h.keyIDs = append(h.keyIDs, keyID)
+	}
+	sort.Strings(h.keyIDs)
+
+	if h.scheme == "" {
+		h.scheme = hmacSchemeBody
+	}
+
+	if h.algorithm == "" {
+		h.algorithm = "sha256"
+	}
+
+	var ok bool
+	h.newHash, ok = hmacHashes[h.algorithm]
+	if !ok {
+		return nil, fmt.Errorf("unsupported algorithm %q", config.Algorithm)
+	}
+
+	if h.maxBodySize <= 0 {
+		h.maxBodySize = defaultMaxBodySize
+	}
+
+	switch h.scheme {
+	case hmacSchemeBody:
+		if h.signatureHeader == "" {
+			return nil, errors.New("the signature header must be set for the body scheme")
+		}
+
+		switch h.signatureEncoding {
+		case "":
+			h.signatureEncoding = "hex"
+		case "hex", "base64":
+		default:
+			return nil, fmt.Errorf("unsupported signature encoding %q", h.signatureEncoding)
+		}
+
+	case hmacSchemeCanonical:
+		if h.timestampHeader == "" {
+			return nil, errors.New("the timestamp header must be set for the canonical scheme")
+		}
+
+		// The host, the timestamp, and the nonce must always be signed.
+		required := append([]string{"host", h.timestampHeader}, config.SignedHeaders...)
+		if h.nonceHeader != "" {
+			required = append(required, h.nonceHeader)
+		}
+
+		for _, header := range required {
+			h.signedHeaders = append(h.signedHeaders, strings.ToLower(header))
+		}
+
+	default:
+		return nil, fmt.Errorf("unknown scheme %q", h.scheme)
+	}
+
+	if h.timestampHeader != "" && h.maxSkew <= 0 {
+		return nil, errors.New("the max skew must be positive")
+	}
+
+	if h.nonceHeader != "" {
+		if h.timestampHeader == "" {
+			return nil, errors.New("the timestamp header must be set to reject the replayed nonces")
+		}
+
+		// A nonce is remembered as long as the requests signed at the same time are accepted.
+		h.nonces, err = newHMACNonceStore(config.NonceStore, name, 2*h.maxSkew, nonceStores, memcached)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return h, nil
+}
+
+func (h *hmacAuth) GetTracingInformation() (string, ext.SpanKindEnum) {
+	return h.name, tracing.SpanKindNoneEnum
+}
+
+func (h *hmacAuth) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	ctx := middlewares.GetLoggerCtx(req.Context(), h.name, hmacTypeName)
+	logger := log.FromContext(ctx)
+
+	body, err := readBody(req, h.maxBodySize)
+	if err != nil {
+		logMessage := fmt.Sprintf("Error reading request body. Cause: %s", err)
+		logger.Debug(logMessage)
+		tracing.SetErrorWithEvent(req, logMessage)
+
+		if errors.Is(err, errBodyTooLarge) {
+			rejectHMAC(ctx, rw, http.StatusRequestEntityTooLarge)
+			return
+		}
+
+		rejectHMAC(ctx, rw, http.StatusBadRequest)
+		return
+	}
+
+	var keyID string
+	switch h.scheme {
+	case hmacSchemeCanonical:
+		keyID, err = h.verifyCanonicalSignature(req, body)
+	default:
+		keyID, err = h.verifyBodySignature(req, body)
+	}
+
+	if err == nil {
+		err = h.verifyFreshness(req, keyID)
+	}
+
+	if err != nil {
+		logMessage := fmt.Sprintf("Authentication failed: %v", err)
+		logger.Debug(logMessage)
+		tracing.SetErrorWithEvent(req, logMessage)
+		rejectHMAC(ctx, rw, http.StatusUnauthorized)
+		return
+	}
+
+	logData := accesslog.GetLogData(req)
+	if logData != nil {
+		logData.Core[accesslog.ClientUsername] = keyID
+	}
+
+	logger.Debug("Authentication succeeded")
+
+	if h.keyIDHeader != "" {
+		req.Header.Set(h.keyIDHeader, keyID)
+	}
+
+	h.next.ServeHTTP(rw, req)
+}
+
+// verifyBodySignature verifies the signature of the body, prefixed with the timestamp and the nonce, if any,
+// against all the secrets, and returns the key ID of the matching secret.
+func (h *hmacAuth) verifyBodySignature(req *http.Request, body []byte) (string, error) {
+	value := req.Header.Get(h.signatureHeader)
+	if value == "" {
+		return "", errors.New("missing signature")
+	}
+
+	if !strings.HasPrefix(value, h.signaturePrefix) {
+		return "", errors.New("invalid signature prefix")
+	}
+	encoded := strings.TrimPrefix(value, h.signaturePrefix)
+
+	var signature []byte
+	var err error
+	switch h.signatureEncoding {
+	case "base64":
+		signature, err = base64.StdEncoding.DecodeString(encoded)
+	default:
+		signature, err = hex.DecodeString(encoded)
+	}
+	if err != nil {
+		return "", fmt.Errorf("invalid signature encoding: %w", err)
+	}
+
+	var prefix string
+	for _, header := range []string{h.timestampHeader, h.nonceHeader} {
+		if header == "" {
+			continue
+		}
+
+		headerValue := req.Header.Get(header)
+		if headerValue == "" {
+			return "", fmt.Errorf("missing %s header", header)
+		}
+		prefix += headerValue + "."
+	}
+
+	for _, keyID := range h.keyIDs {
+		mac := hmac.New(h.newHash, h.secrets[keyID])
+		mac.Write([]byte(prefix))
+		mac.Write(body)
+
+		if hmac.Equal(signature, mac.Sum(nil)) {
+			return keyID, nil
+		}
+	}
+
+	return "", errors.New("invalid signature")
+}
+
+// verifyCanonicalSignature verifies the signature of the canonical request sent in the Authorization header,
+// with the secret of the key ID of the header.
+func (h *hmacAuth) verifyCanonicalSignature(req *http.Request, body []byte) (string, error) {
+	authScheme := "HMAC-" + strings.ToUpper(h.algorithm)
+
+	scheme, params, _ := strings.Cut(req.Header.Get(authorizationHeader), " ")
+	if scheme != authScheme {
+		return "", fmt.Errorf("missing %s authorization", authScheme)
+	}
+
+	var keyID, encoded string
+	var signedHeaders []string
+	for _, param := range strings.Split(params, ",") {
+		name, value, _ := strings.Cut(strings.TrimSpace(param), "=")
+		switch name {
+		case "KeyID":
+			keyID = value
+		case "SignedHeaders":
+			signedHeaders = strings.Split(strings.ToLower(value), ";")
+		case "Signature":
+			encoded = value
+		}
+	}
+
+	secret, ok := h.secrets[keyID]
+	if !ok {
+		return "", fmt.Errorf("unknown key ID %q", keyID)
+	}
+
+	for _, required := range h.signedHeaders {
+		if !contains(signedHeaders, required) {
+			return "", fmt.Errorf("the %s header must be signed", required)
+		}
+	}
+
+	signature, err := hex.DecodeString(encoded)
+	if err != nil {
+		return "", fmt.Errorf("invalid signature encoding: %w", err)
+	}
+
+	canonicalRequest := h.canonicalRequest(req, signedHeaders, body)
+
+	mac := hmac.New(h.newHash, secret)
+	mac.Write([]byte(authScheme + "\n" + req.Header.Get(h.timestampHeader) + "\n" + h.hexHash([]byte(canonicalRequest))))
+
+	if !hmac.Equal(signature, mac.Sum(nil)) {
+		return "", errors.New("invalid signature")
+	}
+
+	return keyID, nil
+}
+
+// canonicalRequest returns the canonical form of the request:
+// the method, the path, the sorted query parameters, the signed headers, and the hash of the body, separated by new lines.
+func (h *hmacAuth) canonicalRequest(req *http.Request, signedHeaders []string, body []byte) string {
+	path := req.URL.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+
+	query := req.URL.Query()
+	var params []string
+	for name, values := range query {
+		for _, value := range values {
+			params = append(params, canonicalEscape(name)+"="+canonicalEscape(value))
+		}
+	}
+	sort.Strings(params)
+
+	var headers strings.Builder
+	for _, name := range signedHeaders {
+		value := req.Host
+		if name != "host" {
+			var values []string
+			for _, v := range req.Header.Values(name) {
+				values = append(values, strings.TrimSpace(v))
+			}
+			value = strings.Join(values, ",")
+		}
+
+		headers.WriteString(name + ":" + value + "\n")
+	}
+
+	return strings.Join([]string{
+		req.Method,
+		path,
+		strings.Join(params, "&"),
+		headers.String(),
+		strings.Join(signedHeaders, ";"),
+		h.hexHash(body),
+	}, "\n")
+}
+
+// verifyFreshness verifies the signing time of the request, and that its nonce was not already used.
+func (h *hmacAuth) verifyFreshness(req *http.Request, keyID string) error {
+	if h.timestampHeader == "" {
+		return nil
+	}
+
+	signedAt, err := parseSigningTime(req.Header.Get(h.timestampHeader))
+	if err != nil {
+		return err
+	}
+
+	skew := time.Since(signedAt)
+	if skew < 0 {
+		skew = -skew
+	}
+
+	if skew > h.maxSkew {
+		return fmt.Errorf("the signing time %s is outside of the allowed skew", signedAt.Format(time.RFC3339))
+	}
+
+	if h.nonces == nil {
+		return nil
+	}
+
+	return h.nonces.add(req.Context(), keyID+"\n"+req.Header.Get(h.nonceHeader))
+}
+
+func (h *hmacAuth) hexHash(data []byte) string {
+	hash := h.newHash()
+	hash.Write(data)
+
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// parseSigningTime parses a signing time, expressed as a Unix timestamp in seconds,
+// in the ISO 8601 basic format (20060102T150405Z), or in the RFC 3339 format.
+func parseSigningTime(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, errors.New("missing signing time")
+	}
+
+	if seconds, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return time.Unix(seconds, 0), nil
+	}
+
+	for _, layout := range []string{"20060102T150405Z", time.RFC3339} {
+		if signedAt, err := time.Parse(layout, value); err == nil {
+			return signedAt, nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("invalid signing time %q", value)
+}
+
+// canonicalEscape escapes the query parameters of the canonical requests, the spaces being encoded as %20.
+func canonicalEscape(value string) string {
+	return strings.ReplaceAll(url.QueryEscape(value), "+", "%20")
+}
+
+func loadHMACSecrets(file string, staticSecrets []string) (map[string][]byte, error) {
+	entries, err := loadUsers(file, staticSecrets)
+	if err != nil {
+		return nil, err
+	}
+
+	secrets := make(map[string][]byte, len(entries))
+	for _, entry := range entries {
+		keyID, secret, ok := strings.Cut(strings.TrimSpace(entry), ":")
+		if !ok || keyID == "" || secret == "" {
+			return nil, errors.New("invalid secret entry, the format must be keyID:secret")
+		}
+
+		if _, exists := secrets[keyID]; exists {
+			return nil, fmt.Errorf("duplicate secret for the key ID %q", keyID)
+		}
+		secrets[keyID] = []byte(secret)
+	}
+
+	return secrets, nil
+}
+
+func rejectHMAC(ctx context.Context, rw http.ResponseWriter, statusCode int) {
+	rw.WriteHeader(statusCode)
+
+	_, err := rw.Write([]byte(http.StatusText(statusCode)))
+	if err != nil {
+		log.FromContext(ctx).Error(err)
+	}
+}
@@ -0,0 +1,581 @@
+package auth
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	ptypes "github.com/traefik/paerser/types"
+	"github.com/traefik/traefik/v2/pkg/config/dynamic"
+)
+
+func TestNewHMAC(t *testing.T) {
+	testCases := []struct {
+		desc          string
+		config        dynamic.HMACAuth
+		expectedError bool
+	}{
+		{
+			desc: "body scheme",
+			config: dynamic.HMACAuth{
+				Secrets:         []string{"github:secret"},
+				SignatureHeader: "X-Hub-Signature-256",
+			},
+		},
+		{
+			desc: "canonical scheme",
+			config: dynamic.HMACAuth{
+				Secrets:         []string{"partner:secret"},
+				Scheme:          "canonical",
+				TimestampHeader: "X-Date",
+				MaxSkew:         ptypes.Duration(5 * time.Minute),
+			},
+		},
+		{
+			desc:          "no secret",
+			config:        dynamic.HMACAuth{SignatureHeader: "X-Signature"},
+			expectedError: true,
+		},
+		{
+			desc: "invalid secret",
+			config: dynamic.HMACAuth{
+				Secrets:         []string{"secret"},
+				SignatureHeader: "X-Signature",
+			},
+			expectedError: true,
+		},
+		{
+			desc: "duplicate key ID",
+			config: dynamic.HMACAuth{
+				Secrets:         []string{"partner:secret", "partner:other"},
+				SignatureHeader: "X-Signature",
+			},
+			expectedError: true,
+		},
+		{
+			desc: "missing secrets file",
+			config: dynamic.HMACAuth{
+				SecretsFile:     filepath.Join(t.TempDir(), "missing"),
+				SignatureHeader: "X-Signature",
+			},
+			expectedError: true,
+		},
+		{
+			desc: "unknown scheme",
+			config: dynamic.HMACAuth{
+				Secrets:         []string{"partner:secret"},
+				Scheme:          "foo",
+				SignatureHeader: "X-Signature",
+			},
+			expectedError: true,
+		},
+		{
+			desc: "unsupported algorithm",
+			config: dynamic.HMACAuth{
+				Secrets:         []string{"partner:secret"},
+				Algorithm:       "md5",
+				SignatureHeader: "X-Signature",
+			},
+			expectedError: true,
+		},
+		{
+			desc: "unsupported signature encoding",
+			config: dynamic.HMACAuth{
+				Secrets:           []string{"partner:secret"},
+				SignatureHeader:   "X-Signature",
+				SignatureEncoding: "base32",
+			},
+			expectedError: true,
+		},
+		{
+			desc: "body scheme without signature header",
+			config: dynamic.HMACAuth{
+				Secrets: []string{"partner:secret"},
+			},
+			expectedError: true,
+		},
+		{
+			desc: "canonical scheme without timestamp header",
+			config: dynamic.HMACAuth{
+				Secrets: []string{"partner:secret"},
+				Scheme:  "canonical",
+			},
+			expectedError: true,
+		},
+		{
+			desc: "nonce header without timestamp header",
+			config: dynamic.HMACAuth{
+				Secrets:         []string{"partner:secret"},
+				SignatureHeader: "X-Signature",
+				NonceHeader:     "X-Nonce",
+			},
+			expectedError: true,
+		},
+		{
+			desc: "timestamp header without max skew",
+			config: dynamic.HMACAuth{
+				Secrets:         []string{"partner:secret"},
+				SignatureHeader: "X-Signature",
+				TimestampHeader: "X-Timestamp",
+			},
+			expectedError: true,
+		},
+		{
+			desc: "memcached nonce store without memcached",
+			config: dynamic.HMACAuth{
+				Secrets:         []string{"partner:secret"},
+				SignatureHeader: "X-Signature",
+				TimestampHeader: "X-Timestamp",
+				MaxSkew:         ptypes.Duration(time.Minute),
+				NonceHeader:     "X-Nonce",
+				NonceStore:      "memcached",
+			},
+			expectedError: true,
+		},
+		{
+			desc: "unknown nonce store",
+			config: dynamic.HMACAuth{
+				Secrets:         []string{"partner:secret"},
+				SignatureHeader: "X-Signature",
+				TimestampHeader: "X-Timestamp",
+				MaxSkew:         ptypes.Duration(time.Minute),
+				NonceHeader:     "X-Nonce",
+				NonceStore:      "foo",
+			},
+			expectedError: true,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {})
+
+			_, err := NewHMAC(context.Background(), next, test.config, "hmacAuth", NewHMACNonceStores(), nil)
+			if test.expectedError {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestHMACAuth_bodyScheme(t *testing.T) {
+	now := strconv.FormatInt(time.Now().Unix(), 10)
+	stale := strconv.FormatInt(time.Now().Add(-time.Hour).Unix(), 10)
+
+	testCases := []struct {
+		desc           string
+		config         dynamic.HMACAuth
+		body           string
+		headers        map[string]string
+		expectedStatus int
+		expectedKeyID  string
+	}{
+		{
+			desc:   "valid signature",
+			config: dynamic.HMACAuth{SignatureHeader: "X-Hub-Signature-256", SignaturePrefix: "sha256="},
+			body:   `{"action":"opened"}`,
+			headers: map[string]string{
+				"X-Hub-Signature-256": "sha256=" + hmacHex("github-secret", `{"action":"opened"}`),
+			},
+			expectedStatus: http.StatusOK,
+			expectedKeyID:  "github",
+		},
+		{
+			desc:   "signature of the rotated secret",
+			config: dynamic.HMACAuth{SignatureHeader: "X-Signature"},
+			body:   "foo",
+			headers: map[string]string{
+				"X-Signature": hmacHex("partner-secret", "foo"),
+			},
+			expectedStatus: http.StatusOK,
+			expectedKeyID:  "partner",
+		},
+		{
+			desc:   "base64 signature",
+			config: dynamic.HMACAuth{SignatureHeader: "X-Signature", SignatureEncoding: "base64"},
+			body:   "foo",
+			headers: map[string]string{
+				"X-Signature": hmacBase64("github-secret", "foo"),
+			},
+			expectedStatus: http.StatusOK,
+			expectedKeyID:  "github",
+		},
+		{
+			desc:           "missing signature",
+			config:         dynamic.HMACAuth{SignatureHeader: "X-Signature"},
+			body:           "foo",
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			desc:   "missing signature prefix",
+			config: dynamic.HMACAuth{SignatureHeader: "X-Signature", SignaturePrefix: "sha256="},
+			body:   "foo",
+			headers: map[string]string{
+				"X-Signature": hmacHex("github-secret", "foo"),
+			},
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			desc:   "tampered body",
+			config: dynamic.HMACAuth{SignatureHeader: "X-Signature"},
+			body:   "bar",
+			headers: map[string]string{
+				"X-Signature": hmacHex("github-secret", "foo"),
+			},
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			desc:   "unknown secret",
+			config: dynamic.HMACAuth{SignatureHeader: "X-Signature"},
+			body:   "foo",
+			headers: map[string]string{
+				"X-Signature": hmacHex("other-secret", "foo"),
+			},
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			desc:   "body too large",
+			config: dynamic.HMACAuth{SignatureHeader: "X-Signature", MaxBodySize: 2},
+			body:   "foo",
+			headers: map[string]string{
+				"X-Signature": hmacHex("github-secret", "foo"),
+			},
+			expectedStatus: http.StatusRequestEntityTooLarge,
+		},
+		{
+			desc: "signed timestamp",
+			config: dynamic.HMACAuth{
+				SignatureHeader: "X-Signature",
+				TimestampHeader: "X-Timestamp",
+				MaxSkew:         ptypes.Duration(5 * time.Minute),
+			},
+			body: "foo",
+			headers: map[string]string{
+				"X-Signature": hmacHex("github-secret", now+".foo"),
+				"X-Timestamp": now,
+			},
+			expectedStatus: http.StatusOK,
+			expectedKeyID:  "github",
+		},
+		{
+			desc: "stale timestamp",
+			config: dynamic.HMACAuth{
+				SignatureHeader: "X-Signature",
+				TimestampHeader: "X-Timestamp",
+				MaxSkew:         ptypes.Duration(5 * time.Minute),
+			},
+			body: "foo",
+			headers: map[string]string{
+				"X-Signature": hmacHex("github-secret", stale+".foo"),
+				"X-Timestamp": stale,
+			},
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			desc: "unsigned timestamp",
+			config: dynamic.HMACAuth{
+				SignatureHeader: "X-Signature",
+				TimestampHeader: "X-Timestamp",
+				MaxSkew:         ptypes.Duration(5 * time.Minute),
+			},
+			body: "foo",
+			headers: map[string]string{
+				"X-Signature": hmacHex("github-secret", "foo"),
+				"X-Timestamp": now,
+			},
+			expectedStatus: http.StatusUnauthorized,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			var forwardedBody string
+			next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				body, err := io.ReadAll(req.Body)
+				require.NoError(t, err)
+				forwardedBody = string(body)
+
+				_, _ = fmt.Fprint(rw, req.Header.Get("X-Key-ID"))
+			})
+
+			config := test.config
+			config.Secrets = []string{"github:github-secret", "partner:partner-secret"}
+			config.KeyIDHeader = "X-Key-ID"
+
+			handler, err := NewHMAC(context.Background(), next, config, "hmacAuth", NewHMACNonceStores(), nil)
+			require.NoError(t, err)
+
+			req := httptest.NewRequest(http.MethodPost, "/webhook", strings.NewReader(test.body))
+			for name, value := range test.headers {
+				req.Header.Set(name, value)
+			}
+
+			rw := httptest.NewRecorder()
+			handler.ServeHTTP(rw, req)
+
+			assert.Equal(t, test.expectedStatus, rw.Code)
+
+			if test.expectedStatus == http.StatusOK {
+				assert.Equal(t, test.expectedKeyID, rw.Body.String())
+				assert.Equal(t, test.body, forwardedBody)
+			}
+		})
+	}
+}
+
+func TestHMACAuth_canonicalScheme(t *testing.T) {
+	signedAt := time.Now().UTC().Format("20060102T150405Z")
+
+	testCases := []struct {
+		desc           string
+		sign           func(req *http.Request)
+		expectedStatus int
+	}{
+		{
+			desc: "valid signature",
+			sign: func(req *http.Request) {
+				signCanonicalRequest(req, "partner", "partner-secret", "host;x-content-type;x-date")
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			desc: "unknown key ID",
+			sign: func(req *http.Request) {
+				signCanonicalRequest(req, "other", "partner-secret", "host;x-content-type;x-date")
+			},
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			desc: "wrong secret",
+			sign: func(req *http.Request) {
+				signCanonicalRequest(req, "partner", "other-secret", "host;x-content-type;x-date")
+			},
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			desc: "required header not signed",
+			sign: func(req *http.Request) {
+				signCanonicalRequest(req, "partner", "partner-secret", "host;x-date")
+			},
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			desc: "tampered query",
+			sign: func(req *http.Request) {
+				signCanonicalRequest(req, "partner", "partner-secret", "host;x-content-type;x-date")
+				req.URL.RawQuery = "b=2&a=3"
+			},
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			desc: "tampered host",
+			sign: func(req *http.Request) {
+				signCanonicalRequest(req, "partner", "partner-secret", "host;x-content-type;x-date")
+				req.Host = "evil.localhost"
+			},
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			desc:           "missing authorization",
+			sign:           func(req *http.Request) {},
+			expectedStatus: http.StatusUnauthorized,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {})
+
+			handler, err := NewHMAC(context.Background(), next, dynamic.HMACAuth{
+				Secrets:         []string{"partner:partner-secret"},
+				Scheme:          "canonical",
+				SignedHeaders:   []string{"X-Content-Type"},
+				TimestampHeader: "X-Date",
+				MaxSkew:         ptypes.Duration(5 * time.Minute),
+			}, "hmacAuth", NewHMACNonceStores(), nil)
+			require.NoError(t, err)
+
+			req := httptest.NewRequest(http.MethodPut, "http://api.localhost/orders/a%20b?b=2&a=1&a=0", strings.NewReader(`{"id":1}`))
+			req.Header.Set("X-Content-Type", "application/json")
+			req.Header.Set("X-Date", signedAt)
+			test.sign(req)
+
+			rw := httptest.NewRecorder()
+			handler.ServeHTTP(rw, req)
+
+			assert.Equal(t, test.expectedStatus, rw.Code)
+		})
+	}
+}
+
+func TestHMACAuth_replayedNonce(t *testing.T) {
+	next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {})
+
+	config := dynamic.HMACAuth{
+		Secrets:         []string{"partner:partner-secret"},
+		SignatureHeader: "X-Signature",
+		TimestampHeader: "X-Timestamp",
+		NonceHeader:     "X-Nonce",
+		MaxSkew:         ptypes.Duration(5 * time.Minute),
+	}
+
+	nonceStores := NewHMACNonceStores()
+
+	handler, err := NewHMAC(context.Background(), next, config, "hmacAuth", nonceStores, nil)
+	require.NoError(t, err)
+
+	now := strconv.FormatInt(time.Now().Unix(), 10)
+
+	serve := func(nonce string) int {
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader("foo"))
+		req.Header.Set("X-Timestamp", now)
+		req.Header.Set("X-Nonce", nonce)
+		req.Header.Set("X-Signature", hmacHex("partner-secret", now+"."+nonce+".foo"))
+
+		rw := httptest.NewRecorder()
+		handler.ServeHTTP(rw, req)
+
+		return rw.Code
+	}
+
+	assert.Equal(t, http.StatusOK, serve("nonce-1"))
+	assert.Equal(t, http.StatusUnauthorized, serve("nonce-1"))
+	assert.Equal(t, http.StatusOK, serve("nonce-2"))
+
+	// The nonces are kept when the middleware is created again by a configuration change.
+	handler, err = NewHMAC(context.Background(), next, config, "hmacAuth", nonceStores, nil)
+	require.NoError(t, err)
+
+	assert.Equal(t, http.StatusUnauthorized, serve("nonce-2"))
+	assert.Equal(t, http.StatusOK, serve("nonce-3"))
+
+	// The nonces of the middlewares no longer in the configuration are forgotten.
+	nonceStores.Sweep()
+	nonceStores.Sweep()
+
+	handler, err = NewHMAC(context.Background(), next, config, "hmacAuth", nonceStores, nil)
+	require.NoError(t, err)
+
+	assert.Equal(t, http.StatusOK, serve("nonce-3"))
+}
+
+func TestHMACSharedNonces(t *testing.T) {
+	store := &fakeNonceAdder{keys: make(map[string]struct{})}
+
+	first := &hmacSharedNonces{ttl: time.Minute, keyPrefix: "traefik-hmacauth-test-", mh: store}
+	second := &hmacSharedNonces{ttl: time.Minute, keyPrefix: "traefik-hmacauth-test-", mh: store}
+
+	require.NoError(t, first.add(context.Background(), "partner\nnonce-1"))
+
+	// The nonces accepted by an instance are refused by the other instances.
+	assert.ErrorIs(t, second.add(context.Background(), "partner\nnonce-1"), errReplayedNonce)
+	assert.NoError(t, second.add(context.Background(), "partner\nnonce-2"))
+
+	// The nonces are refused when they cannot be remembered.
+	store.err = errors.New("unavailable")
+	assert.Error(t, first.add(context.Background(), "partner\nnonce-3"))
+}
+
+type fakeNonceAdder struct {
+	keys map[string]struct{}
+	err  error
+}
+
+func (f *fakeNonceAdder) Add(_ context.Context, key string, _ bool, _ time.Duration) (bool, error) {
+	if f.err != nil {
+		return false, f.err
+	}
+
+	if _, ok := f.keys[key]; ok {
+		return false, nil
+	}
+
+	f.keys[key] = struct{}{}
+	return true, nil
+}
+
+func TestHMACNonces_full(t *testing.T) {
+	nonces := newHMACNonces(100*time.Millisecond, 2)
+
+	require.NoError(t, nonces.add(context.Background(), "nonce-1"))
+	require.NoError(t, nonces.add(context.Background(), "nonce-2"))
+
+	// The remembered nonces are not forgotten to make room for the new ones.
+	assert.ErrorIs(t, nonces.add(context.Background(), "nonce-3"), errTooManyNonces)
+	assert.ErrorIs(t, nonces.add(context.Background(), "nonce-1"), errReplayedNonce)
+
+	// The expired nonces make room for the new ones.
+	time.Sleep(150 * time.Millisecond)
+
+	assert.NoError(t, nonces.add(context.Background(), "nonce-3"))
+	assert.NoError(t, nonces.add(context.Background(), "nonce-1"))
+}
+
+func hmacHex(secret, payload string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(payload))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func hmacBase64(secret, payload string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(payload))
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// signCanonicalRequest signs the request as documented for the canonical scheme, independently of the middleware.
+func signCanonicalRequest(req *http.Request, keyID, secret, signedHeaders string) {
+	body, _ := io.ReadAll(req.Body)
+	req.Body = io.NopCloser(strings.NewReader(string(body)))
+
+	bodyHash := sha256.Sum256(body)
+
+	var canonicalHeaders string
+	for _, name := range strings.Split(signedHeaders, ";") {
+		value := req.Host
+		if name != "host" {
+			value = req.Header.Get(name)
+		}
+		canonicalHeaders += name + ":" + value + "\n"
+	}
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		"a=0&a=1&b=2",
+		canonicalHeaders,
+		signedHeaders,
+		hex.EncodeToString(bodyHash[:]),
+	}, "\n")
+
+	requestHash := sha256.Sum256([]byte(canonicalRequest))
+	signature := hmacHex(secret, "HMAC-SHA256\n"+req.Header.Get("X-Date")+"\n"+hex.EncodeToString(requestHash[:]))
+
+	req.Header.Set("Authorization", fmt.Sprintf("HMAC-SHA256 KeyID=%s, SignedHeaders=%s, Signature=%s", keyID, signedHeaders, signature))
+}
@@ -0,0 +1,208 @@
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/traefik/traefik/v2/pkg/log"
+	mc "github.com/traefik/traefik/v2/pkg/memcached"
+)
+
+const (
+	hmacNonceStoreMemory    = "memory"
+	hmacNonceStoreMemcached = "memcached"
+)
+
+var (
+	errReplayedNonce = errors.New("replayed nonce")
+	errTooManyNonces = errors.New("too many nonces")
+)
+
+// hmacNonceStore remembers the nonces of the accepted requests, to detect the replayed requests.
+type hmacNonceStore interface {
+	add(ctx context.Context, key string) error
+}
+
+func newHMACNonceStore(store, name string, ttl time.Duration, nonceStores *HMACNonceStores, memcached *mc.Client) (hmacNonceStore, error) {
+	switch store {
+	case "", hmacNonceStoreMemory:
+		if nonceStores == nil {
+			return nil, errors.New("the nonce stores are not available")
+		}
+
+		return nonceStores.get(name, ttl), nil
+
+	case hmacNonceStoreMemcached:
+		if memcached == nil {
+			return nil, errors.New("the nonces cannot be stored in memcached, as memcached is not configured")
+		}
+
+		// The nonces of the different middlewares are kept apart in memcached.
+		namespace := sha256.Sum256([]byte(name))
+
+		return &hmacSharedNonces{
+			ttl:       ttl,
+			keyPrefix: "traefik-hmacauth-" + hex.EncodeToString(namespace[:8]) + "-",
+			mh:        mc.NewMemcachedHandler[bool](memcached),
+		}, nil
+
+	default:
+		return nil, fmt.Errorf("unknown nonce store %q", store)
+	}
+}
+
+// HMACNonceStores holds the nonces remembered in memory by the HMAC middlewares, by middleware name,
+// so that the replayed requests are still detected after the configuration changes.
+type HMACNonceStores struct {
+	mu     sync.Mutex
+	stores map[string]*hmacNonces
+}
+
+// NewHMACNonceStores creates an HMACNonceStores.
+func NewHMACNonceStores() *HMACNonceStores {
+	return &HMACNonceStores{stores: make(map[string]*hmacNonces)}
+}
+
+// get returns the nonces of a middleware, which are remembered for at least the given duration.
+func (s *HMACNonceStores) get(name string, ttl time.Duration) *hmacNonces {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	nonces, ok := s.stores[name]
+	if !ok {
+		nonces = newHMACNonces(ttl, hmacMaxNonces)
+		s.stores[name] = nonces
+	}
+
+	nonces.used = true
+	nonces.extendTTL(ttl)
+
+	return nonces
+}
+
+// Sweep forgets the nonces of the middlewares which have not been created since the previous sweep.
+// It is called after each configuration update, once the middlewares of the new configuration are created.
+func (s *HMACNonceStores) Sweep() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for name, nonces := range s.stores {
+		if nonces.used {
+			nonces.used = false
+			continue
+		}
+
+		delete(s.stores, name)
+	}
+}
+
+// hmacNonce is a remembered nonce, with its expiry.
+type hmacNonce struct {
+	key      string
+	expireAt time.Time
+}
+
+// hmacNonces remembers the nonces of the accepted requests in memory, which only detects the requests replayed to the same Traefik instance.
+// As all the nonces are remembered for the same duration, they expire in the order they were added.
+// When the store is full, the new nonces are refused rather than forgetting the unexpired ones,
+// which would allow replaying the requests of the forgotten nonces.
+type hmacNonces struct {
+	ttl         time.Duration
+	maxEntries  int
+	mu          sync.Mutex
+	expirations map[string]time.Time
+	queue       []hmacNonce
+
+	// used is guarded by the lock of the HMACNonceStores.
+	used bool
+}
+
+func newHMACNonces(ttl time.Duration, maxEntries int) *hmacNonces {
+	return &hmacNonces{
+		ttl:         ttl,
+		maxEntries:  maxEntries,
+		expirations: make(map[string]time.Time),
+	}
+}
+
+// add remembers a nonce, and returns an error if it was already used, or if the store is full.
+func (n *hmacNonces) add(_ context.Context, key string) error {
+	now := time.Now()
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	n.removeExpired(now)
+
+	if _, ok := n.expirations[key]; ok {
+		return errReplayedNonce
+	}
+
+	if len(n.expirations) >= n.maxEntries {
+		return errTooManyNonces
+	}
+
+	expireAt := now.Add(n.ttl)
+	n.expirations[key] = expireAt
+	n.queue = append(n.queue, hmacNonce{key: key, expireAt: expireAt})
+
+	return nil
+}
+
+// extendTTL remembers the new nonces for at least the given duration.
+// The duration is never shortened, so that the nonces keep expiring in the order they were added.
+func (n *hmacNonces) extendTTL(ttl time.Duration) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if ttl > n.ttl {
+		n.ttl = ttl
+	}
+}
+
+func (n *hmacNonces) removeExpired(now time.Time) {
+	var i int
+	for i < len(n.queue) && !n.queue[i].expireAt.After(now) {
+		delete(n.expirations, n.queue[i].key)
+		i++
+	}
+
+	n.queue = n.queue[i:]
+}
+
+// nonceAdder stores an item only if its key does not exist yet.
+type nonceAdder interface {
+	Add(ctx context.Context, key string, item bool, ttl time.Duration) (bool, error)
+}
+
+// hmacSharedNonces remembers the nonces of the accepted requests in memcached,
+// which detects the requests replayed to any of the Traefik instances.
+type hmacSharedNonces struct {
+	ttl       time.Duration
+	keyPrefix string
+	mh        nonceAdder
+}
+
+// add remembers a nonce, and returns an error if it was already used, or if it cannot be remembered.
+// The nonces are added only if absent, so that a nonce is accepted once by all the Traefik instances.
+func (n *hmacSharedNonces) add(ctx context.Context, key string) error {
+	// The nonces are hashed, as the memcached keys cannot contain spaces and control characters.
+	hash := sha256.Sum256([]byte(key))
+
+	added, err := n.mh.Add(ctx, n.keyPrefix+hex.EncodeToString(hash[:]), true, n.ttl)
+	if err != nil {
+		log.FromContext(ctx).Errorf("Unable to remember the nonce in memcached, the request is refused: %v", err)
+		return fmt.Errorf("remembering the nonce: %w", err)
+	}
+
+	if !added {
+		return errReplayedNonce
+	}
+
+	return nil
+}
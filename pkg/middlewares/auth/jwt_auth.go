@@ -0,0 +1,633 @@
+package auth
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/opentracing/opentracing-go/ext"
+	"github.com/traefik/traefik/v2/pkg/config/dynamic"
+	"github.com/traefik/traefik/v2/pkg/log"
+	"github.com/traefik/traefik/v2/pkg/middlewares"
+	"github.com/traefik/traefik/v2/pkg/middlewares/accesslog"
+	"github.com/traefik/traefik/v2/pkg/safe"
+	"github.com/traefik/traefik/v2/pkg/tracing"
+	"gopkg.in/square/go-jose.v2"
+	"gopkg.in/square/go-jose.v2/jwt"
+)
+
+const (
+	jwtTypeName = "JWTAuth"
+
+	// jwksMinRefreshInterval is the minimum interval between two fetches of a JSON Web Key Set,
+	// which prevents the tokens referencing unknown keys from triggering a fetch for each request.
+	jwksMinRefreshInterval = 10 * time.Second
+)
+
+var (
+	hmacAlgorithms = []string{
+		string(jose.HS256), string(jose.HS384), string(jose.HS512),
+	}
+	publicKeyAlgorithms = []string{
+		string(jose.RS256), string(jose.RS384), string(jose.RS512),
+		string(jose.PS256), string(jose.PS384), string(jose.PS512),
+		string(jose.ES256), string(jose.ES384), string(jose.ES512),
+		string(jose.EdDSA),
+	}
+)
+
+// jwtAuth is a middleware that verifies the JSON Web Token (JWT) bearer token of the requests.
+type jwtAuth struct {
+	next http.Handler
+	name string
+
+	keys       []jose.JSONWebKey
+	secret     []byte
+	jwks       *jwksFetcher
+	algorithms map[string]struct{}
+
+	issuers        []string
+	audiences      []string
+	clockSkew      time.Duration
+	requireExp     bool
+	requiredClaims map[string]string
+	requiredScopes []string
+	claimsHeaders  map[string]string
+	removeHeader   bool
+}
+
+// NewJWT creates a jwtAuth middleware.
+func NewJWT(ctx context.Context, next http.Handler, config dynamic.JWTAuth, name string) (http.Handler, error) {
+	log.FromContext(middlewares.GetLoggerCtx(ctx, name, jwtTypeName)).Debug("Creating middleware")
+
+	if len(config.Keys) == 0 && config.Secret == "" && config.JWKSURL == "" {
+		return nil, errors.New("no keys, secret or JWKS URL defined")
+	}
+
+	a := &jwtAuth{
+		next:           next,
+		name:           name,
+		algorithms:     make(map[string]struct{}),
+		issuers:        config.Issuers,
+		audiences:      config.Audiences,
+		clockSkew:      time.Duration(config.ClockSkew),
+		requireExp:     config.RequireExp,
+		requiredClaims: config.RequiredClaims,
+		requiredScopes: config.RequiredScopes,
+		claimsHeaders:  config.ClaimsHeaders,
+		removeHeader:   config.RemoveHeader,
+	}
+
+	for i, key := range config.Keys {
+		keys, err := parseJWTKeys([]byte(key))
+		if err != nil {
+			return nil, fmt.Errorf("key %d: %w", i, err)
+		}
+
+		a.keys = append(a.keys, keys...)
+	}
+
+	if config.Secret != "" {
+		a.secret = []byte(config.Secret)
+	}
+
+	if config.JWKSURL != "" {
+		a.jwks = &jwksFetcher{
+			url:             config.JWKSURL,
+			client:          &http.Client{Timeout: 10 * time.Second},
+			refreshInterval: time.Duration(config.JWKSRefreshInterval),
+			minInterval:     jwksMinRefreshInterval,
+		}
+	}
+
+	if len(config.Algorithms) > 0 {
+		for _, algorithm := range config.Algorithms {
+			if !contains(hmacAlgorithms, algorithm) && !contains(publicKeyAlgorithms, algorithm) {
+				return nil, fmt.Errorf("unsupported algorithm %q", algorithm)
+			}
+
+			a.algorithms[algorithm] = struct{}{}
+		}
+	} else {
+		if a.secret != nil {
+			for _, algorithm := range hmacAlgorithms {
+				a.algorithms[algorithm] = struct{}{}
+			}
+		}
+
+		if len(a.keys) > 0 || a.jwks != nil {
+			for _, algorithm := range publicKeyAlgorithms {
+				a.algorithms[algorithm] = struct{}{}
+			}
+		}
+	}
+
+	return a, nil
+}
+
+func (a *jwtAuth) GetTracingInformation() (string, ext.SpanKindEnum) {
+	return a.name, tracing.SpanKindNoneEnum
+}
+
+func (a *jwtAuth) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	ctx := middlewares.GetLoggerCtx(req.Context(), a.name, jwtTypeName)
+	logger := log.FromContext(ctx)
+
+	rawToken, ok := bearerToken(req)
+	if !ok {
+		logger.Debug("Authentication failed: missing bearer token")
+		tracing.SetErrorWithEvent(req, "Authentication failed: missing bearer token")
+		rejectBearer(ctx, rw, http.StatusUnauthorized, "")
+		return
+	}
+
+	claims, err := a.verify(ctx, rawToken)
+	if err != nil {
+		logger.Debugf("Authentication failed: %v", err)
+		tracing.SetErrorWithEvent(req, "Authentication failed")
+		rejectBearer(ctx, rw, http.StatusUnauthorized, "invalid_token")
+		return
+	}
+
+	if err = a.checkScopes(claims); err != nil {
+		logger.Debugf("Authorization failed: %v", err)
+		tracing.SetErrorWithEvent(req, "Authorization failed")
+		rejectBearer(ctx, rw, http.StatusForbidden, "insufficient_scope")
+		return
+	}
+
+	subject, _ := claims["sub"].(string)
+
+	logData := accesslog.GetLogData(req)
+	if logData != nil {
+		logData.Core[accesslog.ClientUsername] = subject
+	}
+
+	logger.Debug("Authentication succeeded")
+
+	setClaimsHeaders(req, claims, a.claimsHeaders)
+
+	if a.removeHeader {
+		logger.Debug("Removing authorization header")
+		req.Header.Del(authorizationHeader)
+	}
+
+	a.next.ServeHTTP(rw, req)
+}
+
+// verify verifies the signature and the claims of the token, and returns its claims.
+func (a *jwtAuth) verify(ctx context.Context, rawToken string) (map[string]interface{}, error) {
+	token, err := jwt.ParseSigned(rawToken)
+	if err != nil {
+		return nil, fmt.Errorf("parsing token: %w", err)
+	}
+
+	if len(token.Headers) != 1 {
+		return nil, errors.New("token must have a single signature")
+	}
+
+	header := token.Headers[0]
+	if _, ok := a.algorithms[header.Algorithm]; !ok {
+		return nil, fmt.Errorf("algorithm %q not allowed", header.Algorithm)
+	}
+
+	var claims map[string]interface{}
+	var registered jwt.Claims
+
+	verified := false
+	for _, key := range a.candidateKeys(ctx, header) {
+		if err = token.Claims(key, &registered, &claims); err == nil {
+			verified = true
+			break
+		}
+	}
+
+	if !verified {
+		return nil, errors.New("invalid signature")
+	}
+
+	if a.requireExp && registered.Expiry == nil {
+		return nil, errors.New("missing exp claim")
+	}
+
+	if err = registered.ValidateWithLeeway(jwt.Expected{Time: time.Now()}, a.clockSkew); err != nil {
+		return nil, err
+	}
+
+	if len(a.issuers) > 0 && !contains(a.issuers, registered.Issuer) {
+		return nil, fmt.Errorf("issuer %q not allowed", registered.Issuer)
+	}
+
+	if len(a.audiences) > 0 && !containsAny(a.audiences, registered.Audience) {
+		return nil, errors.New("audience not allowed")
+	}
+
+	if err = checkRequiredClaims(claims, a.requiredClaims); err != nil {
+		return nil, err
+	}
+
+	return claims, nil
+}
+
+// candidateKeys returns the keys which could have signed a token with the given header.
+func (a *jwtAuth) candidateKeys(ctx context.Context, header jose.Header) []interface{} {
+	if contains(hmacAlgorithms, header.Algorithm) {
+		if a.secret == nil {
+			return nil
+		}
+
+		return []interface{}{a.secret}
+	}
+
+	keys := a.keys
+	if a.jwks != nil {
+		keys = append(keys[:len(keys):len(keys)], a.jwks.get(ctx, header.KeyID)...)
+	}
+
+	var candidates []interface{}
+	for _, key := range keys {
+		if header.KeyID != "" && key.KeyID != "" && header.KeyID != key.KeyID {
+			continue
+		}
+
+		if key.Use == "enc" || (key.Algorithm != "" && key.Algorithm != header.Algorithm) {
+			continue
+		}
+
+		candidates = append(candidates, key.Key)
+	}
+
+	return candidates
+}
+
+func (a *jwtAuth) checkScopes(claims map[string]interface{}) error {
+	if len(a.requiredScopes) == 0 {
+		return nil
+	}
+
+	var scopes []string
+	if scope, ok := claims["scope"].(string); ok {
+		scopes = append(scopes, strings.Fields(scope)...)
+	}
+
+	switch scp := claims["scp"].(type) {
+	case string:
+		scopes = append(scopes, strings.Fields(scp)...)
+	case []interface{}:
+		for _, s := range scp {
+			if value, ok := s.(string); ok {
+				scopes = append(scopes, value)
+			}
+		}
+	}
+
+	for _, required := range a.requiredScopes {
+		if !contains(scopes, required) {
+			return fmt.Errorf("missing scope %q", required)
+		}
+	}
+
+	return nil
+}
+
+// jwksFetcher fetches and caches a JSON Web Key Set.
+type jwksFetcher struct {
+	url             string
+	client          *http.Client
+	refreshInterval time.Duration
+	minInterval     time.Duration
+
+	mu          sync.Mutex
+	keys        []jose.JSONWebKey
+	fetchedAt   time.Time
+	attemptedAt time.Time
+	// refreshing is closed when the fetch in progress, if any, is done.
+	refreshing chan struct{}
+}
+
+// get returns the keys of the set, fetching the set again if it is outdated, or does not have the given key.
+// A single fetch is in progress at a time, and it is only waited for when the current keys cannot verify the token.
+func (f *jwksFetcher) get(ctx context.Context, keyID string) []jose.JSONWebKey {
+	f.mu.Lock()
+
+	now := time.Now()
+
+	outdated := f.fetchedAt.IsZero() || (f.refreshInterval > 0 && now.Sub(f.fetchedAt) >= f.refreshInterval)
+	unknown := keyID != "" && !hasKeyID(f.keys, keyID)
+
+	if (outdated || unknown) && f.refreshing == nil && now.Sub(f.attemptedAt) >= f.minInterval {
+		f.attemptedAt = now
+		f.refreshing = make(chan struct{})
+
+		logger := log.FromContext(ctx)
+		safe.Go(func() { f.refresh(logger) })
+	}
+
+	keys := f.keys
+	refreshing := f.refreshing
+	wait := refreshing != nil && (f.fetchedAt.IsZero() || unknown)
+
+	f.mu.Unlock()
+
+	if !wait {
+		return keys
+	}
+
+	select {
+	case <-refreshing:
+	case <-ctx.Done():
+		return keys
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.keys
+}
+
+// refresh fetches the set, independently of the request which triggered the fetch, as other requests can wait for it.
+func (f *jwksFetcher) refresh(logger log.Logger) {
+	keys, err := f.fetch(context.Background())
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err != nil {
+		// The previous keys are used until the set can be fetched again.
+		logger.Errorf("Unable to fetch JSON Web Key Set from %s: %v", f.url, err)
+	} else {
+		f.keys = keys
+		f.fetchedAt = time.Now()
+	}
+
+	close(f.refreshing)
+	f.refreshing = nil
+}
+
+func (f *jwksFetcher) fetch(ctx context.Context) ([]jose.JSONWebKey, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, f.url, http.NoBody)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, err
+	}
+
+	var set jose.JSONWebKeySet
+	if err = json.Unmarshal(body, &set); err != nil {
+		return nil, err
+	}
+
+	return publicKeys(set.Keys), nil
+}
+
+// parseJWTKeys parses PEM encoded public keys and certificates, or a JSON Web Key (Set).
+func parseJWTKeys(data []byte) ([]jose.JSONWebKey, error) {
+	data = []byte(strings.TrimSpace(string(data)))
+
+	if len(data) > 0 && data[0] == '{' {
+		var set jose.JSONWebKeySet
+		if err := json.Unmarshal(data, &set); err == nil && len(set.Keys) > 0 {
+			return publicKeys(set.Keys), nil
+		}
+
+		var key jose.JSONWebKey
+		if err := json.Unmarshal(data, &key); err != nil {
+			return nil, fmt.Errorf("invalid JSON Web Key: %w", err)
+		}
+
+		return publicKeys([]jose.JSONWebKey{key}), nil
+	}
+
+	var keys []jose.JSONWebKey
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			break
+		}
+
+		var key interface{}
+		var err error
+
+		switch block.Type {
+		case "PUBLIC KEY":
+			key, err = x509.ParsePKIXPublicKey(block.Bytes)
+		case "RSA PUBLIC KEY":
+			key, err = x509.ParsePKCS1PublicKey(block.Bytes)
+		case "CERTIFICATE":
+			var cert *x509.Certificate
+			cert, err = x509.ParseCertificate(block.Bytes)
+			if err == nil {
+				key = cert.PublicKey
+			}
+		default:
+			err = fmt.Errorf("unsupported PEM block %q", block.Type)
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		keys = append(keys, jose.JSONWebKey{Key: key})
+	}
+
+	if len(keys) == 0 {
+		return nil, errors.New("no PEM encoded public key or JSON Web Key found")
+	}
+
+	return keys, nil
+}
+
+// publicKeys returns the public part of the keys, the symmetric keys being discarded.
+func publicKeys(keys []jose.JSONWebKey) []jose.JSONWebKey {
+	var result []jose.JSONWebKey
+	for _, key := range keys {
+		if _, ok := key.Key.([]byte); ok {
+			continue
+		}
+
+		if !key.IsPublic() {
+			key = key.Public()
+		}
+
+		if key.Valid() {
+			result = append(result, key)
+		}
+	}
+
+	return result
+}
+
+func hasKeyID(keys []jose.JSONWebKey, keyID string) bool {
+	for _, key := range keys {
+		if key.KeyID == keyID {
+			return true
+		}
+	}
+
+	return false
+}
+
+func bearerToken(req *http.Request) (string, bool) {
+	scheme, token, ok := strings.Cut(req.Header.Get(authorizationHeader), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return "", false
+	}
+
+	token = strings.TrimSpace(token)
+
+	return token, token != ""
+}
+
+// lookupClaim returns the value of the claim, the names of nested claims being separated by dots.
+func lookupClaim(claims map[string]interface{}, name string) interface{} {
+	if value, ok := claims[name]; ok {
+		return value
+	}
+
+	var current interface{} = claims
+	for _, part := range strings.Split(name, ".") {
+		object, ok := current.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+
+		current = object[part]
+	}
+
+	return current
+}
+
+// setClaimsHeaders sets the headers from the claims,
+// after removing them from the request so that they cannot be forged by the clients.
+func setClaimsHeaders(req *http.Request, claims map[string]interface{}, headers map[string]string) {
+	for header, claim := range headers {
+		req.Header.Del(header)
+
+		value, ok := claimString(lookupClaim(claims, claim))
+		if ok {
+			req.Header.Set(header, value)
+		}
+	}
+}
+
+// checkRequiredClaims checks that the claims have the required claims,
+// a claim having to be equal to the required value, or to contain it for an array claim, unless the value is empty.
+func checkRequiredClaims(claims map[string]interface{}, required map[string]string) error {
+	for name, expected := range required {
+		value := lookupClaim(claims, name)
+		if value == nil {
+			return fmt.Errorf("missing claim %q", name)
+		}
+
+		if expected != "" && !claimContains(value, expected) {
+			return fmt.Errorf("unexpected value for claim %q", name)
+		}
+	}
+
+	return nil
+}
+
+// claimString returns the value of a claim as a header value.
+func claimString(value interface{}) (string, bool) {
+	switch v := value.(type) {
+	case nil:
+		return "", false
+	case string:
+		return v, true
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), true
+	case bool:
+		return strconv.FormatBool(v), true
+	case []interface{}:
+		values := make([]string, 0, len(v))
+		for _, item := range v {
+			if s, ok := claimString(item); ok {
+				values = append(values, s)
+			}
+		}
+
+		return strings.Join(values, ","), true
+	default:
+		raw, err := json.Marshal(v)
+		if err != nil {
+			return "", false
+		}
+
+		return string(raw), true
+	}
+}
+
+// claimContains returns whether the claim is equal to the value, or contains it for an array claim.
+func claimContains(claim interface{}, value string) bool {
+	if items, ok := claim.([]interface{}); ok {
+		for _, item := range items {
+			if s, ok := claimString(item); ok && s == value {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	s, ok := claimString(claim)
+
+	return ok && s == value
+}
+
+func rejectBearer(ctx context.Context, rw http.ResponseWriter, statusCode int, bearerError string) {
+	challenge := fmt.Sprintf("Bearer realm=%q", defaultRealm)
+	if bearerError != "" {
+		challenge += fmt.Sprintf(", error=%q", bearerError)
+	}
+
+	rw.Header().Set("WWW-Authenticate", challenge)
+	rw.WriteHeader(statusCode)
+
+	_, err := rw.Write([]byte(http.StatusText(statusCode)))
+	if err != nil {
+		log.FromContext(ctx).Error(err)
+	}
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
+
+func containsAny(values, candidates []string) bool {
+	for _, candidate := range candidates {
+		if contains(values, candidate) {
+			return true
+		}
+	}
+
+	return false
+}
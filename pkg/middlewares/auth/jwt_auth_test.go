@@ -0,0 +1,550 @@
+package auth
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	ptypes "github.com/traefik/paerser/types"
+	"github.com/traefik/traefik/v2/pkg/config/dynamic"
+	"gopkg.in/square/go-jose.v2"
+	"gopkg.in/square/go-jose.v2/jwt"
+)
+
+func TestNewJWT(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	testCases := []struct {
+		desc          string
+		config        dynamic.JWTAuth
+		expectedError bool
+	}{
+		{
+			desc:          "no keys",
+			config:        dynamic.JWTAuth{},
+			expectedError: true,
+		},
+		{
+			desc:   "PEM public key",
+			config: dynamic.JWTAuth{Keys: []string{pemPublicKey(t, &rsaKey.PublicKey)}},
+		},
+		{
+			desc:   "JSON Web Key Set",
+			config: dynamic.JWTAuth{Keys: []string{jwksJSON(t, jose.JSONWebKey{Key: &rsaKey.PublicKey, KeyID: "1"})}},
+		},
+		{
+			desc:          "invalid key",
+			config:        dynamic.JWTAuth{Keys: []string{"foo"}},
+			expectedError: true,
+		},
+		{
+			desc:   "secret",
+			config: dynamic.JWTAuth{Secret: "secret", Algorithms: []string{"HS256"}},
+		},
+		{
+			desc:          "unsupported algorithm",
+			config:        dynamic.JWTAuth{Secret: "secret", Algorithms: []string{"none"}},
+			expectedError: true,
+		},
+		{
+			desc:   "JWKS URL",
+			config: dynamic.JWTAuth{JWKSURL: "http://127.0.0.1/jwks.json"},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {})
+
+			_, err := NewJWT(context.Background(), next, test.config, "authTest")
+			if test.expectedError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestJWTAuth_ServeHTTP(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	jwks := newJWKSServer(t,
+		jose.JSONWebKey{Key: &rsaKey.PublicKey, KeyID: "rsa", Use: "sig"},
+		jose.JSONWebKey{Key: &ecKey.PublicKey, KeyID: "ec", Use: "sig"},
+	)
+
+	now := time.Now()
+	claims := func(extra map[string]interface{}) map[string]interface{} {
+		c := map[string]interface{}{
+			"iss": "https://issuer.example.org",
+			"aud": []string{"api"},
+			"sub": "alice",
+			"exp": now.Add(time.Hour).Unix(),
+			"nbf": now.Add(-time.Minute).Unix(),
+		}
+		for k, v := range extra {
+			c[k] = v
+		}
+		return c
+	}
+
+	baseConfig := dynamic.JWTAuth{
+		JWKSURL:   jwks.URL,
+		Keys:      []string{pemPublicKey(t, edKey.Public())},
+		Secret:    "secret",
+		Issuers:   []string{"https://issuer.example.org"},
+		Audiences: []string{"api", "other"},
+	}
+
+	testCases := []struct {
+		desc            string
+		config          func(config *dynamic.JWTAuth)
+		authorization   string
+		token           string
+		expectedStatus  int
+		expectedHeaders map[string]string
+	}{
+		{
+			desc:           "missing token",
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			desc:           "not a bearer token",
+			authorization:  "Basic Zm9vOmJhcg==",
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			desc:           "malformed token",
+			authorization:  "Bearer foo",
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			desc:           "RS256 from JWKS",
+			token:          signToken(t, jose.RS256, rsaKey, "rsa", claims(nil)),
+			expectedStatus: http.StatusOK,
+		},
+		{
+			desc:           "ES256 from JWKS",
+			token:          signToken(t, jose.ES256, ecKey, "ec", claims(nil)),
+			expectedStatus: http.StatusOK,
+		},
+		{
+			desc:           "EdDSA from static key",
+			token:          signToken(t, jose.EdDSA, edKey, "", claims(nil)),
+			expectedStatus: http.StatusOK,
+		},
+		{
+			desc:           "HS256 with secret",
+			token:          signToken(t, jose.HS256, []byte("secret"), "", claims(nil)),
+			expectedStatus: http.StatusOK,
+		},
+		{
+			desc:           "HS256 with wrong secret",
+			token:          signToken(t, jose.HS256, []byte("wrong"), "", claims(nil)),
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			desc:           "unknown key",
+			token:          signToken(t, jose.RS256, otherKey, "rsa", claims(nil)),
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			desc: "algorithm not allowed",
+			config: func(config *dynamic.JWTAuth) {
+				config.Algorithms = []string{"RS256"}
+			},
+			token:          signToken(t, jose.HS256, []byte("secret"), "", claims(nil)),
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			desc:           "expired token",
+			token:          signToken(t, jose.RS256, rsaKey, "rsa", claims(map[string]interface{}{"exp": now.Add(-time.Minute).Unix()})),
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			desc:           "missing exp claim",
+			token:          signToken(t, jose.RS256, rsaKey, "rsa", claims(map[string]interface{}{"exp": nil})),
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			desc: "missing exp claim not required",
+			config: func(config *dynamic.JWTAuth) {
+				config.RequireExp = false
+			},
+			token:          signToken(t, jose.RS256, rsaKey, "rsa", claims(map[string]interface{}{"exp": nil})),
+			expectedStatus: http.StatusOK,
+		},
+		{
+			desc: "expired token within clock skew",
+			config: func(config *dynamic.JWTAuth) {
+				config.ClockSkew = ptypes.Duration(2 * time.Minute)
+			},
+			token:          signToken(t, jose.RS256, rsaKey, "rsa", claims(map[string]interface{}{"exp": now.Add(-time.Minute).Unix()})),
+			expectedStatus: http.StatusOK,
+		},
+		{
+			desc:           "token not valid yet",
+			token:          signToken(t, jose.RS256, rsaKey, "rsa", claims(map[string]interface{}{"nbf": now.Add(time.Minute).Unix()})),
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			desc: "token not valid yet within clock skew",
+			config: func(config *dynamic.JWTAuth) {
+				config.ClockSkew = ptypes.Duration(2 * time.Minute)
+			},
+			token:          signToken(t, jose.RS256, rsaKey, "rsa", claims(map[string]interface{}{"nbf": now.Add(time.Minute).Unix()})),
+			expectedStatus: http.StatusOK,
+		},
+		{
+			desc:           "issuer not allowed",
+			token:          signToken(t, jose.RS256, rsaKey, "rsa", claims(map[string]interface{}{"iss": "https://evil.example.org"})),
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			desc:           "audience not allowed",
+			token:          signToken(t, jose.RS256, rsaKey, "rsa", claims(map[string]interface{}{"aud": "web"})),
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			desc: "required claims",
+			config: func(config *dynamic.JWTAuth) {
+				config.RequiredClaims = map[string]string{"tenant": "acme", "groups": "admin", "email": ""}
+			},
+			token: signToken(t, jose.RS256, rsaKey, "rsa", claims(map[string]interface{}{
+				"tenant": "acme",
+				"groups": []string{"dev", "admin"},
+				"email":  "alice@example.org",
+			})),
+			expectedStatus: http.StatusOK,
+		},
+		{
+			desc: "missing required claim",
+			config: func(config *dynamic.JWTAuth) {
+				config.RequiredClaims = map[string]string{"email": ""}
+			},
+			token:          signToken(t, jose.RS256, rsaKey, "rsa", claims(nil)),
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			desc: "unexpected required claim value",
+			config: func(config *dynamic.JWTAuth) {
+				config.RequiredClaims = map[string]string{"groups": "admin"}
+			},
+			token:          signToken(t, jose.RS256, rsaKey, "rsa", claims(map[string]interface{}{"groups": []string{"dev"}})),
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			desc: "required scopes",
+			config: func(config *dynamic.JWTAuth) {
+				config.RequiredScopes = []string{"read", "write"}
+			},
+			token:          signToken(t, jose.RS256, rsaKey, "rsa", claims(map[string]interface{}{"scope": "read write delete"})),
+			expectedStatus: http.StatusOK,
+		},
+		{
+			desc: "required scopes in scp claim",
+			config: func(config *dynamic.JWTAuth) {
+				config.RequiredScopes = []string{"read"}
+			},
+			token:          signToken(t, jose.RS256, rsaKey, "rsa", claims(map[string]interface{}{"scp": []string{"read"}})),
+			expectedStatus: http.StatusOK,
+		},
+		{
+			desc: "missing scope",
+			config: func(config *dynamic.JWTAuth) {
+				config.RequiredScopes = []string{"write"}
+			},
+			token:          signToken(t, jose.RS256, rsaKey, "rsa", claims(map[string]interface{}{"scope": "read"})),
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			desc: "claims headers",
+			config: func(config *dynamic.JWTAuth) {
+				config.ClaimsHeaders = map[string]string{
+					"X-User":   "sub",
+					"X-Groups": "groups",
+					"X-Org":    "org.name",
+					"X-Level":  "level",
+					"X-Forged": "missing",
+				}
+				config.RemoveHeader = true
+			},
+			token: signToken(t, jose.RS256, rsaKey, "rsa", claims(map[string]interface{}{
+				"groups": []string{"dev", "admin"},
+				"org":    map[string]interface{}{"name": "acme"},
+				"level":  3,
+			})),
+			expectedStatus: http.StatusOK,
+			expectedHeaders: map[string]string{
+				"X-User":        "alice",
+				"X-Groups":      "dev,admin",
+				"X-Org":         "acme",
+				"X-Level":       "3",
+				"X-Forged":      "",
+				"Authorization": "",
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			config := baseConfig
+			config.SetDefaults()
+			if test.config != nil {
+				test.config(&config)
+			}
+
+			var forwarded http.Header
+			next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				forwarded = req.Header.Clone()
+			})
+
+			handler, err := NewJWT(context.Background(), next, config, "authTest")
+			require.NoError(t, err)
+
+			req := httptest.NewRequest(http.MethodGet, "http://example.com", nil)
+			req.Header.Set("X-Forged", "forged")
+			if test.authorization != "" {
+				req.Header.Set(authorizationHeader, test.authorization)
+			}
+			if test.token != "" {
+				req.Header.Set(authorizationHeader, "Bearer "+test.token)
+			}
+
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, req)
+
+			assert.Equal(t, test.expectedStatus, recorder.Code)
+
+			switch test.expectedStatus {
+			case http.StatusUnauthorized:
+				assert.Contains(t, recorder.Header().Get("WWW-Authenticate"), `Bearer realm="traefik"`)
+			case http.StatusForbidden:
+				assert.Contains(t, recorder.Header().Get("WWW-Authenticate"), `error="insufficient_scope"`)
+			}
+
+			for name, value := range test.expectedHeaders {
+				assert.Equal(t, value, forwarded.Get(name), name)
+			}
+		})
+	}
+}
+
+func TestJWTAuth_keyRotation(t *testing.T) {
+	oldKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	newKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	jwks := newJWKSServer(t, jose.JSONWebKey{Key: &oldKey.PublicKey, KeyID: "old"})
+
+	handler, err := NewJWT(context.Background(), http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}), dynamic.JWTAuth{JWKSURL: jwks.URL}, "authTest")
+	require.NoError(t, err)
+
+	handler.(*jwtAuth).jwks.minInterval = 0
+
+	serve := func(token string) int {
+		req := httptest.NewRequest(http.MethodGet, "http://example.com", nil)
+		req.Header.Set(authorizationHeader, "Bearer "+token)
+
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, req)
+
+		return recorder.Code
+	}
+
+	claims := map[string]interface{}{"sub": "alice", "exp": time.Now().Add(time.Hour).Unix()}
+
+	assert.Equal(t, http.StatusOK, serve(signToken(t, jose.RS256, oldKey, "old", claims)))
+	assert.Equal(t, 1, jwks.fetches())
+
+	// The cached set is used for the known keys.
+	assert.Equal(t, http.StatusOK, serve(signToken(t, jose.RS256, oldKey, "old", claims)))
+	assert.Equal(t, 1, jwks.fetches())
+
+	// The set is fetched again when a token references an unknown key.
+	jwks.setKeys(jose.JSONWebKey{Key: &newKey.PublicKey, KeyID: "new"})
+	assert.Equal(t, http.StatusOK, serve(signToken(t, jose.RS256, newKey, "new", claims)))
+	assert.Equal(t, 2, jwks.fetches())
+
+	// The previous keys are kept when the set cannot be fetched.
+	jwks.setUnavailable()
+	assert.Equal(t, http.StatusOK, serve(signToken(t, jose.RS256, newKey, "new", claims)))
+	assert.Equal(t, http.StatusUnauthorized, serve(signToken(t, jose.RS256, oldKey, "other", claims)))
+}
+
+func TestJWTAuth_fetchNotBoundToRequest(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	jwks := newJWKSServer(t, jose.JSONWebKey{Key: &key.PublicKey, KeyID: "rsa"})
+	release := jwks.block()
+
+	handler, err := NewJWT(context.Background(), http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}), dynamic.JWTAuth{JWKSURL: jwks.URL}, "authTest")
+	require.NoError(t, err)
+
+	token := signToken(t, jose.RS256, key, "rsa", map[string]interface{}{"sub": "alice", "exp": time.Now().Add(time.Hour).Unix()})
+
+	serve := func(ctx context.Context) int {
+		req := httptest.NewRequest(http.MethodGet, "http://example.com", nil).WithContext(ctx)
+		req.Header.Set(authorizationHeader, "Bearer "+token)
+
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, req)
+
+		return recorder.Code
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	codes := make(chan int, 1)
+	go func() { codes <- serve(ctx) }()
+
+	require.Eventually(t, func() bool { return jwks.fetches() == 1 }, 5*time.Second, 10*time.Millisecond)
+
+	// The canceled request stops waiting for the fetch, which goes on.
+	cancel()
+	assert.Equal(t, http.StatusUnauthorized, <-codes)
+
+	close(release)
+
+	assert.Equal(t, http.StatusOK, serve(context.Background()))
+	assert.Equal(t, 1, jwks.fetches())
+}
+
+type jwksServer struct {
+	*httptest.Server
+
+	mu          sync.Mutex
+	keys        []jose.JSONWebKey
+	count       int
+	unavailable bool
+	blocked     chan struct{}
+}
+
+func newJWKSServer(t *testing.T, keys ...jose.JSONWebKey) *jwksServer {
+	t.Helper()
+
+	s := &jwksServer{keys: keys}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		s.mu.Lock()
+		s.count++
+		blocked := s.blocked
+		s.mu.Unlock()
+
+		if blocked != nil {
+			<-blocked
+		}
+
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		if s.unavailable {
+			rw.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		rw.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(rw).Encode(jose.JSONWebKeySet{Keys: s.keys})
+	}))
+	t.Cleanup(s.Close)
+
+	return s
+}
+
+func (s *jwksServer) setKeys(keys ...jose.JSONWebKey) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.keys = keys
+}
+
+// block blocks the responses until the returned channel is closed.
+func (s *jwksServer) block() chan struct{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.blocked = make(chan struct{})
+	return s.blocked
+}
+
+func (s *jwksServer) setUnavailable() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.unavailable = true
+}
+
+func (s *jwksServer) fetches() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.count
+}
+
+func signToken(t *testing.T, algorithm jose.SignatureAlgorithm, key interface{}, keyID string, claims map[string]interface{}) string {
+	t.Helper()
+
+	options := &jose.SignerOptions{}
+	if keyID != "" {
+		options = options.WithHeader("kid", keyID)
+	}
+
+	signer, err := jose.NewSigner(jose.SigningKey{Algorithm: algorithm, Key: key}, options.WithType("JWT"))
+	require.NoError(t, err)
+
+	token, err := jwt.Signed(signer).Claims(claims).CompactSerialize()
+	require.NoError(t, err)
+
+	return token
+}
+
+func pemPublicKey(t *testing.T, key interface{}) string {
+	t.Helper()
+
+	der, err := x509.MarshalPKIXPublicKey(key)
+	require.NoError(t, err)
+
+	return string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))
+}
+
+func jwksJSON(t *testing.T, keys ...jose.JSONWebKey) string {
+	t.Helper()
+
+	raw, err := json.Marshal(jose.JSONWebKeySet{Keys: keys})
+	require.NoError(t, err)
+
+	return string(raw)
+}
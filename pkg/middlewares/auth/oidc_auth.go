@@ -0,0 +1,865 @@
+package auth
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/opentracing/opentracing-go/ext"
+	"github.com/traefik/traefik/v2/pkg/config/dynamic"
+	"github.com/traefik/traefik/v2/pkg/log"
+	"github.com/traefik/traefik/v2/pkg/middlewares"
+	"github.com/traefik/traefik/v2/pkg/middlewares/accesslog"
+	"github.com/traefik/traefik/v2/pkg/tracing"
+	"github.com/vulcand/oxy/forward"
+	"gopkg.in/square/go-jose.v2/jwt"
+)
+
+const (
+	oidcTypeName = "OIDCAuth"
+
+	// oidcCookieChunkSize is the maximum size of the session cookie values,
+	// the larger sessions being split into several cookies to stay below the browser limits.
+	oidcCookieChunkSize = 3800
+	// oidcStateMaxAge is the maximum duration of the authorization flow.
+	oidcStateMaxAge = 10 * time.Minute
+	// oidcDiscoveryRetryInterval is the minimum interval between two attempts to discover the provider configuration.
+	oidcDiscoveryRetryInterval = 10 * time.Second
+	// oidcJWKSRefreshInterval is the interval between two fetches of the JSON Web Key Set of the provider.
+	oidcJWKSRefreshInterval = 15 * time.Minute
+	// oidcSessionSecretMinLength is the minimum length of the secret of the session cookies.
+	oidcSessionSecretMinLength = 32
+)
+
+// oidcAuth is a middleware authenticating the users with an OpenID Connect provider,
+// with the authorization code flow, and keeping their session in encrypted cookies.
+type oidcAuth struct {
+	next http.Handler
+	name string
+
+	issuerURL             string
+	clientID              string
+	clientSecret          string
+	scopes                []string
+	callbackPath          string
+	logoutPath            string
+	postLogoutRedirectURL string
+
+	sessionMaxAge  time.Duration
+	cookieName     string
+	cookieDomain   string
+	cookiePath     string
+	cookieSameSite http.SameSite
+	aead           cipher.AEAD
+
+	groupsClaim        string
+	allowedGroups      []string
+	requiredClaims     map[string]string
+	claimsHeaders      map[string]string
+	forwardAccessToken bool
+
+	client *http.Client
+
+	mu           sync.Mutex
+	provider     *oidcProvider
+	verifier     *jwtAuth
+	discoveryErr error
+	attemptedAt  time.Time
+}
+
+// oidcProvider holds the discovered configuration of the OpenID Connect provider.
+type oidcProvider struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+	EndSessionEndpoint    string `json:"end_session_endpoint"`
+}
+
+// oidcSession is the session of an authenticated user, stored in the session cookies.
+type oidcSession struct {
+	IDToken      string `json:"idToken"`
+	AccessToken  string `json:"accessToken,omitempty"`
+	RefreshToken string `json:"refreshToken,omitempty"`
+	ExpiresAt    int64  `json:"expiresAt"`
+	CreatedAt    int64  `json:"createdAt"`
+}
+
+// oidcState is the state of an authorization flow, stored in the state cookie.
+type oidcState struct {
+	State       string `json:"state"`
+	Nonce       string `json:"nonce"`
+	Verifier    string `json:"verifier"`
+	RedirectURL string `json:"redirectURL"`
+	CreatedAt   int64  `json:"createdAt"`
+}
+
+// NewOIDC creates an oidcAuth middleware.
+func NewOIDC(ctx context.Context, next http.Handler, config dynamic.OIDCAuth, name string) (http.Handler, error) {
+	log.FromContext(middlewares.GetLoggerCtx(ctx, name, oidcTypeName)).Debug("Creating middleware")
+
+	if config.IssuerURL == "" {
+		return nil, errors.New("issuer URL is required")
+	}
+
+	if config.ClientID == "" {
+		return nil, errors.New("client ID is required")
+	}
+
+	if len(config.SessionSecret) < oidcSessionSecretMinLength {
+		return nil, fmt.Errorf("session secret must be at least %d characters long", oidcSessionSecretMinLength)
+	}
+
+	if config.CookieName == "" {
+		return nil, errors.New("cookie name is required")
+	}
+
+	if !strings.HasPrefix(config.CallbackPath, "/") || !strings.HasPrefix(config.LogoutPath, "/") || config.CallbackPath == config.LogoutPath {
+		return nil, errors.New("callback and logout paths must be distinct absolute paths")
+	}
+
+	sameSite, err := parseSameSite(config.CookieSameSite)
+	if err != nil {
+		return nil, err
+	}
+
+	key := sha256.Sum256([]byte(config.SessionSecret))
+	block, err := aes.NewCipher(key[:])
+	if err != nil {
+		return nil, err
+	}
+
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	return &oidcAuth{
+		next:                  next,
+		name:                  name,
+		issuerURL:             strings.TrimSuffix(config.IssuerURL, "/"),
+		clientID:              config.ClientID,
+		clientSecret:          config.ClientSecret,
+		scopes:                config.Scopes,
+		callbackPath:          config.CallbackPath,
+		logoutPath:            config.LogoutPath,
+		postLogoutRedirectURL: config.PostLogoutRedirectURL,
+		sessionMaxAge:         time.Duration(config.SessionMaxAge),
+		cookieName:            config.CookieName,
+		cookieDomain:          config.CookieDomain,
+		cookiePath:            config.CookiePath,
+		cookieSameSite:        sameSite,
+		aead:                  aead,
+		groupsClaim:           config.GroupsClaim,
+		allowedGroups:         config.AllowedGroups,
+		requiredClaims:        config.RequiredClaims,
+		claimsHeaders:         config.ClaimsHeaders,
+		forwardAccessToken:    config.ForwardAccessToken,
+		client:                &http.Client{Timeout: 10 * time.Second},
+	}, nil
+}
+
+func (o *oidcAuth) GetTracingInformation() (string, ext.SpanKindEnum) {
+	return o.name, tracing.SpanKindNoneEnum
+}
+
+func (o *oidcAuth) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	ctx := middlewares.GetLoggerCtx(req.Context(), o.name, oidcTypeName)
+	logger := log.FromContext(ctx)
+
+	provider, verifier, err := o.discover(ctx)
+	if err != nil {
+		logger.Errorf("Unable to discover the OpenID Connect provider configuration: %v", err)
+		tracing.SetErrorWithEvent(req, "Unable to discover the OpenID Connect provider configuration")
+		rejectOIDC(ctx, rw, http.StatusServiceUnavailable)
+		return
+	}
+
+	switch req.URL.Path {
+	case o.callbackPath:
+		o.callback(ctx, rw, req, provider, verifier)
+		return
+	case o.logoutPath:
+		o.logout(rw, req, provider)
+		return
+	}
+
+	session, err := o.loadSession(req)
+	if err != nil {
+		logger.Debugf("Authentication required: %v", err)
+		o.login(ctx, rw, req, provider)
+		return
+	}
+
+	if time.Now().Unix() >= session.ExpiresAt {
+		if session.RefreshToken == "" {
+			logger.Debug("Authentication required: session expired")
+			o.login(ctx, rw, req, provider)
+			return
+		}
+
+		session, err = o.refresh(ctx, provider, verifier, session)
+		if err != nil {
+			logger.Debugf("Authentication required: unable to refresh the session: %v", err)
+			o.login(ctx, rw, req, provider)
+			return
+		}
+
+		if err = o.saveSession(rw, req, session); err != nil {
+			logger.Errorf("Unable to save the session: %v", err)
+			rejectOIDC(ctx, rw, http.StatusInternalServerError)
+			return
+		}
+	}
+
+	claims, err := session.claims()
+	if err != nil {
+		logger.Debugf("Authentication required: %v", err)
+		o.login(ctx, rw, req, provider)
+		return
+	}
+
+	if err = o.authorize(claims); err != nil {
+		logger.Debugf("Authorization failed: %v", err)
+		tracing.SetErrorWithEvent(req, "Authorization failed")
+		rejectOIDC(ctx, rw, http.StatusForbidden)
+		return
+	}
+
+	subject, _ := claims["sub"].(string)
+
+	logData := accesslog.GetLogData(req)
+	if logData != nil {
+		logData.Core[accesslog.ClientUsername] = subject
+	}
+
+	logger.Debug("Authentication succeeded")
+
+	setClaimsHeaders(req, claims, o.claimsHeaders)
+
+	if o.forwardAccessToken && session.AccessToken != "" {
+		req.Header.Set(authorizationHeader, "Bearer "+session.AccessToken)
+	}
+
+	o.removeCookies(req)
+
+	o.next.ServeHTTP(rw, req)
+}
+
+// discover returns the configuration of the provider, fetching it on the first call.
+func (o *oidcAuth) discover(ctx context.Context) (*oidcProvider, *jwtAuth, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if o.provider != nil {
+		return o.provider, o.verifier, nil
+	}
+
+	if time.Since(o.attemptedAt) < oidcDiscoveryRetryInterval {
+		return nil, nil, o.discoveryErr
+	}
+
+	o.attemptedAt = time.Now()
+
+	provider, err := o.fetchProvider(ctx)
+	if err != nil {
+		o.discoveryErr = err
+		return nil, nil, err
+	}
+
+	verifier := &jwtAuth{
+		jwks: &jwksFetcher{
+			url:             provider.JWKSURI,
+			client:          o.client,
+			refreshInterval: oidcJWKSRefreshInterval,
+			minInterval:     jwksMinRefreshInterval,
+		},
+		algorithms: make(map[string]struct{}),
+		issuers:    []string{provider.Issuer},
+		audiences:  []string{o.clientID},
+	}
+
+	for _, algorithm := range publicKeyAlgorithms {
+		verifier.algorithms[algorithm] = struct{}{}
+	}
+
+	// The ID tokens can be signed with the client secret.
+	if o.clientSecret != "" {
+		verifier.secret = []byte(o.clientSecret)
+
+		for _, algorithm := range hmacAlgorithms {
+			verifier.algorithms[algorithm] = struct{}{}
+		}
+	}
+
+	o.provider = provider
+	o.verifier = verifier
+
+	return provider, verifier, nil
+}
+
+func (o *oidcAuth) fetchProvider(ctx context.Context) (*oidcProvider, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, o.issuerURL+"/.well-known/openid-configuration", http.NoBody)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := o.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+
+	var provider oidcProvider
+	if err = json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&provider); err != nil {
+		return nil, err
+	}
+
+	if strings.TrimSuffix(provider.Issuer, "/") != o.issuerURL {
+		return nil, fmt.Errorf("issuer %q does not match the issuer URL", provider.Issuer)
+	}
+
+	if provider.AuthorizationEndpoint == "" || provider.TokenEndpoint == "" || provider.JWKSURI == "" {
+		return nil, errors.New("authorization, token and JWKS endpoints are required")
+	}
+
+	return &provider, nil
+}
+
+// login redirects the user to the authorization endpoint of the provider.
+func (o *oidcAuth) login(ctx context.Context, rw http.ResponseWriter, req *http.Request, provider *oidcProvider) {
+	// Only the navigations can be redirected to the provider.
+	if req.Method != http.MethodGet && req.Method != http.MethodHead {
+		tracing.SetErrorWithEvent(req, "Authentication required")
+		rejectOIDC(ctx, rw, http.StatusUnauthorized)
+		return
+	}
+
+	state := oidcState{
+		State:       randomString(),
+		Nonce:       randomString(),
+		Verifier:    randomString(),
+		RedirectURL: localRedirectURL(req.URL.RequestURI()),
+		CreatedAt:   time.Now().Unix(),
+	}
+
+	value, err := o.seal(state)
+	if err != nil {
+		log.FromContext(ctx).Errorf("Unable to save the authorization state: %v", err)
+		rejectOIDC(ctx, rw, http.StatusInternalServerError)
+		return
+	}
+
+	stateCookie := o.newCookie(req, o.stateCookieName(), value, int(oidcStateMaxAge.Seconds()))
+	stateCookie.Path = o.callbackPath
+	// The state cookie must be sent with the redirection of the provider.
+	stateCookie.SameSite = http.SameSiteLaxMode
+	http.SetCookie(rw, stateCookie)
+
+	challenge := sha256.Sum256([]byte(state.Verifier))
+
+	authURL, err := url.Parse(provider.AuthorizationEndpoint)
+	if err != nil {
+		log.FromContext(ctx).Errorf("Invalid authorization endpoint: %v", err)
+		rejectOIDC(ctx, rw, http.StatusInternalServerError)
+		return
+	}
+
+	query := authURL.Query()
+	query.Set("response_type", "code")
+	query.Set("client_id", o.clientID)
+	query.Set("redirect_uri", o.redirectURI(req))
+	query.Set("scope", strings.Join(o.scopes, " "))
+	query.Set("state", state.State)
+	query.Set("nonce", state.Nonce)
+	query.Set("code_challenge", base64.RawURLEncoding.EncodeToString(challenge[:]))
+	query.Set("code_challenge_method", "S256")
+	authURL.RawQuery = query.Encode()
+
+	http.Redirect(rw, req, authURL.String(), http.StatusFound)
+}
+
+// callback handles the authorization response of the provider, and creates the session of the user.
+func (o *oidcAuth) callback(ctx context.Context, rw http.ResponseWriter, req *http.Request, provider *oidcProvider, verifier *jwtAuth) {
+	logger := log.FromContext(ctx)
+
+	// The state cookie is single use.
+	http.SetCookie(rw, o.expiredCookie(req, o.stateCookieName(), o.callbackPath))
+
+	state, err := o.loadState(req)
+	if err != nil {
+		logger.Debugf("Authentication failed: %v", err)
+		tracing.SetErrorWithEvent(req, "Authentication failed")
+		rejectOIDC(ctx, rw, http.StatusUnauthorized)
+		return
+	}
+
+	query := req.URL.Query()
+
+	if errorCode := query.Get("error"); errorCode != "" {
+		logger.Debugf("Authentication failed: provider error %q: %s", errorCode, query.Get("error_description"))
+		tracing.SetErrorWithEvent(req, "Authentication failed")
+		rejectOIDC(ctx, rw, http.StatusUnauthorized)
+		return
+	}
+
+	if subtle.ConstantTimeCompare([]byte(query.Get("state")), []byte(state.State)) != 1 {
+		logger.Debug("Authentication failed: state mismatch")
+		tracing.SetErrorWithEvent(req, "Authentication failed")
+		rejectOIDC(ctx, rw, http.StatusUnauthorized)
+		return
+	}
+
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {query.Get("code")},
+		"redirect_uri":  {o.redirectURI(req)},
+		"code_verifier": {state.Verifier},
+	}
+
+	session, err := o.token(ctx, provider, verifier, form, nil, state.Nonce)
+	if err != nil {
+		logger.Debugf("Authentication failed: %v", err)
+		tracing.SetErrorWithEvent(req, "Authentication failed")
+		rejectOIDC(ctx, rw, http.StatusUnauthorized)
+		return
+	}
+
+	if err = o.saveSession(rw, req, session); err != nil {
+		logger.Errorf("Unable to save the session: %v", err)
+		rejectOIDC(ctx, rw, http.StatusInternalServerError)
+		return
+	}
+
+	http.Redirect(rw, req, localRedirectURL(state.RedirectURL), http.StatusFound)
+}
+
+// logout ends the session of the user, and redirects it to the end session endpoint of the provider, if any.
+func (o *oidcAuth) logout(rw http.ResponseWriter, req *http.Request, provider *oidcProvider) {
+	session, _ := o.loadSession(req)
+
+	for _, name := range o.sessionCookieNames(req) {
+		http.SetCookie(rw, o.expiredCookie(req, name, o.cookiePath))
+	}
+
+	if provider.EndSessionEndpoint == "" {
+		target := o.postLogoutRedirectURL
+		if target == "" {
+			target = "/"
+		}
+
+		http.Redirect(rw, req, target, http.StatusFound)
+		return
+	}
+
+	logoutURL, err := url.Parse(provider.EndSessionEndpoint)
+	if err != nil {
+		rejectOIDC(req.Context(), rw, http.StatusInternalServerError)
+		return
+	}
+
+	query := logoutURL.Query()
+	query.Set("client_id", o.clientID)
+	if session != nil {
+		query.Set("id_token_hint", session.IDToken)
+	}
+	if o.postLogoutRedirectURL != "" {
+		query.Set("post_logout_redirect_uri", o.postLogoutRedirectURL)
+	}
+	logoutURL.RawQuery = query.Encode()
+
+	http.Redirect(rw, req, logoutURL.String(), http.StatusFound)
+}
+
+// refresh renews the tokens of the session with its refresh token.
+func (o *oidcAuth) refresh(ctx context.Context, provider *oidcProvider, verifier *jwtAuth, session *oidcSession) (*oidcSession, error) {
+	form := url.Values{
+		"grant_type":    {"refresh_token"},
+		"refresh_token": {session.RefreshToken},
+	}
+
+	return o.token(ctx, provider, verifier, form, session, "")
+}
+
+// token requests tokens to the token endpoint, and returns the resulting session.
+// The tokens missing from the response are kept from the previous session, if any.
+func (o *oidcAuth) token(ctx context.Context, provider *oidcProvider, verifier *jwtAuth, form url.Values, previous *oidcSession, nonce string) (*oidcSession, error) {
+	form.Set("client_id", o.clientID)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, provider.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	if o.clientSecret != "" {
+		req.SetBasicAuth(url.QueryEscape(o.clientID), url.QueryEscape(o.clientSecret))
+	}
+
+	resp, err := o.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code %d from the token endpoint", resp.StatusCode)
+	}
+
+	var tokens struct {
+		AccessToken  string `json:"access_token"`
+		IDToken      string `json:"id_token"`
+		RefreshToken string `json:"refresh_token"`
+		ExpiresIn    int64  `json:"expires_in"`
+	}
+
+	if err = json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&tokens); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+
+	session := &oidcSession{CreatedAt: now.Unix()}
+	if previous != nil {
+		*session = *previous
+	}
+
+	if tokens.IDToken == "" && previous == nil {
+		return nil, errors.New("no ID token in the token response")
+	}
+
+	if tokens.IDToken != "" {
+		claims, err := verifier.verify(ctx, tokens.IDToken)
+		if err != nil {
+			return nil, fmt.Errorf("invalid ID token: %w", err)
+		}
+
+		if nonce != "" {
+			value, _ := claims["nonce"].(string)
+			if subtle.ConstantTimeCompare([]byte(value), []byte(nonce)) != 1 {
+				return nil, errors.New("invalid ID token: nonce mismatch")
+			}
+		}
+
+		session.IDToken = tokens.IDToken
+
+		if exp, ok := claims["exp"].(float64); ok {
+			session.ExpiresAt = int64(exp)
+		}
+	}
+
+	session.AccessToken = tokens.AccessToken
+
+	if tokens.RefreshToken != "" {
+		session.RefreshToken = tokens.RefreshToken
+	}
+
+	if tokens.ExpiresIn > 0 {
+		session.ExpiresAt = now.Unix() + tokens.ExpiresIn
+	}
+
+	return session, nil
+}
+
+func (o *oidcAuth) authorize(claims map[string]interface{}) error {
+	if len(o.allowedGroups) > 0 {
+		groups := lookupClaim(claims, o.groupsClaim)
+
+		allowed := false
+		for _, group := range o.allowedGroups {
+			if groups != nil && claimContains(groups, group) {
+				allowed = true
+				break
+			}
+		}
+
+		if !allowed {
+			return errors.New("not a member of the allowed groups")
+		}
+	}
+
+	return checkRequiredClaims(claims, o.requiredClaims)
+}
+
+// loadSession returns the session of the request cookies.
+func (o *oidcAuth) loadSession(req *http.Request) (*oidcSession, error) {
+	var value string
+	if cookie, err := req.Cookie(o.cookieName); err == nil {
+		value = cookie.Value
+	} else {
+		var chunks strings.Builder
+		for i := 0; ; i++ {
+			cookie, err := req.Cookie(o.cookieName + "_" + strconv.Itoa(i))
+			if err != nil {
+				break
+			}
+
+			chunks.WriteString(cookie.Value)
+		}
+
+		value = chunks.String()
+	}
+
+	if value == "" {
+		return nil, errors.New("no session")
+	}
+
+	var session oidcSession
+	if err := o.open(value, &session); err != nil {
+		return nil, fmt.Errorf("invalid session: %w", err)
+	}
+
+	if o.sessionMaxAge > 0 && time.Since(time.Unix(session.CreatedAt, 0)) > o.sessionMaxAge {
+		return nil, errors.New("session expired")
+	}
+
+	return &session, nil
+}
+
+// saveSession sets the session cookies, splitting the session into several cookies if needed.
+func (o *oidcAuth) saveSession(rw http.ResponseWriter, req *http.Request, session *oidcSession) error {
+	value, err := o.seal(session)
+	if err != nil {
+		return err
+	}
+
+	maxAge := int((o.sessionMaxAge - time.Since(time.Unix(session.CreatedAt, 0))).Seconds())
+	if o.sessionMaxAge <= 0 {
+		maxAge = 0
+	}
+
+	written := make(map[string]struct{})
+
+	if len(value) <= oidcCookieChunkSize {
+		http.SetCookie(rw, o.newCookie(req, o.cookieName, value, maxAge))
+		written[o.cookieName] = struct{}{}
+	} else {
+		for i := 0; len(value) > 0; i++ {
+			size := oidcCookieChunkSize
+			if len(value) < size {
+				size = len(value)
+			}
+
+			name := o.cookieName + "_" + strconv.Itoa(i)
+			http.SetCookie(rw, o.newCookie(req, name, value[:size], maxAge))
+			written[name] = struct{}{}
+
+			value = value[size:]
+		}
+	}
+
+	// Removes the chunks of the previous session which are not used anymore.
+	for _, name := range o.sessionCookieNames(req) {
+		if _, ok := written[name]; !ok {
+			http.SetCookie(rw, o.expiredCookie(req, name, o.cookiePath))
+		}
+	}
+
+	return nil
+}
+
+func (o *oidcAuth) loadState(req *http.Request) (*oidcState, error) {
+	cookie, err := req.Cookie(o.stateCookieName())
+	if err != nil {
+		return nil, errors.New("no authorization state")
+	}
+
+	var state oidcState
+	if err = o.open(cookie.Value, &state); err != nil {
+		return nil, fmt.Errorf("invalid authorization state: %w", err)
+	}
+
+	if time.Since(time.Unix(state.CreatedAt, 0)) > oidcStateMaxAge {
+		return nil, errors.New("authorization state expired")
+	}
+
+	return &state, nil
+}
+
+// sessionCookieNames returns the names of the session cookies of the request.
+func (o *oidcAuth) sessionCookieNames(req *http.Request) []string {
+	var names []string
+	for _, cookie := range req.Cookies() {
+		if o.isSessionCookie(cookie.Name) {
+			names = append(names, cookie.Name)
+		}
+	}
+
+	return names
+}
+
+func (o *oidcAuth) isSessionCookie(name string) bool {
+	if name == o.cookieName {
+		return true
+	}
+
+	suffix := strings.TrimPrefix(name, o.cookieName+"_")
+	if suffix == name {
+		return false
+	}
+
+	_, err := strconv.Atoi(suffix)
+
+	return err == nil
+}
+
+// removeCookies removes the cookies of the middleware from the request, so that the tokens are not forwarded.
+func (o *oidcAuth) removeCookies(req *http.Request) {
+	cookies := req.Cookies()
+	req.Header.Del("Cookie")
+
+	for _, cookie := range cookies {
+		if o.isSessionCookie(cookie.Name) || cookie.Name == o.stateCookieName() {
+			continue
+		}
+
+		req.AddCookie(cookie)
+	}
+}
+
+func (o *oidcAuth) stateCookieName() string {
+	return o.cookieName + "_state"
+}
+
+func (o *oidcAuth) newCookie(req *http.Request, name, value string, maxAge int) *http.Cookie {
+	return &http.Cookie{
+		Name:     name,
+		Value:    value,
+		Path:     o.cookiePath,
+		Domain:   o.cookieDomain,
+		MaxAge:   maxAge,
+		Secure:   isSecure(req),
+		HttpOnly: true,
+		SameSite: o.cookieSameSite,
+	}
+}
+
+func (o *oidcAuth) expiredCookie(req *http.Request, name, path string) *http.Cookie {
+	cookie := o.newCookie(req, name, "", -1)
+	cookie.Path = path
+
+	return cookie
+}
+
+func (o *oidcAuth) redirectURI(req *http.Request) string {
+	scheme := "http"
+	if isSecure(req) {
+		scheme = "https"
+	}
+
+	return scheme + "://" + req.Host + o.callbackPath
+}
+
+// seal encrypts and authenticates the JSON encoding of the value.
+func (o *oidcAuth) seal(value interface{}) (string, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return "", err
+	}
+
+	nonce := make([]byte, o.aead.NonceSize())
+	if _, err = rand.Read(nonce); err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(o.aead.Seal(nonce, nonce, data, []byte(o.cookieName))), nil
+}
+
+// open decrypts and authenticates the value sealed with seal.
+func (o *oidcAuth) open(sealed string, value interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(sealed)
+	if err != nil {
+		return err
+	}
+
+	if len(data) < o.aead.NonceSize() {
+		return errors.New("value too short")
+	}
+
+	nonce, ciphertext := data[:o.aead.NonceSize()], data[o.aead.NonceSize():]
+
+	plaintext, err := o.aead.Open(nil, nonce, ciphertext, []byte(o.cookieName))
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(plaintext, value)
+}
+
+// claims returns the claims of the ID token of the session, which was verified when the session was created.
+func (s *oidcSession) claims() (map[string]interface{}, error) {
+	token, err := jwt.ParseSigned(s.IDToken)
+	if err != nil {
+		return nil, err
+	}
+
+	var claims map[string]interface{}
+	if err = token.UnsafeClaimsWithoutVerification(&claims); err != nil {
+		return nil, err
+	}
+
+	return claims, nil
+}
+
+// localRedirectURL returns the given URL if it is local to the host of the request, and the root path otherwise.
+func localRedirectURL(target string) string {
+	// The URLs starting with // or /\ are not local, as the browsers redirect to their host.
+	if !strings.HasPrefix(target, "/") || strings.HasPrefix(target, "//") || strings.HasPrefix(target, "/\\") {
+		return "/"
+	}
+
+	return target
+}
+
+func parseSameSite(value string) (http.SameSite, error) {
+	switch strings.ToLower(value) {
+	case "", "lax":
+		return http.SameSiteLaxMode, nil
+	case "strict":
+		return http.SameSiteStrictMode, nil
+	case "none":
+		return http.SameSiteNoneMode, nil
+	default:
+		return 0, fmt.Errorf("invalid cookie SameSite value %q", value)
+	}
+}
+
+func isSecure(req *http.Request) bool {
+	return req.TLS != nil || req.Header.Get(forward.XForwardedProto) == "https"
+}
+
+func randomString() string {
+	data := make([]byte, 32)
+	_, _ = rand.Read(data)
+
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func rejectOIDC(ctx context.Context, rw http.ResponseWriter, statusCode int) {
+	rw.WriteHeader(statusCode)
+
+	_, err := rw.Write([]byte(http.StatusText(statusCode)))
+	if err != nil {
+		log.FromContext(ctx).Error(err)
+	}
+}
@@ -0,0 +1,720 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	ptypes "github.com/traefik/paerser/types"
+	"github.com/traefik/traefik/v2/pkg/config/dynamic"
+	"gopkg.in/square/go-jose.v2"
+)
+
+func TestNewOIDC(t *testing.T) {
+	testCases := []struct {
+		desc          string
+		config        func(config *dynamic.OIDCAuth)
+		expectedError bool
+	}{
+		{
+			desc: "valid configuration",
+		},
+		{
+			desc: "missing issuer URL",
+			config: func(config *dynamic.OIDCAuth) {
+				config.IssuerURL = ""
+			},
+			expectedError: true,
+		},
+		{
+			desc: "missing client ID",
+			config: func(config *dynamic.OIDCAuth) {
+				config.ClientID = ""
+			},
+			expectedError: true,
+		},
+		{
+			desc: "short session secret",
+			config: func(config *dynamic.OIDCAuth) {
+				config.SessionSecret = "secret"
+			},
+			expectedError: true,
+		},
+		{
+			desc: "same callback and logout paths",
+			config: func(config *dynamic.OIDCAuth) {
+				config.LogoutPath = config.CallbackPath
+			},
+			expectedError: true,
+		},
+		{
+			desc: "invalid SameSite",
+			config: func(config *dynamic.OIDCAuth) {
+				config.CookieSameSite = "foo"
+			},
+			expectedError: true,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			config := dynamic.OIDCAuth{
+				IssuerURL:     "https://idp.example.org",
+				ClientID:      "app",
+				SessionSecret: strings.Repeat("s", 32),
+			}
+			config.SetDefaults()
+
+			if test.config != nil {
+				test.config(&config)
+			}
+
+			next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {})
+
+			_, err := NewOIDC(context.Background(), next, config, "authTest")
+			if test.expectedError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestOIDCAuth_flow(t *testing.T) {
+	provider := newOIDCProvider(t, map[string]interface{}{
+		"sub":    "alice",
+		"email":  "alice@example.org",
+		"groups": []string{"dev", "admin"},
+	})
+
+	handler, upstream := newTestOIDC(t, provider, func(config *dynamic.OIDCAuth) {
+		config.ClaimsHeaders = map[string]string{"X-User": "sub", "X-Email": "email"}
+		config.ForwardAccessToken = true
+	})
+
+	// An unauthenticated navigation is redirected to the provider.
+	recorder := serveOIDC(handler, http.MethodGet, "http://app.example.com/private?foo=bar", nil)
+	require.Equal(t, http.StatusFound, recorder.Code)
+	assert.False(t, upstream.called())
+
+	authURL, err := url.Parse(recorder.Header().Get("Location"))
+	require.NoError(t, err)
+	assert.Equal(t, provider.URL+"/authorize", authURL.Scheme+"://"+authURL.Host+authURL.Path)
+
+	query := authURL.Query()
+	assert.Equal(t, "code", query.Get("response_type"))
+	assert.Equal(t, "app", query.Get("client_id"))
+	assert.Equal(t, "http://app.example.com/oauth2/callback", query.Get("redirect_uri"))
+	assert.Equal(t, "openid profile email", query.Get("scope"))
+	assert.Equal(t, "S256", query.Get("code_challenge_method"))
+
+	stateCookies := recorder.Result().Cookies()
+	require.Len(t, stateCookies, 1)
+	assert.Equal(t, "/oauth2/callback", stateCookies[0].Path)
+
+	// The provider redirects the user to the callback.
+	callbackURL := provider.authorize(t, authURL)
+
+	recorder = serveOIDC(handler, http.MethodGet, callbackURL, stateCookies)
+	require.Equal(t, http.StatusFound, recorder.Code)
+	assert.Equal(t, "/private?foo=bar", recorder.Header().Get("Location"))
+
+	sessionCookies := validCookies(recorder.Result().Cookies())
+	require.Len(t, sessionCookies, 1)
+	assert.Equal(t, "_traefik_oidc", sessionCookies[0].Name)
+	assert.True(t, sessionCookies[0].HttpOnly)
+
+	// The authenticated requests are forwarded with the identity headers.
+	otherCookie := &http.Cookie{Name: "other", Value: "value"}
+
+	req := httptest.NewRequest(http.MethodPost, "http://app.example.com/private", nil)
+	req.Header.Set("X-User", "forged")
+	req.AddCookie(sessionCookies[0])
+	req.AddCookie(otherCookie)
+
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, req)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	headers := upstream.lastHeaders()
+	assert.Equal(t, "alice", headers.Get("X-User"))
+	assert.Equal(t, "alice@example.org", headers.Get("X-Email"))
+	assert.Equal(t, "Bearer access-1", headers.Get("Authorization"))
+	assert.Equal(t, "other=value", headers.Get("Cookie"))
+
+	// The logout ends the session at the provider.
+	recorder = serveOIDC(handler, http.MethodGet, "http://app.example.com/oauth2/logout", sessionCookies)
+	require.Equal(t, http.StatusFound, recorder.Code)
+
+	logoutURL, err := url.Parse(recorder.Header().Get("Location"))
+	require.NoError(t, err)
+	assert.Equal(t, "/logout", logoutURL.Path)
+	assert.NotEmpty(t, logoutURL.Query().Get("id_token_hint"))
+	assert.Equal(t, "https://app.example.com/", logoutURL.Query().Get("post_logout_redirect_uri"))
+
+	cleared := recorder.Result().Cookies()
+	require.Len(t, cleared, 1)
+	assert.Equal(t, "_traefik_oidc", cleared[0].Name)
+	assert.Equal(t, -1, cleared[0].MaxAge)
+}
+
+func TestOIDCAuth_ServeHTTP(t *testing.T) {
+	provider := newOIDCProvider(t, map[string]interface{}{
+		"sub":    "alice",
+		"groups": []string{"dev"},
+	})
+
+	testCases := []struct {
+		desc           string
+		config         func(config *dynamic.OIDCAuth)
+		method         string
+		target         string
+		cookies        func(t *testing.T, handler *oidcAuth) []*http.Cookie
+		expectedStatus int
+	}{
+		{
+			desc:           "unauthenticated navigation",
+			method:         http.MethodGet,
+			expectedStatus: http.StatusFound,
+		},
+		{
+			desc:           "unauthenticated API call",
+			method:         http.MethodPost,
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			desc:   "tampered session",
+			method: http.MethodGet,
+			cookies: func(t *testing.T, handler *oidcAuth) []*http.Cookie {
+				return []*http.Cookie{{Name: "_traefik_oidc", Value: "tampered"}}
+			},
+			expectedStatus: http.StatusFound,
+		},
+		{
+			desc:   "session of another secret",
+			method: http.MethodGet,
+			cookies: func(t *testing.T, handler *oidcAuth) []*http.Cookie {
+				config := dynamic.OIDCAuth{IssuerURL: provider.URL, ClientID: "app", SessionSecret: strings.Repeat("o", 32)}
+				config.SetDefaults()
+
+				other, err := NewOIDC(context.Background(), nil, config, "other")
+				require.NoError(t, err)
+
+				return sessionCookies(t, other.(*oidcAuth), provider.session(t, time.Hour, ""))
+			},
+			expectedStatus: http.StatusFound,
+		},
+		{
+			desc:   "valid session",
+			method: http.MethodGet,
+			cookies: func(t *testing.T, handler *oidcAuth) []*http.Cookie {
+				return sessionCookies(t, handler, provider.session(t, time.Hour, ""))
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			desc:   "expired session without refresh token",
+			method: http.MethodGet,
+			cookies: func(t *testing.T, handler *oidcAuth) []*http.Cookie {
+				return sessionCookies(t, handler, provider.session(t, -time.Minute, ""))
+			},
+			expectedStatus: http.StatusFound,
+		},
+		{
+			desc:   "session older than the maximum age",
+			method: http.MethodGet,
+			config: func(config *dynamic.OIDCAuth) {
+				config.SessionMaxAge = ptypes.Duration(time.Minute)
+			},
+			cookies: func(t *testing.T, handler *oidcAuth) []*http.Cookie {
+				session := provider.session(t, time.Hour, "")
+				session.CreatedAt = time.Now().Add(-time.Hour).Unix()
+
+				value, err := handler.seal(session)
+				require.NoError(t, err)
+
+				return []*http.Cookie{{Name: "_traefik_oidc", Value: value}}
+			},
+			expectedStatus: http.StatusFound,
+		},
+		{
+			desc: "member of the allowed groups",
+			config: func(config *dynamic.OIDCAuth) {
+				config.AllowedGroups = []string{"admin", "dev"}
+			},
+			method: http.MethodGet,
+			cookies: func(t *testing.T, handler *oidcAuth) []*http.Cookie {
+				return sessionCookies(t, handler, provider.session(t, time.Hour, ""))
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			desc: "not a member of the allowed groups",
+			config: func(config *dynamic.OIDCAuth) {
+				config.AllowedGroups = []string{"admin"}
+			},
+			method: http.MethodGet,
+			cookies: func(t *testing.T, handler *oidcAuth) []*http.Cookie {
+				return sessionCookies(t, handler, provider.session(t, time.Hour, ""))
+			},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			desc: "missing required claim",
+			config: func(config *dynamic.OIDCAuth) {
+				config.RequiredClaims = map[string]string{"email_verified": "true"}
+			},
+			method: http.MethodGet,
+			cookies: func(t *testing.T, handler *oidcAuth) []*http.Cookie {
+				return sessionCookies(t, handler, provider.session(t, time.Hour, ""))
+			},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			desc:           "callback without state",
+			method:         http.MethodGet,
+			target:         "http://app.example.com/oauth2/callback?code=foo&state=bar",
+			expectedStatus: http.StatusUnauthorized,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			handler, _ := newTestOIDC(t, provider, test.config)
+
+			var cookies []*http.Cookie
+			if test.cookies != nil {
+				cookies = test.cookies(t, handler)
+			}
+
+			target := "http://app.example.com/private"
+			if test.target != "" {
+				target = test.target
+			}
+
+			recorder := serveOIDC(handler, test.method, target, cookies)
+			assert.Equal(t, test.expectedStatus, recorder.Code)
+		})
+	}
+}
+
+func TestOIDCAuth_callback(t *testing.T) {
+	provider := newOIDCProvider(t, map[string]interface{}{"sub": "alice"})
+
+	handler, _ := newTestOIDC(t, provider, nil)
+
+	login := func() (*url.URL, []*http.Cookie) {
+		recorder := serveOIDC(handler, http.MethodGet, "http://app.example.com/", nil)
+		require.Equal(t, http.StatusFound, recorder.Code)
+
+		authURL, err := url.Parse(recorder.Header().Get("Location"))
+		require.NoError(t, err)
+
+		return authURL, recorder.Result().Cookies()
+	}
+
+	// The state must match the state cookie.
+	authURL, cookies := login()
+	callbackURL, err := url.Parse(provider.authorize(t, authURL))
+	require.NoError(t, err)
+
+	query := callbackURL.Query()
+	query.Set("state", "forged")
+	callbackURL.RawQuery = query.Encode()
+
+	recorder := serveOIDC(handler, http.MethodGet, callbackURL.String(), cookies)
+	assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+
+	// The code verifier must match the code challenge.
+	authURL, _ = login()
+	_, otherCookies := login()
+
+	recorder = serveOIDC(handler, http.MethodGet, provider.authorize(t, authURL), otherCookies)
+	assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+
+	// The errors of the provider are refused.
+	_, cookies = login()
+
+	recorder = serveOIDC(handler, http.MethodGet, "http://app.example.com/oauth2/callback?error=access_denied", cookies)
+	assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+}
+
+func TestOIDCAuth_callbackRedirectNotLocal(t *testing.T) {
+	provider := newOIDCProvider(t, map[string]interface{}{"sub": "alice"})
+
+	handler, _ := newTestOIDC(t, provider, nil)
+
+	recorder := serveOIDC(handler, http.MethodGet, "http://app.example.com//evil.example.com/path", nil)
+	require.Equal(t, http.StatusFound, recorder.Code)
+
+	authURL, err := url.Parse(recorder.Header().Get("Location"))
+	require.NoError(t, err)
+
+	recorder = serveOIDC(handler, http.MethodGet, provider.authorize(t, authURL), recorder.Result().Cookies())
+	require.Equal(t, http.StatusFound, recorder.Code)
+	assert.Equal(t, "/", recorder.Header().Get("Location"))
+}
+
+func TestLocalRedirectURL(t *testing.T) {
+	testCases := []struct {
+		target   string
+		expected string
+	}{
+		{target: "/private?foo=bar", expected: "/private?foo=bar"},
+		{target: "/", expected: "/"},
+		{target: "", expected: "/"},
+		{target: "//evil.example.com/path", expected: "/"},
+		{target: "/\\evil.example.com/path", expected: "/"},
+		{target: "https://evil.example.com/path", expected: "/"},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.target, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, test.expected, localRedirectURL(test.target))
+		})
+	}
+}
+
+func TestOIDCAuth_refresh(t *testing.T) {
+	provider := newOIDCProvider(t, map[string]interface{}{"sub": "alice"})
+
+	handler, upstream := newTestOIDC(t, provider, func(config *dynamic.OIDCAuth) {
+		config.ForwardAccessToken = true
+	})
+
+	session := provider.session(t, -time.Minute, "refresh-1")
+
+	recorder := serveOIDC(handler, http.MethodGet, "http://app.example.com/private", sessionCookies(t, handler, session))
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	assert.Equal(t, 1, provider.refreshes())
+	assert.Equal(t, "Bearer refreshed-access-1", upstream.lastHeaders().Get("Authorization"))
+
+	// The renewed session is saved.
+	renewed, err := handler.loadSession(cookieRequest(validCookies(recorder.Result().Cookies())))
+	require.NoError(t, err)
+	assert.Equal(t, "refreshed-access-1", renewed.AccessToken)
+	assert.Equal(t, "refresh-1", renewed.RefreshToken)
+	assert.Equal(t, session.CreatedAt, renewed.CreatedAt)
+	assert.Greater(t, renewed.ExpiresAt, time.Now().Unix())
+
+	// A revoked refresh token requires a new login.
+	session = provider.session(t, -time.Minute, "revoked")
+
+	recorder = serveOIDC(handler, http.MethodGet, "http://app.example.com/private", sessionCookies(t, handler, session))
+	assert.Equal(t, http.StatusFound, recorder.Code)
+}
+
+func TestOIDCAuth_chunkedSession(t *testing.T) {
+	provider := newOIDCProvider(t, map[string]interface{}{
+		"sub":  "alice",
+		"blob": strings.Repeat("x", 3*oidcCookieChunkSize),
+	})
+
+	handler, _ := newTestOIDC(t, provider, nil)
+
+	cookies := sessionCookies(t, handler, provider.session(t, time.Hour, ""))
+	require.Greater(t, len(cookies), 1)
+
+	for i, cookie := range cookies {
+		assert.Equal(t, "_traefik_oidc_"+strconv.Itoa(i), cookie.Name)
+		assert.LessOrEqual(t, len(cookie.Value), oidcCookieChunkSize)
+	}
+
+	recorder := serveOIDC(handler, http.MethodGet, "http://app.example.com/private", cookies)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+
+	// The chunks are removed when the session becomes smaller.
+	recorder = httptest.NewRecorder()
+	small := provider.session(t, time.Hour, "")
+	small.IDToken = provider.sign(map[string]interface{}{"sub": "alice"}, time.Hour, "")
+	require.NoError(t, handler.saveSession(recorder, cookieRequest(cookies), small))
+
+	written := recorder.Result().Cookies()
+	require.Len(t, written, len(cookies)+1)
+	assert.Equal(t, "_traefik_oidc", written[0].Name)
+
+	for _, cookie := range written[1:] {
+		assert.Equal(t, -1, cookie.MaxAge, cookie.Name)
+	}
+}
+
+type oidcUpstream struct {
+	mu      sync.Mutex
+	headers http.Header
+}
+
+func (u *oidcUpstream) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	u.headers = req.Header.Clone()
+}
+
+func (u *oidcUpstream) called() bool {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	return u.headers != nil
+}
+
+func (u *oidcUpstream) lastHeaders() http.Header {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	return u.headers
+}
+
+func newTestOIDC(t *testing.T, provider *oidcTestProvider, modify func(config *dynamic.OIDCAuth)) (*oidcAuth, *oidcUpstream) {
+	t.Helper()
+
+	config := dynamic.OIDCAuth{
+		IssuerURL:             provider.URL,
+		ClientID:              "app",
+		ClientSecret:          "client-secret",
+		SessionSecret:         strings.Repeat("s", 32),
+		PostLogoutRedirectURL: "https://app.example.com/",
+	}
+	config.SetDefaults()
+
+	if modify != nil {
+		modify(&config)
+	}
+
+	upstream := &oidcUpstream{}
+
+	handler, err := NewOIDC(context.Background(), upstream, config, "authTest")
+	require.NoError(t, err)
+
+	return handler.(*oidcAuth), upstream
+}
+
+func serveOIDC(handler http.Handler, method, target string, cookies []*http.Cookie) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, nil)
+	for _, cookie := range cookies {
+		req.AddCookie(cookie)
+	}
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, req)
+
+	return recorder
+}
+
+func sessionCookies(t *testing.T, handler *oidcAuth, session *oidcSession) []*http.Cookie {
+	t.Helper()
+
+	recorder := httptest.NewRecorder()
+	require.NoError(t, handler.saveSession(recorder, httptest.NewRequest(http.MethodGet, "http://app.example.com", nil), session))
+
+	return validCookies(recorder.Result().Cookies())
+}
+
+func validCookies(cookies []*http.Cookie) []*http.Cookie {
+	var valid []*http.Cookie
+	for _, cookie := range cookies {
+		if cookie.MaxAge >= 0 {
+			valid = append(valid, cookie)
+		}
+	}
+
+	return valid
+}
+
+func cookieRequest(cookies []*http.Cookie) *http.Request {
+	req := httptest.NewRequest(http.MethodGet, "http://app.example.com", nil)
+	for _, cookie := range cookies {
+		req.AddCookie(cookie)
+	}
+
+	return req
+}
+
+// oidcTestProvider is a mock OpenID Connect provider.
+type oidcTestProvider struct {
+	*httptest.Server
+
+	key    *rsa.PrivateKey
+	claims map[string]interface{}
+
+	mu           sync.Mutex
+	codes        map[string]oidcTestCode
+	refreshCount int
+}
+
+type oidcTestCode struct {
+	nonce     string
+	challenge string
+}
+
+func newOIDCProvider(t *testing.T, claims map[string]interface{}) *oidcTestProvider {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	p := &oidcTestProvider{
+		key:    key,
+		claims: claims,
+		codes:  make(map[string]oidcTestCode),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(rw http.ResponseWriter, req *http.Request) {
+		_ = json.NewEncoder(rw).Encode(oidcProvider{
+			Issuer:                p.URL,
+			AuthorizationEndpoint: p.URL + "/authorize",
+			TokenEndpoint:         p.URL + "/token",
+			JWKSURI:               p.URL + "/jwks",
+			EndSessionEndpoint:    p.URL + "/logout",
+		})
+	})
+	mux.HandleFunc("/jwks", func(rw http.ResponseWriter, req *http.Request) {
+		_ = json.NewEncoder(rw).Encode(jose.JSONWebKeySet{Keys: []jose.JSONWebKey{{Key: &key.PublicKey, KeyID: "1", Use: "sig"}}})
+	})
+	mux.HandleFunc("/token", p.token)
+
+	p.Server = httptest.NewServer(mux)
+	t.Cleanup(p.Close)
+
+	return p
+}
+
+// authorize simulates the authentication of the user, and returns the callback URL.
+func (p *oidcTestProvider) authorize(t *testing.T, authURL *url.URL) string {
+	t.Helper()
+
+	query := authURL.Query()
+	code := randomString()
+
+	p.mu.Lock()
+	p.codes[code] = oidcTestCode{nonce: query.Get("nonce"), challenge: query.Get("code_challenge")}
+	p.mu.Unlock()
+
+	return query.Get("redirect_uri") + "?" + url.Values{"code": {code}, "state": {query.Get("state")}}.Encode()
+}
+
+func (p *oidcTestProvider) token(rw http.ResponseWriter, req *http.Request) {
+	clientID, clientSecret, ok := req.BasicAuth()
+	if !ok || clientID != "app" || clientSecret != "client-secret" {
+		rw.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	response := map[string]interface{}{"expires_in": 3600}
+
+	switch req.FormValue("grant_type") {
+	case "authorization_code":
+		code, ok := p.codes[req.FormValue("code")]
+		delete(p.codes, req.FormValue("code"))
+
+		challenge := sha256.Sum256([]byte(req.FormValue("code_verifier")))
+		if !ok || code.challenge != base64.RawURLEncoding.EncodeToString(challenge[:]) {
+			rw.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		response["id_token"] = p.sign(p.claims, time.Hour, code.nonce)
+		response["access_token"] = "access-1"
+		response["refresh_token"] = "refresh-1"
+
+	case "refresh_token":
+		if req.FormValue("refresh_token") != "refresh-1" {
+			rw.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		p.refreshCount++
+		response["access_token"] = "refreshed-access-1"
+
+	default:
+		rw.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	rw.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(rw).Encode(response)
+}
+
+func (p *oidcTestProvider) refreshes() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.refreshCount
+}
+
+// session returns a session of the user, which expires after the given duration.
+func (p *oidcTestProvider) session(t *testing.T, expiresIn time.Duration, refreshToken string) *oidcSession {
+	t.Helper()
+
+	return &oidcSession{
+		IDToken:      p.sign(p.claims, time.Hour, ""),
+		AccessToken:  "access-1",
+		RefreshToken: refreshToken,
+		ExpiresAt:    time.Now().Add(expiresIn).Unix(),
+		CreatedAt:    time.Now().Unix(),
+	}
+}
+
+func (p *oidcTestProvider) sign(claims map[string]interface{}, expiresIn time.Duration, nonce string) string {
+	token := map[string]interface{}{
+		"iss": p.URL,
+		"aud": "app",
+		"iat": time.Now().Unix(),
+		"exp": time.Now().Add(expiresIn).Unix(),
+	}
+	for k, v := range claims {
+		token[k] = v
+	}
+	if nonce != "" {
+		token["nonce"] = nonce
+	}
+
+	signer, err := jose.NewSigner(jose.SigningKey{Algorithm: jose.RS256, Key: p.key}, (&jose.SignerOptions{}).WithHeader("kid", "1"))
+	if err != nil {
+		panic(err)
+	}
+
+	payload, err := json.Marshal(token)
+	if err != nil {
+		panic(err)
+	}
+
+	signed, err := signer.Sign(payload)
+	if err != nil {
+		panic(err)
+	}
+
+	serialized, err := signed.CompactSerialize()
+	if err != nil {
+		panic(err)
+	}
+
+	return serialized
+}
@@ -0,0 +1,240 @@
+package auth
+
+import (
+	"context"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/opentracing/opentracing-go/ext"
+	"github.com/traefik/traefik/v2/pkg/config/dynamic"
+	"github.com/traefik/traefik/v2/pkg/log"
+	"github.com/traefik/traefik/v2/pkg/middlewares"
+	"github.com/traefik/traefik/v2/pkg/tracing"
+)
+
+const (
+	tlsClientCertTypeName = "TLSClientCertAuth"
+)
+
+// tlsClientCertAuth is a middleware that authorizes the requests based on their verified TLS client certificate.
+type tlsClientCertAuth struct {
+	next  http.Handler
+	rules []certRule
+	name  string
+}
+
+// certRule is a compiled TLS client certificate authorization rule.
+type certRule struct {
+	subject *dnMatcher
+	issuer  *dnMatcher
+	sanURIs patterns
+}
+
+func (r certRule) match(cert *x509.Certificate) bool {
+	if r.subject != nil && !r.subject.match(cert.Subject) {
+		return false
+	}
+
+	if r.issuer != nil && !r.issuer.match(cert.Issuer) {
+		return false
+	}
+
+	if len(r.sanURIs) > 0 {
+		uris := make([]string, 0, len(cert.URIs))
+		for _, uri := range cert.URIs {
+			uris = append(uris, uri.String())
+		}
+
+		if !r.sanURIs.matchAny(uris) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// dnMatcher matches a distinguished name.
+type dnMatcher struct {
+	commonName         patterns
+	organization       patterns
+	organizationalUnit patterns
+}
+
+func (m *dnMatcher) match(name pkix.Name) bool {
+	if len(m.commonName) > 0 && !m.commonName.matchAny([]string{name.CommonName}) {
+		return false
+	}
+
+	if len(m.organization) > 0 && !m.organization.matchAny(name.Organization) {
+		return false
+	}
+
+	if len(m.organizationalUnit) > 0 && !m.organizationalUnit.matchAny(name.OrganizationalUnit) {
+		return false
+	}
+
+	return true
+}
+
+// patterns is a list of values where the * wildcard matches any sequence of characters.
+type patterns []*regexp.Regexp
+
+func newPatterns(values []string) (patterns, error) {
+	var result patterns
+	for _, value := range values {
+		if value == "" {
+			return nil, errors.New("empty value")
+		}
+
+		exp := "^" + strings.ReplaceAll(regexp.QuoteMeta(value), `\*`, ".*") + "$"
+
+		re, err := regexp.Compile(exp)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value %q: %w", value, err)
+		}
+
+		result = append(result, re)
+	}
+
+	return result, nil
+}
+
+func (p patterns) matchAny(values []string) bool {
+	for _, value := range values {
+		for _, re := range p {
+			if re.MatchString(value) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// NewTLSClientCert creates a tlsClientCertAuth middleware.
+func NewTLSClientCert(ctx context.Context, next http.Handler, config dynamic.TLSClientCertAuth, name string) (http.Handler, error) {
+	log.FromContext(middlewares.GetLoggerCtx(ctx, name, tlsClientCertTypeName)).Debug("Creating middleware")
+
+	if len(config.Rules) == 0 {
+		return nil, errors.New("no rules defined")
+	}
+
+	rules := make([]certRule, 0, len(config.Rules))
+	for i, ruleConfig := range config.Rules {
+		rule, err := newCertRule(ruleConfig)
+		if err != nil {
+			return nil, fmt.Errorf("rule %d: %w", i, err)
+		}
+
+		rules = append(rules, rule)
+	}
+
+	return &tlsClientCertAuth{
+		next:  next,
+		rules: rules,
+		name:  name,
+	}, nil
+}
+
+func newCertRule(config dynamic.TLSClientCertAuthRule) (certRule, error) {
+	subject, err := newDNMatcher(config.Subject)
+	if err != nil {
+		return certRule{}, fmt.Errorf("subject: %w", err)
+	}
+
+	issuer, err := newDNMatcher(config.Issuer)
+	if err != nil {
+		return certRule{}, fmt.Errorf("issuer: %w", err)
+	}
+
+	sanURIs, err := newPatterns(config.SANURIs)
+	if err != nil {
+		return certRule{}, fmt.Errorf("sanURIs: %w", err)
+	}
+
+	if subject == nil && issuer == nil && len(sanURIs) == 0 {
+		// A rule without criteria would authorize any verified certificate, which is most likely a configuration mistake.
+		return certRule{}, errors.New("no criteria defined")
+	}
+
+	return certRule{subject: subject, issuer: issuer, sanURIs: sanURIs}, nil
+}
+
+func newDNMatcher(config *dynamic.TLSClientCertAuthDN) (*dnMatcher, error) {
+	if config == nil {
+		return nil, nil
+	}
+
+	commonName, err := newPatterns(config.CommonName)
+	if err != nil {
+		return nil, fmt.Errorf("commonName: %w", err)
+	}
+
+	organization, err := newPatterns(config.Organization)
+	if err != nil {
+		return nil, fmt.Errorf("organization: %w", err)
+	}
+
+	organizationalUnit, err := newPatterns(config.OrganizationalUnit)
+	if err != nil {
+		return nil, fmt.Errorf("organizationalUnit: %w", err)
+	}
+
+	if len(commonName) == 0 && len(organization) == 0 && len(organizationalUnit) == 0 {
+		return nil, nil
+	}
+
+	return &dnMatcher{
+		commonName:         commonName,
+		organization:       organization,
+		organizationalUnit: organizationalUnit,
+	}, nil
+}
+
+func (a *tlsClientCertAuth) GetTracingInformation() (string, ext.SpanKindEnum) {
+	return a.name, tracing.SpanKindNoneEnum
+}
+
+func (a *tlsClientCertAuth) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	ctx := middlewares.GetLoggerCtx(req.Context(), a.name, tlsClientCertTypeName)
+	logger := log.FromContext(ctx)
+
+	// Only the certificates verified during the handshake are considered,
+	// which requires a client authentication type verifying the certificates in the TLS options.
+	if req.TLS == nil || len(req.TLS.VerifiedChains) == 0 || len(req.TLS.VerifiedChains[0]) == 0 {
+		logger.Debug("Rejecting request without a verified TLS client certificate")
+		tracing.SetErrorWithEvent(req, "Rejecting request without a verified TLS client certificate")
+		reject(ctx, rw)
+		return
+	}
+
+	cert := req.TLS.VerifiedChains[0][0]
+
+	for _, rule := range a.rules {
+		if rule.match(cert) {
+			logger.Debugf("Accepting TLS client certificate %q", cert.Subject)
+			a.next.ServeHTTP(rw, req)
+			return
+		}
+	}
+
+	msg := fmt.Sprintf("Rejecting TLS client certificate %q: no matching rule", cert.Subject)
+	logger.Debug(msg)
+	tracing.SetErrorWithEvent(req, msg)
+	reject(ctx, rw)
+}
+
+func reject(ctx context.Context, rw http.ResponseWriter) {
+	statusCode := http.StatusForbidden
+
+	rw.WriteHeader(statusCode)
+	_, err := rw.Write([]byte(http.StatusText(statusCode)))
+	if err != nil {
+		log.FromContext(ctx).Error(err)
+	}
+}
@@ -0,0 +1,190 @@
+package auth
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/traefik/traefik/v2/pkg/config/dynamic"
+)
+
+func TestNewTLSClientCert(t *testing.T) {
+	testCases := []struct {
+		desc          string
+		config        dynamic.TLSClientCertAuth
+		expectedError bool
+	}{
+		{
+			desc:          "no rules",
+			config:        dynamic.TLSClientCertAuth{},
+			expectedError: true,
+		},
+		{
+			desc: "rule without criteria",
+			config: dynamic.TLSClientCertAuth{
+				Rules: []dynamic.TLSClientCertAuthRule{{Subject: &dynamic.TLSClientCertAuthDN{}}},
+			},
+			expectedError: true,
+		},
+		{
+			desc: "empty value",
+			config: dynamic.TLSClientCertAuth{
+				Rules: []dynamic.TLSClientCertAuthRule{{SANURIs: []string{""}}},
+			},
+			expectedError: true,
+		},
+		{
+			desc: "valid rules",
+			config: dynamic.TLSClientCertAuth{
+				Rules: []dynamic.TLSClientCertAuthRule{
+					{SANURIs: []string{"spiffe://example.org/*"}},
+					{Subject: &dynamic.TLSClientCertAuthDN{CommonName: []string{"foo"}}},
+				},
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {})
+
+			_, err := NewTLSClientCert(context.Background(), next, test.config, "authTest")
+			if test.expectedError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestTLSClientCertAuth_ServeHTTP(t *testing.T) {
+	billing := &x509.Certificate{
+		Subject: pkix.Name{CommonName: "billing", Organization: []string{"Example"}, OrganizationalUnit: []string{"Payments"}},
+		Issuer:  pkix.Name{CommonName: "Example Internal CA"},
+		URIs:    []*url.URL{mustParseURL(t, "spiffe://example.org/ns/prod/sa/billing")},
+	}
+
+	frontend := &x509.Certificate{
+		Subject: pkix.Name{CommonName: "frontend", Organization: []string{"Example"}},
+		Issuer:  pkix.Name{CommonName: "Example Public CA"},
+	}
+
+	testCases := []struct {
+		desc               string
+		rules              []dynamic.TLSClientCertAuthRule
+		tls                *tls.ConnectionState
+		expectedStatusCode int
+	}{
+		{
+			desc:               "no TLS",
+			rules:              []dynamic.TLSClientCertAuthRule{{SANURIs: []string{"*"}}},
+			expectedStatusCode: http.StatusForbidden,
+		},
+		{
+			desc:  "certificate not verified",
+			rules: []dynamic.TLSClientCertAuthRule{{Subject: &dynamic.TLSClientCertAuthDN{CommonName: []string{"billing"}}}},
+			tls: &tls.ConnectionState{
+				PeerCertificates: []*x509.Certificate{billing},
+			},
+			expectedStatusCode: http.StatusForbidden,
+		},
+		{
+			desc:               "SPIFFE ID with wildcard",
+			rules:              []dynamic.TLSClientCertAuthRule{{SANURIs: []string{"spiffe://example.org/ns/prod/*"}}},
+			tls:                verified(billing),
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			desc:               "SPIFFE ID mismatch",
+			rules:              []dynamic.TLSClientCertAuthRule{{SANURIs: []string{"spiffe://example.org/ns/staging/*"}}},
+			tls:                verified(billing),
+			expectedStatusCode: http.StatusForbidden,
+		},
+		{
+			desc:               "no URI SAN",
+			rules:              []dynamic.TLSClientCertAuthRule{{SANURIs: []string{"*"}}},
+			tls:                verified(frontend),
+			expectedStatusCode: http.StatusForbidden,
+		},
+		{
+			desc: "subject and issuer",
+			rules: []dynamic.TLSClientCertAuthRule{{
+				Subject: &dynamic.TLSClientCertAuthDN{Organization: []string{"Example"}, OrganizationalUnit: []string{"Payments", "Accounting"}},
+				Issuer:  &dynamic.TLSClientCertAuthDN{CommonName: []string{"Example Internal CA"}},
+			}},
+			tls:                verified(billing),
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			desc: "all criteria of a rule must match",
+			rules: []dynamic.TLSClientCertAuthRule{{
+				Subject: &dynamic.TLSClientCertAuthDN{Organization: []string{"Example"}},
+				Issuer:  &dynamic.TLSClientCertAuthDN{CommonName: []string{"Example Internal CA"}},
+			}},
+			tls:                verified(frontend),
+			expectedStatusCode: http.StatusForbidden,
+		},
+		{
+			desc: "one rule must match",
+			rules: []dynamic.TLSClientCertAuthRule{
+				{SANURIs: []string{"spiffe://example.org/*"}},
+				{Subject: &dynamic.TLSClientCertAuthDN{CommonName: []string{"front*"}}},
+			},
+			tls:                verified(frontend),
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			desc:               "wildcard does not match partially",
+			rules:              []dynamic.TLSClientCertAuthRule{{Subject: &dynamic.TLSClientCertAuthDN{CommonName: []string{"front"}}}},
+			tls:                verified(frontend),
+			expectedStatusCode: http.StatusForbidden,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {})
+
+			handler, err := NewTLSClientCert(context.Background(), next, dynamic.TLSClientCertAuth{Rules: test.rules}, "authTest")
+			require.NoError(t, err)
+
+			req := httptest.NewRequest(http.MethodGet, "https://foo.example.com", nil)
+			req.TLS = test.tls
+
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, req)
+
+			assert.Equal(t, test.expectedStatusCode, recorder.Code)
+		})
+	}
+}
+
+func verified(cert *x509.Certificate) *tls.ConnectionState {
+	return &tls.ConnectionState{
+		PeerCertificates: []*x509.Certificate{cert},
+		VerifiedChains:   [][]*x509.Certificate{{cert}},
+	}
+}
+
+func mustParseURL(t *testing.T, rawURL string) *url.URL {
+	t.Helper()
+
+	u, err := url.Parse(rawURL)
+	require.NoError(t, err)
+
+	return u
+}
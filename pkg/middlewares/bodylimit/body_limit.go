@@ -0,0 +1,60 @@
+package bodylimit
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/opentracing/opentracing-go/ext"
+	"github.com/traefik/traefik/v2/pkg/config/dynamic"
+	"github.com/traefik/traefik/v2/pkg/log"
+	"github.com/traefik/traefik/v2/pkg/middlewares"
+	"github.com/traefik/traefik/v2/pkg/tracing"
+)
+
+const typeName = "BodyLimit"
+
+// bodyLimit is a middleware limiting the size of the request bodies.
+type bodyLimit struct {
+	next        http.Handler
+	name        string
+	maxBodySize int64
+}
+
+// New creates a body limit middleware.
+func New(ctx context.Context, next http.Handler, config dynamic.BodyLimit, name string) (http.Handler, error) {
+	log.FromContext(middlewares.GetLoggerCtx(ctx, name, typeName)).Debug("Creating middleware")
+
+	if config.MaxBodySize <= 0 {
+		return nil, errors.New("maxBodySize must be greater than 0")
+	}
+
+	return &bodyLimit{
+		next:        next,
+		name:        name,
+		maxBodySize: config.MaxBodySize,
+	}, nil
+}
+
+func (b *bodyLimit) GetTracingInformation() (string, ext.SpanKindEnum) {
+	return b.name, tracing.SpanKindNoneEnum
+}
+
+func (b *bodyLimit) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	if req.ContentLength > b.maxBodySize {
+		logger := log.FromContext(middlewares.GetLoggerCtx(req.Context(), b.name, typeName))
+		logger.Debugf("Request body of %d bytes exceeds the limit of %d bytes", req.ContentLength, b.maxBodySize)
+
+		tracing.SetErrorWithEvent(req, "request body too large")
+		http.Error(rw, http.StatusText(http.StatusRequestEntityTooLarge), http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	if req.Body != nil && req.Body != http.NoBody {
+		// The reads of the body fail with an http.MaxBytesError as soon as the limit is crossed,
+		// which is turned into a 413 response by the proxy, or by the middlewares reading the body.
+		req.Body = http.MaxBytesReader(rw, req.Body, b.maxBodySize)
+	}
+
+	b.next.ServeHTTP(rw, req)
+}
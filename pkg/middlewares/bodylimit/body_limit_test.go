@@ -0,0 +1,148 @@
+package bodylimit
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/traefik/traefik/v2/pkg/config/dynamic"
+)
+
+func TestNew(t *testing.T) {
+	testCases := []struct {
+		desc          string
+		config        dynamic.BodyLimit
+		expectedError bool
+	}{
+		{
+			desc:   "valid limit",
+			config: dynamic.BodyLimit{MaxBodySize: 10},
+		},
+		{
+			desc:          "missing limit",
+			config:        dynamic.BodyLimit{},
+			expectedError: true,
+		},
+		{
+			desc:          "negative limit",
+			config:        dynamic.BodyLimit{MaxBodySize: -1},
+			expectedError: true,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			next := http.HandlerFunc(func(http.ResponseWriter, *http.Request) {})
+
+			_, err := New(context.Background(), next, test.config, "bodyLimit")
+			if test.expectedError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestBodyLimit_ServeHTTP(t *testing.T) {
+	testCases := []struct {
+		desc           string
+		body           string
+		contentLength  int64
+		expectedCalled bool
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			desc:           "body under the limit",
+			body:           "0123456789",
+			contentLength:  10,
+			expectedCalled: true,
+			expectedStatus: http.StatusOK,
+			expectedBody:   "0123456789",
+		},
+		{
+			desc:           "announced body over the limit",
+			body:           "0123456789a",
+			contentLength:  11,
+			expectedStatus: http.StatusRequestEntityTooLarge,
+		},
+		{
+			desc:           "streamed body under the limit",
+			body:           "0123456789",
+			contentLength:  -1,
+			expectedCalled: true,
+			expectedStatus: http.StatusOK,
+			expectedBody:   "0123456789",
+		},
+		{
+			desc:           "streamed body over the limit",
+			body:           strings.Repeat("a", 1024),
+			contentLength:  -1,
+			expectedCalled: true,
+			expectedStatus: http.StatusRequestEntityTooLarge,
+		},
+		{
+			desc:           "no body",
+			expectedCalled: true,
+			expectedStatus: http.StatusOK,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			var called bool
+			next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				called = true
+
+				body, err := io.ReadAll(req.Body)
+				if err != nil {
+					var maxBytesErr *http.MaxBytesError
+					if errors.As(err, &maxBytesErr) {
+						rw.WriteHeader(http.StatusRequestEntityTooLarge)
+						return
+					}
+
+					rw.WriteHeader(http.StatusBadRequest)
+					return
+				}
+
+				_, _ = rw.Write(body)
+			})
+
+			handler, err := New(context.Background(), next, dynamic.BodyLimit{MaxBodySize: 10}, "bodyLimit")
+			require.NoError(t, err)
+
+			var body io.Reader
+			if test.body != "" {
+				// Hides the length of the body from httptest.NewRequest.
+				body = io.NopCloser(strings.NewReader(test.body))
+			}
+
+			req := httptest.NewRequest(http.MethodPost, "http://localhost", body)
+			req.ContentLength = test.contentLength
+
+			rw := httptest.NewRecorder()
+			handler.ServeHTTP(rw, req)
+
+			assert.Equal(t, test.expectedCalled, called)
+			assert.Equal(t, test.expectedStatus, rw.Code)
+
+			if test.expectedBody != "" {
+				assert.Equal(t, test.expectedBody, rw.Body.String())
+			}
+		})
+	}
+}
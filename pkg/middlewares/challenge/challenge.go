@@ -0,0 +1,515 @@
+package challenge
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/bits"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mailgun/ttlmap"
+	"github.com/opentracing/opentracing-go/ext"
+	"github.com/traefik/traefik/v2/pkg/config/dynamic"
+	"github.com/traefik/traefik/v2/pkg/ip"
+	"github.com/traefik/traefik/v2/pkg/log"
+	"github.com/traefik/traefik/v2/pkg/middlewares"
+	"github.com/traefik/traefik/v2/pkg/tracing"
+	"github.com/vulcand/oxy/forward"
+)
+
+const typeName = "Challenge"
+
+const (
+	modeProofOfWork = "proofOfWork"
+	modeJavaScript  = "javascript"
+
+	bindIP        = "ip"
+	bindUserAgent = "userAgent"
+)
+
+const (
+	// secretMinLength is the minimum length of the secret signing the challenges and the clearance cookies.
+	secretMinLength = 32
+	// maxDifficulty is the maximum number of leading zero bits of the proof-of-work hash.
+	maxDifficulty = 32
+	// maxFormSize is the maximum size of the body of the requests solving the challenges.
+	maxFormSize = 4096
+	// maxNonceLength is the maximum number of digits of the proof-of-work nonce.
+	maxNonceLength = 20
+	// maxCountedIPs is the maximum number of IPs whose requests are counted at the same time.
+	maxCountedIPs = 65536
+)
+
+// challenge is a middleware serving a challenge page to the matching requests without a valid clearance cookie.
+type challenge struct {
+	next     http.Handler
+	name     string
+	strategy ip.Strategy
+
+	secret     []byte
+	mode       string
+	difficulty int
+	userAgents []*regexp.Regexp
+	rate       *rater
+
+	bindIP        bool
+	bindUserAgent bool
+
+	verifyPath   string
+	challengeTTL time.Duration
+	clearanceTTL time.Duration
+
+	cookieName     string
+	cookieDomain   string
+	cookiePath     string
+	cookieSameSite http.SameSite
+}
+
+// rater counts the requests of the client IPs within a period.
+type rater struct {
+	average int64
+	period  time.Duration
+
+	mu       sync.Mutex
+	counters *ttlmap.TtlMap
+}
+
+// counter counts the requests of an IP, within a period.
+type counter struct {
+	count int64
+	end   time.Time
+}
+
+// New creates a Challenge middleware.
+func New(ctx context.Context, next http.Handler, config dynamic.Challenge, name string) (http.Handler, error) {
+	log.FromContext(middlewares.GetLoggerCtx(ctx, name, typeName)).Debug("Creating middleware")
+
+	if len(config.Secret) < secretMinLength {
+		return nil, fmt.Errorf("secret must be at least %d characters long", secretMinLength)
+	}
+
+	strategy, err := config.IPStrategy.Get()
+	if err != nil {
+		return nil, err
+	}
+
+	c := &challenge{
+		next:         next,
+		name:         name,
+		strategy:     strategy,
+		secret:       []byte(config.Secret),
+		mode:         config.Mode,
+		difficulty:   config.Difficulty,
+		verifyPath:   config.VerifyPath,
+		challengeTTL: time.Duration(config.ChallengeTTL),
+		clearanceTTL: time.Duration(config.ClearanceTTL),
+		cookieName:   config.CookieName,
+		cookieDomain: config.CookieDomain,
+		cookiePath:   config.CookiePath,
+	}
+
+	if c.mode == "" {
+		c.mode = modeProofOfWork
+	}
+	if c.mode != modeProofOfWork && c.mode != modeJavaScript {
+		return nil, fmt.Errorf("invalid mode %q", c.mode)
+	}
+
+	if c.difficulty < 0 || c.difficulty > maxDifficulty {
+		return nil, fmt.Errorf("difficulty must be between 0 and %d", maxDifficulty)
+	}
+
+	for _, userAgent := range config.UserAgents {
+		exp, err := regexp.Compile(userAgent)
+		if err != nil {
+			return nil, fmt.Errorf("invalid user agent expression %q: %w", userAgent, err)
+		}
+
+		c.userAgents = append(c.userAgents, exp)
+	}
+
+	if config.RequestRate != nil {
+		c.rate, err = newRater(*config.RequestRate)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	bindTo := config.BindTo
+	if len(bindTo) == 0 {
+		bindTo = []string{bindIP}
+	}
+
+	for _, value := range bindTo {
+		switch value {
+		case bindIP:
+			c.bindIP = true
+		case bindUserAgent:
+			c.bindUserAgent = true
+		default:
+			return nil, fmt.Errorf("invalid bindTo value %q", value)
+		}
+	}
+
+	if c.verifyPath == "" {
+		c.verifyPath = "/_traefik/challenge"
+	}
+	if !strings.HasPrefix(c.verifyPath, "/") {
+		return nil, errors.New("verify path must be an absolute path")
+	}
+
+	if c.challengeTTL == 0 {
+		c.challengeTTL = 5 * time.Minute
+	}
+	if c.clearanceTTL == 0 {
+		c.clearanceTTL = time.Hour
+	}
+	if c.challengeTTL < 0 || c.clearanceTTL < 0 {
+		return nil, errors.New("the challenge and clearance TTLs must be positive")
+	}
+
+	if c.cookieName == "" {
+		c.cookieName = "_traefik_clearance"
+	}
+	if c.cookiePath == "" {
+		c.cookiePath = "/"
+	}
+
+	c.cookieSameSite, err = parseSameSite(config.CookieSameSite)
+	if err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+func newRater(config dynamic.ChallengeRequestRate) (*rater, error) {
+	r := &rater{
+		average: config.Average,
+		period:  time.Duration(config.Period),
+	}
+
+	if r.period == 0 {
+		r.period = time.Second
+	}
+
+	if r.average <= 0 {
+		return nil, errors.New("the average request rate must be greater than zero")
+	}
+	if r.period < time.Second {
+		return nil, errors.New("the request rate period must be at least 1s")
+	}
+
+	var err error
+	r.counters, err = ttlmap.NewMap(maxCountedIPs)
+	if err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+func (c *challenge) GetTracingInformation() (string, ext.SpanKindEnum) {
+	return c.name, tracing.SpanKindNoneEnum
+}
+
+func (c *challenge) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	logger := log.FromContext(middlewares.GetLoggerCtx(req.Context(), c.name, typeName))
+
+	binding := c.binding(req)
+
+	if req.Method == http.MethodPost && req.URL.Path == c.verifyPath {
+		c.verify(rw, req, binding)
+		return
+	}
+
+	if cookie, err := req.Cookie(c.cookieName); err == nil && c.validClearance(cookie.Value, binding, time.Now()) {
+		c.removeCookie(req)
+		c.next.ServeHTTP(rw, req)
+		return
+	}
+
+	if !c.suspicious(req) {
+		c.next.ServeHTTP(rw, req)
+		return
+	}
+
+	logger.Debugf("Challenging the request from %s", c.strategy.GetIP(req))
+
+	token, err := c.newToken(redirectTarget(req), binding, time.Now())
+	if err != nil {
+		logger.Errorf("Unable to create the challenge: %v", err)
+		rw.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	c.serveChallenge(rw, req, token)
+}
+
+// suspicious returns whether the request has to be challenged,
+// all the requests being challenged if no user agents and no request rate are configured.
+func (c *challenge) suspicious(req *http.Request) bool {
+	if len(c.userAgents) == 0 && c.rate == nil {
+		return true
+	}
+
+	suspicious := false
+
+	userAgent := req.UserAgent()
+	for _, exp := range c.userAgents {
+		if exp.MatchString(userAgent) {
+			suspicious = true
+			break
+		}
+	}
+
+	if c.rate != nil && c.rate.count(c.strategy.GetIP(req)) > c.rate.average {
+		suspicious = true
+	}
+
+	return suspicious
+}
+
+// verify verifies the solution of a challenge, and grants a clearance cookie if it is valid.
+func (c *challenge) verify(rw http.ResponseWriter, req *http.Request, binding string) {
+	logger := log.FromContext(middlewares.GetLoggerCtx(req.Context(), c.name, typeName))
+
+	req.Body = http.MaxBytesReader(rw, req.Body, maxFormSize)
+	if err := req.ParseForm(); err != nil {
+		logger.Debugf("Unable to parse the challenge solution: %v", err)
+		reject(req.Context(), rw, http.StatusBadRequest)
+		return
+	}
+
+	token := req.PostForm.Get("challenge")
+	nonce := req.PostForm.Get("nonce")
+
+	now := time.Now()
+
+	redirect, expiresAt, err := c.parseToken(token, binding)
+	if err != nil {
+		logger.Debugf("Invalid challenge from %s: %v", c.strategy.GetIP(req), err)
+		reject(req.Context(), rw, http.StatusForbidden)
+		return
+	}
+
+	// An expired challenge is not an attack, the client is redirected to get a new challenge.
+	if !now.Before(expiresAt) {
+		logger.Debugf("Expired challenge from %s", c.strategy.GetIP(req))
+		http.Redirect(rw, req, redirect, http.StatusSeeOther)
+		return
+	}
+
+	if c.mode == modeProofOfWork && !validProof(token, nonce, c.difficulty) {
+		logger.Debugf("Invalid proof-of-work from %s", c.strategy.GetIP(req))
+		reject(req.Context(), rw, http.StatusForbidden)
+		return
+	}
+
+	http.SetCookie(rw, &http.Cookie{
+		Name:     c.cookieName,
+		Value:    c.newClearance(binding, now),
+		Path:     c.cookiePath,
+		Domain:   c.cookieDomain,
+		MaxAge:   int(c.clearanceTTL / time.Second),
+		Secure:   isSecure(req),
+		HttpOnly: true,
+		SameSite: c.cookieSameSite,
+	})
+
+	http.Redirect(rw, req, redirect, http.StatusSeeOther)
+}
+
+// binding returns what the challenges and the clearance cookies are bound to.
+func (c *challenge) binding(req *http.Request) string {
+	var values []string
+
+	if c.bindIP {
+		values = append(values, c.strategy.GetIP(req))
+	}
+
+	if c.bindUserAgent {
+		values = append(values, req.UserAgent())
+	}
+
+	return strings.Join(values, "\n")
+}
+
+// newToken creates a challenge token, holding its expiry, a random value, and the URL to redirect to once solved.
+func (c *challenge) newToken(redirect, binding string, now time.Time) (string, error) {
+	payload := make([]byte, 24, 24+len(redirect))
+	binary.BigEndian.PutUint64(payload, uint64(now.Add(c.challengeTTL).Unix()))
+
+	if _, err := rand.Read(payload[8:]); err != nil {
+		return "", err
+	}
+
+	encoded := base64.RawURLEncoding.EncodeToString(append(payload, redirect...))
+
+	return encoded + "." + c.sign("challenge", encoded, binding), nil
+}
+
+// parseToken verifies the signature of a challenge token, and returns the URL to redirect to and the expiry of the challenge.
+func (c *challenge) parseToken(token, binding string) (string, time.Time, error) {
+	encoded, signature, ok := strings.Cut(token, ".")
+	if !ok {
+		return "", time.Time{}, errors.New("malformed challenge")
+	}
+
+	if !hmac.Equal([]byte(signature), []byte(c.sign("challenge", encoded, binding))) {
+		return "", time.Time{}, errors.New("invalid challenge signature")
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil || len(payload) < 24 {
+		return "", time.Time{}, errors.New("malformed challenge")
+	}
+
+	expiresAt := time.Unix(int64(binary.BigEndian.Uint64(payload)), 0)
+
+	return string(payload[24:]), expiresAt, nil
+}
+
+// newClearance creates the value of a clearance cookie, holding its expiry.
+func (c *challenge) newClearance(binding string, now time.Time) string {
+	expiry := strconv.FormatInt(now.Add(c.clearanceTTL).Unix(), 10)
+
+	return expiry + "." + c.sign("clearance", expiry, binding)
+}
+
+// validClearance returns whether the value of a clearance cookie is correctly signed and not expired.
+func (c *challenge) validClearance(value, binding string, now time.Time) bool {
+	expiry, signature, ok := strings.Cut(value, ".")
+	if !ok {
+		return false
+	}
+
+	if !hmac.Equal([]byte(signature), []byte(c.sign("clearance", expiry, binding))) {
+		return false
+	}
+
+	expiresAt, err := strconv.ParseInt(expiry, 10, 64)
+	if err != nil {
+		return false
+	}
+
+	return now.Before(time.Unix(expiresAt, 0))
+}
+
+// sign returns the signature of a value bound to the client, the purpose preventing a challenge from being used as a clearance.
+func (c *challenge) sign(purpose, value, binding string) string {
+	mac := hmac.New(sha256.New, c.secret)
+	mac.Write([]byte(purpose + "\n" + value + "\n" + binding))
+
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func (c *challenge) removeCookie(req *http.Request) {
+	cookies := req.Cookies()
+	req.Header.Del("Cookie")
+
+	for _, cookie := range cookies {
+		if cookie.Name == c.cookieName {
+			continue
+		}
+
+		req.AddCookie(cookie)
+	}
+}
+
+// count counts a request of an IP, and returns the number of requests within the period.
+func (r *rater) count(clientIP string) int64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+
+	value, ok := r.counters.Get(clientIP)
+	if c, isCounter := value.(*counter); ok && isCounter && now.Before(c.end) {
+		c.count++
+		return c.count
+	}
+
+	// The period is rounded up to a second, as the TTLs of the map are in seconds.
+	ttl := int((r.period + time.Second - 1) / time.Second)
+	if err := r.counters.Set(clientIP, &counter{count: 1, end: now.Add(r.period)}, ttl); err != nil {
+		log.WithoutContext().Errorf("Unable to count the request for IP %s: %v", clientIP, err)
+	}
+
+	return 1
+}
+
+// validProof returns whether the SHA-256 hash of the challenge and the nonce has enough leading zero bits.
+func validProof(token, nonce string, difficulty int) bool {
+	if nonce == "" || len(nonce) > maxNonceLength {
+		return false
+	}
+
+	for _, r := range nonce {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+
+	return leadingZeroBits(sha256.Sum256([]byte(token+":"+nonce))) >= difficulty
+}
+
+func leadingZeroBits(hash [sha256.Size]byte) int {
+	var count int
+	for _, b := range hash {
+		if b != 0 {
+			return count + bits.LeadingZeros8(b)
+		}
+
+		count += 8
+	}
+
+	return count
+}
+
+// redirectTarget returns the local URL to redirect to once the challenge is solved.
+func redirectTarget(req *http.Request) string {
+	target := req.URL.RequestURI()
+	// The URLs starting with // are not local, as the browsers redirect to their host.
+	if !strings.HasPrefix(target, "/") || strings.HasPrefix(target, "//") {
+		return "/"
+	}
+
+	return target
+}
+
+func parseSameSite(value string) (http.SameSite, error) {
+	switch strings.ToLower(value) {
+	case "", "lax":
+		return http.SameSiteLaxMode, nil
+	case "strict":
+		return http.SameSiteStrictMode, nil
+	case "none":
+		return http.SameSiteNoneMode, nil
+	default:
+		return 0, fmt.Errorf("invalid cookie SameSite value %q", value)
+	}
+}
+
+func isSecure(req *http.Request) bool {
+	return req.TLS != nil || req.Header.Get(forward.XForwardedProto) == "https"
+}
+
+func reject(ctx context.Context, rw http.ResponseWriter, statusCode int) {
+	rw.WriteHeader(statusCode)
+
+	_, err := rw.Write([]byte(http.StatusText(statusCode)))
+	if err != nil {
+		log.FromContext(ctx).Error(err)
+	}
+}
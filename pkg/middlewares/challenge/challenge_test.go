@@ -0,0 +1,422 @@
+package challenge
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	ptypes "github.com/traefik/paerser/types"
+	"github.com/traefik/traefik/v2/pkg/config/dynamic"
+)
+
+const testSecret = "0123456789abcdef0123456789abcdef"
+
+func TestNew(t *testing.T) {
+	testCases := []struct {
+		desc          string
+		config        dynamic.Challenge
+		expectedError bool
+	}{
+		{
+			desc:   "defaults",
+			config: dynamic.Challenge{Secret: testSecret},
+		},
+		{
+			desc:          "short secret",
+			config:        dynamic.Challenge{Secret: "foo"},
+			expectedError: true,
+		},
+		{
+			desc:          "unknown mode",
+			config:        dynamic.Challenge{Secret: testSecret, Mode: "captcha"},
+			expectedError: true,
+		},
+		{
+			desc:          "difficulty too high",
+			config:        dynamic.Challenge{Secret: testSecret, Difficulty: 33},
+			expectedError: true,
+		},
+		{
+			desc:          "invalid user agent expression",
+			config:        dynamic.Challenge{Secret: testSecret, UserAgents: []string{"("}},
+			expectedError: true,
+		},
+		{
+			desc:          "request rate without average",
+			config:        dynamic.Challenge{Secret: testSecret, RequestRate: &dynamic.ChallengeRequestRate{}},
+			expectedError: true,
+		},
+		{
+			desc: "request rate period too short",
+			config: dynamic.Challenge{Secret: testSecret, RequestRate: &dynamic.ChallengeRequestRate{
+				Average: 10,
+				Period:  ptypes.Duration(time.Millisecond),
+			}},
+			expectedError: true,
+		},
+		{
+			desc:          "unknown binding",
+			config:        dynamic.Challenge{Secret: testSecret, BindTo: []string{"session"}},
+			expectedError: true,
+		},
+		{
+			desc:          "relative verify path",
+			config:        dynamic.Challenge{Secret: testSecret, VerifyPath: "verify"},
+			expectedError: true,
+		},
+		{
+			desc:          "invalid SameSite",
+			config:        dynamic.Challenge{Secret: testSecret, CookieSameSite: "foo"},
+			expectedError: true,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := New(context.Background(), http.NotFoundHandler(), test.config, "challenge")
+			if test.expectedError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestChallenge_solve(t *testing.T) {
+	testCases := []struct {
+		desc   string
+		mode   string
+		bindTo []string
+	}{
+		{
+			desc: "proof-of-work bound to the IP",
+			mode: "proofOfWork",
+		},
+		{
+			desc: "javascript bound to the IP and user agent",
+			mode: "javascript",
+			bindTo: []string{
+				"ip",
+				"userAgent",
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				_, err := req.Cookie("_traefik_clearance")
+				assert.ErrorIs(t, err, http.ErrNoCookie)
+				assert.Equal(t, "bar", req.Header.Get("Cookie")[len("foo="):])
+
+				rw.WriteHeader(http.StatusOK)
+			})
+
+			config := dynamic.Challenge{}
+			config.SetDefaults()
+			config.Secret = testSecret
+			config.Mode = test.mode
+			config.Difficulty = 8
+			if test.bindTo != nil {
+				config.BindTo = test.bindTo
+			}
+
+			handler, err := New(context.Background(), next, config, "challenge")
+			require.NoError(t, err)
+
+			// The request is challenged.
+			req := newRequest(http.MethodGet, "/catalog?page=2", nil)
+			rw := httptest.NewRecorder()
+			handler.ServeHTTP(rw, req)
+
+			require.Equal(t, http.StatusForbidden, rw.Code)
+			assert.Equal(t, "no-store", rw.Header().Get("Cache-Control"))
+
+			token := extractToken(t, rw.Body.String())
+
+			nonce := "0"
+			if test.mode == "proofOfWork" {
+				nonce = solve(token, config.Difficulty)
+			}
+
+			// The solution grants the clearance cookie.
+			req = newRequest(http.MethodPost, "/_traefik/challenge", url.Values{"challenge": {token}, "nonce": {nonce}})
+			rw = httptest.NewRecorder()
+			handler.ServeHTTP(rw, req)
+
+			require.Equal(t, http.StatusSeeOther, rw.Code)
+			assert.Equal(t, "/catalog?page=2", rw.Header().Get("Location"))
+
+			cookies := rw.Result().Cookies()
+			require.Len(t, cookies, 1)
+			assert.Equal(t, "_traefik_clearance", cookies[0].Name)
+			assert.True(t, cookies[0].HttpOnly)
+			assert.Equal(t, 3600, cookies[0].MaxAge)
+
+			// The clearance cookie lets the request through.
+			req = newRequest(http.MethodGet, "/catalog?page=2", nil)
+			req.AddCookie(cookies[0])
+			req.AddCookie(&http.Cookie{Name: "foo", Value: "bar"})
+			rw = httptest.NewRecorder()
+			handler.ServeHTTP(rw, req)
+
+			assert.Equal(t, http.StatusOK, rw.Code)
+
+			// The clearance cookie is bound to the client.
+			req = newRequest(http.MethodGet, "/catalog?page=2", nil)
+			req.RemoteAddr = "192.0.2.2:1234"
+			req.AddCookie(cookies[0])
+			rw = httptest.NewRecorder()
+			handler.ServeHTTP(rw, req)
+
+			assert.Equal(t, http.StatusForbidden, rw.Code)
+		})
+	}
+}
+
+func TestChallenge_verify(t *testing.T) {
+	config := dynamic.Challenge{}
+	config.SetDefaults()
+	config.Secret = testSecret
+	config.Difficulty = 8
+
+	handler, err := New(context.Background(), http.NotFoundHandler(), config, "challenge")
+	require.NoError(t, err)
+
+	c := handler.(*challenge)
+
+	token, err := c.newToken("/catalog", "192.0.2.1", time.Now())
+	require.NoError(t, err)
+
+	expiredToken, err := c.newToken("/catalog", "192.0.2.1", time.Now().Add(-time.Hour))
+	require.NoError(t, err)
+
+	invalidNonce := "0"
+	for validProof(token, invalidNonce, config.Difficulty) {
+		invalidNonce += "0"
+	}
+
+	testCases := []struct {
+		desc             string
+		remoteAddr       string
+		token            string
+		nonce            string
+		expectedStatus   int
+		expectedLocation string
+		expectedCookie   bool
+	}{
+		{
+			desc:             "valid solution",
+			token:            token,
+			nonce:            solve(token, config.Difficulty),
+			expectedStatus:   http.StatusSeeOther,
+			expectedLocation: "/catalog",
+			expectedCookie:   true,
+		},
+		{
+			desc:           "invalid proof-of-work",
+			token:          token,
+			nonce:          invalidNonce,
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			desc:           "non numeric nonce",
+			token:          token,
+			nonce:          "foo",
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			desc:           "tampered challenge",
+			token:          "x" + token,
+			nonce:          "0",
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			desc:           "challenge of another client",
+			remoteAddr:     "192.0.2.2:1234",
+			token:          token,
+			nonce:          solve(token, config.Difficulty),
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			desc:             "expired challenge",
+			token:            expiredToken,
+			nonce:            solve(expiredToken, config.Difficulty),
+			expectedStatus:   http.StatusSeeOther,
+			expectedLocation: "/catalog",
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			req := newRequest(http.MethodPost, "/_traefik/challenge", url.Values{"challenge": {test.token}, "nonce": {test.nonce}})
+			if test.remoteAddr != "" {
+				req.RemoteAddr = test.remoteAddr
+			}
+
+			rw := httptest.NewRecorder()
+			handler.ServeHTTP(rw, req)
+
+			assert.Equal(t, test.expectedStatus, rw.Code)
+			assert.Equal(t, test.expectedLocation, rw.Header().Get("Location"))
+			assert.Equal(t, test.expectedCookie, len(rw.Result().Cookies()) > 0)
+		})
+	}
+}
+
+func TestChallenge_criteria(t *testing.T) {
+	testCases := []struct {
+		desc            string
+		userAgents      []string
+		requestRate     *dynamic.ChallengeRequestRate
+		userAgent       string
+		expectedAllowed int
+	}{
+		{
+			desc:            "all requests",
+			userAgent:       "Mozilla/5.0",
+			expectedAllowed: 0,
+		},
+		{
+			desc:            "matching user agent",
+			userAgents:      []string{"(?i)curl|python-requests", "^$"},
+			userAgent:       "python-requests/2.28",
+			expectedAllowed: 0,
+		},
+		{
+			desc:            "other user agent",
+			userAgents:      []string{"(?i)curl|python-requests", "^$"},
+			userAgent:       "Mozilla/5.0",
+			expectedAllowed: 5,
+		},
+		{
+			desc:            "request rate",
+			requestRate:     &dynamic.ChallengeRequestRate{Average: 3, Period: ptypes.Duration(time.Minute)},
+			userAgent:       "Mozilla/5.0",
+			expectedAllowed: 3,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			config := dynamic.Challenge{
+				Secret:      testSecret,
+				UserAgents:  test.userAgents,
+				RequestRate: test.requestRate,
+			}
+
+			handler, err := New(context.Background(), http.NotFoundHandler(), config, "challenge")
+			require.NoError(t, err)
+
+			var allowed int
+			for i := 0; i < 5; i++ {
+				req := newRequest(http.MethodGet, "/catalog", nil)
+				req.Header.Set("User-Agent", test.userAgent)
+
+				rw := httptest.NewRecorder()
+				handler.ServeHTTP(rw, req)
+
+				if rw.Code == http.StatusNotFound {
+					allowed++
+				}
+			}
+
+			assert.Equal(t, test.expectedAllowed, allowed)
+		})
+	}
+}
+
+func TestChallenge_validClearance(t *testing.T) {
+	handler, err := New(context.Background(), http.NotFoundHandler(), dynamic.Challenge{Secret: testSecret}, "challenge")
+	require.NoError(t, err)
+
+	c := handler.(*challenge)
+	now := time.Now()
+
+	clearance := c.newClearance("192.0.2.1", now)
+
+	assert.True(t, c.validClearance(clearance, "192.0.2.1", now))
+	assert.False(t, c.validClearance(clearance, "192.0.2.2", now))
+	assert.False(t, c.validClearance(clearance, "192.0.2.1", now.Add(2*time.Hour)))
+	assert.False(t, c.validClearance("9"+clearance, "192.0.2.1", now))
+
+	// A challenge cannot be used as a clearance.
+	token, err := c.newToken("/", "192.0.2.1", now)
+	require.NoError(t, err)
+	assert.False(t, c.validClearance(token, "192.0.2.1", now))
+}
+
+func Test_redirectTarget(t *testing.T) {
+	testCases := []struct {
+		target   string
+		expected string
+	}{
+		{target: "/catalog?page=2", expected: "/catalog?page=2"},
+		{target: "//example.com/", expected: "/"},
+		{target: "/\\example.com/", expected: "/%5Cexample.com/"},
+		{target: "http://example.com/catalog", expected: "/catalog"},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.target, func(t *testing.T) {
+			t.Parallel()
+
+			req := httptest.NewRequest(http.MethodGet, test.target, nil)
+
+			assert.Equal(t, test.expected, redirectTarget(req))
+		})
+	}
+}
+
+func newRequest(method, target string, form url.Values) *http.Request {
+	var req *http.Request
+	if form != nil {
+		req = httptest.NewRequest(method, target, strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	} else {
+		req = httptest.NewRequest(method, target, nil)
+	}
+
+	req.RemoteAddr = "192.0.2.1:1234"
+
+	return req
+}
+
+func extractToken(t *testing.T, page string) string {
+	t.Helper()
+
+	matches := regexp.MustCompile(`name="challenge" value="([^"]+)"`).FindStringSubmatch(page)
+	require.Len(t, matches, 2)
+
+	return matches[1]
+}
+
+func solve(token string, difficulty int) string {
+	for nonce := 0; ; nonce++ {
+		if validProof(token, strconv.Itoa(nonce), difficulty) {
+			return strconv.Itoa(nonce)
+		}
+	}
+}
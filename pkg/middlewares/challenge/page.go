@@ -0,0 +1,155 @@
+package challenge
+
+import (
+	"bytes"
+	"html/template"
+	"net/http"
+
+	"github.com/traefik/traefik/v2/pkg/log"
+	"github.com/traefik/traefik/v2/pkg/middlewares"
+)
+
+// pageTemplate is the challenge page.
+// The proof-of-work is computed with a JavaScript implementation of SHA-256,
+// as the Web Crypto API is only available to the pages served over HTTPS.
+var pageTemplate = template.Must(template.New("challenge").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<meta name="robots" content="noindex, nofollow">
+<title>Checking your browser</title>
+</head>
+<body>
+<p>Checking your browser before accessing the page, this should only take a moment.</p>
+<noscript><p>Please enable JavaScript to access the page.</p></noscript>
+<form id="challenge" method="POST" action="{{ .VerifyPath }}">
+<input type="hidden" name="challenge" value="{{ .Token }}">
+<input type="hidden" name="nonce" value="">
+</form>
+<script>
+(function () {
+  var K = [
+    0x428a2f98, 0x71374491, 0xb5c0fbcf, 0xe9b5dba5, 0x3956c25b, 0x59f111f1, 0x923f82a4, 0xab1c5ed5,
+    0xd807aa98, 0x12835b01, 0x243185be, 0x550c7dc3, 0x72be5d74, 0x80deb1fe, 0x9bdc06a7, 0xc19bf174,
+    0xe49b69c1, 0xefbe4786, 0x0fc19dc6, 0x240ca1cc, 0x2de92c6f, 0x4a7484aa, 0x5cb0a9dc, 0x76f988da,
+    0x983e5152, 0xa831c66d, 0xb00327c8, 0xbf597fc7, 0xc6e00bf3, 0xd5a79147, 0x06ca6351, 0x14292967,
+    0x27b70a85, 0x2e1b2138, 0x4d2c6dfc, 0x53380d13, 0x650a7354, 0x766a0abb, 0x81c2c92e, 0x92722c85,
+    0xa2bfe8a1, 0xa81a664b, 0xc24b8b70, 0xc76c51a3, 0xd192e819, 0xd6990624, 0xf40e3585, 0x106aa070,
+    0x19a4c116, 0x1e376c08, 0x2748774c, 0x34b0bcb5, 0x391c0cb3, 0x4ed8aa4a, 0x5b9cca4f, 0x682e6ff3,
+    0x748f82ee, 0x78a5636f, 0x84c87814, 0x8cc70208, 0x90befffa, 0xa4506ceb, 0xbef9a3f7, 0xc67178f2
+  ];
+
+  function ror(x, n) {
+    return (x >>> n) | (x << (32 - n));
+  }
+
+  // sha256 returns the hash of an ASCII string, as eight 32-bit words.
+  function sha256(msg) {
+    var n = msg.length, words = [], i, t;
+    for (i = 0; i < n; i++) {
+      words[i >> 2] |= (msg.charCodeAt(i) & 0xff) << (24 - (i % 4) * 8);
+    }
+    words[n >> 2] |= 0x80 << (24 - (n % 4) * 8);
+    var size = ((n + 8) >> 6) * 16 + 16;
+    words[size - 1] = n * 8;
+
+    var H = [0x6a09e667, 0xbb67ae85, 0x3c6ef372, 0xa54ff53a, 0x510e527f, 0x9b05688c, 0x1f83d9ab, 0x5be0cd19];
+    var W = new Array(64);
+    for (var o = 0; o < size; o += 16) {
+      for (t = 0; t < 64; t++) {
+        if (t < 16) {
+          W[t] = words[o + t] | 0;
+        } else {
+          var x = W[t - 15], y = W[t - 2];
+          var s0 = ror(x, 7) ^ ror(x, 18) ^ (x >>> 3);
+          var s1 = ror(y, 17) ^ ror(y, 19) ^ (y >>> 10);
+          W[t] = (W[t - 16] + s0 + W[t - 7] + s1) | 0;
+        }
+      }
+
+      var a = H[0], b = H[1], c = H[2], d = H[3], e = H[4], f = H[5], g = H[6], h = H[7];
+      for (t = 0; t < 64; t++) {
+        var t1 = (h + (ror(e, 6) ^ ror(e, 11) ^ ror(e, 25)) + ((e & f) ^ (~e & g)) + K[t] + W[t]) | 0;
+        var t2 = ((ror(a, 2) ^ ror(a, 13) ^ ror(a, 22)) + ((a & b) ^ (a & c) ^ (b & c))) | 0;
+        h = g; g = f; f = e; e = (d + t1) | 0;
+        d = c; c = b; b = a; a = (t1 + t2) | 0;
+      }
+
+      H[0] = (H[0] + a) | 0; H[1] = (H[1] + b) | 0; H[2] = (H[2] + c) | 0; H[3] = (H[3] + d) | 0;
+      H[4] = (H[4] + e) | 0; H[5] = (H[5] + f) | 0; H[6] = (H[6] + g) | 0; H[7] = (H[7] + h) | 0;
+    }
+    return H;
+  }
+
+  function leadingZeroBits(hash) {
+    var count = 0;
+    for (var i = 0; i < hash.length; i++) {
+      if (hash[i] !== 0) {
+        return count + Math.clz32(hash[i]);
+      }
+      count += 32;
+    }
+    return count;
+  }
+
+  var form = document.getElementById("challenge");
+  var token = form.elements["challenge"].value;
+  var difficulty = {{ .Difficulty }};
+  var nonce = 0;
+
+  // work looks for the nonce by slices, to keep the page responsive.
+  function work() {
+    var end = Date.now() + 50;
+    while (Date.now() < end) {
+      if (difficulty === 0 || leadingZeroBits(sha256(token + ":" + nonce)) >= difficulty) {
+        form.elements["nonce"].value = String(nonce);
+        form.submit();
+        return;
+      }
+      nonce++;
+    }
+    setTimeout(work, 0);
+  }
+
+  work();
+})();
+</script>
+</body>
+</html>
+`))
+
+type pageData struct {
+	VerifyPath string
+	Token      string
+	Difficulty int
+}
+
+// serveChallenge serves the challenge page, which solves the challenge and posts its solution to the verify path.
+func (c *challenge) serveChallenge(rw http.ResponseWriter, req *http.Request, token string) {
+	logger := log.FromContext(middlewares.GetLoggerCtx(req.Context(), c.name, typeName))
+
+	data := pageData{
+		VerifyPath: c.verifyPath,
+		Token:      token,
+	}
+
+	// In the javascript mode, the page posts the challenge as soon as it is loaded.
+	if c.mode == modeProofOfWork {
+		data.Difficulty = c.difficulty
+	}
+
+	var page bytes.Buffer
+	if err := pageTemplate.Execute(&page, data); err != nil {
+		logger.Errorf("Unable to render the challenge page: %v", err)
+		rw.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	rw.Header().Set("Content-Type", "text/html; charset=utf-8")
+	rw.Header().Set("Cache-Control", "no-store")
+	rw.WriteHeader(http.StatusForbidden)
+
+	if _, err := rw.Write(page.Bytes()); err != nil {
+		logger.Error(err)
+	}
+}
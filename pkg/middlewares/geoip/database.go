@@ -0,0 +1,108 @@
+package geoip
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+
+	"github.com/oschwald/maxminddb-golang"
+	"github.com/traefik/traefik/v2/pkg/filewatcher"
+)
+
+// record is the location of an IP, from the GeoIP2/GeoLite2 Country, City, and ASN databases.
+type record struct {
+	Continent struct {
+		Code string `maxminddb:"code"`
+	} `maxminddb:"continent"`
+	Country struct {
+		ISOCode string `maxminddb:"iso_code"`
+	} `maxminddb:"country"`
+	RegisteredCountry struct {
+		ISOCode string `maxminddb:"iso_code"`
+	} `maxminddb:"registered_country"`
+	City struct {
+		Names map[string]string `maxminddb:"names"`
+	} `maxminddb:"city"`
+	AutonomousSystemNumber uint `maxminddb:"autonomous_system_number"`
+}
+
+// location is the location of an IP, merged from the records of all the databases.
+type location struct {
+	country   string
+	continent string
+	city      string
+	asn       uint
+}
+
+// merge fills the unknown fields of the location from a record.
+func (l *location) merge(rec record) {
+	if l.country == "" {
+		l.country = rec.Country.ISOCode
+		if l.country == "" {
+			l.country = rec.RegisteredCountry.ISOCode
+		}
+	}
+
+	if l.continent == "" {
+		l.continent = rec.Continent.Code
+	}
+
+	if l.city == "" {
+		l.city = rec.City.Names["en"]
+	}
+
+	if l.asn == 0 {
+		l.asn = rec.AutonomousSystemNumber
+	}
+}
+
+func (l location) known() bool {
+	return l.country != "" || l.continent != "" || l.city != "" || l.asn != 0
+}
+
+// database is a MaxMind DB file, reloaded when it changes.
+type database struct {
+	file   string
+	shared *filewatcher.SharedFile
+}
+
+// getDatabase returns the database of a file, which is shared by the middlewares,
+// so that it is not loaded again on each configuration change.
+func getDatabase(ctx context.Context, sharedFiles *filewatcher.SharedFiles, file string) (*database, error) {
+	shared, err := sharedFiles.Get(ctx, "geoip", file, func(file string) (interface{}, error) {
+		return loadDatabase(file)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &database{file: file, shared: shared}, nil
+}
+
+func loadDatabase(file string) (*maxminddb.Reader, error) {
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	reader, err := maxminddb.FromBytes(content)
+	if err != nil {
+		return nil, fmt.Errorf("invalid database %s: %w", file, err)
+	}
+
+	return reader, nil
+}
+
+// lookup returns the record of an IP, and whether the database contains it.
+func (d *database) lookup(ip net.IP) (record, bool, error) {
+	reader := d.shared.Value().(*maxminddb.Reader)
+
+	var rec record
+	_, ok, err := reader.LookupNetwork(ip, &rec)
+	if err != nil {
+		return record{}, false, err
+	}
+
+	return rec, ok, nil
+}
@@ -0,0 +1,244 @@
+package geoip
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/opentracing/opentracing-go/ext"
+	"github.com/traefik/traefik/v2/pkg/config/dynamic"
+	"github.com/traefik/traefik/v2/pkg/filewatcher"
+	"github.com/traefik/traefik/v2/pkg/ip"
+	"github.com/traefik/traefik/v2/pkg/log"
+	"github.com/traefik/traefik/v2/pkg/middlewares"
+	"github.com/traefik/traefik/v2/pkg/middlewares/accesslog"
+	"github.com/traefik/traefik/v2/pkg/tracing"
+)
+
+const typeName = "GeoIP"
+
+// geoIP is a middleware allowing or denying the requests by the location of their source IP.
+type geoIP struct {
+	next      http.Handler
+	name      string
+	strategy  ip.Strategy
+	databases []*database
+
+	allowedCountries  map[string]struct{}
+	deniedCountries   map[string]struct{}
+	allowedContinents map[string]struct{}
+	deniedContinents  map[string]struct{}
+	allowedASNs       map[uint]struct{}
+	deniedASNs        map[uint]struct{}
+	allowUnknown      bool
+
+	countryHeader   string
+	continentHeader string
+	cityHeader      string
+	asnHeader       string
+}
+
+// New creates a GeoIP middleware.
+func New(ctx context.Context, next http.Handler, config dynamic.GeoIP, name string, sharedFiles *filewatcher.SharedFiles) (http.Handler, error) {
+	log.FromContext(middlewares.GetLoggerCtx(ctx, name, typeName)).Debug("Creating middleware")
+
+	if len(config.DatabaseFiles) == 0 {
+		return nil, errors.New("at least one database file is required")
+	}
+
+	if sharedFiles == nil {
+		return nil, errors.New("the shared files are not available")
+	}
+
+	strategy, err := config.IPStrategy.Get()
+	if err != nil {
+		return nil, err
+	}
+
+	g := &geoIP{
+		next:              next,
+		name:              name,
+		strategy:          strategy,
+		allowedCountries:  codeSet(config.AllowedCountries),
+		deniedCountries:   codeSet(config.DeniedCountries),
+		allowedContinents: codeSet(config.AllowedContinents),
+		deniedContinents:  codeSet(config.DeniedContinents),
+		allowUnknown:      config.AllowUnknown,
+		countryHeader:     config.CountryHeader,
+		continentHeader:   config.ContinentHeader,
+		cityHeader:        config.CityHeader,
+		asnHeader:         config.ASNHeader,
+	}
+
+	if g.allowedASNs, err = asnSet(config.AllowedASNs); err != nil {
+		return nil, fmt.Errorf("invalid allowedASNs: %w", err)
+	}
+
+	if g.deniedASNs, err = asnSet(config.DeniedASNs); err != nil {
+		return nil, fmt.Errorf("invalid deniedASNs: %w", err)
+	}
+
+	for _, file := range config.DatabaseFiles {
+		db, err := getDatabase(ctx, sharedFiles, file)
+		if err != nil {
+			return nil, fmt.Errorf("loading the database file: %w", err)
+		}
+
+		g.databases = append(g.databases, db)
+	}
+
+	return g, nil
+}
+
+func codeSet(codes []string) map[string]struct{} {
+	set := make(map[string]struct{}, len(codes))
+	for _, code := range codes {
+		set[strings.ToUpper(strings.TrimSpace(code))] = struct{}{}
+	}
+	return set
+}
+
+func asnSet(asns []int) (map[uint]struct{}, error) {
+	set := make(map[uint]struct{}, len(asns))
+	for _, asn := range asns {
+		if asn <= 0 {
+			return nil, fmt.Errorf("%d is not an autonomous system number", asn)
+		}
+		set[uint(asn)] = struct{}{}
+	}
+	return set, nil
+}
+
+func (g *geoIP) GetTracingInformation() (string, ext.SpanKindEnum) {
+	return g.name, tracing.SpanKindNoneEnum
+}
+
+func (g *geoIP) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	ctx := middlewares.GetLoggerCtx(req.Context(), g.name, typeName)
+	logger := log.FromContext(ctx)
+
+	clientIP := g.strategy.GetIP(req)
+	loc := g.locate(ctx, clientIP)
+
+	if loc.known() {
+		if logData := accesslog.GetLogData(req); logData != nil {
+			logData.Core[accesslog.GeoCountry] = loc.country
+			logData.Core[accesslog.GeoContinent] = loc.continent
+			logData.Core[accesslog.GeoCity] = loc.city
+			if loc.asn != 0 {
+				logData.Core[accesslog.GeoASN] = loc.asn
+			}
+		}
+	}
+
+	if err := g.authorize(loc); err != nil {
+		msg := fmt.Sprintf("Rejecting IP %s: %v", clientIP, err)
+		logger.Debug(msg)
+		tracing.SetErrorWithEvent(req, msg)
+		reject(ctx, rw)
+		return
+	}
+
+	// The headers sent by the client are removed, so that the services can trust them.
+	var asn string
+	if loc.asn != 0 {
+		asn = strconv.FormatUint(uint64(loc.asn), 10)
+	}
+
+	setHeader(req, g.countryHeader, loc.country)
+	setHeader(req, g.continentHeader, loc.continent)
+	setHeader(req, g.cityHeader, loc.city)
+	setHeader(req, g.asnHeader, asn)
+
+	g.next.ServeHTTP(rw, req)
+}
+
+// locate returns the location of an IP from the databases, which are looked up in order.
+func (g *geoIP) locate(ctx context.Context, clientIP string) location {
+	var loc location
+
+	parsedIP := net.ParseIP(clientIP)
+	if parsedIP == nil {
+		log.FromContext(ctx).Debugf("Unable to parse the source IP %q", clientIP)
+		return loc
+	}
+
+	for _, db := range g.databases {
+		rec, ok, err := db.lookup(parsedIP)
+		if err != nil {
+			log.FromContext(ctx).Debugf("Unable to look up the source IP %s in %s: %v", clientIP, db.file, err)
+			continue
+		}
+
+		if ok {
+			loc.merge(rec)
+		}
+	}
+
+	return loc
+}
+
+// authorize checks a location against the deny lists, then against the allow lists.
+func (g *geoIP) authorize(loc location) error {
+	if _, ok := g.deniedCountries[loc.country]; ok && loc.country != "" {
+		return fmt.Errorf("country %s is denied", loc.country)
+	}
+
+	if _, ok := g.deniedContinents[loc.continent]; ok && loc.continent != "" {
+		return fmt.Errorf("continent %s is denied", loc.continent)
+	}
+
+	if _, ok := g.deniedASNs[loc.asn]; ok {
+		return fmt.Errorf("ASN %d is denied", loc.asn)
+	}
+
+	if len(g.allowedCountries) == 0 && len(g.allowedContinents) == 0 && len(g.allowedASNs) == 0 {
+		return nil
+	}
+
+	if !loc.known() {
+		if g.allowUnknown {
+			return nil
+		}
+		return errors.New("unknown location")
+	}
+
+	if _, ok := g.allowedCountries[loc.country]; ok && loc.country != "" {
+		return nil
+	}
+
+	if _, ok := g.allowedContinents[loc.continent]; ok && loc.continent != "" {
+		return nil
+	}
+
+	if _, ok := g.allowedASNs[loc.asn]; ok {
+		return nil
+	}
+
+	return fmt.Errorf("location %s/%s/%d is not allowed", loc.continent, loc.country, loc.asn)
+}
+
+func setHeader(req *http.Request, name, value string) {
+	if name == "" {
+		return
+	}
+
+	req.Header.Del(name)
+	if value != "" {
+		req.Header.Set(name, value)
+	}
+}
+
+func reject(ctx context.Context, rw http.ResponseWriter) {
+	statusCode := http.StatusForbidden
+
+	rw.WriteHeader(statusCode)
+	_, err := rw.Write([]byte(http.StatusText(statusCode)))
+	if err != nil {
+		log.FromContext(ctx).Error(err)
+	}
+}
@@ -0,0 +1,475 @@
+package geoip
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/traefik/traefik/v2/pkg/config/dynamic"
+	"github.com/traefik/traefik/v2/pkg/filewatcher"
+	"github.com/traefik/traefik/v2/pkg/middlewares/accesslog"
+)
+
+var cityRecords = map[string]interface{}{
+	"81.2.69.0/24": map[string]interface{}{
+		"continent": map[string]interface{}{"code": "EU"},
+		"country":   map[string]interface{}{"iso_code": "FR"},
+		"city":      map[string]interface{}{"names": map[string]interface{}{"en": "Paris", "fr": "Paris"}},
+	},
+	"89.160.20.0/24": map[string]interface{}{
+		"continent": map[string]interface{}{"code": "EU"},
+		"country":   map[string]interface{}{"iso_code": "SE"},
+	},
+	"216.160.83.0/24": map[string]interface{}{
+		"continent":          map[string]interface{}{"code": "NA"},
+		"registered_country": map[string]interface{}{"iso_code": "US"},
+	},
+}
+
+var asnRecords = map[string]interface{}{
+	"81.2.69.0/24": map[string]interface{}{
+		"autonomous_system_number":       uint32(12322),
+		"autonomous_system_organization": "Free SAS",
+	},
+	"1.128.0.0/16": map[string]interface{}{
+		"autonomous_system_number":       uint32(1221),
+		"autonomous_system_organization": "Telstra",
+	},
+}
+
+func TestNew(t *testing.T) {
+	cityFile := writeDatabase(t, cityRecords)
+
+	invalidFile := filepath.Join(t.TempDir(), "invalid.mmdb")
+	require.NoError(t, os.WriteFile(invalidFile, []byte("invalid"), 0o600))
+
+	testCases := []struct {
+		desc          string
+		config        dynamic.GeoIP
+		expectedError bool
+	}{
+		{
+			desc:   "database file",
+			config: dynamic.GeoIP{DatabaseFiles: []string{cityFile}, AllowedCountries: []string{"FR"}},
+		},
+		{
+			desc:          "no database file",
+			config:        dynamic.GeoIP{AllowedCountries: []string{"FR"}},
+			expectedError: true,
+		},
+		{
+			desc:          "missing database file",
+			config:        dynamic.GeoIP{DatabaseFiles: []string{filepath.Join(t.TempDir(), "missing.mmdb")}},
+			expectedError: true,
+		},
+		{
+			desc:          "invalid database file",
+			config:        dynamic.GeoIP{DatabaseFiles: []string{invalidFile}},
+			expectedError: true,
+		},
+		{
+			desc:          "invalid ASN",
+			config:        dynamic.GeoIP{DatabaseFiles: []string{cityFile}, DeniedASNs: []int{-1}},
+			expectedError: true,
+		},
+		{
+			desc: "invalid IP strategy",
+			config: dynamic.GeoIP{
+				DatabaseFiles: []string{cityFile},
+				IPStrategy:    &dynamic.IPStrategy{ExcludedIPs: []string{"foo"}},
+			},
+			expectedError: true,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			next := http.HandlerFunc(func(http.ResponseWriter, *http.Request) {})
+
+			_, err := New(context.Background(), next, test.config, "geoip", filewatcher.NewSharedFiles())
+			if test.expectedError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestGeoIP_ServeHTTP(t *testing.T) {
+	cityFile := writeDatabase(t, cityRecords)
+	asnFile := writeDatabase(t, asnRecords)
+
+	testCases := []struct {
+		desc            string
+		config          dynamic.GeoIP
+		remoteAddr      string
+		xForwardedFor   string
+		expectedStatus  int
+		expectedHeaders map[string]string
+	}{
+		{
+			desc:           "allowed country",
+			config:         dynamic.GeoIP{AllowedCountries: []string{"fr"}},
+			remoteAddr:     "81.2.69.142:1234",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			desc:           "country not allowed",
+			config:         dynamic.GeoIP{AllowedCountries: []string{"FR"}},
+			remoteAddr:     "89.160.20.112:1234",
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			desc:           "allowed continent",
+			config:         dynamic.GeoIP{AllowedContinents: []string{"EU"}},
+			remoteAddr:     "89.160.20.112:1234",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			desc:           "allowed ASN",
+			config:         dynamic.GeoIP{AllowedASNs: []int{1221}},
+			remoteAddr:     "1.128.0.1:1234",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			desc:           "denied country",
+			config:         dynamic.GeoIP{DeniedCountries: []string{"SE"}},
+			remoteAddr:     "89.160.20.112:1234",
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			desc:           "denied registered country",
+			config:         dynamic.GeoIP{DeniedCountries: []string{"US"}},
+			remoteAddr:     "216.160.83.56:1234",
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			desc:           "denied ASN before allowed continent",
+			config:         dynamic.GeoIP{AllowedContinents: []string{"EU"}, DeniedASNs: []int{12322}},
+			remoteAddr:     "81.2.69.142:1234",
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			desc:           "no allow list",
+			config:         dynamic.GeoIP{DeniedCountries: []string{"SE"}},
+			remoteAddr:     "10.0.0.1:1234",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			desc:           "unknown location",
+			config:         dynamic.GeoIP{AllowedCountries: []string{"FR"}},
+			remoteAddr:     "10.0.0.1:1234",
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			desc:           "allowed unknown location",
+			config:         dynamic.GeoIP{AllowedCountries: []string{"FR"}, AllowUnknown: true},
+			remoteAddr:     "10.0.0.1:1234",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			desc:           "IPv6 in IPv4 databases",
+			config:         dynamic.GeoIP{AllowedCountries: []string{"FR"}, AllowUnknown: true},
+			remoteAddr:     "[2001:db8::1]:1234",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			desc: "IP strategy",
+			config: dynamic.GeoIP{
+				AllowedCountries: []string{"FR"},
+				IPStrategy:       &dynamic.IPStrategy{Depth: 1},
+			},
+			remoteAddr:     "10.0.0.1:1234",
+			xForwardedFor:  "89.160.20.112, 81.2.69.142",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			desc: "headers",
+			config: dynamic.GeoIP{
+				CountryHeader:   "X-Geo-Country",
+				ContinentHeader: "X-Geo-Continent",
+				CityHeader:      "X-Geo-City",
+				ASNHeader:       "X-Geo-ASN",
+			},
+			remoteAddr:     "81.2.69.142:1234",
+			expectedStatus: http.StatusOK,
+			expectedHeaders: map[string]string{
+				"X-Geo-Country":   "FR",
+				"X-Geo-Continent": "EU",
+				"X-Geo-City":      "Paris",
+				"X-Geo-ASN":       "12322",
+			},
+		},
+		{
+			desc: "spoofed headers",
+			config: dynamic.GeoIP{
+				CountryHeader: "X-Geo-Country",
+				ASNHeader:     "X-Geo-ASN",
+			},
+			remoteAddr:     "10.0.0.1:1234",
+			expectedStatus: http.StatusOK,
+			expectedHeaders: map[string]string{
+				"X-Geo-Country": "",
+				"X-Geo-ASN":     "",
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			var forwarded *http.Request
+			next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				forwarded = req
+			})
+
+			config := test.config
+			config.DatabaseFiles = []string{cityFile, asnFile}
+
+			handler, err := New(context.Background(), next, config, "geoip", filewatcher.NewSharedFiles())
+			require.NoError(t, err)
+
+			req := httptest.NewRequest(http.MethodGet, "http://localhost", nil)
+			req.RemoteAddr = test.remoteAddr
+			req.Header.Set("X-Geo-Country", "XX")
+			req.Header.Set("X-Geo-ASN", "42")
+			if test.xForwardedFor != "" {
+				req.Header.Set("X-Forwarded-For", test.xForwardedFor)
+			}
+
+			rw := httptest.NewRecorder()
+			handler.ServeHTTP(rw, req)
+
+			assert.Equal(t, test.expectedStatus, rw.Code)
+
+			for name, value := range test.expectedHeaders {
+				require.NotNil(t, forwarded)
+				assert.Equal(t, value, forwarded.Header.Get(name), name)
+			}
+		})
+	}
+}
+
+func TestGeoIP_accessLog(t *testing.T) {
+	cityFile := writeDatabase(t, cityRecords)
+	asnFile := writeDatabase(t, asnRecords)
+
+	next := http.HandlerFunc(func(http.ResponseWriter, *http.Request) {})
+
+	handler, err := New(context.Background(), next, dynamic.GeoIP{DatabaseFiles: []string{cityFile, asnFile}}, "geoip", filewatcher.NewSharedFiles())
+	require.NoError(t, err)
+
+	logData := &accesslog.LogData{Core: accesslog.CoreLogData{}}
+
+	req := httptest.NewRequest(http.MethodGet, "http://localhost", nil)
+	req.RemoteAddr = "81.2.69.142:1234"
+	req = req.WithContext(context.WithValue(req.Context(), accesslog.DataTableKey, logData))
+
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	assert.Equal(t, "FR", logData.Core[accesslog.GeoCountry])
+	assert.Equal(t, "EU", logData.Core[accesslog.GeoContinent])
+	assert.Equal(t, "Paris", logData.Core[accesslog.GeoCity])
+	assert.Equal(t, uint(12322), logData.Core[accesslog.GeoASN])
+}
+
+func TestGeoIP_reload(t *testing.T) {
+	file := writeDatabase(t, cityRecords)
+
+	next := http.HandlerFunc(func(http.ResponseWriter, *http.Request) {})
+
+	handler, err := New(context.Background(), next, dynamic.GeoIP{DatabaseFiles: []string{file}, AllowedCountries: []string{"FR"}}, "geoip", filewatcher.NewSharedFiles())
+	require.NoError(t, err)
+
+	serve := func() int {
+		req := httptest.NewRequest(http.MethodGet, "http://localhost", nil)
+		req.RemoteAddr = "89.160.20.112:1234"
+
+		rw := httptest.NewRecorder()
+		handler.ServeHTTP(rw, req)
+
+		return rw.Code
+	}
+
+	assert.Equal(t, http.StatusForbidden, serve())
+
+	content, err := os.ReadFile(writeDatabase(t, map[string]interface{}{
+		"89.160.20.0/24": map[string]interface{}{
+			"country": map[string]interface{}{"iso_code": "FR"},
+		},
+	}))
+	require.NoError(t, err)
+
+	require.NoError(t, os.WriteFile(file, content, 0o600))
+
+	assert.Eventually(t, func() bool { return serve() == http.StatusOK }, 5*time.Second, 50*time.Millisecond)
+
+	// An invalid database is not loaded, and the previous database is kept.
+	require.NoError(t, os.WriteFile(file, []byte("invalid"), 0o600))
+	time.Sleep(2 * filewatcher.ChangeDelay)
+
+	assert.Equal(t, http.StatusOK, serve())
+}
+
+// writeDatabase writes an IPv4 MaxMind DB file, with a record for each network.
+func writeDatabase(t *testing.T, records map[string]interface{}) string {
+	t.Helper()
+
+	type node struct{ children [2]int }
+
+	const (
+		empty = -1
+		leaf  = -2
+	)
+
+	nodes := []node{{children: [2]int{empty, empty}}}
+	leaves := make(map[[2]int]uint32)
+
+	var data bytes.Buffer
+
+	networks := make([]string, 0, len(records))
+	for network := range records {
+		networks = append(networks, network)
+	}
+	sort.Strings(networks)
+
+	for _, network := range networks {
+		_, ipNet, err := net.ParseCIDR(network)
+		require.NoError(t, err)
+
+		offset := uint32(data.Len())
+		encodeValue(t, &data, records[network])
+
+		ones, _ := ipNet.Mask.Size()
+		ip := ipNet.IP.To4()
+
+		current := 0
+		for i := 0; i < ones; i++ {
+			bit := int(ip[i/8]>>(7-i%8)) & 1
+
+			if i == ones-1 {
+				nodes[current].children[bit] = leaf
+				leaves[[2]int{current, bit}] = offset
+				break
+			}
+
+			if nodes[current].children[bit] < 0 {
+				nodes = append(nodes, node{children: [2]int{empty, empty}})
+				nodes[current].children[bit] = len(nodes) - 1
+			}
+			current = nodes[current].children[bit]
+		}
+	}
+
+	nodeCount := uint32(len(nodes))
+
+	var content bytes.Buffer
+	for i, n := range nodes {
+		for bit, child := range n.children {
+			value := uint32(child)
+			switch child {
+			case empty:
+				value = nodeCount
+			case leaf:
+				value = nodeCount + 16 + leaves[[2]int{i, bit}]
+			}
+			content.Write([]byte{byte(value >> 16), byte(value >> 8), byte(value)})
+		}
+	}
+
+	content.Write(make([]byte, 16))
+	content.Write(data.Bytes())
+	content.WriteString("\xAB\xCD\xEFMaxMind.com")
+
+	encodeValue(t, &content, map[string]interface{}{
+		"binary_format_major_version": uint32(2),
+		"binary_format_minor_version": uint32(0),
+		"build_epoch":                 uint32(time.Now().Unix()),
+		"database_type":               "Test",
+		"description":                 map[string]interface{}{"en": "Test database"},
+		"ip_version":                  uint32(4),
+		"languages":                   []interface{}{"en"},
+		"node_count":                  nodeCount,
+		"record_size":                 uint32(24),
+	})
+
+	file := filepath.Join(t.TempDir(), "test.mmdb")
+	require.NoError(t, os.WriteFile(file, content.Bytes(), 0o600))
+
+	return file
+}
+
+// encodeValue encodes a value in the MaxMind DB data section format.
+func encodeValue(t *testing.T, buf *bytes.Buffer, value interface{}) {
+	t.Helper()
+
+	switch v := value.(type) {
+	case string:
+		encodeControl(buf, 2, len(v))
+		buf.WriteString(v)
+
+	case uint32:
+		var b [4]byte
+		binary.BigEndian.PutUint32(b[:], v)
+		encodeControl(buf, 6, 4)
+		buf.Write(b[:])
+
+	case map[string]interface{}:
+		encodeControl(buf, 7, len(v))
+
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			encodeValue(t, buf, key)
+			encodeValue(t, buf, v[key])
+		}
+
+	case []interface{}:
+		encodeControl(buf, 11, len(v))
+		for _, item := range v {
+			encodeValue(t, buf, item)
+		}
+
+	default:
+		t.Fatalf("unsupported value %T", value)
+	}
+}
+
+func encodeControl(buf *bytes.Buffer, typeNum, size int) {
+	var extended []byte
+	if typeNum > 7 {
+		extended = []byte{byte(typeNum - 7)}
+		typeNum = 0
+	}
+
+	// The test values are always smaller than 285 bytes or items.
+	if size < 29 {
+		buf.WriteByte(byte(typeNum<<5 | size))
+		buf.Write(extended)
+		return
+	}
+
+	buf.WriteByte(byte(typeNum<<5 | 29))
+	buf.Write(extended)
+	buf.WriteByte(byte(size - 29))
+}
@@ -0,0 +1,271 @@
+package ipdenylist
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/opentracing/opentracing-go/ext"
+	"github.com/traefik/traefik/v2/pkg/config/dynamic"
+	"github.com/traefik/traefik/v2/pkg/ip"
+	"github.com/traefik/traefik/v2/pkg/ipban"
+	"github.com/traefik/traefik/v2/pkg/log"
+	"github.com/traefik/traefik/v2/pkg/middlewares"
+	"github.com/traefik/traefik/v2/pkg/tracing"
+	"github.com/traefik/traefik/v2/pkg/types"
+)
+
+const (
+	typeName = "IPDenyLister"
+)
+
+// ipDenyLister is a middleware refusing the requests from denied or banned IPs.
+type ipDenyLister struct {
+	next     http.Handler
+	name     string
+	strategy ip.Strategy
+
+	denied     *ip.Checker
+	sourceList *ip.SourceList
+	banList    *ipban.List
+	ban        *banner
+}
+
+// banner bans the IPs after too many matching responses within a window.
+type banner struct {
+	statusCodes  types.HTTPCodeRanges
+	maxResponses int
+	window       time.Duration
+	duration     time.Duration
+}
+
+// New builds a new IPDenyLister.
+func New(ctx context.Context, next http.Handler, config dynamic.IPDenyList, name string, banManager *ipban.Manager, sourceLists *ip.SourceLists) (http.Handler, error) {
+	logger := log.FromContext(middlewares.GetLoggerCtx(ctx, name, typeName))
+	logger.Debug("Creating middleware")
+
+	if len(config.SourceRange) == 0 && len(config.Sources) == 0 && config.BanList == "" {
+		return nil, errors.New("sourceRange, sources, and banList are empty, IPDenyLister not created")
+	}
+
+	strategy, err := config.IPStrategy.Get()
+	if err != nil {
+		return nil, err
+	}
+
+	dl := &ipDenyLister{
+		next:     next,
+		name:     name,
+		strategy: strategy,
+	}
+
+	if len(config.SourceRange) > 0 {
+		dl.denied, err = ip.NewChecker(config.SourceRange)
+		if err != nil {
+			return nil, fmt.Errorf("cannot parse CIDR deny list %s: %w", config.SourceRange, err)
+		}
+	}
+
+	if len(config.Sources) > 0 {
+		if sourceLists == nil {
+			return nil, errors.New("the IP source lists are not available")
+		}
+
+		refreshInterval := time.Duration(config.RefreshInterval)
+		if refreshInterval == 0 {
+			refreshInterval = 10 * time.Minute
+		}
+
+		dl.sourceList, err = sourceLists.Get(ctx, config.Sources, refreshInterval)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if config.Ban != nil && config.BanList == "" {
+		return nil, errors.New("banList is required to ban the IPs")
+	}
+
+	if config.BanList != "" {
+		if banManager == nil {
+			return nil, errors.New("the ban lists are not available")
+		}
+
+		dl.banList, err = banManager.List(config.BanList, config.BanStore)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if config.Ban != nil {
+		dl.ban, err = newBanner(*config.Ban)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	logger.Debugf("Setting up IPDenyLister with sourceRange: %s, sources: %s, banList: %s", config.SourceRange, config.Sources, config.BanList)
+
+	return dl, nil
+}
+
+func newBanner(config dynamic.IPBan) (*banner, error) {
+	if len(config.StatusCodes) == 0 {
+		return nil, errors.New("the status codes banning the IPs are empty")
+	}
+
+	statusCodes, err := types.NewHTTPCodeRanges(config.StatusCodes)
+	if err != nil {
+		return nil, err
+	}
+
+	b := &banner{
+		statusCodes:  statusCodes,
+		maxResponses: config.MaxResponses,
+		window:       time.Duration(config.Window),
+		duration:     time.Duration(config.Duration),
+	}
+
+	if b.maxResponses == 0 {
+		b.maxResponses = 5
+	}
+	if b.window == 0 {
+		b.window = time.Minute
+	}
+	if b.duration == 0 {
+		b.duration = 10 * time.Minute
+	}
+
+	if b.maxResponses < 0 {
+		return nil, errors.New("maxResponses must be positive")
+	}
+	if b.window < time.Second {
+		return nil, errors.New("the window must be at least 1s")
+	}
+	if b.duration < 0 {
+		return nil, errors.New("the ban duration must be positive")
+	}
+
+	return b, nil
+}
+
+func (dl *ipDenyLister) GetTracingInformation() (string, ext.SpanKindEnum) {
+	return dl.name, tracing.SpanKindNoneEnum
+}
+
+func (dl *ipDenyLister) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	ctx := middlewares.GetLoggerCtx(req.Context(), dl.name, typeName)
+	logger := log.FromContext(ctx)
+
+	clientIP := dl.strategy.GetIP(req)
+	addr := parseIP(clientIP)
+
+	if err := dl.check(ctx, addr); err != nil {
+		msg := fmt.Sprintf("Rejecting IP %s: %v", clientIP, err)
+		logger.Debug(msg)
+		tracing.SetErrorWithEvent(req, msg)
+		reject(ctx, rw)
+		return
+	}
+	logger.Debugf("Accepting IP %s", clientIP)
+
+	// The requests whose IP is unknown are not counted, as the IP cannot be banned.
+	if dl.ban == nil || addr == nil {
+		dl.next.ServeHTTP(rw, req)
+		return
+	}
+
+	recorder := &statusRecorder{ResponseWriter: rw, statusCode: http.StatusOK}
+	dl.next.ServeHTTP(recorder, req)
+
+	if !dl.ban.statusCodes.Contains(recorder.statusCode) {
+		return
+	}
+
+	bannedIP := addr.String()
+	// The responses are counted by the ban list, so that the counts are kept across the configuration changes.
+	if count := dl.banList.Count(ctx, bannedIP, dl.ban.window); count >= dl.ban.maxResponses {
+		reason := fmt.Sprintf("%d responses with a %d status code within %s", count, recorder.statusCode, dl.ban.window)
+		logger.Infof("Banning IP %s for %s: %s", bannedIP, dl.ban.duration, reason)
+		dl.banList.Ban(ctx, bannedIP, dl.ban.duration, reason)
+		dl.banList.ResetCount(ctx, bannedIP)
+	}
+}
+
+// check returns an error if the IP is denied or banned.
+func (dl *ipDenyLister) check(ctx context.Context, addr net.IP) error {
+	if addr == nil {
+		// The requests whose IP is unknown are not denied, as it cannot be matched.
+		return nil
+	}
+
+	if dl.denied != nil && dl.denied.ContainsIP(addr) {
+		return errors.New("IP is in the deny list")
+	}
+
+	if dl.sourceList != nil && dl.sourceList.ContainsIP(ctx, addr) {
+		return errors.New("IP is in the deny list sources")
+	}
+
+	if dl.banList != nil && dl.banList.IsBanned(ctx, addr.String()) {
+		return errors.New("IP is banned")
+	}
+
+	return nil
+}
+
+// parseIP parses the client IP, which may contain a port.
+func parseIP(clientIP string) net.IP {
+	host, _, err := net.SplitHostPort(clientIP)
+	if err != nil {
+		host = clientIP
+	}
+
+	return net.ParseIP(host)
+}
+
+func reject(ctx context.Context, rw http.ResponseWriter) {
+	statusCode := http.StatusForbidden
+
+	rw.WriteHeader(statusCode)
+	_, err := rw.Write([]byte(http.StatusText(statusCode)))
+	if err != nil {
+		log.FromContext(ctx).Error(err)
+	}
+}
+
+// statusRecorder captures the status code of the response.
+type statusRecorder struct {
+	http.ResponseWriter
+	statusCode  int
+	wroteHeader bool
+}
+
+// WriteHeader captures the status code for later retrieval.
+func (r *statusRecorder) WriteHeader(status int) {
+	if !r.wroteHeader {
+		r.statusCode = status
+		r.wroteHeader = true
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+// Hijack hijacks the connection.
+func (r *statusRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := r.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, fmt.Errorf("%T is not a http.Hijacker", r.ResponseWriter)
+	}
+	return hijacker.Hijack()
+}
+
+// Flush sends any buffered data to the client.
+func (r *statusRecorder) Flush() {
+	if f, ok := r.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
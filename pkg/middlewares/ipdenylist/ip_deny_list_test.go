@@ -0,0 +1,282 @@
+package ipdenylist
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	ptypes "github.com/traefik/paerser/types"
+	"github.com/traefik/traefik/v2/pkg/config/dynamic"
+	"github.com/traefik/traefik/v2/pkg/ip"
+	"github.com/traefik/traefik/v2/pkg/ipban"
+)
+
+func TestNewIPDenyLister(t *testing.T) {
+	testCases := []struct {
+		desc          string
+		denyList      dynamic.IPDenyList
+		expectedError bool
+	}{
+		{
+			desc:          "empty config",
+			denyList:      dynamic.IPDenyList{},
+			expectedError: true,
+		},
+		{
+			desc: "invalid IP",
+			denyList: dynamic.IPDenyList{
+				SourceRange: []string{"foo"},
+			},
+			expectedError: true,
+		},
+		{
+			desc: "valid IP",
+			denyList: dynamic.IPDenyList{
+				SourceRange: []string{"10.10.10.10"},
+			},
+		},
+		{
+			desc: "missing source loaded in the background",
+			denyList: dynamic.IPDenyList{
+				Sources: []string{"/does/not/exist"},
+			},
+		},
+		{
+			desc: "ban list",
+			denyList: dynamic.IPDenyList{
+				BanList: "login",
+			},
+		},
+		{
+			desc: "unknown ban store",
+			denyList: dynamic.IPDenyList{
+				BanList:  "login",
+				BanStore: "foo",
+			},
+			expectedError: true,
+		},
+		{
+			desc: "memcached ban store without memcached",
+			denyList: dynamic.IPDenyList{
+				BanList:  "login",
+				BanStore: "memcached",
+			},
+			expectedError: true,
+		},
+		{
+			desc: "ban without ban list",
+			denyList: dynamic.IPDenyList{
+				SourceRange: []string{"10.10.10.10"},
+				Ban:         &dynamic.IPBan{StatusCodes: []string{"401"}},
+			},
+			expectedError: true,
+		},
+		{
+			desc: "ban without status codes",
+			denyList: dynamic.IPDenyList{
+				BanList: "login",
+				Ban:     &dynamic.IPBan{},
+			},
+			expectedError: true,
+		},
+		{
+			desc: "ban with a window shorter than a second",
+			denyList: dynamic.IPDenyList{
+				BanList: "login",
+				Ban: &dynamic.IPBan{
+					StatusCodes: []string{"401"},
+					Window:      ptypes.Duration(500 * time.Millisecond),
+				},
+			},
+			expectedError: true,
+		},
+		{
+			desc: "ban",
+			denyList: dynamic.IPDenyList{
+				BanList: "login",
+				Ban:     &dynamic.IPBan{StatusCodes: []string{"401", "403"}},
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+			denyLister, err := New(context.Background(), next, test.denyList, "traefikTest", ipban.NewManager(nil), ip.NewSourceLists())
+
+			if test.expectedError {
+				assert.Error(t, err)
+			} else {
+				require.NoError(t, err)
+				assert.NotNil(t, denyLister)
+			}
+		})
+	}
+}
+
+func TestIPDenyLister_ServeHTTP(t *testing.T) {
+	source := filepath.Join(t.TempDir(), "denied.txt")
+	err := os.WriteFile(source, []byte("# Denied IPs\n30.30.30.30 ; scanner\n40.40.40.0/24\n"), 0o600)
+	require.NoError(t, err)
+
+	testCases := []struct {
+		desc          string
+		denyList      dynamic.IPDenyList
+		remoteAddr    string
+		xForwardedFor string
+		expected      int
+	}{
+		{
+			desc: "allowed with remote address",
+			denyList: dynamic.IPDenyList{
+				SourceRange: []string{"20.20.20.20"},
+			},
+			remoteAddr: "20.20.20.21:1234",
+			expected:   200,
+		},
+		{
+			desc: "denied with remote address",
+			denyList: dynamic.IPDenyList{
+				SourceRange: []string{"20.20.20.20"},
+			},
+			remoteAddr: "20.20.20.20:1234",
+			expected:   403,
+		},
+		{
+			desc: "denied IP from the sources",
+			denyList: dynamic.IPDenyList{
+				Sources: []string{source},
+			},
+			remoteAddr: "30.30.30.30:1234",
+			expected:   403,
+		},
+		{
+			desc: "denied range from the sources",
+			denyList: dynamic.IPDenyList{
+				Sources: []string{source},
+			},
+			remoteAddr: "40.40.40.40:1234",
+			expected:   403,
+		},
+		{
+			desc: "allowed IP with the sources",
+			denyList: dynamic.IPDenyList{
+				Sources: []string{source},
+			},
+			remoteAddr: "50.50.50.50:1234",
+			expected:   200,
+		},
+		{
+			desc: "denied with X-Forwarded-For",
+			denyList: dynamic.IPDenyList{
+				SourceRange: []string{"20.20.20.20"},
+				IPStrategy:  &dynamic.IPStrategy{Depth: 1},
+			},
+			remoteAddr:    "10.10.10.10:1234",
+			xForwardedFor: "20.20.20.20",
+			expected:      403,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+			denyLister, err := New(context.Background(), next, test.denyList, "traefikTest", ipban.NewManager(nil), ip.NewSourceLists())
+			require.NoError(t, err)
+
+			// The sources are loaded in the background.
+			assert.Eventually(t, func() bool {
+				recorder := httptest.NewRecorder()
+
+				req := httptest.NewRequest(http.MethodGet, "http://10.10.10.10", nil)
+				req.RemoteAddr = test.remoteAddr
+				if test.xForwardedFor != "" {
+					req.Header.Set("X-Forwarded-For", test.xForwardedFor)
+				}
+
+				denyLister.ServeHTTP(recorder, req)
+
+				return recorder.Code == test.expected
+			}, 5*time.Second, 10*time.Millisecond)
+		})
+	}
+}
+
+func TestIPDenyLister_Ban(t *testing.T) {
+	banManager := ipban.NewManager(nil)
+
+	next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if req.Header.Get("Authorization") == "" {
+			rw.WriteHeader(http.StatusUnauthorized)
+		}
+	})
+
+	config := dynamic.IPDenyList{
+		BanList: "login",
+		Ban: &dynamic.IPBan{
+			StatusCodes:  []string{"401"},
+			MaxResponses: 3,
+			Window:       ptypes.Duration(time.Minute),
+			Duration:     ptypes.Duration(time.Minute),
+		},
+	}
+
+	login, err := New(context.Background(), next, config, "login", banManager, ip.NewSourceLists())
+	require.NoError(t, err)
+
+	// Another middleware using the same ban list, without banning the IPs.
+	other, err := New(context.Background(), next, dynamic.IPDenyList{BanList: "login"}, "other", banManager, ip.NewSourceLists())
+	require.NoError(t, err)
+
+	serve := func(handler http.Handler, remoteAddr string, authorized bool) int {
+		req := httptest.NewRequest(http.MethodGet, "http://localhost", nil)
+		req.RemoteAddr = remoteAddr
+		if authorized {
+			req.Header.Set("Authorization", "foo")
+		}
+
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, req)
+		return recorder.Code
+	}
+
+	assert.Equal(t, http.StatusOK, serve(login, "10.0.0.1:1234", true))
+	assert.Equal(t, http.StatusUnauthorized, serve(login, "10.0.0.1:1234", false))
+	assert.Equal(t, http.StatusUnauthorized, serve(login, "10.0.0.1:1234", false))
+	assert.Equal(t, http.StatusUnauthorized, serve(login, "10.0.0.2:1234", false))
+	assert.Empty(t, banManager.Bans())
+
+	// The counts are kept when the middleware is created again by a configuration change.
+	login, err = New(context.Background(), next, config, "login", banManager, ip.NewSourceLists())
+	require.NoError(t, err)
+
+	// The third matching response bans the IP.
+	assert.Equal(t, http.StatusUnauthorized, serve(login, "10.0.0.1:1234", false))
+
+	bans := banManager.Bans()
+	require.Len(t, bans, 1)
+	assert.Equal(t, "login", bans[0].List)
+	assert.Equal(t, "10.0.0.1", bans[0].IP)
+	assert.NotEmpty(t, bans[0].Reason)
+
+	assert.Equal(t, http.StatusForbidden, serve(login, "10.0.0.1:1234", true))
+	assert.Equal(t, http.StatusForbidden, serve(other, "10.0.0.1:1234", true))
+	assert.Equal(t, http.StatusOK, serve(login, "10.0.0.2:1234", true))
+
+	assert.Equal(t, 1, banManager.Clear(context.Background(), "login", "10.0.0.1"))
+	assert.Empty(t, banManager.Bans())
+
+	assert.Equal(t, http.StatusOK, serve(login, "10.0.0.1:1234", true))
+}
@@ -6,7 +6,20 @@ import (
 	"github.com/traefik/traefik/v2/pkg/log"
 )
 
+type routerNameKey struct{}
+
 // GetLoggerCtx creates a logger context with the middleware fields.
 func GetLoggerCtx(ctx context.Context, middleware, middlewareType string) context.Context {
 	return log.With(ctx, log.Str(log.MiddlewareName, middleware), log.Str(log.MiddlewareType, middlewareType))
 }
+
+// AddRouterNameInContext adds the name of the router building the middlewares in the context.
+func AddRouterNameInContext(ctx context.Context, routerName string) context.Context {
+	return context.WithValue(ctx, routerNameKey{}, routerName)
+}
+
+// GetRouterName returns the name of the router building the middlewares, if any.
+func GetRouterName(ctx context.Context) string {
+	name, _ := ctx.Value(routerNameKey{}).(string)
+	return name
+}
@@ -0,0 +1,84 @@
+package tcpaccesslog
+
+import (
+	"context"
+	"net"
+	"sync/atomic"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/traefik/traefik/v2/pkg/config/dynamic"
+	"github.com/traefik/traefik/v2/pkg/log"
+	"github.com/traefik/traefik/v2/pkg/middlewares"
+	"github.com/traefik/traefik/v2/pkg/middlewares/accesslog"
+	"github.com/traefik/traefik/v2/pkg/tcp"
+)
+
+const typeName = "AccessLogTCP"
+
+type accessLog struct {
+	next    tcp.Handler
+	handler *accesslog.Handler
+}
+
+// New creates a connection access log middleware,
+// writing the entries in the access log configured by the static configuration.
+func New(ctx context.Context, next tcp.Handler, _ dynamic.TCPAccessLog, name string, handler *accesslog.Handler) (tcp.Handler, error) {
+	logger := log.FromContext(middlewares.GetLoggerCtx(ctx, name, typeName))
+	logger.Debug("Creating middleware")
+
+	if handler == nil {
+		logger.Warn("The access log is not enabled, the TCP connections are not logged")
+		return next, nil
+	}
+
+	return &accessLog{
+		next:    next,
+		handler: handler,
+	}, nil
+}
+
+// ServeTCP serves the given TCP connection, and logs it once it is closed.
+func (a *accessLog) ServeTCP(conn tcp.WriteCloser) {
+	start := time.Now().UTC()
+	cc := &countingConn{WriteCloser: conn}
+
+	a.next.ServeTCP(cc)
+
+	clientAddr := conn.RemoteAddr().String()
+	clientHost, clientPort, err := net.SplitHostPort(clientAddr)
+	if err != nil {
+		clientHost, clientPort = clientAddr, "-"
+	}
+
+	a.handler.LogTCPConnection(logrus.Fields{
+		accesslog.StartUTC:   start,
+		accesslog.StartLocal: start.Local(),
+		accesslog.Duration:   time.Since(start),
+		accesslog.ClientAddr: clientAddr,
+		accesslog.ClientHost: clientHost,
+		accesslog.ClientPort: clientPort,
+		accesslog.BytesIn:    atomic.LoadInt64(&cc.bytesIn),
+		accesslog.BytesOut:   atomic.LoadInt64(&cc.bytesOut),
+	})
+}
+
+// countingConn counts the bytes transferred on the connection.
+type countingConn struct {
+	tcp.WriteCloser
+
+	bytesIn  int64 // accessed atomically.
+	bytesOut int64 // accessed atomically.
+}
+
+func (c *countingConn) Read(p []byte) (int, error) {
+	n, err := c.WriteCloser.Read(p)
+	atomic.AddInt64(&c.bytesIn, int64(n))
+	return n, err
+}
+
+func (c *countingConn) Write(p []byte) (int, error) {
+	n, err := c.WriteCloser.Write(p)
+	atomic.AddInt64(&c.bytesOut, int64(n))
+	return n, err
+}
@@ -0,0 +1,81 @@
+package tcpaccesslog
+
+import (
+	"context"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/traefik/traefik/v2/pkg/config/dynamic"
+	"github.com/traefik/traefik/v2/pkg/middlewares/accesslog"
+	"github.com/traefik/traefik/v2/pkg/tcp"
+	"github.com/traefik/traefik/v2/pkg/types"
+)
+
+func TestAccessLog_ServeTCP(t *testing.T) {
+	logFilePath := filepath.Join(t.TempDir(), "access.log")
+	handler, err := accesslog.NewHandler(&types.AccessLog{FilePath: logFilePath, Format: accesslog.JSONFormat})
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = handler.Close() })
+
+	next := tcp.HandlerFunc(func(conn tcp.WriteCloser) {
+		received := make([]byte, 5)
+		_, err := io.ReadFull(conn, received)
+		require.NoError(t, err)
+
+		_, err = conn.Write([]byte("pong"))
+		require.NoError(t, err)
+
+		conn.Close()
+	})
+
+	middleware, err := New(context.Background(), next, dynamic.TCPAccessLog{}, "foo", handler)
+	require.NoError(t, err)
+
+	client, server := net.Pipe()
+
+	done := make(chan struct{})
+	go func() {
+		middleware.ServeTCP(pipeConn{Conn: server})
+		close(done)
+	}()
+
+	_, err = client.Write([]byte("hello"))
+	require.NoError(t, err)
+
+	response, err := io.ReadAll(client)
+	require.NoError(t, err)
+	assert.Equal(t, "pong", string(response))
+
+	<-done
+
+	logData, err := os.ReadFile(logFilePath)
+	require.NoError(t, err)
+
+	assert.Contains(t, string(logData), `"BytesIn":5`)
+	assert.Contains(t, string(logData), `"BytesOut":4`)
+	assert.Contains(t, string(logData), `"Duration"`)
+}
+
+func TestNew_accessLogDisabled(t *testing.T) {
+	next := tcp.HandlerFunc(func(conn tcp.WriteCloser) {})
+
+	middleware, err := New(context.Background(), next, dynamic.TCPAccessLog{}, "foo", nil)
+	require.NoError(t, err)
+
+	assert.NotNil(t, middleware)
+	_, ok := middleware.(*accessLog)
+	assert.False(t, ok)
+}
+
+type pipeConn struct {
+	net.Conn
+}
+
+func (c pipeConn) CloseWrite() error {
+	return c.Close()
+}
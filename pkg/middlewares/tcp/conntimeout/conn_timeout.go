@@ -0,0 +1,131 @@
+package tcpconntimeout
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"time"
+
+	"github.com/traefik/traefik/v2/pkg/config/dynamic"
+	"github.com/traefik/traefik/v2/pkg/log"
+	"github.com/traefik/traefik/v2/pkg/middlewares"
+	"github.com/traefik/traefik/v2/pkg/tcp"
+)
+
+const typeName = "ConnTimeoutTCP"
+
+type connTimeout struct {
+	name        string
+	next        tcp.Handler
+	idleTimeout time.Duration
+	maxDuration time.Duration
+}
+
+// New creates a connection timeout middleware.
+func New(ctx context.Context, next tcp.Handler, config dynamic.TCPConnTimeout, name string) (tcp.Handler, error) {
+	logger := log.FromContext(middlewares.GetLoggerCtx(ctx, name, typeName))
+	logger.Debug("Creating middleware")
+
+	idleTimeout := time.Duration(config.IdleTimeout)
+	maxDuration := time.Duration(config.MaxDuration)
+
+	if idleTimeout < 0 || maxDuration < 0 {
+		return nil, errors.New("negative durations are not valid")
+	}
+
+	if idleTimeout == 0 && maxDuration == 0 {
+		return nil, errors.New("at least one of idleTimeout or maxDuration must be defined")
+	}
+
+	return &connTimeout{
+		name:        name,
+		next:        next,
+		idleTimeout: idleTimeout,
+		maxDuration: maxDuration,
+	}, nil
+}
+
+// ServeTCP serves the given TCP connection.
+func (c *connTimeout) ServeTCP(conn tcp.WriteCloser) {
+	tc := &activityConn{WriteCloser: conn}
+	tc.touch()
+
+	done := make(chan struct{})
+	defer close(done)
+
+	go c.watch(tc, done)
+
+	c.next.ServeTCP(tc)
+}
+
+// watch closes the connection when it has been idle, or opened, for too long.
+func (c *connTimeout) watch(conn *activityConn, done <-chan struct{}) {
+	logger := log.FromContext(middlewares.GetLoggerCtx(context.Background(), c.name, typeName))
+
+	var maxDurationCh <-chan time.Time
+	if c.maxDuration > 0 {
+		timer := time.NewTimer(c.maxDuration)
+		defer timer.Stop()
+		maxDurationCh = timer.C
+	}
+
+	var idleTimer *time.Timer
+	var idleCh <-chan time.Time
+	if c.idleTimeout > 0 {
+		idleTimer = time.NewTimer(c.idleTimeout)
+		defer idleTimer.Stop()
+		idleCh = idleTimer.C
+	}
+
+	for {
+		select {
+		case <-done:
+			return
+		case <-maxDurationCh:
+			logger.Debugf("Closing connection from %s: max duration reached", conn.RemoteAddr())
+			conn.Close()
+			return
+		case <-idleCh:
+			idle := conn.idleFor()
+			if idle < c.idleTimeout {
+				idleTimer.Reset(c.idleTimeout - idle)
+				continue
+			}
+
+			logger.Debugf("Closing connection from %s: idle timeout reached", conn.RemoteAddr())
+			conn.Close()
+			return
+		}
+	}
+}
+
+// activityConn records the last time data was transferred on the connection, in either direction.
+type activityConn struct {
+	tcp.WriteCloser
+
+	lastActivity int64 // UnixNano, accessed atomically.
+}
+
+func (c *activityConn) Read(p []byte) (int, error) {
+	n, err := c.WriteCloser.Read(p)
+	if n > 0 {
+		c.touch()
+	}
+	return n, err
+}
+
+func (c *activityConn) Write(p []byte) (int, error) {
+	n, err := c.WriteCloser.Write(p)
+	if n > 0 {
+		c.touch()
+	}
+	return n, err
+}
+
+func (c *activityConn) touch() {
+	atomic.StoreInt64(&c.lastActivity, time.Now().UnixNano())
+}
+
+func (c *activityConn) idleFor() time.Duration {
+	return time.Since(time.Unix(0, atomic.LoadInt64(&c.lastActivity)))
+}
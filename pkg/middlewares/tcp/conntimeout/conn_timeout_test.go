@@ -0,0 +1,86 @@
+package tcpconntimeout
+
+import (
+	"context"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	ptypes "github.com/traefik/paerser/types"
+	"github.com/traefik/traefik/v2/pkg/config/dynamic"
+	"github.com/traefik/traefik/v2/pkg/tcp"
+)
+
+func TestNew(t *testing.T) {
+	next := tcp.HandlerFunc(func(conn tcp.WriteCloser) {})
+
+	_, err := New(context.Background(), next, dynamic.TCPConnTimeout{}, "foo")
+	assert.Error(t, err)
+
+	_, err = New(context.Background(), next, dynamic.TCPConnTimeout{IdleTimeout: ptypes.Duration(-time.Second)}, "foo")
+	assert.Error(t, err)
+
+	_, err = New(context.Background(), next, dynamic.TCPConnTimeout{MaxDuration: ptypes.Duration(time.Second)}, "foo")
+	assert.NoError(t, err)
+}
+
+func TestConnTimeout_idleTimeout(t *testing.T) {
+	middleware, err := New(context.Background(), echoHandler(), dynamic.TCPConnTimeout{
+		IdleTimeout: ptypes.Duration(200 * time.Millisecond),
+	}, "foo")
+	require.NoError(t, err)
+
+	client, server := net.Pipe()
+	go middleware.ServeTCP(pipeConn{Conn: server})
+
+	// Keep the connection active for longer than the idle timeout.
+	for i := 0; i < 5; i++ {
+		_, err = client.Write([]byte("ping"))
+		require.NoError(t, err)
+
+		buf := make([]byte, 4)
+		_, err = io.ReadFull(client, buf)
+		require.NoError(t, err)
+
+		time.Sleep(100 * time.Millisecond)
+	}
+
+	// Once idle, the connection is closed.
+	require.NoError(t, client.SetReadDeadline(time.Now().Add(2*time.Second)))
+	_, err = client.Read(make([]byte, 1))
+	assert.ErrorIs(t, err, io.EOF)
+}
+
+func TestConnTimeout_maxDuration(t *testing.T) {
+	middleware, err := New(context.Background(), echoHandler(), dynamic.TCPConnTimeout{
+		MaxDuration: ptypes.Duration(200 * time.Millisecond),
+	}, "foo")
+	require.NoError(t, err)
+
+	client, server := net.Pipe()
+	go middleware.ServeTCP(pipeConn{Conn: server})
+
+	start := time.Now()
+
+	require.NoError(t, client.SetReadDeadline(time.Now().Add(2*time.Second)))
+	_, err = client.Read(make([]byte, 1))
+	assert.ErrorIs(t, err, io.EOF)
+	assert.GreaterOrEqual(t, time.Since(start), 200*time.Millisecond)
+}
+
+func echoHandler() tcp.Handler {
+	return tcp.HandlerFunc(func(conn tcp.WriteCloser) {
+		_, _ = io.Copy(conn, conn)
+	})
+}
+
+type pipeConn struct {
+	net.Conn
+}
+
+func (c pipeConn) CloseWrite() error {
+	return c.Close()
+}
@@ -0,0 +1,129 @@
+package tcpipdenylist
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/traefik/traefik/v2/pkg/config/dynamic"
+	"github.com/traefik/traefik/v2/pkg/ip"
+	"github.com/traefik/traefik/v2/pkg/ipban"
+	"github.com/traefik/traefik/v2/pkg/log"
+	"github.com/traefik/traefik/v2/pkg/middlewares"
+	"github.com/traefik/traefik/v2/pkg/tcp"
+)
+
+const (
+	typeName = "IPDenyListerTCP"
+)
+
+// ipDenyLister is a middleware refusing the connections from denied or banned IPs.
+type ipDenyLister struct {
+	next tcp.Handler
+	name string
+
+	denied     *ip.Checker
+	sourceList *ip.SourceList
+	banList    *ipban.List
+}
+
+// New builds a new TCP IPDenyLister.
+func New(ctx context.Context, next tcp.Handler, config dynamic.TCPIPDenyList, name string, banManager *ipban.Manager, sourceLists *ip.SourceLists) (tcp.Handler, error) {
+	logger := log.FromContext(middlewares.GetLoggerCtx(ctx, name, typeName))
+	logger.Debug("Creating middleware")
+
+	if len(config.SourceRange) == 0 && len(config.Sources) == 0 && config.BanList == "" {
+		return nil, errors.New("sourceRange, sources, and banList are empty, IPDenyLister not created")
+	}
+
+	dl := &ipDenyLister{
+		next: next,
+		name: name,
+	}
+
+	var err error
+	if len(config.SourceRange) > 0 {
+		dl.denied, err = ip.NewChecker(config.SourceRange)
+		if err != nil {
+			return nil, fmt.Errorf("cannot parse CIDR deny list %s: %w", config.SourceRange, err)
+		}
+	}
+
+	if len(config.Sources) > 0 {
+		if sourceLists == nil {
+			return nil, errors.New("the IP source lists are not available")
+		}
+
+		refreshInterval := time.Duration(config.RefreshInterval)
+		if refreshInterval == 0 {
+			refreshInterval = 10 * time.Minute
+		}
+
+		dl.sourceList, err = sourceLists.Get(ctx, config.Sources, refreshInterval)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if config.BanList != "" {
+		if banManager == nil {
+			return nil, errors.New("the ban lists are not available")
+		}
+
+		dl.banList, err = banManager.List(config.BanList, config.BanStore)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	logger.Debugf("Setting up IPDenyLister with sourceRange: %s, sources: %s, banList: %s", config.SourceRange, config.Sources, config.BanList)
+
+	return dl, nil
+}
+
+func (dl *ipDenyLister) ServeTCP(conn tcp.WriteCloser) {
+	ctx := middlewares.GetLoggerCtx(context.Background(), dl.name, typeName)
+	logger := log.FromContext(ctx)
+
+	addr := conn.RemoteAddr().String()
+
+	err := dl.check(ctx, addr)
+	if err != nil {
+		logger.Debugf("Connection from %s rejected: %v", addr, err)
+		conn.Close()
+		return
+	}
+
+	logger.Debugf("Connection from %s accepted", addr)
+
+	dl.next.ServeTCP(conn)
+}
+
+// check returns an error if the IP is denied or banned.
+func (dl *ipDenyLister) check(ctx context.Context, addr string) error {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		host = addr
+	}
+
+	remoteIP := net.ParseIP(host)
+	if remoteIP == nil {
+		return fmt.Errorf("unable to parse address: %s", addr)
+	}
+
+	if dl.denied != nil && dl.denied.ContainsIP(remoteIP) {
+		return errors.New("IP is in the deny list")
+	}
+
+	if dl.sourceList != nil && dl.sourceList.ContainsIP(ctx, remoteIP) {
+		return errors.New("IP is in the deny list sources")
+	}
+
+	if dl.banList != nil && dl.banList.IsBanned(ctx, remoteIP.String()) {
+		return errors.New("IP is banned")
+	}
+
+	return nil
+}
@@ -0,0 +1,176 @@
+package tcpipdenylist
+
+import (
+	"context"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/traefik/traefik/v2/pkg/config/dynamic"
+	"github.com/traefik/traefik/v2/pkg/ip"
+	"github.com/traefik/traefik/v2/pkg/ipban"
+	"github.com/traefik/traefik/v2/pkg/tcp"
+)
+
+func TestNewIPDenyLister(t *testing.T) {
+	testCases := []struct {
+		desc          string
+		denyList      dynamic.TCPIPDenyList
+		expectedError bool
+	}{
+		{
+			desc:          "Empty config",
+			denyList:      dynamic.TCPIPDenyList{},
+			expectedError: true,
+		},
+		{
+			desc: "invalid IP",
+			denyList: dynamic.TCPIPDenyList{
+				SourceRange: []string{"foo"},
+			},
+			expectedError: true,
+		},
+		{
+			desc: "valid IP",
+			denyList: dynamic.TCPIPDenyList{
+				SourceRange: []string{"10.10.10.10"},
+			},
+		},
+		{
+			desc: "ban list",
+			denyList: dynamic.TCPIPDenyList{
+				BanList: "login",
+			},
+		},
+		{
+			desc: "unknown ban store",
+			denyList: dynamic.TCPIPDenyList{
+				BanList:  "login",
+				BanStore: "foo",
+			},
+			expectedError: true,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			next := tcp.HandlerFunc(func(conn tcp.WriteCloser) {})
+			denyLister, err := New(context.Background(), next, test.denyList, "traefikTest", ipban.NewManager(nil), ip.NewSourceLists())
+
+			if test.expectedError {
+				assert.Error(t, err)
+			} else {
+				require.NoError(t, err)
+				assert.NotNil(t, denyLister)
+			}
+		})
+	}
+}
+
+func TestIPDenyLister_ServeTCP(t *testing.T) {
+	testCases := []struct {
+		desc       string
+		denyList   dynamic.TCPIPDenyList
+		remoteAddr string
+		expected   string
+	}{
+		{
+			desc: "allowed with remote address",
+			denyList: dynamic.TCPIPDenyList{
+				SourceRange: []string{"20.20.20.20"},
+			},
+			remoteAddr: "20.20.20.21:1234",
+			expected:   "OK",
+		},
+		{
+			desc: "denied with remote address",
+			denyList: dynamic.TCPIPDenyList{
+				SourceRange: []string{"20.20.20.20"},
+			},
+			remoteAddr: "20.20.20.20:1234",
+		},
+		{
+			desc: "allowed with ban list",
+			denyList: dynamic.TCPIPDenyList{
+				BanList: "login",
+			},
+			remoteAddr: "20.20.20.21:1234",
+			expected:   "OK",
+		},
+		{
+			desc: "banned",
+			denyList: dynamic.TCPIPDenyList{
+				BanList: "login",
+			},
+			remoteAddr: "30.30.30.30:1234",
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			banManager := ipban.NewManager(nil)
+			banList, err := banManager.List("login", "")
+			require.NoError(t, err)
+			banList.Ban(context.Background(), "30.30.30.30", time.Minute, "test")
+
+			next := tcp.HandlerFunc(func(conn tcp.WriteCloser) {
+				write, err := conn.Write([]byte("OK"))
+				require.NoError(t, err)
+				assert.Equal(t, 2, write)
+
+				err = conn.Close()
+				require.NoError(t, err)
+			})
+
+			denyLister, err := New(context.Background(), next, test.denyList, "traefikTest", banManager, ip.NewSourceLists())
+			require.NoError(t, err)
+
+			server, client := net.Pipe()
+
+			go func() {
+				denyLister.ServeTCP(&contextWriteCloser{client, addr{test.remoteAddr}})
+			}()
+
+			read, err := io.ReadAll(server)
+			require.NoError(t, err)
+
+			assert.Equal(t, test.expected, string(read))
+		})
+	}
+}
+
+type contextWriteCloser struct {
+	net.Conn
+	addr
+}
+
+type addr struct {
+	remoteAddr string
+}
+
+func (a addr) Network() string {
+	panic("implement me")
+}
+
+func (a addr) String() string {
+	return a.remoteAddr
+}
+
+func (c contextWriteCloser) CloseWrite() error {
+	panic("implement me")
+}
+
+func (c contextWriteCloser) RemoteAddr() net.Addr { return c.addr }
+
+func (c contextWriteCloser) Context() context.Context {
+	return context.Background()
+}
@@ -0,0 +1,56 @@
+package tcpmaxconn
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+
+	"github.com/traefik/traefik/v2/pkg/config/dynamic"
+	"github.com/traefik/traefik/v2/pkg/log"
+	"github.com/traefik/traefik/v2/pkg/middlewares"
+	"github.com/traefik/traefik/v2/pkg/tcp"
+)
+
+const typeName = "MaxConnTCP"
+
+type maxConn struct {
+	name           string
+	next           tcp.Handler
+	maxConnections int64
+
+	connections int64 // current number of connections, accessed atomically.
+}
+
+// New creates a max connections middleware.
+// Contrary to the InFlightConn middleware, the connections are not grouped by remote IP,
+// so that the limit applies to all the connections handled by the router.
+func New(ctx context.Context, next tcp.Handler, config dynamic.TCPMaxConn, name string) (tcp.Handler, error) {
+	logger := log.FromContext(middlewares.GetLoggerCtx(ctx, name, typeName))
+	logger.Debug("Creating middleware")
+
+	if config.Amount <= 0 {
+		return nil, errors.New("amount must be strictly positive")
+	}
+
+	return &maxConn{
+		name:           name,
+		next:           next,
+		maxConnections: config.Amount,
+	}, nil
+}
+
+// ServeTCP serves the given TCP connection.
+func (m *maxConn) ServeTCP(conn tcp.WriteCloser) {
+	if atomic.AddInt64(&m.connections, 1) > m.maxConnections {
+		atomic.AddInt64(&m.connections, -1)
+
+		ctx := middlewares.GetLoggerCtx(context.Background(), m.name, typeName)
+		log.FromContext(ctx).Errorf("Connection from %s rejected: max number of connections reached", conn.RemoteAddr())
+		conn.Close()
+		return
+	}
+
+	defer atomic.AddInt64(&m.connections, -1)
+
+	m.next.ServeTCP(conn)
+}
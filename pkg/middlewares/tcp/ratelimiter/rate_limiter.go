@@ -0,0 +1,113 @@
+package tcpratelimiter
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/mailgun/ttlmap"
+	"github.com/traefik/traefik/v2/pkg/config/dynamic"
+	"github.com/traefik/traefik/v2/pkg/log"
+	"github.com/traefik/traefik/v2/pkg/middlewares"
+	"github.com/traefik/traefik/v2/pkg/tcp"
+	"golang.org/x/time/rate"
+)
+
+const (
+	typeName   = "RateLimiterTCP"
+	maxSources = 65536
+)
+
+// rateLimiter limits the rate of new connections with a set of token buckets,
+// one for each client IP. The same parameters are applied to all the buckets.
+type rateLimiter struct {
+	name  string
+	next  tcp.Handler
+	rate  rate.Limit // connections/s
+	burst int
+	// Each bucket is "garbage collected" when it has not been used for ttl seconds,
+	// to keep the buckets ttlmap constrained in size.
+	ttl int
+
+	buckets *ttlmap.TtlMap // actual buckets, keyed by client IP.
+}
+
+// New creates a connection rate limiter middleware.
+func New(ctx context.Context, next tcp.Handler, config dynamic.TCPRateLimit, name string) (tcp.Handler, error) {
+	logger := log.FromContext(middlewares.GetLoggerCtx(ctx, name, typeName))
+	logger.Debug("Creating middleware")
+
+	if config.Average <= 0 {
+		return nil, fmt.Errorf("average must be strictly positive, got %d", config.Average)
+	}
+
+	period := time.Duration(config.Period)
+	if period < 0 {
+		return nil, fmt.Errorf("negative value not valid for period: %v", period)
+	}
+	if period == 0 {
+		period = time.Second
+	}
+
+	burst := config.Burst
+	if burst < 1 {
+		burst = 1
+	}
+
+	buckets, err := ttlmap.NewConcurrent(maxSources)
+	if err != nil {
+		return nil, err
+	}
+
+	connRate := rate.Limit(float64(config.Average) * float64(time.Second) / float64(period))
+
+	// The time needed for an empty bucket to be full again, after which it is not useful anymore.
+	ttl := int(float64(burst)/float64(connRate)) + 1
+
+	return &rateLimiter{
+		name:    name,
+		next:    next,
+		rate:    connRate,
+		burst:   int(burst),
+		ttl:     ttl,
+		buckets: buckets,
+	}, nil
+}
+
+// ServeTCP serves the given TCP connection.
+func (rl *rateLimiter) ServeTCP(conn tcp.WriteCloser) {
+	ctx := middlewares.GetLoggerCtx(context.Background(), rl.name, typeName)
+	logger := log.FromContext(ctx)
+
+	ip, _, err := net.SplitHostPort(conn.RemoteAddr().String())
+	if err != nil {
+		logger.Errorf("Cannot parse IP from remote addr: %v", err)
+		conn.Close()
+		return
+	}
+
+	var bucket *rate.Limiter
+	if rlSource, exists := rl.buckets.Get(ip); exists {
+		bucket = rlSource.(*rate.Limiter)
+	} else {
+		bucket = rate.NewLimiter(rl.rate, rl.burst)
+	}
+
+	// We Set even in the case where the source already exists,
+	// because we want to update the expiryTime everytime we get the source,
+	// as the expiryTime is supposed to reflect the activity (or lack thereof) on that source.
+	if err := rl.buckets.Set(ip, bucket, rl.ttl); err != nil {
+		logger.Errorf("Could not insert/update bucket: %v", err)
+		conn.Close()
+		return
+	}
+
+	if !bucket.Allow() {
+		logger.Debugf("Connection from %s rejected: rate limit exceeded", ip)
+		conn.Close()
+		return
+	}
+
+	rl.next.ServeTCP(conn)
+}
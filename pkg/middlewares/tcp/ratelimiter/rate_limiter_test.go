@@ -0,0 +1,112 @@
+package tcpratelimiter
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	ptypes "github.com/traefik/paerser/types"
+	"github.com/traefik/traefik/v2/pkg/config/dynamic"
+	"github.com/traefik/traefik/v2/pkg/tcp"
+)
+
+func TestNew(t *testing.T) {
+	testCases := []struct {
+		desc        string
+		config      dynamic.TCPRateLimit
+		expectedErr bool
+	}{
+		{
+			desc:   "valid configuration",
+			config: dynamic.TCPRateLimit{Average: 10, Period: ptypes.Duration(time.Second), Burst: 5},
+		},
+		{
+			desc:        "zero average",
+			config:      dynamic.TCPRateLimit{Average: 0},
+			expectedErr: true,
+		},
+		{
+			desc:        "negative period",
+			config:      dynamic.TCPRateLimit{Average: 1, Period: ptypes.Duration(-time.Second)},
+			expectedErr: true,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := New(context.Background(), tcp.HandlerFunc(func(conn tcp.WriteCloser) {}), test.config, "foo")
+			if test.expectedErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestRateLimiter_ServeTCP(t *testing.T) {
+	var served int
+	next := tcp.HandlerFunc(func(conn tcp.WriteCloser) {
+		served++
+	})
+
+	// One connection per hour, with a burst of 2.
+	middleware, err := New(context.Background(), next, dynamic.TCPRateLimit{
+		Average: 1,
+		Period:  ptypes.Duration(time.Hour),
+		Burst:   2,
+	}, "foo")
+	require.NoError(t, err)
+
+	var closed int
+	for i := 0; i < 3; i++ {
+		middleware.ServeTCP(&fakeConn{addr: "127.0.0.1:9000", onClose: func() { closed++ }})
+	}
+
+	assert.Equal(t, 2, served)
+	assert.Equal(t, 1, closed)
+
+	// Another source has its own bucket.
+	middleware.ServeTCP(&fakeConn{addr: "127.0.0.2:9000", onClose: func() { closed++ }})
+
+	assert.Equal(t, 3, served)
+	assert.Equal(t, 1, closed)
+}
+
+type fakeConn struct {
+	net.Conn
+
+	addr    string
+	onClose func()
+}
+
+func (c *fakeConn) RemoteAddr() net.Addr {
+	return fakeAddr{addr: c.addr}
+}
+
+func (c *fakeConn) Close() error {
+	c.onClose()
+	return nil
+}
+
+func (c *fakeConn) CloseWrite() error {
+	panic("implement me")
+}
+
+type fakeAddr struct {
+	addr string
+}
+
+func (a fakeAddr) Network() string {
+	return "tcp"
+}
+
+func (a fakeAddr) String() string {
+	return a.addr
+}
@@ -0,0 +1,140 @@
+package udpipwhitelist
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/traefik/traefik/v2/pkg/config/dynamic"
+	"github.com/traefik/traefik/v2/pkg/udp"
+)
+
+func TestNewIPWhiteLister(t *testing.T) {
+	testCases := []struct {
+		desc          string
+		whiteList     dynamic.UDPIPWhiteList
+		expectedError bool
+	}{
+		{
+			desc:          "Empty IP Whitelist",
+			whiteList:     dynamic.UDPIPWhiteList{},
+			expectedError: true,
+		},
+		{
+			desc: "invalid IP",
+			whiteList: dynamic.UDPIPWhiteList{
+				SourceRange: []string{"foo"},
+			},
+			expectedError: true,
+		},
+		{
+			desc: "valid IP",
+			whiteList: dynamic.UDPIPWhiteList{
+				SourceRange: []string{"10.10.10.10"},
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			next := udp.HandlerFunc(func(conn *udp.Conn) {})
+			whiteLister, err := New(context.Background(), next, test.whiteList, "traefikTest")
+
+			if test.expectedError {
+				assert.Error(t, err)
+			} else {
+				require.NoError(t, err)
+				assert.NotNil(t, whiteLister)
+			}
+		})
+	}
+}
+
+func TestIPWhiteLister_ServeUDP(t *testing.T) {
+	testCases := []struct {
+		desc      string
+		whiteList dynamic.UDPIPWhiteList
+		accepted  bool
+	}{
+		{
+			desc: "authorized with remote address",
+			whiteList: dynamic.UDPIPWhiteList{
+				SourceRange: []string{"127.0.0.1"},
+			},
+			accepted: true,
+		},
+		{
+			desc: "non authorized with remote address",
+			whiteList: dynamic.UDPIPWhiteList{
+				SourceRange: []string{"10.0.0.0/8"},
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			next := udp.HandlerFunc(func(conn *udp.Conn) {
+				b := make([]byte, 1024)
+				n, err := conn.Read(b)
+				require.NoError(t, err)
+
+				_, err = conn.Write(b[:n])
+				require.NoError(t, err)
+			})
+
+			whiteLister, err := New(context.Background(), next, test.whiteList, "traefikTest")
+			require.NoError(t, err)
+
+			addr := serve(t, whiteLister)
+
+			clientConn, err := net.Dial("udp", addr)
+			require.NoError(t, err)
+			t.Cleanup(func() { _ = clientConn.Close() })
+
+			_, err = clientConn.Write([]byte("ping"))
+			require.NoError(t, err)
+
+			require.NoError(t, clientConn.SetReadDeadline(time.Now().Add(500*time.Millisecond)))
+
+			b := make([]byte, 1024)
+			n, err := clientConn.Read(b)
+			if !test.accepted {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, "ping", string(b[:n]))
+		})
+	}
+}
+
+func serve(t *testing.T, handler udp.Handler) string {
+	t.Helper()
+
+	listener, err := udp.Listen("udp", &net.UDPAddr{IP: net.ParseIP("127.0.0.1")}, 3*time.Second)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+
+			go handler.ServeUDP(conn)
+		}
+	}()
+
+	return listener.Addr().String()
+}
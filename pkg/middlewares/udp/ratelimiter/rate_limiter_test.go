@@ -0,0 +1,110 @@
+package udpratelimiter
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	ptypes "github.com/traefik/paerser/types"
+	"github.com/traefik/traefik/v2/pkg/config/dynamic"
+	"github.com/traefik/traefik/v2/pkg/udp"
+)
+
+func TestNew(t *testing.T) {
+	testCases := []struct {
+		desc        string
+		config      dynamic.UDPRateLimit
+		expectedErr bool
+	}{
+		{
+			desc:   "valid configuration",
+			config: dynamic.UDPRateLimit{Average: 10, Period: ptypes.Duration(time.Second), Burst: 5},
+		},
+		{
+			desc:        "zero average",
+			config:      dynamic.UDPRateLimit{Average: 0},
+			expectedErr: true,
+		},
+		{
+			desc:        "negative period",
+			config:      dynamic.UDPRateLimit{Average: 1, Period: ptypes.Duration(-time.Second)},
+			expectedErr: true,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := New(context.Background(), udp.HandlerFunc(func(conn *udp.Conn) {}), test.config, "foo")
+			if test.expectedErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestRateLimiter_ServeUDP(t *testing.T) {
+	next := udp.HandlerFunc(func(conn *udp.Conn) {
+		b := make([]byte, 1024)
+		for {
+			n, err := conn.Read(b)
+			if err != nil {
+				return
+			}
+
+			_, err = conn.Write(b[:n])
+			require.NoError(t, err)
+		}
+	})
+
+	// One packet per hour, with a burst of 2.
+	rateLimiter, err := New(context.Background(), next, dynamic.UDPRateLimit{
+		Average: 1,
+		Period:  ptypes.Duration(time.Hour),
+		Burst:   2,
+	}, "foo")
+	require.NoError(t, err)
+
+	listener, err := udp.Listen("udp", &net.UDPAddr{IP: net.ParseIP("127.0.0.1")}, 3*time.Second)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+
+			go rateLimiter.ServeUDP(conn)
+		}
+	}()
+
+	clientConn, err := net.Dial("udp", listener.Addr().String())
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = clientConn.Close() })
+
+	b := make([]byte, 1024)
+	for i := 0; i < 3; i++ {
+		_, err = clientConn.Write([]byte("ping"))
+		require.NoError(t, err)
+
+		require.NoError(t, clientConn.SetReadDeadline(time.Now().Add(500*time.Millisecond)))
+
+		_, err = clientConn.Read(b)
+		if i < 2 {
+			require.NoError(t, err)
+			continue
+		}
+
+		// The third packet exceeds the burst, and is dropped.
+		assert.Error(t, err)
+	}
+}
@@ -0,0 +1,387 @@
+package validation
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/xeipuuv/gojsonschema"
+	"gopkg.in/yaml.v3"
+)
+
+// methods are the operations of an OpenAPI path item, with their HTTP method.
+var methods = map[string]string{
+	"get":     http.MethodGet,
+	"put":     http.MethodPut,
+	"post":    http.MethodPost,
+	"delete":  http.MethodDelete,
+	"options": http.MethodOptions,
+	"head":    http.MethodHead,
+	"patch":   http.MethodPatch,
+	"trace":   http.MethodTrace,
+}
+
+var pathParameter = regexp.MustCompile(`\{[^{}/]+\}`)
+
+// requestBody describes the bodies accepted by an operation.
+type requestBody struct {
+	required bool
+	// content holds the schemas of the bodies by media range, such as application/json or application/*.
+	// The schema is nil when the bodies of the media range are not validated.
+	content map[string]*gojsonschema.Schema
+}
+
+// schema returns the schema of the bodies with the given media type,
+// from the most specific media range matching it, and whether the media type is accepted.
+func (b *requestBody) schema(mediaType string) (*gojsonschema.Schema, bool) {
+	if schema, ok := b.content[mediaType]; ok {
+		return schema, true
+	}
+
+	typ, subtype, _ := strings.Cut(mediaType, "/")
+
+	if i := strings.LastIndexByte(subtype, '+'); i >= 0 {
+		if schema, ok := b.content[typ+"/*"+subtype[i:]]; ok {
+			return schema, true
+		}
+	}
+
+	if schema, ok := b.content[typ+"/*"]; ok {
+		return schema, true
+	}
+
+	schema, ok := b.content["*/*"]
+	return schema, ok
+}
+
+// operation is an OpenAPI operation with a request body.
+type operation struct {
+	method     string
+	path       *regexp.Regexp
+	parameters int
+	body       *requestBody
+}
+
+// openAPI holds the operations of an OpenAPI document.
+type openAPI struct {
+	basePaths  []string
+	operations []operation
+}
+
+// newOpenAPI parses an OpenAPI 3 document, and compiles the schemas of the JSON request bodies of its operations.
+func newOpenAPI(content []byte) (*openAPI, error) {
+	document, err := parseDocument(content)
+	if err != nil {
+		return nil, err
+	}
+
+	root, ok := document.(map[string]interface{})
+	if !ok {
+		return nil, errors.New("the document is not an object")
+	}
+
+	version, _ := root["openapi"].(string)
+	if !strings.HasPrefix(version, "3.") {
+		return nil, fmt.Errorf("unsupported OpenAPI version %q", version)
+	}
+
+	convertNullable(root)
+
+	api := &openAPI{basePaths: basePaths(root)}
+
+	paths, _ := root["paths"].(map[string]interface{})
+	for _, path := range sortedKeys(paths) {
+		item, _, err := resolve(root, paths[path], "#/paths/"+escapePointer(path))
+		if err != nil {
+			return nil, err
+		}
+
+		pattern, err := compilePath(path)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, name := range sortedKeys(item) {
+			method, ok := methods[name]
+			if !ok {
+				continue
+			}
+
+			op, ok := item[name].(map[string]interface{})
+			if !ok || op["requestBody"] == nil {
+				continue
+			}
+
+			body, err := compileRequestBody(root, op["requestBody"], "#/paths/"+escapePointer(path)+"/"+name+"/requestBody")
+			if err != nil {
+				return nil, fmt.Errorf("%s %s: %w", method, path, err)
+			}
+
+			api.operations = append(api.operations, operation{
+				method:     method,
+				path:       pattern,
+				parameters: len(pathParameter.FindAllString(path, -1)),
+				body:       body,
+			})
+		}
+	}
+
+	// The concrete paths are matched before the templated ones.
+	sort.SliceStable(api.operations, func(i, j int) bool {
+		return api.operations[i].parameters < api.operations[j].parameters
+	})
+
+	return api, nil
+}
+
+// requestBody returns the request body of the operation matching the request,
+// or nil if no operation with a request body matches it.
+func (a *openAPI) requestBody(req *http.Request) *requestBody {
+	for _, basePath := range a.basePaths {
+		path := req.URL.Path
+		if basePath != "" {
+			if path != basePath && !strings.HasPrefix(path, basePath+"/") {
+				continue
+			}
+			path = strings.TrimPrefix(path, basePath)
+		}
+
+		if path == "" {
+			path = "/"
+		}
+
+		for _, op := range a.operations {
+			if op.method == req.Method && op.path.MatchString(path) {
+				return op.body
+			}
+		}
+	}
+
+	return nil
+}
+
+// basePaths returns the paths of the server URLs of the document, which prefix the paths of the operations.
+func basePaths(root map[string]interface{}) []string {
+	servers, _ := root["servers"].([]interface{})
+
+	var paths []string
+	seen := make(map[string]struct{})
+
+	for _, raw := range servers {
+		server, _ := raw.(map[string]interface{})
+		serverURL, _ := server["url"].(string)
+
+		// The server variables are replaced by their default value.
+		variables, _ := server["variables"].(map[string]interface{})
+		for name, raw := range variables {
+			variable, _ := raw.(map[string]interface{})
+			if def, ok := variable["default"].(string); ok {
+				serverURL = strings.ReplaceAll(serverURL, "{"+name+"}", def)
+			}
+		}
+
+		u, err := url.Parse(serverURL)
+		if err != nil {
+			continue
+		}
+
+		path := strings.TrimSuffix(u.Path, "/")
+		if _, ok := seen[path]; !ok {
+			seen[path] = struct{}{}
+			paths = append(paths, path)
+		}
+	}
+
+	if len(paths) == 0 {
+		return []string{""}
+	}
+
+	// The longest base paths are matched first.
+	sort.SliceStable(paths, func(i, j int) bool {
+		return len(paths[i]) > len(paths[j])
+	})
+
+	return paths
+}
+
+// compilePath compiles a path template, such as /pets/{petId}, into a regular expression.
+func compilePath(path string) (*regexp.Regexp, error) {
+	var pattern strings.Builder
+	pattern.WriteString("^")
+
+	last := 0
+	for _, loc := range pathParameter.FindAllStringIndex(path, -1) {
+		pattern.WriteString(regexp.QuoteMeta(path[last:loc[0]]))
+		pattern.WriteString("[^/]+")
+		last = loc[1]
+	}
+
+	pattern.WriteString(regexp.QuoteMeta(path[last:]))
+	pattern.WriteString("$")
+
+	return regexp.Compile(pattern.String())
+}
+
+// compileRequestBody compiles the schemas of the JSON media types of a request body object.
+func compileRequestBody(root map[string]interface{}, raw interface{}, pointer string) (*requestBody, error) {
+	object, pointer, err := resolve(root, raw, pointer)
+	if err != nil {
+		return nil, err
+	}
+
+	required, _ := object["required"].(bool)
+	body := &requestBody{
+		required: required,
+		content:  make(map[string]*gojsonschema.Schema),
+	}
+
+	content, _ := object["content"].(map[string]interface{})
+	for mediaRange, raw := range content {
+		mediaType, _ := raw.(map[string]interface{})
+
+		key := strings.ToLower(mediaRange)
+		if i := strings.IndexByte(key, ';'); i >= 0 {
+			key = strings.TrimSpace(key[:i])
+		}
+
+		if !isJSON(key) || mediaType["schema"] == nil {
+			body.content[key] = nil
+			continue
+		}
+
+		// The schema is compiled from the whole document, so that its references are resolved.
+		document := make(map[string]interface{}, len(root)+1)
+		for k, v := range root {
+			document[k] = v
+		}
+		document["$ref"] = pointer + "/content/" + escapePointer(mediaRange) + "/schema"
+
+		schema, err := gojsonschema.NewSchema(gojsonschema.NewGoLoader(document))
+		if err != nil {
+			return nil, fmt.Errorf("compiling the schema of %s: %w", mediaRange, err)
+		}
+
+		body.content[key] = schema
+	}
+
+	return body, nil
+}
+
+// resolve follows the local reference of an object, if any, and returns the referenced object with its JSON pointer.
+func resolve(root map[string]interface{}, raw interface{}, pointer string) (map[string]interface{}, string, error) {
+	for i := 0; i < 10; i++ {
+		object, ok := raw.(map[string]interface{})
+		if !ok {
+			return nil, "", fmt.Errorf("%s is not an object", pointer)
+		}
+
+		ref, ok := object["$ref"].(string)
+		if !ok {
+			return object, pointer, nil
+		}
+
+		if !strings.HasPrefix(ref, "#/") {
+			return nil, "", fmt.Errorf("unsupported reference %q: only the local references are supported", ref)
+		}
+
+		raw = root
+		for _, token := range strings.Split(ref[2:], "/") {
+			token = strings.NewReplacer("~1", "/", "~0", "~").Replace(token)
+
+			parent, ok := raw.(map[string]interface{})
+			if !ok {
+				return nil, "", fmt.Errorf("unresolved reference %q", ref)
+			}
+
+			if raw, ok = parent[token]; !ok {
+				return nil, "", fmt.Errorf("unresolved reference %q", ref)
+			}
+		}
+
+		pointer = ref
+	}
+
+	return nil, "", fmt.Errorf("too many references from %s", pointer)
+}
+
+// parseDocument parses a JSON or YAML document into JSON values.
+func parseDocument(content []byte) (interface{}, error) {
+	var document interface{}
+	if err := yaml.Unmarshal(content, &document); err != nil {
+		return nil, err
+	}
+
+	return normalize(document), nil
+}
+
+// normalize converts the YAML maps into JSON objects, with string keys.
+func normalize(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, val := range v {
+			v[key] = normalize(val)
+		}
+		return v
+
+	case map[interface{}]interface{}:
+		object := make(map[string]interface{}, len(v))
+		for key, val := range v {
+			object[fmt.Sprint(key)] = normalize(val)
+		}
+		return object
+
+	case []interface{}:
+		for i, val := range v {
+			v[i] = normalize(val)
+		}
+		return v
+
+	default:
+		return v
+	}
+}
+
+// convertNullable converts the OpenAPI 3.0 nullable schemas, such as {type: string, nullable: true},
+// into JSON Schemas accepting the null value, such as {type: [string, "null"]}.
+func convertNullable(value interface{}) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if nullable, _ := v["nullable"].(bool); nullable {
+			if typ, ok := v["type"].(string); ok {
+				v["type"] = []interface{}{typ, "null"}
+			}
+		}
+
+		for _, val := range v {
+			convertNullable(val)
+		}
+
+	case []interface{}:
+		for _, val := range v {
+			convertNullable(val)
+		}
+	}
+}
+
+// escapePointer escapes a JSON pointer token.
+func escapePointer(token string) string {
+	return strings.NewReplacer("~", "~0", "/", "~1").Replace(token)
+}
+
+// isJSON reports whether a media type, or a media range, is a JSON media type.
+func isJSON(mediaType string) bool {
+	return mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
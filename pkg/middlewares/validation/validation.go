@@ -0,0 +1,273 @@
+package validation
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/opentracing/opentracing-go/ext"
+	"github.com/traefik/traefik/v2/pkg/config/dynamic"
+	"github.com/traefik/traefik/v2/pkg/log"
+	"github.com/traefik/traefik/v2/pkg/middlewares"
+	"github.com/traefik/traefik/v2/pkg/tracing"
+	"github.com/xeipuuv/gojsonschema"
+)
+
+const typeName = "RequestValidation"
+
+// validation is a middleware validating the JSON request bodies.
+type validation struct {
+	next        http.Handler
+	name        string
+	maxBodySize int64
+
+	// body is the request body of all the requests, when validating against a JSON Schema.
+	body *requestBody
+	// api holds the request bodies of the operations, when validating against an OpenAPI document.
+	api *openAPI
+}
+
+// New creates a request validation middleware.
+func New(ctx context.Context, next http.Handler, config dynamic.RequestValidation, name string) (http.Handler, error) {
+	log.FromContext(middlewares.GetLoggerCtx(ctx, name, typeName)).Debug("Creating middleware")
+
+	if config.MaxBodySize <= 0 {
+		return nil, errors.New("maxBodySize must be greater than 0")
+	}
+
+	schema, err := readSource(config.Schema, config.SchemaFile, "schema")
+	if err != nil {
+		return nil, err
+	}
+
+	document, err := readSource(config.OpenAPI, config.OpenAPIFile, "openAPI")
+	if err != nil {
+		return nil, err
+	}
+
+	v := &validation{
+		next:        next,
+		name:        name,
+		maxBodySize: config.MaxBodySize,
+	}
+
+	switch {
+	case schema != nil && document != nil:
+		return nil, errors.New("schema and openAPI are mutually exclusive")
+
+	case schema != nil:
+		v.body, err = newSchemaBody(schema)
+		if err != nil {
+			return nil, fmt.Errorf("invalid schema: %w", err)
+		}
+
+	case document != nil:
+		v.api, err = newOpenAPI(document)
+		if err != nil {
+			return nil, fmt.Errorf("invalid OpenAPI document: %w", err)
+		}
+
+	default:
+		return nil, errors.New("a schema or an OpenAPI document is required")
+	}
+
+	return v, nil
+}
+
+// readSource returns the content of an inline option, or of its file option.
+func readSource(content, file, option string) ([]byte, error) {
+	switch {
+	case content != "" && file != "":
+		return nil, fmt.Errorf("%s and %sFile are mutually exclusive", option, option)
+
+	case content != "":
+		return []byte(content), nil
+
+	case file != "":
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("reading %sFile: %w", option, err)
+		}
+		return data, nil
+
+	default:
+		return nil, nil
+	}
+}
+
+// newSchemaBody compiles a JSON Schema, which validates the bodies of all the JSON media types.
+func newSchemaBody(content []byte) (*requestBody, error) {
+	document, err := parseDocument(content)
+	if err != nil {
+		return nil, err
+	}
+
+	schema, err := gojsonschema.NewSchema(gojsonschema.NewGoLoader(document))
+	if err != nil {
+		return nil, err
+	}
+
+	return &requestBody{
+		content: map[string]*gojsonschema.Schema{
+			"application/json":   schema,
+			"application/*+json": schema,
+		},
+	}, nil
+}
+
+func (v *validation) GetTracingInformation() (string, ext.SpanKindEnum) {
+	return v.name, tracing.SpanKindNoneEnum
+}
+
+func (v *validation) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	logger := log.FromContext(middlewares.GetLoggerCtx(req.Context(), v.name, typeName))
+
+	body := v.body
+	if v.api != nil {
+		body = v.api.requestBody(req)
+	}
+
+	if body == nil {
+		v.next.ServeHTTP(rw, req)
+		return
+	}
+
+	if req.Body == nil || req.Body == http.NoBody || req.ContentLength == 0 {
+		if body.required {
+			v.reject(rw, req, http.StatusBadRequest, "request body is required", nil)
+			return
+		}
+
+		v.next.ServeHTTP(rw, req)
+		return
+	}
+
+	mediaType, _, err := mime.ParseMediaType(req.Header.Get("Content-Type"))
+	if err != nil {
+		mediaType = ""
+	}
+
+	schema, ok := body.schema(mediaType)
+	if !ok {
+		v.reject(rw, req, http.StatusUnsupportedMediaType, fmt.Sprintf("unsupported content type %q", mediaType), nil)
+		return
+	}
+
+	if schema == nil || !isJSON(mediaType) {
+		v.next.ServeHTTP(rw, req)
+		return
+	}
+
+	content, err := v.readBody(req)
+	if err != nil {
+		logger.Debugf("Error while reading the request body: %v", err)
+
+		var maxBytesErr *http.MaxBytesError
+		if errors.Is(err, errBodyTooLarge) || errors.As(err, &maxBytesErr) {
+			v.reject(rw, req, http.StatusRequestEntityTooLarge, "request body too large", nil)
+			return
+		}
+
+		v.reject(rw, req, http.StatusBadRequest, "unreadable request body", nil)
+		return
+	}
+
+	if len(content) == 0 {
+		if body.required {
+			v.reject(rw, req, http.StatusBadRequest, "request body is required", nil)
+			return
+		}
+
+		v.next.ServeHTTP(rw, req)
+		return
+	}
+
+	result, err := schema.Validate(gojsonschema.NewBytesLoader(content))
+	if err != nil {
+		v.reject(rw, req, http.StatusBadRequest, "invalid JSON request body", nil)
+		return
+	}
+
+	if !result.Valid() {
+		v.reject(rw, req, http.StatusBadRequest, "request body does not match the schema", result.Errors())
+		return
+	}
+
+	v.next.ServeHTTP(rw, req)
+}
+
+var errBodyTooLarge = errors.New("request body too large")
+
+// readBody reads the request body, up to the maximum body size, and restores it for the next handlers.
+func (v *validation) readBody(req *http.Request) ([]byte, error) {
+	if req.ContentLength > v.maxBodySize {
+		return nil, errBodyTooLarge
+	}
+
+	content, err := io.ReadAll(io.LimitReader(req.Body, v.maxBodySize+1))
+	if err != nil {
+		return nil, err
+	}
+
+	if int64(len(content)) > v.maxBodySize {
+		return nil, errBodyTooLarge
+	}
+
+	req.Body = io.NopCloser(bytes.NewReader(content))
+	req.ContentLength = int64(len(content))
+
+	return content, nil
+}
+
+// errorResponse is the body of the responses to the refused requests.
+type errorResponse struct {
+	Message string       `json:"message"`
+	Errors  []fieldError `json:"errors,omitempty"`
+}
+
+// fieldError is a schema validation error.
+type fieldError struct {
+	// Field is the path of the invalid value, such as items.0.name, or empty for the whole body.
+	Field       string `json:"field,omitempty"`
+	Type        string `json:"type"`
+	Description string `json:"description"`
+}
+
+func (v *validation) reject(rw http.ResponseWriter, req *http.Request, status int, message string, resultErrors []gojsonschema.ResultError) {
+	logger := log.FromContext(middlewares.GetLoggerCtx(req.Context(), v.name, typeName))
+	logger.Debugf("Request refused with a %d status: %s", status, message)
+	tracing.SetErrorWithEvent(req, message)
+
+	response := errorResponse{Message: message}
+	for _, e := range resultErrors {
+		field := e.Field()
+		if field == gojsonschema.STRING_CONTEXT_ROOT {
+			field = ""
+		}
+
+		// The required errors are reported on the missing property, instead of on its parent.
+		if property, ok := e.Details()["property"].(string); ok && e.Type() == "required" {
+			field = strings.TrimPrefix(field+"."+property, ".")
+		}
+
+		response.Errors = append(response.Errors, fieldError{
+			Field:       field,
+			Type:        e.Type(),
+			Description: e.Description(),
+		})
+	}
+
+	rw.Header().Set("Content-Type", "application/json")
+	rw.WriteHeader(status)
+
+	if err := json.NewEncoder(rw).Encode(response); err != nil {
+		logger.Debugf("Error while writing the response: %v", err)
+	}
+}
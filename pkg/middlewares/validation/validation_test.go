@@ -0,0 +1,450 @@
+package validation
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/traefik/traefik/v2/pkg/config/dynamic"
+)
+
+const petSchema = `{
+  "type": "object",
+  "required": ["name"],
+  "properties": {
+    "name": {"type": "string", "minLength": 1},
+    "tags": {"type": "array", "items": {"type": "string"}}
+  }
+}`
+
+const petStore = `
+openapi: 3.0.3
+info:
+  title: Pet Store
+  version: 1.0.0
+servers:
+  - url: https://api.example.com/{version}
+    variables:
+      version:
+        default: v1
+paths:
+  /pets:
+    post:
+      requestBody:
+        required: true
+        content:
+          application/json:
+            schema:
+              $ref: '#/components/schemas/Pet'
+          multipart/form-data:
+            schema:
+              type: object
+    get:
+      responses:
+        200:
+          description: The pets.
+  /pets/{petId}:
+    put:
+      requestBody:
+        $ref: '#/components/requestBodies/Pet'
+  /pets/mine:
+    put:
+      requestBody:
+        content:
+          application/merge-patch+json:
+            schema:
+              type: object
+              properties:
+                name:
+                  type: string
+                  nullable: true
+components:
+  requestBodies:
+    Pet:
+      content:
+        application/json:
+          schema:
+            $ref: '#/components/schemas/Pet'
+  schemas:
+    Pet:
+      type: object
+      required: [name]
+      properties:
+        name:
+          type: string
+          minLength: 1
+        age:
+          type: integer
+          minimum: 0
+`
+
+func TestNew(t *testing.T) {
+	schemaFile := filepath.Join(t.TempDir(), "schema.json")
+	require.NoError(t, os.WriteFile(schemaFile, []byte(petSchema), 0o600))
+
+	testCases := []struct {
+		desc          string
+		config        dynamic.RequestValidation
+		expectedError bool
+	}{
+		{
+			desc:   "inline schema",
+			config: dynamic.RequestValidation{Schema: petSchema},
+		},
+		{
+			desc:   "schema file",
+			config: dynamic.RequestValidation{SchemaFile: schemaFile},
+		},
+		{
+			desc:   "inline OpenAPI document",
+			config: dynamic.RequestValidation{OpenAPI: petStore},
+		},
+		{
+			desc:          "no schema",
+			config:        dynamic.RequestValidation{},
+			expectedError: true,
+		},
+		{
+			desc:          "schema and OpenAPI document",
+			config:        dynamic.RequestValidation{Schema: petSchema, OpenAPI: petStore},
+			expectedError: true,
+		},
+		{
+			desc:          "schema and schema file",
+			config:        dynamic.RequestValidation{Schema: petSchema, SchemaFile: schemaFile},
+			expectedError: true,
+		},
+		{
+			desc:          "missing schema file",
+			config:        dynamic.RequestValidation{SchemaFile: filepath.Join(t.TempDir(), "missing.json")},
+			expectedError: true,
+		},
+		{
+			desc:          "invalid schema",
+			config:        dynamic.RequestValidation{Schema: `{"type": 42}`},
+			expectedError: true,
+		},
+		{
+			desc:          "Swagger 2 document",
+			config:        dynamic.RequestValidation{OpenAPI: `{"swagger": "2.0", "paths": {}}`},
+			expectedError: true,
+		},
+		{
+			desc: "unresolved reference",
+			config: dynamic.RequestValidation{OpenAPI: `
+openapi: 3.0.0
+paths:
+  /pets:
+    post:
+      requestBody:
+        $ref: '#/components/requestBodies/Missing'
+`},
+			expectedError: true,
+		},
+		{
+			desc:          "invalid max body size",
+			config:        dynamic.RequestValidation{Schema: petSchema, MaxBodySize: -1},
+			expectedError: true,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			config := test.config
+			if config.MaxBodySize == 0 {
+				config.SetDefaults()
+			}
+
+			next := http.HandlerFunc(func(http.ResponseWriter, *http.Request) {})
+
+			_, err := New(context.Background(), next, config, "validation")
+			if test.expectedError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestValidation_schema(t *testing.T) {
+	testCases := []struct {
+		desc           string
+		method         string
+		contentType    string
+		body           string
+		expectedStatus int
+		expectedErrors []fieldError
+	}{
+		{
+			desc:           "valid body",
+			contentType:    "application/json; charset=utf-8",
+			body:           `{"name": "Rex", "tags": ["dog"]}`,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			desc:           "valid body with a JSON structured syntax suffix",
+			contentType:    "application/vnd.api+json",
+			body:           `{"name": "Rex"}`,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			desc:           "missing property",
+			contentType:    "application/json",
+			body:           `{"tags": ["dog"]}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedErrors: []fieldError{
+				{Field: "name", Type: "required", Description: "name is required"},
+			},
+		},
+		{
+			desc:           "invalid nested value",
+			contentType:    "application/json",
+			body:           `{"name": "Rex", "tags": ["dog", 42]}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedErrors: []fieldError{
+				{Field: "tags.1", Type: "invalid_type", Description: "Invalid type. Expected: string, given: integer"},
+			},
+		},
+		{
+			desc:           "invalid root value",
+			contentType:    "application/json",
+			body:           `[]`,
+			expectedStatus: http.StatusBadRequest,
+			expectedErrors: []fieldError{
+				{Type: "invalid_type", Description: "Invalid type. Expected: object, given: array"},
+			},
+		},
+		{
+			desc:           "malformed JSON",
+			contentType:    "application/json",
+			body:           `{"name": `,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			desc:           "unsupported content type",
+			contentType:    "text/plain",
+			body:           `name=Rex`,
+			expectedStatus: http.StatusUnsupportedMediaType,
+		},
+		{
+			desc:           "missing content type",
+			body:           `{"name": "Rex"}`,
+			expectedStatus: http.StatusUnsupportedMediaType,
+		},
+		{
+			desc:           "no body",
+			method:         http.MethodGet,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			desc:           "body too large",
+			contentType:    "application/json",
+			body:           `{"name": "` + strings.Repeat("a", 100) + `"}`,
+			expectedStatus: http.StatusRequestEntityTooLarge,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				body, err := io.ReadAll(req.Body)
+				require.NoError(t, err)
+				assert.Equal(t, test.body, string(body))
+			})
+
+			config := dynamic.RequestValidation{Schema: petSchema, MaxBodySize: 64}
+
+			handler, err := New(context.Background(), next, config, "validation")
+			require.NoError(t, err)
+
+			method := test.method
+			if method == "" {
+				method = http.MethodPost
+			}
+
+			var body io.Reader
+			if test.body != "" {
+				body = strings.NewReader(test.body)
+			}
+
+			req := httptest.NewRequest(method, "http://localhost/pets", body)
+			if test.contentType != "" {
+				req.Header.Set("Content-Type", test.contentType)
+			}
+
+			rw := httptest.NewRecorder()
+			handler.ServeHTTP(rw, req)
+
+			assert.Equal(t, test.expectedStatus, rw.Code)
+
+			if test.expectedStatus == http.StatusOK {
+				return
+			}
+
+			assert.Equal(t, "application/json", rw.Header().Get("Content-Type"))
+
+			var response errorResponse
+			require.NoError(t, json.NewDecoder(rw.Body).Decode(&response))
+			assert.NotEmpty(t, response.Message)
+			assert.Equal(t, test.expectedErrors, response.Errors)
+		})
+	}
+}
+
+func TestValidation_openAPI(t *testing.T) {
+	testCases := []struct {
+		desc           string
+		method         string
+		path           string
+		contentType    string
+		body           string
+		expectedStatus int
+		expectedFields []string
+	}{
+		{
+			desc:           "valid body",
+			method:         http.MethodPost,
+			path:           "/v1/pets",
+			contentType:    "application/json",
+			body:           `{"name": "Rex", "age": 3}`,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			desc:           "invalid body",
+			method:         http.MethodPost,
+			path:           "/v1/pets",
+			contentType:    "application/json",
+			body:           `{"name": "", "age": -1}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedFields: []string{"age", "name"},
+		},
+		{
+			desc:           "missing required body",
+			method:         http.MethodPost,
+			path:           "/v1/pets",
+			contentType:    "application/json",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			desc:           "media type without JSON schema",
+			method:         http.MethodPost,
+			path:           "/v1/pets",
+			contentType:    "multipart/form-data; boundary=foo",
+			body:           "--foo--",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			desc:           "unsupported media type",
+			method:         http.MethodPost,
+			path:           "/v1/pets",
+			contentType:    "text/plain",
+			body:           "Rex",
+			expectedStatus: http.StatusUnsupportedMediaType,
+		},
+		{
+			desc:           "operation without request body",
+			method:         http.MethodGet,
+			path:           "/v1/pets",
+			contentType:    "application/json",
+			body:           `{"name": 42}`,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			desc:           "templated path with a request body reference",
+			method:         http.MethodPut,
+			path:           "/v1/pets/42",
+			contentType:    "application/json",
+			body:           `{"age": 3}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedFields: []string{"name"},
+		},
+		{
+			desc:           "optional body",
+			method:         http.MethodPut,
+			path:           "/v1/pets/42",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			desc:           "concrete path before templated path",
+			method:         http.MethodPut,
+			path:           "/v1/pets/mine",
+			contentType:    "application/merge-patch+json",
+			body:           `{"name": null}`,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			desc:           "unknown operation",
+			method:         http.MethodPost,
+			path:           "/v1/owners",
+			contentType:    "application/json",
+			body:           `{"name": 42}`,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			desc:           "path outside the server base path",
+			method:         http.MethodPost,
+			path:           "/pets",
+			contentType:    "application/json",
+			body:           `{"name": 42}`,
+			expectedStatus: http.StatusOK,
+		},
+	}
+
+	next := http.HandlerFunc(func(http.ResponseWriter, *http.Request) {})
+
+	config := dynamic.RequestValidation{OpenAPI: petStore}
+	config.SetDefaults()
+
+	handler, err := New(context.Background(), next, config, "validation")
+	require.NoError(t, err)
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			var body io.Reader
+			if test.body != "" {
+				body = strings.NewReader(test.body)
+			}
+
+			req := httptest.NewRequest(test.method, "http://localhost"+test.path, body)
+			if test.contentType != "" {
+				req.Header.Set("Content-Type", test.contentType)
+			}
+
+			rw := httptest.NewRecorder()
+			handler.ServeHTTP(rw, req)
+
+			assert.Equal(t, test.expectedStatus, rw.Code)
+
+			if test.expectedStatus == http.StatusOK {
+				return
+			}
+
+			var response errorResponse
+			require.NoError(t, json.NewDecoder(rw.Body).Decode(&response))
+
+			var fields []string
+			for _, e := range response.Errors {
+				fields = append(fields, e.Field)
+			}
+			assert.ElementsMatch(t, test.expectedFields, fields)
+		})
+	}
+}
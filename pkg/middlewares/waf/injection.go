@@ -0,0 +1,575 @@
+package waf
+
+import (
+	"strings"
+)
+
+// The SQL injection and XSS detections are heuristics inspired by libinjection:
+// the values are tokenized as SQL or HTML, and the token sequences typical of injections are detected.
+
+type sqlTokenType int
+
+const (
+	sqlNumber sqlTokenType = iota
+	sqlString
+	sqlName
+	sqlKeyword
+	sqlLogical
+	sqlComparison
+	sqlOperator
+	sqlVariable
+	sqlOpenParen
+	sqlCloseParen
+	sqlComma
+	sqlSemicolon
+	sqlOther
+)
+
+type sqlToken struct {
+	typ sqlTokenType
+	val string
+}
+
+var sqlKeywords = map[string]struct{}{
+	"ALL": {}, "ALTER": {}, "AS": {}, "BY": {}, "CASE": {}, "CREATE": {}, "DATABASE": {}, "DECLARE": {}, "DELAY": {},
+	"DELETE": {}, "DISTINCT": {}, "DROP": {}, "ELSE": {}, "END": {}, "EXEC": {}, "EXECUTE": {}, "FROM": {},
+	"FUNCTION": {}, "GRANT": {}, "GROUP": {}, "HAVING": {}, "INDEX": {}, "INSERT": {}, "INTO": {}, "LIMIT": {},
+	"ORDER": {}, "PROCEDURE": {}, "REVOKE": {}, "SCHEMA": {}, "SELECT": {}, "SET": {}, "SHUTDOWN": {}, "TABLE": {},
+	"THEN": {}, "TIME": {}, "TRUNCATE": {}, "UNION": {}, "UPDATE": {}, "USER": {}, "VALUES": {}, "VIEW": {},
+	"WAITFOR": {}, "WHEN": {}, "WHERE": {},
+}
+
+var sqlLogicalOperators = map[string]struct{}{
+	"AND": {}, "OR": {}, "XOR": {}, "NOT": {}, "&&": {}, "||": {},
+}
+
+var sqlComparisonOperators = map[string]struct{}{
+	"=": {}, "<": {}, ">": {}, "<=": {}, ">=": {}, "<>": {}, "!=": {}, "<=>": {},
+	"LIKE": {}, "RLIKE": {}, "REGEXP": {}, "IS": {}, "IN": {}, "BETWEEN": {}, "SOUNDS": {},
+}
+
+// sqlStatements holds the statements detected when stacked after a semicolon,
+// with the check of the tokens following the statement keyword.
+var sqlStatements = map[string]func(rest []sqlToken) bool{
+	"ALTER":    followedBy("TABLE", "DATABASE", "USER", "PROCEDURE", "FUNCTION", "VIEW", "SCHEMA"),
+	"CREATE":   followedBy("TABLE", "DATABASE", "USER", "PROCEDURE", "FUNCTION", "VIEW", "INDEX", "SCHEMA"),
+	"DECLARE":  func(rest []sqlToken) bool { return len(rest) > 0 && rest[0].typ == sqlVariable },
+	"DELETE":   followedBy("FROM"),
+	"DROP":     followedBy("TABLE", "DATABASE", "USER", "PROCEDURE", "FUNCTION", "VIEW", "INDEX", "SCHEMA"),
+	"EXEC":     isProcedureCall,
+	"EXECUTE":  isProcedureCall,
+	"INSERT":   followedBy("INTO"),
+	"SELECT":   isSelect,
+	"SHUTDOWN": func(rest []sqlToken) bool { return len(rest) == 0 || rest[0].typ == sqlSemicolon },
+	"TRUNCATE": followedBy("TABLE"),
+	"UPDATE":   func(rest []sqlToken) bool { return len(rest) > 1 && rest[0].typ == sqlName && rest[1].val == "SET" },
+	"WAITFOR":  followedBy("DELAY", "TIME"),
+}
+
+func followedBy(keywords ...string) func(rest []sqlToken) bool {
+	return func(rest []sqlToken) bool {
+		return len(rest) > 0 && contains(keywords, rest[0].val)
+	}
+}
+
+// isProcedureCall detects the calls of the system stored procedures, such as EXEC xp_cmdshell.
+func isProcedureCall(rest []sqlToken) bool {
+	return len(rest) > 0 && rest[0].typ == sqlName && (strings.HasPrefix(rest[0].val, "XP_") || strings.HasPrefix(rest[0].val, "SP_"))
+}
+
+// isSelect detects the SELECT statements reading a table, a function, or a variable.
+func isSelect(rest []sqlToken) bool {
+	for i, token := range rest {
+		if token.typ == sqlSemicolon {
+			return false
+		}
+		if token.val == "FROM" || token.typ == sqlVariable || isCall(rest[i:]) {
+			return true
+		}
+	}
+	return false
+}
+
+// detectSQLi reports whether the value looks like an SQL injection.
+// The value is analyzed as is, and as if it was inserted in a single-quoted or a double-quoted SQL string.
+func detectSQLi(value string) bool {
+	if value == "" {
+		return false
+	}
+
+	tokens, _ := tokenizeSQL(value)
+	if hasUnionSelect(tokens) || hasStackedStatement(tokens) {
+		return true
+	}
+
+	// Numerical context, such as id=1 OR 1=1.
+	if len(tokens) > 0 && tokens[0].typ == sqlNumber && isInjectedCondition(skipCloseParens(tokens, 1), false, false) {
+		return true
+	}
+
+	for _, quote := range []byte{'\'', '"'} {
+		idx := strings.IndexByte(value, quote)
+		if idx < 0 {
+			continue
+		}
+
+		rest, comment := tokenizeSQL(value[idx+1:])
+		if len(rest) == 0 {
+			if comment {
+				// Truncation of the query, such as admin'--.
+				return true
+			}
+			continue
+		}
+
+		if hasUnionSelect(rest) || hasStackedStatement(rest) || isInjectedCondition(skipCloseParens(rest, 0), true, comment) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// tokenizeSQL splits the value into SQL tokens, ignoring the inline comments.
+// It stops at the first comment running up to the end of the line, and reports it.
+func tokenizeSQL(s string) ([]sqlToken, bool) {
+	var tokens []sqlToken
+
+	for i := 0; i < len(s); {
+		c := s[i]
+
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f' || c == '\v':
+			i++
+
+		case strings.HasPrefix(s[i:], "/*!"):
+			// MySQL executable comment: its content is code.
+			i += 3
+			for i < len(s) && isDigit(s[i]) {
+				i++
+			}
+
+		case strings.HasPrefix(s[i:], "/*"):
+			i = indexAfter(s, i+2, "*/")
+
+		case strings.HasPrefix(s[i:], "*/"):
+			i += 2
+
+		case strings.HasPrefix(s[i:], "--") || c == '#':
+			return tokens, true
+
+		case c == '\'' || c == '"':
+			end := i + 1
+			for end < len(s) {
+				if s[end] == '\\' {
+					end += 2
+					continue
+				}
+				if s[end] == c {
+					if end+1 < len(s) && s[end+1] == c {
+						end += 2
+						continue
+					}
+					break
+				}
+				end++
+			}
+			if end > len(s) {
+				end = len(s)
+			}
+			tokens = append(tokens, sqlToken{typ: sqlString, val: s[i:end]})
+			i = end + 1
+
+		case c == '`':
+			end := indexAfter(s, i+1, "`")
+			tokens = append(tokens, sqlToken{typ: sqlName, val: s[i:end]})
+			i = end
+
+		case isDigit(c) || c == '.' && i+1 < len(s) && isDigit(s[i+1]):
+			end := i + 1
+			for end < len(s) && (isWordByte(s[end]) || s[end] == '.') {
+				end++
+			}
+			tokens = append(tokens, sqlToken{typ: sqlNumber, val: s[i:end]})
+			i = end
+
+		case c == '@':
+			end := i + 1
+			for end < len(s) && (isWordByte(s[end]) || s[end] == '@') {
+				end++
+			}
+			tokens = append(tokens, sqlToken{typ: sqlVariable, val: s[i:end]})
+			i = end
+
+		case isWordByte(c):
+			end := i + 1
+			for end < len(s) && (isWordByte(s[end]) || s[end] == '$') {
+				end++
+			}
+			tokens = append(tokens, classifyWord(strings.ToUpper(s[i:end])))
+			i = end
+
+		case c == '(':
+			tokens = append(tokens, sqlToken{typ: sqlOpenParen, val: "("})
+			i++
+
+		case c == ')':
+			tokens = append(tokens, sqlToken{typ: sqlCloseParen, val: ")"})
+			i++
+
+		case c == ',':
+			tokens = append(tokens, sqlToken{typ: sqlComma, val: ","})
+			i++
+
+		case c == ';':
+			tokens = append(tokens, sqlToken{typ: sqlSemicolon, val: ";"})
+			i++
+
+		case strings.IndexByte("=<>!|&+-*/%^~:", c) >= 0:
+			end := i + 1
+			for end < len(s) && end-i < 3 && strings.IndexByte("=<>!|&", s[end]) >= 0 {
+				end++
+			}
+			tokens = append(tokens, classifyOperator(s[i:end]))
+			i = end
+
+		default:
+			tokens = append(tokens, sqlToken{typ: sqlOther, val: string(c)})
+			i++
+		}
+	}
+
+	return tokens, false
+}
+
+func classifyWord(word string) sqlToken {
+	if _, ok := sqlLogicalOperators[word]; ok {
+		return sqlToken{typ: sqlLogical, val: word}
+	}
+	if _, ok := sqlComparisonOperators[word]; ok {
+		return sqlToken{typ: sqlComparison, val: word}
+	}
+	if _, ok := sqlKeywords[word]; ok {
+		return sqlToken{typ: sqlKeyword, val: word}
+	}
+	return sqlToken{typ: sqlName, val: word}
+}
+
+func classifyOperator(op string) sqlToken {
+	if _, ok := sqlLogicalOperators[op]; ok {
+		return sqlToken{typ: sqlLogical, val: op}
+	}
+	if _, ok := sqlComparisonOperators[op]; ok {
+		return sqlToken{typ: sqlComparison, val: op}
+	}
+	return sqlToken{typ: sqlOperator, val: op}
+}
+
+// hasUnionSelect detects the UNION [ALL|DISTINCT] SELECT sequences.
+func hasUnionSelect(tokens []sqlToken) bool {
+	for i, token := range tokens {
+		if token.typ != sqlKeyword || token.val != "UNION" {
+			continue
+		}
+
+		j := i + 1
+		if j < len(tokens) && (tokens[j].val == "ALL" || tokens[j].val == "DISTINCT") {
+			j++
+		}
+		for j < len(tokens) && tokens[j].typ == sqlOpenParen {
+			j++
+		}
+
+		if j < len(tokens) && tokens[j].val == "SELECT" {
+			return true
+		}
+	}
+
+	return false
+}
+
+// hasStackedStatement detects the statements stacked after a semicolon, such as ; DROP TABLE.
+func hasStackedStatement(tokens []sqlToken) bool {
+	for i, token := range tokens {
+		if token.typ != sqlSemicolon || i+1 >= len(tokens) || tokens[i+1].typ != sqlKeyword {
+			continue
+		}
+
+		if check, ok := sqlStatements[tokens[i+1].val]; ok && check(tokens[i+2:]) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isInjectedCondition detects a condition injected with a logical operator, such as OR 1=1 or AND SLEEP(5),
+// or a comparison closing the string, such as '='.
+// A condition made of a single operand is only detected when it is followed by a comment,
+// or when it is a number or a boolean ending a value inserted in a string.
+func isInjectedCondition(tokens []sqlToken, quoted, comment bool) bool {
+	if len(tokens) == 0 {
+		return false
+	}
+
+	if tokens[0].typ == sqlComparison {
+		// Comparison with the end of the string, such as '='.
+		n := operandLength(tokens[1:])
+		return quoted && n > 0 && (len(tokens) == n+1 || tokens[n+1].typ == sqlLogical)
+	}
+
+	if tokens[0].typ != sqlLogical {
+		return false
+	}
+
+	rest := tokens[1:]
+	for len(rest) > 0 && rest[0].typ == sqlLogical && rest[0].val == "NOT" {
+		rest = rest[1:]
+	}
+
+	n := operandLength(rest)
+	switch {
+	case n == 0:
+		return false
+	case isComparison(rest) || isCall(rest) || rest[0].typ == sqlVariable:
+		return true
+	case rest[0].typ == sqlOpenParen:
+		inner := rest[1:n]
+		return len(inner) > 0 && inner[0].val == "SELECT" || isComparison(inner)
+	case len(rest) == n:
+		if comment {
+			return true
+		}
+		return quoted && (rest[0].typ == sqlNumber || rest[0].val == "TRUE" || rest[0].val == "FALSE")
+	default:
+		return false
+	}
+}
+
+// isComparison reports whether the tokens start with a comparison of two operands.
+func isComparison(tokens []sqlToken) bool {
+	n := operandLength(tokens)
+	return n > 0 && len(tokens) > n && tokens[n].typ == sqlComparison && operandLength(tokens[n+1:]) > 0
+}
+
+// operandLength returns the number of tokens of the operand at the beginning of the tokens, or 0 if there is none.
+func operandLength(tokens []sqlToken) int {
+	if len(tokens) == 0 {
+		return 0
+	}
+
+	switch tokens[0].typ {
+	case sqlNumber, sqlString, sqlVariable:
+		return 1
+	case sqlName:
+		if isCall(tokens) {
+			return 1 + closingParen(tokens[1:])
+		}
+		return 1
+	case sqlOpenParen:
+		return closingParen(tokens)
+	case sqlOperator:
+		if tokens[0].val == "-" || tokens[0].val == "+" || tokens[0].val == "~" {
+			if n := operandLength(tokens[1:]); n > 0 {
+				return n + 1
+			}
+		}
+		return 0
+	default:
+		return 0
+	}
+}
+
+func isCall(tokens []sqlToken) bool {
+	return len(tokens) > 1 && tokens[0].typ == sqlName && tokens[1].typ == sqlOpenParen
+}
+
+// closingParen returns the number of tokens up to the parenthesis closing the one starting the tokens,
+// or all the tokens if it is not closed.
+func closingParen(tokens []sqlToken) int {
+	depth := 0
+	for i, token := range tokens {
+		switch token.typ {
+		case sqlOpenParen:
+			depth++
+		case sqlCloseParen:
+			depth--
+			if depth == 0 {
+				return i + 1
+			}
+		}
+	}
+	return len(tokens)
+}
+
+func skipCloseParens(tokens []sqlToken, start int) []sqlToken {
+	if start > len(tokens) {
+		return nil
+	}
+
+	tokens = tokens[start:]
+	for len(tokens) > 0 && tokens[0].typ == sqlCloseParen {
+		tokens = tokens[1:]
+	}
+	return tokens
+}
+
+func isDigit(c byte) bool {
+	return '0' <= c && c <= '9'
+}
+
+func isWordByte(c byte) bool {
+	return 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || isDigit(c) || c == '_' || c >= 0x80
+}
+
+// xssTags holds the HTML elements that are detected whatever their attributes.
+var xssTags = map[string]struct{}{
+	"applet": {}, "base": {}, "embed": {}, "frame": {}, "frameset": {}, "iframe": {}, "import": {}, "link": {},
+	"meta": {}, "object": {}, "script": {}, "style": {}, "xss": {},
+}
+
+// xssURLAttributes holds the HTML attributes whose value is a URL.
+var xssURLAttributes = map[string]struct{}{
+	"action": {}, "background": {}, "data": {}, "dynsrc": {}, "formaction": {}, "href": {}, "lowsrc": {}, "poster": {},
+	"src": {}, "to": {}, "values": {}, "xlink:href": {},
+}
+
+// detectXSS reports whether the value looks like a cross-site scripting attempt:
+// a dangerous HTML element, an event handler attribute, or a script URL.
+func detectXSS(value string) bool {
+	if isScriptURL(value) {
+		return true
+	}
+
+	for i := strings.IndexByte(value, '<'); i >= 0 && i < len(value); {
+		next, dangerous := scanTag(value, i+1)
+		if dangerous {
+			return true
+		}
+
+		idx := strings.IndexByte(value[next:], '<')
+		if idx < 0 {
+			break
+		}
+		i = next + idx
+	}
+
+	return false
+}
+
+// scanTag scans the HTML tag starting at the given index, after the < character,
+// and returns the index following the tag, and whether it is dangerous.
+func scanTag(s string, i int) (int, bool) {
+	start := i
+	for i < len(s) && (isWordByte(s[i]) || s[i] == ':' || s[i] == '-') {
+		i++
+	}
+
+	if i == start || !isLetter(s[start]) {
+		return i, false
+	}
+
+	if _, ok := xssTags[strings.ToLower(s[start:i])]; ok {
+		return i, true
+	}
+
+	for i < len(s) && s[i] != '>' {
+		if s[i] == '<' {
+			return i, false
+		}
+
+		if isSpace(s[i]) || s[i] == '/' || s[i] == '"' || s[i] == '\'' {
+			i++
+			continue
+		}
+
+		nameStart := i
+		for i < len(s) && !isSpace(s[i]) && s[i] != '/' && s[i] != '>' && s[i] != '=' {
+			i++
+		}
+		name := strings.ToLower(s[nameStart:i])
+
+		if len(name) > 2 && strings.HasPrefix(name, "on") {
+			return i, true
+		}
+
+		for i < len(s) && isSpace(s[i]) {
+			i++
+		}
+		if i >= len(s) || s[i] != '=' {
+			continue
+		}
+		i++
+		for i < len(s) && isSpace(s[i]) {
+			i++
+		}
+
+		var attrValue string
+		attrValue, i = scanAttributeValue(s, i)
+
+		if _, ok := xssURLAttributes[name]; ok && isScriptURL(attrValue) {
+			return i, true
+		}
+
+		if name == "style" {
+			lower := strings.ToLower(removeWhitespace(attrValue))
+			if strings.Contains(lower, "expression(") || strings.Contains(lower, "javascript:") {
+				return i, true
+			}
+		}
+	}
+
+	return i, false
+}
+
+func scanAttributeValue(s string, i int) (string, int) {
+	if i < len(s) && (s[i] == '"' || s[i] == '\'' || s[i] == '`') {
+		end := strings.IndexByte(s[i+1:], s[i])
+		if end < 0 {
+			return s[i+1:], len(s)
+		}
+		return s[i+1 : i+1+end], i + end + 2
+	}
+
+	start := i
+	for i < len(s) && !isSpace(s[i]) && s[i] != '>' {
+		i++
+	}
+	return s[start:i], i
+}
+
+// isScriptURL reports whether the value is a URL executing a script, such as javascript:alert(1).
+// As done by the browsers, the control characters and the spaces are ignored.
+func isScriptURL(value string) bool {
+	var b strings.Builder
+	for i := 0; i < len(value) && b.Len() < len("data:text/html"); i++ {
+		if value[i] > ' ' {
+			b.WriteByte(value[i])
+		}
+	}
+
+	scheme := strings.ToLower(b.String())
+	for _, prefix := range []string{"javascript:", "vbscript:", "livescript:", "data:text/html"} {
+		if strings.HasPrefix(scheme, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func isLetter(c byte) bool {
+	return 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z'
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f'
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,92 @@
+package waf
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_detectSQLi(t *testing.T) {
+	testCases := []struct {
+		value    string
+		expected bool
+	}{
+		{value: "1 OR 1=1", expected: true},
+		{value: "1) or (1=1", expected: true},
+		{value: "1 AND SLEEP(5)", expected: true},
+		{value: "' OR '1'='1", expected: true},
+		{value: "x' or 'a'='a", expected: true},
+		{value: "admin'--", expected: true},
+		{value: "admin' #", expected: true},
+		{value: "' or 1 --", expected: true},
+		{value: "' or true", expected: true},
+		{value: "'='", expected: true},
+		{value: "\" or \"\"=\"", expected: true},
+		{value: "') or ('x'='x", expected: true},
+		{value: "' and (select count(*) from users) > 0 --", expected: true},
+		{value: "-1 UNION SELECT username, password FROM users", expected: true},
+		{value: "1 union all select null,null", expected: true},
+		{value: "1/**/UNION/**/SELECT/**/1", expected: true},
+		{value: "1 /*!50000UNION*/ SELECT 1", expected: true},
+		{value: "1; DROP TABLE users", expected: true},
+		{value: "'; exec xp_cmdshell 'dir'--", expected: true},
+		{value: "1; waitfor delay '0:0:5'", expected: true},
+		{value: "'; update users set admin=1 --", expected: true},
+		{value: "", expected: false},
+		{value: "hello world", expected: false},
+		{value: "O'Reilly", expected: false},
+		{value: "It's a nice day, isn't it?", expected: false},
+		{value: "rock'n'roll or jazz", expected: false},
+		{value: "the 'or' operator", expected: false},
+		{value: "select the items from the list", expected: false},
+		{value: "Note; update the docs", expected: false},
+		{value: "Please select; delete the spam", expected: false},
+		{value: "1 and 2 stars", expected: false},
+		{value: "john.doe@example.com", expected: false},
+		{value: "42", expected: false},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.value, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, test.expected, detectSQLi(test.value))
+		})
+	}
+}
+
+func Test_detectXSS(t *testing.T) {
+	testCases := []struct {
+		value    string
+		expected bool
+	}{
+		{value: "<script>alert(1)</script>", expected: true},
+		{value: "<SCRIPT SRC=//evil.example.com/x.js>", expected: true},
+		{value: "<img src=x onerror=alert(1)>", expected: true},
+		{value: "<svg/onload=alert(1)>", expected: true},
+		{value: `<a href="javascript:alert(1)">click</a>`, expected: true},
+		{value: "<a href=' java\tscript:alert(1)'>click</a>", expected: true},
+		{value: `<iframe src="https://example.com">`, expected: true},
+		{value: `<div style="width: expression(alert(1))">`, expected: true},
+		{value: `<form action="data:text/html;base64,PHNjcmlwdD4=">`, expected: true},
+		{value: "javascript:alert(document.cookie)", expected: true},
+		{value: "  JaVaScRiPt:alert(1)", expected: true},
+		{value: "", expected: false},
+		{value: "hello world", expected: false},
+		{value: "1 < 2 and 3 > 2", expected: false},
+		{value: "a<b", expected: false},
+		{value: "<b>bold</b> and <i>italic</i>", expected: false},
+		{value: `<a href="https://example.com" title="javascript: the good parts">link</a>`, expected: false},
+		{value: "https://example.com/?q=javascript", expected: false},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.value, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, test.expected, detectXSS(test.value))
+		})
+	}
+}
@@ -0,0 +1,189 @@
+package waf
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/traefik/traefik/v2/pkg/ip"
+)
+
+// operator is the test of a rule applied to the values of its variables.
+type operator struct {
+	name   string
+	negate bool
+	match  func(value string) bool
+}
+
+type operatorBuilder func(param string) (func(string) bool, error)
+
+var operators = map[string]operatorBuilder{
+	"rx":                 newRegexOperator,
+	"pm":                 newPhraseMatchOperator,
+	"contains":           newStringOperator(strings.Contains),
+	"streq":              newStringOperator(func(value, param string) bool { return value == param }),
+	"beginsWith":         newStringOperator(strings.HasPrefix),
+	"endsWith":           newStringOperator(strings.HasSuffix),
+	"within":             newStringOperator(func(value, param string) bool { return value != "" && strings.Contains(param, value) }),
+	"eq":                 newNumberOperator(func(value, param int) bool { return value == param }),
+	"ge":                 newNumberOperator(func(value, param int) bool { return value >= param }),
+	"gt":                 newNumberOperator(func(value, param int) bool { return value > param }),
+	"le":                 newNumberOperator(func(value, param int) bool { return value <= param }),
+	"lt":                 newNumberOperator(func(value, param int) bool { return value < param }),
+	"ipMatch":            newIPMatchOperator,
+	"detectSQLi":         newDetectionOperator(detectSQLi),
+	"detectXSS":          newDetectionOperator(detectXSS),
+	"unconditionalMatch": newDetectionOperator(func(string) bool { return true }),
+	"validateByteRange":  newByteRangeOperator,
+}
+
+// parseOperator parses an operator, such as "!@rx ^foo".
+// The @rx operator is used when the operator name is omitted.
+func parseOperator(raw string) (*operator, error) {
+	op := &operator{name: "rx"}
+
+	expr := raw
+	if strings.HasPrefix(expr, "!") {
+		op.negate = true
+		expr = expr[1:]
+	}
+
+	var param string
+	if strings.HasPrefix(expr, "@") {
+		var name string
+		name, param, _ = strings.Cut(expr[1:], " ")
+		op.name = name
+		param = strings.TrimSpace(param)
+	} else {
+		param = expr
+	}
+
+	if strings.Contains(param, "%{") {
+		return nil, fmt.Errorf("operator %q: macro expansion is not supported", raw)
+	}
+
+	build, ok := operators[op.name]
+	if !ok {
+		return nil, fmt.Errorf("unsupported operator @%s", op.name)
+	}
+
+	match, err := build(param)
+	if err != nil {
+		return nil, fmt.Errorf("operator @%s: %w", op.name, err)
+	}
+	op.match = match
+
+	return op, nil
+}
+
+func newRegexOperator(param string) (func(string) bool, error) {
+	re, err := regexp.Compile(param)
+	if err != nil {
+		return nil, err
+	}
+	return re.MatchString, nil
+}
+
+// newPhraseMatchOperator builds the @pm operator, matching case-insensitively any of the space separated phrases.
+func newPhraseMatchOperator(param string) (func(string) bool, error) {
+	phrases := strings.Fields(strings.ToLower(param))
+	if len(phrases) == 0 {
+		return nil, errors.New("no phrase")
+	}
+
+	return func(value string) bool {
+		value = strings.ToLower(value)
+		for _, phrase := range phrases {
+			if strings.Contains(value, phrase) {
+				return true
+			}
+		}
+		return false
+	}, nil
+}
+
+func newStringOperator(fn func(value, param string) bool) operatorBuilder {
+	return func(param string) (func(string) bool, error) {
+		return func(value string) bool {
+			return fn(value, param)
+		}, nil
+	}
+}
+
+// newNumberOperator builds a numerical comparison operator.
+// As done by ModSecurity, the values that are not numbers are compared as 0.
+func newNumberOperator(fn func(value, param int) bool) operatorBuilder {
+	return func(param string) (func(string) bool, error) {
+		expected, err := strconv.Atoi(param)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %q", param)
+		}
+
+		return func(value string) bool {
+			v, _ := strconv.Atoi(strings.TrimSpace(value))
+			return fn(v, expected)
+		}, nil
+	}
+}
+
+func newIPMatchOperator(param string) (func(string) bool, error) {
+	checker, err := ip.NewChecker(strings.Split(strings.ReplaceAll(param, " ", ""), ","))
+	if err != nil {
+		return nil, err
+	}
+
+	return func(value string) bool {
+		ok, err := checker.Contains(value)
+		return err == nil && ok
+	}, nil
+}
+
+func newDetectionOperator(detect func(string) bool) operatorBuilder {
+	return func(string) (func(string) bool, error) {
+		return detect, nil
+	}
+}
+
+// newByteRangeOperator builds the @validateByteRange operator,
+// matching the values containing a byte outside the allowed ranges, such as "9,10,13,32-126".
+func newByteRangeOperator(param string) (func(string) bool, error) {
+	var allowed [256]bool
+
+	for _, part := range strings.Split(param, ",") {
+		part = strings.TrimSpace(part)
+
+		low, high, isRange := strings.Cut(part, "-")
+		if !isRange {
+			high = low
+		}
+
+		from, err := strconv.Atoi(strings.TrimSpace(low))
+		if err != nil {
+			return nil, fmt.Errorf("invalid byte range %q", part)
+		}
+
+		to, err := strconv.Atoi(strings.TrimSpace(high))
+		if err != nil {
+			return nil, fmt.Errorf("invalid byte range %q", part)
+		}
+
+		if from < 0 || to > 255 || from > to {
+			return nil, fmt.Errorf("invalid byte range %q", part)
+		}
+
+		for b := from; b <= to; b++ {
+			allowed[b] = true
+		}
+	}
+
+	return func(value string) bool {
+		for i := 0; i < len(value); i++ {
+			if !allowed[value[i]] {
+				return true
+			}
+		}
+		return false
+	}, nil
+}
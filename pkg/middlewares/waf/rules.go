@@ -0,0 +1,539 @@
+package waf
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+type disruptiveAction int
+
+const (
+	// actionBlock adds the score of the rule to the anomaly score of the request.
+	actionBlock disruptiveAction = iota
+	// actionDeny blocks the request as soon as the rule matches.
+	actionDeny
+	// actionPass only records the match of the rule.
+	actionPass
+)
+
+const defaultScore = 5
+
+var severities = map[string]int{
+	"EMERGENCY": 0,
+	"ALERT":     1,
+	"CRITICAL":  2,
+	"ERROR":     3,
+	"WARNING":   4,
+	"NOTICE":    5,
+	"INFO":      6,
+	"DEBUG":     7,
+}
+
+var anomalyScores = map[string]int{
+	"critical": 5,
+	"error":    4,
+	"warning":  3,
+	"notice":   2,
+}
+
+var anomalyScoreMacro = regexp.MustCompile(`(?i)^%\{tx\.(critical|error|warning|notice)_anomaly_score\}$`)
+
+// ignoredActions holds the metadata and logging actions accepted for compatibility, and ignored.
+var ignoredActions = map[string]struct{}{
+	"accuracy":   {},
+	"auditlog":   {},
+	"capture":    {},
+	"log":        {},
+	"logdata":    {},
+	"maturity":   {},
+	"noauditlog": {},
+	"nolog":      {},
+	"rev":        {},
+	"ver":        {},
+}
+
+// rule is a rule, or a link of a chained rule.
+// The metadata and the disruptive action of a chained rule are defined by its first link.
+type rule struct {
+	id       int
+	phase    int
+	msg      string
+	severity int
+	status   int
+	action   disruptiveAction
+	score    int
+	scoreSet bool
+
+	variables       []variable
+	exclusions      []selector
+	operator        *operator
+	transformations []transformation
+	multiMatch      bool
+
+	chain   bool
+	chained *rule
+}
+
+// idRange is an inclusive range of rule IDs.
+type idRange struct {
+	from, to int
+}
+
+func (r idRange) contains(id int) bool {
+	return r.from <= id && id <= r.to
+}
+
+// parseIDRange parses a rule ID, or a range of rule IDs such as 942100-942199.
+func parseIDRange(raw string) (idRange, error) {
+	from, to, isRange := strings.Cut(strings.TrimSpace(raw), "-")
+	if !isRange {
+		to = from
+	}
+
+	low, err := strconv.Atoi(from)
+	if err != nil {
+		return idRange{}, fmt.Errorf("invalid rule ID %q", raw)
+	}
+
+	high, err := strconv.Atoi(to)
+	if err != nil || high < low {
+		return idRange{}, fmt.Errorf("invalid rule ID range %q", raw)
+	}
+
+	return idRange{from: low, to: high}, nil
+}
+
+// parseRules parses rules written in the SecRule language.
+// It returns the rules, and the ranges of the IDs of the rules removed with SecRuleRemoveById.
+func parseRules(content string) ([]*rule, []idRange, error) {
+	var rules []*rule
+	var removed []idRange
+
+	// last is the last link of the current rule, while it is chained.
+	var last *rule
+
+	lines := strings.Split(content, "\n")
+	for i := 0; i < len(lines); i++ {
+		lineNumber := i + 1
+
+		line := strings.TrimSpace(lines[i])
+		for strings.HasSuffix(line, `\`) && i+1 < len(lines) {
+			i++
+			line = strings.TrimSuffix(line, `\`) + strings.TrimSpace(lines[i])
+		}
+
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		args, err := splitArgs(line)
+		if err != nil {
+			return nil, nil, fmt.Errorf("line %d: %w", lineNumber, err)
+		}
+
+		directive := strings.ToLower(args[0])
+
+		if last != nil && directive != "secrule" {
+			return nil, nil, fmt.Errorf("line %d: rule %d is chained to a %s directive", lineNumber, rules[len(rules)-1].id, args[0])
+		}
+
+		switch directive {
+		case "secrule", "secaction":
+			r, err := parseRule(directive, args[1:], last != nil)
+			if err != nil {
+				return nil, nil, fmt.Errorf("line %d: %w", lineNumber, err)
+			}
+
+			if last != nil {
+				last.chained = r
+
+				if r.scoreSet {
+					start := rules[len(rules)-1]
+					start.score += r.score
+					start.scoreSet = true
+				}
+			} else {
+				rules = append(rules, r)
+			}
+
+			last = nil
+			if r.chain {
+				last = r
+			}
+
+		case "secruleremovebyid":
+			if len(args) < 2 {
+				return nil, nil, fmt.Errorf("line %d: SecRuleRemoveById requires rule IDs", lineNumber)
+			}
+
+			for _, arg := range args[1:] {
+				for _, raw := range strings.Split(arg, ",") {
+					ids, err := parseIDRange(raw)
+					if err != nil {
+						return nil, nil, fmt.Errorf("line %d: %w", lineNumber, err)
+					}
+					removed = append(removed, ids)
+				}
+			}
+
+		case "secmarker":
+			// The markers are only used by the unsupported skipAfter action.
+
+		default:
+			return nil, nil, fmt.Errorf("line %d: unsupported directive %s", lineNumber, args[0])
+		}
+	}
+
+	if last != nil {
+		return nil, nil, fmt.Errorf("rule %d is chained to no rule", rules[len(rules)-1].id)
+	}
+
+	for _, r := range rules {
+		if !r.scoreSet {
+			r.score = severityScore(r.severity)
+		}
+	}
+
+	return rules, removed, nil
+}
+
+// parseRule parses the arguments of a SecRule or a SecAction directive.
+func parseRule(directive string, args []string, link bool) (*rule, error) {
+	r := &rule{phase: 2, severity: -1}
+
+	var actions string
+	if directive == "secaction" {
+		if len(args) != 1 {
+			return nil, errors.New("SecAction requires actions")
+		}
+		actions = args[0]
+	} else {
+		if len(args) < 2 || len(args) > 3 {
+			return nil, errors.New("SecRule requires variables, an operator, and actions")
+		}
+
+		var err error
+		r.variables, r.exclusions, err = parseVariables(args[0])
+		if err != nil {
+			return nil, err
+		}
+
+		r.operator, err = parseOperator(args[1])
+		if err != nil {
+			return nil, err
+		}
+
+		if len(args) == 3 {
+			actions = args[2]
+		}
+	}
+
+	if err := r.applyActions(actions, link); err != nil {
+		if r.id > 0 {
+			return nil, fmt.Errorf("rule %d: %w", r.id, err)
+		}
+		return nil, err
+	}
+
+	if !link && r.id == 0 {
+		return nil, errors.New("rule without id action")
+	}
+
+	return r, nil
+}
+
+// applyActions applies the actions of the rule, such as "id:1,phase:2,deny,t:lowercase".
+// The metadata and disruptive actions are not allowed in the links of a chained rule.
+func (r *rule) applyActions(raw string, link bool) error {
+	for _, action := range splitActions(raw) {
+		name, val, _ := strings.Cut(action, ":")
+		name = strings.TrimSpace(name)
+		val = strings.Trim(strings.TrimSpace(val), "'")
+
+		switch name {
+		case "id", "phase", "msg", "tag", "severity", "status", "deny", "drop", "block", "pass":
+			if link {
+				return fmt.Errorf("action %s is not allowed in a chained rule", name)
+			}
+		}
+
+		switch name {
+		case "id":
+			id, err := strconv.Atoi(val)
+			if err != nil || id <= 0 {
+				return fmt.Errorf("invalid id %q", val)
+			}
+			r.id = id
+
+		case "phase":
+			switch val {
+			case "1":
+				r.phase = 1
+			case "2", "request":
+				r.phase = 2
+			default:
+				return fmt.Errorf("unsupported phase %q: only the request phases 1 and 2 are supported", val)
+			}
+
+		case "msg":
+			r.msg = val
+
+		case "tag":
+			// The tags are only used to classify the rules.
+
+		case "severity":
+			severity, ok := severities[strings.ToUpper(val)]
+			if !ok {
+				var err error
+				severity, err = strconv.Atoi(val)
+				if err != nil || severity < 0 || severity > 7 {
+					return fmt.Errorf("invalid severity %q", val)
+				}
+			}
+			r.severity = severity
+
+		case "status":
+			status, err := strconv.Atoi(val)
+			if err != nil || status < 100 || status > 599 {
+				return fmt.Errorf("invalid status %q", val)
+			}
+			r.status = status
+
+		case "deny", "drop":
+			r.action = actionDeny
+
+		case "block":
+			r.action = actionBlock
+
+		case "pass":
+			r.action = actionPass
+
+		case "chain":
+			r.chain = true
+
+		case "multiMatch":
+			r.multiMatch = true
+
+		case "t":
+			if val == "none" {
+				r.transformations = nil
+				continue
+			}
+
+			t, ok := transformations[val]
+			if !ok {
+				return fmt.Errorf("unsupported transformation %s", val)
+			}
+			r.transformations = append(r.transformations, t)
+
+		case "setvar":
+			score, ok, err := parseAnomalyScore(val)
+			if err != nil {
+				return err
+			}
+			if ok {
+				r.score += score
+				r.scoreSet = true
+			}
+
+		default:
+			if _, ok := ignoredActions[name]; !ok {
+				return fmt.Errorf("unsupported action %s", name)
+			}
+		}
+	}
+
+	return nil
+}
+
+// parseAnomalyScore parses a setvar action incrementing an anomaly score, such as tx.anomaly_score=+%{tx.critical_anomaly_score}.
+// The setvar actions setting other variables are ignored.
+func parseAnomalyScore(raw string) (int, bool, error) {
+	name, val, _ := strings.Cut(raw, "=")
+	name = strings.ToLower(strings.TrimSpace(name))
+
+	if !strings.HasPrefix(name, "tx.") || !strings.Contains(name, "anomaly_score") {
+		return 0, false, nil
+	}
+
+	val = strings.TrimSpace(val)
+	if !strings.HasPrefix(val, "+") {
+		return 0, false, fmt.Errorf("unsupported setvar %q: only the increments of the anomaly score are supported", raw)
+	}
+	val = val[1:]
+
+	if m := anomalyScoreMacro.FindStringSubmatch(val); m != nil {
+		return anomalyScores[strings.ToLower(m[1])], true, nil
+	}
+
+	score, err := strconv.Atoi(val)
+	if err != nil {
+		return 0, false, fmt.Errorf("unsupported setvar %q: invalid anomaly score increment", raw)
+	}
+
+	return score, true, nil
+}
+
+// severityScore returns the anomaly score of a rule without setvar action, from its severity.
+func severityScore(severity int) int {
+	switch {
+	case severity < 0:
+		return defaultScore
+	case severity <= severities["CRITICAL"]:
+		return anomalyScores["critical"]
+	case severity == severities["ERROR"]:
+		return anomalyScores["error"]
+	case severity == severities["WARNING"]:
+		return anomalyScores["warning"]
+	case severity == severities["NOTICE"]:
+		return anomalyScores["notice"]
+	default:
+		return 0
+	}
+}
+
+// splitArgs splits a directive into its arguments, separated by spaces.
+// The arguments can be quoted with double or single quotes, and the escaped quotes are unescaped.
+func splitArgs(line string) ([]string, error) {
+	var args []string
+
+	for i := 0; i < len(line); {
+		if line[i] == ' ' || line[i] == '\t' {
+			i++
+			continue
+		}
+
+		quote := line[i]
+		if quote != '"' && quote != '\'' {
+			end := strings.IndexAny(line[i:], " \t")
+			if end < 0 {
+				end = len(line) - i
+			}
+			args = append(args, line[i:i+end])
+			i += end
+			continue
+		}
+
+		var b strings.Builder
+		i++
+		for ; i < len(line) && line[i] != quote; i++ {
+			if line[i] == '\\' && i+1 < len(line) && line[i+1] == quote {
+				i++
+			}
+			b.WriteByte(line[i])
+		}
+
+		if i >= len(line) {
+			return nil, fmt.Errorf("unterminated quoted argument in %q", line)
+		}
+
+		args = append(args, b.String())
+		i++
+	}
+
+	return args, nil
+}
+
+// splitActions splits the actions of a rule, separated by commas outside single quotes.
+func splitActions(raw string) []string {
+	var actions []string
+
+	inQuote := false
+	start := 0
+	for i := 0; i < len(raw); i++ {
+		switch {
+		case raw[i] == '\'':
+			inQuote = !inQuote
+		case raw[i] == ',' && !inQuote:
+			actions = append(actions, raw[start:i])
+			start = i + 1
+		}
+	}
+	actions = append(actions, raw[start:])
+
+	var result []string
+	for _, action := range actions {
+		if action = strings.TrimSpace(action); action != "" {
+			result = append(result, action)
+		}
+	}
+
+	return result
+}
+
+// matches reports whether the request matches the rule, and all its chained links.
+// It returns the variable matched by the first link.
+func (r *rule) matches(tx *transaction) (string, bool) {
+	var matched string
+
+	for link := r; link != nil; link = link.chained {
+		name, ok := link.matchesLink(tx)
+		if !ok {
+			return "", false
+		}
+
+		if link == r {
+			matched = name
+		}
+	}
+
+	return matched, true
+}
+
+func (r *rule) matchesLink(tx *transaction) (string, bool) {
+	if r.operator == nil {
+		// SecAction.
+		return "", true
+	}
+
+	for _, v := range r.variables {
+		for _, val := range tx.values(v, r.exclusions) {
+			if !r.test(val.val) {
+				continue
+			}
+
+			name := v.collection
+			if v.count {
+				name = "&" + name
+			}
+			if val.key != "" {
+				name += ":" + val.key
+			}
+
+			return name, true
+		}
+	}
+
+	return "", false
+}
+
+// test applies the transformations to the value, and tests the result with the operator.
+// With multiMatch, the operator is tested against the value before and after each transformation.
+func (r *rule) test(val string) bool {
+	if r.multiMatch && r.operator.test(val) {
+		return true
+	}
+
+	for _, t := range r.transformations {
+		val = t(val)
+		if r.multiMatch && r.operator.test(val) {
+			return true
+		}
+	}
+
+	return !r.multiMatch && r.operator.test(val)
+}
+
+func (o *operator) test(val string) bool {
+	return o.match(val) != o.negate
+}
+
+// excludeTargets removes the targets from the rule, and from its chained links.
+func (r *rule) excludeTargets(targets []selector) {
+	for link := r; link != nil; link = link.chained {
+		link.exclusions = append(link.exclusions, targets...)
+	}
+}
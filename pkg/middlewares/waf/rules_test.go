@@ -0,0 +1,257 @@
+package waf
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_parseRules(t *testing.T) {
+	testCases := []struct {
+		desc          string
+		content       string
+		expectedIDs   []int
+		expectedScore map[int]int
+		expectedError bool
+	}{
+		{
+			desc: "rules with comments and continuation lines",
+			content: `# SQL injection
+SecRule ARGS|REQUEST_COOKIES|!REQUEST_COOKIES:session "@detectSQLi" \
+    "id:942100,\
+    phase:2,\
+    block,\
+    t:none,t:urlDecodeUni,\
+    msg:'SQL Injection Attack Detected via libinjection',\
+    tag:'attack-sqli',\
+    severity:'CRITICAL',\
+    setvar:'tx.sql_injection_score=+%{tx.critical_anomaly_score}',\
+    setvar:'tx.inbound_anomaly_score_pl1=+%{tx.critical_anomaly_score}'"
+
+SecRule REQUEST_METHOD "!@within GET HEAD POST" "id:911100,phase:1,deny,status:405,msg:'Method is not allowed'"
+SecMarker END
+`,
+			expectedIDs:   []int{942100, 911100},
+			expectedScore: map[int]int{942100: 5, 911100: 5},
+		},
+		{
+			desc: "score from severity",
+			content: `SecRule ARGS "@contains foo" "id:1,severity:WARNING"
+SecRule ARGS "@contains bar" "id:2,severity:4"
+SecRule ARGS "@contains baz" "id:3,severity:INFO"
+SecRule ARGS "@contains qux" "id:4,setvar:tx.anomaly_score=+7"`,
+			expectedIDs:   []int{1, 2, 3, 4},
+			expectedScore: map[int]int{1: 3, 2: 3, 3: 0, 4: 7},
+		},
+		{
+			desc: "chained rule",
+			content: `SecRule REQUEST_METHOD "@streq POST" "id:1,phase:2,deny,chain"
+    SecRule &REQUEST_HEADERS:Content-Type "@eq 0" "t:none,setvar:tx.anomaly_score=+2"`,
+			expectedIDs:   []int{1},
+			expectedScore: map[int]int{1: 2},
+		},
+		{
+			desc: "removed rules",
+			content: `SecRule ARGS "@contains foo" "id:1"
+SecRule ARGS "@contains bar" "id:2"
+SecRuleRemoveById 1`,
+			expectedIDs: []int{1, 2},
+		},
+		{
+			desc:        "unconditional action",
+			content:     `SecAction "id:900000,phase:1,pass,nolog"`,
+			expectedIDs: []int{900000},
+		},
+		{
+			desc:          "missing id",
+			content:       `SecRule ARGS "@contains foo" "phase:2,deny"`,
+			expectedError: true,
+		},
+		{
+			desc:          "unsupported directive",
+			content:       `SecRuleEngine On`,
+			expectedError: true,
+		},
+		{
+			desc:          "unsupported variable",
+			content:       `SecRule TX:PARANOIA_LEVEL "@lt 1" "id:1"`,
+			expectedError: true,
+		},
+		{
+			desc:          "unsupported operator",
+			content:       `SecRule ARGS "@pmFromFile words.data" "id:1"`,
+			expectedError: true,
+		},
+		{
+			desc:          "unsupported transformation",
+			content:       `SecRule ARGS "@contains foo" "id:1,t:sha1"`,
+			expectedError: true,
+		},
+		{
+			desc:          "unsupported action",
+			content:       `SecRule ARGS "@contains foo" "id:1,skipAfter:END"`,
+			expectedError: true,
+		},
+		{
+			desc:          "response phase",
+			content:       `SecRule ARGS "@contains foo" "id:1,phase:4"`,
+			expectedError: true,
+		},
+		{
+			desc:          "invalid regular expression",
+			content:       `SecRule ARGS "@rx (foo" "id:1"`,
+			expectedError: true,
+		},
+		{
+			desc:          "macro expansion",
+			content:       `SecRule ARGS "@streq %{tx.foo}" "id:1"`,
+			expectedError: true,
+		},
+		{
+			desc:          "disruptive action in a chained rule",
+			content:       "SecRule ARGS \"@contains foo\" \"id:1,chain\"\nSecRule ARGS \"@contains bar\" \"deny\"",
+			expectedError: true,
+		},
+		{
+			desc:          "unterminated chain",
+			content:       `SecRule ARGS "@contains foo" "id:1,chain"`,
+			expectedError: true,
+		},
+		{
+			desc:          "unterminated quote",
+			content:       `SecRule ARGS "@contains foo "id:1"`,
+			expectedError: true,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			rules, _, err := parseRules(test.content)
+			if test.expectedError {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			var ids []int
+			for _, r := range rules {
+				ids = append(ids, r.id)
+
+				if score, ok := test.expectedScore[r.id]; ok {
+					assert.Equal(t, score, r.score, "score of rule %d", r.id)
+				}
+			}
+			assert.Equal(t, test.expectedIDs, ids)
+		})
+	}
+}
+
+func Test_rule_matches(t *testing.T) {
+	testCases := []struct {
+		desc             string
+		rule             string
+		target           string
+		expected         bool
+		expectedVariable string
+	}{
+		{
+			desc:             "regular expression on a named argument",
+			rule:             `SecRule ARGS:q "^foo" "id:1"`,
+			target:           "/?q=foobar&other=foo",
+			expected:         true,
+			expectedVariable: "ARGS:q",
+		},
+		{
+			desc:   "excluded argument",
+			rule:   `SecRule ARGS|!ARGS:q "@contains foo" "id:1"`,
+			target: "/?q=foobar",
+		},
+		{
+			desc:             "argument names",
+			rule:             `SecRule ARGS_NAMES "@rx ^_" "id:1"`,
+			target:           "/?_debug=1",
+			expected:         true,
+			expectedVariable: "ARGS_NAMES:_debug",
+		},
+		{
+			desc:             "argument selected by regular expression",
+			rule:             `SecRule ARGS:/^user/ "@streq admin" "id:1"`,
+			target:           "/?USERNAME=admin",
+			expected:         true,
+			expectedVariable: "ARGS:USERNAME",
+		},
+		{
+			desc:             "transformations",
+			rule:             `SecRule ARGS "@contains <script>" "id:1,t:urlDecodeUni,t:lowercase"`,
+			target:           "/?q=%253CSCRIPT%253E",
+			expected:         true,
+			expectedVariable: "ARGS:q",
+		},
+		{
+			desc:   "transformations reset",
+			rule:   `SecRule ARGS "@contains <script>" "id:1,t:lowercase,t:none"`,
+			target: "/?q=%3CSCRIPT%3E",
+		},
+		{
+			desc:             "multiMatch",
+			rule:             `SecRule ARGS "@streq %41" "id:1,t:urlDecode,multiMatch"`,
+			target:           "/?q=%2541",
+			expected:         true,
+			expectedVariable: "ARGS:q",
+		},
+		{
+			desc:             "argument count",
+			rule:             `SecRule &ARGS "@gt 2" "id:1"`,
+			target:           "/?a=1&b=2&c=3",
+			expected:         true,
+			expectedVariable: "&ARGS",
+		},
+		{
+			desc:   "negated operator",
+			rule:   `SecRule REQUEST_METHOD "!@within GET HEAD" "id:1"`,
+			target: "/",
+		},
+		{
+			desc:             "chained rule",
+			rule:             "SecRule REQUEST_FILENAME \"@endsWith .php\" \"id:1,chain\"\nSecRule ARGS:cmd \"@pm system exec\"",
+			target:           "/index.php?cmd=SYSTEM(id)",
+			expected:         true,
+			expectedVariable: "REQUEST_FILENAME",
+		},
+		{
+			desc:   "unmatched chained rule",
+			rule:   "SecRule REQUEST_FILENAME \"@endsWith .php\" \"id:1,chain\"\nSecRule ARGS:cmd \"@pm system exec\"",
+			target: "/index.php?cmd=ls",
+		},
+		{
+			desc:             "byte range",
+			rule:             `SecRule ARGS "@validateByteRange 32-126" "id:1"`,
+			target:           "/?q=foo%00",
+			expected:         true,
+			expectedVariable: "ARGS:q",
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			rules, _, err := parseRules(test.rule)
+			require.NoError(t, err)
+			require.Len(t, rules, 1)
+
+			tx := newTransaction(httptest.NewRequest(http.MethodGet, test.target, nil))
+
+			variable, ok := rules[0].matches(tx)
+			assert.Equal(t, test.expected, ok)
+			assert.Equal(t, test.expectedVariable, variable)
+		})
+	}
+}
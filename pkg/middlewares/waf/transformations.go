@@ -0,0 +1,265 @@
+package waf
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"html"
+	"path"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+type transformation func(string) string
+
+var transformations = map[string]transformation{
+	"lowercase":          strings.ToLower,
+	"uppercase":          strings.ToUpper,
+	"urlDecode":          urlDecode,
+	"urlDecodeUni":       urlDecodeUni,
+	"htmlEntityDecode":   html.UnescapeString,
+	"compressWhitespace": compressWhitespace,
+	"removeWhitespace":   removeWhitespace,
+	"removeNulls":        func(s string) string { return strings.ReplaceAll(s, "\x00", "") },
+	"replaceNulls":       func(s string) string { return strings.ReplaceAll(s, "\x00", " ") },
+	"trim":               func(s string) string { return strings.TrimFunc(s, isWhitespace) },
+	"trimLeft":           func(s string) string { return strings.TrimLeftFunc(s, isWhitespace) },
+	"trimRight":          func(s string) string { return strings.TrimRightFunc(s, isWhitespace) },
+	"normalizePath":      normalizePath,
+	"normalisePath":      normalizePath,
+	"normalizePathWin":   normalizePathWin,
+	"normalisePathWin":   normalizePathWin,
+	"base64Decode":       base64Decode,
+	"hexDecode":          hexDecode,
+	"cmdLine":            cmdLine,
+	"length":             func(s string) string { return strconv.Itoa(len(s)) },
+	"removeComments":     removeComments,
+	"replaceComments":    replaceComments,
+}
+
+func isWhitespace(r rune) bool {
+	switch r {
+	case ' ', '\t', '\n', '\r', '\f', '\v', 0xa0:
+		return true
+	default:
+		return false
+	}
+}
+
+// urlDecode decodes the %XX sequences and the + signs, keeping the invalid sequences as is.
+func urlDecode(s string) string {
+	return decodePercent(s, false)
+}
+
+// urlDecodeUni is like urlDecode, but also decodes the %uXXXX sequences.
+// The full-width ASCII characters are mapped to their ASCII equivalents, as done by ModSecurity.
+func urlDecodeUni(s string) string {
+	return decodePercent(s, true)
+}
+
+func decodePercent(s string, unicode bool) string {
+	if !strings.ContainsAny(s, "%+") {
+		return s
+	}
+
+	var b strings.Builder
+	b.Grow(len(s))
+
+	for i := 0; i < len(s); i++ {
+		switch {
+		case s[i] == '+':
+			b.WriteByte(' ')
+
+		case s[i] == '%' && unicode && i+5 < len(s) && (s[i+1] == 'u' || s[i+1] == 'U') && isHex(s[i+2:i+6]):
+			code, _ := strconv.ParseUint(s[i+2:i+6], 16, 32)
+			if code >= 0xff01 && code <= 0xff5e {
+				code -= 0xfee0
+			}
+			b.WriteRune(rune(code))
+			i += 5
+
+		case s[i] == '%' && i+2 < len(s) && isHex(s[i+1:i+3]):
+			v, _ := strconv.ParseUint(s[i+1:i+3], 16, 8)
+			b.WriteByte(byte(v))
+			i += 2
+
+		default:
+			b.WriteByte(s[i])
+		}
+	}
+
+	return b.String()
+}
+
+func isHex(s string) bool {
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if !('0' <= c && c <= '9' || 'a' <= c && c <= 'f' || 'A' <= c && c <= 'F') {
+			return false
+		}
+	}
+	return true
+}
+
+func compressWhitespace(s string) string {
+	var b strings.Builder
+	b.Grow(len(s))
+
+	inSpace := false
+	for _, r := range s {
+		if isWhitespace(r) {
+			if !inSpace {
+				b.WriteByte(' ')
+			}
+			inSpace = true
+			continue
+		}
+
+		inSpace = false
+		b.WriteRune(r)
+	}
+
+	return b.String()
+}
+
+func removeWhitespace(s string) string {
+	return strings.Map(func(r rune) rune {
+		if isWhitespace(r) {
+			return -1
+		}
+		return r
+	}, s)
+}
+
+// normalizePath removes the multiple slashes, and the self and back references, keeping the trailing slash.
+func normalizePath(s string) string {
+	if s == "" {
+		return s
+	}
+
+	cleaned := path.Clean(s)
+	if strings.HasSuffix(s, "/") && !strings.HasSuffix(cleaned, "/") {
+		cleaned += "/"
+	}
+
+	return cleaned
+}
+
+func normalizePathWin(s string) string {
+	return normalizePath(strings.ReplaceAll(s, `\`, "/"))
+}
+
+// base64Decode decodes a base64 value, with or without padding, or returns it as is if it is invalid.
+func base64Decode(s string) string {
+	for _, encoding := range []*base64.Encoding{base64.StdEncoding, base64.RawStdEncoding, base64.URLEncoding, base64.RawURLEncoding} {
+		if decoded, err := encoding.DecodeString(s); err == nil {
+			return string(decoded)
+		}
+	}
+	return s
+}
+
+// hexDecode decodes a hexadecimal value, or returns it as is if it is invalid.
+func hexDecode(s string) string {
+	decoded, err := hex.DecodeString(s)
+	if err != nil {
+		return s
+	}
+	return string(decoded)
+}
+
+// cmdLine normalizes a command line, as done by ModSecurity:
+// it removes the \ " ' and ^ characters, removes the spaces before the / and ( characters,
+// replaces the , and ; characters by spaces, compresses the whitespaces, and lowercases the value.
+func cmdLine(s string) string {
+	var b strings.Builder
+	b.Grow(len(s))
+
+	pendingSpace := false
+	for _, r := range s {
+		switch {
+		case r == '\\' || r == '"' || r == '\'' || r == '^':
+			continue
+
+		case r == ',' || r == ';' || isWhitespace(r):
+			pendingSpace = true
+			continue
+
+		case r == '/' || r == '(':
+			pendingSpace = false
+		}
+
+		if pendingSpace && b.Len() > 0 {
+			b.WriteByte(' ')
+		}
+		pendingSpace = false
+
+		b.WriteString(strings.ToLower(string(r)))
+	}
+
+	return b.String()
+}
+
+// replaceComments replaces each /* */ comment by a space.
+func replaceComments(s string) string {
+	var b strings.Builder
+	b.Grow(len(s))
+
+	for {
+		start := strings.Index(s, "/*")
+		if start < 0 {
+			b.WriteString(s)
+			return b.String()
+		}
+
+		b.WriteString(s[:start])
+		b.WriteByte(' ')
+
+		end := strings.Index(s[start+2:], "*/")
+		if end < 0 {
+			return b.String()
+		}
+		s = s[start+2+end+2:]
+	}
+}
+
+// removeComments removes the /* */ and <!-- --> comments, and the -- and # comments up to the end of the line.
+func removeComments(s string) string {
+	var b strings.Builder
+	b.Grow(len(s))
+
+	for i := 0; i < len(s); {
+		var end int
+		switch {
+		case strings.HasPrefix(s[i:], "/*"):
+			end = indexAfter(s, i+2, "*/")
+		case strings.HasPrefix(s[i:], "<!--"):
+			end = indexAfter(s, i+4, "-->")
+		case strings.HasPrefix(s[i:], "--"), s[i] == '#':
+			end = strings.IndexByte(s[i:], '\n')
+			if end < 0 {
+				end = len(s)
+			} else {
+				end += i
+			}
+		default:
+			_, size := utf8.DecodeRuneInString(s[i:])
+			b.WriteString(s[i : i+size])
+			i += size
+			continue
+		}
+
+		i = end
+	}
+
+	return b.String()
+}
+
+// indexAfter returns the index following the first occurrence of sep in s from the start index, or the length of s.
+func indexAfter(s string, start int, sep string) int {
+	idx := strings.Index(s[start:], sep)
+	if idx < 0 {
+		return len(s)
+	}
+	return start + idx + len(sep)
+}
@@ -0,0 +1,438 @@
+package waf
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// collections holds the supported variables, and whether their values have a name that can be selected.
+var collections = map[string]bool{
+	"ARGS":                  true,
+	"ARGS_GET":              true,
+	"ARGS_GET_NAMES":        false,
+	"ARGS_NAMES":            false,
+	"ARGS_POST":             true,
+	"ARGS_POST_NAMES":       false,
+	"QUERY_STRING":          false,
+	"REMOTE_ADDR":           false,
+	"REQUEST_BASENAME":      false,
+	"REQUEST_BODY":          false,
+	"REQUEST_COOKIES":       true,
+	"REQUEST_COOKIES_NAMES": false,
+	"REQUEST_FILENAME":      false,
+	"REQUEST_HEADERS":       true,
+	"REQUEST_HEADERS_NAMES": false,
+	"REQUEST_LINE":          false,
+	"REQUEST_METHOD":        false,
+	"REQUEST_PROTOCOL":      false,
+	"REQUEST_URI":           false,
+	"REQUEST_URI_RAW":       false,
+}
+
+// selector selects the values of a collection, by name, or by a regular expression matched against the names.
+// All the values are selected when both are empty.
+type selector struct {
+	collection string
+	key        string
+	keyRegex   *regexp.Regexp
+}
+
+func (s selector) String() string {
+	switch {
+	case s.keyRegex != nil:
+		return s.collection + ":/" + s.keyRegex.String()[len("(?i)"):] + "/"
+	case s.key != "":
+		return s.collection + ":" + s.key
+	default:
+		return s.collection
+	}
+}
+
+func (s selector) matches(collection string, v value) bool {
+	if s.collection != collection {
+		return false
+	}
+
+	switch {
+	case s.keyRegex != nil:
+		return s.keyRegex.MatchString(v.key)
+	case s.key != "":
+		return strings.EqualFold(s.key, v.key)
+	default:
+		return true
+	}
+}
+
+// variable is a target of a rule.
+type variable struct {
+	selector
+
+	// count makes the variable hold the number of selected values, such as &ARGS.
+	count bool
+}
+
+// parseVariables parses the variables of a rule, such as "ARGS|!ARGS:password|&REQUEST_HEADERS:Host".
+// It returns the variables, and the excluded targets.
+func parseVariables(raw string) ([]variable, []selector, error) {
+	var variables []variable
+	var exclusions []selector
+
+	for _, part := range strings.Split(raw, "|") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			return nil, nil, fmt.Errorf("empty variable in %q", raw)
+		}
+
+		switch part[0] {
+		case '!':
+			sel, err := parseSelector(part[1:])
+			if err != nil {
+				return nil, nil, err
+			}
+			if sel.key == "" && sel.keyRegex == nil {
+				return nil, nil, fmt.Errorf("variable exclusion %q must select a name", part)
+			}
+			exclusions = append(exclusions, sel)
+
+		case '&':
+			sel, err := parseSelector(part[1:])
+			if err != nil {
+				return nil, nil, err
+			}
+			variables = append(variables, variable{selector: sel, count: true})
+
+		default:
+			sel, err := parseSelector(part)
+			if err != nil {
+				return nil, nil, err
+			}
+			variables = append(variables, variable{selector: sel})
+		}
+	}
+
+	return variables, exclusions, nil
+}
+
+// parseSelector parses a variable selector, such as ARGS, ARGS:id, or ARGS:/^user/.
+func parseSelector(raw string) (selector, error) {
+	name, key, hasKey := strings.Cut(raw, ":")
+	name = strings.ToUpper(name)
+
+	selectable, ok := collections[name]
+	if !ok {
+		return selector{}, fmt.Errorf("unsupported variable %s", name)
+	}
+
+	sel := selector{collection: name}
+	if !hasKey {
+		return sel, nil
+	}
+
+	if !selectable {
+		return selector{}, fmt.Errorf("variable %s has no named values", name)
+	}
+
+	key = strings.Trim(key, `'`)
+	if key == "" {
+		return selector{}, fmt.Errorf("empty name in variable %q", raw)
+	}
+
+	if len(key) > 1 && strings.HasPrefix(key, "/") && strings.HasSuffix(key, "/") {
+		re, err := regexp.Compile("(?i)" + key[1:len(key)-1])
+		if err != nil {
+			return selector{}, fmt.Errorf("variable %q: %w", raw, err)
+		}
+		sel.keyRegex = re
+		return sel, nil
+	}
+
+	sel.key = key
+	return sel, nil
+}
+
+// value is a value of a collection, with its name.
+type value struct {
+	key string
+	val string
+}
+
+// transaction holds the data of a request inspected by the rules.
+// The collections are computed on demand, and cached.
+type transaction struct {
+	req   *http.Request
+	body  []byte
+	cache map[string][]value
+}
+
+func newTransaction(req *http.Request) *transaction {
+	return &transaction{req: req, cache: make(map[string][]value)}
+}
+
+// setBody sets the inspected request body, making the body variables available.
+func (tx *transaction) setBody(body []byte) {
+	tx.body = body
+	tx.cache = make(map[string][]value)
+}
+
+// values returns the values of a variable, without the excluded ones.
+func (tx *transaction) values(v variable, exclusions []selector) []value {
+	var values []value
+
+	for _, val := range tx.collection(v.collection) {
+		if !v.matches(v.collection, val) || isExcluded(v.collection, val, exclusions) {
+			continue
+		}
+
+		values = append(values, val)
+	}
+
+	if v.count {
+		return []value{{val: strconv.Itoa(len(values))}}
+	}
+
+	return values
+}
+
+func isExcluded(collection string, v value, exclusions []selector) bool {
+	for _, exclusion := range exclusions {
+		if exclusion.matches(collection, v) {
+			return true
+		}
+	}
+	return false
+}
+
+func (tx *transaction) collection(name string) []value {
+	if values, ok := tx.cache[name]; ok {
+		return values
+	}
+
+	values := tx.computeCollection(name)
+	tx.cache[name] = values
+
+	return values
+}
+
+func (tx *transaction) computeCollection(name string) []value {
+	req := tx.req
+
+	switch name {
+	case "ARGS":
+		return append(append([]value{}, tx.collection("ARGS_GET")...), tx.collection("ARGS_POST")...)
+
+	case "ARGS_GET":
+		// The parsing errors are ignored, to inspect the valid parameters.
+		query, _ := url.ParseQuery(req.URL.RawQuery)
+		return fromValues(query)
+
+	case "ARGS_POST":
+		return tx.bodyArguments()
+
+	case "ARGS_NAMES", "ARGS_GET_NAMES", "ARGS_POST_NAMES", "REQUEST_COOKIES_NAMES", "REQUEST_HEADERS_NAMES":
+		return names(tx.collection(strings.TrimSuffix(name, "_NAMES")))
+
+	case "QUERY_STRING":
+		return single(req.URL.RawQuery)
+
+	case "REMOTE_ADDR":
+		host, _, err := net.SplitHostPort(req.RemoteAddr)
+		if err != nil {
+			host = req.RemoteAddr
+		}
+		return single(host)
+
+	case "REQUEST_BASENAME":
+		filename := req.URL.EscapedPath()
+		return single(filename[strings.LastIndexByte(filename, '/')+1:])
+
+	case "REQUEST_BODY":
+		if tx.body == nil {
+			return nil
+		}
+		return single(string(tx.body))
+
+	case "REQUEST_COOKIES":
+		var values []value
+		for _, cookie := range req.Cookies() {
+			values = append(values, value{key: cookie.Name, val: cookie.Value})
+		}
+		return values
+
+	case "REQUEST_FILENAME":
+		return single(req.URL.EscapedPath())
+
+	case "REQUEST_HEADERS":
+		values := []value{{key: "Host", val: req.Host}}
+		for _, key := range sortedKeys(req.Header) {
+			for _, val := range req.Header[key] {
+				values = append(values, value{key: key, val: val})
+			}
+		}
+		return values
+
+	case "REQUEST_LINE":
+		return single(req.Method + " " + requestURI(req) + " " + req.Proto)
+
+	case "REQUEST_METHOD":
+		return single(req.Method)
+
+	case "REQUEST_PROTOCOL":
+		return single(req.Proto)
+
+	case "REQUEST_URI", "REQUEST_URI_RAW":
+		return single(requestURI(req))
+
+	default:
+		return nil
+	}
+}
+
+// bodyArguments parses the arguments of URL-encoded, multipart, and JSON request bodies.
+// The JSON objects are flattened, the names of the nested values being the path of the values, such as user.emails.0.
+func (tx *transaction) bodyArguments() []value {
+	if len(tx.body) == 0 {
+		return nil
+	}
+
+	mediaType, params, err := mime.ParseMediaType(tx.req.Header.Get("Content-Type"))
+	if err != nil {
+		return nil
+	}
+
+	switch {
+	case mediaType == "application/x-www-form-urlencoded":
+		form, _ := url.ParseQuery(string(tx.body))
+		return fromValues(form)
+
+	case mediaType == "multipart/form-data":
+		return multipartArguments(tx.body, params["boundary"])
+
+	case mediaType == "application/json" || strings.HasSuffix(mediaType, "+json"):
+		decoder := json.NewDecoder(bytes.NewReader(tx.body))
+		decoder.UseNumber()
+
+		var data interface{}
+		if err := decoder.Decode(&data); err != nil {
+			return nil
+		}
+
+		var values []value
+		flattenJSON("", data, &values)
+		return values
+
+	default:
+		return nil
+	}
+}
+
+// multipartArguments returns the form fields of a multipart body, the files being ignored.
+// The body may be truncated: the fields read before the truncation are returned.
+func multipartArguments(body []byte, boundary string) []value {
+	if boundary == "" {
+		return nil
+	}
+
+	var values []value
+
+	reader := multipart.NewReader(bytes.NewReader(body), boundary)
+	for {
+		part, err := reader.NextPart()
+		if err != nil {
+			return values
+		}
+
+		if part.FormName() == "" || part.FileName() != "" {
+			continue
+		}
+
+		content, err := io.ReadAll(part)
+		if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+			return values
+		}
+
+		values = append(values, value{key: part.FormName(), val: string(content)})
+	}
+}
+
+func flattenJSON(prefix string, data interface{}, values *[]value) {
+	switch d := data.(type) {
+	case map[string]interface{}:
+		keys := make([]string, 0, len(d))
+		for key := range d {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			flattenJSON(joinPath(prefix, key), d[key], values)
+		}
+
+	case []interface{}:
+		for i, item := range d {
+			flattenJSON(joinPath(prefix, strconv.Itoa(i)), item, values)
+		}
+
+	case nil:
+		*values = append(*values, value{key: prefix})
+
+	default:
+		*values = append(*values, value{key: prefix, val: fmt.Sprint(d)})
+	}
+}
+
+func joinPath(prefix, key string) string {
+	if prefix == "" {
+		return key
+	}
+	return prefix + "." + key
+}
+
+func requestURI(req *http.Request) string {
+	if req.RequestURI != "" {
+		return req.RequestURI
+	}
+	return req.URL.RequestURI()
+}
+
+func fromValues(params url.Values) []value {
+	var values []value
+	for _, key := range sortedKeys(params) {
+		for _, val := range params[key] {
+			values = append(values, value{key: key, val: val})
+		}
+	}
+	return values
+}
+
+func names(values []value) []value {
+	result := make([]value, 0, len(values))
+	for _, v := range values {
+		result = append(result, value{key: v.key, val: v.key})
+	}
+	return result
+}
+
+func single(val string) []value {
+	return []value{{val: val}}
+}
+
+func sortedKeys(m map[string][]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
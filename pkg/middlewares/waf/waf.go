@@ -0,0 +1,373 @@
+package waf
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+
+	gokitmetrics "github.com/go-kit/kit/metrics"
+	"github.com/opentracing/opentracing-go/ext"
+	"github.com/traefik/traefik/v2/pkg/config/dynamic"
+	"github.com/traefik/traefik/v2/pkg/log"
+	"github.com/traefik/traefik/v2/pkg/metrics"
+	"github.com/traefik/traefik/v2/pkg/middlewares"
+	"github.com/traefik/traefik/v2/pkg/middlewares/accesslog"
+	"github.com/traefik/traefik/v2/pkg/tracing"
+)
+
+const (
+	typeName = "WAF"
+
+	modeBlock  = "block"
+	modeDetect = "detect"
+
+	actionBlocked  = "blocked"
+	actionDetected = "detected"
+)
+
+// waf is a middleware inspecting the requests with a set of rules.
+type waf struct {
+	next             http.Handler
+	name             string
+	phases           [2][]*rule
+	block            bool
+	anomalyThreshold int
+	maxBodySize      int64
+
+	ruleMatchesCounter     gokitmetrics.Counter
+	blockedRequestsCounter gokitmetrics.Counter
+}
+
+// New creates a web application firewall middleware.
+func New(ctx context.Context, next http.Handler, config dynamic.WAF, name string, metricsRegistry metrics.Registry) (http.Handler, error) {
+	logger := log.FromContext(middlewares.GetLoggerCtx(ctx, name, typeName))
+	logger.Debug("Creating middleware")
+
+	var block bool
+	switch config.Mode {
+	case modeBlock:
+		block = true
+	case modeDetect:
+	default:
+		return nil, fmt.Errorf("unsupported mode %q: must be %s or %s", config.Mode, modeBlock, modeDetect)
+	}
+
+	if config.AnomalyThreshold <= 0 {
+		return nil, errors.New("anomalyThreshold must be greater than 0")
+	}
+
+	if config.MaxBodySize < 0 {
+		return nil, errors.New("maxBodySize must be greater than or equal to 0")
+	}
+
+	rules, err := loadRules(config)
+	if err != nil {
+		return nil, err
+	}
+
+	rules, err = applyExclusions(rules, config.Exclusions, middlewares.GetRouterName(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(rules) == 0 {
+		logger.Warn("No rule defined, all the requests are accepted")
+	} else {
+		logger.Debugf("Setting up WAF with %d rules", len(rules))
+	}
+
+	w := &waf{
+		next:                   next,
+		name:                   name,
+		block:                  block,
+		anomalyThreshold:       config.AnomalyThreshold,
+		maxBodySize:            config.MaxBodySize,
+		ruleMatchesCounter:     metricsRegistry.WAFRuleMatchesCounter(),
+		blockedRequestsCounter: metricsRegistry.WAFBlockedRequestsCounter(),
+	}
+
+	for _, r := range rules {
+		w.phases[r.phase-1] = append(w.phases[r.phase-1], r)
+	}
+
+	return w, nil
+}
+
+// loadRules parses the rules of the configuration, and of the rules files.
+func loadRules(config dynamic.WAF) ([]*rule, error) {
+	var rules []*rule
+	var removed []idRange
+
+	sources := make([]string, 0, len(config.Rules)+len(config.RulesFiles))
+	contents := make([]string, 0, len(config.Rules)+len(config.RulesFiles))
+
+	for i, content := range config.Rules {
+		sources = append(sources, "rules["+strconv.Itoa(i)+"]")
+		contents = append(contents, content)
+	}
+
+	for _, file := range config.RulesFiles {
+		content, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("reading rules file: %w", err)
+		}
+
+		sources = append(sources, file)
+		contents = append(contents, string(content))
+	}
+
+	ids := make(map[int]struct{})
+	for i, content := range contents {
+		parsed, removedIDs, err := parseRules(content)
+		if err != nil {
+			return nil, fmt.Errorf("parsing %s: %w", sources[i], err)
+		}
+
+		for _, r := range parsed {
+			if _, exists := ids[r.id]; exists {
+				return nil, fmt.Errorf("parsing %s: duplicate rule id %d", sources[i], r.id)
+			}
+			ids[r.id] = struct{}{}
+		}
+
+		rules = append(rules, parsed...)
+		removed = append(removed, removedIDs...)
+	}
+
+	return removeRules(rules, removed), nil
+}
+
+// applyExclusions removes the rules, or the targets of the rules, excluded for the router.
+func applyExclusions(rules []*rule, exclusions []dynamic.WAFExclusion, routerName string) ([]*rule, error) {
+	for _, exclusion := range exclusions {
+		ranges := make([]idRange, 0, len(exclusion.RuleIDs))
+		for _, raw := range exclusion.RuleIDs {
+			ids, err := parseIDRange(raw)
+			if err != nil {
+				return nil, fmt.Errorf("invalid exclusion: %w", err)
+			}
+			ranges = append(ranges, ids)
+		}
+
+		targets := make([]selector, 0, len(exclusion.Targets))
+		for _, raw := range exclusion.Targets {
+			target, err := parseSelector(raw)
+			if err != nil {
+				return nil, fmt.Errorf("invalid exclusion target: %w", err)
+			}
+			targets = append(targets, target)
+		}
+
+		if !appliesToRouter(exclusion.Routers, routerName) {
+			continue
+		}
+
+		if len(ranges) == 0 {
+			ranges = []idRange{{from: 0, to: int(^uint(0) >> 1)}}
+		}
+
+		if len(targets) == 0 {
+			rules = removeRules(rules, ranges)
+			continue
+		}
+
+		for _, r := range rules {
+			if inRanges(r.id, ranges) {
+				r.excludeTargets(targets)
+			}
+		}
+	}
+
+	return rules, nil
+}
+
+// appliesToRouter reports whether an exclusion applies to the router.
+// The routers can be referenced with or without their provider namespace.
+func appliesToRouter(routers []string, routerName string) bool {
+	if len(routers) == 0 {
+		return true
+	}
+
+	shortName, _, _ := strings.Cut(routerName, "@")
+	for _, router := range routers {
+		if routerName != "" && (router == routerName || router == shortName) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func removeRules(rules []*rule, ranges []idRange) []*rule {
+	if len(ranges) == 0 {
+		return rules
+	}
+
+	kept := make([]*rule, 0, len(rules))
+	for _, r := range rules {
+		if !inRanges(r.id, ranges) {
+			kept = append(kept, r)
+		}
+	}
+	return kept
+}
+
+func inRanges(id int, ranges []idRange) bool {
+	for _, ids := range ranges {
+		if ids.contains(id) {
+			return true
+		}
+	}
+	return false
+}
+
+func (w *waf) GetTracingInformation() (string, ext.SpanKindEnum) {
+	return w.name, tracing.SpanKindNoneEnum
+}
+
+func (w *waf) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	logger := log.FromContext(middlewares.GetLoggerCtx(req.Context(), w.name, typeName))
+
+	tx := newTransaction(req)
+	result := &evaluation{}
+
+	w.evaluate(tx, w.phases[0], result)
+
+	if len(w.phases[1]) > 0 && !(w.block && result.denied) {
+		body, err := w.inspectBody(req)
+		if err != nil {
+			logger.Debugf("Error while reading the request body: %v", err)
+
+			// The body is limited by a BodyLimit middleware.
+			var maxBytesErr *http.MaxBytesError
+			if errors.As(err, &maxBytesErr) {
+				http.Error(rw, http.StatusText(http.StatusRequestEntityTooLarge), http.StatusRequestEntityTooLarge)
+				return
+			}
+
+			http.Error(rw, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+			return
+		}
+
+		tx.setBody(body)
+		w.evaluate(tx, w.phases[1], result)
+	}
+
+	if len(result.matches) == 0 {
+		w.next.ServeHTTP(rw, req)
+		return
+	}
+
+	blocked := w.block && (result.denied || result.score >= w.anomalyThreshold)
+
+	action := actionDetected
+	if blocked {
+		action = actionBlocked
+	}
+
+	ids := make([]string, 0, len(result.matches))
+	for _, m := range result.matches {
+		id := strconv.Itoa(m.rule.id)
+		ids = append(ids, id)
+
+		w.ruleMatchesCounter.With("middleware", w.name, "rule", id).Add(1)
+
+		logger.Debugf("Rule %d matched %s: %s", m.rule.id, m.variable, m.rule.msg)
+	}
+
+	if logData := accesslog.GetLogData(req); logData != nil {
+		logData.Core[accesslog.WAFMatchedRules] = strings.Join(ids, ",")
+		logData.Core[accesslog.WAFAnomalyScore] = result.score
+		logData.Core[accesslog.WAFAction] = action
+	}
+
+	if !blocked {
+		w.next.ServeHTTP(rw, req)
+		return
+	}
+
+	w.blockedRequestsCounter.With("middleware", w.name).Add(1)
+
+	msg := fmt.Sprintf("Request blocked by the rules %s, with an anomaly score of %d", strings.Join(ids, ","), result.score)
+	logger.Debug(msg)
+	tracing.SetErrorWithEvent(req, msg)
+
+	status := result.status
+	if status == 0 {
+		status = http.StatusForbidden
+	}
+	http.Error(rw, http.StatusText(status), status)
+}
+
+// evaluation holds the result of the evaluation of the rules against a request.
+type evaluation struct {
+	matches []match
+	score   int
+	denied  bool
+	status  int
+}
+
+// match is a rule matched by a request.
+type match struct {
+	rule     *rule
+	variable string
+}
+
+// evaluate evaluates the rules of a phase.
+// In block mode, the evaluation stops at the first matched deny rule.
+func (w *waf) evaluate(tx *transaction, rules []*rule, result *evaluation) {
+	for _, r := range rules {
+		variable, ok := r.matches(tx)
+		if !ok {
+			continue
+		}
+
+		result.matches = append(result.matches, match{rule: r, variable: variable})
+
+		switch r.action {
+		case actionBlock:
+			result.score += r.score
+
+		case actionDeny:
+			if !result.denied {
+				result.denied = true
+				result.status = r.status
+			}
+
+			if w.block {
+				return
+			}
+		}
+	}
+}
+
+// inspectBody reads the request body up to the maximum inspected size,
+// and restores it so that it is entirely forwarded.
+func (w *waf) inspectBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil || req.Body == http.NoBody || w.maxBodySize == 0 {
+		return nil, nil
+	}
+
+	body, err := io.ReadAll(io.LimitReader(req.Body, w.maxBodySize))
+	if err != nil {
+		return nil, err
+	}
+
+	req.Body = readCloser{
+		Reader: io.MultiReader(bytes.NewReader(body), req.Body),
+		Closer: req.Body,
+	}
+
+	return body, nil
+}
+
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
@@ -0,0 +1,380 @@
+package waf
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/traefik/traefik/v2/pkg/config/dynamic"
+	"github.com/traefik/traefik/v2/pkg/metrics"
+	"github.com/traefik/traefik/v2/pkg/middlewares"
+	"github.com/traefik/traefik/v2/pkg/middlewares/accesslog"
+)
+
+const testRules = `
+SecRule REQUEST_METHOD "!@within GET HEAD POST" "id:911100,phase:1,deny,status:405,msg:'Method is not allowed'"
+SecRule ARGS "@detectSQLi" "id:942100,phase:2,block,t:urlDecodeUni,msg:'SQL Injection Attack',severity:CRITICAL"
+SecRule ARGS "@detectXSS" "id:941100,phase:2,block,t:urlDecodeUni,t:htmlEntityDecode,msg:'XSS Attack',severity:CRITICAL"
+SecRule REQUEST_HEADERS:User-Agent "@pm nikto sqlmap" "id:913100,phase:1,block,msg:'Security scanner',severity:WARNING"
+SecRule REQUEST_FILENAME "@endsWith .bak" "id:920440,phase:1,pass,msg:'Backup file access'"
+`
+
+func TestNew(t *testing.T) {
+	rulesFile := filepath.Join(t.TempDir(), "rules.conf")
+	require.NoError(t, os.WriteFile(rulesFile, []byte(testRules), 0o600))
+
+	testCases := []struct {
+		desc          string
+		config        dynamic.WAF
+		expectedError bool
+	}{
+		{
+			desc:   "rules",
+			config: dynamic.WAF{Rules: []string{testRules}},
+		},
+		{
+			desc:   "rules file",
+			config: dynamic.WAF{RulesFiles: []string{rulesFile}},
+		},
+		{
+			desc:          "missing rules file",
+			config:        dynamic.WAF{RulesFiles: []string{filepath.Join(t.TempDir(), "missing.conf")}},
+			expectedError: true,
+		},
+		{
+			desc:          "duplicate rule id",
+			config:        dynamic.WAF{Rules: []string{testRules}, RulesFiles: []string{rulesFile}},
+			expectedError: true,
+		},
+		{
+			desc:          "invalid rule",
+			config:        dynamic.WAF{Rules: []string{`SecRule ARGS "@contains foo" "id:1,ctl:ruleEngine=Off"`}},
+			expectedError: true,
+		},
+		{
+			desc:          "invalid mode",
+			config:        dynamic.WAF{Mode: "learn", Rules: []string{testRules}},
+			expectedError: true,
+		},
+		{
+			desc:          "invalid anomaly threshold",
+			config:        dynamic.WAF{AnomalyThreshold: -1, Rules: []string{testRules}},
+			expectedError: true,
+		},
+		{
+			desc: "invalid exclusion rule IDs",
+			config: dynamic.WAF{
+				Rules:      []string{testRules},
+				Exclusions: []dynamic.WAFExclusion{{RuleIDs: []string{"942199-942100"}}},
+			},
+			expectedError: true,
+		},
+		{
+			desc: "invalid exclusion target",
+			config: dynamic.WAF{
+				Rules:      []string{testRules},
+				Exclusions: []dynamic.WAFExclusion{{Targets: []string{"TX:foo"}}},
+			},
+			expectedError: true,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			config := dynamic.WAF{}
+			config.SetDefaults()
+			if test.config.Mode != "" {
+				config.Mode = test.config.Mode
+			}
+			if test.config.AnomalyThreshold != 0 {
+				config.AnomalyThreshold = test.config.AnomalyThreshold
+			}
+			config.Rules = test.config.Rules
+			config.RulesFiles = test.config.RulesFiles
+			config.Exclusions = test.config.Exclusions
+
+			next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {})
+
+			_, err := New(context.Background(), next, config, "waf", metrics.NewVoidRegistry())
+			if test.expectedError {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestWAF_ServeHTTP(t *testing.T) {
+	testCases := []struct {
+		desc            string
+		mode            string
+		threshold       int
+		request         func() *http.Request
+		expectedStatus  int
+		expectedRules   string
+		expectedScore   int
+		expectedAction  string
+		expectedForward bool
+	}{
+		{
+			desc: "clean request",
+			request: func() *http.Request {
+				return httptest.NewRequest(http.MethodGet, "/search?q=traefik", nil)
+			},
+			expectedStatus:  http.StatusOK,
+			expectedForward: true,
+		},
+		{
+			desc: "SQL injection in the query",
+			request: func() *http.Request {
+				return httptest.NewRequest(http.MethodGet, "/search?q=1%27%20OR%20%271%27%3D%271", nil)
+			},
+			expectedStatus: http.StatusForbidden,
+			expectedRules:  "942100",
+			expectedScore:  5,
+			expectedAction: "blocked",
+		},
+		{
+			desc: "XSS in a URL-encoded body",
+			request: func() *http.Request {
+				req := httptest.NewRequest(http.MethodPost, "/comments", strings.NewReader("comment=%3Cimg%20src%3Dx%20onerror%3Dalert(1)%3E"))
+				req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+				return req
+			},
+			expectedStatus: http.StatusForbidden,
+			expectedRules:  "941100",
+			expectedScore:  5,
+			expectedAction: "blocked",
+		},
+		{
+			desc: "SQL injection in a JSON body",
+			request: func() *http.Request {
+				req := httptest.NewRequest(http.MethodPost, "/login", strings.NewReader(`{"user":{"name":"admin'--"}}`))
+				req.Header.Set("Content-Type", "application/json")
+				return req
+			},
+			expectedStatus: http.StatusForbidden,
+			expectedRules:  "942100",
+			expectedScore:  5,
+			expectedAction: "blocked",
+		},
+		{
+			desc: "deny rule",
+			request: func() *http.Request {
+				return httptest.NewRequest(http.MethodDelete, "/?q=1%20OR%201%3D1", nil)
+			},
+			expectedStatus: http.StatusMethodNotAllowed,
+			expectedRules:  "911100",
+			expectedAction: "blocked",
+		},
+		{
+			desc: "score below the threshold",
+			request: func() *http.Request {
+				req := httptest.NewRequest(http.MethodGet, "/", nil)
+				req.Header.Set("User-Agent", "Nikto/2.1.6")
+				return req
+			},
+			expectedStatus:  http.StatusOK,
+			expectedRules:   "913100",
+			expectedScore:   3,
+			expectedAction:  "detected",
+			expectedForward: true,
+		},
+		{
+			desc: "scores summed up to the threshold",
+			request: func() *http.Request {
+				req := httptest.NewRequest(http.MethodGet, "/?q=%3Cscript%3E", nil)
+				req.Header.Set("User-Agent", "sqlmap/1.6")
+				return req
+			},
+			threshold:      8,
+			expectedStatus: http.StatusForbidden,
+			expectedRules:  "913100,941100",
+			expectedScore:  8,
+			expectedAction: "blocked",
+		},
+		{
+			desc: "pass rule",
+			request: func() *http.Request {
+				return httptest.NewRequest(http.MethodGet, "/backup.bak", nil)
+			},
+			expectedStatus:  http.StatusOK,
+			expectedRules:   "920440",
+			expectedAction:  "detected",
+			expectedForward: true,
+		},
+		{
+			desc: "detect mode",
+			mode: "detect",
+			request: func() *http.Request {
+				return httptest.NewRequest(http.MethodPut, "/?q=1%20UNION%20SELECT%20password%20FROM%20users", nil)
+			},
+			expectedStatus:  http.StatusOK,
+			expectedRules:   "911100,942100",
+			expectedScore:   5,
+			expectedAction:  "detected",
+			expectedForward: true,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			config := dynamic.WAF{}
+			config.SetDefaults()
+			config.Rules = []string{testRules}
+			if test.mode != "" {
+				config.Mode = test.mode
+			}
+			if test.threshold != 0 {
+				config.AnomalyThreshold = test.threshold
+			}
+
+			var forwarded bool
+			next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				forwarded = true
+			})
+
+			handler, err := New(context.Background(), next, config, "waf", metrics.NewVoidRegistry())
+			require.NoError(t, err)
+
+			logData := &accesslog.LogData{Core: accesslog.CoreLogData{}}
+			req := test.request()
+			req = req.WithContext(context.WithValue(req.Context(), accesslog.DataTableKey, logData))
+
+			rw := httptest.NewRecorder()
+			handler.ServeHTTP(rw, req)
+
+			assert.Equal(t, test.expectedStatus, rw.Code)
+			assert.Equal(t, test.expectedForward, forwarded)
+
+			if test.expectedRules == "" {
+				assert.NotContains(t, logData.Core, accesslog.WAFMatchedRules)
+				return
+			}
+
+			assert.Equal(t, test.expectedRules, logData.Core[accesslog.WAFMatchedRules])
+			assert.Equal(t, test.expectedScore, logData.Core[accesslog.WAFAnomalyScore])
+			assert.Equal(t, test.expectedAction, logData.Core[accesslog.WAFAction])
+		})
+	}
+}
+
+func TestWAF_exclusions(t *testing.T) {
+	testCases := []struct {
+		desc           string
+		routerName     string
+		exclusions     []dynamic.WAFExclusion
+		target         string
+		expectedStatus int
+	}{
+		{
+			desc:           "no exclusion",
+			routerName:     "cms@file",
+			target:         "/?content=%3Cscript%3E",
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			desc:           "excluded rule",
+			routerName:     "cms@file",
+			exclusions:     []dynamic.WAFExclusion{{Routers: []string{"cms"}, RuleIDs: []string{"941100-941199"}}},
+			target:         "/?content=%3Cscript%3E",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			desc:           "excluded rule for another router",
+			routerName:     "api@file",
+			exclusions:     []dynamic.WAFExclusion{{Routers: []string{"cms@file"}, RuleIDs: []string{"941100"}}},
+			target:         "/?content=%3Cscript%3E",
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			desc:           "excluded target",
+			routerName:     "cms@file",
+			exclusions:     []dynamic.WAFExclusion{{RuleIDs: []string{"941100"}, Targets: []string{"ARGS:content"}}},
+			target:         "/?content=%3Cscript%3E",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			desc:           "excluded target on another argument",
+			routerName:     "cms@file",
+			exclusions:     []dynamic.WAFExclusion{{RuleIDs: []string{"941100"}, Targets: []string{"ARGS:content"}}},
+			target:         "/?content=%3Cscript%3E&title=%3Cscript%3E",
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			desc:           "excluded target for all rules",
+			routerName:     "cms@file",
+			exclusions:     []dynamic.WAFExclusion{{Routers: []string{"cms@file"}, Targets: []string{"ARGS:content"}}},
+			target:         "/?content=%3Cscript%3E",
+			expectedStatus: http.StatusOK,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			config := dynamic.WAF{}
+			config.SetDefaults()
+			config.Rules = []string{testRules}
+			config.Exclusions = test.exclusions
+
+			next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {})
+
+			ctx := middlewares.AddRouterNameInContext(context.Background(), test.routerName)
+			handler, err := New(ctx, next, config, "waf", metrics.NewVoidRegistry())
+			require.NoError(t, err)
+
+			rw := httptest.NewRecorder()
+			handler.ServeHTTP(rw, httptest.NewRequest(http.MethodGet, test.target, nil))
+
+			assert.Equal(t, test.expectedStatus, rw.Code)
+		})
+	}
+}
+
+func TestWAF_bodyInspectionLimit(t *testing.T) {
+	config := dynamic.WAF{}
+	config.SetDefaults()
+	config.Rules = []string{`SecRule REQUEST_BODY "@contains <script>" "id:1,phase:2,deny"`}
+	config.MaxBodySize = 16
+
+	var forwardedBody string
+	next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		body, err := io.ReadAll(req.Body)
+		require.NoError(t, err)
+		forwardedBody = string(body)
+	})
+
+	handler, err := New(context.Background(), next, config, "waf", metrics.NewVoidRegistry())
+	require.NoError(t, err)
+
+	// The body is inspected up to the limit.
+	rw := httptest.NewRecorder()
+	handler.ServeHTTP(rw, httptest.NewRequest(http.MethodPost, "/", strings.NewReader("foo=<script>")))
+	assert.Equal(t, http.StatusForbidden, rw.Code)
+
+	// The rest of the body is forwarded without being inspected.
+	body := strings.Repeat("a", 16) + "<script>"
+
+	rw = httptest.NewRecorder()
+	handler.ServeHTTP(rw, httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body)))
+	assert.Equal(t, http.StatusOK, rw.Code)
+	assert.Equal(t, body, forwardedBody)
+}
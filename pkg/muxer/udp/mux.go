@@ -0,0 +1,230 @@
+package udp
+
+import (
+	"fmt"
+	"net"
+	"sort"
+
+	"github.com/traefik/traefik/v2/pkg/ip"
+	"github.com/traefik/traefik/v2/pkg/log"
+	"github.com/traefik/traefik/v2/pkg/rules"
+	"github.com/traefik/traefik/v2/pkg/udp"
+	"github.com/vulcand/predicate"
+)
+
+var udpFuncs = map[string]func(*matchersTree, ...string) error{
+	"ClientIP": clientIP,
+}
+
+// ConnData contains UDP session metadata.
+type ConnData struct {
+	remoteIP string
+}
+
+// NewConnData builds a connData struct from the remote address of a session.
+func NewConnData(remoteAddr net.Addr) (ConnData, error) {
+	remoteIP, _, err := net.SplitHostPort(remoteAddr.String())
+	if err != nil {
+		return ConnData{}, fmt.Errorf("error while parsing remote address %q: %w", remoteAddr.String(), err)
+	}
+
+	return ConnData{remoteIP: remoteIP}, nil
+}
+
+// Muxer defines a muxer that handles UDP routing with rules.
+type Muxer struct {
+	routes []*route
+	parser predicate.Parser
+}
+
+// NewMuxer returns a UDP muxer.
+func NewMuxer() (*Muxer, error) {
+	var matcherNames []string
+	for matcherName := range udpFuncs {
+		matcherNames = append(matcherNames, matcherName)
+	}
+
+	parser, err := rules.NewParser(matcherNames)
+	if err != nil {
+		return nil, fmt.Errorf("error while creating rules parser: %w", err)
+	}
+
+	return &Muxer{parser: parser}, nil
+}
+
+// Match returns the handler of the first route matching the session metadata.
+func (m Muxer) Match(meta ConnData) udp.Handler {
+	for _, route := range m.routes {
+		if route.matchers.match(meta) {
+			return route.handler
+		}
+	}
+
+	return nil
+}
+
+// AddRoute adds a new route, associated to the given handler, at the given
+// priority, to the muxer.
+// An empty rule matches all the sessions.
+func (m *Muxer) AddRoute(rule string, priority int, handler udp.Handler) error {
+	var matchers matchersTree
+	if rule == "" {
+		matchers.matcher = func(ConnData) bool { return true }
+	} else {
+		parse, err := m.parser.Parse(rule)
+		if err != nil {
+			return fmt.Errorf("error while parsing rule %s: %w", rule, err)
+		}
+
+		buildTree, ok := parse.(rules.TreeBuilder)
+		if !ok {
+			return fmt.Errorf("error while parsing rule %s", rule)
+		}
+
+		err = addRule(&matchers, buildTree())
+		if err != nil {
+			return err
+		}
+	}
+
+	// Default value, which means the user has not set it, so we'll compute it.
+	// A route without rule therefore comes last, unless a priority is set.
+	if priority == 0 {
+		priority = len(rule)
+	}
+
+	newRoute := &route{
+		handler:  handler,
+		matchers: matchers,
+		priority: priority,
+	}
+	m.routes = append(m.routes, newRoute)
+
+	sort.Stable(routes(m.routes))
+
+	return nil
+}
+
+func addRule(tree *matchersTree, rule *rules.Tree) error {
+	switch rule.Matcher {
+	case "and", "or":
+		tree.operator = rule.Matcher
+		tree.left = &matchersTree{}
+		err := addRule(tree.left, rule.RuleLeft)
+		if err != nil {
+			return err
+		}
+
+		tree.right = &matchersTree{}
+		return addRule(tree.right, rule.RuleRight)
+	default:
+		err := rules.CheckRule(rule)
+		if err != nil {
+			return err
+		}
+
+		err = udpFuncs[rule.Matcher](tree, rule.Value...)
+		if err != nil {
+			return err
+		}
+
+		if rule.Not {
+			matcherFunc := tree.matcher
+			tree.matcher = func(meta ConnData) bool {
+				return !matcherFunc(meta)
+			}
+		}
+	}
+
+	return nil
+}
+
+// HasRoutes returns whether the muxer has routes.
+func (m *Muxer) HasRoutes() bool {
+	return len(m.routes) > 0
+}
+
+// routes implements sort.Interface.
+type routes []*route
+
+// Len implements sort.Interface.
+func (r routes) Len() int { return len(r) }
+
+// Swap implements sort.Interface.
+func (r routes) Swap(i, j int) { r[i], r[j] = r[j], r[i] }
+
+// Less implements sort.Interface.
+func (r routes) Less(i, j int) bool { return r[i].priority > r[j].priority }
+
+// route holds the matchers to match UDP route,
+// and the handler that will serve the session.
+type route struct {
+	// matchers tree structure reflecting the rule.
+	matchers matchersTree
+	// handler responsible for handling the route.
+	handler udp.Handler
+	// priority is used to disambiguate between two (or more) rules that would
+	// all match for a given session.
+	// Computed from the matching rule length, if not user-set.
+	priority int
+}
+
+// matcher is a matcher func used to match session properties.
+type matcher func(meta ConnData) bool
+
+// matchersTree represents the matchers tree structure.
+type matchersTree struct {
+	// If matcher is not nil, it means that this matcherTree is a leaf of the tree.
+	// It is therefore mutually exclusive with left and right.
+	matcher matcher
+	// operator to combine the evaluation of left and right leaves.
+	operator string
+	// Mutually exclusive with matcher.
+	left  *matchersTree
+	right *matchersTree
+}
+
+func (m *matchersTree) match(meta ConnData) bool {
+	if m == nil {
+		// This should never happen as it should have been detected during parsing.
+		log.WithoutContext().Warnf("Rule matcher is nil")
+		return false
+	}
+
+	if m.matcher != nil {
+		return m.matcher(meta)
+	}
+
+	switch m.operator {
+	case "or":
+		return m.left.match(meta) || m.right.match(meta)
+	case "and":
+		return m.left.match(meta) && m.right.match(meta)
+	default:
+		// This should never happen as it should have been detected during parsing.
+		log.WithoutContext().Warnf("Invalid rule operator %s", m.operator)
+		return false
+	}
+}
+
+func clientIP(tree *matchersTree, clientIPs ...string) error {
+	checker, err := ip.NewChecker(clientIPs)
+	if err != nil {
+		return fmt.Errorf("could not initialize IP Checker for \"ClientIP\" matcher: %w", err)
+	}
+
+	tree.matcher = func(meta ConnData) bool {
+		if meta.remoteIP == "" {
+			return false
+		}
+
+		ok, err := checker.Contains(meta.remoteIP)
+		if err != nil {
+			log.WithoutContext().Warnf("\"ClientIP\" matcher: could not match remote address: %v", err)
+			return false
+		}
+		return ok
+	}
+
+	return nil
+}
@@ -0,0 +1,185 @@
+package udp
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/traefik/traefik/v2/pkg/udp"
+)
+
+type namedHandler string
+
+func (h namedHandler) ServeUDP(*udp.Conn) {}
+
+func Test_ClientIP(t *testing.T) {
+	testCases := []struct {
+		desc        string
+		rule        string
+		remoteAddr  string
+		expectedErr bool
+		matched     bool
+	}{
+		{
+			desc:        "Empty ClientIP",
+			rule:        "ClientIP(``)",
+			expectedErr: true,
+		},
+		{
+			desc:        "Invalid ClientIP",
+			rule:        "ClientIP(`invalid`)",
+			expectedErr: true,
+		},
+		{
+			desc:        "Unknown matcher",
+			rule:        "HostSNI(`foo.bar`)",
+			expectedErr: true,
+		},
+		{
+			desc:       "Matching IP",
+			rule:       "ClientIP(`10.0.0.1`)",
+			remoteAddr: "10.0.0.1:53",
+			matched:    true,
+		},
+		{
+			desc:       "Not matching IP",
+			rule:       "ClientIP(`10.0.0.1`)",
+			remoteAddr: "10.0.0.2:53",
+		},
+		{
+			desc:       "Matching CIDR",
+			rule:       "ClientIP(`10.0.0.0/8`)",
+			remoteAddr: "10.1.2.3:53",
+			matched:    true,
+		},
+		{
+			desc:       "Matching IPv6 CIDR",
+			rule:       "ClientIP(`fe80::/64`)",
+			remoteAddr: "[fe80::1]:53",
+			matched:    true,
+		},
+		{
+			desc:       "Matching one of several",
+			rule:       "ClientIP(`10.0.0.1`, `192.168.0.0/16`)",
+			remoteAddr: "192.168.1.1:53",
+			matched:    true,
+		},
+		{
+			desc:       "Negated",
+			rule:       "!ClientIP(`10.0.0.0/8`)",
+			remoteAddr: "192.168.1.1:53",
+			matched:    true,
+		},
+		{
+			desc:       "Or operator",
+			rule:       "ClientIP(`10.0.0.0/8`) || ClientIP(`192.168.0.0/16`)",
+			remoteAddr: "192.168.1.1:53",
+			matched:    true,
+		},
+		{
+			desc:       "And operator",
+			rule:       "ClientIP(`10.0.0.0/8`) && !ClientIP(`10.0.0.1`)",
+			remoteAddr: "10.0.0.1:53",
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			muxer, err := NewMuxer()
+			require.NoError(t, err)
+
+			err = muxer.AddRoute(test.rule, 0, namedHandler("handler"))
+			if test.expectedErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			addr, err := net.ResolveUDPAddr("udp", test.remoteAddr)
+			require.NoError(t, err)
+
+			meta, err := NewConnData(addr)
+			require.NoError(t, err)
+
+			handler := muxer.Match(meta)
+			assert.Equal(t, test.matched, handler != nil)
+		})
+	}
+}
+
+func Test_Priority(t *testing.T) {
+	testCases := []struct {
+		desc            string
+		rules           map[string]int
+		remoteAddr      string
+		expectedHandler string
+	}{
+		{
+			desc: "Longest rule first when no priority",
+			rules: map[string]int{
+				"ClientIP(`10.0.0.0/8`)":  0,
+				"ClientIP(`10.0.0.0/16`)": 0,
+			},
+			remoteAddr:      "10.0.0.1:53",
+			expectedHandler: "ClientIP(`10.0.0.0/16`)",
+		},
+		{
+			desc: "Higher priority first",
+			rules: map[string]int{
+				"ClientIP(`10.0.0.0/8`)":  100,
+				"ClientIP(`10.0.0.0/16`)": 0,
+			},
+			remoteAddr:      "10.0.0.1:53",
+			expectedHandler: "ClientIP(`10.0.0.0/8`)",
+		},
+		{
+			desc: "Route without rule as fallback",
+			rules: map[string]int{
+				"":                       0,
+				"ClientIP(`10.0.0.0/8`)": 0,
+			},
+			remoteAddr:      "192.168.0.1:53",
+			expectedHandler: "",
+		},
+		{
+			desc: "Route with rule before route without rule",
+			rules: map[string]int{
+				"":                       0,
+				"ClientIP(`10.0.0.0/8`)": 0,
+			},
+			remoteAddr:      "10.0.0.1:53",
+			expectedHandler: "ClientIP(`10.0.0.0/8`)",
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			muxer, err := NewMuxer()
+			require.NoError(t, err)
+
+			for rule, priority := range test.rules {
+				err = muxer.AddRoute(rule, priority, namedHandler(rule))
+				require.NoError(t, err)
+			}
+
+			addr, err := net.ResolveUDPAddr("udp", test.remoteAddr)
+			require.NoError(t, err)
+
+			meta, err := NewConnData(addr)
+			require.NoError(t, err)
+
+			handler := muxer.Match(meta)
+			require.NotNil(t, handler)
+			assert.Equal(t, namedHandler(test.expectedHandler), handler)
+		})
+	}
+}
@@ -0,0 +1,233 @@
+package acme
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-acme/lego/v4/challenge"
+	"github.com/go-acme/lego/v4/challenge/dns01"
+	"github.com/go-acme/lego/v4/providers/dns"
+)
+
+// cnameSupportEnv is the environment variable making lego follow the CNAME of the _acme-challenge records.
+const cnameSupportEnv = "LEGO_EXPERIMENTAL_CNAME_SUPPORT"
+
+// envLock serializes the creation of the DNS providers, which read their configuration from the environment,
+// with the changes of the environment.
+var envLock sync.Mutex
+
+// newDNSProvider creates the DNS challenge provider with the given name,
+// the given environment variables overriding the process environment during its creation.
+func newDNSProvider(name string, env map[string]string) (challenge.Provider, error) {
+	envLock.Lock()
+	defer envLock.Unlock()
+
+	previous := make(map[string]*string)
+	defer func() {
+		for key, value := range previous {
+			if value == nil {
+				_ = os.Unsetenv(key)
+				continue
+			}
+
+			_ = os.Setenv(key, *value)
+		}
+	}()
+
+	for key, value := range env {
+		key = strings.ToUpper(key)
+
+		if _, ok := previous[key]; !ok {
+			if current, ok := os.LookupEnv(key); ok {
+				previous[key] = &current
+			} else {
+				previous[key] = nil
+			}
+		}
+
+		if err := os.Setenv(key, value); err != nil {
+			return nil, err
+		}
+	}
+
+	return dns.NewDNSChallengeProviderByName(name)
+}
+
+// newDNSChallengeProvider creates the DNS challenge provider of the configuration,
+// selecting the provider by domain when domains are configured.
+func newDNSChallengeProvider(conf *DNSChallenge) (challenge.Provider, error) {
+	if conf.FollowCNAME {
+		// lego only supports the CNAME following process-wide.
+		envLock.Lock()
+		err := os.Setenv(cnameSupportEnv, "true")
+		envLock.Unlock()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if len(conf.Domains) == 0 {
+		return newDNSProvider(conf.Provider, nil)
+	}
+
+	selector := &dnsProviderSelector{}
+
+	if conf.Provider != "" {
+		provider, err := newDNSProvider(conf.Provider, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		selector.fallback = provider
+	}
+
+	sequential := isSequential(selector.fallback)
+
+	for _, domain := range conf.Domains {
+		suffix := normalizeDNSName(domain.Domain)
+		if suffix == "" {
+			return nil, errors.New("DNS challenge domain is empty")
+		}
+
+		if domain.Provider == "" {
+			return nil, fmt.Errorf("DNS challenge provider is missing for domain %s", domain.Domain)
+		}
+
+		provider, err := newDNSProvider(domain.Provider, domain.Env)
+		if err != nil {
+			return nil, fmt.Errorf("unable to create DNS challenge provider for domain %s: %w", domain.Domain, err)
+		}
+
+		sequential = sequential || isSequential(provider)
+
+		selector.domains = append(selector.domains, dnsDomainProvider{suffix: suffix, provider: provider})
+	}
+
+	// Prefers the most specific domains.
+	sort.SliceStable(selector.domains, func(i, j int) bool {
+		return len(selector.domains[i].suffix) > len(selector.domains[j].suffix)
+	})
+
+	if sequential {
+		return &sequentialDNSProviderSelector{selector}, nil
+	}
+
+	return selector, nil
+}
+
+type dnsDomainProvider struct {
+	suffix   string
+	provider challenge.Provider
+}
+
+// dnsProviderSelector implements challenge.Provider.
+// It solves the challenges with the provider of the domain of the challenge record,
+// which is the target of the _acme-challenge CNAME, if any, when the CNAME following is enabled.
+type dnsProviderSelector struct {
+	domains  []dnsDomainProvider
+	fallback challenge.Provider
+}
+
+// Present creates the TXT record of the challenge with the provider of the record domain.
+func (s *dnsProviderSelector) Present(domain, token, keyAuth string) error {
+	provider, err := s.provider(domain, keyAuth)
+	if err != nil {
+		return err
+	}
+
+	return provider.Present(domain, token, keyAuth)
+}
+
+// CleanUp removes the TXT record of the challenge with the provider of the record domain.
+func (s *dnsProviderSelector) CleanUp(domain, token, keyAuth string) error {
+	provider, err := s.provider(domain, keyAuth)
+	if err != nil {
+		return err
+	}
+
+	return provider.CleanUp(domain, token, keyAuth)
+}
+
+// Timeout returns the longest propagation timeout and polling interval of the providers.
+func (s *dnsProviderSelector) Timeout() (time.Duration, time.Duration) {
+	timeout, interval := dns01.DefaultPropagationTimeout, dns01.DefaultPollingInterval
+
+	for _, provider := range s.providers() {
+		p, ok := provider.(challenge.ProviderTimeout)
+		if !ok {
+			continue
+		}
+
+		t, i := p.Timeout()
+		if t > timeout {
+			timeout = t
+		}
+		if i > interval {
+			interval = i
+		}
+	}
+
+	return timeout, interval
+}
+
+func (s *dnsProviderSelector) provider(domain, keyAuth string) (challenge.Provider, error) {
+	fqdn, _ := dns01.GetRecord(domain, keyAuth)
+	name := normalizeDNSName(fqdn)
+
+	for _, d := range s.domains {
+		if name == d.suffix || strings.HasSuffix(name, "."+d.suffix) {
+			return d.provider, nil
+		}
+	}
+
+	if s.fallback != nil {
+		return s.fallback, nil
+	}
+
+	return nil, fmt.Errorf("no DNS challenge provider for %s", fqdn)
+}
+
+func (s *dnsProviderSelector) providers() []challenge.Provider {
+	var providers []challenge.Provider
+	if s.fallback != nil {
+		providers = append(providers, s.fallback)
+	}
+
+	for _, d := range s.domains {
+		providers = append(providers, d.provider)
+	}
+
+	return providers
+}
+
+// sequentialDNSProviderSelector is a dnsProviderSelector with at least one provider solving the challenges sequentially.
+type sequentialDNSProviderSelector struct {
+	*dnsProviderSelector
+}
+
+// Sequential returns the longest interval between the challenges of the sequential providers.
+func (s *sequentialDNSProviderSelector) Sequential() time.Duration {
+	var interval time.Duration
+	for _, provider := range s.providers() {
+		p, ok := provider.(interface{ Sequential() time.Duration })
+		if ok && p.Sequential() > interval {
+			interval = p.Sequential()
+		}
+	}
+
+	return interval
+}
+
+func isSequential(provider challenge.Provider) bool {
+	_, ok := provider.(interface{ Sequential() time.Duration })
+	return ok
+}
+
+func normalizeDNSName(name string) string {
+	return strings.ToLower(strings.TrimSuffix(strings.TrimPrefix(strings.TrimSpace(name), "*."), "."))
+}
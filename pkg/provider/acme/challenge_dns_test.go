@@ -0,0 +1,237 @@
+package acme
+
+import (
+	"net"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/go-acme/lego/v4/challenge"
+	"github.com/go-acme/lego/v4/challenge/dns01"
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDNSProviderSelector(t *testing.T) {
+	testCases := []struct {
+		desc             string
+		domain           string
+		withoutFallback  bool
+		expectedProvider string
+		expectedError    bool
+	}{
+		{
+			desc:             "domain",
+			domain:           "example.org",
+			expectedProvider: "example.org",
+		},
+		{
+			desc:             "subdomain",
+			domain:           "foo.example.org",
+			expectedProvider: "example.org",
+		},
+		{
+			desc:             "wildcard",
+			domain:           "*.example.org",
+			expectedProvider: "example.org",
+		},
+		{
+			desc:             "most specific domain",
+			domain:           "foo.sub.example.org",
+			expectedProvider: "sub.example.org",
+		},
+		{
+			desc:             "domain with the same suffix",
+			domain:           "myexample.org",
+			expectedProvider: "fallback",
+		},
+		{
+			desc:             "other domain",
+			domain:           "example.com",
+			expectedProvider: "fallback",
+		},
+		{
+			desc:            "other domain without fallback",
+			domain:          "example.com",
+			withoutFallback: true,
+			expectedError:   true,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			providers := map[string]*fakeDNSProvider{
+				"example.org":     {},
+				"sub.example.org": {},
+				"fallback":        {},
+			}
+
+			selector := &dnsProviderSelector{
+				domains: []dnsDomainProvider{
+					{suffix: "sub.example.org", provider: providers["sub.example.org"]},
+					{suffix: "example.org", provider: providers["example.org"]},
+				},
+			}
+			if !test.withoutFallback {
+				selector.fallback = providers["fallback"]
+			}
+
+			err := selector.Present(test.domain, "token", "keyAuth")
+			if test.expectedError {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			require.NoError(t, selector.CleanUp(test.domain, "token", "keyAuth"))
+
+			for name, provider := range providers {
+				if name == test.expectedProvider {
+					assert.Equal(t, []string{test.domain}, provider.presented)
+					assert.Equal(t, []string{test.domain}, provider.cleaned)
+					continue
+				}
+
+				assert.Empty(t, provider.presented, name)
+			}
+		})
+	}
+}
+
+func TestDNSProviderSelector_CNAME(t *testing.T) {
+	server := startDNSServer(t, map[string]string{
+		"_acme-challenge.app.example.org.": "app.validation.test.",
+	})
+
+	t.Setenv(cnameSupportEnv, "true")
+	require.NoError(t, dns01.AddRecursiveNameservers([]string{server})(nil))
+
+	validation := &fakeDNSProvider{}
+	zone := &fakeDNSProvider{}
+
+	selector := &dnsProviderSelector{
+		domains: []dnsDomainProvider{
+			{suffix: "validation.test", provider: validation},
+			{suffix: "example.org", provider: zone},
+		},
+	}
+
+	// The challenge record of the delegated domain is created in the validation zone.
+	require.NoError(t, selector.Present("app.example.org", "token", "keyAuth"))
+	assert.Equal(t, []string{"app.example.org"}, validation.presented)
+	assert.Empty(t, zone.presented)
+
+	require.NoError(t, selector.Present("other.example.org", "token", "keyAuth"))
+	assert.Equal(t, []string{"other.example.org"}, zone.presented)
+}
+
+func TestNewDNSChallengeProvider(t *testing.T) {
+	t.Setenv("EXEC_PATH", "/bin/default")
+
+	provider, err := newDNSChallengeProvider(&DNSChallenge{
+		Provider: "exec",
+		Domains: []DNSChallengeDomain{
+			{
+				Domain:   "example.org",
+				Provider: "exec",
+				Env: map[string]string{
+					"EXEC_PATH":                "/bin/example",
+					"exec_propagation_timeout": "300",
+				},
+			},
+			{
+				Domain:   "*.sub.example.org",
+				Provider: "exec",
+				Env:      map[string]string{"EXEC_POLLING_INTERVAL": "10"},
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	// The exec provider solves the challenges sequentially.
+	selector, ok := provider.(*sequentialDNSProviderSelector)
+	require.True(t, ok)
+
+	require.Len(t, selector.domains, 2)
+	assert.Equal(t, "sub.example.org", selector.domains[0].suffix)
+	assert.Equal(t, "example.org", selector.domains[1].suffix)
+
+	timeout, interval := selector.Timeout()
+	assert.Equal(t, 300*time.Second, timeout)
+	assert.Equal(t, 10*time.Second, interval)
+
+	// The process environment is restored after the creation of the providers.
+	assert.Equal(t, "/bin/default", os.Getenv("EXEC_PATH"))
+	_, ok = os.LookupEnv("EXEC_PROPAGATION_TIMEOUT")
+	assert.False(t, ok)
+
+	_, err = newDNSChallengeProvider(&DNSChallenge{
+		Domains: []DNSChallengeDomain{{Domain: "example.org", Provider: "unknown"}},
+	})
+	assert.Error(t, err)
+
+	_, err = newDNSChallengeProvider(&DNSChallenge{
+		Domains: []DNSChallengeDomain{{Domain: "example.org"}},
+	})
+	assert.Error(t, err)
+}
+
+// startDNSServer starts a DNS server answering with the given CNAME records, and returns its address.
+func startDNSServer(t *testing.T, cnames map[string]string) string {
+	t.Helper()
+
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	server := &dns.Server{
+		PacketConn: conn,
+		Handler: dns.HandlerFunc(func(w dns.ResponseWriter, req *dns.Msg) {
+			msg := new(dns.Msg)
+			msg.SetReply(req)
+
+			for _, question := range req.Question {
+				target, ok := cnames[question.Name]
+				if !ok {
+					continue
+				}
+
+				msg.Answer = append(msg.Answer, &dns.CNAME{
+					Hdr:    dns.RR_Header{Name: question.Name, Rrtype: dns.TypeCNAME, Class: dns.ClassINET, Ttl: 60},
+					Target: target,
+				})
+			}
+
+			if len(msg.Answer) == 0 {
+				msg.Rcode = dns.RcodeNameError
+			}
+
+			_ = w.WriteMsg(msg)
+		}),
+	}
+
+	go func() { _ = server.ActivateAndServe() }()
+	t.Cleanup(func() { _ = server.Shutdown() })
+
+	return conn.LocalAddr().String()
+}
+
+type fakeDNSProvider struct {
+	presented []string
+	cleaned   []string
+}
+
+var _ challenge.Provider = (*fakeDNSProvider)(nil)
+
+func (f *fakeDNSProvider) Present(domain, _, _ string) error {
+	f.presented = append(f.presented, domain)
+	return nil
+}
+
+func (f *fakeDNSProvider) CleanUp(domain, _, _ string) error {
+	f.cleaned = append(f.cleaned, domain)
+	return nil
+}
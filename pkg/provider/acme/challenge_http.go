@@ -21,6 +21,9 @@ import (
 type ChallengeHTTP struct {
 	httpChallenges map[string]map[string][]byte
 	lock           sync.RWMutex
+
+	// stores share the challenges with the other Traefik instances.
+	stores []ChallengeStore
 }
 
 // NewChallengeHTTP creates a new ChallengeHTTP.
@@ -30,11 +33,32 @@ func NewChallengeHTTP() *ChallengeHTTP {
 	}
 }
 
+// AddStore adds a store with which the challenges are shared,
+// so that they can be answered by any Traefik instance using the same store.
+func (c *ChallengeHTTP) AddStore(store ChallengeStore) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	for _, s := range c.stores {
+		if s == store {
+			return
+		}
+	}
+
+	c.stores = append(c.stores, store)
+}
+
 // Present presents a challenge to obtain new ACME certificate.
 func (c *ChallengeHTTP) Present(domain, token, keyAuth string) error {
 	c.lock.Lock()
 	defer c.lock.Unlock()
 
+	for _, store := range c.stores {
+		if err := store.SetHTTPChallengeToken(token, domain, []byte(keyAuth)); err != nil {
+			return fmt.Errorf("unable to share the challenge for %s: %w", domain, err)
+		}
+	}
+
 	if _, ok := c.httpChallenges[token]; !ok {
 		c.httpChallenges[token] = map[string][]byte{}
 	}
@@ -49,6 +73,13 @@ func (c *ChallengeHTTP) CleanUp(domain, token, _ string) error {
 	c.lock.Lock()
 	defer c.lock.Unlock()
 
+	for _, store := range c.stores {
+		if err := store.RemoveHTTPChallengeToken(token, domain); err != nil {
+			log.WithoutContext().WithField(log.ProviderName, "acme").
+				Errorf("Unable to remove the shared challenge for %s (token %q): %v", domain, token, err)
+		}
+	}
+
 	if c.httpChallenges == nil && len(c.httpChallenges) == 0 {
 		return nil
 	}
@@ -111,17 +142,23 @@ func (c *ChallengeHTTP) getTokenValue(ctx context.Context, token, domain string)
 		c.lock.RLock()
 		defer c.lock.RUnlock()
 
-		if _, ok := c.httpChallenges[token]; !ok {
-			return fmt.Errorf("cannot find challenge for token %q (%s)", token, domain)
+		if _, ok := c.httpChallenges[token]; ok {
+			var found bool
+			if result, found = c.httpChallenges[token][domain]; found {
+				return nil
+			}
 		}
 
-		var ok bool
-		result, ok = c.httpChallenges[token][domain]
-		if !ok {
-			return fmt.Errorf("cannot find challenge for %s (token %q)", domain, token)
+		// The challenge might have been presented by another Traefik instance.
+		for _, store := range c.stores {
+			value, err := store.GetHTTPChallengeToken(token, domain)
+			if err == nil && len(value) > 0 {
+				result = value
+				return nil
+			}
 		}
 
-		return nil
+		return fmt.Errorf("cannot find challenge for %s (token %q)", domain, token)
 	}
 
 	notify := func(err error, time time.Duration) {
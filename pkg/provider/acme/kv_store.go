@@ -0,0 +1,296 @@
+package acme
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/cenkalti/backoff/v4"
+	"github.com/kvtools/valkeyrie/store"
+	ptypes "github.com/traefik/paerser/types"
+	"github.com/traefik/traefik/v2/pkg/job"
+	"github.com/traefik/traefik/v2/pkg/log"
+	"github.com/traefik/traefik/v2/pkg/provider/kv"
+	"github.com/traefik/traefik/v2/pkg/safe"
+	"github.com/traefik/traefik/v2/pkg/types"
+)
+
+var _ SharedStore = (*KVStore)(nil)
+
+// KVStorage holds the configuration of the KV store used to share the ACME data between Traefik instances.
+type KVStorage struct {
+	Backend   string           `description:"KV store backend: consul, etcd, redis or zooKeeper." json:"backend,omitempty" toml:"backend,omitempty" yaml:"backend,omitempty" export:"true"`
+	Endpoints []string         `description:"KV store endpoints." json:"endpoints,omitempty" toml:"endpoints,omitempty" yaml:"endpoints,omitempty"`
+	RootKey   string           `description:"Root key used to store the ACME data." json:"rootKey,omitempty" toml:"rootKey,omitempty" yaml:"rootKey,omitempty" export:"true"`
+	Username  string           `description:"KV Username" json:"username,omitempty" toml:"username,omitempty" yaml:"username,omitempty" loggable:"false"`
+	Password  string           `description:"KV Password" json:"password,omitempty" toml:"password,omitempty" yaml:"password,omitempty" loggable:"false"`
+	Token     string           `description:"KV Token" json:"token,omitempty" toml:"token,omitempty" yaml:"token,omitempty" loggable:"false"`
+	TLS       *types.ClientTLS `description:"Enable TLS support." json:"tls,omitempty" toml:"tls,omitempty" yaml:"tls,omitempty" export:"true"`
+	LockTTL   ptypes.Duration  `description:"Duration after which a lock held by an unreachable instance is released." json:"lockTTL,omitempty" toml:"lockTTL,omitempty" yaml:"lockTTL,omitempty" export:"true"`
+}
+
+// SetDefaults sets the default values.
+func (k *KVStorage) SetDefaults() {
+	k.RootKey = "traefik/acme"
+	k.LockTTL = ptypes.Duration(2 * time.Minute)
+}
+
+// KVStore is a Store implementation backed by a KV store,
+// which allows several Traefik instances to share the same ACME data.
+type KVStore struct {
+	client  store.Store
+	rootKey string
+	lockTTL time.Duration
+}
+
+// NewKVStore creates a KVStore from the given configuration.
+func NewKVStore(ctx context.Context, config *KVStorage) (*KVStore, error) {
+	var backend store.Backend
+	switch strings.ToLower(config.Backend) {
+	case "consul":
+		backend = store.CONSUL
+	case "etcd":
+		backend = store.ETCDV3
+	case "redis":
+		backend = store.REDIS
+	case "zookeeper":
+		backend = store.ZK
+	default:
+		return nil, fmt.Errorf("unsupported KV store backend %q", config.Backend)
+	}
+
+	if len(config.Endpoints) == 0 {
+		return nil, errors.New("at least one KV store endpoint is required")
+	}
+
+	storeConfig := &store.Config{
+		ConnectionTimeout: 3 * time.Second,
+		Bucket:            "traefik",
+		Username:          config.Username,
+		Password:          config.Password,
+		Token:             config.Token,
+	}
+
+	if config.TLS != nil {
+		var err error
+		storeConfig.TLS, err = config.TLS.CreateTLSConfig(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("unable to create client TLS configuration: %w", err)
+		}
+	}
+
+	client, err := kv.NewClient(ctx, backend, config.Endpoints, storeConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to KV store: %w", err)
+	}
+
+	return newKVStore(client, config.RootKey, time.Duration(config.LockTTL)), nil
+}
+
+func newKVStore(client store.Store, rootKey string, lockTTL time.Duration) *KVStore {
+	return &KVStore{
+		client:  client,
+		rootKey: rootKey,
+		lockTTL: lockTTL,
+	}
+}
+
+// GetAccount returns ACME Account.
+func (s *KVStore) GetAccount(resolverName string) (*Account, error) {
+	var account *Account
+	if err := s.get(path.Join(s.rootKey, resolverName, "account"), &account); err != nil {
+		return nil, err
+	}
+
+	return account, nil
+}
+
+// SaveAccount stores ACME Account.
+func (s *KVStore) SaveAccount(resolverName string, account *Account) error {
+	return s.put(path.Join(s.rootKey, resolverName, "account"), account)
+}
+
+// GetCertificates returns ACME Certificates list.
+func (s *KVStore) GetCertificates(resolverName string) ([]*CertAndStore, error) {
+	var certificates []*CertAndStore
+	if err := s.get(path.Join(s.rootKey, resolverName, "certificates"), &certificates); err != nil {
+		return nil, err
+	}
+
+	return certificates, nil
+}
+
+// SaveCertificates stores ACME Certificates list.
+func (s *KVStore) SaveCertificates(resolverName string, certificates []*CertAndStore) error {
+	return s.put(path.Join(s.rootKey, resolverName, "certificates"), certificates)
+}
+
+// GetHTTPChallengeToken returns the key authorization of the given HTTP-01 challenge.
+func (s *KVStore) GetHTTPChallengeToken(token, domain string) ([]byte, error) {
+	pair, err := s.client.Get(context.Background(), s.challengeKey(token, domain), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return pair.Value, nil
+}
+
+// SetHTTPChallengeToken stores the key authorization of the given HTTP-01 challenge.
+func (s *KVStore) SetHTTPChallengeToken(token, domain string, keyAuth []byte) error {
+	return s.client.Put(context.Background(), s.challengeKey(token, domain), keyAuth, nil)
+}
+
+// RemoveHTTPChallengeToken removes the given HTTP-01 challenge.
+func (s *KVStore) RemoveHTTPChallengeToken(token, domain string) error {
+	err := s.client.Delete(context.Background(), s.challengeKey(token, domain))
+	if err != nil && !errors.Is(err, store.ErrKeyNotFound) {
+		return err
+	}
+
+	return nil
+}
+
+// Lock acquires a lock on the given key, waiting for the other instances to release it.
+func (s *KVStore) Lock(ctx context.Context, key string) (func(), error) {
+	lockKey := path.Join(s.rootKey, "locks", key)
+
+	locker, err := s.client.NewLock(ctx, lockKey, &store.LockOptions{TTL: s.lockTTL})
+	if err != nil {
+		return nil, fmt.Errorf("unable to create lock %s: %w", lockKey, err)
+	}
+
+	// The lock is kept alive in the background until it is released,
+	// so its context must outlive the acquisition.
+	lockCtx, cancel := context.WithCancel(context.Background())
+
+	type lockResult struct {
+		lost <-chan struct{}
+		err  error
+	}
+
+	acquired := make(chan lockResult, 1)
+	safe.Go(func() {
+		lost, errLock := locker.Lock(lockCtx)
+		acquired <- lockResult{lost: lost, err: errLock}
+	})
+
+	var result lockResult
+	select {
+	case result = <-acquired:
+	case <-ctx.Done():
+		cancel()
+		return nil, ctx.Err()
+	}
+
+	if result.err != nil {
+		cancel()
+		return nil, fmt.Errorf("unable to acquire lock %s: %w", lockKey, result.err)
+	}
+
+	return func() {
+		defer cancel()
+
+		select {
+		case <-result.lost:
+			// The lock has already been lost, e.g. because the KV store was unreachable.
+			return
+		default:
+		}
+
+		if err := locker.Unlock(context.Background()); err != nil {
+			log.WithoutContext().WithField(log.ProviderName, "acme").Errorf("Unable to release lock %s: %v", lockKey, err)
+		}
+	}, nil
+}
+
+// WatchCertificates sends the certificates list of the given resolver each time it is changed.
+func (s *KVStore) WatchCertificates(ctx context.Context, resolverName string) (<-chan []*CertAndStore, error) {
+	key := path.Join(s.rootKey, resolverName, "certificates")
+	logger := log.FromContext(ctx)
+
+	certificatesChan := make(chan []*CertAndStore)
+
+	operation := func() error {
+		events, err := s.client.Watch(ctx, key, nil)
+		if err != nil {
+			return fmt.Errorf("failed to watch %s: %w", key, err)
+		}
+
+		for {
+			select {
+			case <-ctx.Done():
+				return nil
+			case pair, ok := <-events:
+				if !ok {
+					return errors.New("the Watch channel is closed")
+				}
+
+				if pair == nil || len(pair.Value) == 0 {
+					continue
+				}
+
+				var certificates []*CertAndStore
+				if err := json.Unmarshal(pair.Value, &certificates); err != nil {
+					logger.Errorf("Unable to decode the certificates stored at %s: %v", key, err)
+					continue
+				}
+
+				select {
+				case certificatesChan <- certificates:
+				case <-ctx.Done():
+					return nil
+				}
+			}
+		}
+	}
+
+	notify := func(err error, time time.Duration) {
+		logger.Errorf("KV store watch error: %v, retrying in %s", err, time)
+	}
+
+	safe.Go(func() {
+		err := backoff.RetryNotify(safe.OperationWithRecover(operation), backoff.WithContext(job.NewBackOff(backoff.NewExponentialBackOff()), ctx), notify)
+		if err != nil && ctx.Err() == nil {
+			logger.Errorf("Cannot watch the certificates stored at %s: %v", key, err)
+		}
+	})
+
+	return certificatesChan, nil
+}
+
+func (s *KVStore) challengeKey(token, domain string) string {
+	return path.Join(s.rootKey, "http-challenges", token, domain)
+}
+
+func (s *KVStore) get(key string, value interface{}) error {
+	pair, err := s.client.Get(context.Background(), key, nil)
+	if errors.Is(err, store.ErrKeyNotFound) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("unable to read %s: %w", key, err)
+	}
+
+	if pair == nil || len(pair.Value) == 0 {
+		return nil
+	}
+
+	return json.Unmarshal(pair.Value, value)
+}
+
+func (s *KVStore) put(key string, value interface{}) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+
+	if err := s.client.Put(context.Background(), key, data, nil); err != nil {
+		return fmt.Errorf("unable to write %s: %w", key, err)
+	}
+
+	return nil
+}
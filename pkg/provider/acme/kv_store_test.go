@@ -0,0 +1,343 @@
+package acme
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-acme/lego/v4/challenge/http01"
+	"github.com/kvtools/valkeyrie/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/traefik/traefik/v2/pkg/types"
+)
+
+func TestKVStore_Account(t *testing.T) {
+	s := newKVStore(newKVClientMock(), "traefik/acme", time.Minute)
+
+	account, err := s.GetAccount("myresolver")
+	require.NoError(t, err)
+	assert.Nil(t, account)
+
+	err = s.SaveAccount("myresolver", &Account{Email: "foo@bar.com", KeyType: "RSA4096"})
+	require.NoError(t, err)
+
+	account, err = s.GetAccount("myresolver")
+	require.NoError(t, err)
+	assert.Equal(t, &Account{Email: "foo@bar.com", KeyType: "RSA4096"}, account)
+
+	account, err = s.GetAccount("otherresolver")
+	require.NoError(t, err)
+	assert.Nil(t, account)
+}
+
+func TestKVStore_Certificates(t *testing.T) {
+	s := newKVStore(newKVClientMock(), "traefik/acme", time.Minute)
+
+	certificates, err := s.GetCertificates("myresolver")
+	require.NoError(t, err)
+	assert.Empty(t, certificates)
+
+	expected := []*CertAndStore{
+		{
+			Certificate: Certificate{
+				Domain:      types.Domain{Main: "foo.com", SANs: []string{"bar.com"}},
+				Certificate: []byte("cert"),
+				Key:         []byte("key"),
+			},
+			Store: "default",
+		},
+	}
+
+	err = s.SaveCertificates("myresolver", expected)
+	require.NoError(t, err)
+
+	certificates, err = s.GetCertificates("myresolver")
+	require.NoError(t, err)
+	assert.Equal(t, expected, certificates)
+}
+
+func TestKVStore_HTTPChallengeToken(t *testing.T) {
+	s := newKVStore(newKVClientMock(), "traefik/acme", time.Minute)
+
+	_, err := s.GetHTTPChallengeToken("token", "foo.com")
+	require.Error(t, err)
+
+	err = s.SetHTTPChallengeToken("token", "foo.com", []byte("keyAuth"))
+	require.NoError(t, err)
+
+	keyAuth, err := s.GetHTTPChallengeToken("token", "foo.com")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("keyAuth"), keyAuth)
+
+	err = s.RemoveHTTPChallengeToken("token", "foo.com")
+	require.NoError(t, err)
+
+	_, err = s.GetHTTPChallengeToken("token", "foo.com")
+	require.Error(t, err)
+
+	// Removing an unknown challenge is not an error.
+	err = s.RemoveHTTPChallengeToken("token", "foo.com")
+	require.NoError(t, err)
+}
+
+func TestKVStore_Lock(t *testing.T) {
+	s := newKVStore(newKVClientMock(), "traefik/acme", time.Minute)
+
+	unlock, err := s.Lock(context.Background(), "myresolver/domains/foo.com")
+	require.NoError(t, err)
+
+	// Another key can be locked independently.
+	unlockOther, err := s.Lock(context.Background(), "myresolver/domains/bar.com")
+	require.NoError(t, err)
+	unlockOther()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	_, err = s.Lock(ctx, "myresolver/domains/foo.com")
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+
+	acquired := make(chan struct{})
+	go func() {
+		unlockSecond, errLock := s.Lock(context.Background(), "myresolver/domains/foo.com")
+		if errLock == nil {
+			unlockSecond()
+		}
+		close(acquired)
+	}()
+
+	select {
+	case <-acquired:
+		t.Fatal("The lock has been acquired twice")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	unlock()
+
+	select {
+	case <-acquired:
+	case <-time.After(time.Second):
+		t.Fatal("The lock has not been released")
+	}
+}
+
+func TestKVStore_WatchCertificates(t *testing.T) {
+	s := newKVStore(newKVClientMock(), "traefik/acme", time.Minute)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	certificatesChan, err := s.WatchCertificates(ctx, "myresolver")
+	require.NoError(t, err)
+
+	expected := []*CertAndStore{
+		{Certificate: Certificate{Domain: types.Domain{Main: "foo.com"}, Certificate: []byte("cert"), Key: []byte("key")}, Store: "default"},
+	}
+
+	err = s.SaveCertificates("myresolver", expected)
+	require.NoError(t, err)
+
+	select {
+	case certificates := <-certificatesChan:
+		assert.Equal(t, expected, certificates)
+	case <-time.After(time.Second):
+		t.Fatal("The certificates update has not been received")
+	}
+}
+
+func TestChallengeHTTP_SharedStore(t *testing.T) {
+	s := newKVStore(newKVClientMock(), "traefik/acme", time.Minute)
+
+	presenter := NewChallengeHTTP()
+	presenter.AddStore(s)
+
+	responder := NewChallengeHTTP()
+	responder.AddStore(s)
+
+	err := presenter.Present("foo.com", "token", "keyAuth")
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodGet, "http://foo.com"+http01.ChallengePath("token"), nil)
+	rw := httptest.NewRecorder()
+	responder.ServeHTTP(rw, req)
+
+	assert.Equal(t, http.StatusOK, rw.Code)
+	assert.Equal(t, "keyAuth", rw.Body.String())
+
+	err = presenter.CleanUp("foo.com", "token", "keyAuth")
+	require.NoError(t, err)
+
+	_, err = s.GetHTTPChallengeToken("token", "foo.com")
+	require.Error(t, err)
+}
+
+func TestProvider_lockCertificate(t *testing.T) {
+	s := newKVStore(newKVClientMock(), "traefik/acme", time.Minute)
+
+	stored := &CertAndStore{
+		Certificate: Certificate{Domain: types.Domain{Main: "foo.com", SANs: []string{"bar.com"}}, Certificate: []byte("cert"), Key: []byte("key")},
+		Store:       "default",
+	}
+	err := s.SaveCertificates("myresolver", []*CertAndStore{stored})
+	require.NoError(t, err)
+
+	p := &Provider{ResolverName: "myresolver", Store: s}
+
+	unlock, cert, err := p.lockCertificate(context.Background(), types.Domain{Main: "foo.com", SANs: []string{"bar.com"}})
+	require.NoError(t, err)
+	assert.Equal(t, stored, cert)
+	unlock()
+
+	unlock, cert, err = p.lockCertificate(context.Background(), types.Domain{Main: "foo.com"})
+	require.NoError(t, err)
+	assert.Nil(t, cert)
+	unlock()
+}
+
+// kvClientMock is an in-memory store.Store, supporting the operations used by the KVStore.
+type kvClientMock struct {
+	mu       sync.Mutex
+	values   map[string][]byte
+	locks    map[string]chan struct{}
+	watchers map[string][]chan *store.KVPair
+}
+
+func newKVClientMock() *kvClientMock {
+	return &kvClientMock{
+		values:   make(map[string][]byte),
+		locks:    make(map[string]chan struct{}),
+		watchers: make(map[string][]chan *store.KVPair),
+	}
+}
+
+func (m *kvClientMock) Put(_ context.Context, key string, value []byte, _ *store.WriteOptions) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.values[key] = value
+
+	for _, watcher := range m.watchers[key] {
+		watcher <- &store.KVPair{Key: key, Value: value}
+	}
+
+	return nil
+}
+
+func (m *kvClientMock) Get(_ context.Context, key string, _ *store.ReadOptions) (*store.KVPair, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	value, ok := m.values[key]
+	if !ok {
+		return nil, store.ErrKeyNotFound
+	}
+
+	return &store.KVPair{Key: key, Value: value}, nil
+}
+
+func (m *kvClientMock) Delete(_ context.Context, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.values[key]; !ok {
+		return store.ErrKeyNotFound
+	}
+
+	delete(m.values, key)
+
+	return nil
+}
+
+func (m *kvClientMock) Exists(_ context.Context, key string, _ *store.ReadOptions) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	_, ok := m.values[key]
+
+	return ok, nil
+}
+
+func (m *kvClientMock) Watch(_ context.Context, key string, _ *store.ReadOptions) (<-chan *store.KVPair, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	watcher := make(chan *store.KVPair, 10)
+	m.watchers[key] = append(m.watchers[key], watcher)
+
+	// Like the actual stores, the current value is sent first.
+	if value, ok := m.values[key]; ok {
+		watcher <- &store.KVPair{Key: key, Value: value}
+	}
+
+	return watcher, nil
+}
+
+func (m *kvClientMock) WatchTree(_ context.Context, _ string, _ *store.ReadOptions) (<-chan []*store.KVPair, error) {
+	return nil, errors.New("method WatchTree not supported")
+}
+
+func (m *kvClientMock) NewLock(_ context.Context, key string, _ *store.LockOptions) (store.Locker, error) {
+	return &lockMock{client: m, key: key}, nil
+}
+
+func (m *kvClientMock) List(_ context.Context, _ string, _ *store.ReadOptions) ([]*store.KVPair, error) {
+	return nil, errors.New("method List not supported")
+}
+
+func (m *kvClientMock) DeleteTree(_ context.Context, _ string) error {
+	return errors.New("method DeleteTree not supported")
+}
+
+func (m *kvClientMock) AtomicPut(_ context.Context, _ string, _ []byte, _ *store.KVPair, _ *store.WriteOptions) (bool, *store.KVPair, error) {
+	return false, nil, errors.New("method AtomicPut not supported")
+}
+
+func (m *kvClientMock) AtomicDelete(_ context.Context, _ string, _ *store.KVPair) (bool, error) {
+	return false, errors.New("method AtomicDelete not supported")
+}
+
+func (m *kvClientMock) Close() error {
+	return nil
+}
+
+type lockMock struct {
+	client *kvClientMock
+	key    string
+}
+
+func (l *lockMock) Lock(ctx context.Context) (<-chan struct{}, error) {
+	for {
+		l.client.mu.Lock()
+		held, ok := l.client.locks[l.key]
+		if !ok {
+			l.client.locks[l.key] = make(chan struct{})
+			l.client.mu.Unlock()
+
+			return make(chan struct{}), nil
+		}
+		l.client.mu.Unlock()
+
+		select {
+		case <-held:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+func (l *lockMock) Unlock(_ context.Context) error {
+	l.client.mu.Lock()
+	defer l.client.mu.Unlock()
+
+	if held, ok := l.client.locks[l.key]; ok {
+		close(held)
+		delete(l.client.locks, l.key)
+	}
+
+	return nil
+}
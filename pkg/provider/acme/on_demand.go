@@ -0,0 +1,231 @@
+package acme
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/patrickmn/go-cache"
+	ptypes "github.com/traefik/paerser/types"
+	"github.com/traefik/traefik/v2/pkg/log"
+	traefiktls "github.com/traefik/traefik/v2/pkg/tls"
+	"github.com/traefik/traefik/v2/pkg/types"
+	"golang.org/x/sync/singleflight"
+	"golang.org/x/time/rate"
+)
+
+// OnDemand contains the configuration of the certificates obtained during the TLS handshakes.
+type OnDemand struct {
+	Ask               string             `description:"URL asked whether a certificate can be obtained for a domain, given as the domain query parameter. Any 2xx status code allows it." json:"ask,omitempty" toml:"ask,omitempty" yaml:"ask,omitempty"`
+	RateLimit         *OnDemandRateLimit `description:"Limits the rate at which certificates are obtained on demand." json:"rateLimit,omitempty" toml:"rateLimit,omitempty" yaml:"rateLimit,omitempty" export:"true"`
+	DenyCacheDuration ptypes.Duration    `description:"Duration during which a domain is not asked for again after being denied, or after a failure to obtain its certificate." json:"denyCacheDuration,omitempty" toml:"denyCacheDuration,omitempty" yaml:"denyCacheDuration,omitempty" export:"true"`
+	Timeout           ptypes.Duration    `description:"Maximum duration a TLS handshake waits for its certificate, before falling back to the default certificate." json:"timeout,omitempty" toml:"timeout,omitempty" yaml:"timeout,omitempty" export:"true"`
+}
+
+// SetDefaults sets the default values.
+func (o *OnDemand) SetDefaults() {
+	o.RateLimit = &OnDemandRateLimit{}
+	o.RateLimit.SetDefaults()
+	o.DenyCacheDuration = ptypes.Duration(10 * time.Minute)
+	o.Timeout = ptypes.Duration(30 * time.Second)
+}
+
+// OnDemandRateLimit contains the rate limit of the certificates obtained on demand.
+type OnDemandRateLimit struct {
+	Average int64           `description:"Maximum number of certificates obtained on demand per period." json:"average,omitempty" toml:"average,omitempty" yaml:"average,omitempty" export:"true"`
+	Period  ptypes.Duration `description:"Period of the rate limit." json:"period,omitempty" toml:"period,omitempty" yaml:"period,omitempty" export:"true"`
+	Burst   int64           `description:"Maximum number of certificates obtained on demand at once." json:"burst,omitempty" toml:"burst,omitempty" yaml:"burst,omitempty" export:"true"`
+}
+
+// SetDefaults sets the default values.
+func (r *OnDemandRateLimit) SetDefaults() {
+	r.Average = 10
+	r.Period = ptypes.Duration(time.Minute)
+	r.Burst = 10
+}
+
+// onDemandObtainer obtains the certificates for the server names unknown to the TLS store,
+// once they have been allowed by the ask endpoint.
+type onDemandObtainer struct {
+	askURL  *url.URL
+	client  *http.Client
+	limiter *rate.Limiter
+	timeout time.Duration
+	denied  *cache.Cache
+	group   singleflight.Group
+
+	resolve func(ctx context.Context, domain types.Domain) (*tls.Certificate, error)
+}
+
+func newOnDemandObtainer(config *OnDemand, resolve func(ctx context.Context, domain types.Domain) (*tls.Certificate, error)) (*onDemandObtainer, error) {
+	if config.Ask == "" {
+		return nil, errors.New("an ask URL is required to obtain certificates on demand")
+	}
+
+	askURL, err := url.Parse(config.Ask)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ask URL: %w", err)
+	}
+
+	limit := rate.Inf
+	burst := 1
+	if config.RateLimit != nil && config.RateLimit.Average > 0 {
+		period := time.Duration(config.RateLimit.Period)
+		if period <= 0 {
+			period = time.Second
+		}
+
+		limit = rate.Limit(float64(config.RateLimit.Average) * float64(time.Second) / float64(period))
+
+		if config.RateLimit.Burst > 1 {
+			burst = int(config.RateLimit.Burst)
+		}
+	}
+
+	timeout := time.Duration(config.Timeout)
+	if timeout <= 0 {
+		timeout = 30 * time.Second
+	}
+
+	denyCacheDuration := time.Duration(config.DenyCacheDuration)
+	if denyCacheDuration <= 0 {
+		denyCacheDuration = cache.NoExpiration
+	}
+
+	return &onDemandObtainer{
+		askURL:  askURL,
+		client:  &http.Client{Timeout: 10 * time.Second},
+		limiter: rate.NewLimiter(limit, burst),
+		timeout: timeout,
+		denied:  cache.New(denyCacheDuration, time.Minute),
+		resolve: resolve,
+	}, nil
+}
+
+// Obtain obtains a certificate for the given server name, if allowed.
+// It implements traefiktls.CertificateObtainer.
+func (o *onDemandObtainer) Obtain(ctx context.Context, serverName string) (*tls.Certificate, error) {
+	if !isOnDemandDomain(serverName) {
+		return nil, nil
+	}
+
+	if _, denied := o.denied.Get(serverName); denied {
+		return nil, nil
+	}
+
+	// The concurrent handshakes for the same server name share the same attempt.
+	result := o.group.DoChan(serverName, func() (interface{}, error) {
+		cert, err := o.obtain(serverName)
+		if err != nil {
+			o.denied.SetDefault(serverName, struct{}{})
+		}
+
+		return cert, err
+	})
+
+	timer := time.NewTimer(o.timeout)
+	defer timer.Stop()
+
+	select {
+	case res := <-result:
+		if res.Err != nil {
+			return nil, res.Err
+		}
+
+		cert, _ := res.Val.(*tls.Certificate)
+		return cert, nil
+	case <-timer.C:
+		// The certificate is still being obtained, and will be used by the next handshakes.
+		return nil, fmt.Errorf("timeout while obtaining the certificate for %q", serverName)
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (o *onDemandObtainer) obtain(serverName string) (*tls.Certificate, error) {
+	allowed, err := o.ask(serverName)
+	if err != nil {
+		return nil, err
+	}
+
+	if !allowed {
+		return nil, fmt.Errorf("obtaining a certificate for %q is not allowed", serverName)
+	}
+
+	if !o.limiter.Allow() {
+		// Not a failure of the domain: the next handshakes might be allowed to obtain it.
+		return nil, nil
+	}
+
+	// The certificate is not bound to the context of the handshake,
+	// as it is still useful to the next handshakes if the current one gives up.
+	return o.resolve(context.Background(), types.Domain{Main: serverName})
+}
+
+func (o *onDemandObtainer) ask(serverName string) (bool, error) {
+	askURL := *o.askURL
+
+	query := askURL.Query()
+	query.Set("domain", serverName)
+	askURL.RawQuery = query.Encode()
+
+	resp, err := o.client.Get(askURL.String())
+	if err != nil {
+		return false, fmt.Errorf("unable to ask whether a certificate can be obtained for %q: %w", serverName, err)
+	}
+	_ = resp.Body.Close()
+
+	return resp.StatusCode >= http.StatusOK && resp.StatusCode < http.StatusMultipleChoices, nil
+}
+
+// isOnDemandDomain returns whether a certificate could be obtained for the given server name.
+func isOnDemandDomain(serverName string) bool {
+	if serverName == "" || net.ParseIP(serverName) != nil {
+		return false
+	}
+
+	// Single-label names (e.g. localhost) cannot get public certificates.
+	if !strings.Contains(strings.Trim(serverName, "."), ".") {
+		return false
+	}
+
+	for _, c := range serverName {
+		switch {
+		case c >= 'a' && c <= 'z', c >= '0' && c <= '9', c == '-', c == '.':
+		default:
+			return false
+		}
+	}
+
+	return true
+}
+
+// obtainOnDemand obtains the certificate of the given domain during a TLS handshake,
+// and adds it to the certificates of the resolver.
+func (p *Provider) obtainOnDemand(ctx context.Context, domain types.Domain) (*tls.Certificate, error) {
+	ctx = log.With(ctx, log.Str(log.ProviderName, p.ResolverName+".acme"), log.Str("domain", domain.Main))
+	log.FromContext(ctx).Debug("Obtaining certificate on demand")
+
+	cert, err := p.resolveCertificate(ctx, domain, traefiktls.DefaultTLSStoreName)
+	if err != nil {
+		return nil, err
+	}
+
+	if cert == nil {
+		// The certificate is already being obtained, or has been obtained in the meantime.
+		return nil, nil
+	}
+
+	tlsCert, err := tls.X509KeyPair(cert.Certificate, cert.PrivateKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return &tlsCert, nil
+}
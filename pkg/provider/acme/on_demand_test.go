@@ -0,0 +1,228 @@
+package acme
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	ptypes "github.com/traefik/paerser/types"
+	"github.com/traefik/traefik/v2/pkg/types"
+)
+
+func TestOnDemandObtainer_Obtain(t *testing.T) {
+	testCases := []struct {
+		desc           string
+		serverName     string
+		askStatus      int
+		resolveErr     error
+		expectedErr    bool
+		expectedCert   bool
+		expectedAsked  bool
+		expectedDenied bool
+	}{
+		{
+			desc:          "allowed domain",
+			serverName:    "foo.example.com",
+			askStatus:     http.StatusOK,
+			expectedCert:  true,
+			expectedAsked: true,
+		},
+		{
+			desc:           "denied domain",
+			serverName:     "foo.example.com",
+			askStatus:      http.StatusForbidden,
+			expectedErr:    true,
+			expectedAsked:  true,
+			expectedDenied: true,
+		},
+		{
+			desc:           "failure to obtain the certificate",
+			serverName:     "foo.example.com",
+			askStatus:      http.StatusOK,
+			resolveErr:     errors.New("boom"),
+			expectedErr:    true,
+			expectedAsked:  true,
+			expectedDenied: true,
+		},
+		{
+			desc:       "IP address",
+			serverName: "127.0.0.1",
+			askStatus:  http.StatusOK,
+		},
+		{
+			desc:       "single label",
+			serverName: "localhost",
+			askStatus:  http.StatusOK,
+		},
+		{
+			desc:       "invalid characters",
+			serverName: "foo_bar.example.com",
+			askStatus:  http.StatusOK,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			var asked []string
+			var mu sync.Mutex
+			server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				mu.Lock()
+				asked = append(asked, req.URL.Query().Get("domain"))
+				mu.Unlock()
+
+				rw.WriteHeader(test.askStatus)
+			}))
+			t.Cleanup(server.Close)
+
+			resolve := func(_ context.Context, domain types.Domain) (*tls.Certificate, error) {
+				if test.resolveErr != nil {
+					return nil, test.resolveErr
+				}
+				return &tls.Certificate{}, nil
+			}
+
+			config := &OnDemand{}
+			config.SetDefaults()
+			config.Ask = server.URL
+
+			obtainer, err := newOnDemandObtainer(config, resolve)
+			require.NoError(t, err)
+
+			cert, err := obtainer.Obtain(context.Background(), test.serverName)
+			if test.expectedErr {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+
+			assert.Equal(t, test.expectedCert, cert != nil)
+
+			mu.Lock()
+			if test.expectedAsked {
+				assert.Equal(t, []string{test.serverName}, asked)
+			} else {
+				assert.Empty(t, asked)
+			}
+			mu.Unlock()
+
+			_, denied := obtainer.denied.Get(test.serverName)
+			assert.Equal(t, test.expectedDenied, denied)
+		})
+	}
+}
+
+func TestOnDemandObtainer_DenyCache(t *testing.T) {
+	var asked int32
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		atomic.AddInt32(&asked, 1)
+		rw.WriteHeader(http.StatusNotFound)
+	}))
+	t.Cleanup(server.Close)
+
+	config := &OnDemand{}
+	config.SetDefaults()
+	config.Ask = server.URL
+
+	obtainer, err := newOnDemandObtainer(config, func(_ context.Context, _ types.Domain) (*tls.Certificate, error) {
+		return &tls.Certificate{}, nil
+	})
+	require.NoError(t, err)
+
+	_, err = obtainer.Obtain(context.Background(), "foo.example.com")
+	require.Error(t, err)
+
+	// The denied domain is not asked for again.
+	cert, err := obtainer.Obtain(context.Background(), "foo.example.com")
+	require.NoError(t, err)
+	assert.Nil(t, cert)
+
+	assert.Equal(t, int32(1), atomic.LoadInt32(&asked))
+}
+
+func TestOnDemandObtainer_RateLimit(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(server.Close)
+
+	config := &OnDemand{
+		Ask:       server.URL,
+		RateLimit: &OnDemandRateLimit{Average: 1, Period: ptypes.Duration(time.Hour), Burst: 2},
+	}
+
+	var resolved int32
+	obtainer, err := newOnDemandObtainer(config, func(_ context.Context, _ types.Domain) (*tls.Certificate, error) {
+		atomic.AddInt32(&resolved, 1)
+		return &tls.Certificate{}, nil
+	})
+	require.NoError(t, err)
+
+	for _, serverName := range []string{"foo.example.com", "bar.example.com", "baz.example.com"} {
+		_, err = obtainer.Obtain(context.Background(), serverName)
+		require.NoError(t, err)
+	}
+
+	assert.Equal(t, int32(2), atomic.LoadInt32(&resolved))
+
+	// A rate limited domain is not denied, so it can be obtained later on.
+	_, denied := obtainer.denied.Get("baz.example.com")
+	assert.False(t, denied)
+}
+
+func TestOnDemandObtainer_Timeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(server.Close)
+
+	config := &OnDemand{}
+	config.SetDefaults()
+	config.Ask = server.URL
+	config.Timeout = ptypes.Duration(50 * time.Millisecond)
+
+	release := make(chan struct{})
+	var resolved int32
+	obtainer, err := newOnDemandObtainer(config, func(_ context.Context, _ types.Domain) (*tls.Certificate, error) {
+		atomic.AddInt32(&resolved, 1)
+		<-release
+		return &tls.Certificate{}, nil
+	})
+	require.NoError(t, err)
+
+	// The concurrent handshakes share the same attempt, and give up after the timeout.
+	var wg sync.WaitGroup
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			cert, errObtain := obtainer.Obtain(context.Background(), "foo.example.com")
+			assert.Error(t, errObtain)
+			assert.Nil(t, cert)
+		}()
+	}
+	wg.Wait()
+
+	close(release)
+
+	assert.Equal(t, int32(1), atomic.LoadInt32(&resolved))
+}
+
+func TestNewOnDemandObtainer_NoAsk(t *testing.T) {
+	config := &OnDemand{}
+	config.SetDefaults()
+
+	_, err := newOnDemandObtainer(config, nil)
+	require.Error(t, err)
+}
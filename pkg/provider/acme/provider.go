@@ -16,11 +16,12 @@ import (
 	"github.com/go-acme/lego/v4/challenge"
 	"github.com/go-acme/lego/v4/challenge/dns01"
 	"github.com/go-acme/lego/v4/lego"
-	"github.com/go-acme/lego/v4/providers/dns"
 	"github.com/go-acme/lego/v4/registration"
+	"github.com/sirupsen/logrus"
 	ptypes "github.com/traefik/paerser/types"
 	"github.com/traefik/traefik/v2/pkg/config/dynamic"
 	"github.com/traefik/traefik/v2/pkg/log"
+	"github.com/traefik/traefik/v2/pkg/metrics"
 	httpmuxer "github.com/traefik/traefik/v2/pkg/muxer/http"
 	tcpmuxer "github.com/traefik/traefik/v2/pkg/muxer/tcp"
 	"github.com/traefik/traefik/v2/pkg/safe"
@@ -42,6 +43,9 @@ type Configuration struct {
 	EAB                  *EAB   `description:"External Account Binding to use." json:"eab,omitempty" toml:"eab,omitempty" yaml:"eab,omitempty"`
 	CertificatesDuration int    `description:"Certificates' duration in hours." json:"certificatesDuration,omitempty" toml:"certificatesDuration,omitempty" yaml:"certificatesDuration,omitempty" export:"true"`
 
+	KVStorage *KVStorage `description:"Storage in a KV store shared between Traefik instances, replacing the storage file." json:"kvStorage,omitempty" toml:"kvStorage,omitempty" yaml:"kvStorage,omitempty" export:"true"`
+	OnDemand  *OnDemand  `description:"Obtains certificates during the TLS handshakes, for the allowed domains unknown to the default TLS store." json:"onDemand,omitempty" toml:"onDemand,omitempty" yaml:"onDemand,omitempty" export:"true"`
+
 	DNSChallenge  *DNSChallenge  `description:"Activate DNS-01 Challenge." json:"dnsChallenge,omitempty" toml:"dnsChallenge,omitempty" yaml:"dnsChallenge,omitempty" label:"allowEmpty" file:"allowEmpty" export:"true"`
 	HTTPChallenge *HTTPChallenge `description:"Activate HTTP-01 Challenge." json:"httpChallenge,omitempty" toml:"httpChallenge,omitempty" yaml:"httpChallenge,omitempty" label:"allowEmpty" file:"allowEmpty" export:"true"`
 	TLSChallenge  *TLSChallenge  `description:"Activate TLS-ALPN-01 Challenge." json:"tlsChallenge,omitempty" toml:"tlsChallenge,omitempty" yaml:"tlsChallenge,omitempty" label:"allowEmpty" file:"allowEmpty" export:"true"`
@@ -76,10 +80,19 @@ type EAB struct {
 
 // DNSChallenge contains DNS challenge configuration.
 type DNSChallenge struct {
-	Provider                string          `description:"Use a DNS-01 based challenge provider rather than HTTPS." json:"provider,omitempty" toml:"provider,omitempty" yaml:"provider,omitempty" export:"true"`
-	DelayBeforeCheck        ptypes.Duration `description:"Assume DNS propagates after a delay in seconds rather than finding and querying nameservers." json:"delayBeforeCheck,omitempty" toml:"delayBeforeCheck,omitempty" yaml:"delayBeforeCheck,omitempty" export:"true"`
-	Resolvers               []string        `description:"Use following DNS servers to resolve the FQDN authority." json:"resolvers,omitempty" toml:"resolvers,omitempty" yaml:"resolvers,omitempty"`
-	DisablePropagationCheck bool            `description:"Disable the DNS propagation checks before notifying ACME that the DNS challenge is ready. [not recommended]" json:"disablePropagationCheck,omitempty" toml:"disablePropagationCheck,omitempty" yaml:"disablePropagationCheck,omitempty" export:"true"`
+	Provider                string               `description:"Use a DNS-01 based challenge provider rather than HTTPS." json:"provider,omitempty" toml:"provider,omitempty" yaml:"provider,omitempty" export:"true"`
+	DelayBeforeCheck        ptypes.Duration      `description:"Assume DNS propagates after a delay in seconds rather than finding and querying nameservers." json:"delayBeforeCheck,omitempty" toml:"delayBeforeCheck,omitempty" yaml:"delayBeforeCheck,omitempty" export:"true"`
+	Resolvers               []string             `description:"Use following DNS servers to resolve the FQDN authority." json:"resolvers,omitempty" toml:"resolvers,omitempty" yaml:"resolvers,omitempty"`
+	DisablePropagationCheck bool                 `description:"Disable the DNS propagation checks before notifying ACME that the DNS challenge is ready. [not recommended]" json:"disablePropagationCheck,omitempty" toml:"disablePropagationCheck,omitempty" yaml:"disablePropagationCheck,omitempty" export:"true"`
+	FollowCNAME             bool                 `description:"Follow the CNAME of the _acme-challenge records, to solve the challenges of the domains delegated to another zone. Enabled for all the resolvers." json:"followCNAME,omitempty" toml:"followCNAME,omitempty" yaml:"followCNAME,omitempty" export:"true"`
+	Domains                 []DNSChallengeDomain `description:"DNS-01 based challenge providers by domain. The provider option is used for the other domains." json:"domains,omitempty" toml:"domains,omitempty" yaml:"domains,omitempty" export:"true"`
+}
+
+// DNSChallengeDomain selects the DNS provider of a domain and its subdomains.
+type DNSChallengeDomain struct {
+	Domain   string            `description:"Domain, with its subdomains, whose challenges are solved by the provider." json:"domain,omitempty" toml:"domain,omitempty" yaml:"domain,omitempty" export:"true"`
+	Provider string            `description:"DNS-01 based challenge provider of the domain." json:"provider,omitempty" toml:"provider,omitempty" yaml:"provider,omitempty" export:"true"`
+	Env      map[string]string `description:"Environment variables configuring the provider, e.g. its credentials, instead of the process environment." json:"env,omitempty" toml:"env,omitempty" yaml:"env,omitempty"`
 }
 
 // HTTPChallenge contains HTTP challenge configuration.
@@ -105,11 +118,13 @@ type Provider struct {
 	certsChan              chan *CertAndStore
 	configurationChan      chan<- dynamic.Message
 	tlsManager             *traefiktls.Manager
+	metricsRegistry        metrics.Registry
 	clientMutex            sync.Mutex
 	configFromListenerChan chan dynamic.Configuration
 	pool                   *safe.Pool
 	resolvingDomains       map[string]struct{}
 	resolvingDomainsMutex  sync.RWMutex
+	onDemand               *onDemandObtainer
 }
 
 // SetTLSManager sets the tls manager to use.
@@ -117,6 +132,11 @@ func (p *Provider) SetTLSManager(tlsManager *traefiktls.Manager) {
 	p.tlsManager = tlsManager
 }
 
+// SetMetricsRegistry sets the metrics registry recording the certificate renewals.
+func (p *Provider) SetMetricsRegistry(metricsRegistry metrics.Registry) {
+	p.metricsRegistry = metricsRegistry
+}
+
 // SetConfigListenerChan initializes the configFromListenerChan.
 func (p *Provider) SetConfigListenerChan(configFromListenerChan chan dynamic.Configuration) {
 	p.configFromListenerChan = configFromListenerChan
@@ -132,7 +152,7 @@ func (p *Provider) Init() error {
 	ctx := log.With(context.Background(), log.Str(log.ProviderName, p.ResolverName+".acme"))
 	logger := log.FromContext(ctx)
 
-	if len(p.Configuration.Storage) == 0 {
+	if p.Configuration.KVStorage == nil && len(p.Configuration.Storage) == 0 {
 		return errors.New("unable to initialize ACME provider with no storage location for the certificates")
 	}
 
@@ -160,6 +180,13 @@ func (p *Provider) Init() error {
 	// Init the currently resolved domain map
 	p.resolvingDomains = make(map[string]struct{})
 
+	if p.OnDemand != nil {
+		p.onDemand, err = newOnDemandObtainer(p.OnDemand, p.obtainOnDemand)
+		if err != nil {
+			return fmt.Errorf("unable to obtain certificates on demand: %w", err)
+		}
+	}
+
 	return nil
 }
 
@@ -201,6 +228,10 @@ func (p *Provider) Provide(configurationChan chan<- dynamic.Message, pool *safe.
 	p.configurationChan = configurationChan
 	p.refreshCertificates()
 
+	if p.onDemand != nil {
+		p.tlsManager.SetCertificateObtainer(traefiktls.DefaultTLSStoreName, p.onDemand.Obtain)
+	}
+
 	renewPeriod, renewInterval := getCertificateRenewDurations(p.CertificatesDuration)
 	log.FromContext(ctx).Debugf("Attempt to renew certificates %q before expiry and check every %q",
 		renewPeriod, renewInterval)
@@ -234,6 +265,25 @@ func (p *Provider) getClient() (*lego.Client, error) {
 		return p.client, nil
 	}
 
+	if shared, ok := p.Store.(SharedStore); ok {
+		// Prevents the instances sharing the store from registering several accounts.
+		unlock, err := shared.Lock(ctx, p.ResolverName+"/account")
+		if err != nil {
+			return nil, err
+		}
+		defer unlock()
+
+		// The account might have been registered by another instance since the initialization.
+		storedAccount, err := shared.GetAccount(p.ResolverName)
+		if err != nil {
+			return nil, fmt.Errorf("unable to get ACME account: %w", err)
+		}
+
+		if storedAccount != nil && storedAccount.Registration != nil && isAccountMatchingCaServer(ctx, storedAccount.Registration.URI, p.CAServer) {
+			p.account = storedAccount
+		}
+	}
+
 	account, err := p.initAccount(ctx)
 	if err != nil {
 		return nil, err
@@ -274,17 +324,20 @@ func (p *Provider) getClient() (*lego.Client, error) {
 		return nil, err
 	}
 
-	if (p.DNSChallenge == nil || len(p.DNSChallenge.Provider) == 0) &&
+	if (p.DNSChallenge == nil || (len(p.DNSChallenge.Provider) == 0 && len(p.DNSChallenge.Domains) == 0)) &&
 		(p.HTTPChallenge == nil || len(p.HTTPChallenge.EntryPoint) == 0) &&
 		p.TLSChallenge == nil {
 		return nil, errors.New("ACME challenge not specified, please select TLS or HTTP or DNS Challenge")
 	}
 
-	if p.DNSChallenge != nil && len(p.DNSChallenge.Provider) > 0 {
+	if p.DNSChallenge != nil && (len(p.DNSChallenge.Provider) > 0 || len(p.DNSChallenge.Domains) > 0) {
 		logger.Debugf("Using DNS Challenge provider: %s", p.DNSChallenge.Provider)
+		for _, domain := range p.DNSChallenge.Domains {
+			logger.Debugf("Using DNS Challenge provider %s for domain %s", domain.Provider, domain.Domain)
+		}
 
 		var provider challenge.Provider
-		provider, err = dns.NewDNSChallengeProviderByName(p.DNSChallenge.Provider)
+		provider, err = newDNSChallengeProvider(p.DNSChallenge)
 		if err != nil {
 			return nil, err
 		}
@@ -482,7 +535,27 @@ func (p *Provider) resolveCertificate(ctx context.Context, domain types.Domain,
 
 	defer p.removeResolvingDomains(uncheckedDomains)
 
+	if len(uncheckedDomains) > 1 {
+		domain = types.Domain{Main: uncheckedDomains[0], SANs: uncheckedDomains[1:]}
+	} else {
+		domain = types.Domain{Main: uncheckedDomains[0]}
+	}
+
+	unlock, stored, err := p.lockCertificate(ctx, domain)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
 	logger := log.FromContext(ctx)
+
+	renewPeriod, _ := getCertificateRenewDurations(p.CertificatesDuration)
+	if stored != nil && !isRenewalNeeded(ctx, &stored.Certificate, renewPeriod) {
+		logger.Debugf("Certificates for domains %+v already obtained by another instance", uncheckedDomains)
+		p.addCertificateForDomain(domain, stored.Certificate.Certificate, stored.Key, tlsStore)
+		return nil, nil
+	}
+
 	logger.Debugf("Loading ACME certificates %+v...", uncheckedDomains)
 
 	client, err := p.getClient()
@@ -510,11 +583,6 @@ func (p *Provider) resolveCertificate(ctx context.Context, domain types.Domain,
 
 	logger.Debugf("Certificates obtained for domains %+v", uncheckedDomains)
 
-	if len(uncheckedDomains) > 1 {
-		domain = types.Domain{Main: uncheckedDomains[0], SANs: uncheckedDomains[1:]}
-	} else {
-		domain = types.Domain{Main: uncheckedDomains[0]}
-	}
 	p.addCertificateForDomain(domain, cert.Certificate, cert.PrivateKey, tlsStore)
 
 	return cert, nil
@@ -529,6 +597,35 @@ func (p *Provider) removeResolvingDomains(resolvingDomains []string) {
 	}
 }
 
+// lockCertificate takes the lock on the certificate of the given domains,
+// shared with the other Traefik instances using the same store,
+// and returns the certificate stored for these domains, if any, as another instance might have obtained it in the meantime.
+func (p *Provider) lockCertificate(ctx context.Context, domain types.Domain) (func(), *CertAndStore, error) {
+	shared, ok := p.Store.(SharedStore)
+	if !ok {
+		return func() {}, nil, nil
+	}
+
+	unlock, err := shared.Lock(ctx, p.ResolverName+"/domains/"+strings.Join(domain.ToStrArray(), ","))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	certificates, err := shared.GetCertificates(p.ResolverName)
+	if err != nil {
+		unlock()
+		return nil, nil, fmt.Errorf("unable to get ACME certificates: %w", err)
+	}
+
+	for _, cert := range certificates {
+		if reflect.DeepEqual(cert.Domain, domain) {
+			return unlock, cert, nil
+		}
+	}
+
+	return unlock, nil, nil
+}
+
 func (p *Provider) addCertificateForDomain(domain types.Domain, certificate, key []byte, tlsStore string) {
 	p.certsChan <- &CertAndStore{Certificate: Certificate{Certificate: certificate, Key: key, Domain: domain}, Store: tlsStore}
 }
@@ -612,9 +709,28 @@ func (p *Provider) watchCertificate(ctx context.Context) {
 	p.certsChan = make(chan *CertAndStore)
 
 	p.pool.GoCtx(func(ctxPool context.Context) {
+		var storedCertsChan <-chan []*CertAndStore
+		if shared, ok := p.Store.(SharedStore); ok {
+			var err error
+			storedCertsChan, err = shared.WatchCertificates(log.With(ctxPool, log.Str(log.ProviderName, p.ResolverName+".acme")), p.ResolverName)
+			if err != nil {
+				log.FromContext(ctx).Errorf("Unable to watch the shared certificates: %v", err)
+			}
+		}
+
 		for {
 			select {
+			case certificates := <-storedCertsChan:
+				if reflect.DeepEqual(certificates, p.certificates) {
+					continue
+				}
+
+				log.FromContext(ctx).Debug("Certificates updated by another instance")
+				p.certificates = certificates
+				p.refreshCertificates()
 			case cert := <-p.certsChan:
+				unlock := p.syncStoredCertificates(ctx)
+
 				certUpdated := false
 				for _, domainsCertificate := range p.certificates {
 					if reflect.DeepEqual(cert.Domain, domainsCertificate.Certificate.Domain) {
@@ -631,6 +747,8 @@ func (p *Provider) watchCertificate(ctx context.Context) {
 				if err != nil {
 					log.FromContext(ctx).Error(err)
 				}
+
+				unlock()
 			case <-ctxPool.Done():
 				return
 			}
@@ -638,6 +756,34 @@ func (p *Provider) watchCertificate(ctx context.Context) {
 	})
 }
 
+// syncStoredCertificates takes the lock on the certificates list shared with the other Traefik instances,
+// and reloads it, so that the certificates obtained by these instances are not overwritten.
+// It returns the function releasing the lock.
+func (p *Provider) syncStoredCertificates(ctx context.Context) func() {
+	shared, ok := p.Store.(SharedStore)
+	if !ok {
+		return func() {}
+	}
+
+	logger := log.FromContext(ctx)
+
+	unlock, err := shared.Lock(ctx, p.ResolverName+"/certificates")
+	if err != nil {
+		logger.Errorf("Unable to lock the shared certificates: %v", err)
+		return func() {}
+	}
+
+	certificates, err := shared.GetCertificates(p.ResolverName)
+	if err != nil {
+		logger.Errorf("Unable to reload the shared certificates: %v", err)
+		return unlock
+	}
+
+	p.certificates = certificates
+
+	return unlock
+}
+
 func (p *Provider) saveCertificates() error {
 	err := p.Store.SaveCertificates(p.ResolverName, p.certificates)
 
@@ -678,35 +824,93 @@ func (p *Provider) renewCertificates(ctx context.Context, renewPeriod time.Durat
 
 	logger.Info("Testing certificate renew...")
 	for _, cert := range p.certificates {
-		crt, err := getX509Certificate(ctx, &cert.Certificate)
 		// If there's an error, we assume the cert is broken, and needs update
-		if err != nil || crt == nil || crt.NotAfter.Before(time.Now().Add(renewPeriod)) {
-			client, err := p.getClient()
-			if err != nil {
-				logger.Infof("Error renewing certificate from LE : %+v, %v", cert.Domain, err)
-				continue
-			}
+		if isRenewalNeeded(ctx, &cert.Certificate, renewPeriod) {
+			p.renewCertificate(ctx, cert, renewPeriod)
+		}
+	}
+}
 
-			logger.Infof("Renewing certificate from LE : %+v", cert.Domain)
+func (p *Provider) renewCertificate(ctx context.Context, cert *CertAndStore, renewPeriod time.Duration) {
+	logger := log.FromContext(ctx)
 
-			renewedCert, err := client.Certificate.Renew(certificate.Resource{
-				Domain:      cert.Domain.Main,
-				PrivateKey:  cert.Key,
-				Certificate: cert.Certificate.Certificate,
-			}, true, oscpMustStaple, p.PreferredChain)
-			if err != nil {
-				logger.Errorf("Error renewing certificate from LE: %v, %v", cert.Domain, err)
-				continue
-			}
+	unlock, stored, err := p.lockCertificate(ctx, cert.Domain)
+	if err != nil {
+		p.recordRenewal(ctx, cert.Domain, nil, err)
+		return
+	}
+	defer unlock()
 
-			if len(renewedCert.Certificate) == 0 || len(renewedCert.PrivateKey) == 0 {
-				logger.Errorf("domains %v renew certificate with no value: %v", cert.Domain.ToStrArray(), cert)
-				continue
-			}
+	if stored != nil && !isRenewalNeeded(ctx, &stored.Certificate, renewPeriod) {
+		logger.Debugf("Certificate %+v already renewed by another instance", cert.Domain)
+		p.addCertificateForDomain(cert.Domain, stored.Certificate.Certificate, stored.Key, cert.Store)
+		return
+	}
 
-			p.addCertificateForDomain(cert.Domain, renewedCert.Certificate, renewedCert.PrivateKey, cert.Store)
+	client, err := p.getClient()
+	if err != nil {
+		p.recordRenewal(ctx, cert.Domain, nil, err)
+		return
+	}
+
+	logger.Infof("Renewing certificate from LE : %+v", cert.Domain)
+
+	renewedCert, err := client.Certificate.Renew(certificate.Resource{
+		Domain:      cert.Domain.Main,
+		PrivateKey:  cert.Key,
+		Certificate: cert.Certificate.Certificate,
+	}, true, oscpMustStaple, p.PreferredChain)
+	if err != nil {
+		p.recordRenewal(ctx, cert.Domain, nil, err)
+		return
+	}
+
+	if len(renewedCert.Certificate) == 0 || len(renewedCert.PrivateKey) == 0 {
+		p.recordRenewal(ctx, cert.Domain, nil, errors.New("renewed certificate with no value"))
+		return
+	}
+
+	p.recordRenewal(ctx, cert.Domain, &Certificate{Domain: cert.Domain, Certificate: renewedCert.Certificate, Key: renewedCert.PrivateKey}, nil)
+
+	p.addCertificateForDomain(cert.Domain, renewedCert.Certificate, renewedCert.PrivateKey, cert.Store)
+}
+
+// recordRenewal logs the outcome of the renewal of a certificate as a structured event,
+// and counts it in the metrics.
+func (p *Provider) recordRenewal(ctx context.Context, domain types.Domain, renewed *Certificate, err error) {
+	logger := log.FromContext(ctx).WithFields(logrus.Fields{
+		"event":   "certificateRenewal",
+		"domains": strings.Join(domain.ToStrArray(), ","),
+	})
+
+	// The domains are not a label, as they would make the number of series grow without bound.
+	labels := []string{"resolver", p.ResolverName}
+
+	if err != nil {
+		if p.metricsRegistry != nil {
+			p.metricsRegistry.TLSCertsRenewalsFailureCounter().With(labels...).Add(1)
 		}
+
+		logger.WithField("status", "failure").Errorf("Unable to renew certificate: %v", err)
+		return
 	}
+
+	if p.metricsRegistry != nil {
+		p.metricsRegistry.TLSCertsRenewalsCounter().With(labels...).Add(1)
+	}
+
+	if crt, errX509 := getX509Certificate(ctx, renewed); errX509 == nil && crt != nil {
+		logger = logger.WithField("notAfter", crt.NotAfter.UTC().Format(time.RFC3339))
+	}
+
+	logger.WithField("status", "success").Info("Certificate renewed")
+}
+
+// isRenewalNeeded returns whether the given certificate expires within the renew period, or cannot be parsed.
+func isRenewalNeeded(ctx context.Context, cert *Certificate, renewPeriod time.Duration) bool {
+	crt, err := getX509Certificate(ctx, cert)
+
+	return err != nil || crt == nil || crt.NotAfter.Before(time.Now().Add(renewPeriod))
 }
 
 // Get provided certificate which check a domains list (Main and SANs)
@@ -1,5 +1,7 @@
 package acme
 
+import "context"
+
 // StoredData represents the data managed by Store.
 type StoredData struct {
 	Account      *Account
@@ -13,3 +15,24 @@ type Store interface {
 	GetCertificates(string) ([]*CertAndStore, error)
 	SaveCertificates(string, []*CertAndStore) error
 }
+
+// ChallengeStore is implemented by the stores able to share the HTTP-01 challenge tokens between Traefik instances.
+type ChallengeStore interface {
+	GetHTTPChallengeToken(token, domain string) ([]byte, error)
+	SetHTTPChallengeToken(token, domain string, keyAuth []byte) error
+	RemoveHTTPChallengeToken(token, domain string) error
+}
+
+// SharedStore is implemented by the stores shared between several Traefik instances.
+// It allows the instances to take turns when obtaining or renewing certificates,
+// and to be notified of the certificates obtained by the other instances.
+type SharedStore interface {
+	Store
+	ChallengeStore
+
+	// Lock acquires a lock on the given key, shared between all the instances using the store.
+	// The lock is held until the returned unlock function is called.
+	Lock(ctx context.Context, key string) (unlock func(), err error)
+	// WatchCertificates sends the certificates list of the given resolver each time it is changed.
+	WatchCertificates(ctx context.Context, resolverName string) (<-chan []*CertAndStore, error)
+}
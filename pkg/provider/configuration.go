@@ -31,8 +31,9 @@ func Merge(ctx context.Context, configurations map[string]*dynamic.Configuration
 			Middlewares: make(map[string]*dynamic.TCPMiddleware),
 		},
 		UDP: &dynamic.UDPConfiguration{
-			Routers:  make(map[string]*dynamic.UDPRouter),
-			Services: make(map[string]*dynamic.UDPService),
+			Routers:     make(map[string]*dynamic.UDPRouter),
+			Services:    make(map[string]*dynamic.UDPService),
+			Middlewares: make(map[string]*dynamic.UDPMiddleware),
 		},
 	}
 
@@ -60,6 +61,9 @@ func Merge(ctx context.Context, configurations map[string]*dynamic.Configuration
 	middlewaresTCPToDelete := map[string]struct{}{}
 	middlewaresTCP := map[string][]string{}
 
+	middlewaresUDPToDelete := map[string]struct{}{}
+	middlewaresUDP := map[string][]string{}
+
 	transportsToDelete := map[string]struct{}{}
 	transports := map[string][]string{}
 
@@ -133,6 +137,13 @@ func Merge(ctx context.Context, configurations map[string]*dynamic.Configuration
 				middlewaresTCPToDelete[middlewareName] = struct{}{}
 			}
 		}
+
+		for middlewareName, middleware := range conf.UDP.Middlewares {
+			middlewaresUDP[middlewareName] = append(middlewaresUDP[middlewareName], root)
+			if !AddMiddlewareUDP(configuration.UDP, middlewareName, middleware) {
+				middlewaresUDPToDelete[middlewareName] = struct{}{}
+			}
+		}
 	}
 
 	for serviceName := range servicesToDelete {
@@ -189,6 +200,12 @@ func Merge(ctx context.Context, configurations map[string]*dynamic.Configuration
 		delete(configuration.TCP.Middlewares, middlewareName)
 	}
 
+	for middlewareName := range middlewaresUDPToDelete {
+		logger.WithField(log.MiddlewareName, middlewareName).
+			Errorf("UDP middleware defined multiple times with different configurations in %v", middlewaresUDP[middlewareName])
+		delete(configuration.UDP.Middlewares, middlewareName)
+	}
+
 	return configuration
 }
 
@@ -272,6 +289,16 @@ func AddRouterUDP(configuration *dynamic.UDPConfiguration, routerName string, ro
 	return reflect.DeepEqual(configuration.Routers[routerName], router)
 }
 
+// AddMiddlewareUDP adds a middleware to a configuration.
+func AddMiddlewareUDP(configuration *dynamic.UDPConfiguration, middlewareName string, middleware *dynamic.UDPMiddleware) bool {
+	if _, ok := configuration.Middlewares[middlewareName]; !ok {
+		configuration.Middlewares[middlewareName] = middleware
+		return true
+	}
+
+	return reflect.DeepEqual(configuration.Middlewares[middlewareName], middleware)
+}
+
 // AddService Adds a service to a configurations.
 func AddService(configuration *dynamic.HTTPConfiguration, serviceName string, service *dynamic.Service) bool {
 	if _, ok := configuration.Services[serviceName]; !ok {
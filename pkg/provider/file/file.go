@@ -253,6 +253,49 @@ func (p *Provider) loadFileConfig(ctx context.Context, filename string, parseTem
 		}
 	}
 
+	// TCP ServersTransport
+	if configuration.TCP != nil && len(configuration.TCP.ServersTransports) > 0 {
+		for _, st := range configuration.TCP.ServersTransports {
+			if st.TLS == nil {
+				continue
+			}
+
+			var certificates []tls.Certificate
+			for _, cert := range st.TLS.Certificates {
+				content, err := cert.CertFile.Read()
+				if err != nil {
+					log.FromContext(ctx).Error(err)
+					continue
+				}
+				cert.CertFile = tls.FileOrContent(content)
+
+				content, err = cert.KeyFile.Read()
+				if err != nil {
+					log.FromContext(ctx).Error(err)
+					continue
+				}
+				cert.KeyFile = tls.FileOrContent(content)
+
+				certificates = append(certificates, cert)
+			}
+
+			st.TLS.Certificates = certificates
+
+			var rootCAs []tls.FileOrContent
+			for _, rootCA := range st.TLS.RootCAs {
+				content, err := rootCA.Read()
+				if err != nil {
+					log.FromContext(ctx).Error(err)
+					continue
+				}
+
+				rootCAs = append(rootCAs, tls.FileOrContent(content))
+			}
+
+			st.TLS.RootCAs = rootCAs
+		}
+	}
+
 	return configuration, nil
 }
 
@@ -294,17 +337,19 @@ func (p *Provider) loadFileConfigFromDirectory(ctx context.Context, directory st
 				ServersTransports: make(map[string]*dynamic.ServersTransport),
 			},
 			TCP: &dynamic.TCPConfiguration{
-				Routers:     make(map[string]*dynamic.TCPRouter),
-				Services:    make(map[string]*dynamic.TCPService),
-				Middlewares: make(map[string]*dynamic.TCPMiddleware),
+				Routers:           make(map[string]*dynamic.TCPRouter),
+				Services:          make(map[string]*dynamic.TCPService),
+				Middlewares:       make(map[string]*dynamic.TCPMiddleware),
+				ServersTransports: make(map[string]*dynamic.TCPServersTransport),
 			},
 			TLS: &dynamic.TLSConfiguration{
 				Stores:  make(map[string]tls.Store),
 				Options: make(map[string]tls.Options),
 			},
 			UDP: &dynamic.UDPConfiguration{
-				Routers:  make(map[string]*dynamic.UDPRouter),
-				Services: make(map[string]*dynamic.UDPService),
+				Routers:     make(map[string]*dynamic.UDPRouter),
+				Services:    make(map[string]*dynamic.UDPService),
+				Middlewares: make(map[string]*dynamic.UDPMiddleware),
 			},
 		}
 	}
@@ -391,6 +436,14 @@ func (p *Provider) loadFileConfigFromDirectory(ctx context.Context, directory st
 			}
 		}
 
+		for name, conf := range c.TCP.ServersTransports {
+			if _, exists := configuration.TCP.ServersTransports[name]; exists {
+				logger.WithField(log.ServersTransportName, name).Warn("TCP servers transport already configured, skipping")
+			} else {
+				configuration.TCP.ServersTransports[name] = conf
+			}
+		}
+
 		for name, conf := range c.UDP.Routers {
 			if _, exists := configuration.UDP.Routers[name]; exists {
 				logger.WithField(log.RouterName, name).Warn("UDP router already configured, skipping")
@@ -399,6 +452,14 @@ func (p *Provider) loadFileConfigFromDirectory(ctx context.Context, directory st
 			}
 		}
 
+		for name, conf := range c.UDP.Middlewares {
+			if _, exists := configuration.UDP.Middlewares[name]; exists {
+				logger.WithField(log.MiddlewareName, name).Warn("UDP middleware already configured, skipping")
+			} else {
+				configuration.UDP.Middlewares[name] = conf
+			}
+		}
+
 		for name, conf := range c.UDP.Services {
 			if _, exists := configuration.UDP.Services[name]; exists {
 				logger.WithField(log.ServiceName, name).Warn("UDP service already configured, skipping")
@@ -505,17 +566,19 @@ func (p *Provider) decodeConfiguration(filePath, content string) (*dynamic.Confi
 			ServersTransports: make(map[string]*dynamic.ServersTransport),
 		},
 		TCP: &dynamic.TCPConfiguration{
-			Routers:     make(map[string]*dynamic.TCPRouter),
-			Services:    make(map[string]*dynamic.TCPService),
-			Middlewares: make(map[string]*dynamic.TCPMiddleware),
+			Routers:           make(map[string]*dynamic.TCPRouter),
+			Services:          make(map[string]*dynamic.TCPService),
+			Middlewares:       make(map[string]*dynamic.TCPMiddleware),
+			ServersTransports: make(map[string]*dynamic.TCPServersTransport),
 		},
 		TLS: &dynamic.TLSConfiguration{
 			Stores:  make(map[string]tls.Store),
 			Options: make(map[string]tls.Options),
 		},
 		UDP: &dynamic.UDPConfiguration{
-			Routers:  make(map[string]*dynamic.UDPRouter),
-			Services: make(map[string]*dynamic.UDPService),
+			Routers:     make(map[string]*dynamic.UDPRouter),
+			Services:    make(map[string]*dynamic.UDPService),
+			Middlewares: make(map[string]*dynamic.UDPMiddleware),
 		},
 	}
 
@@ -220,6 +220,36 @@ func (p *Provider) loadConfigurationFromCRD(ctx context.Context, client Client)
 			continue
 		}
 
+		jwtAuth, err := createJWTAuthMiddleware(client, middleware.Namespace, middleware.Spec.JWTAuth)
+		if err != nil {
+			log.FromContext(ctxMid).Errorf("Error while reading JWT auth middleware: %v", err)
+			continue
+		}
+
+		oidcAuth, err := createOIDCAuthMiddleware(client, middleware.Namespace, middleware.Spec.OIDCAuth)
+		if err != nil {
+			log.FromContext(ctxMid).Errorf("Error while reading OIDC auth middleware: %v", err)
+			continue
+		}
+
+		apiKeyAuth, err := createAPIKeyAuthMiddleware(client, middleware.Namespace, middleware.Spec.APIKeyAuth)
+		if err != nil {
+			log.FromContext(ctxMid).Errorf("Error while reading API key auth middleware: %v", err)
+			continue
+		}
+
+		hmacAuth, err := createHMACAuthMiddleware(client, middleware.Namespace, middleware.Spec.HMACAuth)
+		if err != nil {
+			log.FromContext(ctxMid).Errorf("Error while reading HMAC auth middleware: %v", err)
+			continue
+		}
+
+		challenge, err := createChallengeMiddleware(client, middleware.Namespace, middleware.Spec.Challenge)
+		if err != nil {
+			log.FromContext(ctxMid).Errorf("Error while reading challenge middleware: %v", err)
+			continue
+		}
+
 		errorPage, errorPageService, err := p.createErrorPageMiddleware(client, middleware.Namespace, middleware.Spec.Errors)
 		if err != nil {
 			log.FromContext(ctxMid).Errorf("Error while reading error page middleware: %v", err)
@@ -272,11 +302,22 @@ func (p *Provider) loadConfigurationFromCRD(ctx context.Context, client Client)
 			BasicAuth:         basicAuth,
 			DigestAuth:        digestAuth,
 			ForwardAuth:       forwardAuth,
+			JWTAuth:           jwtAuth,
+			OIDCAuth:          oidcAuth,
+			APIKeyAuth:        apiKeyAuth,
+			HMACAuth:          hmacAuth,
 			InFlightReq:       middleware.Spec.InFlightReq,
 			Buffering:         middleware.Spec.Buffering,
 			CircuitBreaker:    circuitBreaker,
 			Compress:          middleware.Spec.Compress,
 			PassTLSClientCert: middleware.Spec.PassTLSClientCert,
+			TLSClientCertAuth: middleware.Spec.TLSClientCertAuth,
+			WAF:               createWAFMiddleware(middleware.Spec.WAF),
+			BodyLimit:         middleware.Spec.BodyLimit,
+			RequestValidation: createRequestValidationMiddleware(middleware.Spec.RequestValidation),
+			GeoIP:             middleware.Spec.GeoIP,
+			IPDenyList:        middleware.Spec.IPDenyList,
+			Challenge:         challenge,
 			Retry:             retry,
 			ContentType:       middleware.Spec.ContentType,
 			Plugin:            plugin,
@@ -289,6 +330,11 @@ func (p *Provider) loadConfigurationFromCRD(ctx context.Context, client Client)
 		conf.TCP.Middlewares[id] = &dynamic.TCPMiddleware{
 			InFlightConn: middlewareTCP.Spec.InFlightConn,
 			IPWhiteList:  middlewareTCP.Spec.IPWhiteList,
+			IPDenyList:   middlewareTCP.Spec.IPDenyList,
+			RateLimit:    middlewareTCP.Spec.RateLimit,
+			MaxConn:      middlewareTCP.Spec.MaxConn,
+			ConnTimeout:  middlewareTCP.Spec.ConnTimeout,
+			AccessLog:    middlewareTCP.Spec.AccessLog,
 		}
 	}
 
@@ -381,6 +427,7 @@ func (p *Provider) loadConfigurationFromCRD(ctx context.Context, client Client)
 			MaxIdleConnsPerHost: serversTransport.Spec.MaxIdleConnsPerHost,
 			ForwardingTimeouts:  forwardingTimeout,
 			PeerCertURI:         serversTransport.Spec.PeerCertURI,
+			Spiffe:              serversTransport.Spec.Spiffe,
 		}
 	}
 
@@ -540,6 +587,32 @@ func createForwardAuthMiddleware(k8sClient Client, namespace string, auth *v1alp
 		AuthResponseHeaders:      auth.AuthResponseHeaders,
 		AuthResponseHeadersRegex: auth.AuthResponseHeadersRegex,
 		AuthRequestHeaders:       auth.AuthRequestHeaders,
+		ForwardBody:              auth.ForwardBody,
+		MaxBodySize:              auth.MaxBodySize,
+		FailurePolicy:            auth.FailurePolicy,
+		FailureStatus:            auth.FailureStatus,
+	}
+
+	if auth.Cache != nil {
+		forwardAuth.Cache = &dynamic.ForwardAuthCache{
+			KeyHeaders:         auth.Cache.KeyHeaders,
+			KeyCookies:         auth.Cache.KeyCookies,
+			IgnoreMethodAndURI: auth.Cache.IgnoreMethodAndURI,
+			Store:              auth.Cache.Store,
+			MaxEntries:         auth.Cache.MaxEntries,
+		}
+
+		if auth.Cache.TTL != nil {
+			if err := forwardAuth.Cache.TTL.Set(auth.Cache.TTL.String()); err != nil {
+				return nil, err
+			}
+		}
+
+		if auth.Cache.DenyTTL != nil {
+			if err := forwardAuth.Cache.DenyTTL.Set(auth.Cache.DenyTTL.String()); err != nil {
+				return nil, err
+			}
+		}
 	}
 
 	if auth.TLS == nil {
@@ -571,6 +644,287 @@ func createForwardAuthMiddleware(k8sClient Client, namespace string, auth *v1alp
 	return forwardAuth, nil
 }
 
+func createJWTAuthMiddleware(client Client, namespace string, auth *v1alpha1.JWTAuth) (*dynamic.JWTAuth, error) {
+	if auth == nil {
+		return nil, nil
+	}
+
+	jwtAuth := &dynamic.JWTAuth{
+		Keys:           auth.Keys,
+		JWKSURL:        auth.JWKSURL,
+		Algorithms:     auth.Algorithms,
+		Issuers:        auth.Issuers,
+		Audiences:      auth.Audiences,
+		RequiredClaims: auth.RequiredClaims,
+		RequiredScopes: auth.RequiredScopes,
+		ClaimsHeaders:  auth.ClaimsHeaders,
+		RemoveHeader:   auth.RemoveHeader,
+	}
+	jwtAuth.SetDefaults()
+
+	if auth.JWKSRefreshInterval != nil {
+		if err := jwtAuth.JWKSRefreshInterval.Set(auth.JWKSRefreshInterval.String()); err != nil {
+			return nil, err
+		}
+	}
+
+	if auth.ClockSkew != nil {
+		if err := jwtAuth.ClockSkew.Set(auth.ClockSkew.String()); err != nil {
+			return nil, err
+		}
+	}
+
+	if auth.RequireExp != nil {
+		jwtAuth.RequireExp = *auth.RequireExp
+	}
+
+	if auth.Secret == "" {
+		return jwtAuth, nil
+	}
+
+	secret, ok, err := client.GetSecret(namespace, auth.Secret)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch secret '%s/%s': %w", namespace, auth.Secret, err)
+	}
+	if !ok {
+		return nil, fmt.Errorf("secret '%s/%s' not found", namespace, auth.Secret)
+	}
+	if secret == nil {
+		return nil, fmt.Errorf("data for secret '%s/%s' must not be nil", namespace, auth.Secret)
+	}
+
+	sharedSecret, ok := secret.Data["secret"]
+	if !ok || len(sharedSecret) == 0 {
+		return nil, fmt.Errorf("secret '%s/%s' must contain a secret key", namespace, auth.Secret)
+	}
+
+	jwtAuth.Secret = string(sharedSecret)
+
+	return jwtAuth, nil
+}
+
+func createOIDCAuthMiddleware(client Client, namespace string, auth *v1alpha1.OIDCAuth) (*dynamic.OIDCAuth, error) {
+	if auth == nil {
+		return nil, nil
+	}
+
+	if auth.Secret == "" {
+		return nil, fmt.Errorf("auth secret must be set")
+	}
+
+	oidcAuth := &dynamic.OIDCAuth{}
+	oidcAuth.SetDefaults()
+
+	oidcAuth.IssuerURL = auth.IssuerURL
+	oidcAuth.ClientID = auth.ClientID
+	oidcAuth.PostLogoutRedirectURL = auth.PostLogoutRedirectURL
+	oidcAuth.CookieDomain = auth.CookieDomain
+	oidcAuth.AllowedGroups = auth.AllowedGroups
+	oidcAuth.RequiredClaims = auth.RequiredClaims
+	oidcAuth.ClaimsHeaders = auth.ClaimsHeaders
+	oidcAuth.ForwardAccessToken = auth.ForwardAccessToken
+
+	if len(auth.Scopes) > 0 {
+		oidcAuth.Scopes = auth.Scopes
+	}
+	if auth.CallbackPath != "" {
+		oidcAuth.CallbackPath = auth.CallbackPath
+	}
+	if auth.LogoutPath != "" {
+		oidcAuth.LogoutPath = auth.LogoutPath
+	}
+	if auth.CookieName != "" {
+		oidcAuth.CookieName = auth.CookieName
+	}
+	if auth.CookiePath != "" {
+		oidcAuth.CookiePath = auth.CookiePath
+	}
+	if auth.CookieSameSite != "" {
+		oidcAuth.CookieSameSite = auth.CookieSameSite
+	}
+	if auth.GroupsClaim != "" {
+		oidcAuth.GroupsClaim = auth.GroupsClaim
+	}
+
+	if auth.SessionMaxAge != nil {
+		if err := oidcAuth.SessionMaxAge.Set(auth.SessionMaxAge.String()); err != nil {
+			return nil, err
+		}
+	}
+
+	secret, ok, err := client.GetSecret(namespace, auth.Secret)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch secret '%s/%s': %w", namespace, auth.Secret, err)
+	}
+	if !ok {
+		return nil, fmt.Errorf("secret '%s/%s' not found", namespace, auth.Secret)
+	}
+	if secret == nil {
+		return nil, fmt.Errorf("data for secret '%s/%s' must not be nil", namespace, auth.Secret)
+	}
+
+	sessionSecret, ok := secret.Data["sessionSecret"]
+	if !ok || len(sessionSecret) == 0 {
+		return nil, fmt.Errorf("secret '%s/%s' must contain a sessionSecret key", namespace, auth.Secret)
+	}
+
+	oidcAuth.SessionSecret = string(sessionSecret)
+	oidcAuth.ClientSecret = string(secret.Data["clientSecret"])
+
+	return oidcAuth, nil
+}
+
+func createAPIKeyAuthMiddleware(client Client, namespace string, auth *v1alpha1.APIKeyAuth) (*dynamic.APIKeyAuth, error) {
+	if auth == nil {
+		return nil, nil
+	}
+
+	if auth.Secret == "" {
+		return nil, fmt.Errorf("auth secret must be set")
+	}
+
+	secret, ok, err := client.GetSecret(namespace, auth.Secret)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch secret '%s/%s': %w", namespace, auth.Secret, err)
+	}
+	if !ok {
+		return nil, fmt.Errorf("secret '%s/%s' not found", namespace, auth.Secret)
+	}
+	if secret == nil {
+		return nil, fmt.Errorf("data for secret '%s/%s' must not be nil", namespace, auth.Secret)
+	}
+
+	keys, err := loadAuthCredentials(secret)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load API keys: %w", err)
+	}
+
+	apiKeyAuth := &dynamic.APIKeyAuth{}
+	apiKeyAuth.SetDefaults()
+
+	apiKeyAuth.Keys = keys
+	apiKeyAuth.QueryParam = auth.QueryParam
+	apiKeyAuth.CookieName = auth.CookieName
+	apiKeyAuth.RemoveKey = auth.RemoveKey
+	apiKeyAuth.ConsumerHeader = auth.ConsumerHeader
+	apiKeyAuth.MetadataHeaders = auth.MetadataHeaders
+
+	if auth.HeaderName != "" {
+		apiKeyAuth.HeaderName = auth.HeaderName
+	}
+
+	return apiKeyAuth, nil
+}
+
+func createWAFMiddleware(waf *v1alpha1.WAF) *dynamic.WAF {
+	if waf == nil {
+		return nil
+	}
+
+	wafConfig := &dynamic.WAF{}
+	wafConfig.SetDefaults()
+
+	wafConfig.Rules = waf.Rules
+	wafConfig.Exclusions = waf.Exclusions
+
+	if waf.Mode != "" {
+		wafConfig.Mode = waf.Mode
+	}
+
+	if waf.AnomalyThreshold != 0 {
+		wafConfig.AnomalyThreshold = waf.AnomalyThreshold
+	}
+
+	if waf.MaxBodySize != nil {
+		wafConfig.MaxBodySize = *waf.MaxBodySize
+	}
+
+	return wafConfig
+}
+
+func createRequestValidationMiddleware(validation *v1alpha1.RequestValidation) *dynamic.RequestValidation {
+	if validation == nil {
+		return nil
+	}
+
+	validationConfig := &dynamic.RequestValidation{
+		Schema:  validation.Schema,
+		OpenAPI: validation.OpenAPI,
+	}
+	validationConfig.SetDefaults()
+
+	if validation.MaxBodySize != nil {
+		validationConfig.MaxBodySize = *validation.MaxBodySize
+	}
+
+	return validationConfig
+}
+
+func createHMACAuthMiddleware(client Client, namespace string, auth *v1alpha1.HMACAuth) (*dynamic.HMACAuth, error) {
+	if auth == nil {
+		return nil, nil
+	}
+
+	if auth.Secret == "" {
+		return nil, fmt.Errorf("auth secret must be set")
+	}
+
+	secret, ok, err := client.GetSecret(namespace, auth.Secret)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch secret '%s/%s': %w", namespace, auth.Secret, err)
+	}
+	if !ok {
+		return nil, fmt.Errorf("secret '%s/%s' not found", namespace, auth.Secret)
+	}
+	if secret == nil {
+		return nil, fmt.Errorf("data for secret '%s/%s' must not be nil", namespace, auth.Secret)
+	}
+
+	secrets, err := loadAuthCredentials(secret)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load HMAC secrets: %w", err)
+	}
+
+	hmacAuth := &dynamic.HMACAuth{}
+	hmacAuth.SetDefaults()
+
+	hmacAuth.Secrets = secrets
+	hmacAuth.SignaturePrefix = auth.SignaturePrefix
+	hmacAuth.SignedHeaders = auth.SignedHeaders
+	hmacAuth.TimestampHeader = auth.TimestampHeader
+	hmacAuth.NonceHeader = auth.NonceHeader
+	hmacAuth.NonceStore = auth.NonceStore
+	hmacAuth.KeyIDHeader = auth.KeyIDHeader
+
+	if auth.Scheme != "" {
+		hmacAuth.Scheme = auth.Scheme
+	}
+
+	if auth.Algorithm != "" {
+		hmacAuth.Algorithm = auth.Algorithm
+	}
+
+	if auth.SignatureHeader != "" {
+		hmacAuth.SignatureHeader = auth.SignatureHeader
+	}
+
+	if auth.SignatureEncoding != "" {
+		hmacAuth.SignatureEncoding = auth.SignatureEncoding
+	}
+
+	if auth.MaxSkew != nil {
+		if err := hmacAuth.MaxSkew.Set(auth.MaxSkew.String()); err != nil {
+			return nil, err
+		}
+	}
+
+	if auth.MaxBodySize != nil {
+		hmacAuth.MaxBodySize = *auth.MaxBodySize
+	}
+
+	return hmacAuth, nil
+}
+
 func loadCASecret(namespace, secretName string, k8sClient Client) (string, error) {
 	secret, ok, err := k8sClient.GetSecret(namespace, secretName)
 	if err != nil {
@@ -618,6 +972,78 @@ func loadAuthTLSSecret(namespace, secretName string, k8sClient Client) (string,
 	return getCertificateBlocks(secret, namespace, secretName)
 }
 
+func createChallengeMiddleware(client Client, namespace string, challenge *v1alpha1.Challenge) (*dynamic.Challenge, error) {
+	if challenge == nil {
+		return nil, nil
+	}
+
+	if challenge.Secret == "" {
+		return nil, fmt.Errorf("challenge secret must be set")
+	}
+
+	challengeMiddleware := &dynamic.Challenge{}
+	challengeMiddleware.SetDefaults()
+
+	challengeMiddleware.UserAgents = challenge.UserAgents
+	challengeMiddleware.RequestRate = challenge.RequestRate
+	challengeMiddleware.IPStrategy = challenge.IPStrategy
+	challengeMiddleware.CookieDomain = challenge.CookieDomain
+
+	if challenge.Mode != "" {
+		challengeMiddleware.Mode = challenge.Mode
+	}
+	if challenge.Difficulty != nil {
+		challengeMiddleware.Difficulty = *challenge.Difficulty
+	}
+	if len(challenge.BindTo) > 0 {
+		challengeMiddleware.BindTo = challenge.BindTo
+	}
+	if challenge.VerifyPath != "" {
+		challengeMiddleware.VerifyPath = challenge.VerifyPath
+	}
+	if challenge.CookieName != "" {
+		challengeMiddleware.CookieName = challenge.CookieName
+	}
+	if challenge.CookiePath != "" {
+		challengeMiddleware.CookiePath = challenge.CookiePath
+	}
+	if challenge.CookieSameSite != "" {
+		challengeMiddleware.CookieSameSite = challenge.CookieSameSite
+	}
+
+	if challenge.ChallengeTTL != nil {
+		if err := challengeMiddleware.ChallengeTTL.Set(challenge.ChallengeTTL.String()); err != nil {
+			return nil, err
+		}
+	}
+
+	if challenge.ClearanceTTL != nil {
+		if err := challengeMiddleware.ClearanceTTL.Set(challenge.ClearanceTTL.String()); err != nil {
+			return nil, err
+		}
+	}
+
+	secret, ok, err := client.GetSecret(namespace, challenge.Secret)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch secret '%s/%s': %w", namespace, challenge.Secret, err)
+	}
+	if !ok {
+		return nil, fmt.Errorf("secret '%s/%s' not found", namespace, challenge.Secret)
+	}
+	if secret == nil {
+		return nil, fmt.Errorf("data for secret '%s/%s' must not be nil", namespace, challenge.Secret)
+	}
+
+	signingSecret, ok := secret.Data["secret"]
+	if !ok || len(signingSecret) == 0 {
+		return nil, fmt.Errorf("secret '%s/%s' must contain a secret key", namespace, challenge.Secret)
+	}
+
+	challengeMiddleware.Secret = string(signingSecret)
+
+	return challengeMiddleware, nil
+}
+
 func createBasicAuthMiddleware(client Client, namespace string, basicAuth *v1alpha1.BasicAuth) (*dynamic.BasicAuth, error) {
 	if basicAuth == nil {
 		return nil, nil
@@ -821,8 +1247,9 @@ func buildTLSOptions(ctx context.Context, client Client) map[string]tls.Options
 				CAFiles:        clientCAs,
 				ClientAuthType: tlsOption.Spec.ClientAuth.ClientAuthType,
 			},
-			SniStrict:     tlsOption.Spec.SniStrict,
-			ALPNProtocols: alpnProtocols,
+			SniStrict:             tlsOption.Spec.SniStrict,
+			ALPNProtocols:         alpnProtocols,
+			DisableSessionTickets: tlsOption.Spec.DisableSessionTickets,
 		}
 	}
 
@@ -238,6 +238,7 @@ func (c configBuilder) buildServicesLB(ctx context.Context, namespace string, tS
 		wrrServices = append(wrrServices, dynamic.WRRService{
 			Name:   fullName,
 			Weight: weight,
+			Match:  service.Match,
 		})
 	}
 
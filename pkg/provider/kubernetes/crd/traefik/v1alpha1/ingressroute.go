@@ -124,6 +124,10 @@ type LoadBalancerSpec struct {
 // Service defines an upstream HTTP service to proxy traffic to.
 type Service struct {
 	LoadBalancerSpec `json:",inline"`
+
+	// Match defines the conditions under which a request is deterministically sent to this service, regardless of the weights.
+	// It should only be specified when the service is load-balanced with others, by a route or a Weighted Round Robin TraefikService.
+	Match *dynamic.WRRMatch `json:"match,omitempty"`
 }
 
 // MiddlewareRef is a reference to a Middleware resource.
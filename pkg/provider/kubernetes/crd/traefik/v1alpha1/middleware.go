@@ -41,11 +41,22 @@ type MiddlewareSpec struct {
 	BasicAuth         *BasicAuth                 `json:"basicAuth,omitempty"`
 	DigestAuth        *DigestAuth                `json:"digestAuth,omitempty"`
 	ForwardAuth       *ForwardAuth               `json:"forwardAuth,omitempty"`
+	JWTAuth           *JWTAuth                   `json:"jwtAuth,omitempty"`
+	OIDCAuth          *OIDCAuth                  `json:"oidcAuth,omitempty"`
+	APIKeyAuth        *APIKeyAuth                `json:"apiKeyAuth,omitempty"`
+	HMACAuth          *HMACAuth                  `json:"hmacAuth,omitempty"`
 	InFlightReq       *dynamic.InFlightReq       `json:"inFlightReq,omitempty"`
 	Buffering         *dynamic.Buffering         `json:"buffering,omitempty"`
 	CircuitBreaker    *CircuitBreaker            `json:"circuitBreaker,omitempty"`
 	Compress          *dynamic.Compress          `json:"compress,omitempty"`
 	PassTLSClientCert *dynamic.PassTLSClientCert `json:"passTLSClientCert,omitempty"`
+	TLSClientCertAuth *dynamic.TLSClientCertAuth `json:"tlsClientCertAuth,omitempty"`
+	WAF               *WAF                       `json:"waf,omitempty"`
+	BodyLimit         *dynamic.BodyLimit         `json:"bodyLimit,omitempty"`
+	RequestValidation *RequestValidation         `json:"requestValidation,omitempty"`
+	GeoIP             *dynamic.GeoIP             `json:"geoIP,omitempty"`
+	IPDenyList        *dynamic.IPDenyList        `json:"ipDenyList,omitempty"`
+	Challenge         *Challenge                 `json:"challenge,omitempty"`
 	Retry             *Retry                     `json:"retry,omitempty"`
 	ContentType       *dynamic.ContentType       `json:"contentType,omitempty"`
 	// Plugin defines the middleware plugin configuration.
@@ -99,6 +110,30 @@ type Chain struct {
 
 // +k8s:deepcopy-gen=true
 
+// APIKeyAuth holds the API key authentication middleware configuration.
+// This middleware verifies the API keys of the requests against hashed keys.
+// More info: https://doc.traefik.io/traefik/v2.8/middlewares/http/apikeyauth/
+type APIKeyAuth struct {
+	// Secret is the name of the referenced Kubernetes Secret containing the authorized API keys.
+	Secret string `json:"secret,omitempty"`
+	// HeaderName defines the name of the header holding the API key.
+	// Default: X-API-Key.
+	HeaderName string `json:"headerName,omitempty"`
+	// QueryParam defines the name of the query parameter holding the API key.
+	QueryParam string `json:"queryParam,omitempty"`
+	// CookieName defines the name of the cookie holding the API key.
+	CookieName string `json:"cookieName,omitempty"`
+	// RemoveKey defines whether to remove the API key from the request before forwarding it to the service.
+	RemoveKey bool `json:"removeKey,omitempty"`
+	// ConsumerHeader defines the header set on the forwarded requests with the consumer name of the API key.
+	ConsumerHeader string `json:"consumerHeader,omitempty"`
+	// MetadataHeaders defines the headers set on the forwarded requests from the metadata of the API key.
+	// The key is the header name, and the value is the metadata name.
+	MetadataHeaders map[string]string `json:"metadataHeaders,omitempty"`
+}
+
+// +k8s:deepcopy-gen=true
+
 // BasicAuth holds the basic auth middleware configuration.
 // This middleware restricts access to your services to known users.
 // More info: https://doc.traefik.io/traefik/v2.8/middlewares/http/basicauth/
@@ -154,6 +189,174 @@ type ForwardAuth struct {
 	AuthRequestHeaders []string `json:"authRequestHeaders,omitempty"`
 	// TLS defines the configuration used to secure the connection to the authentication server.
 	TLS *ClientTLS `json:"tls,omitempty"`
+	// ForwardBody defines whether to forward the request body to the authentication server, with the method of the request.
+	ForwardBody bool `json:"forwardBody,omitempty"`
+	// MaxBodySize defines the maximum size in bytes of the request body forwarded to the authentication server.
+	// Requests with a larger body are refused. Default: 1048576 (1 MiB).
+	MaxBodySize int64 `json:"maxBodySize,omitempty"`
+	// Cache defines the caching of the authentication decisions.
+	// More info: https://doc.traefik.io/traefik/v2.8/middlewares/http/forwardauth/#cache
+	Cache *ForwardAuthCache `json:"cache,omitempty"`
+	// FailurePolicy defines how the failures of the authentication server are handled:
+	// passthrough (the response of the authentication server is returned), open (the request is forwarded), or closed (the request is refused).
+	FailurePolicy string `json:"failurePolicy,omitempty"`
+	// FailureStatus defines the status codes, or ranges of status codes, of the authentication server responses handled as failures.
+	// Default: 500-599.
+	FailureStatus []string `json:"failureStatus,omitempty"`
+}
+
+// +k8s:deepcopy-gen=true
+
+// ForwardAuthCache holds the forward auth decisions cache configuration.
+type ForwardAuthCache struct {
+	// TTL defines how long the decisions allowing the requests are cached.
+	TTL *intstr.IntOrString `json:"ttl,omitempty"`
+	// DenyTTL defines how long the decisions refusing the requests are cached.
+	// The refusals are not cached by default.
+	DenyTTL *intstr.IntOrString `json:"denyTTL,omitempty"`
+	// KeyHeaders defines the request headers identifying the decisions, such as Authorization.
+	KeyHeaders []string `json:"keyHeaders,omitempty"`
+	// KeyCookies defines the request cookies identifying the decisions.
+	KeyCookies []string `json:"keyCookies,omitempty"`
+	// IgnoreMethodAndURI defines whether the decisions are shared between the methods and URIs of a host.
+	IgnoreMethodAndURI bool `json:"ignoreMethodAndURI,omitempty"`
+	// Store defines where the decisions are cached: memory (default), or memcached to share them between the Traefik instances.
+	Store string `json:"store,omitempty"`
+	// MaxEntries defines the maximum number of decisions cached in memory. Default: 10000.
+	MaxEntries int `json:"maxEntries,omitempty"`
+}
+
+// +k8s:deepcopy-gen=true
+
+// HMACAuth holds the HMAC signature authentication middleware configuration.
+// This middleware verifies the HMAC signatures of the requests, computed with shared secrets.
+// More info: https://doc.traefik.io/traefik/v2.8/middlewares/http/hmacauth/
+type HMACAuth struct {
+	// Secret is the name of the referenced Kubernetes Secret containing the shared secrets.
+	Secret string `json:"secret,omitempty"`
+	// Scheme defines how the requests are signed: body (the signature of the body is sent in a header),
+	// or canonical (the signature of the canonical request is sent in the Authorization header).
+	// Default: body.
+	Scheme string `json:"scheme,omitempty"`
+	// Algorithm defines the hash algorithm of the signatures: sha1, sha256, or sha512.
+	// Default: sha256.
+	Algorithm string `json:"algorithm,omitempty"`
+	// SignatureHeader defines the header holding the signature, for the body scheme.
+	// Default: X-Signature.
+	SignatureHeader string `json:"signatureHeader,omitempty"`
+	// SignaturePrefix defines the prefix of the signature in the signature header, such as sha256=, for the body scheme.
+	SignaturePrefix string `json:"signaturePrefix,omitempty"`
+	// SignatureEncoding defines the encoding of the signature, for the body scheme: hex, or base64.
+	// Default: hex.
+	SignatureEncoding string `json:"signatureEncoding,omitempty"`
+	// SignedHeaders defines the headers that must be signed, for the canonical scheme.
+	SignedHeaders []string `json:"signedHeaders,omitempty"`
+	// TimestampHeader defines the header holding the signing time of the request, which is signed.
+	// It is required for the canonical scheme.
+	TimestampHeader string `json:"timestampHeader,omitempty"`
+	// MaxSkew defines the maximum difference between the signing time of the requests and the current time.
+	// Default: 5m.
+	MaxSkew *intstr.IntOrString `json:"maxSkew,omitempty"`
+	// NonceHeader defines the header holding the nonce of the request, which is signed.
+	// Each nonce is only accepted once, which protects against the replay of the requests.
+	NonceHeader string `json:"nonceHeader,omitempty"`
+	// NonceStore defines where the nonces are remembered: memory (default), which only detects the requests replayed to the same Traefik instance,
+	// or memcached to share them between the Traefik instances.
+	NonceStore string `json:"nonceStore,omitempty"`
+	// MaxBodySize defines the maximum size in bytes of the signed request bodies.
+	// Requests with a larger body are refused. Default: 1048576 (1 MiB).
+	MaxBodySize *int64 `json:"maxBodySize,omitempty"`
+	// KeyIDHeader defines the header set on the forwarded requests with the key ID of the secret verifying the signature.
+	KeyIDHeader string `json:"keyIDHeader,omitempty"`
+}
+
+// +k8s:deepcopy-gen=true
+
+// JWTAuth holds the JWT authentication middleware configuration.
+// This middleware verifies the JSON Web Token (JWT) bearer token of the requests.
+// More info: https://doc.traefik.io/traefik/v2.8/middlewares/http/jwtauth/
+type JWTAuth struct {
+	// Keys defines the keys verifying the token signatures, as PEM encoded public keys or certificates (RSA, ECDSA or Ed25519), or as JSON Web Key Sets.
+	Keys []string `json:"keys,omitempty"`
+	// Secret is the name of the referenced Kubernetes Secret containing the shared secret verifying the tokens signed with an HMAC algorithm.
+	// The shared secret is extracted from the key `secret`.
+	Secret string `json:"secret,omitempty"`
+	// JWKSURL defines the URL of the JSON Web Key Set verifying the token signatures.
+	JWKSURL string `json:"jwksURL,omitempty"`
+	// JWKSRefreshInterval defines the interval between two fetches of the JSON Web Key Set.
+	// Default: 15m.
+	JWKSRefreshInterval *intstr.IntOrString `json:"jwksRefreshInterval,omitempty"`
+	// Algorithms defines the allowed signature algorithms.
+	Algorithms []string `json:"algorithms,omitempty"`
+	// Issuers defines the allowed issuers (iss claim) of the tokens.
+	Issuers []string `json:"issuers,omitempty"`
+	// Audiences defines the allowed audiences (aud claim) of the tokens.
+	Audiences []string `json:"audiences,omitempty"`
+	// ClockSkew defines the tolerated clock skew when checking the exp, nbf and iat claims.
+	ClockSkew *intstr.IntOrString `json:"clockSkew,omitempty"`
+	// RequireExp defines whether the tokens must have an expiration time (exp claim).
+	// Default: true.
+	RequireExp *bool `json:"requireExp,omitempty"`
+	// RequiredClaims defines the claims the tokens must have.
+	RequiredClaims map[string]string `json:"requiredClaims,omitempty"`
+	// RequiredScopes defines the scopes the tokens must have, in their scope or scp claim.
+	RequiredScopes []string `json:"requiredScopes,omitempty"`
+	// ClaimsHeaders defines the headers set on the forwarded requests from the token claims.
+	ClaimsHeaders map[string]string `json:"claimsHeaders,omitempty"`
+	// RemoveHeader defines whether to remove the authorization header before forwarding the request to the backend.
+	RemoveHeader bool `json:"removeHeader,omitempty"`
+}
+
+// +k8s:deepcopy-gen=true
+
+// OIDCAuth holds the OpenID Connect authentication middleware configuration.
+// This middleware authenticates the users with an OpenID Connect provider, and keeps their session in cookies.
+// More info: https://doc.traefik.io/traefik/v2.8/middlewares/http/oidcauth/
+type OIDCAuth struct {
+	// IssuerURL defines the URL of the OpenID Connect provider, from which its configuration is discovered.
+	IssuerURL string `json:"issuerURL,omitempty"`
+	// ClientID defines the client identifier registered with the provider.
+	ClientID string `json:"clientID,omitempty"`
+	// Secret is the name of the referenced Kubernetes Secret containing the secrets of the middleware.
+	// The secret of the session cookies is extracted from the key `sessionSecret`,
+	// and the optional client secret is extracted from the key `clientSecret`.
+	Secret string `json:"secret,omitempty"`
+	// Scopes defines the scopes requested to the provider.
+	// Default: openid, profile, email.
+	Scopes []string `json:"scopes,omitempty"`
+	// CallbackPath defines the path of the redirection URI handling the authorization responses of the provider.
+	// Default: /oauth2/callback.
+	CallbackPath string `json:"callbackPath,omitempty"`
+	// LogoutPath defines the path ending the session of the users.
+	// Default: /oauth2/logout.
+	LogoutPath string `json:"logoutPath,omitempty"`
+	// PostLogoutRedirectURL defines the URL the users are redirected to after the logout.
+	PostLogoutRedirectURL string `json:"postLogoutRedirectURL,omitempty"`
+	// SessionMaxAge defines the maximum lifetime of the sessions.
+	// Default: 24h.
+	SessionMaxAge *intstr.IntOrString `json:"sessionMaxAge,omitempty"`
+	// CookieName defines the name of the session cookie.
+	// Default: _traefik_oidc.
+	CookieName string `json:"cookieName,omitempty"`
+	// CookieDomain defines the domain of the session cookie.
+	CookieDomain string `json:"cookieDomain,omitempty"`
+	// CookiePath defines the path of the session cookie.
+	// Default: /.
+	CookiePath string `json:"cookiePath,omitempty"`
+	// CookieSameSite defines the SameSite attribute of the session cookie (none, lax or strict).
+	// Default: lax.
+	CookieSameSite string `json:"cookieSameSite,omitempty"`
+	// GroupsClaim defines the claim holding the groups of the users.
+	// Default: groups.
+	GroupsClaim string `json:"groupsClaim,omitempty"`
+	// AllowedGroups defines the groups allowed to access the service.
+	AllowedGroups []string `json:"allowedGroups,omitempty"`
+	// RequiredClaims defines the claims the ID tokens of the users must have.
+	RequiredClaims map[string]string `json:"requiredClaims,omitempty"`
+	// ClaimsHeaders defines the headers set on the forwarded requests from the ID token claims.
+	ClaimsHeaders map[string]string `json:"claimsHeaders,omitempty"`
+	// ForwardAccessToken defines whether to forward the access token in the Authorization header of the requests.
+	ForwardAccessToken bool `json:"forwardAccessToken,omitempty"`
 }
 
 // ClientTLS holds the client TLS configuration.
@@ -209,6 +412,94 @@ type Retry struct {
 	InitialInterval intstr.IntOrString `json:"initialInterval,omitempty"`
 }
 
+// +k8s:deepcopy-gen=true
+
+// WAF holds the web application firewall middleware configuration.
+// This middleware inspects the requests with a set of rules written in a subset of the ModSecurity SecRule language.
+// More info: https://doc.traefik.io/traefik/v2.8/middlewares/http/waf/
+type WAF struct {
+	// Rules defines the rules, using the SecRule syntax.
+	Rules []string `json:"rules,omitempty"`
+	// Mode defines whether the requests reaching the anomaly threshold, or matching a deny rule, are refused (block),
+	// or only recorded in the access logs and the metrics (detect).
+	// Default: block.
+	Mode string `json:"mode,omitempty"`
+	// AnomalyThreshold defines the anomaly score from which a request is blocked.
+	// Default: 5.
+	AnomalyThreshold int `json:"anomalyThreshold,omitempty"`
+	// MaxBodySize defines the maximum size in bytes of the request body inspected by the rules.
+	// The rest of the body is forwarded without being inspected. Default: 131072 (128 KiB).
+	MaxBodySize *int64 `json:"maxBodySize,omitempty"`
+	// Exclusions defines the rules, or the rule targets, disabled for some routers.
+	Exclusions []dynamic.WAFExclusion `json:"exclusions,omitempty"`
+}
+
+// +k8s:deepcopy-gen=true
+
+// RequestValidation holds the request validation middleware configuration.
+// This middleware validates the JSON request bodies against a JSON Schema, or against the operations of an OpenAPI 3 document.
+// More info: https://doc.traefik.io/traefik/v2.8/middlewares/http/requestvalidation/
+type RequestValidation struct {
+	// Schema defines the JSON Schema of the request bodies, in JSON or YAML.
+	Schema string `json:"schema,omitempty"`
+	// OpenAPI defines an OpenAPI 3 document, in JSON or YAML.
+	// The request bodies are validated against the schema of the operation matching their method and path.
+	OpenAPI string `json:"openAPI,omitempty"`
+	// MaxBodySize defines the maximum size in bytes of the validated request bodies.
+	// The requests with a larger body are refused with a 413 (Request Entity Too Large) response.
+	// Default: 1048576 (1 MiB).
+	MaxBodySize *int64 `json:"maxBodySize,omitempty"`
+}
+
+// +k8s:deepcopy-gen=true
+
+// Challenge holds the challenge middleware configuration.
+// This middleware serves a proof-of-work or JavaScript challenge page to the matching requests without a clearance cookie,
+// and grants a signed clearance cookie to the clients solving it.
+// More info: https://doc.traefik.io/traefik/v2.8/middlewares/http/challenge/
+type Challenge struct {
+	// Secret is the name of the referenced Kubernetes Secret containing the secret signing the challenges and the clearance cookies,
+	// extracted from the key `secret`.
+	Secret string `json:"secret,omitempty"`
+	// Mode defines the challenge: proofOfWork (the client computes a proof-of-work in JavaScript),
+	// or javascript (the client only has to run JavaScript).
+	// Default: proofOfWork.
+	Mode string `json:"mode,omitempty"`
+	// Difficulty defines the number of leading zero bits of the proof-of-work hash.
+	// Default: 16.
+	Difficulty *int `json:"difficulty,omitempty"`
+	// UserAgents defines the regular expressions matching the suspicious user agents, which are challenged.
+	UserAgents []string `json:"userAgents,omitempty"`
+	// RequestRate defines the request rate of a client IP above which its requests are challenged.
+	RequestRate *dynamic.ChallengeRequestRate `json:"requestRate,omitempty"`
+	// IPStrategy holds the IP strategy configuration used by Traefik to determine the client IP.
+	// More info: https://doc.traefik.io/traefik/v2.8/middlewares/http/ipwhitelist/#ipstrategy
+	IPStrategy *dynamic.IPStrategy `json:"ipStrategy,omitempty"`
+	// BindTo defines what the clearance cookies are bound to: ip, userAgent, or both.
+	// Default: ip.
+	BindTo []string `json:"bindTo,omitempty"`
+	// VerifyPath defines the path receiving the solutions of the challenges.
+	// Default: /_traefik/challenge.
+	VerifyPath string `json:"verifyPath,omitempty"`
+	// ChallengeTTL defines how long a challenge can be solved.
+	// Default: 5m.
+	ChallengeTTL *intstr.IntOrString `json:"challengeTTL,omitempty"`
+	// ClearanceTTL defines how long a clearance cookie is valid.
+	// Default: 1h.
+	ClearanceTTL *intstr.IntOrString `json:"clearanceTTL,omitempty"`
+	// CookieName defines the name of the clearance cookie.
+	// Default: _traefik_clearance.
+	CookieName string `json:"cookieName,omitempty"`
+	// CookieDomain defines the domain of the clearance cookie.
+	CookieDomain string `json:"cookieDomain,omitempty"`
+	// CookiePath defines the path of the clearance cookie.
+	// Default: /.
+	CookiePath string `json:"cookiePath,omitempty"`
+	// CookieSameSite defines the SameSite attribute of the clearance cookie (none, lax or strict).
+	// Default: lax.
+	CookieSameSite string `json:"cookieSameSite,omitempty"`
+}
+
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
 
 // MiddlewareList is a collection of Middleware resources.
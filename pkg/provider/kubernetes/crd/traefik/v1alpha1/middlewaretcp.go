@@ -27,6 +27,16 @@ type MiddlewareTCPSpec struct {
 	InFlightConn *dynamic.TCPInFlightConn `json:"inFlightConn,omitempty"`
 	// IPWhiteList defines the IPWhiteList middleware configuration.
 	IPWhiteList *dynamic.TCPIPWhiteList `json:"ipWhiteList,omitempty"`
+	// IPDenyList defines the IPDenyList middleware configuration.
+	IPDenyList *dynamic.TCPIPDenyList `json:"ipDenyList,omitempty"`
+	// RateLimit defines the RateLimit middleware configuration.
+	RateLimit *dynamic.TCPRateLimit `json:"rateLimit,omitempty"`
+	// MaxConn defines the MaxConn middleware configuration.
+	MaxConn *dynamic.TCPMaxConn `json:"maxConn,omitempty"`
+	// ConnTimeout defines the ConnTimeout middleware configuration.
+	ConnTimeout *dynamic.TCPConnTimeout `json:"connTimeout,omitempty"`
+	// AccessLog defines the AccessLog middleware configuration.
+	AccessLog *dynamic.TCPAccessLog `json:"accessLog,omitempty"`
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
@@ -48,6 +48,9 @@ type TLSOptionSpec struct {
 	// ALPNProtocols defines the list of supported application level protocols for the TLS handshake, in order of preference.
 	// More info: https://doc.traefik.io/traefik/v2.8/https/tls/#alpn-protocols
 	ALPNProtocols []string `json:"alpnProtocols,omitempty"`
+	// DisableSessionTickets defines whether the TLS session resumption with session tickets is disabled.
+	// More info: https://doc.traefik.io/traefik/v2.8/https/tls/#session-tickets
+	DisableSessionTickets bool `json:"disableSessionTickets,omitempty"`
 }
 
 // +k8s:deepcopy-gen=true
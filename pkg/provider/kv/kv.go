@@ -179,7 +179,12 @@ func (p *Provider) createKVClient(ctx context.Context) (store.Store, error) {
 		}
 	}
 
-	switch p.storeType {
+	return NewClient(ctx, p.storeType, p.Endpoints, storeConfig)
+}
+
+// NewClient creates a client for the given KV store backend.
+func NewClient(ctx context.Context, storeType store.Backend, endpoints []string, storeConfig *store.Config) (store.Store, error) {
+	switch storeType {
 	case store.CONSUL:
 		consul.Register()
 	case store.ETCDV3:
@@ -190,7 +195,7 @@ func (p *Provider) createKVClient(ctx context.Context) (store.Store, error) {
 		redis.Register()
 	}
 
-	kvStore, err := valkeyrie.NewStore(ctx, p.storeType, p.Endpoints, storeConfig)
+	kvStore, err := valkeyrie.NewStore(ctx, storeType, endpoints, storeConfig)
 	if err != nil {
 		return nil, err
 	}
@@ -12,7 +12,7 @@ type storeWrapper struct {
 }
 
 func (s *storeWrapper) Put(ctx context.Context, key string, value []byte, options *store.WriteOptions) error {
-	log.WithoutContext().Debugf("Put: %s", key, string(value))
+	log.WithoutContext().Debugf("Put: %s", key)
 
 	if s.Store == nil {
 		return nil
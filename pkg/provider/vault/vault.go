@@ -0,0 +1,515 @@
+package vault
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cenkalti/backoff/v4"
+	"github.com/sirupsen/logrus"
+	ptypes "github.com/traefik/paerser/types"
+	"github.com/traefik/traefik/v2/pkg/config/dynamic"
+	"github.com/traefik/traefik/v2/pkg/job"
+	"github.com/traefik/traefik/v2/pkg/log"
+	"github.com/traefik/traefik/v2/pkg/metrics"
+	httpmuxer "github.com/traefik/traefik/v2/pkg/muxer/http"
+	tcpmuxer "github.com/traefik/traefik/v2/pkg/muxer/tcp"
+	"github.com/traefik/traefik/v2/pkg/safe"
+	traefiktls "github.com/traefik/traefik/v2/pkg/tls"
+	"github.com/traefik/traefik/v2/pkg/types"
+)
+
+// Configuration holds the configuration of a certificate resolver issuing the certificates with a HashiCorp Vault PKI secrets engine.
+type Configuration struct {
+	URL         string           `description:"Address of the Vault server. Defaults to the VAULT_ADDR environment variable." json:"url,omitempty" toml:"url,omitempty" yaml:"url,omitempty"`
+	Token       string           `description:"Token used to authenticate to Vault. Defaults to the VAULT_TOKEN environment variable." json:"token,omitempty" toml:"token,omitempty" yaml:"token,omitempty" loggable:"false"`
+	Namespace   string           `description:"Vault Enterprise namespace of the PKI secrets engine." json:"namespace,omitempty" toml:"namespace,omitempty" yaml:"namespace,omitempty" export:"true"`
+	Mount       string           `description:"Mount path of the PKI secrets engine." json:"mount,omitempty" toml:"mount,omitempty" yaml:"mount,omitempty" export:"true"`
+	Role        string           `description:"Role used to issue the certificates." json:"role,omitempty" toml:"role,omitempty" yaml:"role,omitempty" export:"true"`
+	TTL         ptypes.Duration  `description:"Requested duration of the certificates." json:"ttl,omitempty" toml:"ttl,omitempty" yaml:"ttl,omitempty" export:"true"`
+	RenewBefore ptypes.Duration  `description:"Period before the expiration of the certificates during which they are renewed." json:"renewBefore,omitempty" toml:"renewBefore,omitempty" yaml:"renewBefore,omitempty" export:"true"`
+	TLS         *types.ClientTLS `description:"Enable TLS support to connect to Vault." json:"tls,omitempty" toml:"tls,omitempty" yaml:"tls,omitempty" export:"true"`
+}
+
+// SetDefaults sets the default values.
+func (c *Configuration) SetDefaults() {
+	c.Mount = "pki"
+	c.TTL = ptypes.Duration(24 * time.Hour)
+	c.RenewBefore = ptypes.Duration(8 * time.Hour)
+}
+
+// certificate is a certificate issued by Vault.
+type certificate struct {
+	Domain      types.Domain
+	Certificate []byte
+	Key         []byte
+	NotAfter    time.Time
+}
+
+// Provider issues certificates with a HashiCorp Vault PKI secrets engine,
+// for the domains of the routers using its certificate resolver, and renews them before their expiration.
+type Provider struct {
+	*Configuration
+	ResolverName string
+
+	client                 *http.Client
+	configurationChan      chan<- dynamic.Message
+	configFromListenerChan chan dynamic.Configuration
+	metricsRegistry        metrics.Registry
+
+	// syncMu serializes the issuances and the renewals.
+	syncMu         sync.Mutex
+	certificatesMu sync.RWMutex
+	// domains are the domains of the last configuration received from the listener.
+	domains      map[string]types.Domain
+	certificates map[string]*certificate
+}
+
+// SetMetricsRegistry sets the metrics registry recording the certificate renewals.
+func (p *Provider) SetMetricsRegistry(metricsRegistry metrics.Registry) {
+	p.metricsRegistry = metricsRegistry
+}
+
+// SetConfigListenerChan initializes the configFromListenerChan.
+func (p *Provider) SetConfigListenerChan(configFromListenerChan chan dynamic.Configuration) {
+	p.configFromListenerChan = configFromListenerChan
+}
+
+// ListenConfiguration sets a new Configuration into the configFromListenerChan.
+func (p *Provider) ListenConfiguration(config dynamic.Configuration) {
+	p.configFromListenerChan <- config
+}
+
+// Init validates the configuration and creates the Vault client.
+func (p *Provider) Init() error {
+	if p.URL == "" {
+		p.URL = os.Getenv("VAULT_ADDR")
+	}
+	if p.Token == "" {
+		p.Token = os.Getenv("VAULT_TOKEN")
+	}
+
+	if p.URL == "" {
+		return errors.New("the Vault server address is required")
+	}
+
+	if p.Role == "" {
+		return errors.New("the role issuing the certificates is required")
+	}
+
+	if p.TTL <= 0 || p.RenewBefore >= p.TTL {
+		return fmt.Errorf("the renewal period (%s) must be shorter than the certificates duration (%s)", time.Duration(p.RenewBefore), time.Duration(p.TTL))
+	}
+
+	ctx := log.With(context.Background(), log.Str(log.ProviderName, p.ResolverName+".vault"))
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if p.TLS != nil {
+		tlsConfig, err := p.TLS.CreateTLSConfig(ctx)
+		if err != nil {
+			return fmt.Errorf("creating TLS configuration: %w", err)
+		}
+		transport.TLSClientConfig = tlsConfig
+	}
+
+	p.client = &http.Client{Transport: transport, Timeout: 30 * time.Second}
+	p.certificates = make(map[string]*certificate)
+
+	return nil
+}
+
+// Provide allows the provider to provide configurations to traefik
+// using the given configuration channel.
+func (p *Provider) Provide(configurationChan chan<- dynamic.Message, pool *safe.Pool) error {
+	ctx := log.With(context.Background(), log.Str(log.ProviderName, p.ResolverName+".vault"))
+
+	p.configurationChan = configurationChan
+
+	pool.GoCtx(func(ctxPool context.Context) {
+		cancelSync := func() {}
+		defer func() { cancelSync() }()
+
+		for {
+			select {
+			case config := <-p.configFromListenerChan:
+				domains := p.getRoutersDomains(ctx, config)
+
+				p.certificatesMu.Lock()
+				p.domains = domains
+				p.certificatesMu.Unlock()
+
+				// The synchronization of the previous domains is not retried anymore.
+				cancelSync()
+
+				ctxSync, cancel := context.WithCancel(ctxPool)
+				cancelSync = cancel
+				ctxSync = log.With(ctxSync, log.Str(log.ProviderName, p.ResolverName+".vault"))
+
+				safe.Go(func() { p.syncCertificatesWithRetry(ctxSync) })
+
+			case <-ctxPool.Done():
+				return
+			}
+		}
+	})
+
+	pool.GoCtx(func(ctxPool context.Context) {
+		ticker := time.NewTicker(renewCheckInterval(time.Duration(p.RenewBefore)))
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				p.renewCertificates(ctx)
+
+			case <-ctxPool.Done():
+				return
+			}
+		}
+	})
+
+	return nil
+}
+
+// renewCheckInterval returns the interval between two checks of the certificates expiration,
+// for the certificates to be renewed early enough in their renewal period.
+func renewCheckInterval(renewBefore time.Duration) time.Duration {
+	interval := renewBefore / 4
+	if interval > time.Hour {
+		return time.Hour
+	}
+	if interval < time.Minute {
+		return time.Minute
+	}
+	return interval
+}
+
+// getRoutersDomains returns the domains of the HTTP and TCP routers using the certificate resolver.
+func (p *Provider) getRoutersDomains(ctx context.Context, config dynamic.Configuration) map[string]types.Domain {
+	domains := make(map[string]types.Domain)
+
+	addDomains := func(ctxRouter context.Context, tlsDomains []types.Domain, ruleDomains []string, err error) {
+		if len(tlsDomains) > 0 {
+			for _, domain := range tlsDomains {
+				domains[domainKey(domain)] = domain
+			}
+			return
+		}
+
+		if err != nil {
+			log.FromContext(ctxRouter).Errorf("Error parsing domains in provider Vault: %v", err)
+			return
+		}
+
+		var names []string
+		for _, name := range ruleDomains {
+			if name != "*" {
+				names = append(names, name)
+			}
+		}
+
+		if len(names) == 0 {
+			log.FromContext(ctxRouter).Debug("No domain parsed in provider Vault")
+			return
+		}
+
+		domain := types.Domain{Main: names[0], SANs: names[1:]}
+		domains[domainKey(domain)] = domain
+	}
+
+	if config.HTTP != nil {
+		for routerName, route := range config.HTTP.Routers {
+			if route.TLS == nil || route.TLS.CertResolver != p.ResolverName {
+				continue
+			}
+
+			ctxRouter := log.With(ctx, log.Str(log.RouterName, routerName), log.Str(log.Rule, route.Rule))
+			ruleDomains, err := httpmuxer.ParseDomains(route.Rule)
+			addDomains(ctxRouter, route.TLS.Domains, ruleDomains, err)
+		}
+	}
+
+	if config.TCP != nil {
+		for routerName, route := range config.TCP.Routers {
+			if route.TLS == nil || route.TLS.CertResolver != p.ResolverName {
+				continue
+			}
+
+			ctxRouter := log.With(ctx, log.Str(log.RouterName, routerName), log.Str(log.Rule, route.Rule))
+			ruleDomains, err := tcpmuxer.ParseHostSNI(route.Rule)
+			addDomains(ctxRouter, route.TLS.Domains, ruleDomains, err)
+		}
+	}
+
+	return domains
+}
+
+// syncCertificatesWithRetry synchronizes the certificates, and retries until all of them are issued or the context is canceled.
+func (p *Provider) syncCertificatesWithRetry(ctx context.Context) {
+	logger := log.FromContext(ctx)
+
+	operation := func() error {
+		return p.syncCertificates(ctx)
+	}
+
+	notify := func(err error, time time.Duration) {
+		logger.Errorf("Unable to synchronize Vault certificates: %v, retrying in %s", err, time)
+	}
+
+	err := backoff.RetryNotify(safe.OperationWithRecover(operation), backoff.WithContext(job.NewBackOff(backoff.NewExponentialBackOff()), ctx), notify)
+	if err != nil && ctx.Err() == nil {
+		logger.Errorf("Unable to synchronize Vault certificates: %v", err)
+	}
+}
+
+// syncCertificates issues the certificates of the new domains, and forgets the certificates of the domains which are not used anymore.
+// It returns an error if any certificate cannot be issued, after refreshing the certificates which have been issued.
+func (p *Provider) syncCertificates(ctx context.Context) error {
+	p.syncMu.Lock()
+	defer p.syncMu.Unlock()
+
+	p.certificatesMu.RLock()
+	domains := p.domains
+	var changed bool
+	for key := range p.certificates {
+		if _, ok := domains[key]; !ok {
+			changed = true
+		}
+	}
+	p.certificatesMu.RUnlock()
+
+	var failed []string
+	certificates := make(map[string]*certificate)
+	for key, domain := range domains {
+		p.certificatesMu.RLock()
+		cert, ok := p.certificates[key]
+		p.certificatesMu.RUnlock()
+
+		if !ok {
+			var err error
+			cert, err = p.issueCertificate(ctx, domain)
+			if err != nil {
+				log.FromContext(ctx).Errorf("Unable to obtain Vault certificate for domains %q: %v", strings.Join(domain.ToStrArray(), ","), err)
+				failed = append(failed, key)
+				continue
+			}
+
+			changed = true
+		}
+
+		certificates[key] = cert
+	}
+
+	if changed {
+		p.certificatesMu.Lock()
+		p.certificates = certificates
+		p.certificatesMu.Unlock()
+
+		p.refreshCertificates()
+	}
+
+	if len(failed) > 0 {
+		sort.Strings(failed)
+		return fmt.Errorf("unable to obtain certificates for domains %q", failed)
+	}
+
+	return nil
+}
+
+// renewCertificates renews the certificates entering their renewal period.
+func (p *Provider) renewCertificates(ctx context.Context) {
+	p.syncMu.Lock()
+	defer p.syncMu.Unlock()
+
+	p.certificatesMu.RLock()
+	var toRenew []*certificate
+	for _, cert := range p.certificates {
+		if time.Now().Add(time.Duration(p.RenewBefore)).After(cert.NotAfter) {
+			toRenew = append(toRenew, cert)
+		}
+	}
+	p.certificatesMu.RUnlock()
+
+	if len(toRenew) == 0 {
+		return
+	}
+
+	var renewed bool
+	for _, cert := range toRenew {
+		newCert, err := p.issueCertificate(ctx, cert.Domain)
+		p.recordRenewal(ctx, cert.Domain, newCert, err)
+		if err != nil {
+			continue
+		}
+
+		p.certificatesMu.Lock()
+		p.certificates[domainKey(cert.Domain)] = newCert
+		p.certificatesMu.Unlock()
+
+		renewed = true
+	}
+
+	if renewed {
+		p.refreshCertificates()
+	}
+}
+
+// recordRenewal logs the outcome of a certificate renewal as a structured event, and records it in the metrics.
+func (p *Provider) recordRenewal(ctx context.Context, domain types.Domain, renewed *certificate, err error) {
+	logger := log.FromContext(ctx).WithFields(logrus.Fields{
+		"event":   "certificateRenewal",
+		"domains": strings.Join(domain.ToStrArray(), ","),
+	})
+
+	labels := []string{"resolver", p.ResolverName}
+
+	if err != nil {
+		if p.metricsRegistry != nil {
+			p.metricsRegistry.TLSCertsRenewalsFailureCounter().With(labels...).Add(1)
+		}
+
+		logger.WithField("status", "failure").Errorf("Unable to renew certificate: %v", err)
+		return
+	}
+
+	if p.metricsRegistry != nil {
+		p.metricsRegistry.TLSCertsRenewalsCounter().With(labels...).Add(1)
+	}
+
+	logger.WithFields(logrus.Fields{
+		"status":   "success",
+		"notAfter": renewed.NotAfter.UTC().Format(time.RFC3339),
+	}).Info("Certificate renewed")
+}
+
+type issueResponse struct {
+	Errors []string `json:"errors"`
+	Data   struct {
+		Certificate string   `json:"certificate"`
+		IssuingCA   string   `json:"issuing_ca"`
+		CAChain     []string `json:"ca_chain"`
+		PrivateKey  string   `json:"private_key"`
+		Expiration  int64    `json:"expiration"`
+	} `json:"data"`
+}
+
+// issueCertificate issues a certificate for the given domain with the issue endpoint of the PKI secrets engine.
+func (p *Provider) issueCertificate(ctx context.Context, domain types.Domain) (*certificate, error) {
+	var altNames, ipSANs []string
+	for _, san := range domain.SANs {
+		if net.ParseIP(san) != nil {
+			ipSANs = append(ipSANs, san)
+			continue
+		}
+		altNames = append(altNames, san)
+	}
+
+	body, err := json.Marshal(map[string]string{
+		"common_name": domain.Main,
+		"alt_names":   strings.Join(altNames, ","),
+		"ip_sans":     strings.Join(ipSANs, ","),
+		"ttl":         time.Duration(p.TTL).String(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	issueURL := fmt.Sprintf("%s/v1/%s/issue/%s", strings.TrimSuffix(p.URL, "/"), strings.Trim(p.Mount, "/"), p.Role)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, issueURL, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Vault-Token", p.Token)
+	if p.Namespace != "" {
+		req.Header.Set("X-Vault-Namespace", p.Namespace)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	var issued issueResponse
+	if err = json.Unmarshal(data, &issued); err != nil && resp.StatusCode == http.StatusOK {
+		return nil, fmt.Errorf("decoding response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code %d: %s", resp.StatusCode, strings.Join(issued.Errors, ", "))
+	}
+
+	if issued.Data.Certificate == "" || issued.Data.PrivateKey == "" {
+		return nil, errors.New("no certificate or private key in the response")
+	}
+
+	chain := []string{issued.Data.Certificate}
+	if len(issued.Data.CAChain) > 0 {
+		chain = append(chain, issued.Data.CAChain...)
+	} else if issued.Data.IssuingCA != "" {
+		chain = append(chain, issued.Data.IssuingCA)
+	}
+
+	return &certificate{
+		Domain:      domain,
+		Certificate: []byte(strings.Join(chain, "\n")),
+		Key:         []byte(issued.Data.PrivateKey),
+		NotAfter:    time.Unix(issued.Data.Expiration, 0),
+	}, nil
+}
+
+func (p *Provider) refreshCertificates() {
+	conf := dynamic.Message{
+		ProviderName: p.ResolverName + ".vault",
+		Configuration: &dynamic.Configuration{
+			HTTP: &dynamic.HTTPConfiguration{
+				Routers:     map[string]*dynamic.Router{},
+				Middlewares: map[string]*dynamic.Middleware{},
+				Services:    map[string]*dynamic.Service{},
+			},
+			TLS: &dynamic.TLSConfiguration{},
+		},
+	}
+
+	p.certificatesMu.RLock()
+	keys := make([]string, 0, len(p.certificates))
+	for key := range p.certificates {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		cert := p.certificates[key]
+		conf.Configuration.TLS.Certificates = append(conf.Configuration.TLS.Certificates, &traefiktls.CertAndStores{
+			Certificate: traefiktls.Certificate{
+				CertFile: traefiktls.FileOrContent(cert.Certificate),
+				KeyFile:  traefiktls.FileOrContent(cert.Key),
+			},
+			Stores: []string{traefiktls.DefaultTLSStoreName},
+		})
+	}
+	p.certificatesMu.RUnlock()
+
+	p.configurationChan <- conf
+}
+
+func domainKey(domain types.Domain) string {
+	return strings.Join(domain.ToStrArray(), ",")
+}
@@ -0,0 +1,409 @@
+package vault
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	ptypes "github.com/traefik/paerser/types"
+	"github.com/traefik/traefik/v2/pkg/config/dynamic"
+	"github.com/traefik/traefik/v2/pkg/types"
+)
+
+const testToken = "root"
+
+// fakeVault is a stand-in for a Vault server in dev mode, with a PKI secrets engine mounted on "pki" and a "web" role.
+type fakeVault struct {
+	*httptest.Server
+
+	ca      *x509.Certificate
+	caKey   crypto.Signer
+	issued  int32
+	serials int64
+	// failures is the number of issue requests failing before the certificates are issued.
+	failures int32
+}
+
+func newFakeVault(t *testing.T) *fakeVault {
+	t.Helper()
+
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "Vault Test CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(48 * time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, caKey.Public(), caKey)
+	require.NoError(t, err)
+
+	ca, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	vault := &fakeVault{ca: ca, caKey: caKey, serials: 1}
+	vault.Server = httptest.NewServer(http.HandlerFunc(vault.serveHTTP))
+	t.Cleanup(vault.Close)
+
+	return vault
+}
+
+func (f *fakeVault) serveHTTP(rw http.ResponseWriter, req *http.Request) {
+	writeErrors := func(status int, errs ...string) {
+		rw.WriteHeader(status)
+		_ = json.NewEncoder(rw).Encode(map[string][]string{"errors": errs})
+	}
+
+	if req.Header.Get("X-Vault-Token") != testToken {
+		writeErrors(http.StatusForbidden, "permission denied")
+		return
+	}
+
+	if req.Method != http.MethodPost || req.URL.Path != "/v1/pki/issue/web" {
+		writeErrors(http.StatusNotFound, "unknown role")
+		return
+	}
+
+	if atomic.AddInt32(&f.failures, -1) >= 0 {
+		writeErrors(http.StatusServiceUnavailable, "Vault is sealed")
+		return
+	}
+
+	var body map[string]string
+	if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+		writeErrors(http.StatusBadRequest, err.Error())
+		return
+	}
+
+	ttl, err := time.ParseDuration(body["ttl"])
+	if err != nil {
+		writeErrors(http.StatusBadRequest, err.Error())
+		return
+	}
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		writeErrors(http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(atomic.AddInt64(&f.serials, 1)),
+		Subject:      pkix.Name{CommonName: body["common_name"]},
+		DNSNames:     []string{body["common_name"]},
+		NotBefore:    time.Now().Add(-time.Minute),
+		NotAfter:     time.Now().Add(ttl),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	if body["alt_names"] != "" {
+		template.DNSNames = append(template.DNSNames, strings.Split(body["alt_names"], ",")...)
+	}
+	if body["ip_sans"] != "" {
+		for _, ip := range strings.Split(body["ip_sans"], ",") {
+			template.IPAddresses = append(template.IPAddresses, net.ParseIP(ip))
+		}
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, f.ca, key.Public(), f.caKey)
+	if err != nil {
+		writeErrors(http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		writeErrors(http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	atomic.AddInt32(&f.issued, 1)
+
+	caPEM := string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: f.ca.Raw}))
+	_ = json.NewEncoder(rw).Encode(map[string]interface{}{
+		"data": map[string]interface{}{
+			"certificate": string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})),
+			"issuing_ca":  caPEM,
+			"ca_chain":    []string{caPEM},
+			"private_key": string(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})),
+			"expiration":  template.NotAfter.Unix(),
+		},
+	})
+}
+
+func newTestProvider(t *testing.T, url, token, role string) *Provider {
+	t.Helper()
+
+	config := &Configuration{}
+	config.SetDefaults()
+	config.URL = url
+	config.Token = token
+	config.Role = role
+
+	p := &Provider{Configuration: config, ResolverName: "vault"}
+	require.NoError(t, p.Init())
+
+	return p
+}
+
+func TestProvider_Init(t *testing.T) {
+	testCases := []struct {
+		desc        string
+		config      Configuration
+		expectedErr bool
+	}{
+		{
+			desc:   "valid configuration",
+			config: Configuration{URL: "http://127.0.0.1:8200", Role: "web", TTL: ptypes.Duration(24 * time.Hour), RenewBefore: ptypes.Duration(8 * time.Hour)},
+		},
+		{
+			desc:        "missing role",
+			config:      Configuration{URL: "http://127.0.0.1:8200", TTL: ptypes.Duration(24 * time.Hour), RenewBefore: ptypes.Duration(8 * time.Hour)},
+			expectedErr: true,
+		},
+		{
+			desc:        "renewal period longer than the certificates duration",
+			config:      Configuration{URL: "http://127.0.0.1:8200", Role: "web", TTL: ptypes.Duration(time.Hour), RenewBefore: ptypes.Duration(2 * time.Hour)},
+			expectedErr: true,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			p := &Provider{Configuration: &test.config, ResolverName: "vault"}
+
+			err := p.Init()
+			if test.expectedErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestProvider_issueCertificate(t *testing.T) {
+	vault := newFakeVault(t)
+
+	testCases := []struct {
+		desc        string
+		token       string
+		role        string
+		domain      types.Domain
+		expectedErr string
+	}{
+		{
+			desc:   "certificate issued",
+			token:  testToken,
+			role:   "web",
+			domain: types.Domain{Main: "foo.example.com", SANs: []string{"bar.example.com", "10.0.0.1"}},
+		},
+		{
+			desc:        "invalid token",
+			token:       "invalid",
+			role:        "web",
+			domain:      types.Domain{Main: "foo.example.com"},
+			expectedErr: "unexpected status code 403: permission denied",
+		},
+		{
+			desc:        "unknown role",
+			token:       testToken,
+			role:        "unknown",
+			domain:      types.Domain{Main: "foo.example.com"},
+			expectedErr: "unexpected status code 404: unknown role",
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			p := newTestProvider(t, vault.URL, test.token, test.role)
+
+			cert, err := p.issueCertificate(context.Background(), test.domain)
+			if test.expectedErr != "" {
+				require.EqualError(t, err, test.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+
+			block, rest := pem.Decode(cert.Certificate)
+			require.NotNil(t, block)
+
+			leaf, err := x509.ParseCertificate(block.Bytes)
+			require.NoError(t, err)
+
+			assert.Equal(t, []string{"foo.example.com", "bar.example.com"}, leaf.DNSNames)
+			require.Len(t, leaf.IPAddresses, 1)
+			assert.Equal(t, "10.0.0.1", leaf.IPAddresses[0].String())
+			assert.WithinDuration(t, time.Now().Add(24*time.Hour), cert.NotAfter, time.Minute)
+
+			// The chain includes the CA certificate.
+			block, _ = pem.Decode(rest)
+			require.NotNil(t, block)
+			assert.Equal(t, vault.ca.Raw, block.Bytes)
+
+			_, err = tls.X509KeyPair(cert.Certificate, cert.Key)
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestProvider_getRoutersDomains(t *testing.T) {
+	p := &Provider{ResolverName: "vault"}
+
+	config := dynamic.Configuration{
+		HTTP: &dynamic.HTTPConfiguration{
+			Routers: map[string]*dynamic.Router{
+				"rule": {
+					Rule: "Host(`foo.example.com`, `bar.example.com`)",
+					TLS:  &dynamic.RouterTLSConfig{CertResolver: "vault"},
+				},
+				"domains": {
+					Rule: "PathPrefix(`/`)",
+					TLS: &dynamic.RouterTLSConfig{
+						CertResolver: "vault",
+						Domains:      []types.Domain{{Main: "baz.example.com", SANs: []string{"qux.example.com"}}},
+					},
+				},
+				"other-resolver": {
+					Rule: "Host(`other.example.com`)",
+					TLS:  &dynamic.RouterTLSConfig{CertResolver: "acme"},
+				},
+				"no-tls": {
+					Rule: "Host(`notls.example.com`)",
+				},
+			},
+		},
+		TCP: &dynamic.TCPConfiguration{
+			Routers: map[string]*dynamic.TCPRouter{
+				"sni": {
+					Rule: "HostSNI(`tcp.example.com`)",
+					TLS:  &dynamic.RouterTCPTLSConfig{CertResolver: "vault"},
+				},
+				"catch-all": {
+					Rule: "HostSNI(`*`)",
+					TLS:  &dynamic.RouterTCPTLSConfig{CertResolver: "vault"},
+				},
+			},
+		},
+	}
+
+	expected := map[string]types.Domain{
+		"foo.example.com,bar.example.com": {Main: "foo.example.com", SANs: []string{"bar.example.com"}},
+		"baz.example.com,qux.example.com": {Main: "baz.example.com", SANs: []string{"qux.example.com"}},
+		"tcp.example.com":                 {Main: "tcp.example.com", SANs: []string{}},
+	}
+
+	assert.Equal(t, expected, p.getRoutersDomains(context.Background(), config))
+}
+
+func TestProvider_syncAndRenewCertificates(t *testing.T) {
+	vault := newFakeVault(t)
+
+	p := newTestProvider(t, vault.URL, testToken, "web")
+
+	configurationChan := make(chan dynamic.Message, 10)
+	p.configurationChan = configurationChan
+
+	foo := types.Domain{Main: "foo.example.com"}
+	bar := types.Domain{Main: "bar.example.com"}
+
+	p.domains = map[string]types.Domain{domainKey(foo): foo, domainKey(bar): bar}
+	require.NoError(t, p.syncCertificates(context.Background()))
+
+	msg := <-configurationChan
+	assert.Equal(t, "vault.vault", msg.ProviderName)
+	require.Len(t, msg.Configuration.TLS.Certificates, 2)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&vault.issued))
+
+	// The known domains are not issued again.
+	require.NoError(t, p.syncCertificates(context.Background()))
+	assert.Empty(t, configurationChan)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&vault.issued))
+
+	// The certificates are renewed when entering their renewal period.
+	p.renewCertificates(context.Background())
+	assert.Empty(t, configurationChan)
+
+	previous := p.certificates[domainKey(foo)]
+	previous.NotAfter = time.Now().Add(time.Hour)
+
+	p.renewCertificates(context.Background())
+
+	msg = <-configurationChan
+	require.Len(t, msg.Configuration.TLS.Certificates, 2)
+	assert.Equal(t, int32(3), atomic.LoadInt32(&vault.issued))
+	assert.NotSame(t, previous, p.certificates[domainKey(foo)])
+
+	// The certificates of the domains not used anymore are forgotten.
+	p.domains = map[string]types.Domain{domainKey(bar): bar}
+	require.NoError(t, p.syncCertificates(context.Background()))
+
+	msg = <-configurationChan
+	require.Len(t, msg.Configuration.TLS.Certificates, 1)
+	assert.Equal(t, int32(3), atomic.LoadInt32(&vault.issued))
+}
+
+func TestProvider_syncCertificatesWithRetry(t *testing.T) {
+	vault := newFakeVault(t)
+	vault.failures = 1
+
+	p := newTestProvider(t, vault.URL, testToken, "web")
+
+	configurationChan := make(chan dynamic.Message, 10)
+	p.configurationChan = configurationChan
+
+	foo := types.Domain{Main: "foo.example.com"}
+	p.domains = map[string]types.Domain{domainKey(foo): foo}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		p.syncCertificatesWithRetry(ctx)
+	}()
+
+	select {
+	case msg := <-configurationChan:
+		require.Len(t, msg.Configuration.TLS.Certificates, 1)
+	case <-time.After(10 * time.Second):
+		t.Fatal("timeout waiting for the certificate to be issued")
+	}
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("timeout waiting for the synchronization to end")
+	}
+
+	assert.Equal(t, int32(1), atomic.LoadInt32(&vault.issued))
+}
@@ -20,13 +20,15 @@ func mergeConfiguration(configurations dynamic.Configurations, defaultEntryPoint
 			ServersTransports: make(map[string]*dynamic.ServersTransport),
 		},
 		TCP: &dynamic.TCPConfiguration{
-			Routers:     make(map[string]*dynamic.TCPRouter),
-			Services:    make(map[string]*dynamic.TCPService),
-			Middlewares: make(map[string]*dynamic.TCPMiddleware),
+			Routers:           make(map[string]*dynamic.TCPRouter),
+			Services:          make(map[string]*dynamic.TCPService),
+			Middlewares:       make(map[string]*dynamic.TCPMiddleware),
+			ServersTransports: make(map[string]*dynamic.TCPServersTransport),
 		},
 		UDP: &dynamic.UDPConfiguration{
-			Routers:  make(map[string]*dynamic.UDPRouter),
-			Services: make(map[string]*dynamic.UDPService),
+			Routers:     make(map[string]*dynamic.UDPRouter),
+			Services:    make(map[string]*dynamic.UDPService),
+			Middlewares: make(map[string]*dynamic.UDPMiddleware),
 		},
 		TLS: &dynamic.TLSConfiguration{
 			Stores:  make(map[string]tls.Store),
@@ -78,12 +80,18 @@ func mergeConfiguration(configurations dynamic.Configurations, defaultEntryPoint
 			for serviceName, service := range configuration.TCP.Services {
 				conf.TCP.Services[provider.MakeQualifiedName(pvd, serviceName)] = service
 			}
+			for serversTransportName, serversTransport := range configuration.TCP.ServersTransports {
+				conf.TCP.ServersTransports[provider.MakeQualifiedName(pvd, serversTransportName)] = serversTransport
+			}
 		}
 
 		if configuration.UDP != nil {
 			for routerName, router := range configuration.UDP.Routers {
 				conf.UDP.Routers[provider.MakeQualifiedName(pvd, routerName)] = router
 			}
+			for middlewareName, middleware := range configuration.UDP.Middlewares {
+				conf.UDP.Middlewares[provider.MakeQualifiedName(pvd, middlewareName)] = middleware
+			}
 			for serviceName, service := range configuration.UDP.Services {
 				conf.UDP.Services[provider.MakeQualifiedName(pvd, serviceName)] = service
 			}
@@ -95,7 +103,10 @@ func mergeConfiguration(configurations dynamic.Configurations, defaultEntryPoint
 					continue
 				}
 
-				conf.TLS.Certificates = append(conf.TLS.Certificates, cert)
+				// The certificate is copied, as the configuration of the provider is not owned by the aggregation.
+				certAndStores := *cert
+				certAndStores.Provider = pvd
+				conf.TLS.Certificates = append(conf.TLS.Certificates, &certAndStores)
 			}
 
 			for key, store := range configuration.TLS.Stores {
@@ -157,6 +157,7 @@ func Test_mergeConfiguration_tlsCertificates(t *testing.T) {
 			expected: []*tls.CertAndStores{{
 				Certificate: tls.Certificate{CertFile: "foo", KeyFile: "bar"},
 				Stores:      []string{tlsalpn01.ACMETLS1Protocol},
+				Provider:    "tlsalpn.acme",
 			}},
 		},
 	}
@@ -168,6 +169,13 @@ func Test_mergeConfiguration_tlsCertificates(t *testing.T) {
 
 			actual := mergeConfiguration(test.given, []string{"defaultEP"})
 			assert.Equal(t, test.expected, actual.TLS.Certificates)
+
+			// The configurations of the providers are not modified.
+			for _, configuration := range test.given {
+				for _, cert := range configuration.TLS.Certificates {
+					assert.Empty(t, cert.Provider)
+				}
+			}
 		})
 	}
 }
@@ -210,6 +210,15 @@ func logConfiguration(logger log.Logger, configMsg dynamic.Message) {
 		}
 	}
 
+	if copyConf.TCP != nil {
+		for _, transport := range copyConf.TCP.ServersTransports {
+			if transport.TLS != nil {
+				transport.TLS.Certificates = tls.Certificates{}
+				transport.TLS.RootCAs = []tls.FileOrContent{}
+			}
+		}
+	}
+
 	jsonConf, err := json.Marshal(copyConf)
 	if err != nil {
 		logger.Errorf("Could not marshal dynamic configuration: %v", err)
@@ -233,7 +242,7 @@ func isEmptyConfiguration(conf *dynamic.Configuration) bool {
 	httpEmpty := conf.HTTP.Routers == nil && conf.HTTP.Services == nil && conf.HTTP.Middlewares == nil
 	tlsEmpty := conf.TLS == nil || conf.TLS.Certificates == nil && conf.TLS.Stores == nil && conf.TLS.Options == nil
 	tcpEmpty := conf.TCP.Routers == nil && conf.TCP.Services == nil && conf.TCP.Middlewares == nil
-	udpEmpty := conf.UDP.Routers == nil && conf.UDP.Services == nil
+	udpEmpty := conf.UDP.Routers == nil && conf.UDP.Services == nil && conf.UDP.Middlewares == nil
 
 	return httpEmpty && tlsEmpty && tcpEmpty && udpEmpty
 }
@@ -12,15 +12,23 @@ import (
 
 	"github.com/containous/alice"
 	"github.com/traefik/traefik/v2/pkg/config/runtime"
+	"github.com/traefik/traefik/v2/pkg/filewatcher"
+	"github.com/traefik/traefik/v2/pkg/ip"
+	"github.com/traefik/traefik/v2/pkg/ipban"
+	"github.com/traefik/traefik/v2/pkg/metrics"
 	"github.com/traefik/traefik/v2/pkg/middlewares/addprefix"
 	"github.com/traefik/traefik/v2/pkg/middlewares/auth"
+	"github.com/traefik/traefik/v2/pkg/middlewares/bodylimit"
 	"github.com/traefik/traefik/v2/pkg/middlewares/buffering"
 	"github.com/traefik/traefik/v2/pkg/middlewares/chain"
+	"github.com/traefik/traefik/v2/pkg/middlewares/challenge"
 	"github.com/traefik/traefik/v2/pkg/middlewares/circuitbreaker"
 	"github.com/traefik/traefik/v2/pkg/middlewares/compress"
 	"github.com/traefik/traefik/v2/pkg/middlewares/customerrors"
+	"github.com/traefik/traefik/v2/pkg/middlewares/geoip"
 	"github.com/traefik/traefik/v2/pkg/middlewares/headers"
 	"github.com/traefik/traefik/v2/pkg/middlewares/inflightreq"
+	"github.com/traefik/traefik/v2/pkg/middlewares/ipdenylist"
 	"github.com/traefik/traefik/v2/pkg/middlewares/ipwhitelist"
 	"github.com/traefik/traefik/v2/pkg/middlewares/passtlsclientcert"
 	"github.com/traefik/traefik/v2/pkg/middlewares/ratelimiter"
@@ -31,6 +39,8 @@ import (
 	"github.com/traefik/traefik/v2/pkg/middlewares/stripprefix"
 	"github.com/traefik/traefik/v2/pkg/middlewares/stripprefixregex"
 	"github.com/traefik/traefik/v2/pkg/middlewares/tracing"
+	"github.com/traefik/traefik/v2/pkg/middlewares/validation"
+	"github.com/traefik/traefik/v2/pkg/middlewares/waf"
 	"github.com/traefik/traefik/v2/pkg/server/provider"
 )
 
@@ -42,10 +52,15 @@ const (
 
 // Builder the middleware builder.
 type Builder struct {
-	configs        map[string]*runtime.MiddlewareInfo
-	pluginBuilder  PluginsBuilder
-	serviceBuilder serviceBuilder
-	memcached      *memcached.Client
+	configs         map[string]*runtime.MiddlewareInfo
+	pluginBuilder   PluginsBuilder
+	serviceBuilder  serviceBuilder
+	memcached       *memcached.Client
+	metricsRegistry metrics.Registry
+	banManager      *ipban.Manager
+	sharedFiles     *filewatcher.SharedFiles
+	sourceLists     *ip.SourceLists
+	hmacNonces      *auth.HMACNonceStores
 }
 
 type serviceBuilder interface {
@@ -53,8 +68,8 @@ type serviceBuilder interface {
 }
 
 // NewBuilder creates a new Builder.
-func NewBuilder(configs map[string]*runtime.MiddlewareInfo, serviceBuilder serviceBuilder, pluginBuilder PluginsBuilder, memcached *memcached.Client) *Builder {
-	return &Builder{configs: configs, serviceBuilder: serviceBuilder, pluginBuilder: pluginBuilder, memcached: memcached}
+func NewBuilder(configs map[string]*runtime.MiddlewareInfo, serviceBuilder serviceBuilder, pluginBuilder PluginsBuilder, memcached *memcached.Client, metricsRegistry metrics.Registry, banManager *ipban.Manager, sharedFiles *filewatcher.SharedFiles, sourceLists *ip.SourceLists, hmacNonces *auth.HMACNonceStores) *Builder {
+	return &Builder{configs: configs, serviceBuilder: serviceBuilder, pluginBuilder: pluginBuilder, memcached: memcached, metricsRegistry: metricsRegistry, banManager: banManager, sharedFiles: sharedFiles, sourceLists: sourceLists, hmacNonces: hmacNonces}
 }
 
 // BuildChain creates a middleware chain.
@@ -141,6 +156,16 @@ func (b *Builder) buildConstructor(ctx context.Context, middlewareName string) (
 		}
 	}
 
+	// BodyLimit
+	if config.BodyLimit != nil {
+		if middleware != nil {
+			return nil, badConf
+		}
+		middleware = func(next http.Handler) (http.Handler, error) {
+			return bodylimit.New(ctx, next, *config.BodyLimit, middlewareName)
+		}
+	}
+
 	// Chain
 	if config.Chain != nil {
 		if middleware != nil {
@@ -228,7 +253,47 @@ func (b *Builder) buildConstructor(ctx context.Context, middlewareName string) (
 			return nil, badConf
 		}
 		middleware = func(next http.Handler) (http.Handler, error) {
-			return auth.NewForward(ctx, next, *config.ForwardAuth, middlewareName)
+			return auth.NewForward(ctx, next, *config.ForwardAuth, middlewareName, b.memcached)
+		}
+	}
+
+	// JWTAuth
+	if config.JWTAuth != nil {
+		if middleware != nil {
+			return nil, badConf
+		}
+		middleware = func(next http.Handler) (http.Handler, error) {
+			return auth.NewJWT(ctx, next, *config.JWTAuth, middlewareName)
+		}
+	}
+
+	// OIDCAuth
+	if config.OIDCAuth != nil {
+		if middleware != nil {
+			return nil, badConf
+		}
+		middleware = func(next http.Handler) (http.Handler, error) {
+			return auth.NewOIDC(ctx, next, *config.OIDCAuth, middlewareName)
+		}
+	}
+
+	// APIKeyAuth
+	if config.APIKeyAuth != nil {
+		if middleware != nil {
+			return nil, badConf
+		}
+		middleware = func(next http.Handler) (http.Handler, error) {
+			return auth.NewAPIKey(ctx, next, *config.APIKeyAuth, middlewareName, b.sharedFiles)
+		}
+	}
+
+	// HMACAuth
+	if config.HMACAuth != nil {
+		if middleware != nil {
+			return nil, badConf
+		}
+		middleware = func(next http.Handler) (http.Handler, error) {
+			return auth.NewHMAC(ctx, next, *config.HMACAuth, middlewareName, b.hmacNonces, b.memcached)
 		}
 	}
 
@@ -242,6 +307,16 @@ func (b *Builder) buildConstructor(ctx context.Context, middlewareName string) (
 		}
 	}
 
+	// IPDenyList
+	if config.IPDenyList != nil {
+		if middleware != nil {
+			return nil, badConf
+		}
+		middleware = func(next http.Handler) (http.Handler, error) {
+			return ipdenylist.New(ctx, next, *config.IPDenyList, middlewareName, b.banManager, b.sourceLists)
+		}
+	}
+
 	// IPWhiteList
 	if config.IPWhiteList != nil {
 		if middleware != nil {
@@ -272,6 +347,16 @@ func (b *Builder) buildConstructor(ctx context.Context, middlewareName string) (
 		}
 	}
 
+	// TLSClientCertAuth
+	if config.TLSClientCertAuth != nil {
+		if middleware != nil {
+			return nil, badConf
+		}
+		middleware = func(next http.Handler) (http.Handler, error) {
+			return auth.NewTLSClientCert(ctx, next, *config.TLSClientCertAuth, middlewareName)
+		}
+	}
+
 	// RateLimit
 	if config.RateLimit != nil {
 		if middleware != nil {
@@ -353,6 +438,46 @@ func (b *Builder) buildConstructor(ctx context.Context, middlewareName string) (
 		}
 	}
 
+	// WAF
+	if config.WAF != nil {
+		if middleware != nil {
+			return nil, badConf
+		}
+		middleware = func(next http.Handler) (http.Handler, error) {
+			return waf.New(ctx, next, *config.WAF, middlewareName, b.metricsRegistry)
+		}
+	}
+
+	// RequestValidation
+	if config.RequestValidation != nil {
+		if middleware != nil {
+			return nil, badConf
+		}
+		middleware = func(next http.Handler) (http.Handler, error) {
+			return validation.New(ctx, next, *config.RequestValidation, middlewareName)
+		}
+	}
+
+	// GeoIP
+	if config.GeoIP != nil {
+		if middleware != nil {
+			return nil, badConf
+		}
+		middleware = func(next http.Handler) (http.Handler, error) {
+			return geoip.New(ctx, next, *config.GeoIP, middlewareName, b.sharedFiles)
+		}
+	}
+
+	// Challenge
+	if config.Challenge != nil {
+		if middleware != nil {
+			return nil, badConf
+		}
+		middleware = func(next http.Handler) (http.Handler, error) {
+			return challenge.New(ctx, next, *config.Challenge, middlewareName)
+		}
+	}
+
 	// Plugin
 	if config.Plugin != nil && !reflect.ValueOf(b.pluginBuilder).IsNil() { // Using "reflect" because "b.pluginBuilder" is an interface.
 		if middleware != nil {
@@ -6,8 +6,16 @@ import (
 	"strings"
 
 	"github.com/traefik/traefik/v2/pkg/config/runtime"
+	"github.com/traefik/traefik/v2/pkg/ip"
+	"github.com/traefik/traefik/v2/pkg/ipban"
+	"github.com/traefik/traefik/v2/pkg/middlewares/accesslog"
+	tcpaccesslog "github.com/traefik/traefik/v2/pkg/middlewares/tcp/accesslog"
+	conntimeout "github.com/traefik/traefik/v2/pkg/middlewares/tcp/conntimeout"
 	inflightconn "github.com/traefik/traefik/v2/pkg/middlewares/tcp/inflightconn"
+	ipdenylist "github.com/traefik/traefik/v2/pkg/middlewares/tcp/ipdenylist"
 	ipwhitelist "github.com/traefik/traefik/v2/pkg/middlewares/tcp/ipwhitelist"
+	maxconn "github.com/traefik/traefik/v2/pkg/middlewares/tcp/maxconn"
+	ratelimiter "github.com/traefik/traefik/v2/pkg/middlewares/tcp/ratelimiter"
 	"github.com/traefik/traefik/v2/pkg/server/provider"
 	"github.com/traefik/traefik/v2/pkg/tcp"
 )
@@ -20,12 +28,15 @@ const (
 
 // Builder the middleware builder.
 type Builder struct {
-	configs map[string]*runtime.TCPMiddlewareInfo
+	configs     map[string]*runtime.TCPMiddlewareInfo
+	banManager  *ipban.Manager
+	sourceLists *ip.SourceLists
+	accessLog   *accesslog.Handler
 }
 
 // NewBuilder creates a new Builder.
-func NewBuilder(configs map[string]*runtime.TCPMiddlewareInfo) *Builder {
-	return &Builder{configs: configs}
+func NewBuilder(configs map[string]*runtime.TCPMiddlewareInfo, banManager *ipban.Manager, sourceLists *ip.SourceLists, accessLog *accesslog.Handler) *Builder {
+	return &Builder{configs: configs, banManager: banManager, sourceLists: sourceLists, accessLog: accessLog}
 }
 
 // BuildChain creates a middleware chain.
@@ -101,6 +112,41 @@ func (b *Builder) buildConstructor(ctx context.Context, middlewareName string) (
 		}
 	}
 
+	// IPDenyList
+	if config.IPDenyList != nil {
+		middleware = func(next tcp.Handler) (tcp.Handler, error) {
+			return ipdenylist.New(ctx, next, *config.IPDenyList, middlewareName, b.banManager, b.sourceLists)
+		}
+	}
+
+	// RateLimit
+	if config.RateLimit != nil {
+		middleware = func(next tcp.Handler) (tcp.Handler, error) {
+			return ratelimiter.New(ctx, next, *config.RateLimit, middlewareName)
+		}
+	}
+
+	// MaxConn
+	if config.MaxConn != nil {
+		middleware = func(next tcp.Handler) (tcp.Handler, error) {
+			return maxconn.New(ctx, next, *config.MaxConn, middlewareName)
+		}
+	}
+
+	// ConnTimeout
+	if config.ConnTimeout != nil {
+		middleware = func(next tcp.Handler) (tcp.Handler, error) {
+			return conntimeout.New(ctx, next, *config.ConnTimeout, middlewareName)
+		}
+	}
+
+	// AccessLog
+	if config.AccessLog != nil {
+		middleware = func(next tcp.Handler) (tcp.Handler, error) {
+			return tcpaccesslog.New(ctx, next, *config.AccessLog, middlewareName, b.accessLog)
+		}
+	}
+
 	if middleware == nil {
 		return nil, fmt.Errorf("invalid middleware %q configuration: invalid middleware type or middleware does not exist", middlewareName)
 	}
@@ -70,11 +70,21 @@ func NewRouter() (*Router, error) {
 // GetTLSGetClientInfo is called after a ClientHello is received from a client.
 func (r *Router) GetTLSGetClientInfo() func(info *tls.ClientHelloInfo) (*tls.Config, error) {
 	return func(info *tls.ClientHelloInfo) (*tls.Config, error) {
-		if tlsConfig, ok := r.hostHTTPTLSConfig[info.ServerName]; ok {
-			return tlsConfig, nil
+		tlsConfig, ok := r.hostHTTPTLSConfig[info.ServerName]
+		if !ok {
+			tlsConfig = r.httpsTLSConfig
 		}
 
-		return r.httpsTLSConfig, nil
+		// The GetConfigForClient of the returned configuration is not called by crypto/tls,
+		// so it is called here to let the configuration update itself, e.g. its session ticket keys.
+		if tlsConfig != nil && tlsConfig.GetConfigForClient != nil {
+			config, err := tlsConfig.GetConfigForClient(info)
+			if err != nil || config != nil {
+				return config, err
+			}
+		}
+
+		return tlsConfig, nil
 	}
 }
 
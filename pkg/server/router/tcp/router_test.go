@@ -162,7 +162,7 @@ func Test_Routing(t *testing.T) {
 		},
 	}
 
-	serviceManager := tcp.NewManager(conf)
+	serviceManager := tcp.NewManager(conf, nil)
 
 	// Creates the tlsManager and defines the TLS 1.0 and 1.2 TLSOptions.
 	tlsManager := traefiktls.NewManager()
@@ -183,7 +183,7 @@ func Test_Routing(t *testing.T) {
 		},
 		[]*traefiktls.CertAndStores{})
 
-	middlewaresBuilder := tcpmiddleware.NewBuilder(conf.TCPMiddlewares)
+	middlewaresBuilder := tcpmiddleware.NewBuilder(conf.TCPMiddlewares, nil, nil, nil)
 
 	manager := NewManager(conf, serviceManager, middlewaresBuilder,
 		nil, nil, tlsManager)
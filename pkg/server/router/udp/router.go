@@ -7,25 +7,33 @@ import (
 
 	"github.com/traefik/traefik/v2/pkg/config/runtime"
 	"github.com/traefik/traefik/v2/pkg/log"
+	udpmuxer "github.com/traefik/traefik/v2/pkg/muxer/udp"
 	"github.com/traefik/traefik/v2/pkg/server/provider"
 	udpservice "github.com/traefik/traefik/v2/pkg/server/service/udp"
 	"github.com/traefik/traefik/v2/pkg/udp"
 )
 
+type middlewareBuilder interface {
+	BuildChain(ctx context.Context, names []string) *udp.Chain
+}
+
 // NewManager Creates a new Manager.
 func NewManager(conf *runtime.Configuration,
 	serviceManager *udpservice.Manager,
+	middlewaresBuilder middlewareBuilder,
 ) *Manager {
 	return &Manager{
-		serviceManager: serviceManager,
-		conf:           conf,
+		serviceManager:     serviceManager,
+		middlewaresBuilder: middlewaresBuilder,
+		conf:               conf,
 	}
 }
 
 // Manager is a route/router manager.
 type Manager struct {
-	serviceManager *udpservice.Manager
-	conf           *runtime.Configuration
+	serviceManager     *udpservice.Manager
+	middlewaresBuilder middlewareBuilder
+	conf               *runtime.Configuration
 }
 
 func (m *Manager) getUDPRouters(ctx context.Context, entryPoints []string) map[string]map[string]*runtime.UDPRouterInfo {
@@ -48,21 +56,27 @@ func (m *Manager) BuildHandlers(rootCtx context.Context, entryPoints []string) m
 
 		ctx := log.With(rootCtx, log.Str(log.EntryPointName, entryPointName))
 
-		if len(routers) > 1 {
-			log.FromContext(ctx).Warn("Config has more than one udp router for a given entrypoint.")
+		handler, err := m.buildEntryPointHandler(ctx, routers)
+		if err != nil {
+			log.FromContext(ctx).Error(err)
+			continue
 		}
 
-		handlers := m.buildEntryPointHandlers(ctx, routers)
-
-		if len(handlers) > 0 {
-			// As UDP support only one router per entrypoint, we only take the first one.
-			entryPointHandlers[entryPointName] = handlers[0]
+		if handler != nil {
+			entryPointHandlers[entryPointName] = handler
 		}
 	}
 	return entryPointHandlers
 }
 
-func (m *Manager) buildEntryPointHandlers(ctx context.Context, configs map[string]*runtime.UDPRouterInfo) []udp.Handler {
+// buildEntryPointHandler builds the handler dispatching the sessions of an entry point
+// to its routers, according to their rules and priorities.
+func (m *Manager) buildEntryPointHandler(ctx context.Context, configs map[string]*runtime.UDPRouterInfo) (udp.Handler, error) {
+	muxer, err := udpmuxer.NewMuxer()
+	if err != nil {
+		return nil, err
+	}
+
 	var rtNames []string
 	for routerName := range configs {
 		rtNames = append(rtNames, routerName)
@@ -72,7 +86,8 @@ func (m *Manager) buildEntryPointHandlers(ctx context.Context, configs map[strin
 		return rtNames[i] > rtNames[j]
 	})
 
-	var handlers []udp.Handler
+	var routes int
+	var catchAllRouters []string
 
 	for _, routerName := range rtNames {
 		routerConfig := configs[routerName]
@@ -87,15 +102,60 @@ func (m *Manager) buildEntryPointHandlers(ctx context.Context, configs map[strin
 			continue
 		}
 
-		handler, err := m.serviceManager.BuildUDP(ctxRouter, routerConfig.Service)
+		handler, err := m.buildUDPHandler(ctxRouter, routerConfig)
 		if err != nil {
 			routerConfig.AddError(err, true)
 			logger.Error(err)
 			continue
 		}
 
-		handlers = append(handlers, handler)
+		err = muxer.AddRoute(routerConfig.Rule, routerConfig.Priority, handler)
+		if err != nil {
+			routerConfig.AddError(err, true)
+			logger.Error(err)
+			continue
+		}
+
+		routes++
+
+		if routerConfig.Rule == "" {
+			catchAllRouters = append(catchAllRouters, routerName)
+		}
 	}
 
-	return handlers
+	if len(catchAllRouters) > 1 {
+		log.FromContext(ctx).Warnf("Config has more than one udp router without rule for a given entrypoint, only %s is used for the unmatched sessions.", catchAllRouters[0])
+	}
+
+	if routes == 0 {
+		return nil, nil
+	}
+
+	return udp.HandlerFunc(func(conn *udp.Conn) {
+		connData, err := udpmuxer.NewConnData(conn.RemoteAddr())
+		if err != nil {
+			log.WithoutContext().Errorf("Error while reading UDP session data: %v", err)
+			conn.Close()
+			return
+		}
+
+		handler := muxer.Match(connData)
+		if handler == nil {
+			conn.Close()
+			return
+		}
+
+		handler.ServeUDP(conn)
+	}), nil
+}
+
+func (m *Manager) buildUDPHandler(ctx context.Context, router *runtime.UDPRouterInfo) (udp.Handler, error) {
+	sHandler, err := m.serviceManager.BuildUDP(ctx, router.Service)
+	if err != nil {
+		return nil, err
+	}
+
+	mHandler := m.middlewaresBuilder.BuildChain(ctx, router.Middlewares)
+
+	return udp.NewChain().Extend(*mHandler).Then(sHandler)
 }
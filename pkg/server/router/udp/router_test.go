@@ -7,6 +7,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/traefik/traefik/v2/pkg/config/dynamic"
 	"github.com/traefik/traefik/v2/pkg/config/runtime"
+	udpmiddleware "github.com/traefik/traefik/v2/pkg/server/middleware/udp"
 	"github.com/traefik/traefik/v2/pkg/server/service/udp"
 )
 
@@ -15,6 +16,7 @@ func TestRuntimeConfiguration(t *testing.T) {
 		desc          string
 		serviceConfig map[string]*runtime.UDPServiceInfo
 		routerConfig  map[string]*runtime.UDPRouterInfo
+		middlewares   map[string]*runtime.UDPMiddlewareInfo
 		expectedError int
 	}{
 		{
@@ -103,6 +105,84 @@ func TestRuntimeConfiguration(t *testing.T) {
 			},
 			expectedError: 2,
 		},
+		{
+			desc: "Router with invalid rule",
+			serviceConfig: map[string]*runtime.UDPServiceInfo{
+				"foo-service": {
+					UDPService: &dynamic.UDPService{
+						LoadBalancer: &dynamic.UDPServersLoadBalancer{
+							Servers: []dynamic.UDPServer{
+								{
+									Address: "127.0.0.1:80",
+								},
+							},
+						},
+					},
+				},
+			},
+			routerConfig: map[string]*runtime.UDPRouterInfo{
+				"foo": {
+					UDPRouter: &dynamic.UDPRouter{
+						EntryPoints: []string{"web"},
+						Service:     "foo-service",
+						Rule:        "ClientIP(`10.0.0.0/8`)",
+					},
+				},
+				"bar": {
+					UDPRouter: &dynamic.UDPRouter{
+						EntryPoints: []string{"web"},
+						Service:     "foo-service",
+						Rule:        "HostSNI(`foo.bar`)",
+					},
+				},
+			},
+			expectedError: 1,
+		},
+		{
+			desc: "Router with middlewares",
+			serviceConfig: map[string]*runtime.UDPServiceInfo{
+				"foo-service": {
+					UDPService: &dynamic.UDPService{
+						LoadBalancer: &dynamic.UDPServersLoadBalancer{
+							Servers: []dynamic.UDPServer{
+								{
+									Address: "127.0.0.1:80",
+								},
+							},
+						},
+					},
+				},
+			},
+			middlewares: map[string]*runtime.UDPMiddlewareInfo{
+				"whitelist": {
+					UDPMiddleware: &dynamic.UDPMiddleware{
+						IPWhiteList: &dynamic.UDPIPWhiteList{SourceRange: []string{"10.0.0.0/8"}},
+					},
+				},
+				"broken": {
+					UDPMiddleware: &dynamic.UDPMiddleware{
+						RateLimit: &dynamic.UDPRateLimit{},
+					},
+				},
+			},
+			routerConfig: map[string]*runtime.UDPRouterInfo{
+				"foo": {
+					UDPRouter: &dynamic.UDPRouter{
+						EntryPoints: []string{"web"},
+						Service:     "foo-service",
+						Middlewares: []string{"whitelist"},
+					},
+				},
+				"bar": {
+					UDPRouter: &dynamic.UDPRouter{
+						EntryPoints: []string{"web"},
+						Service:     "foo-service",
+						Middlewares: []string{"broken"},
+					},
+				},
+			},
+			expectedError: 2,
+		},
 	}
 
 	for _, test := range testCases {
@@ -114,11 +194,13 @@ func TestRuntimeConfiguration(t *testing.T) {
 			entryPoints := []string{"web"}
 
 			conf := &runtime.Configuration{
-				UDPServices: test.serviceConfig,
-				UDPRouters:  test.routerConfig,
+				UDPServices:    test.serviceConfig,
+				UDPRouters:     test.routerConfig,
+				UDPMiddlewares: test.middlewares,
 			}
 			serviceManager := udp.NewManager(conf)
-			routerManager := NewManager(conf, serviceManager)
+			middlewaresBuilder := udpmiddleware.NewBuilder(conf.UDPMiddlewares)
+			routerManager := NewManager(conf, serviceManager, middlewaresBuilder)
 
 			_ = routerManager.BuildHandlers(context.Background(), entryPoints)
 
@@ -136,6 +218,11 @@ func TestRuntimeConfiguration(t *testing.T) {
 					allErrors++
 				}
 			}
+			for _, v := range conf.UDPMiddlewares {
+				if len(v.Err) > 0 {
+					allErrors++
+				}
+			}
 			assert.Equal(t, test.expectedError, allErrors)
 		})
 	}
@@ -4,17 +4,25 @@ import (
 	"context"
 	"github.com/traefik/traefik/v2/pkg/config/runtime"
 	"github.com/traefik/traefik/v2/pkg/config/static"
+	"github.com/traefik/traefik/v2/pkg/dnsdiscovery"
+	"github.com/traefik/traefik/v2/pkg/filewatcher"
+	"github.com/traefik/traefik/v2/pkg/ip"
+	"github.com/traefik/traefik/v2/pkg/ipban"
 	"github.com/traefik/traefik/v2/pkg/log"
 	"github.com/traefik/traefik/v2/pkg/memcached"
 	"github.com/traefik/traefik/v2/pkg/metrics"
+	"github.com/traefik/traefik/v2/pkg/middlewares/accesslog"
+	"github.com/traefik/traefik/v2/pkg/middlewares/auth"
 	"github.com/traefik/traefik/v2/pkg/server/middleware"
 	tcpmiddleware "github.com/traefik/traefik/v2/pkg/server/middleware/tcp"
+	udpmiddleware "github.com/traefik/traefik/v2/pkg/server/middleware/udp"
 	"github.com/traefik/traefik/v2/pkg/server/router"
 	tcprouter "github.com/traefik/traefik/v2/pkg/server/router/tcp"
 	udprouter "github.com/traefik/traefik/v2/pkg/server/router/udp"
 	"github.com/traefik/traefik/v2/pkg/server/service"
 	"github.com/traefik/traefik/v2/pkg/server/service/tcp"
 	"github.com/traefik/traefik/v2/pkg/server/service/udp"
+	traefiktcp "github.com/traefik/traefik/v2/pkg/tcp"
 	"github.com/traefik/traefik/v2/pkg/tls"
 	udptypes "github.com/traefik/traefik/v2/pkg/udp"
 )
@@ -33,11 +41,24 @@ type RouterFactory struct {
 	tlsManager   *tls.Manager
 
 	memcached *memcached.Client
+
+	banManager  *ipban.Manager
+	sharedFiles *filewatcher.SharedFiles
+	sourceLists *ip.SourceLists
+	hmacNonces  *auth.HMACNonceStores
+
+	dialerManager *traefiktcp.DialerManager
+
+	dnsLauncher *dnsdiscovery.Launcher
+
+	accessLog *accesslog.Handler
 }
 
 // NewRouterFactory creates a new RouterFactory.
 func NewRouterFactory(staticConfiguration static.Configuration, managerFactory *service.ManagerFactory, tlsManager *tls.Manager,
 	chainBuilder *middleware.ChainBuilder, pluginBuilder middleware.PluginsBuilder, metricsRegistry metrics.Registry, memcached *memcached.Client,
+	banManager *ipban.Manager, sharedFiles *filewatcher.SharedFiles, sourceLists *ip.SourceLists, hmacNonces *auth.HMACNonceStores,
+	dialerManager *traefiktcp.DialerManager, dnsLauncher *dnsdiscovery.Launcher, accessLog *accesslog.Handler,
 ) *RouterFactory {
 	var entryPointsTCP, entryPointsUDP []string
 	for name, cfg := range staticConfiguration.EntryPoints {
@@ -63,6 +84,13 @@ func NewRouterFactory(staticConfiguration static.Configuration, managerFactory *
 		chainBuilder:    chainBuilder,
 		pluginBuilder:   pluginBuilder,
 		memcached:       memcached,
+		banManager:      banManager,
+		sharedFiles:     sharedFiles,
+		sourceLists:     sourceLists,
+		hmacNonces:      hmacNonces,
+		dialerManager:   dialerManager,
+		dnsLauncher:     dnsLauncher,
+		accessLog:       accessLog,
 	}
 }
 
@@ -73,7 +101,7 @@ func (f *RouterFactory) CreateRouters(rtConf *runtime.Configuration) (map[string
 	// HTTP
 	serviceManager := f.managerFactory.Build(rtConf)
 
-	middlewaresBuilder := middleware.NewBuilder(rtConf.Middlewares, serviceManager, f.pluginBuilder, f.memcached)
+	middlewaresBuilder := middleware.NewBuilder(rtConf.Middlewares, serviceManager, f.pluginBuilder, f.memcached, f.metricsRegistry, f.banManager, f.sharedFiles, f.sourceLists, f.hmacNonces)
 
 	routerManager := router.NewManager(rtConf, serviceManager, middlewaresBuilder, f.chainBuilder, f.metricsRegistry)
 
@@ -83,18 +111,27 @@ func (f *RouterFactory) CreateRouters(rtConf *runtime.Configuration) (map[string
 	serviceManager.LaunchHealthCheck()
 
 	// TCP
-	svcTCPManager := tcp.NewManager(rtConf)
+	svcTCPManager := tcp.NewManager(rtConf, f.dialerManager)
 
-	middlewaresTCPBuilder := tcpmiddleware.NewBuilder(rtConf.TCPMiddlewares)
+	middlewaresTCPBuilder := tcpmiddleware.NewBuilder(rtConf.TCPMiddlewares, f.banManager, f.sourceLists, f.accessLog)
 
 	rtTCPManager := tcprouter.NewManager(rtConf, svcTCPManager, middlewaresTCPBuilder, handlersNonTLS, handlersTLS, f.tlsManager)
 	routersTCP := rtTCPManager.BuildHandlers(ctx, f.entryPointsTCP)
 
 	// UDP
 	svcUDPManager := udp.NewManager(rtConf)
-	rtUDPManager := udprouter.NewManager(rtConf, svcUDPManager)
+
+	middlewaresUDPBuilder := udpmiddleware.NewBuilder(rtConf.UDPMiddlewares)
+
+	rtUDPManager := udprouter.NewManager(rtConf, svcUDPManager, middlewaresUDPBuilder)
 	routersUDP := rtUDPManager.BuildHandlers(ctx, f.entryPointsUDP)
 
+	var dnsWatchers []*dnsdiscovery.Watcher
+	dnsWatchers = append(dnsWatchers, serviceManager.DNSWatchers()...)
+	dnsWatchers = append(dnsWatchers, svcTCPManager.DNSWatchers()...)
+	dnsWatchers = append(dnsWatchers, svcUDPManager.DNSWatchers()...)
+	f.dnsLauncher.Launch(dnsWatchers)
+
 	rtConf.PopulateUsedBy()
 
 	return routersTCP, routersUDP
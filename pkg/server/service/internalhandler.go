@@ -6,11 +6,14 @@ import (
 	"fmt"
 	"net/http"
 	"strings"
+
+	"github.com/traefik/traefik/v2/pkg/dnsdiscovery"
 )
 
 type serviceManager interface {
 	BuildHTTP(rootCtx context.Context, serviceName string) (http.Handler, error)
 	LaunchHealthCheck()
+	DNSWatchers() []*dnsdiscovery.Watcher
 }
 
 // InternalHandlers is the internal HTTP handlers builder.
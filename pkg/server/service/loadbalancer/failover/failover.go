@@ -1,39 +1,64 @@
 package failover
 
 import (
+	"bufio"
+	"bytes"
 	"context"
 	"errors"
+	"fmt"
+	"io"
+	"net"
 	"net/http"
 	"sync"
 
 	"github.com/traefik/traefik/v2/pkg/config/dynamic"
 	"github.com/traefik/traefik/v2/pkg/log"
+	"github.com/traefik/traefik/v2/pkg/types"
 )
 
-// Failover is an http.Handler that can forward requests to the fallback handler
+type statusHandler struct {
+	http.Handler
+	up bool
+}
+
+// Failover is an http.Handler that can forward requests to the fallback handlers
 // when the main handler status is down.
+// The fallback handlers are tried in order, and the first one with an up status is used.
+// Optionally, a request is also replayed on the next available handler
+// when the response of a handler has one of the configured status codes.
 type Failover struct {
 	wantsHealthCheck bool
-	handler          http.Handler
-	fallbackHandler  http.Handler
 	// updaters is the list of hooks that are run (to update the Failover
 	// parent(s)), whenever the Failover status changes.
 	updaters []func(bool)
 
-	handlerStatusMu sync.RWMutex
-	handlerStatus   bool
+	// errors enables the per-request failover,
+	// on the proxy errors and on the statusCodes of the responses.
+	errors      bool
+	statusCodes types.HTTPCodeRanges
+	maxBodySize int64
 
-	fallbackStatusMu sync.RWMutex
-	fallbackStatus   bool
+	mu sync.RWMutex
+	// handlers holds the main handler, followed by the fallback handlers in order.
+	handlers []*statusHandler
 }
 
 // New creates a new Failover handler.
 func New(hc *dynamic.HealthCheck) *Failover {
 	return &Failover{
 		wantsHealthCheck: hc != nil,
+		handlers:         []*statusHandler{{}},
 	}
 }
 
+// SetErrors enables the per-request failover on the proxy errors, and on the given response status codes.
+// Requests with a body larger than maxBodySize (if positive) are not replayed.
+func (f *Failover) SetErrors(statusCodes types.HTTPCodeRanges, maxBodySize int64) {
+	f.errors = true
+	f.statusCodes = statusCodes
+	f.maxBodySize = maxBodySize
+}
+
 // RegisterStatusUpdater adds fn to the list of hooks that are run when the
 // status of the Failover changes.
 // Not thread safe.
@@ -48,93 +73,287 @@ func (f *Failover) RegisterStatusUpdater(fn func(up bool)) error {
 }
 
 func (f *Failover) ServeHTTP(w http.ResponseWriter, req *http.Request) {
-	f.handlerStatusMu.RLock()
-	handlerStatus := f.handlerStatus
-	f.handlerStatusMu.RUnlock()
+	candidates := f.upHandlers()
+	if len(candidates) == 0 {
+		http.Error(w, http.StatusText(http.StatusServiceUnavailable), http.StatusServiceUnavailable)
+		return
+	}
 
-	if handlerStatus {
-		f.handler.ServeHTTP(w, req)
+	if !f.errors || len(candidates) == 1 {
+		candidates[0].ServeHTTP(w, req)
 		return
 	}
 
-	f.fallbackStatusMu.RLock()
-	fallbackStatus := f.fallbackStatus
-	f.fallbackStatusMu.RUnlock()
+	body, err := readBody(req, f.maxBodySize)
+	if errors.Is(err, errBodyTooLarge) {
+		log.FromContext(req.Context()).Debug("No failover on errors, request body larger than allowed size")
+		candidates[0].ServeHTTP(w, req)
+		return
+	}
+	if err != nil {
+		// The body limit of a BodyLimit middleware is reported as such, rather than as an internal error.
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			http.Error(w, http.StatusText(http.StatusRequestEntityTooLarge), http.StatusRequestEntityTooLarge)
+			return
+		}
 
-	if fallbackStatus {
-		f.fallbackHandler.ServeHTTP(w, req)
+		http.Error(w, fmt.Sprintf("%s: error reading request body: %v", http.StatusText(http.StatusInternalServerError), err), http.StatusInternalServerError)
 		return
 	}
 
-	http.Error(w, http.StatusText(http.StatusServiceUnavailable), http.StatusServiceUnavailable)
+	for i, handler := range candidates {
+		if body != nil {
+			req.Body = io.NopCloser(bytes.NewReader(body))
+		}
+
+		// The last candidate response is always sent, whatever its status code.
+		if i == len(candidates)-1 {
+			handler.ServeHTTP(w, req)
+			return
+		}
+
+		rw := &failoverResponseWriter{rw: w, header: make(http.Header), statusCodes: f.statusCodes}
+		handler.ServeHTTP(rw, req.WithContext(context.WithValue(req.Context(), responseWriterKey{}, rw)))
+		if !rw.failed {
+			// The handler may return without writing the response, as net/http sends an implicit 200 in this case.
+			rw.flushHeaders()
+			return
+		}
+
+		if rw.proxyError {
+			log.FromContext(req.Context()).Debugf("Proxy error (status %d) triggers failover", rw.status)
+			continue
+		}
+
+		log.FromContext(req.Context()).Debugf("Response status %d triggers failover", rw.status)
+	}
+}
+
+// upHandlers returns the handlers with an up status, in order.
+func (f *Failover) upHandlers() []http.Handler {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	var handlers []http.Handler
+	for _, h := range f.handlers {
+		if h.Handler != nil && h.up {
+			handlers = append(handlers, h.Handler)
+		}
+	}
+
+	return handlers
 }
 
 // SetHandler sets the main http.Handler.
 func (f *Failover) SetHandler(handler http.Handler) {
-	f.handlerStatusMu.Lock()
-	defer f.handlerStatusMu.Unlock()
+	f.mu.Lock()
+	defer f.mu.Unlock()
 
-	f.handler = handler
-	f.handlerStatus = true
+	f.handlers[0] = &statusHandler{Handler: handler, up: true}
 }
 
 // SetHandlerStatus sets the main handler status.
 func (f *Failover) SetHandlerStatus(ctx context.Context, up bool) {
-	f.handlerStatusMu.Lock()
-	defer f.handlerStatusMu.Unlock()
+	f.setStatus(ctx, 0, up)
+}
+
+// SetFallbackHandler sets the first fallback http.Handler.
+func (f *Failover) SetFallbackHandler(handler http.Handler) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if len(f.handlers) > 1 {
+		f.handlers[1] = &statusHandler{Handler: handler, up: true}
+		return
+	}
+
+	f.handlers = append(f.handlers, &statusHandler{Handler: handler, up: true})
+}
+
+// SetFallbackHandlerStatus sets the first fallback handler status.
+func (f *Failover) SetFallbackHandlerStatus(ctx context.Context, up bool) {
+	f.SetFallbackHandlerStatusAt(ctx, 0, up)
+}
+
+// AddFallbackHandler appends an http.Handler to the fallback handlers,
+// and returns its index among them.
+func (f *Failover) AddFallbackHandler(handler http.Handler) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.handlers = append(f.handlers, &statusHandler{Handler: handler, up: true})
+
+	return len(f.handlers) - 2
+}
+
+// SetFallbackHandlerStatusAt sets the status of the fallback handler at the given index.
+func (f *Failover) SetFallbackHandlerStatusAt(ctx context.Context, index int, up bool) {
+	f.setStatus(ctx, index+1, up)
+}
+
+func (f *Failover) setStatus(ctx context.Context, index int, up bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if index >= len(f.handlers) {
+		return
+	}
 
 	status := "DOWN"
 	if up {
 		status = "UP"
 	}
 
-	if up == f.handlerStatus {
+	if up == f.handlers[index].up {
 		// We're still with the same status, no need to propagate.
 		log.FromContext(ctx).Debugf("Still %s, no need to propagate", status)
 		return
 	}
 
 	log.FromContext(ctx).Debugf("Propagating new %s status", status)
-	f.handlerStatus = up
+	f.handlers[index].up = up
+
+	// Failover service status is set to DOWN
+	// when all the handlers have a DOWN status.
+	var anyUp bool
+	for _, h := range f.handlers {
+		anyUp = anyUp || h.up
+	}
 
 	for _, fn := range f.updaters {
-		// Failover service status is set to DOWN
-		// when main and fallback handlers have a DOWN status.
-		fn(f.handlerStatus || f.fallbackStatus)
+		fn(anyUp)
 	}
 }
 
-// SetFallbackHandler sets the fallback http.Handler.
-func (f *Failover) SetFallbackHandler(handler http.Handler) {
-	f.fallbackStatusMu.Lock()
-	defer f.fallbackStatusMu.Unlock()
+type responseWriterKey struct{}
 
-	f.fallbackHandler = handler
-	f.fallbackStatus = true
+// ReportProxyError reports that the response to the request is a proxy error,
+// such as a connection error to the server, so that the request fails over to the next available handler,
+// whatever the configured status codes.
+// It must be called before the error response is written.
+func ReportProxyError(req *http.Request) {
+	if rw, ok := req.Context().Value(responseWriterKey{}).(*failoverResponseWriter); ok {
+		rw.proxyError = true
+	}
 }
 
-// SetFallbackHandlerStatus sets the fallback handler status.
-func (f *Failover) SetFallbackHandlerStatus(ctx context.Context, up bool) {
-	f.fallbackStatusMu.Lock()
-	defer f.fallbackStatusMu.Unlock()
+var errBodyTooLarge = errors.New("request body too large")
 
-	status := "DOWN"
-	if up {
-		status = "UP"
+// readBody reads the whole request body, so that the request can be replayed.
+func readBody(req *http.Request, maxBodySize int64) ([]byte, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, nil
 	}
 
-	if up == f.fallbackStatus {
-		// We're still with the same status, no need to propagate.
-		log.FromContext(ctx).Debugf("Still %s, no need to propagate", status)
+	if maxBodySize < 0 {
+		return io.ReadAll(req.Body)
+	}
+
+	// We purposefully try to read _more_ than maxBodySize,
+	// to detect whether the request body is larger than what we allow.
+	body, err := io.ReadAll(io.LimitReader(req.Body, maxBodySize+1))
+	if err != nil {
+		return nil, err
+	}
+
+	if int64(len(body)) > maxBodySize {
+		req.Body = io.NopCloser(io.MultiReader(bytes.NewReader(body), req.Body))
+		return nil, errBodyTooLarge
+	}
+
+	return body, nil
+}
+
+// failoverResponseWriter holds back the response headers until the status code is known,
+// and discards the response when its status code triggers a failover.
+type failoverResponseWriter struct {
+	rw          http.ResponseWriter
+	header      http.Header
+	statusCodes types.HTTPCodeRanges
+
+	status      int
+	headersSent bool
+	failed      bool
+	proxyError  bool
+	hijacked    bool
+}
+
+func (r *failoverResponseWriter) Header() http.Header {
+	if r.headersSent {
+		return r.rw.Header()
+	}
+
+	return r.header
+}
+
+func (r *failoverResponseWriter) WriteHeader(statusCode int) {
+	if r.headersSent || r.failed {
 		return
 	}
 
-	log.FromContext(ctx).Debugf("Propagating new %s status", status)
-	f.fallbackStatus = up
+	r.status = statusCode
 
-	for _, fn := range f.updaters {
-		// Failover service status is set to DOWN
-		// when main and fallback handlers have a DOWN status.
-		fn(f.handlerStatus || f.fallbackStatus)
+	// Interim responses are dropped, as the final status code is not known yet.
+	if statusCode >= 100 && statusCode < 200 && statusCode != http.StatusSwitchingProtocols {
+		return
+	}
+
+	if r.proxyError || r.statusCodes.Contains(statusCode) {
+		r.failed = true
+		return
+	}
+
+	for k, v := range r.header {
+		r.rw.Header()[k] = v
 	}
+
+	r.headersSent = true
+	r.rw.WriteHeader(statusCode)
+}
+
+// flushHeaders sends the buffered headers with an implicit 200 status code,
+// when the handler returned without writing the response.
+func (r *failoverResponseWriter) flushHeaders() {
+	if r.headersSent || r.failed || r.hijacked {
+		return
+	}
+
+	r.WriteHeader(http.StatusOK)
+}
+
+func (r *failoverResponseWriter) Write(b []byte) (int, error) {
+	if !r.headersSent && !r.failed {
+		r.WriteHeader(http.StatusOK)
+	}
+
+	if r.failed {
+		return len(b), nil
+	}
+
+	return r.rw.Write(b)
+}
+
+func (r *failoverResponseWriter) Flush() {
+	if r.failed || !r.headersSent {
+		return
+	}
+
+	if flusher, ok := r.rw.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+func (r *failoverResponseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := r.rw.(http.Hijacker)
+	if !ok {
+		return nil, nil, fmt.Errorf("%T is not a http.Hijacker", r.rw)
+	}
+
+	conn, rw, err := hijacker.Hijack()
+	if err == nil {
+		r.hijacked = true
+	}
+
+	return conn, rw, err
 }
@@ -2,13 +2,16 @@ package failover
 
 import (
 	"context"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"github.com/traefik/traefik/v2/pkg/config/dynamic"
+	"github.com/traefik/traefik/v2/pkg/types"
 )
 
 type responseRecorder struct {
@@ -161,3 +164,213 @@ func TestFailoverPropagate(t *testing.T) {
 	assert.Equal(t, 1, recorder.save["topFailover"])
 	assert.Equal(t, []int{200}, recorder.status)
 }
+
+func TestFailoverMultipleFallbacks(t *testing.T) {
+	failover := New(&dynamic.HealthCheck{})
+
+	status := true
+	require.NoError(t, failover.RegisterStatusUpdater(func(up bool) {
+		status = up
+	}))
+
+	failover.SetHandler(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.Header().Set("server", "handler")
+		rw.WriteHeader(http.StatusOK)
+	}))
+
+	failover.SetFallbackHandler(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.Header().Set("server", "fallback")
+		rw.WriteHeader(http.StatusOK)
+	}))
+
+	index := failover.AddFallbackHandler(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.Header().Set("server", "maintenance")
+		rw.WriteHeader(http.StatusOK)
+	}))
+	assert.Equal(t, 1, index)
+
+	failover.SetHandlerStatus(context.Background(), false)
+	failover.SetFallbackHandlerStatus(context.Background(), false)
+
+	recorder := &responseRecorder{ResponseRecorder: httptest.NewRecorder(), save: map[string]int{}}
+	failover.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	assert.Equal(t, 1, recorder.save["maintenance"])
+	assert.Equal(t, []int{200}, recorder.status)
+	assert.True(t, status)
+
+	failover.SetFallbackHandlerStatusAt(context.Background(), index, false)
+
+	recorder = &responseRecorder{ResponseRecorder: httptest.NewRecorder(), save: map[string]int{}}
+	failover.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	assert.Equal(t, []int{503}, recorder.status)
+	assert.False(t, status)
+}
+
+func TestFailoverOnStatusCodes(t *testing.T) {
+	statusCodes, err := types.NewHTTPCodeRanges([]string{"500-599"})
+	require.NoError(t, err)
+
+	testCases := []struct {
+		desc            string
+		maxBodySize     int64
+		handlerStatus   int
+		fallbackStatus  int
+		expectedServer  string
+		expectedStatus  int
+		expectedReplays int
+	}{
+		{
+			desc:           "no failover on success",
+			maxBodySize:    -1,
+			handlerStatus:  http.StatusOK,
+			fallbackStatus: http.StatusOK,
+			expectedServer: "handler",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			desc:            "failover on bad gateway",
+			maxBodySize:     -1,
+			handlerStatus:   http.StatusBadGateway,
+			fallbackStatus:  http.StatusOK,
+			expectedServer:  "fallback",
+			expectedStatus:  http.StatusOK,
+			expectedReplays: 1,
+		},
+		{
+			desc:            "last response is sent",
+			maxBodySize:     -1,
+			handlerStatus:   http.StatusBadGateway,
+			fallbackStatus:  http.StatusServiceUnavailable,
+			expectedServer:  "fallback",
+			expectedStatus:  http.StatusServiceUnavailable,
+			expectedReplays: 1,
+		},
+		{
+			desc:           "no failover when body is too large",
+			maxBodySize:    2,
+			handlerStatus:  http.StatusBadGateway,
+			fallbackStatus: http.StatusOK,
+			expectedServer: "handler",
+			expectedStatus: http.StatusBadGateway,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			var replays int
+
+			failover := New(nil)
+			failover.SetErrors(statusCodes, test.maxBodySize)
+
+			failover.SetHandler(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				body, err := io.ReadAll(req.Body)
+				require.NoError(t, err)
+				assert.Equal(t, "body", string(body))
+
+				rw.Header().Set("server", "handler")
+				rw.WriteHeader(test.handlerStatus)
+				_, _ = rw.Write([]byte("handler"))
+			}))
+
+			failover.SetFallbackHandler(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				replays++
+
+				body, err := io.ReadAll(req.Body)
+				require.NoError(t, err)
+				assert.Equal(t, "body", string(body))
+
+				rw.Header().Set("server", "fallback")
+				rw.WriteHeader(test.fallbackStatus)
+				_, _ = rw.Write([]byte("fallback"))
+			}))
+
+			recorder := httptest.NewRecorder()
+			failover.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/", strings.NewReader("body")))
+
+			assert.Equal(t, test.expectedStatus, recorder.Code)
+			assert.Equal(t, test.expectedServer, recorder.Header().Get("server"))
+			assert.Equal(t, test.expectedServer, recorder.Body.String())
+			assert.Equal(t, test.expectedReplays, replays)
+		})
+	}
+}
+
+func TestFailoverOnStatusCodes_bodyLimit(t *testing.T) {
+	statusCodes, err := types.NewHTTPCodeRanges([]string{"500-599"})
+	require.NoError(t, err)
+
+	failover := New(nil)
+	failover.SetErrors(statusCodes, 1<<20)
+
+	failover.SetHandler(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.WriteHeader(http.StatusOK)
+	}))
+	failover.SetFallbackHandler(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.WriteHeader(http.StatusOK)
+	}))
+
+	recorder := httptest.NewRecorder()
+
+	// The body is limited by a BodyLimit middleware.
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader("body"))
+	req.Body = http.MaxBytesReader(recorder, req.Body, 2)
+
+	failover.ServeHTTP(recorder, req)
+
+	assert.Equal(t, http.StatusRequestEntityTooLarge, recorder.Code)
+}
+
+func TestFailoverOnProxyError(t *testing.T) {
+	statusCodes, err := types.NewHTTPCodeRanges([]string{"503"})
+	require.NoError(t, err)
+
+	failover := New(nil)
+	failover.SetErrors(statusCodes, -1)
+
+	failover.SetHandler(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		ReportProxyError(req)
+
+		rw.Header().Set("server", "handler")
+		rw.WriteHeader(http.StatusBadGateway)
+	}))
+
+	failover.SetFallbackHandler(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.Header().Set("server", "fallback")
+		rw.WriteHeader(http.StatusOK)
+	}))
+
+	recorder := httptest.NewRecorder()
+	failover.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, "fallback", recorder.Header().Get("server"))
+}
+
+func TestFailoverFlushesHeaders(t *testing.T) {
+	statusCodes, err := types.NewHTTPCodeRanges([]string{"500-599"})
+	require.NoError(t, err)
+
+	failover := New(nil)
+	failover.SetErrors(statusCodes, -1)
+
+	// The handler returns without writing the response.
+	failover.SetHandler(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.Header().Set("server", "handler")
+	}))
+
+	failover.SetFallbackHandler(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.Header().Set("server", "fallback")
+		rw.WriteHeader(http.StatusOK)
+	}))
+
+	recorder := httptest.NewRecorder()
+	failover.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, "handler", recorder.Header().Get("server"))
+}
@@ -5,6 +5,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"hash/fnv"
 	"net/http"
 	"sync"
 
@@ -19,6 +20,13 @@ type namedHandler struct {
 	deadline float64
 }
 
+// matchedHandler is a child service receiving all the requests satisfying its match conditions.
+type matchedHandler struct {
+	http.Handler
+	name  string
+	match *dynamic.WRRMatch
+}
+
 type stickyCookie struct {
 	name     string
 	secure   bool
@@ -37,6 +45,9 @@ type Balancer struct {
 	mutex       sync.RWMutex
 	handlers    []*namedHandler
 	curDeadline float64
+	// matchedHandlers are the child services that declared match conditions,
+	// in the order they were added. The first healthy one matching a request wins.
+	matchedHandlers []*matchedHandler
 	// status is a record of which child services of the Balancer are healthy, keyed
 	// by name of child service. A service is initially added to the map when it is
 	// created via AddService, and it is later removed or added to the map as needed,
@@ -100,7 +111,9 @@ func (b *Balancer) SetStatus(ctx context.Context, childName string, up bool) {
 	b.mutex.Lock()
 	defer b.mutex.Unlock()
 
-	upBefore := len(b.status) > 0
+	// Only the weighted handlers can serve all the requests,
+	// so the balancer is not up when only matched handlers are.
+	upBefore := b.weightedHandlerUp()
 
 	status := "DOWN"
 	if up {
@@ -113,7 +126,7 @@ func (b *Balancer) SetStatus(ctx context.Context, childName string, up bool) {
 		delete(b.status, childName)
 	}
 
-	upAfter := len(b.status) > 0
+	upAfter := b.weightedHandlerUp()
 	status = "DOWN"
 	if upAfter {
 		status = "UP"
@@ -153,7 +166,7 @@ func (b *Balancer) nextServer() (*namedHandler, error) {
 	if len(b.handlers) == 0 {
 		return nil, fmt.Errorf("no servers in the pool")
 	}
-	if len(b.status) == 0 {
+	if !b.weightedHandlerUp() {
 		return nil, errNoAvailableServer
 	}
 
@@ -176,7 +189,81 @@ func (b *Balancer) nextServer() (*namedHandler, error) {
 	return handler, nil
 }
 
+// weightedHandlerUp reports whether at least one of the weighted handlers is healthy.
+// The status map cannot be used alone, as it also records the matched-only handlers.
+// Must be called with the mutex held.
+func (b *Balancer) weightedHandlerUp() bool {
+	for _, handler := range b.handlers {
+		if _, ok := b.status[handler.name]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// matchedServer returns the first healthy matched handler whose conditions are satisfied by req, if any.
+func (b *Balancer) matchedServer(req *http.Request) *matchedHandler {
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
+
+	for _, handler := range b.matchedHandlers {
+		if _, ok := b.status[handler.name]; !ok {
+			continue
+		}
+
+		if matches(handler.match, req) {
+			return handler
+		}
+	}
+
+	return nil
+}
+
+func matches(match *dynamic.WRRMatch, req *http.Request) bool {
+	for name, value := range match.Headers {
+		if req.Header.Get(name) != value {
+			return false
+		}
+	}
+
+	for name, value := range match.Cookies {
+		cookie, err := req.Cookie(name)
+		if err != nil || cookie.Value != value {
+			return false
+		}
+	}
+
+	if match.Percentage != nil {
+		var value string
+		if match.Percentage.Header != "" {
+			value = req.Header.Get(match.Percentage.Header)
+		} else if cookie, err := req.Cookie(match.Percentage.Cookie); err == nil {
+			value = cookie.Value
+		}
+
+		if value == "" {
+			return false
+		}
+
+		hash := fnv.New32a()
+		_, _ = hash.Write([]byte(value))
+		if int(hash.Sum32()%100) >= match.Percentage.Value {
+			return false
+		}
+	}
+
+	return true
+}
+
 func (b *Balancer) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if len(b.matchedHandlers) > 0 {
+		if handler := b.matchedServer(req); handler != nil {
+			log.WithoutContext().Debugf("Service selected by WRR match: %s", handler.name)
+			handler.ServeHTTP(w, req)
+			return
+		}
+	}
+
 	if b.stickyCookie != nil {
 		cookie, err := req.Cookie(b.stickyCookie.name)
 
@@ -243,3 +330,15 @@ func (b *Balancer) AddService(name string, handler http.Handler, weight *int) {
 	b.status[name] = struct{}{}
 	b.mutex.Unlock()
 }
+
+// AddMatchedService adds a handler receiving the requests satisfying the given match conditions.
+// Such requests bypass the weights, whereas the other requests are load-balanced as usual,
+// so the handler can also be added with AddService to receive its weighted share of them.
+func (b *Balancer) AddMatchedService(name string, handler http.Handler, match *dynamic.WRRMatch) {
+	h := &matchedHandler{Handler: handler, name: name, match: match}
+
+	b.mutex.Lock()
+	b.matchedHandlers = append(b.matchedHandlers, h)
+	b.status[name] = struct{}{}
+	b.mutex.Unlock()
+}
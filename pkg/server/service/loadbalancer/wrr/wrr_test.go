@@ -2,11 +2,13 @@ package wrr
 
 import (
 	"context"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"github.com/traefik/traefik/v2/pkg/config/dynamic"
 )
 
@@ -290,3 +292,189 @@ func TestBalancerBias(t *testing.T) {
 
 	assert.Equal(t, wantSequence, recorder.sequence)
 }
+
+func TestBalancerMatch(t *testing.T) {
+	testCases := []struct {
+		desc    string
+		match   *dynamic.WRRMatch
+		headers map[string]string
+		cookies map[string]string
+		want    string
+	}{
+		{
+			desc:    "matching header",
+			match:   &dynamic.WRRMatch{Headers: map[string]string{"X-Canary": "true"}},
+			headers: map[string]string{"X-Canary": "true"},
+			want:    "canary",
+		},
+		{
+			desc:    "header with another value",
+			match:   &dynamic.WRRMatch{Headers: map[string]string{"X-Canary": "true"}},
+			headers: map[string]string{"X-Canary": "false"},
+			want:    "stable",
+		},
+		{
+			desc:    "matching cookie",
+			match:   &dynamic.WRRMatch{Cookies: map[string]string{"canary": "always"}},
+			cookies: map[string]string{"canary": "always"},
+			want:    "canary",
+		},
+		{
+			desc:  "missing cookie",
+			match: &dynamic.WRRMatch{Cookies: map[string]string{"canary": "always"}},
+			want:  "stable",
+		},
+		{
+			desc: "all conditions must match",
+			match: &dynamic.WRRMatch{
+				Headers: map[string]string{"X-Canary": "true"},
+				Cookies: map[string]string{"canary": "always"},
+			},
+			headers: map[string]string{"X-Canary": "true"},
+			want:    "stable",
+		},
+		{
+			desc:    "full percentage",
+			match:   &dynamic.WRRMatch{Percentage: &dynamic.WRRPercentage{Header: "X-User-Id", Value: 100}},
+			headers: map[string]string{"X-User-Id": "42"},
+			want:    "canary",
+		},
+		{
+			desc:    "zero percentage",
+			match:   &dynamic.WRRMatch{Percentage: &dynamic.WRRPercentage{Header: "X-User-Id", Value: 0}},
+			headers: map[string]string{"X-User-Id": "42"},
+			want:    "stable",
+		},
+		{
+			desc:  "percentage without hashed value",
+			match: &dynamic.WRRMatch{Percentage: &dynamic.WRRPercentage{Cookie: "user", Value: 100}},
+			want:  "stable",
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			balancer := New(nil, nil)
+
+			balancer.AddService("stable", http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				rw.Header().Set("server", "stable")
+				rw.WriteHeader(http.StatusOK)
+			}), Int(1))
+
+			balancer.AddMatchedService("canary", http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				rw.Header().Set("server", "canary")
+				rw.WriteHeader(http.StatusOK)
+			}), test.match)
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			for name, value := range test.headers {
+				req.Header.Set(name, value)
+			}
+			for name, value := range test.cookies {
+				req.AddCookie(&http.Cookie{Name: name, Value: value})
+			}
+
+			recorder := &responseRecorder{ResponseRecorder: httptest.NewRecorder(), save: map[string]int{}}
+			for i := 0; i < 3; i++ {
+				balancer.ServeHTTP(recorder, req)
+			}
+
+			assert.Equal(t, 3, recorder.save[test.want])
+		})
+	}
+}
+
+func TestBalancerMatchPercentageIsDeterministic(t *testing.T) {
+	balancer := New(nil, nil)
+
+	balancer.AddService("stable", http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.Header().Set("server", "stable")
+		rw.WriteHeader(http.StatusOK)
+	}), Int(1))
+
+	balancer.AddMatchedService("canary", http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.Header().Set("server", "canary")
+		rw.WriteHeader(http.StatusOK)
+	}), &dynamic.WRRMatch{Percentage: &dynamic.WRRPercentage{Header: "X-User-Id", Value: 50}})
+
+	for i := 0; i < 20; i++ {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("X-User-Id", fmt.Sprintf("user-%d", i))
+
+		recorder := &responseRecorder{ResponseRecorder: httptest.NewRecorder(), save: map[string]int{}}
+		for j := 0; j < 5; j++ {
+			balancer.ServeHTTP(recorder, req)
+		}
+
+		assert.Len(t, recorder.save, 1)
+	}
+}
+
+func TestBalancerMatchedServiceDown(t *testing.T) {
+	balancer := New(nil, &dynamic.HealthCheck{})
+
+	balancer.AddService("stable", http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.Header().Set("server", "stable")
+		rw.WriteHeader(http.StatusOK)
+	}), Int(1))
+
+	balancer.AddMatchedService("canary", http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.Header().Set("server", "canary")
+		rw.WriteHeader(http.StatusOK)
+	}), &dynamic.WRRMatch{Headers: map[string]string{"X-Canary": "true"}})
+
+	balancer.SetStatus(context.WithValue(context.Background(), serviceName, "parent"), "canary", false)
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("X-Canary", "true")
+
+	recorder := &responseRecorder{ResponseRecorder: httptest.NewRecorder(), save: map[string]int{}}
+	for i := 0; i < 3; i++ {
+		balancer.ServeHTTP(recorder, req)
+	}
+
+	assert.Equal(t, 0, recorder.save["canary"])
+	assert.Equal(t, 3, recorder.save["stable"])
+}
+
+func TestBalancerMatchedOnlyServiceUp(t *testing.T) {
+	balancer := New(nil, &dynamic.HealthCheck{})
+
+	balancer.AddService("stable", http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {}), Int(1))
+	balancer.AddMatchedService("canary", http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {}),
+		&dynamic.WRRMatch{Headers: map[string]string{"X-Canary": "true"}})
+
+	balancer.SetStatus(context.WithValue(context.Background(), serviceName, "parent"), "stable", false)
+
+	recorder := httptest.NewRecorder()
+	balancer.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	assert.Equal(t, http.StatusServiceUnavailable, recorder.Result().StatusCode)
+}
+
+func TestBalancerMatchedOnlyServiceStatusPropagation(t *testing.T) {
+	balancer := New(nil, &dynamic.HealthCheck{})
+
+	balancer.AddService("stable", http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {}), Int(1))
+	balancer.AddMatchedService("canary", http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {}),
+		&dynamic.WRRMatch{Headers: map[string]string{"X-Canary": "true"}})
+
+	var statuses []bool
+	err := balancer.RegisterStatusUpdater(func(up bool) {
+		statuses = append(statuses, up)
+	})
+	require.NoError(t, err)
+
+	ctx := context.WithValue(context.Background(), serviceName, "parent")
+
+	// The matched-only service being up does not keep the balancer up.
+	balancer.SetStatus(ctx, "stable", false)
+	balancer.SetStatus(ctx, "canary", false)
+	balancer.SetStatus(ctx, "canary", true)
+	balancer.SetStatus(ctx, "stable", true)
+
+	assert.Equal(t, []bool{false, true}, statuses)
+}
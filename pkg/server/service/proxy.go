@@ -15,6 +15,7 @@ import (
 	ptypes "github.com/traefik/paerser/types"
 	"github.com/traefik/traefik/v2/pkg/config/dynamic"
 	"github.com/traefik/traefik/v2/pkg/log"
+	"github.com/traefik/traefik/v2/pkg/server/service/loadbalancer/failover"
 	"golang.org/x/net/http/httpguts"
 )
 
@@ -87,7 +88,11 @@ func buildProxy(passHostHeader *bool, responseForwarding *dynamic.ResponseForwar
 		ErrorHandler: func(w http.ResponseWriter, request *http.Request, err error) {
 			statusCode := http.StatusInternalServerError
 
+			var maxBytesErr *http.MaxBytesError
+
 			switch {
+			case errors.As(err, &maxBytesErr):
+				statusCode = http.StatusRequestEntityTooLarge
 			case errors.Is(err, io.EOF):
 				statusCode = http.StatusBadGateway
 			case errors.Is(err, context.Canceled):
@@ -103,6 +108,10 @@ func buildProxy(passHostHeader *bool, responseForwarding *dynamic.ResponseForwar
 				}
 			}
 
+			if statusCode == http.StatusBadGateway || statusCode == http.StatusGatewayTimeout {
+				failover.ReportProxyError(request)
+			}
+
 			log.Debugf("'%d %s' caused by: %v", statusCode, statusText(statusCode), err)
 			w.WriteHeader(statusCode)
 			_, werr := w.Write([]byte(statusText(statusCode)))
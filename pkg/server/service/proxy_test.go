@@ -7,6 +7,8 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"github.com/traefik/traefik/v2/pkg/testhelpers"
 )
 
@@ -35,3 +37,24 @@ func BenchmarkProxy(b *testing.B) {
 		handler.ServeHTTP(w, req)
 	}
 }
+
+func TestProxyRequestBodyTooLarge(t *testing.T) {
+	backend := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		_, _ = io.Copy(io.Discard, req.Body)
+		rw.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(backend.Close)
+
+	handler, err := buildProxy(Bool(true), nil, http.DefaultTransport, newBufferPool())
+	require.NoError(t, err)
+
+	rw := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, backend.URL, io.NopCloser(strings.NewReader(strings.Repeat("a", 1024))))
+	req.RequestURI = ""
+	req.ContentLength = -1
+	req.Body = http.MaxBytesReader(rw, req.Body, 10)
+
+	handler.ServeHTTP(rw, req)
+
+	assert.Equal(t, http.StatusRequestEntityTooLarge, rw.Code)
+}
@@ -129,7 +129,7 @@ func TestKeepConnectionWhenSameConfiguration(t *testing.T) {
 	srv.TLS = &tls.Config{Certificates: []tls.Certificate{cert}}
 	srv.StartTLS()
 
-	rtManager := NewRoundTripperManager()
+	rtManager := NewRoundTripperManager(nil)
 
 	dynamicConf := map[string]*dynamic.ServersTransport{
 		"test": {
@@ -197,7 +197,7 @@ func TestMTLS(t *testing.T) {
 	}
 	srv.StartTLS()
 
-	rtManager := NewRoundTripperManager()
+	rtManager := NewRoundTripperManager(nil)
 
 	dynamicConf := map[string]*dynamic.ServersTransport{
 		"test": {
@@ -269,7 +269,7 @@ func TestDisableHTTP2(t *testing.T) {
 			srv.EnableHTTP2 = test.serverHTTP2
 			srv.StartTLS()
 
-			rtManager := NewRoundTripperManager()
+			rtManager := NewRoundTripperManager(nil)
 
 			dynamicConf := map[string]*dynamic.ServersTransport{
 				"test": {
@@ -293,3 +293,55 @@ func TestDisableHTTP2(t *testing.T) {
 		})
 	}
 }
+
+func TestSpiffe_invalidConfiguration(t *testing.T) {
+	testCases := []struct {
+		desc      string
+		withSrc   bool
+		transport *dynamic.ServersTransport
+	}{
+		{
+			desc:      "SPIFFE not configured",
+			transport: &dynamic.ServersTransport{Spiffe: &dynamic.Spiffe{}},
+		},
+		{
+			desc:      "with insecureSkipVerify",
+			withSrc:   true,
+			transport: &dynamic.ServersTransport{Spiffe: &dynamic.Spiffe{}, InsecureSkipVerify: true},
+		},
+		{
+			desc:      "with root CAs",
+			withSrc:   true,
+			transport: &dynamic.ServersTransport{Spiffe: &dynamic.Spiffe{}, RootCAs: []traefiktls.FileOrContent{traefiktls.FileOrContent(LocalhostCert)}},
+		},
+		{
+			desc:      "with peerCertURI",
+			withSrc:   true,
+			transport: &dynamic.ServersTransport{Spiffe: &dynamic.Spiffe{}, PeerCertURI: "spiffe://foo/bar"},
+		},
+		{
+			desc:      "invalid SPIFFE ID",
+			withSrc:   true,
+			transport: &dynamic.ServersTransport{Spiffe: &dynamic.Spiffe{IDs: []string{"foo"}}},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			var source traefiktls.SpiffeX509Source
+			if test.withSrc {
+				source = fakeSpiffeX509Source{}
+			}
+
+			_, err := NewRoundTripperManager(source).createRoundTripper(test.transport)
+			assert.Error(t, err)
+		})
+	}
+}
+
+type fakeSpiffeX509Source struct {
+	traefiktls.SpiffeX509Source
+}
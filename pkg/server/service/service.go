@@ -8,11 +8,13 @@ import (
 	"net/http/httputil"
 	"net/url"
 	"reflect"
+	"strings"
 	"time"
 
 	"github.com/containous/alice"
 	"github.com/traefik/traefik/v2/pkg/config/dynamic"
 	"github.com/traefik/traefik/v2/pkg/config/runtime"
+	"github.com/traefik/traefik/v2/pkg/dnsdiscovery"
 	"github.com/traefik/traefik/v2/pkg/healthcheck"
 	"github.com/traefik/traefik/v2/pkg/log"
 	"github.com/traefik/traefik/v2/pkg/metrics"
@@ -26,6 +28,7 @@ import (
 	"github.com/traefik/traefik/v2/pkg/server/service/loadbalancer/failover"
 	"github.com/traefik/traefik/v2/pkg/server/service/loadbalancer/mirror"
 	"github.com/traefik/traefik/v2/pkg/server/service/loadbalancer/wrr"
+	"github.com/traefik/traefik/v2/pkg/types"
 	"github.com/vulcand/oxy/roundrobin"
 	"github.com/vulcand/oxy/roundrobin/stickycookie"
 )
@@ -37,6 +40,10 @@ const (
 
 const defaultMaxBodySize int64 = -1
 
+// defaultFailoverMaxBodySize is the default maximum size of the request bodies buffered to be replayed by the failover,
+// as the bodies are held in memory.
+const defaultFailoverMaxBodySize int64 = 1 << 20
+
 // RoundTripperGetter is a roundtripper getter interface.
 type RoundTripperGetter interface {
 	Get(name string) (http.RoundTripper, error)
@@ -66,6 +73,8 @@ type Manager struct {
 	// which is why there is not just one Balancer per service name.
 	balancers map[string]healthcheck.Balancers
 	configs   map[string]*runtime.ServiceInfo
+	// dnsWatchers are the watchers of the servers discovered through DNS, returned by DNSWatchers.
+	dnsWatchers []*dnsdiscovery.Watcher
 }
 
 // BuildHTTP Creates a http.Handler for a service configuration.
@@ -161,20 +170,53 @@ func (m *Manager) getFailoverServiceHandler(ctx context.Context, serviceName str
 
 	f.SetFallbackHandler(fallbackHandler)
 
-	// Do not report the health of the fallback handler.
+	type fallback struct {
+		name    string
+		handler http.Handler
+		index   int
+	}
+
+	fallbacks := []fallback{{name: config.Fallback, handler: fallbackHandler}}
+	for _, name := range config.Fallbacks {
+		handler, err := m.BuildHTTP(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+
+		fallbacks = append(fallbacks, fallback{name: name, handler: handler, index: f.AddFallbackHandler(handler)})
+	}
+
+	if config.Errors != nil {
+		statusCodes, err := types.NewHTTPCodeRanges(config.Errors.Status)
+		if err != nil {
+			return nil, fmt.Errorf("invalid errors status for %v: %w", serviceName, err)
+		}
+
+		maxBodySize := defaultFailoverMaxBodySize
+		if config.Errors.MaxBodySize != nil {
+			maxBodySize = *config.Errors.MaxBodySize
+		}
+
+		f.SetErrors(statusCodes, maxBodySize)
+	}
+
+	// Do not report the health of the fallback handlers.
 	if config.HealthCheck == nil {
 		return f, nil
 	}
 
-	fallbackUpdater, ok := fallbackHandler.(healthcheck.StatusUpdater)
-	if !ok {
-		return nil, fmt.Errorf("child service %v of %v not a healthcheck.StatusUpdater (%T)", config.Fallback, serviceName, fallbackHandler)
-	}
+	for _, fb := range fallbacks {
+		fallbackUpdater, ok := fb.handler.(healthcheck.StatusUpdater)
+		if !ok {
+			return nil, fmt.Errorf("child service %v of %v not a healthcheck.StatusUpdater (%T)", fb.name, serviceName, fb.handler)
+		}
 
-	if err := fallbackUpdater.RegisterStatusUpdater(func(up bool) {
-		f.SetFallbackHandlerStatus(ctx, up)
-	}); err != nil {
-		return nil, fmt.Errorf("cannot register %v as updater for %v: %w", config.Fallback, serviceName, err)
+		index := fb.index
+		if err := fallbackUpdater.RegisterStatusUpdater(func(up bool) {
+			f.SetFallbackHandlerStatusAt(ctx, index, up)
+		}); err != nil {
+			return nil, fmt.Errorf("cannot register %v as updater for %v: %w", fb.name, serviceName, err)
+		}
 	}
 
 	return f, nil
@@ -220,6 +262,14 @@ func (m *Manager) getWRRServiceHandler(ctx context.Context, serviceName string,
 
 		balancer.AddService(service.Name, serviceHandler, service.Weight)
 
+		if service.Match != nil {
+			if err := validateWRRMatch(service.Match); err != nil {
+				return nil, fmt.Errorf("invalid match for child service %v of %v: %w", service.Name, serviceName, err)
+			}
+
+			balancer.AddMatchedService(service.Name, serviceHandler, service.Match)
+		}
+
 		if config.HealthCheck == nil {
 			continue
 		}
@@ -242,6 +292,26 @@ func (m *Manager) getWRRServiceHandler(ctx context.Context, serviceName string,
 	return balancer, nil
 }
 
+func validateWRRMatch(match *dynamic.WRRMatch) error {
+	if len(match.Headers) == 0 && len(match.Cookies) == 0 && match.Percentage == nil {
+		return errors.New("at least one condition must be defined")
+	}
+
+	if match.Percentage == nil {
+		return nil
+	}
+
+	if (match.Percentage.Header == "") == (match.Percentage.Cookie == "") {
+		return errors.New("percentage must define exactly one of header or cookie")
+	}
+
+	if match.Percentage.Value < 0 || match.Percentage.Value > 100 {
+		return fmt.Errorf("percentage value must be between 0 and 100, got %d", match.Percentage.Value)
+	}
+
+	return nil
+}
+
 func (m *Manager) getLoadBalancerServiceHandler(ctx context.Context, serviceName string, service *dynamic.ServersLoadBalancer) (http.Handler, error) {
 	if service.PassHostHeader == nil {
 		defaultPassHostHeader := true
@@ -407,9 +477,95 @@ func (m *Manager) getLoadBalancer(ctx context.Context, serviceName string, servi
 		return nil, fmt.Errorf("error configuring load balancer for service %s: %w", serviceName, err)
 	}
 
+	for _, dnsServer := range service.DNSServers {
+		watcher, err := newDNSWatcher(ctx, lbsu, service.Servers, dnsServer)
+		if err != nil {
+			return nil, fmt.Errorf("error configuring DNS discovery for service %s: %w", serviceName, err)
+		}
+
+		m.dnsWatchers = append(m.dnsWatchers, watcher)
+	}
+
 	return lbsu, nil
 }
 
+// newDNSWatcher creates a watcher keeping the servers of the load-balancer
+// in sync with the ones discovered through DNS.
+func newDNSWatcher(ctx context.Context, lb healthcheck.BalancerHandler, staticServers []dynamic.Server, dnsServer dynamic.DNSServer) (*dnsdiscovery.Watcher, error) {
+	logger := log.FromContext(ctx)
+
+	scheme := dnsServer.Scheme
+	if scheme == "" {
+		scheme = "http"
+	}
+
+	// Servers that are also defined statically are neither added again, nor removed when they are no longer discovered.
+	static := staticServerKeys(staticServers)
+
+	discovered := make(map[string]*url.URL)
+
+	return dnsdiscovery.NewWatcher(ctx, dnsServer, func(targets []dnsdiscovery.Target) {
+		current := make(map[string]*url.URL)
+		for _, target := range targets {
+			u := &url.URL{Scheme: scheme, Host: target.Address()}
+			if _, ok := static[serverKey(u)]; ok {
+				continue
+			}
+
+			current[serverKey(u)] = u
+		}
+
+		for key, u := range discovered {
+			if _, ok := current[key]; ok {
+				continue
+			}
+
+			logger.Debugf("Removing discovered server %s", u)
+			if err := lb.RemoveServer(u); err != nil {
+				logger.Errorf("Error removing discovered server %s from load balancer: %v", u, err)
+			}
+		}
+
+		for key, u := range current {
+			if _, ok := discovered[key]; ok {
+				continue
+			}
+
+			logger.Debugf("Creating discovered server %s", u)
+			if err := lb.UpsertServer(u, roundrobin.Weight(1)); err != nil {
+				logger.Errorf("Error adding discovered server %s to load balancer: %v", u, err)
+			}
+		}
+
+		discovered = current
+	})
+}
+
+// staticServerKeys returns the keys of the static servers, to be compared with the discovered ones.
+func staticServerKeys(servers []dynamic.Server) map[string]struct{} {
+	keys := make(map[string]struct{})
+	for _, srv := range servers {
+		u, err := url.Parse(srv.URL)
+		if err != nil {
+			continue
+		}
+
+		keys[serverKey(u)] = struct{}{}
+	}
+
+	return keys
+}
+
+// serverKey identifies a server by its scheme and host, whatever the path of its URL, such as a trailing slash.
+func serverKey(u *url.URL) string {
+	return strings.ToLower(u.Scheme) + "://" + strings.ToLower(u.Host)
+}
+
+// DNSWatchers returns the watchers of the servers discovered through DNS.
+func (m *Manager) DNSWatchers() []*dnsdiscovery.Watcher {
+	return m.dnsWatchers
+}
+
 func (m *Manager) upsertServers(ctx context.Context, lb healthcheck.BalancerHandler, servers []dynamic.Server) error {
 	logger := log.FromContext(ctx)
 
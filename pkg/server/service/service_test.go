@@ -4,6 +4,7 @@ import (
 	"context"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"strings"
 	"testing"
 
@@ -406,3 +407,20 @@ func TestMultipleTypeOnBuildHTTP(t *testing.T) {
 	_, err := manager.BuildHTTP(context.Background(), "test@file")
 	assert.Error(t, err, "cannot create service: multi-types service not supported, consider declaring two different pieces of service instead")
 }
+
+func TestStaticServerKeys(t *testing.T) {
+	static := staticServerKeys([]dynamic.Server{
+		{URL: "http://10.0.0.1:80/"},
+		{URL: "HTTPS://Backend.example.com:8443/api"},
+	})
+
+	for _, discovered := range []*url.URL{
+		{Scheme: "http", Host: "10.0.0.1:80"},
+		{Scheme: "https", Host: "backend.example.com:8443"},
+	} {
+		assert.Contains(t, static, serverKey(discovered))
+	}
+
+	assert.NotContains(t, static, serverKey(&url.URL{Scheme: "https", Host: "10.0.0.1:80"}))
+	assert.NotContains(t, static, serverKey(&url.URL{Scheme: "http", Host: "10.0.0.2:80"}))
+}
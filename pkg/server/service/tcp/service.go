@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/traefik/traefik/v2/pkg/config/runtime"
+	"github.com/traefik/traefik/v2/pkg/dnsdiscovery"
 	"github.com/traefik/traefik/v2/pkg/log"
 	"github.com/traefik/traefik/v2/pkg/server/provider"
 	"github.com/traefik/traefik/v2/pkg/tcp"
@@ -15,13 +16,17 @@ import (
 
 // Manager is the TCPHandlers factory.
 type Manager struct {
-	configs map[string]*runtime.TCPServiceInfo
+	dialerManager *tcp.DialerManager
+	configs       map[string]*runtime.TCPServiceInfo
+	// dnsWatchers are the watchers of the servers discovered through DNS, returned by DNSWatchers.
+	dnsWatchers []*dnsdiscovery.Watcher
 }
 
 // NewManager creates a new manager.
-func NewManager(conf *runtime.Configuration) *Manager {
+func NewManager(conf *runtime.Configuration, dialerManager *tcp.DialerManager) *Manager {
 	return &Manager{
-		configs: conf.TCPServices,
+		dialerManager: dialerManager,
+		configs:       conf.TCPServices,
 	}
 }
 
@@ -45,30 +50,80 @@ func (m *Manager) BuildTCP(rootCtx context.Context, serviceName string) (tcp.Han
 	logger := log.FromContext(ctx)
 	switch {
 	case conf.LoadBalancer != nil:
-		loadBalancer := tcp.NewWRRLoadBalancer()
-
 		if conf.LoadBalancer.TerminationDelay == nil {
 			defaultTerminationDelay := 100
 			conf.LoadBalancer.TerminationDelay = &defaultTerminationDelay
 		}
 		duration := time.Duration(*conf.LoadBalancer.TerminationDelay) * time.Millisecond
 
+		proxyProtocol := conf.LoadBalancer.ProxyProtocol
+
+		var dialer tcp.Dialer
+		if len(conf.LoadBalancer.ServersTransport) > 0 {
+			conf.LoadBalancer.ServersTransport = provider.GetQualifiedName(ctx, conf.LoadBalancer.ServersTransport)
+
+			var err error
+			dialer, err = m.dialerManager.Get(conf.LoadBalancer.ServersTransport)
+			if err != nil {
+				conf.AddError(err, true)
+				return nil, err
+			}
+
+			// The PROXY protocol configuration of the service takes precedence over the one of the servers transport.
+			if proxyProtocol == nil {
+				proxyProtocol = dialer.ProxyProtocol()
+			}
+		}
+
+		var addresses []string
 		for name, server := range conf.LoadBalancer.Servers {
 			if _, _, err := net.SplitHostPort(server.Address); err != nil {
 				logger.Errorf("In service %q: %v", serviceQualifiedName, err)
 				continue
 			}
 
-			handler, err := tcp.NewProxy(server.Address, duration, conf.LoadBalancer.ProxyProtocol)
+			addresses = append(addresses, server.Address)
+			logger.WithField(log.ServerName, name).Debugf("Creating TCP server %d at %s", name, server.Address)
+		}
+
+		buildLoadBalancer := func(addresses []string) *tcp.WRRLoadBalancer {
+			loadBalancer := tcp.NewWRRLoadBalancer()
+			for _, address := range addresses {
+				handler, err := tcp.NewProxy(address, duration, proxyProtocol, dialer)
+				if err != nil {
+					logger.Errorf("In service %q server %q: %v", serviceQualifiedName, address, err)
+					continue
+				}
+
+				loadBalancer.AddServer(handler)
+			}
+			return loadBalancer
+		}
+
+		if len(conf.LoadBalancer.DNSServers) == 0 {
+			return buildLoadBalancer(addresses), nil
+		}
+
+		switcher := &tcp.HandlerSwitcher{}
+		switcher.Switch(buildLoadBalancer(addresses))
+
+		discovered := dnsdiscovery.NewTargetSet(len(conf.LoadBalancer.DNSServers))
+		for i, dnsServer := range conf.LoadBalancer.DNSServers {
+			index := i
+			watcher, err := dnsdiscovery.NewWatcher(ctx, dnsServer, func(targets []dnsdiscovery.Target) {
+				discoveredAddresses := discovered.Set(index, targets)
+				switcher.Switch(buildLoadBalancer(append(append([]string{}, addresses...), discoveredAddresses...)))
+			})
 			if err != nil {
-				logger.Errorf("In service %q server %q: %v", serviceQualifiedName, server.Address, err)
-				continue
+				err = fmt.Errorf("error configuring DNS discovery: %w", err)
+				conf.AddError(err, true)
+				return nil, err
 			}
 
-			loadBalancer.AddServer(handler)
-			logger.WithField(log.ServerName, name).Debugf("Creating TCP server %d at %s", name, server.Address)
+			m.dnsWatchers = append(m.dnsWatchers, watcher)
 		}
-		return loadBalancer, nil
+
+		return switcher, nil
 	case conf.Weighted != nil:
 		loadBalancer := tcp.NewWRRLoadBalancer()
 		for _, service := range conf.Weighted.Services {
@@ -86,3 +141,8 @@ func (m *Manager) BuildTCP(rootCtx context.Context, serviceName string) (tcp.Han
 		return nil, err
 	}
 }
+
+// DNSWatchers returns the watchers of the servers discovered through DNS.
+func (m *Manager) DNSWatchers() []*dnsdiscovery.Watcher {
+	return m.dnsWatchers
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/traefik/traefik/v2/pkg/config/dynamic"
 	"github.com/traefik/traefik/v2/pkg/config/runtime"
 	"github.com/traefik/traefik/v2/pkg/server/provider"
+	"github.com/traefik/traefik/v2/pkg/tcp"
 )
 
 func TestManager_BuildTCP(t *testing.T) {
@@ -171,6 +172,45 @@ func TestManager_BuildTCP(t *testing.T) {
 			},
 			providerName: "provider-1",
 		},
+		{
+			desc:        "servers transport",
+			serviceName: "serviceName",
+			configs: map[string]*runtime.TCPServiceInfo{
+				"serviceName@provider-1": {
+					TCPService: &dynamic.TCPService{
+						LoadBalancer: &dynamic.TCPServersLoadBalancer{
+							Servers: []dynamic.TCPServer{
+								{
+									Address: "192.168.0.12:80",
+								},
+							},
+							ServersTransport: "foo",
+						},
+					},
+				},
+			},
+			providerName: "provider-1",
+		},
+		{
+			desc:        "unknown servers transport",
+			serviceName: "serviceName",
+			configs: map[string]*runtime.TCPServiceInfo{
+				"serviceName@provider-1": {
+					TCPService: &dynamic.TCPService{
+						LoadBalancer: &dynamic.TCPServersLoadBalancer{
+							Servers: []dynamic.TCPServer{
+								{
+									Address: "192.168.0.12:80",
+								},
+							},
+							ServersTransport: "bar",
+						},
+					},
+				},
+			},
+			providerName:  "provider-1",
+			expectedError: "TCP servers transport not found bar@provider-1",
+		},
 	}
 
 	for _, test := range testCases {
@@ -178,9 +218,14 @@ func TestManager_BuildTCP(t *testing.T) {
 		t.Run(test.desc, func(t *testing.T) {
 			t.Parallel()
 
+			dialerManager := tcp.NewDialerManager(nil)
+			dialerManager.Update(map[string]*dynamic.TCPServersTransport{
+				"foo@provider-1": {TLS: &dynamic.TLSClientConfig{}},
+			})
+
 			manager := NewManager(&runtime.Configuration{
 				TCPServices: test.configs,
-			})
+			}, dialerManager)
 
 			ctx := context.Background()
 			if len(test.providerName) > 0 {
@@ -7,6 +7,7 @@ import (
 	"net"
 
 	"github.com/traefik/traefik/v2/pkg/config/runtime"
+	"github.com/traefik/traefik/v2/pkg/dnsdiscovery"
 	"github.com/traefik/traefik/v2/pkg/log"
 	"github.com/traefik/traefik/v2/pkg/server/provider"
 	"github.com/traefik/traefik/v2/pkg/udp"
@@ -15,6 +16,8 @@ import (
 // Manager handles UDP services creation.
 type Manager struct {
 	configs map[string]*runtime.UDPServiceInfo
+	// dnsWatchers are the watchers of the servers discovered through DNS, returned by DNSWatchers.
+	dnsWatchers []*dnsdiscovery.Watcher
 }
 
 // NewManager creates a new manager.
@@ -44,24 +47,55 @@ func (m *Manager) BuildUDP(rootCtx context.Context, serviceName string) (udp.Han
 	logger := log.FromContext(ctx)
 	switch {
 	case conf.LoadBalancer != nil:
-		loadBalancer := udp.NewWRRLoadBalancer()
-
+		var addresses []string
 		for name, server := range conf.LoadBalancer.Servers {
 			if _, _, err := net.SplitHostPort(server.Address); err != nil {
 				logger.Errorf("In udp service %q: %v", serviceQualifiedName, err)
 				continue
 			}
 
-			handler, err := udp.NewProxy(server.Address)
+			addresses = append(addresses, server.Address)
+			logger.WithField(log.ServerName, name).Debugf("Creating UDP server %d at %s", name, server.Address)
+		}
+
+		buildLoadBalancer := func(addresses []string) *udp.WRRLoadBalancer {
+			loadBalancer := udp.NewWRRLoadBalancer()
+			for _, address := range addresses {
+				handler, err := udp.NewProxy(address)
+				if err != nil {
+					logger.Errorf("In udp service %q server %q: %v", serviceQualifiedName, address, err)
+					continue
+				}
+
+				loadBalancer.AddServer(handler)
+			}
+			return loadBalancer
+		}
+
+		if len(conf.LoadBalancer.DNSServers) == 0 {
+			return buildLoadBalancer(addresses), nil
+		}
+
+		switcher := &udp.HandlerSwitcher{}
+		switcher.Switch(buildLoadBalancer(addresses))
+
+		discovered := dnsdiscovery.NewTargetSet(len(conf.LoadBalancer.DNSServers))
+		for i, dnsServer := range conf.LoadBalancer.DNSServers {
+			index := i
+			watcher, err := dnsdiscovery.NewWatcher(ctx, dnsServer, func(targets []dnsdiscovery.Target) {
+				discoveredAddresses := discovered.Set(index, targets)
+				switcher.Switch(buildLoadBalancer(append(append([]string{}, addresses...), discoveredAddresses...)))
+			})
 			if err != nil {
-				logger.Errorf("In udp service %q server %q: %v", serviceQualifiedName, server.Address, err)
-				continue
+				err = fmt.Errorf("error configuring DNS discovery: %w", err)
+				conf.AddError(err, true)
+				return nil, err
 			}
 
-			loadBalancer.AddServer(handler)
-			logger.WithField(log.ServerName, name).Debugf("Creating UDP server %d at %s", name, server.Address)
+			m.dnsWatchers = append(m.dnsWatchers, watcher)
 		}
-		return loadBalancer, nil
+
+		return switcher, nil
 	case conf.Weighted != nil:
 		loadBalancer := udp.NewWRRLoadBalancer()
 		for _, service := range conf.Weighted.Services {
@@ -79,3 +113,8 @@ func (m *Manager) BuildUDP(rootCtx context.Context, serviceName string) (udp.Han
 		return nil, err
 	}
 }
+
+// DNSWatchers returns the watchers of the servers discovered through DNS.
+func (m *Manager) DNSWatchers() []*dnsdiscovery.Watcher {
+	return m.dnsWatchers
+}
@@ -0,0 +1,237 @@
+package tcp
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/pires/go-proxyproto"
+	"github.com/traefik/traefik/v2/pkg/config/dynamic"
+	"github.com/traefik/traefik/v2/pkg/log"
+	traefiktls "github.com/traefik/traefik/v2/pkg/tls"
+)
+
+// Dialer dials the connections to the TCP servers.
+type Dialer interface {
+	// Dial connects to the server, and sends the PROXY protocol header, if any, before the TLS handshake.
+	Dial(network, addr string, proxyHeader *proxyproto.Header) (net.Conn, error)
+	// ProxyProtocol returns the PROXY protocol configuration of the servers transport, if any.
+	ProxyProtocol() *dynamic.ProxyProtocol
+}
+
+// tcpDialer dials the connections to the TCP servers of a servers transport.
+type tcpDialer struct {
+	netDialer     *net.Dialer
+	tlsConfig     *tls.Config
+	proxyProtocol *dynamic.ProxyProtocol
+}
+
+func (d *tcpDialer) ProxyProtocol() *dynamic.ProxyProtocol {
+	return d.proxyProtocol
+}
+
+func (d *tcpDialer) Dial(network, addr string, proxyHeader *proxyproto.Header) (net.Conn, error) {
+	conn, err := d.netDialer.Dial(network, addr)
+	if err != nil {
+		return nil, err
+	}
+
+	if proxyHeader != nil {
+		if _, err := proxyHeader.WriteTo(conn); err != nil {
+			_ = conn.Close()
+			return nil, fmt.Errorf("error while writing proxy protocol headers to backend connection: %w", err)
+		}
+	}
+
+	if d.tlsConfig == nil {
+		return conn, nil
+	}
+
+	tlsConfig := d.tlsConfig
+	if tlsConfig.ServerName == "" {
+		host, _, err := net.SplitHostPort(addr)
+		if err != nil {
+			_ = conn.Close()
+			return nil, err
+		}
+
+		tlsConfig = tlsConfig.Clone()
+		tlsConfig.ServerName = host
+	}
+
+	tlsConn := tls.Client(conn, tlsConfig)
+
+	if d.netDialer.Timeout > 0 {
+		if err := tlsConn.SetDeadline(time.Now().Add(d.netDialer.Timeout)); err != nil {
+			_ = conn.Close()
+			return nil, err
+		}
+	}
+
+	if err := tlsConn.Handshake(); err != nil {
+		_ = conn.Close()
+		return nil, fmt.Errorf("TLS handshake with %s: %w", addr, err)
+	}
+
+	if err := tlsConn.SetDeadline(time.Time{}); err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+
+	return tlsConn, nil
+}
+
+// NewDialerManager creates a new DialerManager.
+// The spiffeX509Source is nil when the SPIFFE integration is not configured.
+func NewDialerManager(spiffeX509Source traefiktls.SpiffeX509Source) *DialerManager {
+	return &DialerManager{
+		dialers:          make(map[string]Dialer),
+		configs:          make(map[string]*dynamic.TCPServersTransport),
+		spiffeX509Source: spiffeX509Source,
+	}
+}
+
+// DialerManager handles the dialers of the TCP servers transports.
+type DialerManager struct {
+	dialersLock sync.RWMutex
+	dialers     map[string]Dialer
+	configs     map[string]*dynamic.TCPServersTransport
+
+	spiffeX509Source traefiktls.SpiffeX509Source
+}
+
+// Update updates the dialers configurations.
+func (d *DialerManager) Update(newConfigs map[string]*dynamic.TCPServersTransport) {
+	d.dialersLock.Lock()
+	defer d.dialersLock.Unlock()
+
+	dialers := make(map[string]Dialer)
+	for name, newConfig := range newConfigs {
+		if dialer, ok := d.dialers[name]; ok && reflect.DeepEqual(newConfig, d.configs[name]) {
+			dialers[name] = dialer
+			continue
+		}
+
+		dialer, err := d.createDialer(newConfig)
+		if err != nil {
+			// The services referencing the transport fail to be built, rather than connecting to the servers without the expected security.
+			log.WithoutContext().Errorf("Could not configure TCP servers transport %s: %v", name, err)
+			continue
+		}
+
+		dialers[name] = dialer
+	}
+
+	d.dialers = dialers
+	d.configs = newConfigs
+}
+
+// Get gets a dialer by name.
+func (d *DialerManager) Get(name string) (Dialer, error) {
+	d.dialersLock.RLock()
+	defer d.dialersLock.RUnlock()
+
+	if dialer, ok := d.dialers[name]; ok {
+		return dialer, nil
+	}
+
+	return nil, fmt.Errorf("TCP servers transport not found %s", name)
+}
+
+func (d *DialerManager) createDialer(cfg *dynamic.TCPServersTransport) (*tcpDialer, error) {
+	if cfg == nil {
+		return nil, errors.New("no transport configuration given")
+	}
+
+	if cfg.ProxyProtocol != nil && (cfg.ProxyProtocol.Version < 1 || cfg.ProxyProtocol.Version > 2) {
+		return nil, fmt.Errorf("unknown proxyProtocol version: %d", cfg.ProxyProtocol.Version)
+	}
+
+	dialer := &tcpDialer{
+		netDialer: &net.Dialer{
+			Timeout:   time.Duration(cfg.DialTimeout),
+			KeepAlive: time.Duration(cfg.DialKeepAlive),
+		},
+		proxyProtocol: cfg.ProxyProtocol,
+	}
+
+	if cfg.TLS == nil {
+		return dialer, nil
+	}
+
+	tlsConfig, err := d.createTLSConfig(cfg.TLS)
+	if err != nil {
+		return nil, err
+	}
+
+	dialer.tlsConfig = tlsConfig
+
+	return dialer, nil
+}
+
+func (d *DialerManager) createTLSConfig(cfg *dynamic.TLSClientConfig) (*tls.Config, error) {
+	if cfg.Spiffe != nil {
+		if d.spiffeX509Source == nil {
+			return nil, errors.New("SPIFFE is enabled for this transport, but not configured")
+		}
+
+		if cfg.InsecureSkipVerify || len(cfg.RootCAs) > 0 || len(cfg.Certificates) > 0 || cfg.PeerCertURI != "" {
+			return nil, errors.New("SPIFFE cannot be used with the insecureSkipVerify, rootCAs, certificates and peerCertURI options")
+		}
+
+		tlsConfig, err := traefiktls.NewSpiffeClientConfig(d.spiffeX509Source, cfg.Spiffe.IDs, cfg.Spiffe.TrustDomain)
+		if err != nil {
+			return nil, fmt.Errorf("unable to build SPIFFE TLS configuration: %w", err)
+		}
+
+		tlsConfig.ServerName = cfg.ServerName
+
+		return tlsConfig, nil
+	}
+
+	rootCAs, err := createRootCACertPool(cfg.RootCAs)
+	if err != nil {
+		return nil, err
+	}
+
+	tlsConfig := &tls.Config{
+		ServerName:         cfg.ServerName,
+		InsecureSkipVerify: cfg.InsecureSkipVerify,
+		RootCAs:            rootCAs,
+		Certificates:       cfg.Certificates.GetCertificates(),
+	}
+
+	if cfg.PeerCertURI != "" {
+		tlsConfig.VerifyPeerCertificate = func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+			return traefiktls.VerifyPeerCertificate(cfg.PeerCertURI, tlsConfig, rawCerts)
+		}
+	}
+
+	return tlsConfig, nil
+}
+
+func createRootCACertPool(rootCAs []traefiktls.FileOrContent) (*x509.CertPool, error) {
+	if len(rootCAs) == 0 {
+		return nil, nil
+	}
+
+	roots := x509.NewCertPool()
+
+	for _, cert := range rootCAs {
+		certContent, err := cert.Read()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read root CA: %w", err)
+		}
+
+		if !roots.AppendCertsFromPEM(certContent) {
+			return nil, errors.New("failed to parse root CA")
+		}
+	}
+
+	return roots, nil
+}
@@ -0,0 +1,392 @@
+package tcp
+
+import (
+	"bufio"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"math/big"
+	"net"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/pires/go-proxyproto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/traefik/traefik/v2/pkg/config/dynamic"
+	traefiktls "github.com/traefik/traefik/v2/pkg/tls"
+)
+
+func TestDialerManager(t *testing.T) {
+	dialerManager := NewDialerManager(nil)
+
+	dialerManager.Update(map[string]*dynamic.TCPServersTransport{
+		"plain":         {},
+		"tls":           {TLS: &dynamic.TLSClientConfig{}},
+		"spiffe":        {TLS: &dynamic.TLSClientConfig{Spiffe: &dynamic.Spiffe{}}},
+		"proxyProtocol": {ProxyProtocol: &dynamic.ProxyProtocol{Version: 3}},
+		"rootCAs":       {TLS: &dynamic.TLSClientConfig{RootCAs: []traefiktls.FileOrContent{"foo"}}},
+	})
+
+	_, err := dialerManager.Get("plain")
+	require.NoError(t, err)
+
+	tlsDialer, err := dialerManager.Get("tls")
+	require.NoError(t, err)
+
+	// The SPIFFE transport cannot be created without the SPIFFE integration.
+	_, err = dialerManager.Get("spiffe")
+	assert.Error(t, err)
+
+	_, err = dialerManager.Get("proxyProtocol")
+	assert.Error(t, err)
+
+	_, err = dialerManager.Get("rootCAs")
+	assert.Error(t, err)
+
+	_, err = dialerManager.Get("unknown")
+	assert.Error(t, err)
+
+	dialerManager.Update(map[string]*dynamic.TCPServersTransport{
+		"tls": {TLS: &dynamic.TLSClientConfig{}},
+	})
+
+	// The dialers of the unchanged transports are kept.
+	dialer, err := dialerManager.Get("tls")
+	require.NoError(t, err)
+	assert.Same(t, tlsDialer, dialer)
+
+	_, err = dialerManager.Get("plain")
+	assert.Error(t, err)
+}
+
+func TestDialer_TLS(t *testing.T) {
+	ca := newTestCA(t)
+	serverCert, serverKey := ca.issue(t, "backend.test", "spiffe://backend.test/server")
+	clientCert, clientKey := ca.issue(t, "client.test", "")
+
+	testCases := []struct {
+		desc              string
+		transport         *dynamic.TCPServersTransport
+		clientAuth        bool
+		proxyProtocol     bool
+		expectedError     bool
+		expectedSNI       string
+		expectedProxyAddr string
+	}{
+		{
+			desc: "verified with the root CAs",
+			transport: &dynamic.TCPServersTransport{TLS: &dynamic.TLSClientConfig{
+				ServerName: "backend.test",
+				RootCAs:    []traefiktls.FileOrContent{traefiktls.FileOrContent(ca.certPEM)},
+			}},
+			expectedSNI: "backend.test",
+		},
+		{
+			desc: "unknown authority",
+			transport: &dynamic.TCPServersTransport{TLS: &dynamic.TLSClientConfig{
+				ServerName: "backend.test",
+			}},
+			expectedError: true,
+		},
+		{
+			desc: "server name mismatch",
+			transport: &dynamic.TCPServersTransport{TLS: &dynamic.TLSClientConfig{
+				ServerName: "other.test",
+				RootCAs:    []traefiktls.FileOrContent{traefiktls.FileOrContent(ca.certPEM)},
+			}},
+			expectedError: true,
+		},
+		{
+			desc: "insecure skip verify",
+			transport: &dynamic.TCPServersTransport{TLS: &dynamic.TLSClientConfig{
+				InsecureSkipVerify: true,
+			}},
+			expectedSNI: "",
+		},
+		{
+			desc: "client certificate",
+			transport: &dynamic.TCPServersTransport{TLS: &dynamic.TLSClientConfig{
+				ServerName:   "backend.test",
+				RootCAs:      []traefiktls.FileOrContent{traefiktls.FileOrContent(ca.certPEM)},
+				Certificates: traefiktls.Certificates{{CertFile: traefiktls.FileOrContent(clientCert), KeyFile: traefiktls.FileOrContent(clientKey)}},
+			}},
+			clientAuth:  true,
+			expectedSNI: "backend.test",
+		},
+		{
+			desc: "missing client certificate",
+			transport: &dynamic.TCPServersTransport{TLS: &dynamic.TLSClientConfig{
+				ServerName: "backend.test",
+				RootCAs:    []traefiktls.FileOrContent{traefiktls.FileOrContent(ca.certPEM)},
+			}},
+			clientAuth:    true,
+			expectedError: true,
+		},
+		{
+			desc: "peer certificate URI",
+			transport: &dynamic.TCPServersTransport{TLS: &dynamic.TLSClientConfig{
+				InsecureSkipVerify: true,
+				RootCAs:            []traefiktls.FileOrContent{traefiktls.FileOrContent(ca.certPEM)},
+				PeerCertURI:        "spiffe://backend.test/server",
+			}},
+			expectedSNI: "",
+		},
+		{
+			desc: "peer certificate URI mismatch",
+			transport: &dynamic.TCPServersTransport{TLS: &dynamic.TLSClientConfig{
+				InsecureSkipVerify: true,
+				RootCAs:            []traefiktls.FileOrContent{traefiktls.FileOrContent(ca.certPEM)},
+				PeerCertURI:        "spiffe://backend.test/other",
+			}},
+			expectedError: true,
+		},
+		{
+			desc: "PROXY protocol header sent before the TLS handshake",
+			transport: &dynamic.TCPServersTransport{
+				ProxyProtocol: &dynamic.ProxyProtocol{Version: 2},
+				TLS: &dynamic.TLSClientConfig{
+					ServerName: "backend.test",
+					RootCAs:    []traefiktls.FileOrContent{traefiktls.FileOrContent(ca.certPEM)},
+				},
+			},
+			proxyProtocol:     true,
+			expectedSNI:       "backend.test",
+			expectedProxyAddr: "10.0.0.1:4242",
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			cert, err := tls.X509KeyPair(serverCert, serverKey)
+			require.NoError(t, err)
+
+			serverConfig := &tls.Config{Certificates: []tls.Certificate{cert}}
+			if test.clientAuth {
+				serverConfig.ClientAuth = tls.RequireAndVerifyClientCert
+				serverConfig.ClientCAs = x509.NewCertPool()
+				serverConfig.ClientCAs.AddCert(ca.cert)
+			}
+
+			backendAddr, remoteAddrs := startTLSBackend(t, serverConfig, test.proxyProtocol)
+
+			dialerManager := NewDialerManager(nil)
+			dialerManager.Update(map[string]*dynamic.TCPServersTransport{"test": test.transport})
+
+			dialer, err := dialerManager.Get("test")
+			require.NoError(t, err)
+
+			proxy, err := NewProxy(backendAddr, 10*time.Millisecond, dialer.ProxyProtocol(), dialer)
+			require.NoError(t, err)
+
+			conn, err := proxy.dialBackend(fakeClientConn{
+				remoteAddr: &net.TCPAddr{IP: net.IPv4(10, 0, 0, 1), Port: 4242},
+				localAddr:  &net.TCPAddr{IP: net.IPv4(10, 0, 0, 2), Port: 443},
+			})
+
+			// With TLS 1.3, the server rejects the client certificate after the client completes the handshake.
+			var sni []byte
+			if err == nil {
+				_, err = conn.Write([]byte("ping"))
+				require.NoError(t, err)
+				require.NoError(t, conn.CloseWrite())
+
+				sni, err = io.ReadAll(conn)
+				_ = conn.Close()
+			}
+
+			if test.expectedError {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			assert.Equal(t, test.expectedSNI, string(sni))
+
+			if test.proxyProtocol {
+				assert.Equal(t, test.expectedProxyAddr, <-remoteAddrs)
+			}
+		})
+	}
+}
+
+func TestDialer_ProxyProtocolBeforeTLSHandshake(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = listener.Close() })
+
+	dialerManager := NewDialerManager(nil)
+	dialerManager.Update(map[string]*dynamic.TCPServersTransport{"test": {
+		ProxyProtocol: &dynamic.ProxyProtocol{Version: 2},
+		TLS:           &dynamic.TLSClientConfig{InsecureSkipVerify: true},
+	}})
+
+	dialer, err := dialerManager.Get("test")
+	require.NoError(t, err)
+
+	header := proxyproto.HeaderProxyFromAddrs(2,
+		&net.TCPAddr{IP: net.IPv4(10, 0, 0, 1), Port: 4242},
+		&net.TCPAddr{IP: net.IPv4(10, 0, 0, 2), Port: 443},
+	)
+
+	go func() {
+		// The handshake fails as the server closes the connection without answering.
+		conn, err := dialer.Dial("tcp", listener.Addr().String(), header)
+		if err == nil {
+			_ = conn.Close()
+		}
+	}()
+
+	conn, err := listener.Accept()
+	require.NoError(t, err)
+	defer conn.Close()
+
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+
+	reader := bufio.NewReader(conn)
+
+	received, err := proxyproto.Read(reader)
+	require.NoError(t, err)
+	assert.Equal(t, "10.0.0.1:4242", received.SourceAddr.String())
+	assert.Equal(t, "10.0.0.2:443", received.DestinationAddr.String())
+
+	// The PROXY protocol header is followed by the TLS ClientHello, a handshake record.
+	recordType, err := reader.ReadByte()
+	require.NoError(t, err)
+	assert.Equal(t, byte(0x16), recordType)
+}
+
+// startTLSBackend starts a TLS server answering with the server name requested by the clients.
+// The remote addresses of the clients are sent to the returned channel.
+func startTLSBackend(t *testing.T, config *tls.Config, proxyProtocol bool) (string, <-chan string) {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = listener.Close() })
+
+	var rawListener net.Listener = listener
+	if proxyProtocol {
+		rawListener = &proxyproto.Listener{Listener: listener}
+	}
+
+	remoteAddrs := make(chan string, 1)
+	go func() {
+		for {
+			conn, err := rawListener.Accept()
+			if err != nil {
+				return
+			}
+
+			go func() {
+				defer conn.Close()
+
+				tlsConn := tls.Server(conn, config)
+				if _, err := io.ReadAll(tlsConn); err != nil {
+					return
+				}
+
+				remoteAddrs <- conn.RemoteAddr().String()
+
+				_, _ = tlsConn.Write([]byte(tlsConn.ConnectionState().ServerName))
+			}()
+		}
+	}()
+
+	return listener.Addr().String(), remoteAddrs
+}
+
+type fakeClientConn struct {
+	WriteCloser
+
+	remoteAddr net.Addr
+	localAddr  net.Addr
+}
+
+func (c fakeClientConn) RemoteAddr() net.Addr {
+	return c.remoteAddr
+}
+
+func (c fakeClientConn) LocalAddr() net.Addr {
+	return c.localAddr
+}
+
+type testCA struct {
+	cert    *x509.Certificate
+	certPEM []byte
+	key     *ecdsa.PrivateKey
+}
+
+func newTestCA(t *testing.T) *testCA {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "Test CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, key.Public(), key)
+	require.NoError(t, err)
+
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	return &testCA{
+		cert:    cert,
+		certPEM: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		key:     key,
+	}
+}
+
+// issue returns the PEM encoded certificate and key for the given DNS name and URI SAN.
+func (ca *testCA) issue(t *testing.T, dnsName, uri string) ([]byte, []byte) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	serial, err := rand.Int(rand.Reader, big.NewInt(1<<62))
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: dnsName},
+		DNSNames:     []string{dnsName},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+
+	if uri != "" {
+		u, err := url.Parse(uri)
+		require.NoError(t, err)
+		template.URIs = []*url.URL{u}
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.cert, key.Public(), ca.key)
+	require.NoError(t, err)
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+}
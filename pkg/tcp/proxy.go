@@ -17,10 +17,12 @@ type Proxy struct {
 	tcpAddr          *net.TCPAddr
 	terminationDelay time.Duration
 	proxyProtocol    *dynamic.ProxyProtocol
+	dialer           Dialer
 }
 
 // NewProxy creates a new Proxy.
-func NewProxy(address string, terminationDelay time.Duration, proxyProtocol *dynamic.ProxyProtocol) (*Proxy, error) {
+// The dialer is optional, and dials the connections to the server instead of the plain TCP dial.
+func NewProxy(address string, terminationDelay time.Duration, proxyProtocol *dynamic.ProxyProtocol, dialer Dialer) (*Proxy, error) {
 	if proxyProtocol != nil && (proxyProtocol.Version < 1 || proxyProtocol.Version > 2) {
 		return nil, fmt.Errorf("unknown proxyProtocol version: %d", proxyProtocol.Version)
 	}
@@ -41,6 +43,7 @@ func NewProxy(address string, terminationDelay time.Duration, proxyProtocol *dyn
 		tcpAddr:          tcpAddr,
 		terminationDelay: terminationDelay,
 		proxyProtocol:    proxyProtocol,
+		dialer:           dialer,
 	}, nil
 }
 
@@ -51,7 +54,7 @@ func (p *Proxy) ServeTCP(conn WriteCloser) {
 	// needed because of e.g. server.trackedConnection
 	defer conn.Close()
 
-	connBackend, err := p.dialBackend()
+	connBackend, err := p.dialBackend(conn)
 	if err != nil {
 		log.WithoutContext().Errorf("Error while connecting to backend: %v", err)
 		return
@@ -61,14 +64,6 @@ func (p *Proxy) ServeTCP(conn WriteCloser) {
 	defer connBackend.Close()
 	errChan := make(chan error)
 
-	if p.proxyProtocol != nil && p.proxyProtocol.Version > 0 && p.proxyProtocol.Version < 3 {
-		header := proxyproto.HeaderProxyFromAddrs(byte(p.proxyProtocol.Version), conn.RemoteAddr(), conn.LocalAddr())
-		if _, err := header.WriteTo(connBackend); err != nil {
-			log.WithoutContext().Errorf("Error while writing proxy protocol headers to backend connection: %v", err)
-			return
-		}
-	}
-
 	go p.connCopy(conn, connBackend, errChan)
 	go p.connCopy(connBackend, conn, errChan)
 
@@ -80,7 +75,43 @@ func (p *Proxy) ServeTCP(conn WriteCloser) {
 	<-errChan
 }
 
-func (p Proxy) dialBackend() (*net.TCPConn, error) {
+func (p Proxy) dialBackend(conn WriteCloser) (WriteCloser, error) {
+	var proxyHeader *proxyproto.Header
+	if p.proxyProtocol != nil && p.proxyProtocol.Version > 0 && p.proxyProtocol.Version < 3 {
+		proxyHeader = proxyproto.HeaderProxyFromAddrs(byte(p.proxyProtocol.Version), conn.RemoteAddr(), conn.LocalAddr())
+	}
+
+	if p.dialer != nil {
+		connBackend, err := p.dialer.Dial("tcp", p.address, proxyHeader)
+		if err != nil {
+			return nil, err
+		}
+
+		writeCloser, ok := connBackend.(WriteCloser)
+		if !ok {
+			_ = connBackend.Close()
+			return nil, fmt.Errorf("connection to %s does not support half-closing", p.address)
+		}
+
+		return writeCloser, nil
+	}
+
+	connBackend, err := p.dialTCP()
+	if err != nil {
+		return nil, err
+	}
+
+	if proxyHeader != nil {
+		if _, err := proxyHeader.WriteTo(connBackend); err != nil {
+			_ = connBackend.Close()
+			return nil, fmt.Errorf("error while writing proxy protocol headers to backend connection: %w", err)
+		}
+	}
+
+	return connBackend, nil
+}
+
+func (p Proxy) dialTCP() (*net.TCPConn, error) {
 	// Dial using directly the TCPAddr for IP based addresses.
 	if p.tcpAddr != nil {
 		return net.DialTCP("tcp", nil, p.tcpAddr)
@@ -117,7 +117,7 @@ func (c *Certificates) CreateTLSConfig(entryPointName string) (*tls.Config, erro
 		config.Certificates = append(config.Certificates, *cert)
 	} else {
 		for _, certificate := range *c {
-			err := certificate.AppendCertificate(domainsCertificates, entryPointName)
+			_, err := certificate.AppendCertificate(domainsCertificates, entryPointName)
 			if err != nil {
 				log.Errorf("Unable to add a certificate to the entryPoint %q : %v", entryPointName, err)
 				continue
@@ -149,19 +149,20 @@ func (c *Certificates) isEmpty() bool {
 }
 
 // AppendCertificate appends a Certificate to a certificates map keyed by entrypoint.
-func (c *Certificate) AppendCertificate(certs map[string]map[string]*tls.Certificate, ep string) error {
+// It returns the appended certificate, or nil if a certificate for the same domains already exists.
+func (c *Certificate) AppendCertificate(certs map[string]map[string]*tls.Certificate, ep string) (*tls.Certificate, error) {
 	certContent, err := c.CertFile.Read()
 	if err != nil {
-		return fmt.Errorf("unable to read CertFile : %w", err)
+		return nil, fmt.Errorf("unable to read CertFile : %w", err)
 	}
 
 	keyContent, err := c.KeyFile.Read()
 	if err != nil {
-		return fmt.Errorf("unable to read KeyFile : %w", err)
+		return nil, fmt.Errorf("unable to read KeyFile : %w", err)
 	}
 	tlsCert, err := tls.X509KeyPair(certContent, keyContent)
 	if err != nil {
-		return fmt.Errorf("unable to generate TLS certificate : %w", err)
+		return nil, fmt.Errorf("unable to generate TLS certificate : %w", err)
 	}
 
 	parsedCert, _ := x509.ParseCertificate(tlsCert.Certificate[0])
@@ -200,12 +201,13 @@ func (c *Certificate) AppendCertificate(certs map[string]map[string]*tls.Certifi
 	}
 	if certExists {
 		log.Debugf("Skipping addition of certificate for domain(s) %q, to EntryPoint %s, as it already exists for this Entrypoint.", certKey, ep)
-	} else {
-		log.Debugf("Adding certificate for domain(s) %s", certKey)
-		certs[ep][certKey] = &tlsCert
+		return nil, nil
 	}
 
-	return err
+	log.Debugf("Adding certificate for domain(s) %s", certKey)
+	certs[ep][certKey] = &tlsCert
+
+	return &tlsCert, nil
 }
 
 // GetCertificate retrieves Certificate as tls.Certificate.
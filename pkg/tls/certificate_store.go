@@ -1,11 +1,13 @@
 package tls
 
 import (
+	"context"
 	"crypto/tls"
 	"crypto/x509"
 	"net"
 	"sort"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/patrickmn/go-cache"
@@ -13,22 +15,33 @@ import (
 	"github.com/traefik/traefik/v2/pkg/safe"
 )
 
+// CertificateObtainer obtains, during the TLS handshake, a certificate for a server name not matched by the certificates of a store.
+// It returns a nil certificate if no certificate can be obtained for this server name.
+type CertificateObtainer func(ctx context.Context, serverName string) (*tls.Certificate, error)
+
 // CertificateStore store for dynamic certificates.
 type CertificateStore struct {
 	DynamicCerts       *safe.Safe
 	DefaultCertificate *tls.Certificate
 	CertCache          *cache.Cache
+
+	// obtainer holds the CertificateObtainer, which can be set during the TLS handshakes.
+	obtainer atomic.Value
+	// obtainedCerts holds the certificates obtained during the TLS handshakes.
+	// Unlike CertCache, it is not flushed when the certificates are reloaded.
+	obtainedCerts *cache.Cache
 }
 
 // NewCertificateStore create a store for dynamic certificates.
 func NewCertificateStore() *CertificateStore {
 	return &CertificateStore{
-		DynamicCerts: &safe.Safe{},
-		CertCache:    cache.New(1*time.Hour, 10*time.Minute),
+		DynamicCerts:  &safe.Safe{},
+		CertCache:     cache.New(1*time.Hour, 10*time.Minute),
+		obtainedCerts: cache.New(1*time.Hour, 10*time.Minute),
 	}
 }
 
-func (c CertificateStore) getDefaultCertificateDomains() []string {
+func (c *CertificateStore) getDefaultCertificateDomains() []string {
 	var allCerts []string
 
 	if c.DefaultCertificate == nil {
@@ -55,7 +68,7 @@ func (c CertificateStore) getDefaultCertificateDomains() []string {
 }
 
 // GetAllDomains return a slice with all the certificate domain.
-func (c CertificateStore) GetAllDomains() []string {
+func (c *CertificateStore) GetAllDomains() []string {
 	allDomains := c.getDefaultCertificateDomains()
 
 	// Get dynamic certificates
@@ -111,11 +124,47 @@ func (c *CertificateStore) GetBestCertificate(clientHello *tls.ClientHelloInfo)
 		return matchedCerts[keys[len(keys)-1]]
 	}
 
+	// The certificates are only obtained for the server names provided by the clients.
+	obtainer := c.getObtainer()
+	if obtainer != nil && len(clientHello.ServerName) > 0 {
+		if cert, ok := c.obtainedCerts.Get(serverName); ok {
+			c.CertCache.SetDefault(serverName, cert)
+			return cert.(*tls.Certificate)
+		}
+
+		ctx := context.Background()
+		if clientHello.Context() != nil {
+			ctx = clientHello.Context()
+		}
+
+		cert, err := obtainer(ctx, serverName)
+		if err != nil {
+			log.WithoutContext().Debugf("Unable to obtain a certificate for %q: %v", serverName, err)
+			return nil
+		}
+
+		if cert != nil {
+			c.obtainedCerts.SetDefault(serverName, cert)
+			c.CertCache.SetDefault(serverName, cert)
+			return cert
+		}
+	}
+
 	return nil
 }
 
+// SetObtainer sets the obtainer called for the server names not matched by the certificates of the store.
+func (c *CertificateStore) SetObtainer(obtainer CertificateObtainer) {
+	c.obtainer.Store(obtainer)
+}
+
+func (c *CertificateStore) getObtainer() CertificateObtainer {
+	obtainer, _ := c.obtainer.Load().(CertificateObtainer)
+	return obtainer
+}
+
 // ResetCache clears the cache in the store.
-func (c CertificateStore) ResetCache() {
+func (c *CertificateStore) ResetCache() {
 	if c.CertCache != nil {
 		c.CertCache.Flush()
 	}
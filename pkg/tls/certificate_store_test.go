@@ -1,6 +1,7 @@
 package tls
 
 import (
+	"context"
 	"crypto/tls"
 	"fmt"
 	"strings"
@@ -89,6 +90,65 @@ func TestGetBestCertificate(t *testing.T) {
 	}
 }
 
+func TestGetBestCertificate_Obtainer(t *testing.T) {
+	dynamicCert, err := loadTestCert("snitest.com", false)
+	require.NoError(t, err)
+
+	obtainedCert, err := loadTestCert("snitest.org", false)
+	require.NoError(t, err)
+
+	var obtained []string
+	store := NewCertificateStore()
+	store.DynamicCerts.Set(map[string]*tls.Certificate{"snitest.com": dynamicCert})
+	store.SetObtainer(func(_ context.Context, serverName string) (*tls.Certificate, error) {
+		obtained = append(obtained, serverName)
+
+		if serverName == "snitest.org" {
+			return obtainedCert, nil
+		}
+		return nil, nil
+	})
+
+	// Known server names do not trigger the obtainer.
+	cert := store.GetBestCertificate(&tls.ClientHelloInfo{ServerName: "snitest.com"})
+	assert.Equal(t, dynamicCert, cert)
+	assert.Empty(t, obtained)
+
+	cert = store.GetBestCertificate(&tls.ClientHelloInfo{ServerName: "snitest.org"})
+	assert.Equal(t, obtainedCert, cert)
+	assert.Equal(t, []string{"snitest.org"}, obtained)
+
+	// The obtained certificate is cached.
+	cert = store.GetBestCertificate(&tls.ClientHelloInfo{ServerName: "snitest.org"})
+	assert.Equal(t, obtainedCert, cert)
+	assert.Equal(t, []string{"snitest.org"}, obtained)
+
+	cert = store.GetBestCertificate(&tls.ClientHelloInfo{ServerName: "unknown.org"})
+	assert.Nil(t, cert)
+	assert.Equal(t, []string{"snitest.org", "unknown.org"}, obtained)
+}
+
+func TestCertificateStore_SetObtainerConcurrently(t *testing.T) {
+	store := NewCertificateStore()
+	store.DynamicCerts.Set(map[string]*tls.Certificate{})
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 100; i++ {
+			store.SetObtainer(func(_ context.Context, _ string) (*tls.Certificate, error) {
+				return nil, nil
+			})
+		}
+	}()
+
+	for i := 0; i < 100; i++ {
+		assert.Nil(t, store.GetBestCertificate(&tls.ClientHelloInfo{ServerName: "snitest.org"}))
+	}
+
+	<-done
+}
+
 func loadTestCert(certName string, uppercase bool) (*tls.Certificate, error) {
 	replacement := "wildcard"
 	if uppercase {
@@ -0,0 +1,69 @@
+package tls
+
+import (
+	"context"
+	"path/filepath"
+	"reflect"
+	"sort"
+
+	"github.com/traefik/traefik/v2/pkg/filewatcher"
+	"github.com/traefik/traefik/v2/pkg/log"
+)
+
+// certificatesFiles returns the paths of the files of the given certificates.
+func certificatesFiles(certs []*CertAndStores) []string {
+	unique := make(map[string]struct{})
+	for _, cert := range certs {
+		for _, file := range []FileOrContent{cert.CertFile, cert.KeyFile} {
+			if file.IsPath() {
+				if path, err := filepath.Abs(file.String()); err == nil {
+					unique[path] = struct{}{}
+				}
+			}
+		}
+	}
+
+	files := make([]string, 0, len(unique))
+	for file := range unique {
+		files = append(files, file)
+	}
+	sort.Strings(files)
+
+	return files
+}
+
+// watchCertificatesFiles watches the files of the certificates, to reload the certificates when the files change,
+// without waiting for a new dynamic configuration.
+func (m *Manager) watchCertificatesFiles(ctx context.Context) {
+	files := certificatesFiles(m.certs)
+
+	if m.filesWatcher != nil && reflect.DeepEqual(m.filesWatcher.Files(), files) {
+		// The files have just been read, their current content is the reference to detect the next changes.
+		m.filesWatcher.HasChanged()
+		return
+	}
+
+	if m.filesWatcher != nil {
+		m.filesWatcher.Close()
+		m.filesWatcher = nil
+	}
+
+	if len(files) == 0 {
+		return
+	}
+
+	watcher, err := filewatcher.New(ctx, files, func() {
+		log.FromContext(ctx).Info("The certificate files have changed, reloading the certificates")
+
+		m.lock.Lock()
+		defer m.lock.Unlock()
+
+		m.updateCertificates(ctx)
+	})
+	if err != nil {
+		log.FromContext(ctx).Errorf("Unable to watch the certificate files, they will only be reloaded with the dynamic configuration: %v", err)
+		return
+	}
+
+	m.filesWatcher = watcher
+}
@@ -0,0 +1,120 @@
+package tls
+
+import (
+	"context"
+	"crypto/tls"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/traefik/traefik/v2/pkg/tls/generate"
+)
+
+func TestManager_certificatesFilesWatch(t *testing.T) {
+	testCases := []struct {
+		desc string
+		// setup writes the initial key pair, and returns the paths of the certificate and key files,
+		// and a function writing the given key pair in place of the initial one.
+		setup func(t *testing.T, dir string, cert, key []byte) (string, string, func(cert, key []byte))
+	}{
+		{
+			desc: "files rewritten",
+			setup: func(t *testing.T, dir string, cert, key []byte) (string, string, func(cert, key []byte)) {
+				t.Helper()
+
+				certFile := filepath.Join(dir, "tls.crt")
+				keyFile := filepath.Join(dir, "tls.key")
+				writeKeyPair(t, certFile, keyFile, cert, key)
+
+				return certFile, keyFile, func(cert, key []byte) {
+					writeKeyPair(t, certFile, keyFile, cert, key)
+				}
+			},
+		},
+		{
+			desc: "symlinks swapped, as in a mounted Kubernetes secret",
+			setup: func(t *testing.T, dir string, cert, key []byte) (string, string, func(cert, key []byte)) {
+				t.Helper()
+
+				writeVersion := func(version string, cert, key []byte) {
+					require.NoError(t, os.Mkdir(filepath.Join(dir, version), 0o700))
+					writeKeyPair(t, filepath.Join(dir, version, "tls.crt"), filepath.Join(dir, version, "tls.key"), cert, key)
+				}
+
+				writeVersion("..v1", cert, key)
+				require.NoError(t, os.Symlink("..v1", filepath.Join(dir, "..data")))
+				require.NoError(t, os.Symlink(filepath.Join("..data", "tls.crt"), filepath.Join(dir, "tls.crt")))
+				require.NoError(t, os.Symlink(filepath.Join("..data", "tls.key"), filepath.Join(dir, "tls.key")))
+
+				return filepath.Join(dir, "tls.crt"), filepath.Join(dir, "tls.key"), func(cert, key []byte) {
+					writeVersion("..v2", cert, key)
+					require.NoError(t, os.Symlink("..v2", filepath.Join(dir, "..data_tmp")))
+					require.NoError(t, os.Rename(filepath.Join(dir, "..data_tmp"), filepath.Join(dir, "..data")))
+				}
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			cert, key, err := generate.KeyPair("foo.example.com", time.Time{})
+			require.NoError(t, err)
+
+			certFile, keyFile, update := test.setup(t, t.TempDir(), cert, key)
+
+			tlsManager := NewManager()
+			tlsManager.UpdateConfigs(context.Background(), nil, map[string]Options{DefaultTLSConfigName: DefaultTLSOptions}, []*CertAndStores{{
+				Certificate: Certificate{CertFile: FileOrContent(certFile), KeyFile: FileOrContent(keyFile)},
+			}})
+			t.Cleanup(func() {
+				tlsManager.UpdateConfigs(context.Background(), nil, nil, nil)
+			})
+
+			require.NotNil(t, tlsManager.filesWatcher)
+
+			// The configuration is built before the change, as the routers are.
+			config, err := tlsManager.Get(DefaultTLSStoreName, DefaultTLSConfigName)
+			require.NoError(t, err)
+
+			served, err := config.GetCertificate(&tls.ClientHelloInfo{ServerName: "foo.example.com"})
+			require.NoError(t, err)
+			require.NotNil(t, served)
+
+			initial := served.Certificate[0]
+
+			newCert, newKey, err := generate.KeyPair("foo.example.com", time.Time{})
+			require.NoError(t, err)
+
+			update(newCert, newKey)
+
+			assert.Eventually(t, func() bool {
+				served, err := config.GetCertificate(&tls.ClientHelloInfo{ServerName: "foo.example.com"})
+				return err == nil && served != nil && string(served.Certificate[0]) != string(initial)
+			}, 5*time.Second, 50*time.Millisecond)
+		})
+	}
+}
+
+func TestManager_certificatesFilesWatch_content(t *testing.T) {
+	cert, key, err := generate.KeyPair("foo.example.com", time.Time{})
+	require.NoError(t, err)
+
+	tlsManager := NewManager()
+	tlsManager.UpdateConfigs(context.Background(), nil, nil, []*CertAndStores{{
+		Certificate: Certificate{CertFile: FileOrContent(cert), KeyFile: FileOrContent(key)},
+	}})
+
+	// The certificates defined by their content have no files to watch.
+	assert.Nil(t, tlsManager.filesWatcher)
+}
+
+func writeKeyPair(t *testing.T, certFile, keyFile string, cert, key []byte) {
+	t.Helper()
+
+	require.NoError(t, os.WriteFile(certFile, cert, 0o600))
+	require.NoError(t, os.WriteFile(keyFile, key, 0o600))
+}
@@ -0,0 +1,264 @@
+package tls
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/asn1"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/traefik/traefik/v2/pkg/log"
+	"golang.org/x/crypto/ocsp"
+)
+
+const (
+	ocspRefreshInterval = time.Minute
+	// ocspRetryDelay is the delay before asking again an OCSP responder which failed to answer.
+	ocspRetryDelay = 5 * time.Minute
+)
+
+// mustStapleOID is the TLS Feature extension, as defined in RFC 7633.
+var mustStapleOID = asn1.ObjectIdentifier{1, 3, 6, 1, 5, 5, 7, 1, 24}
+
+// ocspStaple holds the OCSP response stapled to a certificate.
+type ocspStaple struct {
+	leaf       *x509.Certificate
+	issuer     *x509.Certificate
+	mustStaple bool
+
+	raw        []byte
+	thisUpdate time.Time
+	nextUpdate time.Time
+	nextFetch  time.Time
+}
+
+// ocspStapler staples OCSP responses to the served certificates,
+// and refreshes them in the background, before they expire.
+type ocspStapler struct {
+	client *http.Client
+
+	mu      sync.RWMutex
+	staples map[[32]byte]*ocspStaple
+	// refreshNow wakes up the refresh loop when new certificates are stapled.
+	refreshNow chan struct{}
+}
+
+func newOCSPStapler() *ocspStapler {
+	return &ocspStapler{
+		client:     &http.Client{Timeout: 10 * time.Second},
+		staples:    make(map[[32]byte]*ocspStaple),
+		refreshNow: make(chan struct{}, 1),
+	}
+}
+
+// update sets the certificates to staple, keeping the responses already fetched for the known ones.
+func (s *ocspStapler) update(certs []*tls.Certificate) {
+	staples := make(map[[32]byte]*ocspStaple)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var added bool
+	for _, cert := range certs {
+		if cert == nil || len(cert.Certificate) == 0 {
+			continue
+		}
+
+		key := sha256.Sum256(cert.Certificate[0])
+		if staple, ok := s.staples[key]; ok {
+			staples[key] = staple
+			continue
+		}
+
+		staple, err := newOCSPStaple(cert)
+		if err != nil {
+			log.WithoutContext().Debugf("OCSP stapling is not available for certificate: %v", err)
+			continue
+		}
+
+		if staple != nil {
+			staples[key] = staple
+			added = true
+		}
+	}
+
+	s.staples = staples
+
+	if added {
+		select {
+		case s.refreshNow <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// staple returns the given certificate, with its OCSP response if available.
+func (s *ocspStapler) staple(cert *tls.Certificate) *tls.Certificate {
+	if cert == nil || len(cert.Certificate) == 0 {
+		return cert
+	}
+
+	s.mu.RLock()
+	staple, ok := s.staples[sha256.Sum256(cert.Certificate[0])]
+	var raw []byte
+	if ok && time.Now().Before(staple.nextUpdate) {
+		raw = staple.raw
+	}
+	s.mu.RUnlock()
+
+	if raw == nil {
+		return cert
+	}
+
+	// The certificate is copied, as it might be served concurrently.
+	stapled := *cert
+	stapled.OCSPStaple = raw
+
+	return &stapled
+}
+
+// run refreshes the OCSP responses until the context is done.
+func (s *ocspStapler) run(ctx context.Context) {
+	ticker := time.NewTicker(ocspRefreshInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.refreshNow:
+		case <-ticker.C:
+		}
+
+		s.refresh()
+	}
+}
+
+// refresh fetches the OCSP responses which are missing, or halfway to their expiration.
+func (s *ocspStapler) refresh() {
+	now := time.Now()
+
+	s.mu.RLock()
+	var toFetch []*ocspStaple
+	for _, staple := range s.staples {
+		if now.After(staple.nextFetch) {
+			toFetch = append(toFetch, staple)
+		}
+	}
+	s.mu.RUnlock()
+
+	for _, staple := range toFetch {
+		logger := log.WithoutContext().WithField("certificate", staple.leaf.Subject.CommonName)
+
+		resp, raw, err := fetchOCSPResponse(s.client, staple.leaf, staple.issuer)
+
+		s.mu.Lock()
+		if err != nil {
+			staple.nextFetch = now.Add(ocspRetryDelay)
+			s.mu.Unlock()
+
+			if staple.mustStaple {
+				logger.Errorf("Unable to get the OCSP response of a must-staple certificate, which clients might reject: %v", err)
+			} else {
+				logger.Debugf("Unable to get the OCSP response: %v", err)
+			}
+			continue
+		}
+
+		staple.raw = raw
+		staple.thisUpdate = resp.ThisUpdate
+		staple.nextUpdate = resp.NextUpdate
+		if staple.nextUpdate.IsZero() {
+			// The responder has newer information at any time, so the response is refreshed regularly.
+			staple.nextUpdate = now.Add(time.Hour)
+		}
+		staple.nextFetch = staple.thisUpdate.Add(staple.nextUpdate.Sub(staple.thisUpdate) / 2)
+		s.mu.Unlock()
+
+		if resp.Status == ocsp.Revoked {
+			logger.Errorf("The certificate has been revoked at %s", resp.RevokedAt)
+		}
+	}
+}
+
+// newOCSPStaple returns the staple of the given certificate, or nil if the certificate has no OCSP responder.
+func newOCSPStaple(cert *tls.Certificate) (*ocspStaple, error) {
+	leaf := cert.Leaf
+	if leaf == nil {
+		var err error
+		leaf, err = x509.ParseCertificate(cert.Certificate[0])
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if len(leaf.OCSPServer) == 0 {
+		return nil, nil
+	}
+
+	if len(cert.Certificate) < 2 {
+		return nil, fmt.Errorf("no issuer certificate in the chain of %s", leaf.Subject.CommonName)
+	}
+
+	issuer, err := x509.ParseCertificate(cert.Certificate[1])
+	if err != nil {
+		return nil, err
+	}
+
+	return &ocspStaple{
+		leaf:       leaf,
+		issuer:     issuer,
+		mustStaple: isMustStaple(leaf),
+	}, nil
+}
+
+func isMustStaple(cert *x509.Certificate) bool {
+	for _, ext := range cert.Extensions {
+		if ext.Id.Equal(mustStapleOID) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// fetchOCSPResponse asks the OCSP responder of the given certificate for its status.
+func fetchOCSPResponse(client *http.Client, leaf, issuer *x509.Certificate) (*ocsp.Response, []byte, error) {
+	if len(leaf.OCSPServer) == 0 {
+		return nil, nil, errors.New("no OCSP responder")
+	}
+
+	req, err := ocsp.CreateRequest(leaf, issuer, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	httpResp, err := client.Post(leaf.OCSPServer[0], "application/ocsp-request", bytes.NewReader(req))
+	if err != nil {
+		return nil, nil, err
+	}
+	defer func() { _ = httpResp.Body.Close() }()
+
+	if httpResp.StatusCode != http.StatusOK {
+		return nil, nil, fmt.Errorf("unexpected status code from OCSP responder %s: %d", leaf.OCSPServer[0], httpResp.StatusCode)
+	}
+
+	raw, err := io.ReadAll(io.LimitReader(httpResp.Body, 1<<20))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	resp, err := ocsp.ParseResponseForCert(raw, leaf, issuer)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid response from OCSP responder %s: %w", leaf.OCSPServer[0], err)
+	}
+
+	return resp, raw, nil
+}
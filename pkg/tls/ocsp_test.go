@@ -0,0 +1,207 @@
+package tls
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ocsp"
+)
+
+func TestOCSPStapler(t *testing.T) {
+	ca, caKey := createTestCA(t)
+
+	var requests int32
+	responder := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		atomic.AddInt32(&requests, 1)
+
+		body, err := io.ReadAll(req.Body)
+		require.NoError(t, err)
+
+		ocspReq, err := ocsp.ParseRequest(body)
+		require.NoError(t, err)
+
+		resp, err := ocsp.CreateResponse(ca, ca, ocsp.Response{
+			Status:       ocsp.Good,
+			SerialNumber: ocspReq.SerialNumber,
+			ThisUpdate:   time.Now().Add(-time.Minute),
+			NextUpdate:   time.Now().Add(time.Hour),
+		}, caKey)
+		require.NoError(t, err)
+
+		_, _ = rw.Write(resp)
+	}))
+	t.Cleanup(responder.Close)
+
+	leaf := createTestLeaf(t, ca, caKey, 2, responder.URL)
+	cert := &tls.Certificate{Certificate: [][]byte{leaf.Raw, ca.Raw}}
+
+	withoutResponder := createTestLeaf(t, ca, caKey, 3, "")
+	certWithoutResponder := &tls.Certificate{Certificate: [][]byte{withoutResponder.Raw, ca.Raw}}
+
+	// The refresh loop is not run, to refresh synchronously.
+	stapler := newOCSPStapler()
+
+	stapler.update([]*tls.Certificate{cert, certWithoutResponder, nil})
+	require.Len(t, stapler.staples, 1)
+
+	// No response fetched yet.
+	assert.Same(t, cert, stapler.staple(cert))
+
+	stapler.refresh()
+
+	stapled := stapler.staple(cert)
+	require.NotSame(t, cert, stapled)
+	assert.Empty(t, cert.OCSPStaple)
+
+	resp, err := ocsp.ParseResponseForCert(stapled.OCSPStaple, leaf, ca)
+	require.NoError(t, err)
+	assert.Equal(t, ocsp.Good, resp.Status)
+
+	assert.Same(t, certWithoutResponder, stapler.staple(certWithoutResponder))
+
+	// The response is not fetched again before being halfway to its expiration.
+	stapler.refresh()
+	assert.Equal(t, int32(1), atomic.LoadInt32(&requests))
+
+	// The known certificates keep their response.
+	stapler.update([]*tls.Certificate{cert})
+	assert.NotEmpty(t, stapler.staple(cert).OCSPStaple)
+
+	stapler.update(nil)
+	assert.Same(t, cert, stapler.staple(cert))
+}
+
+func TestOCSPStapler_run(t *testing.T) {
+	ca, caKey := createTestCA(t)
+
+	fetched := make(chan struct{}, 1)
+	responder := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		select {
+		case fetched <- struct{}{}:
+		default:
+		}
+
+		rw.WriteHeader(http.StatusInternalServerError)
+	}))
+	t.Cleanup(responder.Close)
+
+	leaf := createTestLeaf(t, ca, caKey, 2, responder.URL)
+
+	stapler := newOCSPStapler()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		stapler.run(ctx)
+		close(done)
+	}()
+
+	// The new certificates are refreshed without waiting for the refresh interval.
+	stapler.update([]*tls.Certificate{{Certificate: [][]byte{leaf.Raw, ca.Raw}}})
+
+	select {
+	case <-fetched:
+	case <-time.After(5 * time.Second):
+		t.Fatal("timeout waiting for the OCSP request")
+	}
+
+	cancel()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the refresh loop did not stop")
+	}
+}
+
+func TestNewOCSPStaple(t *testing.T) {
+	ca, caKey := createTestCA(t)
+
+	mustStaple := pkix.Extension{
+		Id: mustStapleOID,
+		// status_request TLS feature.
+		Value: mustMarshal(t, []int{5}),
+	}
+
+	testCases := []struct {
+		desc               string
+		cert               func() *tls.Certificate
+		expectedStaple     bool
+		expectedMustStaple bool
+		expectedErr        bool
+	}{
+		{
+			desc: "no OCSP responder",
+			cert: func() *tls.Certificate {
+				leaf := createTestLeaf(t, ca, caKey, 2, "")
+				return &tls.Certificate{Certificate: [][]byte{leaf.Raw, ca.Raw}}
+			},
+		},
+		{
+			desc: "no issuer",
+			cert: func() *tls.Certificate {
+				leaf := createTestLeaf(t, ca, caKey, 2, "http://ocsp.example.com")
+				return &tls.Certificate{Certificate: [][]byte{leaf.Raw}}
+			},
+			expectedErr: true,
+		},
+		{
+			desc: "OCSP responder",
+			cert: func() *tls.Certificate {
+				leaf := createTestLeaf(t, ca, caKey, 2, "http://ocsp.example.com")
+				return &tls.Certificate{Certificate: [][]byte{leaf.Raw, ca.Raw}}
+			},
+			expectedStaple: true,
+		},
+		{
+			desc: "must-staple",
+			cert: func() *tls.Certificate {
+				leaf := createTestLeaf(t, ca, caKey, 2, "http://ocsp.example.com", mustStaple)
+				return &tls.Certificate{Certificate: [][]byte{leaf.Raw, ca.Raw}}
+			},
+			expectedStaple:     true,
+			expectedMustStaple: true,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			staple, err := newOCSPStaple(test.cert())
+			if test.expectedErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			if !test.expectedStaple {
+				assert.Nil(t, staple)
+				return
+			}
+
+			require.NotNil(t, staple)
+			assert.Equal(t, test.expectedMustStaple, staple.mustStaple)
+		})
+	}
+}
+
+func mustMarshal(t *testing.T, val interface{}) []byte {
+	t.Helper()
+
+	data, err := asn1.Marshal(val)
+	require.NoError(t, err)
+
+	return data
+}
@@ -0,0 +1,350 @@
+package tls
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/patrickmn/go-cache"
+	"github.com/traefik/traefik/v2/pkg/log"
+	"github.com/traefik/traefik/v2/pkg/safe"
+	"golang.org/x/crypto/ocsp"
+)
+
+const (
+	// crlURLRefreshInterval is the maximum duration a CRL downloaded from a URL is used before being downloaded again.
+	crlURLRefreshInterval = time.Hour
+	// crlRetryDelay is the delay before downloading again a CRL which failed to download.
+	crlRetryDelay = time.Minute
+	// ocspStatusRetryDelay is the delay before requesting again the OCSP status of a certificate which could not be fetched.
+	ocspStatusRetryDelay = time.Minute
+)
+
+var (
+	errRevoked             = errors.New("certificate revoked")
+	errUndeterminedRevoked = errors.New("revocation status cannot be determined")
+)
+
+// crlsFromURL caches the CRLs downloaded from URLs, shared by all the TLS configurations.
+var crlsFromURL = &crlCache{
+	lists:     make(map[string]*cachedCRL),
+	downloads: make(map[string]*crlDownload),
+}
+
+// ocspResponses caches the OCSP responses of the client certificates, or the errors fetching them, by certificate.
+var ocspResponses = cache.New(time.Hour, 10*time.Minute)
+
+// ocspFetches are the OCSP requests in progress, shared by the handshakes of the same certificate.
+var ocspFetches = &ocspFetcher{fetches: make(map[string]*ocspFetch)}
+
+// revocationChecker checks that the client certificates have not been revoked.
+type revocationChecker struct {
+	crls     []*x509.RevocationList
+	crlURLs  []string
+	ocsp     bool
+	softFail bool
+	client   *http.Client
+}
+
+func newRevocationChecker(config *Revocation) (*revocationChecker, error) {
+	checker := &revocationChecker{
+		ocsp:     config.OCSP,
+		softFail: config.SoftFail,
+		client:   &http.Client{Timeout: 5 * time.Second},
+	}
+
+	for _, crl := range config.CRLs {
+		if strings.HasPrefix(crl, "http://") || strings.HasPrefix(crl, "https://") {
+			checker.crlURLs = append(checker.crlURLs, crl)
+			continue
+		}
+
+		data, err := os.ReadFile(crl)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read CRL: %w", err)
+		}
+
+		list, err := parseCRL(data)
+		if err != nil {
+			return nil, fmt.Errorf("invalid CRL in %s: %w", crl, err)
+		}
+
+		checker.crls = append(checker.crls, list)
+	}
+
+	return checker, nil
+}
+
+// verifyConnection checks the revocation status of the verified client certificate chain.
+// It is meant to be used as tls.Config.VerifyConnection.
+func (c *revocationChecker) verifyConnection(cs tls.ConnectionState) error {
+	// No certificate, or a certificate which is not verified, e.g. with the RequestClientCert client auth type.
+	if len(cs.VerifiedChains) == 0 {
+		return nil
+	}
+
+	chain := cs.VerifiedChains[0]
+	for i := 0; i < len(chain)-1; i++ {
+		err := c.check(chain[i], chain[i+1])
+		if err == nil {
+			continue
+		}
+
+		if errors.Is(err, errUndeterminedRevoked) && c.softFail {
+			log.WithoutContext().Debugf("Client certificate %q accepted: %v", chain[i].Subject, err)
+			continue
+		}
+
+		return fmt.Errorf("client certificate %q: %w", chain[i].Subject, err)
+	}
+
+	return nil
+}
+
+func (c *revocationChecker) check(cert, issuer *x509.Certificate) error {
+	// A revocation found in any CRL prevails over the CRLs which cannot be used.
+	var undetermined error
+
+	crls := c.crls
+	for _, crlURL := range c.crlURLs {
+		list, err := crlsFromURL.get(c.client, crlURL)
+		if err != nil {
+			// The CRL might not concern this issuer, but this cannot be known for sure.
+			undetermined = fmt.Errorf("%w: %v", errUndeterminedRevoked, err)
+			continue
+		}
+		crls = append(crls, list)
+	}
+
+	now := time.Now()
+	for _, crl := range crls {
+		if !bytes.Equal(crl.RawIssuer, issuer.RawSubject) {
+			continue
+		}
+
+		if err := crl.CheckSignatureFrom(issuer); err != nil {
+			undetermined = fmt.Errorf("%w: invalid CRL signature: %v", errUndeterminedRevoked, err)
+			continue
+		}
+
+		for _, revoked := range crl.RevokedCertificates {
+			if revoked.SerialNumber.Cmp(cert.SerialNumber) == 0 {
+				return errRevoked
+			}
+		}
+
+		if !crl.NextUpdate.IsZero() && now.After(crl.NextUpdate) {
+			undetermined = fmt.Errorf("%w: the CRL of %q is outdated", errUndeterminedRevoked, issuer.Subject)
+		}
+	}
+
+	if c.ocsp && len(cert.OCSPServer) > 0 {
+		if err := c.checkOCSP(cert, issuer); err != nil {
+			if errors.Is(err, errRevoked) {
+				return err
+			}
+			undetermined = err
+		}
+	}
+
+	return undetermined
+}
+
+func (c *revocationChecker) checkOCSP(cert, issuer *x509.Certificate) error {
+	key := sha256.Sum256(cert.Raw)
+	cacheKey := string(key[:])
+
+	result, ok := ocspResponses.Get(cacheKey)
+	if !ok {
+		result = ocspFetches.fetch(c.client, cacheKey, cert, issuer)
+	}
+
+	resp, ok := result.(*ocsp.Response)
+	if !ok {
+		return fmt.Errorf("%w: %v", errUndeterminedRevoked, result)
+	}
+
+	switch resp.Status {
+	case ocsp.Good:
+		return nil
+	case ocsp.Revoked:
+		return errRevoked
+	default:
+		return fmt.Errorf("%w: unknown to the OCSP responder", errUndeterminedRevoked)
+	}
+}
+
+// ocspFetch is an OCSP request in progress, resulting in an OCSP response or an error.
+type ocspFetch struct {
+	done   chan struct{}
+	result interface{}
+}
+
+type ocspFetcher struct {
+	mu      sync.Mutex
+	fetches map[string]*ocspFetch
+}
+
+// fetch requests the OCSP status of a certificate, unless it is already being requested, and caches the result.
+// The failures are cached for a short time, so that an unavailable responder does not slow down every handshake.
+func (f *ocspFetcher) fetch(client *http.Client, cacheKey string, cert, issuer *x509.Certificate) interface{} {
+	f.mu.Lock()
+	if fetch, ok := f.fetches[cacheKey]; ok {
+		f.mu.Unlock()
+
+		<-fetch.done
+		return fetch.result
+	}
+
+	fetch := &ocspFetch{done: make(chan struct{})}
+	f.fetches[cacheKey] = fetch
+	f.mu.Unlock()
+
+	resp, _, err := fetchOCSPResponse(client, cert, issuer)
+
+	switch {
+	case err != nil:
+		fetch.result = err
+		ocspResponses.Set(cacheKey, err, ocspStatusRetryDelay)
+
+	// The response is cached until the responder has newer information.
+	case resp.NextUpdate.IsZero():
+		fetch.result = resp
+		ocspResponses.SetDefault(cacheKey, resp)
+
+	default:
+		fetch.result = resp
+		if time.Now().Before(resp.NextUpdate) {
+			ocspResponses.Set(cacheKey, resp, time.Until(resp.NextUpdate))
+		}
+	}
+
+	f.mu.Lock()
+	delete(f.fetches, cacheKey)
+	f.mu.Unlock()
+
+	close(fetch.done)
+
+	return fetch.result
+}
+
+type cachedCRL struct {
+	list    *x509.RevocationList
+	expires time.Time
+}
+
+// crlDownload is a CRL download in progress.
+type crlDownload struct {
+	done chan struct{}
+	list *x509.RevocationList
+	err  error
+}
+
+type crlCache struct {
+	mu        sync.Mutex
+	lists     map[string]*cachedCRL
+	downloads map[string]*crlDownload
+}
+
+// get returns the CRL downloaded from the given URL.
+// Once expired, the CRL is downloaded again in the background, while the cached one is still used.
+func (c *crlCache) get(client *http.Client, crlURL string) (*x509.RevocationList, error) {
+	c.mu.Lock()
+	cached, ok := c.lists[crlURL]
+	if ok && time.Now().Before(cached.expires) {
+		c.mu.Unlock()
+		return cached.list, nil
+	}
+
+	download := c.download(client, crlURL)
+	c.mu.Unlock()
+
+	if ok {
+		return cached.list, nil
+	}
+
+	// The first download of a CRL is waited for, as there is no CRL to use yet.
+	<-download.done
+
+	return download.list, download.err
+}
+
+// download starts downloading the CRL from the given URL, unless it is already being downloaded.
+// The caller must hold the lock.
+func (c *crlCache) download(client *http.Client, crlURL string) *crlDownload {
+	if download, ok := c.downloads[crlURL]; ok {
+		return download
+	}
+
+	download := &crlDownload{done: make(chan struct{})}
+	c.downloads[crlURL] = download
+
+	safe.Go(func() {
+		defer close(download.done)
+
+		download.list, download.err = downloadCRL(client, crlURL)
+
+		now := time.Now()
+
+		c.mu.Lock()
+		defer c.mu.Unlock()
+
+		delete(c.downloads, crlURL)
+
+		if download.err != nil {
+			if cached, ok := c.lists[crlURL]; ok {
+				// The outdated CRL is still used until its next update.
+				log.WithoutContext().Errorf("Unable to download the CRL %s: %v", crlURL, download.err)
+				c.lists[crlURL] = &cachedCRL{list: cached.list, expires: now.Add(crlRetryDelay)}
+			}
+			return
+		}
+
+		expires := now.Add(crlURLRefreshInterval)
+		if !download.list.NextUpdate.IsZero() && download.list.NextUpdate.Before(expires) {
+			expires = download.list.NextUpdate
+		}
+
+		c.lists[crlURL] = &cachedCRL{list: download.list, expires: expires}
+	})
+
+	return download
+}
+
+func downloadCRL(client *http.Client, crlURL string) (*x509.RevocationList, error) {
+	resp, err := client.Get(crlURL)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 50<<20))
+	if err != nil {
+		return nil, err
+	}
+
+	return parseCRL(data)
+}
+
+// parseCRL parses a CRL, either PEM or DER encoded.
+func parseCRL(data []byte) (*x509.RevocationList, error) {
+	if block, _ := pem.Decode(data); block != nil {
+		data = block.Bytes
+	}
+
+	return x509.ParseRevocationList(data)
+}
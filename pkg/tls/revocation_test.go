@@ -0,0 +1,370 @@
+package tls
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ocsp"
+)
+
+func TestRevocationChecker_CRL(t *testing.T) {
+	ca, caKey := createTestCA(t)
+	good := createTestLeaf(t, ca, caKey, 2, "")
+	revoked := createTestLeaf(t, ca, caKey, 3, "")
+
+	crl := createTestCRL(t, ca, caKey, time.Now().Add(time.Hour), revoked.SerialNumber)
+	outdatedCRL := createTestCRL(t, ca, caKey, time.Now().Add(-time.Minute), revoked.SerialNumber)
+
+	crlServer := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		_, _ = rw.Write(crl)
+	}))
+	t.Cleanup(crlServer.Close)
+
+	crlFile := filepath.Join(t.TempDir(), "crl.pem")
+	err := os.WriteFile(crlFile, pem.EncodeToMemory(&pem.Block{Type: "X509 CRL", Bytes: crl}), 0o600)
+	require.NoError(t, err)
+
+	outdatedCRLFile := filepath.Join(t.TempDir(), "outdated.crl")
+	err = os.WriteFile(outdatedCRLFile, outdatedCRL, 0o600)
+	require.NoError(t, err)
+
+	testCases := []struct {
+		desc        string
+		config      Revocation
+		cert        *x509.Certificate
+		expectedErr bool
+	}{
+		{
+			desc:   "CRL file, good certificate",
+			config: Revocation{CRLs: []string{crlFile}},
+			cert:   good,
+		},
+		{
+			desc:        "CRL file, revoked certificate",
+			config:      Revocation{CRLs: []string{crlFile}},
+			cert:        revoked,
+			expectedErr: true,
+		},
+		{
+			desc:        "CRL URL, revoked certificate",
+			config:      Revocation{CRLs: []string{crlServer.URL}},
+			cert:        revoked,
+			expectedErr: true,
+		},
+		{
+			desc:        "outdated CRL",
+			config:      Revocation{CRLs: []string{outdatedCRLFile}},
+			cert:        good,
+			expectedErr: true,
+		},
+		{
+			desc:   "outdated CRL with soft fail",
+			config: Revocation{CRLs: []string{outdatedCRLFile}, SoftFail: true},
+			cert:   good,
+		},
+		{
+			desc:        "outdated CRL with soft fail, revoked certificate",
+			config:      Revocation{CRLs: []string{outdatedCRLFile, crlFile}, SoftFail: true},
+			cert:        revoked,
+			expectedErr: true,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			checker, err := newRevocationChecker(&test.config)
+			require.NoError(t, err)
+
+			err = checker.verifyConnection(tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{test.cert, ca}}})
+			if test.expectedErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestCRLCache(t *testing.T) {
+	ca, caKey := createTestCA(t)
+
+	var downloads int32
+	crlServer := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		count := atomic.AddInt32(&downloads, 1)
+		_, _ = rw.Write(createTestCRL(t, ca, caKey, time.Now().Add(time.Hour), big.NewInt(int64(count))))
+	}))
+	t.Cleanup(crlServer.Close)
+
+	cache := &crlCache{
+		lists:     make(map[string]*cachedCRL),
+		downloads: make(map[string]*crlDownload),
+	}
+
+	list, err := cache.get(crlServer.Client(), crlServer.URL)
+	require.NoError(t, err)
+	require.Len(t, list.RevokedCertificates, 1)
+	assert.Equal(t, int64(1), list.RevokedCertificates[0].SerialNumber.Int64())
+
+	// The CRL is cached.
+	list, err = cache.get(crlServer.Client(), crlServer.URL)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), list.RevokedCertificates[0].SerialNumber.Int64())
+	assert.Equal(t, int32(1), atomic.LoadInt32(&downloads))
+
+	cache.mu.Lock()
+	cache.lists[crlServer.URL] = &cachedCRL{list: list, expires: time.Now().Add(-time.Second)}
+	cache.mu.Unlock()
+
+	// The expired CRL is served while the new one is downloaded.
+	list, err = cache.get(crlServer.Client(), crlServer.URL)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), list.RevokedCertificates[0].SerialNumber.Int64())
+
+	assert.Eventually(t, func() bool {
+		list, err = cache.get(crlServer.Client(), crlServer.URL)
+		return err == nil && list.RevokedCertificates[0].SerialNumber.Int64() == 2
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&downloads))
+}
+
+func TestRevocationChecker_OCSP(t *testing.T) {
+	ca, caKey := createTestCA(t)
+
+	responder := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		body, err := io.ReadAll(req.Body)
+		require.NoError(t, err)
+
+		ocspReq, err := ocsp.ParseRequest(body)
+		require.NoError(t, err)
+
+		status := ocsp.Good
+		switch ocspReq.SerialNumber.Int64() {
+		case 3:
+			status = ocsp.Revoked
+		case 4:
+			status = ocsp.Unknown
+		}
+
+		resp, err := ocsp.CreateResponse(ca, ca, ocsp.Response{
+			Status:       status,
+			SerialNumber: ocspReq.SerialNumber,
+			ThisUpdate:   time.Now().Add(-time.Minute),
+			NextUpdate:   time.Now().Add(time.Hour),
+			RevokedAt:    time.Now().Add(-time.Minute),
+		}, caKey)
+		require.NoError(t, err)
+
+		_, _ = rw.Write(resp)
+	}))
+	t.Cleanup(responder.Close)
+
+	testCases := []struct {
+		desc        string
+		serial      int64
+		ocspServer  string
+		softFail    bool
+		expectedErr bool
+	}{
+		{
+			desc:       "good certificate",
+			serial:     2,
+			ocspServer: responder.URL,
+		},
+		{
+			desc:        "revoked certificate",
+			serial:      3,
+			ocspServer:  responder.URL,
+			expectedErr: true,
+		},
+		{
+			desc:        "unknown certificate",
+			serial:      4,
+			ocspServer:  responder.URL,
+			expectedErr: true,
+		},
+		{
+			desc:       "unknown certificate with soft fail",
+			serial:     5,
+			ocspServer: responder.URL,
+			softFail:   true,
+		},
+		{
+			desc:        "unreachable responder",
+			serial:      6,
+			ocspServer:  "http://127.0.0.1:1",
+			expectedErr: true,
+		},
+		{
+			desc:       "unreachable responder with soft fail",
+			serial:     7,
+			ocspServer: "http://127.0.0.1:1",
+			softFail:   true,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			cert := createTestLeaf(t, ca, caKey, test.serial, test.ocspServer)
+
+			checker, err := newRevocationChecker(&Revocation{OCSP: true, SoftFail: test.softFail})
+			require.NoError(t, err)
+
+			err = checker.verifyConnection(tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{cert, ca}}})
+			if test.expectedErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestRevocationChecker_OCSPFailureCached(t *testing.T) {
+	ca, caKey := createTestCA(t)
+
+	var requests int32
+	responder := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		time.Sleep(50 * time.Millisecond)
+
+		rw.WriteHeader(http.StatusInternalServerError)
+	}))
+	t.Cleanup(responder.Close)
+
+	cert := createTestLeaf(t, ca, caKey, 8, responder.URL)
+
+	checker, err := newRevocationChecker(&Revocation{OCSP: true})
+	require.NoError(t, err)
+
+	// The concurrent handshakes share the same request, and its failure is cached.
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			err := checker.verifyConnection(tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{cert, ca}}})
+			assert.ErrorIs(t, err, errUndeterminedRevoked)
+		}()
+	}
+	wg.Wait()
+
+	err = checker.verifyConnection(tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{cert, ca}}})
+	assert.ErrorIs(t, err, errUndeterminedRevoked)
+
+	assert.Equal(t, int32(1), atomic.LoadInt32(&requests))
+}
+
+func TestBuildTLSConfig_Revocation(t *testing.T) {
+	_, err := buildTLSConfig(Options{ClientAuth: ClientAuth{Revocation: &Revocation{OCSP: true}}})
+	assert.Error(t, err)
+
+	_, err = buildTLSConfig(Options{ClientAuth: ClientAuth{
+		CAFiles:    []FileOrContent{localhostCert},
+		Revocation: &Revocation{CRLs: []string{"/does/not/exist"}},
+	}})
+	assert.Error(t, err)
+
+	conf, err := buildTLSConfig(Options{ClientAuth: ClientAuth{
+		CAFiles:    []FileOrContent{localhostCert},
+		Revocation: &Revocation{OCSP: true},
+	}})
+	require.NoError(t, err)
+	assert.NotNil(t, conf.VerifyConnection)
+}
+
+func createTestCA(t *testing.T) (*x509.Certificate, crypto.Signer) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "Test CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(24 * time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign | x509.KeyUsageDigitalSignature,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, key.Public(), key)
+	require.NoError(t, err)
+
+	ca, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	return ca, key
+}
+
+func createTestLeaf(t *testing.T, ca *x509.Certificate, caKey crypto.Signer, serial int64, ocspServer string, extensions ...pkix.Extension) *x509.Certificate {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber:    big.NewInt(serial),
+		Subject:         pkix.Name{CommonName: "client"},
+		NotBefore:       time.Now().Add(-time.Hour),
+		NotAfter:        time.Now().Add(24 * time.Hour),
+		KeyUsage:        x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:     []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth, x509.ExtKeyUsageServerAuth},
+		ExtraExtensions: extensions,
+	}
+	if ocspServer != "" {
+		template.OCSPServer = []string{ocspServer}
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, ca, key.Public(), caKey)
+	require.NoError(t, err)
+
+	leaf, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	return leaf
+}
+
+func createTestCRL(t *testing.T, ca *x509.Certificate, caKey crypto.Signer, nextUpdate time.Time, revoked ...*big.Int) []byte {
+	t.Helper()
+
+	var revokedCerts []pkix.RevokedCertificate
+	for _, serial := range revoked {
+		revokedCerts = append(revokedCerts, pkix.RevokedCertificate{SerialNumber: serial, RevocationTime: time.Now().Add(-time.Minute)})
+	}
+
+	crl, err := x509.CreateRevocationList(rand.Reader, &x509.RevocationList{
+		Number:              big.NewInt(1),
+		ThisUpdate:          time.Now().Add(-time.Hour),
+		NextUpdate:          nextUpdate,
+		RevokedCertificates: revokedCerts,
+	}, ca, caKey)
+	require.NoError(t, err)
+
+	return crl
+}
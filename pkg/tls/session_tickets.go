@@ -0,0 +1,318 @@
+package tls
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/traefik/traefik/v2/pkg/filewatcher"
+	"github.com/traefik/traefik/v2/pkg/log"
+	"github.com/traefik/traefik/v2/pkg/safe"
+)
+
+// sessionTicketKeyLength is the length of the session ticket keys expected by crypto/tls.
+const sessionTicketKeyLength = 32
+
+// sessionTicketKeysSyncPeriod is the maximum period between two synchronizations of the rotated keys with the store.
+var sessionTicketKeysSyncPeriod = time.Minute
+
+// SessionTicketKeyStore stores the session ticket keys shared between the Traefik instances.
+type SessionTicketKeyStore interface {
+	Get(ctx context.Context, key string, dst *[]byte) error
+	// Add stores the item only if the key does not exist yet, and returns whether it was stored.
+	Add(ctx context.Context, key string, item []byte, ttl time.Duration) (bool, error)
+}
+
+// SessionTicketKeys holds the session ticket keys of the TLS configurations.
+// The first key encrypts the new session tickets, and all the keys decrypt the session tickets.
+type SessionTicketKeys struct {
+	mu         sync.RWMutex
+	keys       [][sessionTicketKeyLength]byte
+	generation uint64
+}
+
+// NewSessionTicketKeysFromFiles reads the session ticket keys from the given files,
+// and reads them again when the files change.
+func NewSessionTicketKeysFromFiles(ctx context.Context, files []string) (*SessionTicketKeys, error) {
+	keys, err := readSessionTicketKeys(files)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &SessionTicketKeys{}
+	s.set(keys)
+
+	_, err = filewatcher.New(ctx, files, func() {
+		keys, err := readSessionTicketKeys(files)
+		if err != nil {
+			log.FromContext(ctx).Errorf("Unable to reload the TLS session ticket keys: %v", err)
+			return
+		}
+
+		log.FromContext(ctx).Info("Reloading the TLS session ticket keys")
+		s.set(keys)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// NewRotatingSessionTicketKeys creates session ticket keys rotated every interval,
+// the previous keys decrypting the session tickets for the overlap duration.
+// The keys are shared through the store with the other Traefik instances, or only kept in memory if the store is nil.
+func NewRotatingSessionTicketKeys(ctx context.Context, store SessionTicketKeyStore, interval, overlap time.Duration) (*SessionTicketKeys, error) {
+	if interval <= 0 {
+		return nil, errors.New("the rotation interval must be positive")
+	}
+
+	if overlap < 0 {
+		overlap = 0
+	}
+
+	r := &keysRotator{
+		keys:     &SessionTicketKeys{},
+		store:    store,
+		interval: interval,
+		previous: int64((overlap + interval - 1) / interval),
+		cache:    make(map[int64][sessionTicketKeyLength]byte),
+	}
+
+	r.sync(ctx, time.Now())
+
+	period := sessionTicketKeysSyncPeriod
+	if interval < period {
+		period = interval
+	}
+
+	safe.Go(func() {
+		ticker := time.NewTicker(period)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case now := <-ticker.C:
+				r.sync(ctx, now)
+			}
+		}
+	})
+
+	return r.keys, nil
+}
+
+func (s *SessionTicketKeys) get() ([][sessionTicketKeyLength]byte, uint64) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.keys, s.generation
+}
+
+func (s *SessionTicketKeys) set(keys [][sessionTicketKeyLength]byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(keys) == len(s.keys) {
+		same := true
+		for i := range keys {
+			if keys[i] != s.keys[i] {
+				same = false
+				break
+			}
+		}
+
+		if same {
+			return
+		}
+	}
+
+	s.keys = keys
+	s.generation++
+}
+
+// apply sets the keys on the TLS configuration, and updates them on the next handshakes when they change.
+func (s *SessionTicketKeys) apply(conf *tls.Config) {
+	keys, generation := s.get()
+	if len(keys) > 0 {
+		conf.SetSessionTicketKeys(keys)
+	}
+
+	current := generation
+	conf.GetConfigForClient = func(_ *tls.ClientHelloInfo) (*tls.Config, error) {
+		keys, generation := s.get()
+		if atomic.SwapUint64(&current, generation) != generation && len(keys) > 0 {
+			conf.SetSessionTicketKeys(keys)
+		}
+
+		// Uses the configuration itself.
+		return nil, nil
+	}
+}
+
+// keysRotator rotates the session ticket keys.
+// The rotation periods are aligned on the Unix epoch, so that all the instances sharing the store use the same key at the same time.
+// The key of the next period is created ahead of time, which lets the instances agree on its value before using it.
+type keysRotator struct {
+	keys     *SessionTicketKeys
+	store    SessionTicketKeyStore
+	interval time.Duration
+	// previous is the number of previous keys still decrypting the tickets.
+	previous int64
+
+	// cache holds the known keys by period, used when the store is not available.
+	cache map[int64][sessionTicketKeyLength]byte
+}
+
+func (r *keysRotator) sync(ctx context.Context, now time.Time) {
+	period := now.UnixNano() / int64(r.interval)
+
+	keys := [][sessionTicketKeyLength]byte{r.key(ctx, period, true)}
+
+	// Creates the next key ahead of time.
+	r.key(ctx, period+1, true)
+
+	for i := int64(1); i <= r.previous; i++ {
+		if key, ok := r.lookup(ctx, period-i); ok {
+			keys = append(keys, key)
+		}
+	}
+
+	for p := range r.cache {
+		if p < period-r.previous {
+			delete(r.cache, p)
+		}
+	}
+
+	r.keys.set(keys)
+}
+
+// key returns the key of the given period, and creates it if it does not exist yet.
+func (r *keysRotator) key(ctx context.Context, period int64, create bool) [sessionTicketKeyLength]byte {
+	if key, ok := r.lookup(ctx, period); ok {
+		return key
+	}
+
+	var key [sessionTicketKeyLength]byte
+	if _, err := rand.Read(key[:]); err != nil {
+		log.FromContext(ctx).Errorf("Unable to generate a TLS session ticket key: %v", err)
+	}
+
+	r.cache[period] = key
+
+	if r.store == nil {
+		return key
+	}
+
+	return r.share(ctx, period, key)
+}
+
+// share stores the key of the given period, unless another instance already stored its own key, which is then used.
+// The stored keys are never overwritten, so that all the instances agree on the key of each period.
+func (r *keysRotator) share(ctx context.Context, period int64, key [sessionTicketKeyLength]byte) [sessionTicketKeyLength]byte {
+	added, err := r.store.Add(ctx, r.storeKey(period), key[:], r.ttl())
+	if err != nil {
+		log.FromContext(ctx).Warnf("Unable to store the TLS session ticket key: %v", err)
+		return key
+	}
+
+	if added {
+		return key
+	}
+
+	var value []byte
+	if err := r.store.Get(ctx, r.storeKey(period), &value); err != nil || len(value) != sessionTicketKeyLength {
+		log.FromContext(ctx).Warnf("Unable to read the TLS session ticket key stored by another instance: %v", err)
+		return key
+	}
+
+	var stored [sessionTicketKeyLength]byte
+	copy(stored[:], value)
+	r.cache[period] = stored
+
+	return stored
+}
+
+// lookup returns the key of the given period from the store, or from the cache.
+func (r *keysRotator) lookup(ctx context.Context, period int64) ([sessionTicketKeyLength]byte, bool) {
+	cached, inCache := r.cache[period]
+
+	if r.store == nil {
+		return cached, inCache
+	}
+
+	var value []byte
+	err := r.store.Get(ctx, r.storeKey(period), &value)
+	if err == nil && len(value) == sessionTicketKeyLength {
+		var key [sessionTicketKeyLength]byte
+		copy(key[:], value)
+		r.cache[period] = key
+
+		return key, true
+	}
+
+	if inCache {
+		// Stores again the key, e.g. after a restart of the store, unless another instance did it first.
+		return r.share(ctx, period, cached), true
+	}
+
+	return cached, inCache
+}
+
+func (r *keysRotator) storeKey(period int64) string {
+	return fmt.Sprintf("traefik-tls-session-ticket-key-%d-%d", int64(r.interval.Seconds()), period)
+}
+
+func (r *keysRotator) ttl() time.Duration {
+	return time.Duration(r.previous+2) * r.interval
+}
+
+func readSessionTicketKeys(files []string) ([][sessionTicketKeyLength]byte, error) {
+	if len(files) == 0 {
+		return nil, errors.New("no session ticket key files")
+	}
+
+	var keys [][sessionTicketKeyLength]byte
+	for _, file := range files {
+		content, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("reading session ticket key file: %w", err)
+		}
+
+		key, err := parseSessionTicketKey(content)
+		if err != nil {
+			return nil, fmt.Errorf("invalid session ticket key in %s: %w", file, err)
+		}
+
+		keys = append(keys, key)
+	}
+
+	return keys, nil
+}
+
+func parseSessionTicketKey(content []byte) ([sessionTicketKeyLength]byte, error) {
+	var key [sessionTicketKeyLength]byte
+
+	if len(content) == sessionTicketKeyLength {
+		copy(key[:], content)
+		return key, nil
+	}
+
+	decoded, err := base64.StdEncoding.DecodeString(string(bytes.TrimSpace(content)))
+	if err != nil || len(decoded) != sessionTicketKeyLength {
+		return key, fmt.Errorf("a key must be %d bytes long, raw or base64 encoded", sessionTicketKeyLength)
+	}
+
+	copy(key[:], decoded)
+
+	return key, nil
+}
@@ -0,0 +1,306 @@
+package tls
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestManager_Get_sessionTickets(t *testing.T) {
+	testCases := []struct {
+		desc           string
+		sharedKeys     bool
+		disabled       bool
+		expectedResume bool
+	}{
+		{
+			desc: "keys generated by each instance",
+		},
+		{
+			desc:           "shared keys",
+			sharedKeys:     true,
+			expectedResume: true,
+		},
+		{
+			desc:       "session tickets disabled",
+			sharedKeys: true,
+			disabled:   true,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			keys := &SessionTicketKeys{}
+			keys.set([][sessionTicketKeyLength]byte{{1}})
+
+			newConfig := func() *tls.Config {
+				manager := NewManager()
+				manager.UpdateConfigs(context.Background(), nil, map[string]Options{
+					"default": {DisableSessionTickets: test.disabled},
+				}, nil)
+
+				if test.sharedKeys {
+					manager.SetSessionTicketKeys(keys)
+				}
+
+				config, err := manager.Get("default", "default")
+				require.NoError(t, err)
+
+				return config
+			}
+
+			clientConfig := &tls.Config{
+				InsecureSkipVerify: true,
+				ClientSessionCache: tls.NewLRUClientSessionCache(1),
+			}
+
+			// The first instance issues the session ticket.
+			assert.False(t, handshake(t, newConfig(), clientConfig))
+
+			// The second instance resumes the session, if it shares the keys of the first one.
+			assert.Equal(t, test.expectedResume, handshake(t, newConfig(), clientConfig))
+		})
+	}
+}
+
+func TestSessionTicketKeys_rotation(t *testing.T) {
+	keys := &SessionTicketKeys{}
+	keys.set([][sessionTicketKeyLength]byte{{1}})
+
+	manager := NewManager()
+	manager.UpdateConfigs(context.Background(), nil, map[string]Options{"default": {}}, nil)
+	manager.SetSessionTicketKeys(keys)
+
+	serverConfig, err := manager.Get("default", "default")
+	require.NoError(t, err)
+
+	clientConfig := &tls.Config{
+		InsecureSkipVerify: true,
+		ClientSessionCache: tls.NewLRUClientSessionCache(1),
+	}
+
+	assert.False(t, handshake(t, serverConfig, clientConfig))
+	assert.True(t, handshake(t, serverConfig, clientConfig))
+
+	// The previous key still decrypts the tickets.
+	keys.set([][sessionTicketKeyLength]byte{{2}, {1}})
+	assert.True(t, handshake(t, serverConfig, clientConfig))
+
+	// Once the previous key is removed, the tickets encrypted with it cannot be decrypted anymore.
+	keys.set([][sessionTicketKeyLength]byte{{3}})
+	assert.False(t, handshake(t, serverConfig, clientConfig))
+}
+
+func TestNewRotatingSessionTicketKeys(t *testing.T) {
+	store := newFakeKeyStore()
+	interval := time.Hour
+
+	newRotator := func(store SessionTicketKeyStore) *keysRotator {
+		return &keysRotator{
+			keys:     &SessionTicketKeys{},
+			store:    store,
+			interval: interval,
+			previous: 2,
+			cache:    make(map[int64][sessionTicketKeyLength]byte),
+		}
+	}
+
+	now := time.Now()
+
+	first := newRotator(store)
+	first.sync(context.Background(), now)
+
+	second := newRotator(store)
+	second.sync(context.Background(), now)
+
+	firstKeys, _ := first.keys.get()
+	secondKeys, _ := second.keys.get()
+	require.Len(t, firstKeys, 1)
+	assert.Equal(t, firstKeys, secondKeys)
+
+	// The key of the next period was created ahead of time, and is used after the rotation.
+	var next []byte
+	require.NoError(t, store.Get(context.Background(), first.storeKey(now.UnixNano()/int64(interval)+1), &next))
+
+	for i := 1; i <= 3; i++ {
+		first.sync(context.Background(), now.Add(time.Duration(i)*interval))
+		second.sync(context.Background(), now.Add(time.Duration(i)*interval))
+
+		rotatedKeys, _ := first.keys.get()
+		secondRotatedKeys, _ := second.keys.get()
+		assert.Equal(t, rotatedKeys, secondRotatedKeys)
+
+		if i == 1 {
+			assert.Equal(t, next, rotatedKeys[0][:])
+		}
+
+		// The two previous keys are kept for the overlap.
+		if i <= 2 {
+			require.Len(t, rotatedKeys, i+1)
+			assert.Equal(t, firstKeys[0], rotatedKeys[i])
+			continue
+		}
+
+		require.Len(t, rotatedKeys, 3)
+		assert.NotContains(t, rotatedKeys, firstKeys[0])
+	}
+
+	// Without store, the keys are kept when the rotation happens.
+	local := newRotator(nil)
+	local.sync(context.Background(), now)
+	localKeys, generation := local.keys.get()
+
+	local.sync(context.Background(), now)
+	sameKeys, sameGeneration := local.keys.get()
+	assert.Equal(t, localKeys, sameKeys)
+	assert.Equal(t, generation, sameGeneration)
+
+	// When the store is unavailable, the keys are not regenerated.
+	unavailable := newRotator(unavailableKeyStore{})
+	unavailable.sync(context.Background(), now)
+	unavailableKeys, _ := unavailable.keys.get()
+
+	unavailable.sync(context.Background(), now)
+	sameUnavailableKeys, _ := unavailable.keys.get()
+	assert.Equal(t, unavailableKeys, sameUnavailableKeys)
+
+	// A key stored by another instance is never overwritten, and is used instead of the local one.
+	racing := newRotator(store)
+	period := now.UnixNano() / int64(interval)
+	racing.cache[period] = [sessionTicketKeyLength]byte{42}
+
+	stored := racing.share(context.Background(), period, racing.cache[period])
+	assert.Equal(t, firstKeys[0], stored)
+	assert.Equal(t, firstKeys[0], racing.cache[period])
+
+	var value []byte
+	require.NoError(t, store.Get(context.Background(), racing.storeKey(period), &value))
+	assert.Equal(t, firstKeys[0][:], value)
+}
+
+func TestNewSessionTicketKeysFromFiles(t *testing.T) {
+	dir := t.TempDir()
+
+	rawKey := [sessionTicketKeyLength]byte{1}
+	encodedKey := [sessionTicketKeyLength]byte{2}
+
+	currentFile := filepath.Join(dir, "current.key")
+	previousFile := filepath.Join(dir, "previous.key")
+	require.NoError(t, os.WriteFile(currentFile, rawKey[:], 0o600))
+	require.NoError(t, os.WriteFile(previousFile, []byte(base64.StdEncoding.EncodeToString(encodedKey[:])+"\n"), 0o600))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	keys, err := NewSessionTicketKeysFromFiles(ctx, []string{currentFile, previousFile})
+	require.NoError(t, err)
+
+	loaded, _ := keys.get()
+	assert.Equal(t, [][sessionTicketKeyLength]byte{rawKey, encodedKey}, loaded)
+
+	newKey := [sessionTicketKeyLength]byte{3}
+	require.NoError(t, os.WriteFile(currentFile, newKey[:], 0o600))
+
+	assert.Eventually(t, func() bool {
+		reloaded, _ := keys.get()
+		return reloaded[0] == newKey
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// An invalid key is not loaded.
+	invalidFile := filepath.Join(dir, "invalid.key")
+	require.NoError(t, os.WriteFile(invalidFile, []byte("foo"), 0o600))
+
+	_, err = NewSessionTicketKeysFromFiles(ctx, []string{invalidFile})
+	assert.Error(t, err)
+}
+
+// handshake completes a TLS handshake with a server using the given configuration, and returns whether the session was resumed.
+func handshake(t *testing.T, serverConfig, clientConfig *tls.Config) bool {
+	t.Helper()
+
+	serverConn, clientConn := net.Pipe()
+
+	errCh := make(chan error, 1)
+	go func() {
+		defer serverConn.Close()
+
+		server := tls.Server(serverConn, serverConfig)
+		if err := server.Handshake(); err != nil {
+			errCh <- err
+			return
+		}
+
+		// Lets the client receive the session ticket sent after the handshake.
+		_, err := server.Write([]byte("x"))
+		errCh <- err
+	}()
+
+	client := tls.Client(clientConn, clientConfig)
+	require.NoError(t, client.Handshake())
+
+	_, err := client.Read(make([]byte, 1))
+	require.NoError(t, err)
+	require.NoError(t, <-errCh)
+
+	resumed := client.ConnectionState().DidResume
+	_ = client.Close()
+
+	return resumed
+}
+
+type fakeKeyStore struct {
+	mu     sync.Mutex
+	values map[string][]byte
+}
+
+func newFakeKeyStore() *fakeKeyStore {
+	return &fakeKeyStore{values: make(map[string][]byte)}
+}
+
+func (f *fakeKeyStore) Get(_ context.Context, key string, dst *[]byte) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	value, ok := f.values[key]
+	if !ok {
+		return errors.New("not found")
+	}
+
+	*dst = append([]byte(nil), value...)
+	return nil
+}
+
+func (f *fakeKeyStore) Add(_ context.Context, key string, item []byte, _ time.Duration) (bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if _, ok := f.values[key]; ok {
+		return false, nil
+	}
+
+	f.values[key] = append([]byte(nil), item...)
+	return true, nil
+}
+
+type unavailableKeyStore struct{}
+
+func (unavailableKeyStore) Get(context.Context, string, *[]byte) error {
+	return errors.New("unavailable")
+}
+
+func (unavailableKeyStore) Add(context.Context, string, []byte, time.Duration) (bool, error) {
+	return false, errors.New("unavailable")
+}
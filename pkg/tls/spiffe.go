@@ -0,0 +1,58 @@
+package tls
+
+import (
+	"crypto/tls"
+	"fmt"
+
+	"github.com/spiffe/go-spiffe/v2/bundle/x509bundle"
+	"github.com/spiffe/go-spiffe/v2/spiffeid"
+	"github.com/spiffe/go-spiffe/v2/spiffetls/tlsconfig"
+	"github.com/spiffe/go-spiffe/v2/svid/x509svid"
+)
+
+// SpiffeX509Source allows to retrieve the X.509 SVID and the trust bundle, kept up to date by the SPIFFE Workload API.
+type SpiffeX509Source interface {
+	x509svid.Source
+	x509bundle.Source
+}
+
+// NewSpiffeClientConfig returns a TLS client configuration presenting the X.509 SVID of the source,
+// and verifying the servers with the trust bundle of the source.
+// The servers are authorized if their SPIFFE ID is one of the given IDs, otherwise if it belongs to the given trust domain,
+// and, if neither is defined, as long as it is verified by the trust bundle.
+func NewSpiffeClientConfig(source SpiffeX509Source, ids []string, trustDomain string) (*tls.Config, error) {
+	authorizer, err := buildSpiffeAuthorizer(ids, trustDomain)
+	if err != nil {
+		return nil, err
+	}
+
+	return tlsconfig.MTLSClientConfig(source, source, authorizer), nil
+}
+
+func buildSpiffeAuthorizer(ids []string, trustDomain string) (tlsconfig.Authorizer, error) {
+	switch {
+	case len(ids) > 0:
+		spiffeIDs := make([]spiffeid.ID, 0, len(ids))
+		for _, rawID := range ids {
+			id, err := spiffeid.FromString(rawID)
+			if err != nil {
+				return nil, fmt.Errorf("invalid SPIFFE ID %q: %w", rawID, err)
+			}
+
+			spiffeIDs = append(spiffeIDs, id)
+		}
+
+		return tlsconfig.AuthorizeOneOf(spiffeIDs...), nil
+
+	case trustDomain != "":
+		td, err := spiffeid.TrustDomainFromString(trustDomain)
+		if err != nil {
+			return nil, fmt.Errorf("invalid SPIFFE trust domain %q: %w", trustDomain, err)
+		}
+
+		return tlsconfig.AuthorizeMemberOf(td), nil
+
+	default:
+		return tlsconfig.AuthorizeAny(), nil
+	}
+}
@@ -0,0 +1,320 @@
+package tls
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"net"
+	"net/url"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/spiffe/go-spiffe/v2/proto/spiffe/workload"
+	"github.com/spiffe/go-spiffe/v2/workloadapi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+)
+
+func TestNewSpiffeClientConfig(t *testing.T) {
+	ca := newSpiffeCA(t)
+
+	workloadAPI := newFakeWorkloadAPI(t)
+	workloadAPI.setSVID(ca.issue(t, "spiffe://traefik.test/traefik"), ca)
+
+	source, err := workloadapi.NewX509Source(context.Background(), workloadapi.WithClientOptions(workloadapi.WithAddr(workloadAPI.addr)))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = source.Close() })
+
+	serverAddr, clientIDs := startSpiffeTLSServer(t, ca, ca.issue(t, "spiffe://traefik.test/backend"))
+
+	testCases := []struct {
+		desc          string
+		ids           []string
+		trustDomain   string
+		expectedError bool
+	}{
+		{
+			desc: "any SPIFFE ID of the trust bundle",
+		},
+		{
+			desc: "allowed SPIFFE ID",
+			ids:  []string{"spiffe://traefik.test/foo", "spiffe://traefik.test/backend"},
+		},
+		{
+			desc:          "SPIFFE ID not allowed",
+			ids:           []string{"spiffe://traefik.test/foo"},
+			expectedError: true,
+		},
+		{
+			desc:        "allowed trust domain",
+			trustDomain: "spiffe://traefik.test",
+		},
+		{
+			desc:          "trust domain not allowed",
+			trustDomain:   "spiffe://other.test",
+			expectedError: true,
+		},
+		{
+			desc:          "SPIFFE IDs take precedence over the trust domain",
+			ids:           []string{"spiffe://traefik.test/foo"},
+			trustDomain:   "spiffe://traefik.test",
+			expectedError: true,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			config, err := NewSpiffeClientConfig(source, test.ids, test.trustDomain)
+			require.NoError(t, err)
+
+			conn, err := tls.Dial("tcp", serverAddr, config)
+			if test.expectedError {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			// The handshake is completed on the server side after the first exchange.
+			_, err = conn.Write([]byte("ping"))
+			require.NoError(t, err)
+			_, err = conn.Read(make([]byte, 4))
+			require.NoError(t, err)
+			require.NoError(t, conn.Close())
+
+			assert.Equal(t, "spiffe://traefik.test/traefik", <-clientIDs)
+		})
+	}
+}
+
+func TestNewSpiffeClientConfig_invalid(t *testing.T) {
+	_, err := NewSpiffeClientConfig(nil, []string{"foo"}, "")
+	assert.Error(t, err)
+
+	_, err = NewSpiffeClientConfig(nil, nil, "foo bar")
+	assert.Error(t, err)
+}
+
+func TestNewSpiffeClientConfig_rotation(t *testing.T) {
+	ca := newSpiffeCA(t)
+
+	workloadAPI := newFakeWorkloadAPI(t)
+	workloadAPI.setSVID(ca.issue(t, "spiffe://traefik.test/traefik"), ca)
+
+	source, err := workloadapi.NewX509Source(context.Background(), workloadapi.WithClientOptions(workloadapi.WithAddr(workloadAPI.addr)))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = source.Close() })
+
+	config, err := NewSpiffeClientConfig(source, nil, "")
+	require.NoError(t, err)
+
+	initial, err := config.GetClientCertificate(&tls.CertificateRequestInfo{})
+	require.NoError(t, err)
+
+	workloadAPI.setSVID(ca.issue(t, "spiffe://traefik.test/traefik"), ca)
+
+	assert.Eventually(t, func() bool {
+		current, err := config.GetClientCertificate(&tls.CertificateRequestInfo{})
+		return err == nil && string(current.Certificate[0]) != string(initial.Certificate[0])
+	}, 5*time.Second, 50*time.Millisecond)
+}
+
+type spiffeCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+}
+
+func newSpiffeCA(t *testing.T) *spiffeCA {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "SPIFFE CA"},
+		URIs:                  []*url.URL{{Scheme: "spiffe", Host: "traefik.test"}},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, key.Public(), key)
+	require.NoError(t, err)
+
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	return &spiffeCA{cert: cert, key: key}
+}
+
+type spiffeSVID struct {
+	id   string
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+}
+
+func (ca *spiffeCA) issue(t *testing.T, id string) spiffeSVID {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	uri, err := url.Parse(id)
+	require.NoError(t, err)
+
+	serial, err := rand.Int(rand.Reader, big.NewInt(1<<62))
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: serial,
+		URIs:         []*url.URL{uri},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.cert, key.Public(), ca.key)
+	require.NoError(t, err)
+
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	return spiffeSVID{id: id, cert: cert, key: key}
+}
+
+// startSpiffeTLSServer starts a TLS server presenting the given SVID, and requiring a client certificate verified by the CA.
+// The SPIFFE IDs of the clients are sent to the returned channel.
+func startSpiffeTLSServer(t *testing.T, ca *spiffeCA, svid spiffeSVID) (string, <-chan string) {
+	t.Helper()
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.cert)
+
+	listener, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{
+		Certificates: []tls.Certificate{{Certificate: [][]byte{svid.cert.Raw}, PrivateKey: svid.key}},
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		ClientCAs:    roots,
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = listener.Close() })
+
+	clientIDs := make(chan string, 10)
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+
+			go func() {
+				defer conn.Close()
+
+				buf := make([]byte, 4)
+				if _, err := conn.Read(buf); err != nil {
+					return
+				}
+
+				state := conn.(*tls.Conn).ConnectionState()
+				clientIDs <- state.PeerCertificates[0].URIs[0].String()
+
+				_, _ = conn.Write(buf)
+			}()
+		}
+	}()
+
+	return listener.Addr().String(), clientIDs
+}
+
+// fakeWorkloadAPI is a SPIFFE Workload API server streaming the X.509 SVID set with setSVID.
+type fakeWorkloadAPI struct {
+	workload.UnimplementedSpiffeWorkloadAPIServer
+
+	addr string
+
+	mu       sync.Mutex
+	response *workload.X509SVIDResponse
+	updates  []chan struct{}
+}
+
+func newFakeWorkloadAPI(t *testing.T) *fakeWorkloadAPI {
+	t.Helper()
+
+	socketPath := filepath.Join(t.TempDir(), "agent.sock")
+
+	listener, err := net.Listen("unix", socketPath)
+	require.NoError(t, err)
+
+	api := &fakeWorkloadAPI{addr: "unix://" + socketPath}
+
+	server := grpc.NewServer()
+	workload.RegisterSpiffeWorkloadAPIServer(server, api)
+
+	go func() { _ = server.Serve(listener) }()
+	t.Cleanup(server.Stop)
+
+	return api
+}
+
+func (f *fakeWorkloadAPI) setSVID(svid spiffeSVID, ca *spiffeCA) {
+	key, err := x509.MarshalPKCS8PrivateKey(svid.key)
+	if err != nil {
+		panic(err)
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.response = &workload.X509SVIDResponse{
+		Svids: []*workload.X509SVID{{
+			SpiffeId:    svid.id,
+			X509Svid:    svid.cert.Raw,
+			X509SvidKey: key,
+			Bundle:      ca.cert.Raw,
+		}},
+	}
+
+	for _, update := range f.updates {
+		select {
+		case update <- struct{}{}:
+		default:
+		}
+	}
+}
+
+func (f *fakeWorkloadAPI) FetchX509SVID(_ *workload.X509SVIDRequest, stream workload.SpiffeWorkloadAPI_FetchX509SVIDServer) error {
+	update := make(chan struct{}, 1)
+	update <- struct{}{}
+
+	f.mu.Lock()
+	f.updates = append(f.updates, update)
+	f.mu.Unlock()
+
+	for {
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case <-update:
+			f.mu.Lock()
+			response := f.response
+			f.mu.Unlock()
+
+			if err := stream.Send(response); err != nil {
+				return err
+			}
+		}
+	}
+}
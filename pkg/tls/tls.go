@@ -10,6 +10,21 @@ type ClientAuth struct {
 	// ClientAuthType defines the client authentication type to apply.
 	// The available values are: "NoClientCert", "RequestClientCert", "VerifyClientCertIfGiven" and "RequireAndVerifyClientCert".
 	ClientAuthType string `json:"clientAuthType,omitempty" toml:"clientAuthType,omitempty" yaml:"clientAuthType,omitempty" export:"true"`
+	// Revocation defines how the revocation of the client certificates is checked.
+	Revocation *Revocation `json:"revocation,omitempty" toml:"revocation,omitempty" yaml:"revocation,omitempty" export:"true"`
+}
+
+// +k8s:deepcopy-gen=true
+
+// Revocation defines the revocation checks of the client certificates.
+type Revocation struct {
+	// CRLs lists the certificate revocation lists, as file paths or HTTP(S) URLs.
+	CRLs []string `json:"crls,omitempty" toml:"crls,omitempty" yaml:"crls,omitempty"`
+	// OCSP enables asking the OCSP responders of the client certificates for their status.
+	OCSP bool `json:"ocsp,omitempty" toml:"ocsp,omitempty" yaml:"ocsp,omitempty" export:"true"`
+	// SoftFail accepts the client certificates whose revocation status cannot be determined,
+	// e.g. when an OCSP responder or a CRL distribution point is unreachable.
+	SoftFail bool `json:"softFail,omitempty" toml:"softFail,omitempty" yaml:"softFail,omitempty" export:"true"`
 }
 
 // +k8s:deepcopy-gen=true
@@ -24,6 +39,7 @@ type Options struct {
 	SniStrict                bool       `json:"sniStrict,omitempty" toml:"sniStrict,omitempty" yaml:"sniStrict,omitempty" export:"true"`
 	PreferServerCipherSuites bool       `json:"preferServerCipherSuites,omitempty" toml:"preferServerCipherSuites,omitempty" yaml:"preferServerCipherSuites,omitempty" export:"true"` // Deprecated: https://github.com/golang/go/issues/45430
 	ALPNProtocols            []string   `json:"alpnProtocols,omitempty" toml:"alpnProtocols,omitempty" yaml:"alpnProtocols,omitempty" export:"true"`
+	DisableSessionTickets    bool       `json:"disableSessionTickets,omitempty" toml:"disableSessionTickets,omitempty" yaml:"disableSessionTickets,omitempty" export:"true"`
 }
 
 // SetDefaults sets the default values for an Options struct.
@@ -45,4 +61,6 @@ type Store struct {
 type CertAndStores struct {
 	Certificate `yaml:",inline" export:"true"`
 	Stores      []string `json:"stores,omitempty" toml:"stores,omitempty" yaml:"stores,omitempty" export:"true"`
+	// Provider is the qualified name of the provider of the certificate, set during the aggregation of the configurations.
+	Provider string `json:"-" toml:"-" yaml:"-" file:"-"`
 }
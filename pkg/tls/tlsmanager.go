@@ -9,7 +9,9 @@ import (
 	"sync"
 
 	"github.com/go-acme/lego/v4/challenge/tlsalpn01"
+	"github.com/patrickmn/go-cache"
 	"github.com/sirupsen/logrus"
+	"github.com/traefik/traefik/v2/pkg/filewatcher"
 	"github.com/traefik/traefik/v2/pkg/log"
 	"github.com/traefik/traefik/v2/pkg/tls/generate"
 	"github.com/traefik/traefik/v2/pkg/types"
@@ -36,6 +38,16 @@ type Manager struct {
 	stores       map[string]*CertificateStore
 	configs      map[string]Options
 	certs        []*CertAndStores
+	obtainers    map[string]CertificateObtainer
+	// obtainedCerts holds the certificates obtained during the TLS handshakes by each store,
+	// which are kept across the reloads as they are not necessarily provided by the dynamic configuration yet.
+	obtainedCerts map[string]*cache.Cache
+	stapler       *ocspStapler
+	// certsProviders holds the qualified name of the provider of each dynamic certificate.
+	certsProviders map[*tls.Certificate]string
+	filesWatcher   *filewatcher.Watcher
+	// sessionTicketKeys, if any, are shared by all the TLS configurations, instead of the keys generated by each configuration.
+	sessionTicketKeys *SessionTicketKeys
 }
 
 // NewManager creates a new Manager.
@@ -45,6 +57,7 @@ func NewManager() *Manager {
 		configs: map[string]Options{
 			"default": DefaultTLSOptions,
 		},
+		stapler: newOCSPStapler(),
 	}
 }
 
@@ -78,11 +91,21 @@ func (m *Manager) UpdateConfigs(ctx context.Context, stores map[string]Store, co
 			log.FromContext(ctxStore).Errorf("Error while creating certificate store: %v", err)
 			continue
 		}
+		m.setObtainer(storeName, store)
 		m.stores[storeName] = store
 	}
 
+	m.updateCertificates(ctx)
+	m.watchCertificatesFiles(ctx)
+}
+
+// updateCertificates (re)loads the dynamic certificates into the stores, and updates the OCSP stapler with the served certificates.
+// The caller must hold the lock.
+func (m *Manager) updateCertificates(ctx context.Context) {
+	m.certsProviders = make(map[*tls.Certificate]string)
+
 	storesCertificates := make(map[string]map[string]*tls.Certificate)
-	for _, conf := range certs {
+	for _, conf := range m.certs {
 		if len(conf.Stores) == 0 {
 			if log.GetLevel() >= logrus.DebugLevel {
 				log.FromContext(ctx).Debugf("No store is defined to add the certificate %s, it will be added to the default store.",
@@ -92,20 +115,87 @@ func (m *Manager) UpdateConfigs(ctx context.Context, stores map[string]Store, co
 		}
 		for _, store := range conf.Stores {
 			ctxStore := log.With(ctx, log.Str(log.TLSStoreName, store))
-			if err := conf.Certificate.AppendCertificate(storesCertificates, store); err != nil {
+			cert, err := conf.Certificate.AppendCertificate(storesCertificates, store)
+			if err != nil {
 				log.FromContext(ctxStore).Errorf("Unable to append certificate %s to store: %v", conf.Certificate.GetTruncatedCertificateName(), err)
+				continue
+			}
+
+			if cert != nil {
+				m.certsProviders[cert] = conf.Provider
 			}
 		}
 	}
 
-	for storeName, certs := range storesCertificates {
-		st, ok := m.stores[storeName]
-		if !ok {
-			st, _ = buildCertificateStore(context.Background(), Store{}, storeName)
+	for storeName := range storesCertificates {
+		if _, ok := m.stores[storeName]; !ok {
+			st, _ := buildCertificateStore(context.Background(), Store{}, storeName)
+			m.setObtainer(storeName, st)
 			m.stores[storeName] = st
 		}
+	}
+
+	for storeName, st := range m.stores {
+		certs, ok := storesCertificates[storeName]
+		if !ok {
+			certs = make(map[string]*tls.Certificate)
+		}
 		st.DynamicCerts.Set(certs)
+		// The certificates matched by server names can have been replaced.
+		st.CertCache.Flush()
+	}
+
+	var servedCerts []*tls.Certificate
+	for _, store := range m.stores {
+		servedCerts = append(servedCerts, store.DefaultCertificate)
+		for _, cert := range store.DynamicCerts.Get().(map[string]*tls.Certificate) {
+			servedCerts = append(servedCerts, cert)
+		}
 	}
+	m.stapler.update(servedCerts)
+}
+
+// RunOCSPStapler refreshes, until the context is done, the OCSP responses stapled to the served certificates.
+func (m *Manager) RunOCSPStapler(ctx context.Context) {
+	m.stapler.run(ctx)
+}
+
+// SetCertificateObtainer sets the obtainer called by the given store for the server names not matched by its certificates.
+func (m *Manager) SetCertificateObtainer(storeName string, obtainer CertificateObtainer) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if m.obtainers == nil {
+		m.obtainers = make(map[string]CertificateObtainer)
+	}
+	m.obtainers[storeName] = obtainer
+
+	if store, ok := m.stores[storeName]; ok {
+		store.SetObtainer(obtainer)
+	}
+}
+
+// setObtainer sets the obtainer of the given store, and the certificates it has already obtained.
+// The caller must hold the lock.
+func (m *Manager) setObtainer(storeName string, store *CertificateStore) {
+	store.SetObtainer(m.obtainers[storeName])
+
+	if m.obtainedCerts == nil {
+		m.obtainedCerts = make(map[string]*cache.Cache)
+	}
+	if obtained, ok := m.obtainedCerts[storeName]; ok {
+		store.obtainedCerts = obtained
+		return
+	}
+	m.obtainedCerts[storeName] = store.obtainedCerts
+}
+
+// SetSessionTicketKeys sets the session ticket keys of the TLS configurations built afterwards.
+func (m *Manager) SetSessionTicketKeys(keys *SessionTicketKeys) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	m.sessionTicketKeys = keys
 }
 
 // Get gets the TLS configuration to use for a given store / configuration.
@@ -128,6 +218,10 @@ func (m *Manager) Get(storeName, configName string) (*tls.Config, error) {
 		tlsConfig = &tls.Config{}
 	}
 
+	if m.sessionTicketKeys != nil && !tlsConfig.SessionTicketsDisabled {
+		m.sessionTicketKeys.apply(tlsConfig)
+	}
+
 	store := m.getStore(storeName)
 	if store == nil {
 		err = fmt.Errorf("TLS store %s not found", storeName)
@@ -162,7 +256,7 @@ func (m *Manager) Get(storeName, configName string) (*tls.Config, error) {
 
 		bestCertificate := store.GetBestCertificate(clientHello)
 		if bestCertificate != nil {
-			return bestCertificate, nil
+			return m.stapler.staple(bestCertificate), nil
 		}
 
 		if sniStrict {
@@ -179,7 +273,7 @@ func (m *Manager) Get(storeName, configName string) (*tls.Config, error) {
 		}
 
 		log.WithoutContext().Debugf("Serving default certificate for request: %q", domainToCheck)
-		return store.DefaultCertificate, nil
+		return m.stapler.staple(store.DefaultCertificate), nil
 	}
 
 	return tlsConfig, err
@@ -206,6 +300,66 @@ func (m *Manager) GetCertificates() []*x509.Certificate {
 	return certificates
 }
 
+// CertificateInfo describes a certificate served by a TLS store.
+type CertificateInfo struct {
+	// Store is the name of the TLS store serving the certificate.
+	Store string
+	// Provider is the qualified name of the provider of the certificate.
+	// It is empty for the default certificate of the store.
+	Provider string
+	// Default is whether the certificate is the default certificate of the store.
+	Default bool
+	// Certificate is the leaf certificate.
+	Certificate *x509.Certificate
+}
+
+// GetCertificatesInfo returns the certificates served by the TLS stores,
+// without the certificates of the ACME TLS-ALPN-01 challenges.
+func (m *Manager) GetCertificatesInfo() []CertificateInfo {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+
+	var infos []CertificateInfo
+	for storeName, store := range m.stores {
+		if storeName == tlsalpn01.ACMETLS1Protocol {
+			continue
+		}
+
+		if leaf := parseLeaf(store.DefaultCertificate); leaf != nil {
+			infos = append(infos, CertificateInfo{Store: storeName, Default: true, Certificate: leaf})
+		}
+
+		if store.DynamicCerts == nil || store.DynamicCerts.Get() == nil {
+			continue
+		}
+
+		for _, cert := range store.DynamicCerts.Get().(map[string]*tls.Certificate) {
+			if leaf := parseLeaf(cert); leaf != nil {
+				infos = append(infos, CertificateInfo{Store: storeName, Provider: m.certsProviders[cert], Certificate: leaf})
+			}
+		}
+	}
+
+	return infos
+}
+
+func parseLeaf(cert *tls.Certificate) *x509.Certificate {
+	if cert == nil || len(cert.Certificate) == 0 {
+		return nil
+	}
+
+	if cert.Leaf != nil {
+		return cert.Leaf
+	}
+
+	leaf, err := x509.ParseCertificate(cert.Certificate[0])
+	if err != nil {
+		return nil
+	}
+
+	return leaf
+}
+
 // getStore returns the store found for storeName, or nil otherwise.
 func (m *Manager) getStore(storeName string) *CertificateStore {
 	st, ok := m.stores[storeName]
@@ -254,7 +408,8 @@ func buildCertificateStore(ctx context.Context, tlsStore Store, storename string
 // creates a TLS config that allows terminating HTTPS for multiple domains using SNI.
 func buildTLSConfig(tlsOption Options) (*tls.Config, error) {
 	conf := &tls.Config{
-		NextProtos: tlsOption.ALPNProtocols,
+		NextProtos:             tlsOption.ALPNProtocols,
+		SessionTicketsDisabled: tlsOption.DisableSessionTickets,
 	}
 
 	if len(tlsOption.ClientAuth.CAFiles) > 0 {
@@ -299,6 +454,18 @@ func buildTLSConfig(tlsOption Options) (*tls.Config, error) {
 		}
 	}
 
+	if tlsOption.ClientAuth.Revocation != nil {
+		if conf.ClientCAs == nil {
+			return nil, errors.New("revocation checks of the client certificates require CAFiles")
+		}
+
+		checker, err := newRevocationChecker(tlsOption.ClientAuth.Revocation)
+		if err != nil {
+			return nil, err
+		}
+		conf.VerifyConnection = checker.verifyConnection
+	}
+
 	// Set the minimum TLS version if set in the config
 	if minConst, exists := MinVersion[tlsOption.MinVersion]; exists {
 		conf.MinVersion = minConst
@@ -347,3 +347,28 @@ func TestClientAuth(t *testing.T) {
 		})
 	}
 }
+
+func TestManager_UpdateConfigs_KeepObtainedCertificates(t *testing.T) {
+	obtainedCert, err := loadTestCert("snitest.org", false)
+	require.NoError(t, err)
+
+	var obtained []string
+	tlsManager := NewManager()
+	tlsManager.SetCertificateObtainer(DefaultTLSStoreName, func(_ context.Context, serverName string) (*tls.Certificate, error) {
+		obtained = append(obtained, serverName)
+		return obtainedCert, nil
+	})
+
+	tlsManager.UpdateConfigs(context.Background(), nil, nil, nil)
+
+	cert := tlsManager.GetStore(DefaultTLSStoreName).GetBestCertificate(&tls.ClientHelloInfo{ServerName: "snitest.org"})
+	assert.Equal(t, obtainedCert, cert)
+	assert.Equal(t, []string{"snitest.org"}, obtained)
+
+	// The reload flushes the certificates cache, but not the obtained certificates.
+	tlsManager.UpdateConfigs(context.Background(), nil, nil, nil)
+
+	cert = tlsManager.GetStore(DefaultTLSStoreName).GetBestCertificate(&tls.ClientHelloInfo{ServerName: "snitest.org"})
+	assert.Equal(t, obtainedCert, cert)
+	assert.Equal(t, []string{"snitest.org"}, obtained)
+}
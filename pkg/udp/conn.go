@@ -205,8 +205,13 @@ type Conn struct {
 	timeout  time.Duration // for timeouts
 	doneOnce sync.Once
 	doneCh   chan struct{}
+
+	filters []PacketFilter // to drop some of the packets received from the remote
 }
 
+// PacketFilter reports whether a packet received from the remote of a session should be kept.
+type PacketFilter func(packet []byte) bool
+
 // readLoop waits for data to come from the listener's readLoop.
 // It then waits for a Read operation to be ready to consume said data,
 // that is to say it waits on readCh to receive the slice of bytes that the Read operation wants to read onto.
@@ -255,20 +260,49 @@ func (c *Conn) readLoop() {
 // Read reads up to len(p) bytes into p from the connection.
 // Each call corresponds to at most one datagram.
 // If p is smaller than the datagram, the extra bytes will be discarded.
+// The datagrams dropped by the packet filters are skipped.
 func (c *Conn) Read(p []byte) (int, error) {
-	select {
-	case c.readCh <- p:
-		n := <-c.sizeCh
-		c.muActivity.Lock()
-		c.lastActivity = time.Now()
-		c.muActivity.Unlock()
-		return n, nil
-
-	case <-c.doneCh:
-		return 0, io.EOF
+	for {
+		select {
+		case c.readCh <- p:
+			n := <-c.sizeCh
+			if !c.accept(p[:n]) {
+				continue
+			}
+
+			c.muActivity.Lock()
+			c.lastActivity = time.Now()
+			c.muActivity.Unlock()
+			return n, nil
+
+		case <-c.doneCh:
+			return 0, io.EOF
+		}
 	}
 }
 
+func (c *Conn) accept(packet []byte) bool {
+	for _, filter := range c.filters {
+		if !filter(packet) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// AddPacketFilter adds a filter on the packets received from the remote.
+// It is not thread safe, and is meant to be called before the session is read,
+// e.g. by a middleware.
+func (c *Conn) AddPacketFilter(filter PacketFilter) {
+	c.filters = append(c.filters, filter)
+}
+
+// RemoteAddr returns the remote network address of the session.
+func (c *Conn) RemoteAddr() net.Addr {
+	return c.rAddr
+}
+
 // Write writes len(p) bytes from p to the underlying connection.
 // Each call sends at most one datagram.
 // It is an error to send a message larger than the system's max UDP datagram size.
@@ -374,6 +374,53 @@ func TestReadLoopMaxDataSize(t *testing.T) {
 	}
 }
 
+func TestPacketFilter(t *testing.T) {
+	addr, err := net.ResolveUDPAddr("udp", ":0")
+	require.NoError(t, err)
+
+	ln, err := Listen("udp", addr, 3*time.Second)
+	require.NoError(t, err)
+	defer func() {
+		err := ln.Close()
+		require.NoError(t, err)
+	}()
+
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if errors.Is(err, errClosedListener) {
+				return
+			}
+			require.NoError(t, err)
+
+			conn.AddPacketFilter(func(packet []byte) bool {
+				return string(packet) != "DROP"
+			})
+
+			go func() {
+				b := make([]byte, 2048)
+				for {
+					n, err := conn.Read(b)
+					if err != nil {
+						return
+					}
+
+					_, err = conn.Write(b[:n])
+					require.NoError(t, err)
+				}
+			}()
+		}
+	}()
+
+	udpConn, err := net.Dial("udp", ln.Addr().String())
+	require.NoError(t, err)
+
+	_, err = udpConn.Write([]byte("DROP"))
+	require.NoError(t, err)
+
+	requireEcho(t, "KEEP", udpConn, time.Second)
+}
+
 // requireEcho tests that the conn session is live and functional,
 // by writing data through it, and expecting the same data as a response when reading on it.
 // It fatals if the read blocks longer than timeout,
@@ -16,6 +16,16 @@ const allColumns = [
     }),
     component: AvatarState
   },
+  {
+    name: 'certificateStatus',
+    required: true,
+    label: 'Status',
+    align: 'left',
+    fieldToProps: row => ({
+      state: { enabled: 'positive', warning: 'warning' }[row.status] || 'negative'
+    }),
+    component: AvatarState
+  },
   {
     name: 'tls',
     align: 'left',
@@ -81,6 +91,57 @@ const allColumns = [
     fieldToProps: () => ({ class: 'app-chip app-chip-service', dense: true }),
     content: row => row.service
   },
+  {
+    name: 'commonName',
+    align: 'left',
+    label: 'Common Name',
+    component: QChip,
+    fieldToProps: () => ({ class: 'app-chip app-chip-name', dense: true }),
+    content: row => row.commonName
+  },
+  {
+    name: 'sans',
+    align: 'left',
+    label: 'SANs',
+    component: Chips,
+    fieldToProps: row => ({
+      classNames: 'app-chip app-chip-rule',
+      dense: true,
+      list: row.sans
+    })
+  },
+  {
+    name: 'issuer',
+    align: 'left',
+    label: 'Issuer',
+    fieldToProps: () => ({}),
+    content: row => row.issuer
+  },
+  {
+    name: 'notAfter',
+    align: 'left',
+    label: 'Expires',
+    fieldToProps: () => ({}),
+    content: row => new Date(row.notAfter).toLocaleString()
+  },
+  {
+    name: 'resolver',
+    align: 'left',
+    label: 'Source',
+    fieldToProps: () => ({}),
+    content: row => row.resolver || row.provider || (row.default ? 'default' : '')
+  },
+  {
+    name: 'certificateRouters',
+    align: 'left',
+    label: 'Routers',
+    component: Chips,
+    fieldToProps: row => ({
+      classNames: 'app-chip app-chip-service',
+      dense: true,
+      list: [...(row.routers || []), ...(row.tcpRouters || [])]
+    })
+  },
   {
     name: 'provider',
     align: 'center',
@@ -102,7 +163,16 @@ const columnsByResource = {
   ],
   udpRouters: ['status', 'entryPoints', 'name', 'service', 'provider'],
   services: ['status', 'name', 'type', 'servers', 'provider'],
-  middlewares: ['status', 'name', 'type', 'provider']
+  middlewares: ['status', 'name', 'type', 'provider'],
+  certificates: [
+    'certificateStatus',
+    'commonName',
+    'sans',
+    'issuer',
+    'notAfter',
+    'resolver',
+    'certificateRouters'
+  ]
 }
 
 const propsByType = {
@@ -129,6 +199,11 @@ const propsByType = {
   },
   'tcp-middlewares': {
     columns: columnsByResource.middlewares
+  },
+  'tls-certificates': {
+    columns: columnsByResource.certificates,
+    // The certificates have no detail page.
+    noDetail: true
   }
 }
 
@@ -137,6 +212,7 @@ const GetTablePropsMixin = {
     getTableProps ({ type }) {
       return {
         onRowClick: row =>
+          !get(propsByType, `${type}.noDetail`, false) &&
           this.$router.push({
             path: `/${type.replace('-', '/', 'gi')}/${row.name}`
           }),
@@ -0,0 +1,27 @@
+import { APP } from '../_helpers/APP'
+import { getTotal } from './utils'
+
+const apiBase = '/tls'
+
+function getAllCertificates (params) {
+  return APP.api.get(`${apiBase}/certificates?search=${params.query}&status=${params.status}&per_page=${params.limit}&page=${params.page}`)
+    .then(response => {
+      const { data = [], headers } = response
+      const total = getTotal(headers, params)
+      console.log('Success -> TlsService -> getAllCertificates', response.data)
+      return { data, total }
+    })
+}
+
+function getCertificateByName (name) {
+  return APP.api.get(`${apiBase}/certificates/${name}`)
+    .then(body => {
+      console.log('Success -> TlsService -> getCertificateByName', body.data)
+      return body.data
+    })
+}
+
+export default {
+  getAllCertificates,
+  getCertificateByName
+}
@@ -243,6 +243,21 @@ const routes = [
         }
       }
     ]
+  },
+  {
+    path: '/tls',
+    redirect: '/tls/certificates',
+    component: LayoutDefault,
+    children: [
+      {
+        path: 'certificates',
+        name: 'tlsCertificates',
+        component: () => import('pages/tls/Certificates.vue'),
+        meta: {
+          title: 'TLS Certificates'
+        }
+      }
+    ]
   }
 ]
 
@@ -6,6 +6,7 @@ import entrypoints from './entrypoints'
 import http from './http'
 import tcp from './tcp'
 import udp from './udp'
+import tls from './tls'
 import platform from './platform'
 
 Vue.use(Vuex)
@@ -23,6 +24,7 @@ export default function (/* { ssrContext } */) {
       http,
       tcp,
       udp,
+      tls,
       platform
     },
 
@@ -0,0 +1,27 @@
+import TlsService from '../../_services/TlsService'
+
+export function getAllCertificates ({ commit }, params) {
+  commit('getAllCertificatesRequest')
+  return TlsService.getAllCertificates(params)
+    .then(body => {
+      commit('getAllCertificatesSuccess', { body, ...params })
+      return body
+    })
+    .catch(error => {
+      commit('getAllCertificatesFailure', error)
+      return Promise.reject(error)
+    })
+}
+
+export function getCertificateByName ({ commit }, name) {
+  commit('getCertificateByNameRequest')
+  return TlsService.getCertificateByName(name)
+    .then(body => {
+      commit('getCertificateByNameSuccess', body)
+      return body
+    })
+    .catch(error => {
+      commit('getCertificateByNameFailure', error)
+      return Promise.reject(error)
+    })
+}
@@ -0,0 +1,13 @@
+// ----------------------------
+// all Certificates
+// ----------------------------
+export function allCertificates (state) {
+  return state.allCertificates
+}
+
+// ----------------------------
+// Certificate by Name
+// ----------------------------
+export function certificateByName (state) {
+  return state.certificateByName
+}
@@ -0,0 +1,53 @@
+import { withPagination } from '../../_helpers/Mutations'
+
+// ----------------------------
+// Get All Certificates
+// ----------------------------
+export function getAllCertificatesRequest (state) {
+  withPagination('request', { statePath: 'allCertificates' })(state)
+}
+
+export function getAllCertificatesSuccess (state, data) {
+  const { query = '', status = '' } = data
+  const currentState = state.allCertificates
+
+  const isSameContext = currentState.currentQuery === query && currentState.currentStatus === status
+
+  state.allCertificates = {
+    ...state.allCertificates,
+    currentQuery: query,
+    currentStatus: status
+  }
+
+  withPagination('success', {
+    isSameContext,
+    statePath: 'allCertificates'
+  })(state, data)
+}
+
+export function getAllCertificatesFailure (state, error) {
+  withPagination('failure', { statePath: 'allCertificates' })(state, error)
+}
+
+export function getAllCertificatesClear (state) {
+  state.allCertificates = {}
+}
+
+// ----------------------------
+// Get Certificate By Name
+// ----------------------------
+export function getCertificateByNameRequest (state) {
+  state.certificateByName.loading = true
+}
+
+export function getCertificateByNameSuccess (state, body) {
+  state.certificateByName = { item: body, loading: false }
+}
+
+export function getCertificateByNameFailure (state, error) {
+  state.certificateByName = { error }
+}
+
+export function getCertificateByNameClear (state) {
+  state.certificateByName = {}
+}
@@ -0,0 +1,69 @@
+import { expect } from 'chai'
+import store from './index.js'
+
+const {
+  getAllCertificatesRequest,
+  getAllCertificatesSuccess,
+  getAllCertificatesFailure
+} = store.mutations
+
+describe('tls mutations', function () {
+  describe('tls certificates mutations', function () {
+    it('getAllCertificatesRequest', function () {
+      const state = {
+        allCertificates: {
+          items: [{}, {}, {}]
+        }
+      }
+
+      getAllCertificatesRequest(state)
+
+      expect(state.allCertificates.loading).to.equal(true)
+      expect(state.allCertificates.items.length).to.equal(3)
+    })
+
+    it('getAllCertificatesSuccess page 1', function () {
+      const state = {
+        allCertificates: {
+          loading: true
+        }
+      }
+
+      const data = {
+        body: {
+          data: [{}, {}, {}],
+          total: 3
+        },
+        query: 'test query',
+        status: 'warning',
+        page: 1
+      }
+
+      getAllCertificatesSuccess(state, data)
+
+      expect(state.allCertificates.loading).to.equal(false)
+      expect(state.allCertificates.total).to.equal(3)
+      expect(state.allCertificates.items.length).to.equal(3)
+      expect(state.allCertificates.currentPage).to.equal(1)
+      expect(state.allCertificates.currentQuery).to.equal('test query')
+      expect(state.allCertificates.currentStatus).to.equal('warning')
+    })
+
+    it('getAllCertificatesFailing', function () {
+      const state = {
+        allCertificates: {
+          items: [{}, {}, {}],
+          loading: true
+        }
+      }
+
+      const error = { message: 'invalid request: page: 2, per_page: 10' }
+
+      getAllCertificatesFailure(state, error)
+
+      expect(state.allCertificates.loading).to.equal(false)
+      expect(state.allCertificates.endReached).to.equal(true)
+      expect(state.allCertificates.items.length).to.equal(3)
+    })
+  })
+})
@@ -0,0 +1,4 @@
+export default {
+  allCertificates: {},
+  certificateByName: {}
+}